@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/base64"
@@ -13,8 +14,11 @@ import (
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/test/clitest"
@@ -27,6 +31,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
+	"software.sslmate.com/src/go-pkcs12"
 )
 
 var availableFormats = []string{"pretty", "json"}
@@ -378,6 +383,183 @@ Writing bundle #0 to file %s
 	}
 }
 
+func TestFetchX509CommandKeyStore(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("federated.org"))
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+
+	fakeRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    svid.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				},
+			},
+			FederatedBundles: map[string][]byte{
+				"spiffe://federated.org": x509util.DERFromCertificates(federatedCA.Bundle().X509Authorities()),
+			},
+		},
+	}
+
+	t.Run("pkcs12", func(t *testing.T) {
+		testDir := t.TempDir()
+		passwordFile := filepath.Join(testDir, "password")
+		require.NoError(t, os.WriteFile(passwordFile, []byte("password\n"), 0600))
+
+		test := setupTest(t, newFetchX509Command, fakeRequest)
+		rc := test.cmd.Run(test.args("-silent", "-write", testDir, "-format", "pkcs12", "-passwordFile", passwordFile))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Equal(t, fmt.Sprintf("Writing keystore #0 to file %s/svid.0.p12.\nWriting truststore #0 to file %s/bundle.0.p12.\n", testDir, testDir), test.stdout.String())
+
+		content, err := os.ReadFile(filepath.Join(testDir, "svid.0.p12"))
+		require.NoError(t, err)
+		key, cert, _, err := pkcs12.DecodeChain(content, "password")
+		require.NoError(t, err)
+		require.Equal(t, svid.PrivateKey, key)
+		require.Equal(t, svid.Certificates[0], cert)
+
+		content, err = os.ReadFile(filepath.Join(testDir, "bundle.0.p12"))
+		require.NoError(t, err)
+		certs, err := pkcs12.DecodeTrustStore(content, "password")
+		require.NoError(t, err)
+		require.Equal(t, append(ca.X509Authorities(), federatedCA.X509Authorities()...), certs)
+	})
+
+	t.Run("jks", func(t *testing.T) {
+		testDir := t.TempDir()
+		t.Setenv("KEYSTORE_PASSWORD", "password")
+
+		test := setupTest(t, newFetchX509Command, fakeRequest)
+		rc := test.cmd.Run(test.args("-silent", "-write", testDir, "-format", "jks", "-passwordEnv", "KEYSTORE_PASSWORD"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.FileExists(t, filepath.Join(testDir, "svid.0.jks"))
+		require.FileExists(t, filepath.Join(testDir, "bundle.0.jks"))
+	})
+
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		fetch          bool
+		expectedStderr string
+	}{
+		{
+			name:           "unsupported format",
+			args:           []string{"-write", t.TempDir(), "-format", "der"},
+			expectedStderr: "invalid -format: unsupported keystore format \"der\": expected \"pkcs12\" or \"jks\"\n",
+		},
+		{
+			name:           "keystore format without write",
+			args:           []string{"-format", "pkcs12"},
+			expectedStderr: "the pkcs12 format requires -write\n",
+		},
+		{
+			name:           "password with pem format",
+			args:           []string{"-write", t.TempDir(), "-passwordEnv", "KEYSTORE_PASSWORD"},
+			expectedStderr: "-passwordFile and -passwordEnv are only supported by the pkcs12 and jks formats\n",
+		},
+		{
+			name:           "both password sources",
+			args:           []string{"-write", t.TempDir(), "-format", "jks", "-passwordEnv", "KEYSTORE_PASSWORD", "-passwordFile", "password"},
+			expectedStderr: "only one of -passwordFile or -passwordEnv can be set\n",
+		},
+		{
+			name:           "password not set",
+			args:           []string{"-write", t.TempDir(), "-format", "jks", "-passwordEnv", "UNSET_KEYSTORE_PASSWORD"},
+			fetch:          true,
+			expectedStderr: "password environment variable \"UNSET_KEYSTORE_PASSWORD\" is not set\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*fakeworkloadapi.FakeRequest
+			if tt.fetch {
+				requests = append(requests, fakeRequest)
+			}
+			test := setupTest(t, newFetchX509Command, requests...)
+			rc := test.cmd.Run(test.args(tt.args...))
+			require.Equal(t, 1, rc)
+			require.Equal(t, tt.expectedStderr, test.stderr.String())
+		})
+	}
+}
+
+func TestWatcherWritesSVIDs(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	testDir := t.TempDir()
+	env := &commoncli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: new(bytes.Buffer),
+	}
+
+	w := newWatcher(context.Background(), env, &svidWriter{dir: testDir, format: "pkcs12"}, &reloader{})
+
+	for range 2 {
+		svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+		w.OnX509ContextUpdate(&workloadapi.X509Context{
+			SVIDs:   []*x509svid.SVID{svid},
+			Bundles: x509bundle.NewSet(ca.X509Bundle()),
+		})
+		require.Empty(t, env.Stderr.(*bytes.Buffer).String())
+
+		// The keystore is rewritten on every update
+		content, err := os.ReadFile(filepath.Join(testDir, "svid.0.p12"))
+		require.NoError(t, err)
+		_, cert, _, err := pkcs12.DecodeChain(content, "")
+		require.NoError(t, err)
+		require.Equal(t, svid.Certificates[0], cert)
+	}
+}
+
+func TestReloaderValidate(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		reloader  reloader
+		writePath string
+		expectErr string
+	}{
+		{
+			name:      "no reload",
+			writePath: "dir",
+		},
+		{
+			name:      "reload command",
+			reloader:  reloader{cmd: "true"},
+			writePath: "dir",
+		},
+		{
+			name:      "reload without write",
+			reloader:  reloader{cmd: "true"},
+			expectErr: "-reloadCmd and -reloadSignal require -write",
+		},
+		{
+			name:      "signal without pid file",
+			reloader:  reloader{signal: "SIGHUP"},
+			writePath: "dir",
+			expectErr: "-reloadSignal and -reloadPidFile must be set together",
+		},
+		{
+			name:      "pid file without signal",
+			reloader:  reloader{pidFile: "pid"},
+			writePath: "dir",
+			expectErr: "-reloadSignal and -reloadPidFile must be set together",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.reloader.validate(tt.writePath)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func TestValidateJWTCommandHelp(t *testing.T) {
 	test := setupTest(t, newValidateJWTCommand)
 	test.cmd.Help()
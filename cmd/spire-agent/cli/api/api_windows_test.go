@@ -18,10 +18,16 @@ const (
     	Time to wait for a response (default 5s)
 `
 	fetchX509Usage = `Usage of fetch x509:
+  -format string
+    	Format of the SVID data written to disk (pem, pkcs12, jks) (default "pem")
   -namedPipeName string
     	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -passwordEnv string
+    	Environment variable holding the keystore and truststore password (pkcs12 and jks formats)
+  -passwordFile string
+    	Path to a file holding the keystore and truststore password (pkcs12 and jks formats)
   -silent
     	Suppress stdout
   -timeout value
@@ -4,11 +4,9 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
-	"path"
 	"time"
 
 	"github.com/mitchellh/cli"
@@ -18,7 +16,6 @@ import (
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
-	"github.com/spiffe/spire/pkg/common/diskutil"
 )
 
 func NewFetchX509Command() cli.Command {
@@ -30,11 +27,11 @@ func newFetchX509Command(env *commoncli.Env, clientMaker workloadClientMaker) cl
 }
 
 type fetchX509Command struct {
-	silent    bool
-	writePath string
-	env       *commoncli.Env
-	printer   cliprinter.Printer
-	respTime  time.Duration
+	silent   bool
+	writer   svidWriter
+	env      *commoncli.Env
+	printer  cliprinter.Printer
+	respTime time.Duration
 }
 
 func (*fetchX509Command) name() string {
@@ -46,6 +43,10 @@ func (*fetchX509Command) synopsis() string {
 }
 
 func (c *fetchX509Command) run(ctx context.Context, _ *commoncli.Env, client *workloadClient) error {
+	if err := c.writer.validate(); err != nil {
+		return err
+	}
+
 	start := time.Now()
 	resp, err := c.fetchX509SVID(ctx, client)
 	c.respTime = time.Since(start)
@@ -58,7 +59,8 @@ func (c *fetchX509Command) run(ctx context.Context, _ *commoncli.Env, client *wo
 
 func (c *fetchX509Command) appendFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.silent, "silent", false, "Suppress stdout")
-	fs.StringVar(&c.writePath, "write", "", "Write SVID data to the specified path (optional; only available for pretty output format)")
+	fs.StringVar(&c.writer.dir, "write", "", "Write SVID data to the specified path (optional; only available for pretty output format)")
+	c.writer.appendFlags(fs)
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintFetchX509)
 }
 
@@ -74,83 +76,6 @@ func (c *fetchX509Command) fetchX509SVID(ctx context.Context, client *workloadCl
 	return stream.Recv()
 }
 
-func (c *fetchX509Command) writeResponse(svids []*X509SVID) error {
-	for i, svid := range svids {
-		svidPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.pem", i))
-		keyPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.key", i))
-		bundlePath := path.Join(c.writePath, fmt.Sprintf("bundle.%v.pem", i))
-
-		c.env.Printf("Writing SVID #%d to file %s.\n", i, svidPath)
-		err := c.writeCerts(svidPath, svid.Certificates)
-		if err != nil {
-			return err
-		}
-
-		c.env.Printf("Writing key #%d to file %s.\n", i, keyPath)
-		err = c.writeKey(keyPath, svid.PrivateKey)
-		if err != nil {
-			return err
-		}
-
-		c.env.Printf("Writing bundle #%d to file %s.\n", i, bundlePath)
-		err = c.writeCerts(bundlePath, svid.Bundle)
-		if err != nil {
-			return err
-		}
-
-		// sort and write the keys by trust domain so the output is consistent
-		federatedDomains := make([]string, 0, len(svid.FederatedBundles))
-		for trustDomain := range svid.FederatedBundles {
-			federatedDomains = append(federatedDomains, trustDomain)
-		}
-
-		for j, trustDomain := range federatedDomains {
-			bundlePath := path.Join(c.writePath, fmt.Sprintf("federated_bundle.%d.%d.pem", i, j))
-			c.env.Printf("Writing federated bundle #%d for trust domain %s to file %s.\n", j, trustDomain, bundlePath)
-			err = c.writeCerts(bundlePath, svid.FederatedBundles[trustDomain])
-			if err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
-// writeCerts takes a slice of data, which may contain multiple certificates,
-// and encodes them as PEM blocks, writing them to filename
-func (c *fetchX509Command) writeCerts(filename string, certs []*x509.Certificate) error {
-	pemData := []byte{}
-	for _, cert := range certs {
-		b := &pem.Block{
-			Type:  "CERTIFICATE",
-			Bytes: cert.Raw,
-		}
-		pemData = append(pemData, pem.EncodeToMemory(b)...)
-	}
-
-	return c.writeFile(filename, pemData)
-}
-
-// writeKey takes a private key, formats as PEM, and writes it to filename
-func (c *fetchX509Command) writeKey(filename string, privateKey crypto.PrivateKey) error {
-	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
-	if err != nil {
-		return err
-	}
-	b := &pem.Block{
-		Type:  "PRIVATE KEY",
-		Bytes: data,
-	}
-
-	return diskutil.WritePrivateFile(filename, pem.EncodeToMemory(b))
-}
-
-// writeFile creates or truncates filename, and writes data to it
-func (c *fetchX509Command) writeFile(filename string, data []byte) error {
-	return diskutil.WritePubliclyReadableFile(filename, data)
-}
-
 func (c *fetchX509Command) prettyPrintFetchX509(env *commoncli.Env, results ...any) error {
 	resp, ok := results[0].(*workload.X509SVIDResponse)
 	if !ok {
@@ -166,8 +91,8 @@ func (c *fetchX509Command) prettyPrintFetchX509(env *commoncli.Env, results ...a
 		printX509SVIDResponse(env, svids, c.respTime)
 	}
 
-	if c.writePath != "" {
-		if err := c.writeResponse(svids); err != nil {
+	if c.writer.dir != "" {
+		if err := c.writer.write(env, svids); err != nil {
 			return err
 		}
 	}
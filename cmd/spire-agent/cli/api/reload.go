@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+)
+
+// reloader notifies a workload after its SVID data has been rewritten, by
+// running a command and/or by sending a signal to a process.
+type reloader struct {
+	cmd     string
+	signal  string
+	pidFile string
+}
+
+func (r *reloader) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&r.cmd, "reloadCmd", "", "Command to run after SVID data is written (optional; arguments are split on white space and no shell is involved)")
+	fs.StringVar(&r.signal, "reloadSignal", "", "Signal sent to the process identified by -reloadPidFile after SVID data is written (optional, e.g. SIGHUP)")
+	fs.StringVar(&r.pidFile, "reloadPidFile", "", "Path to a file holding the ID of the process to signal")
+}
+
+func (r *reloader) validate(writePath string) error {
+	if (r.cmd != "" || r.signal != "") && writePath == "" {
+		return errors.New("-reloadCmd and -reloadSignal require -write")
+	}
+	if (r.signal == "") != (r.pidFile == "") {
+		return errors.New("-reloadSignal and -reloadPidFile must be set together")
+	}
+	if r.signal != "" {
+		if _, err := parseSignal(r.signal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *reloader) reload(ctx context.Context, env *commoncli.Env) error {
+	if r.cmd != "" {
+		args := strings.Fields(r.cmd)
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint: gosec // the command is provided by the operator
+		cmd.Stdout = env.Stdout
+		cmd.Stderr = env.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("reload command failed: %w", err)
+		}
+	}
+
+	if r.signal != "" {
+		sig, err := parseSignal(r.signal)
+		if err != nil {
+			return err
+		}
+		pid, err := readPidFile(r.pidFile)
+		if err != nil {
+			return err
+		}
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			return fmt.Errorf("failed to find process %d: %w", pid, err)
+		}
+		if err := process.Signal(sig); err != nil {
+			return fmt.Errorf("failed to signal process %d: %w", pid, err)
+		}
+	}
+
+	return nil
+}
+
+func readPidFile(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pid file: %w", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("invalid pid in pid file %q", path)
+	}
+	return pid, nil
+}
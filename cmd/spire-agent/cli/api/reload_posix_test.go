@@ -0,0 +1,61 @@
+//go:build !windows
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	reloadedPath := filepath.Join(dir, "reloaded")
+	pidFile := filepath.Join(dir, "pid")
+	require.NoError(t, os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600))
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	r := &reloader{
+		cmd:     "touch " + reloadedPath,
+		signal:  "usr1",
+		pidFile: pidFile,
+	}
+	require.NoError(t, r.validate(dir))
+	require.NoError(t, r.reload(context.Background(), &commoncli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: new(bytes.Buffer),
+	}))
+
+	require.FileExists(t, reloadedPath)
+	select {
+	case sig := <-signals:
+		require.Equal(t, syscall.SIGUSR1, sig)
+	case <-time.After(time.Minute):
+		require.Fail(t, "signal not received")
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	sig, err := parseSignal("SIGHUP")
+	require.NoError(t, err)
+	require.Equal(t, syscall.SIGHUP, sig)
+
+	sig, err = parseSignal("hup")
+	require.NoError(t, err)
+	require.Equal(t, syscall.SIGHUP, sig)
+
+	_, err = parseSignal("SIGFOO")
+	require.EqualError(t, err, `unknown signal "SIGFOO"`)
+}
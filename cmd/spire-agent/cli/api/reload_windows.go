@@ -0,0 +1,12 @@
+//go:build windows
+
+package api
+
+import (
+	"errors"
+	"os"
+)
+
+func parseSignal(string) (os.Signal, error) {
+	return nil, errors.New("-reloadSignal is not supported on Windows")
+}
@@ -16,7 +16,9 @@ import (
 )
 
 type WatchCLI struct {
-	config *common.ConfigOS
+	config   *common.ConfigOS
+	writer   *svidWriter
+	reloader *reloader
 }
 
 func (WatchCLI) Synopsis() string {
@@ -47,10 +49,19 @@ func (w *WatchCLI) Run(args []string) int {
 		return 1
 	}
 
+	if err := w.writer.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := w.reloader.validate(w.writer.dir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	if err := workloadapi.WatchX509Context(ctx, newWatcher(), clientOption); err != nil {
+	if err := workloadapi.WatchX509Context(ctx, newWatcher(ctx, commoncli.DefaultEnv, w.writer, w.reloader), clientOption); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
@@ -63,16 +74,33 @@ func (w *WatchCLI) parseConfig(args []string) error {
 	c := &common.ConfigOS{}
 	c.AddOSFlags(fs)
 
+	writer := &svidWriter{}
+	fs.StringVar(&writer.dir, "write", "", "Write SVID data to the specified path on every update (optional)")
+	writer.appendFlags(fs)
+
+	reloader := &reloader{}
+	reloader.appendFlags(fs)
+
 	w.config = c
+	w.writer = writer
+	w.reloader = reloader
 	return fs.Parse(args)
 }
 
 type watcher struct {
+	ctx        context.Context
+	env        *commoncli.Env
+	writer     *svidWriter
+	reloader   *reloader
 	updateTime time.Time
 }
 
-func newWatcher() *watcher {
+func newWatcher(ctx context.Context, env *commoncli.Env, writer *svidWriter, reloader *reloader) *watcher {
 	return &watcher{
+		ctx:        ctx,
+		env:        env,
+		writer:     writer,
+		reloader:   reloader,
 		updateTime: time.Now(),
 	}
 }
@@ -99,10 +127,21 @@ func (w *watcher) OnX509ContextUpdate(x509Context *workloadapi.X509Context) {
 			FederatedBundles: federatedBundles,
 		})
 	}
-	printX509SVIDResponse(commoncli.DefaultEnv, svids, time.Since(w.updateTime))
+	printX509SVIDResponse(w.env, svids, time.Since(w.updateTime))
 	w.updateTime = time.Now()
+
+	if w.writer.dir == "" {
+		return
+	}
+	if err := w.writer.write(w.env, svids); err != nil {
+		_ = w.env.ErrPrintf("Failed to write SVID data: %v\n", err)
+		return
+	}
+	if err := w.reloader.reload(w.ctx, w.env); err != nil {
+		_ = w.env.ErrPrintf("Failed to reload: %v\n", err)
+	}
 }
 
 func (w *watcher) OnX509ContextWatchError(err error) {
-	fmt.Fprintln(os.Stderr, err)
+	_ = w.env.ErrPrintln(err)
 }
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"github.com/spiffe/spire/pkg/common/keystore"
+)
+
+const (
+	writeFormatPEM = "pem"
+
+	// keyStoreAlias is the alias of the SVID entry in keystores.
+	keyStoreAlias = "svid"
+)
+
+// svidWriter writes X509-SVIDs and their bundles to a directory, either as
+// PEM files or as keystores and truststores. Files are written atomically so
+// that readers never observe partially written data.
+type svidWriter struct {
+	dir          string
+	format       string
+	passwordFile string
+	passwordEnv  string
+}
+
+func (w *svidWriter) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&w.format, "format", writeFormatPEM, "Format of the SVID data written to disk (pem, pkcs12, jks)")
+	fs.StringVar(&w.passwordFile, "passwordFile", "", "Path to a file holding the keystore and truststore password (pkcs12 and jks formats)")
+	fs.StringVar(&w.passwordEnv, "passwordEnv", "", "Environment variable holding the keystore and truststore password (pkcs12 and jks formats)")
+}
+
+func (w *svidWriter) validate() error {
+	if w.format == writeFormatPEM {
+		if w.passwordFile != "" || w.passwordEnv != "" {
+			return errors.New("-passwordFile and -passwordEnv are only supported by the pkcs12 and jks formats")
+		}
+		return nil
+	}
+
+	if err := keystore.ValidateFormat(w.format); err != nil {
+		return fmt.Errorf("invalid -format: %w", err)
+	}
+	if w.dir == "" {
+		return fmt.Errorf("the %s format requires -write", w.format)
+	}
+	if w.passwordFile != "" && w.passwordEnv != "" {
+		return errors.New("only one of -passwordFile or -passwordEnv can be set")
+	}
+	return nil
+}
+
+// write writes the SVIDs to the configured directory.
+func (w *svidWriter) write(env *commoncli.Env, svids []*X509SVID) error {
+	if w.format == writeFormatPEM {
+		return w.writePEM(env, svids)
+	}
+	return w.writeKeyStores(env, svids)
+}
+
+func (w *svidWriter) writePEM(env *commoncli.Env, svids []*X509SVID) error {
+	for i, svid := range svids {
+		svidPath := path.Join(w.dir, fmt.Sprintf("svid.%v.pem", i))
+		keyPath := path.Join(w.dir, fmt.Sprintf("svid.%v.key", i))
+		bundlePath := path.Join(w.dir, fmt.Sprintf("bundle.%v.pem", i))
+
+		env.Printf("Writing SVID #%d to file %s.\n", i, svidPath)
+		err := writeCerts(svidPath, svid.Certificates)
+		if err != nil {
+			return err
+		}
+
+		env.Printf("Writing key #%d to file %s.\n", i, keyPath)
+		err = writeKey(keyPath, svid.PrivateKey)
+		if err != nil {
+			return err
+		}
+
+		env.Printf("Writing bundle #%d to file %s.\n", i, bundlePath)
+		err = writeCerts(bundlePath, svid.Bundle)
+		if err != nil {
+			return err
+		}
+
+		// sort and write the keys by trust domain so the output is consistent
+		for j, trustDomain := range slices.Sorted(maps.Keys(svid.FederatedBundles)) {
+			bundlePath := path.Join(w.dir, fmt.Sprintf("federated_bundle.%d.%d.pem", i, j))
+			env.Printf("Writing federated bundle #%d for trust domain %s to file %s.\n", j, trustDomain, bundlePath)
+			err = writeCerts(bundlePath, svid.FederatedBundles[trustDomain])
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeKeyStores writes a keystore holding the SVID and its private key, and
+// a truststore holding the bundle and the federated bundles, for each SVID.
+func (w *svidWriter) writeKeyStores(env *commoncli.Env, svids []*X509SVID) error {
+	// The password is read on every write so that it can be rotated
+	// alongside the SVIDs.
+	password, err := w.readPassword()
+	if err != nil {
+		return err
+	}
+
+	for i, svid := range svids {
+		keyStorePath := path.Join(w.dir, fmt.Sprintf("svid.%d.%s", i, w.fileExtension()))
+		trustStorePath := path.Join(w.dir, fmt.Sprintf("bundle.%d.%s", i, w.fileExtension()))
+
+		keyStore, err := keystore.EncodeKeyStore(w.format, keyStoreAlias, svid.PrivateKey, svid.Certificates, password)
+		if err != nil {
+			return fmt.Errorf("failed to encode keystore #%d: %w", i, err)
+		}
+		env.Printf("Writing keystore #%d to file %s.\n", i, keyStorePath)
+		if err := diskutil.AtomicWritePrivateFile(keyStorePath, keyStore); err != nil {
+			return err
+		}
+
+		entries, err := trustStoreEntries(svid)
+		if err != nil {
+			return err
+		}
+		trustStore, err := keystore.EncodeTrustStore(w.format, entries, password)
+		if err != nil {
+			return fmt.Errorf("failed to encode truststore #%d: %w", i, err)
+		}
+		env.Printf("Writing truststore #%d to file %s.\n", i, trustStorePath)
+		if err := diskutil.AtomicWritePubliclyReadableFile(trustStorePath, trustStore); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *svidWriter) readPassword() (string, error) {
+	switch {
+	case w.passwordFile != "":
+		data, err := os.ReadFile(w.passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case w.passwordEnv != "":
+		password, ok := os.LookupEnv(w.passwordEnv)
+		if !ok {
+			return "", fmt.Errorf("password environment variable %q is not set", w.passwordEnv)
+		}
+		return password, nil
+	default:
+		return "", nil
+	}
+}
+
+func (w *svidWriter) fileExtension() string {
+	if w.format == keystore.FormatPKCS12 {
+		return "p12"
+	}
+	return w.format
+}
+
+// trustStoreEntries returns the truststore entries for the bundle and the
+// federated bundles of the SVID. Entries are aliased after the trust domain
+// of the certificate and its position in the bundle, and federated bundles
+// are sorted by trust domain so the output is consistent.
+func trustStoreEntries(svid *X509SVID) ([]keystore.TrustStoreEntry, error) {
+	id, err := spiffeid.FromString(svid.SPIFFEID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []keystore.TrustStoreEntry
+	appendBundle := func(trustDomain string, bundle []*x509.Certificate) {
+		for j, cert := range bundle {
+			entries = append(entries, keystore.TrustStoreEntry{
+				Alias: fmt.Sprintf("%s-%d", trustDomain, j),
+				Cert:  cert,
+			})
+		}
+	}
+
+	appendBundle(id.TrustDomain().Name(), svid.Bundle)
+	for _, trustDomain := range slices.Sorted(maps.Keys(svid.FederatedBundles)) {
+		appendBundle(trustDomain, svid.FederatedBundles[trustDomain])
+	}
+	return entries, nil
+}
+
+// writeCerts takes a slice of data, which may contain multiple certificates,
+// and encodes them as PEM blocks, writing them to filename
+func writeCerts(filename string, certs []*x509.Certificate) error {
+	pemData := []byte{}
+	for _, cert := range certs {
+		b := &pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: cert.Raw,
+		}
+		pemData = append(pemData, pem.EncodeToMemory(b)...)
+	}
+
+	return diskutil.AtomicWritePubliclyReadableFile(filename, pemData)
+}
+
+// writeKey takes a private key, formats as PEM, and writes it to filename
+func writeKey(filename string, privateKey crypto.PrivateKey) error {
+	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	b := &pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: data,
+	}
+
+	return diskutil.AtomicWritePrivateFile(filename, pem.EncodeToMemory(b))
+}
@@ -0,0 +1,44 @@
+//go:build !windows
+
+package cache
+
+const (
+	listUsage = `Usage of cache list:
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -socketPath string
+    	Path to the SPIRE Agent admin API Unix domain socket (default "/tmp/spire-agent/private/admin.sock")
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	showUsage = `Usage of cache show:
+  -entryID string
+    	The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -socketPath string
+    	Path to the SPIRE Agent admin API Unix domain socket (default "/tmp/spire-agent/private/admin.sock")
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	resyncUsage = `Usage of cache resync:
+  -evictStaleJWTSVIDs
+    	Also evict the expired or about to expire JWT-SVIDs from the JWT-SVID cache
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -socketPath string
+    	Path to the SPIRE Agent admin API Unix domain socket (default "/tmp/spire-agent/private/admin.sock")
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	rotateUsage = `Usage of cache rotate:
+  -entryID string
+    	The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -socketPath string
+    	Path to the SPIRE Agent admin API Unix domain socket (default "/tmp/spire-agent/private/admin.sock")
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+)
@@ -0,0 +1,453 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	availableFormats = []string{"pretty", "json"}
+
+	fooEntry = &cachev1.Entry{
+		Id:                "FOO",
+		SpiffeId:          "spiffe://example.org/foo",
+		ParentId:          "spiffe://example.org/spire/agent/foo",
+		Selectors:         []*cachev1.Selector{{Type: "unix", Value: "uid:1000"}},
+		Hint:              "external",
+		RevisionNumber:    3,
+		X509SvidExpiresAt: 1700000000,
+		Subscribers:       2,
+	}
+	barEntry = &cachev1.Entry{
+		Id:        "BAR",
+		SpiffeId:  "spiffe://example.org/bar",
+		ParentId:  "spiffe://example.org/spire/agent/foo",
+		Selectors: []*cachev1.Selector{{Type: "unix", Value: "uid:1001"}},
+		Stale:     true,
+	}
+
+	fooEntryPretty = `Entry ID         : FOO
+SPIFFE ID        : spiffe://example.org/foo
+Parent ID        : spiffe://example.org/spire/agent/foo
+Revision         : 3
+Selector         : unix:uid:1000
+Hint             : external
+X509-SVID expiry : 2023-11-14 22:13:20 +0000 UTC
+Subscribers      : 2
+`
+	barEntryPretty = `Entry ID         : BAR
+SPIFFE ID        : spiffe://example.org/bar
+Parent ID        : spiffe://example.org/spire/agent/foo
+Revision         : 0
+Selector         : unix:uid:1001
+X509-SVID        : not cached
+Stale            : true
+Subscribers      : 0
+`
+	fooEntryJSON = `{
+  "id": "FOO",
+  "spiffe_id": "spiffe://example.org/foo",
+  "parent_id": "spiffe://example.org/spire/agent/foo",
+  "selectors": [{"type": "unix", "value": "uid:1000"}],
+  "hint": "external",
+  "federates_with": [],
+  "revision_number": "3",
+  "x509_svid_expires_at": "1700000000",
+  "stale": false,
+  "subscribers": 2
+}`
+)
+
+func TestListCommandHelp(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.cmd.Help()
+	require.Equal(t, listUsage, test.stderr.String())
+}
+
+func TestListCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	require.Equal(t, "Lists the entries and subscribers in the agent cache", test.cmd.Synopsis())
+}
+
+func TestListCommand(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		entries              []*cachev1.Entry
+		subscriberSets       []*cachev1.SubscriberSet
+		listErr              error
+		expectedStderr       string
+		expectedStdoutPretty []string
+		expectedStdoutJSON   string
+	}{
+		{
+			name:    "success",
+			entries: []*cachev1.Entry{barEntry, fooEntry},
+			subscriberSets: []*cachev1.SubscriberSet{
+				{
+					Selectors: []*cachev1.Selector{{Type: "unix", Value: "gid:1000"}, {Type: "unix", Value: "uid:1000"}},
+					Count:     2,
+				},
+			},
+			expectedStdoutPretty: []string{
+				"Found 2 cached entries\n" + barEntryPretty + "\n" + fooEntryPretty + "\n",
+				"Found 1 subscriber selector set\nSelectors        : unix:gid:1000, unix:uid:1000\nSubscribers      : 2\n",
+			},
+			expectedStdoutJSON: `[
+  {
+    "entries": [
+      {
+        "id": "BAR",
+        "spiffe_id": "spiffe://example.org/bar",
+        "parent_id": "spiffe://example.org/spire/agent/foo",
+        "selectors": [{"type": "unix", "value": "uid:1001"}],
+        "hint": "",
+        "federates_with": [],
+        "revision_number": "0",
+        "x509_svid_expires_at": "0",
+        "stale": true,
+        "subscribers": 0
+      },
+      ` + fooEntryJSON + `
+    ]
+  },
+  {
+    "subscriber_sets": [
+      {
+        "selectors": [{"type": "unix", "value": "gid:1000"}, {"type": "unix", "value": "uid:1000"}],
+        "count": 2
+      }
+    ]
+  }
+]`,
+		},
+		{
+			name: "empty cache",
+			expectedStdoutPretty: []string{
+				"No cached entries found\nNo subscribers found\n",
+			},
+			expectedStdoutJSON: `[{"entries": []}, {"subscriber_sets": []}]`,
+		},
+		{
+			name:           "server error",
+			listErr:        status.Error(codes.Internal, "internal error"),
+			expectedStderr: "error listing cached entries: rpc error: code = Internal desc = internal error\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newListCommand)
+				test.server.entries = tt.entries
+				test.server.subscriberSets = tt.subscriberSets
+				test.server.err = tt.listErr
+
+				rc := test.cmd.Run(test.args("-output", format))
+				if tt.expectedStderr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expectedStderr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				assertOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutJSON, tt.expectedStdoutPretty...)
+			})
+		}
+	}
+}
+
+func TestShowCommandHelp(t *testing.T) {
+	test := setupTest(t, newShowCommand)
+	test.cmd.Help()
+	require.Equal(t, showUsage, test.stderr.String())
+}
+
+func TestShowCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newShowCommand)
+	require.Equal(t, "Shows an entry in the agent cache", test.cmd.Synopsis())
+}
+
+func TestShowCommand(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		args                 []string
+		expectedStderr       string
+		expectedStdoutPretty string
+		expectedStdoutJSON   string
+	}{
+		{
+			name:                 "success",
+			args:                 []string{"-entryID", "FOO"},
+			expectedStdoutPretty: fooEntryPretty,
+			expectedStdoutJSON:   `{"entry": ` + fooEntryJSON + `}`,
+		},
+		{
+			name:           "missing entry ID",
+			expectedStderr: "an entry ID is required\n",
+		},
+		{
+			name:           "entry not found",
+			args:           []string{"-entryID", "UNKNOWN"},
+			expectedStderr: "error fetching cached entry: rpc error: code = NotFound desc = entry not found\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newShowCommand)
+				test.server.entries = []*cachev1.Entry{barEntry, fooEntry}
+
+				args := append(tt.args, "-output", format)
+				rc := test.cmd.Run(test.args(args...))
+				if tt.expectedStderr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expectedStderr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				assertOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutJSON, tt.expectedStdoutPretty)
+			})
+		}
+	}
+}
+
+func TestResyncCommandHelp(t *testing.T) {
+	test := setupTest(t, newResyncCommand)
+	test.cmd.Help()
+	require.Equal(t, resyncUsage, test.stderr.String())
+}
+
+func TestResyncCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newResyncCommand)
+	require.Equal(t, "Synchronizes the agent cache with the server", test.cmd.Synopsis())
+}
+
+func TestResyncCommand(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		args                 []string
+		resyncErr            error
+		expectEvicted        bool
+		expectedStderr       string
+		expectedStdoutPretty string
+		expectedStdoutJSON   string
+	}{
+		{
+			name:                 "success",
+			expectedStdoutPretty: "Agent cache synchronized at 2023-11-14 22:13:20 +0000 UTC\n",
+			expectedStdoutJSON:   `{"last_sync": "1700000000"}`,
+		},
+		{
+			name:                 "success evicting stale JWT-SVIDs",
+			args:                 []string{"-evictStaleJWTSVIDs"},
+			expectEvicted:        true,
+			expectedStdoutPretty: "Agent cache synchronized at 2023-11-14 22:13:20 +0000 UTC\nEvicted 3 stale JWT-SVIDs\n",
+			expectedStdoutJSON:   `[{"last_sync": "1700000000"}, {"evicted": 3}]`,
+		},
+		{
+			name:           "synchronization fails",
+			args:           []string{"-evictStaleJWTSVIDs"},
+			resyncErr:      status.Error(codes.Internal, "failed to synchronize: oh no"),
+			expectedStderr: "error synchronizing agent cache: rpc error: code = Internal desc = failed to synchronize: oh no\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newResyncCommand)
+				test.server.err = tt.resyncErr
+
+				args := append(tt.args, "-output", format)
+				rc := test.cmd.Run(test.args(args...))
+				require.Equal(t, tt.expectEvicted, test.server.evicted)
+				if tt.expectedStderr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expectedStderr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				assertOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutJSON, tt.expectedStdoutPretty)
+			})
+		}
+	}
+}
+
+func TestRotateCommandHelp(t *testing.T) {
+	test := setupTest(t, newRotateCommand)
+	test.cmd.Help()
+	require.Equal(t, rotateUsage, test.stderr.String())
+}
+
+func TestRotateCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newRotateCommand)
+	require.Equal(t, "Forces the rotation of the X509-SVID of an entry in the agent cache", test.cmd.Synopsis())
+}
+
+func TestRotateCommand(t *testing.T) {
+	for _, tt := range []struct {
+		name                 string
+		args                 []string
+		expectRotated        []string
+		expectedStderr       string
+		expectedStdoutPretty string
+		expectedStdoutJSON   string
+	}{
+		{
+			name:                 "success",
+			args:                 []string{"-entryID", "FOO"},
+			expectRotated:        []string{"FOO"},
+			expectedStdoutPretty: "X509-SVID rotation scheduled; a new X509-SVID will be minted on the next SVID sync\n",
+			expectedStdoutJSON:   `{}`,
+		},
+		{
+			name:           "missing entry ID",
+			expectedStderr: "an entry ID is required\n",
+		},
+		{
+			name:           "entry not found",
+			args:           []string{"-entryID", "UNKNOWN"},
+			expectedStderr: "error rotating X509-SVID: rpc error: code = NotFound desc = entry not found\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newRotateCommand)
+				test.server.entries = []*cachev1.Entry{barEntry, fooEntry}
+
+				args := append(tt.args, "-output", format)
+				rc := test.cmd.Run(test.args(args...))
+				require.Equal(t, tt.expectRotated, test.server.rotated)
+				if tt.expectedStderr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expectedStderr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				assertOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutJSON, tt.expectedStdoutPretty)
+			})
+		}
+	}
+}
+
+func setupTest(t *testing.T, newCmd func(env *commoncli.Env, clientMaker cacheClientMaker) cli.Command) *cacheTest {
+	server := &fakeCacheServer{}
+
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		cachev1.RegisterCacheServer(s, server)
+	})
+
+	stdin := new(bytes.Buffer)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	cmd := newCmd(&commoncli.Env{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	}, newCacheClient)
+
+	test := &cacheTest{
+		addr:   clitest.GetAddr(addr),
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		server: server,
+		cmd:    cmd,
+	}
+
+	t.Cleanup(func() {
+		test.afterTest(t)
+	})
+
+	return test
+}
+
+type cacheTest struct {
+	stdin  *bytes.Buffer
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	addr   string
+	server *fakeCacheServer
+
+	cmd cli.Command
+}
+
+func (s *cacheTest) afterTest(t *testing.T) {
+	t.Logf("TEST:%s", t.Name())
+	t.Logf("STDOUT:\n%s", s.stdout.String())
+	t.Logf("STDIN:\n%s", s.stdin.String())
+	t.Logf("STDERR:\n%s", s.stderr.String())
+}
+
+func (s *cacheTest) args(extra ...string) []string {
+	return append([]string{clitest.AddrArg, s.addr}, extra...)
+}
+
+func assertOutputBasedOnFormat(t *testing.T, format, stdoutString, expectedStdoutJSON string, expectedStdoutPretty ...string) {
+	switch format {
+	case "pretty":
+		for _, expected := range expectedStdoutPretty {
+			require.Contains(t, stdoutString, expected)
+		}
+	case "json":
+		require.JSONEq(t, expectedStdoutJSON, stdoutString)
+	}
+}
+
+type fakeCacheServer struct {
+	cachev1.UnimplementedCacheServer
+
+	entries        []*cachev1.Entry
+	subscriberSets []*cachev1.SubscriberSet
+	err            error
+	evicted        bool
+	rotated        []string
+}
+
+func (s *fakeCacheServer) ListEntries(context.Context, *cachev1.ListEntriesRequest) (*cachev1.ListEntriesResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &cachev1.ListEntriesResponse{Entries: s.entries}, nil
+}
+
+func (s *fakeCacheServer) GetEntry(_ context.Context, req *cachev1.GetEntryRequest) (*cachev1.GetEntryResponse, error) {
+	for _, entry := range s.entries {
+		if entry.Id == req.Id {
+			return &cachev1.GetEntryResponse{Entry: entry}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "entry not found")
+}
+
+func (s *fakeCacheServer) ListSubscribers(context.Context, *cachev1.ListSubscribersRequest) (*cachev1.ListSubscribersResponse, error) {
+	return &cachev1.ListSubscribersResponse{SubscriberSets: s.subscriberSets}, nil
+}
+
+func (s *fakeCacheServer) Resync(context.Context, *cachev1.ResyncRequest) (*cachev1.ResyncResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &cachev1.ResyncResponse{LastSync: 1700000000}, nil
+}
+
+func (s *fakeCacheServer) RotateX509SVID(_ context.Context, req *cachev1.RotateX509SVIDRequest) (*cachev1.RotateX509SVIDResponse, error) {
+	if _, err := s.GetEntry(context.Background(), &cachev1.GetEntryRequest{Id: req.Id}); err != nil {
+		return nil, err
+	}
+	s.rotated = append(s.rotated, req.Id)
+	return &cachev1.RotateX509SVIDResponse{}, nil
+}
+
+func (s *fakeCacheServer) EvictStaleJWTSVIDs(context.Context, *cachev1.EvictStaleJWTSVIDsRequest) (*cachev1.EvictStaleJWTSVIDsResponse, error) {
+	s.evicted = true
+	return &cachev1.EvictStaleJWTSVIDsResponse{Evicted: 3}, nil
+}
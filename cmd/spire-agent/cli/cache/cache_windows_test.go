@@ -0,0 +1,44 @@
+//go:build windows
+
+package cache
+
+const (
+	listUsage = `Usage of cache list:
+  -namedPipeName string
+    	Pipe name of the SPIRE Agent admin API named pipe (default "\\spire-agent\\private\\admin")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	showUsage = `Usage of cache show:
+  -entryID string
+    	The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry
+  -namedPipeName string
+    	Pipe name of the SPIRE Agent admin API named pipe (default "\\spire-agent\\private\\admin")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	resyncUsage = `Usage of cache resync:
+  -evictStaleJWTSVIDs
+    	Also evict the expired or about to expire JWT-SVIDs from the JWT-SVID cache
+  -namedPipeName string
+    	Pipe name of the SPIRE Agent admin API named pipe (default "\\spire-agent\\private\\admin")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+	rotateUsage = `Usage of cache rotate:
+  -entryID string
+    	The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry
+  -namedPipeName string
+    	Pipe name of the SPIRE Agent admin API named pipe (default "\\spire-agent\\private\\admin")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -timeout value
+    	Time to wait for a response (default 5s)
+`
+)
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"context"
+	"flag"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	"github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/util"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+)
+
+const commandTimeout = 5 * time.Second
+
+type cacheClient struct {
+	cachev1.CacheClient
+	timeout time.Duration
+}
+
+type cacheClientMaker func(ctx context.Context, addr net.Addr, timeout time.Duration) (*cacheClient, error)
+
+// newCacheClient is the default client maker
+func newCacheClient(_ context.Context, addr net.Addr, timeout time.Duration) (*cacheClient, error) {
+	target, err := util.GetTargetName(addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := util.NewGRPCClient(target)
+	if err != nil {
+		return nil, err
+	}
+	return &cacheClient{
+		CacheClient: cachev1.NewCacheClient(conn),
+		timeout:     timeout,
+	}, nil
+}
+
+func (c *cacheClient) prepareContext(ctx context.Context) (context.Context, func()) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return ctx, func() {}
+}
+
+// command is a common interface for commands in this package. the adapter
+// can adapter this interface to the Command interface from github.com/mitchellh/cli.
+type command interface {
+	name() string
+	synopsis() string
+	appendFlags(*flag.FlagSet)
+	run(context.Context, *cli.Env, *cacheClient) error
+}
+
+type adapter struct {
+	common.AdminConfigOS // os specific
+
+	env          *cli.Env
+	clientsMaker cacheClientMaker
+	cmd          command
+
+	timeout cli.DurationFlag
+	flags   *flag.FlagSet
+}
+
+// adaptCommand converts a command into one conforming to the Command interface from github.com/mitchellh/cli
+func adaptCommand(env *cli.Env, clientsMaker cacheClientMaker, cmd command) *adapter {
+	a := &adapter{
+		clientsMaker: clientsMaker,
+		cmd:          cmd,
+		env:          env,
+		timeout:      cli.DurationFlag(commandTimeout),
+	}
+
+	fs := flag.NewFlagSet(cmd.name(), flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	fs.Var(&a.timeout, "timeout", "Time to wait for a response")
+
+	a.AddOSFlags(fs)
+	a.cmd.appendFlags(fs)
+	a.flags = fs
+
+	return a
+}
+
+func (a *adapter) Run(args []string) int {
+	ctx := context.Background()
+
+	if err := a.flags.Parse(args); err != nil {
+		_ = a.env.ErrPrintln(err)
+		return 1
+	}
+
+	addr, err := a.GetAddr()
+	if err != nil {
+		_ = a.env.ErrPrintln(err)
+		return 1
+	}
+	client, err := a.clientsMaker(ctx, addr, time.Duration(a.timeout))
+	if err != nil {
+		_ = a.env.ErrPrintln(err)
+		return 1
+	}
+
+	if err := a.cmd.run(ctx, a.env, client); err != nil {
+		_ = a.env.ErrPrintln(err)
+		return 1
+	}
+
+	return 0
+}
+
+func (a *adapter) Help() string {
+	_ = a.flags.Parse([]string{"-h"})
+	return ""
+}
+
+func (a *adapter) Synopsis() string {
+	return a.cmd.synopsis()
+}
+
+func printEntry(env *cli.Env, e *cachev1.Entry) {
+	_ = env.Printf("Entry ID         : %s\n", e.Id)
+	_ = env.Printf("SPIFFE ID        : %s\n", e.SpiffeId)
+	_ = env.Printf("Parent ID        : %s\n", e.ParentId)
+	_ = env.Printf("Revision         : %d\n", e.RevisionNumber)
+
+	for _, s := range e.Selectors {
+		_ = env.Printf("Selector         : %s:%s\n", s.Type, s.Value)
+	}
+	for _, id := range e.FederatesWith {
+		_ = env.Printf("FederatesWith    : %s\n", id)
+	}
+
+	if e.Hint != "" {
+		_ = env.Printf("Hint             : %s\n", e.Hint)
+	}
+
+	if e.X509SvidExpiresAt == 0 {
+		_ = env.Printf("X509-SVID        : not cached\n")
+	} else {
+		_ = env.Printf("X509-SVID expiry : %s\n", time.Unix(e.X509SvidExpiresAt, 0).UTC())
+	}
+
+	// stale is rare, so only show stale if true to keep
+	// from muddying the output.
+	if e.Stale {
+		_ = env.Printf("Stale            : %t\n", e.Stale)
+	}
+
+	_ = env.Printf("Subscribers      : %d\n", e.Subscribers)
+	_ = env.Printf("\n")
+}
+
+func printSubscriberSet(env *cli.Env, s *cachev1.SubscriberSet) {
+	selectors := make([]string, 0, len(s.Selectors))
+	for _, selector := range s.Selectors {
+		selectors = append(selectors, selector.Type+":"+selector.Value)
+	}
+	_ = env.Printf("Selectors        : %s\n", strings.Join(selectors, ", "))
+	_ = env.Printf("Subscribers      : %d\n", s.Count)
+	_ = env.Printf("\n")
+}
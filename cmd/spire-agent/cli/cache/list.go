@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+)
+
+func NewListCommand() cli.Command {
+	return newListCommand(commoncli.DefaultEnv, newCacheClient)
+}
+
+func newListCommand(env *commoncli.Env, clientMaker cacheClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, &listCommand{env: env})
+}
+
+type listCommand struct {
+	env     *commoncli.Env
+	printer cliprinter.Printer
+}
+
+func (*listCommand) name() string {
+	return "cache list"
+}
+
+func (*listCommand) synopsis() string {
+	return "Lists the entries and subscribers in the agent cache"
+}
+
+func (c *listCommand) appendFlags(fs *flag.FlagSet) {
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintList)
+}
+
+func (c *listCommand) run(ctx context.Context, _ *commoncli.Env, client *cacheClient) error {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+
+	entriesResp, err := client.ListEntries(ctx, &cachev1.ListEntriesRequest{})
+	if err != nil {
+		return fmt.Errorf("error listing cached entries: %w", err)
+	}
+	subscribersResp, err := client.ListSubscribers(ctx, &cachev1.ListSubscribersRequest{})
+	if err != nil {
+		return fmt.Errorf("error listing cache subscribers: %w", err)
+	}
+
+	return c.printer.PrintProto(entriesResp, subscribersResp)
+}
+
+func prettyPrintList(env *commoncli.Env, results ...any) error {
+	entriesResp, ok := results[0].(*cachev1.ListEntriesResponse)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+	subscribersResp, ok := results[1].(*cachev1.ListSubscribersResponse)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+
+	switch len(entriesResp.Entries) {
+	case 0:
+		_ = env.Println("No cached entries found")
+	case 1:
+		_ = env.Println("Found 1 cached entry")
+	default:
+		_ = env.Printf("Found %d cached entries\n", len(entriesResp.Entries))
+	}
+	for _, entry := range entriesResp.Entries {
+		printEntry(env, entry)
+	}
+
+	switch len(subscribersResp.SubscriberSets) {
+	case 0:
+		_ = env.Println("No subscribers found")
+	case 1:
+		_ = env.Println("Found 1 subscriber selector set")
+	default:
+		_ = env.Printf("Found %d subscriber selector sets\n", len(subscribersResp.SubscriberSets))
+	}
+	for _, subscriberSet := range subscribersResp.SubscriberSets {
+		printSubscriberSet(env, subscriberSet)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+)
+
+func NewResyncCommand() cli.Command {
+	return newResyncCommand(commoncli.DefaultEnv, newCacheClient)
+}
+
+func newResyncCommand(env *commoncli.Env, clientMaker cacheClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, &resyncCommand{env: env})
+}
+
+type resyncCommand struct {
+	evictStaleJWTSVIDs bool
+	env                *commoncli.Env
+	printer            cliprinter.Printer
+}
+
+func (*resyncCommand) name() string {
+	return "cache resync"
+}
+
+func (*resyncCommand) synopsis() string {
+	return "Synchronizes the agent cache with the server"
+}
+
+func (c *resyncCommand) appendFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.evictStaleJWTSVIDs, "evictStaleJWTSVIDs", false, "Also evict the expired or about to expire JWT-SVIDs from the JWT-SVID cache")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintResync)
+}
+
+func (c *resyncCommand) run(ctx context.Context, _ *commoncli.Env, client *cacheClient) error {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+
+	resp, err := client.Resync(ctx, &cachev1.ResyncRequest{})
+	if err != nil {
+		return fmt.Errorf("error synchronizing agent cache: %w", err)
+	}
+
+	if !c.evictStaleJWTSVIDs {
+		return c.printer.PrintProto(resp)
+	}
+
+	evictResp, err := client.EvictStaleJWTSVIDs(ctx, &cachev1.EvictStaleJWTSVIDsRequest{})
+	if err != nil {
+		return fmt.Errorf("error evicting stale JWT-SVIDs: %w", err)
+	}
+	return c.printer.PrintProto(resp, evictResp)
+}
+
+func prettyPrintResync(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*cachev1.ResyncResponse)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+	_ = env.Printf("Agent cache synchronized at %s\n", time.Unix(resp.LastSync, 0).UTC())
+
+	if len(results) > 1 {
+		evictResp, ok := results[1].(*cachev1.EvictStaleJWTSVIDsResponse)
+		if !ok {
+			return cliprinter.ErrInternalCustomPrettyFunc
+		}
+		_ = env.Printf("Evicted %d stale JWT-SVIDs\n", evictResp.Evicted)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+)
+
+func NewRotateCommand() cli.Command {
+	return newRotateCommand(commoncli.DefaultEnv, newCacheClient)
+}
+
+func newRotateCommand(env *commoncli.Env, clientMaker cacheClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, &rotateCommand{env: env})
+}
+
+type rotateCommand struct {
+	entryID string
+	env     *commoncli.Env
+	printer cliprinter.Printer
+}
+
+func (*rotateCommand) name() string {
+	return "cache rotate"
+}
+
+func (*rotateCommand) synopsis() string {
+	return "Forces the rotation of the X509-SVID of an entry in the agent cache"
+}
+
+func (c *rotateCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.entryID, "entryID", "", "The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintRotate)
+}
+
+func (c *rotateCommand) run(ctx context.Context, _ *commoncli.Env, client *cacheClient) error {
+	if c.entryID == "" {
+		return errors.New("an entry ID is required")
+	}
+
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+
+	resp, err := client.RotateX509SVID(ctx, &cachev1.RotateX509SVIDRequest{Id: c.entryID})
+	if err != nil {
+		return fmt.Errorf("error rotating X509-SVID: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func prettyPrintRotate(env *commoncli.Env, _ ...any) error {
+	return env.Println("X509-SVID rotation scheduled; a new X509-SVID will be minted on the next SVID sync")
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+)
+
+func NewShowCommand() cli.Command {
+	return newShowCommand(commoncli.DefaultEnv, newCacheClient)
+}
+
+func newShowCommand(env *commoncli.Env, clientMaker cacheClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, &showCommand{env: env})
+}
+
+type showCommand struct {
+	entryID string
+	env     *commoncli.Env
+	printer cliprinter.Printer
+}
+
+func (*showCommand) name() string {
+	return "cache show"
+}
+
+func (*showCommand) synopsis() string {
+	return "Shows an entry in the agent cache"
+}
+
+func (c *showCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.entryID, "entryID", "", "The ID of the cached entry, or the materialized entry ID for entries materialized from a template entry")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintShow)
+}
+
+func (c *showCommand) run(ctx context.Context, _ *commoncli.Env, client *cacheClient) error {
+	if c.entryID == "" {
+		return errors.New("an entry ID is required")
+	}
+
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+
+	resp, err := client.GetEntry(ctx, &cachev1.GetEntryRequest{Id: c.entryID})
+	if err != nil {
+		return fmt.Errorf("error fetching cached entry: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func prettyPrintShow(env *commoncli.Env, results ...any) error {
+	resp, ok := results[0].(*cachev1.GetEntryResponse)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+	printEntry(env, resp.Entry)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/api"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/cache"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/healthcheck"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/validate"
@@ -37,6 +38,18 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"api watch": func() (cli.Command, error) {
 			return &api.WatchCLI{}, nil
 		},
+		"cache list": func() (cli.Command, error) {
+			return cache.NewListCommand(), nil
+		},
+		"cache show": func() (cli.Command, error) {
+			return cache.NewShowCommand(), nil
+		},
+		"cache resync": func() (cli.Command, error) {
+			return cache.NewResyncCommand(), nil
+		},
+		"cache rotate": func() (cli.Command, error) {
+			return cache.NewRotateCommand(), nil
+		},
 		"run": func() (cli.Command, error) {
 			return run.NewRunCommand(ctx, cc.LogOptions, cc.AllowUnknownConfig), nil
 		},
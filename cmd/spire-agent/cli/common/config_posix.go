@@ -28,3 +28,17 @@ func (c *ConfigOS) GetTargetName() (string, error) {
 	}
 	return util.GetTargetName(addr)
 }
+
+// AdminConfigOS holds the OS specific configuration to reach the SPIRE Agent
+// admin API.
+type AdminConfigOS struct {
+	socketPath string
+}
+
+func (c *AdminConfigOS) AddOSFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.socketPath, "socketPath", DefaultAdminSocketPath, "Path to the SPIRE Agent admin API Unix domain socket")
+}
+
+func (c *AdminConfigOS) GetAddr() (net.Addr, error) {
+	return util.GetUnixAddrWithAbsPath(c.socketPath)
+}
@@ -20,3 +20,17 @@ func (c *ConfigOS) AddOSFlags(flags *flag.FlagSet) {
 func (c *ConfigOS) GetAddr() (net.Addr, error) {
 	return namedpipe.AddrFromName(c.namedPipeName), nil
 }
+
+// AdminConfigOS holds the OS specific configuration to reach the SPIRE Agent
+// admin API.
+type AdminConfigOS struct {
+	namedPipeName string
+}
+
+func (c *AdminConfigOS) AddOSFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.namedPipeName, "namedPipeName", DefaultAdminNamedPipeName, "Pipe name of the SPIRE Agent admin API named pipe")
+}
+
+func (c *AdminConfigOS) GetAddr() (net.Addr, error) {
+	return namedpipe.AddrFromName(c.namedPipeName), nil
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent"
+	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	"github.com/spiffe/spire/pkg/agent/workloadkey"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/catalog"
@@ -40,6 +42,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
+	"github.com/spiffe/spire/proto/spire/common"
 )
 
 const (
@@ -96,7 +99,13 @@ type agentConfig struct {
 	X509SVIDCacheMaxSize          int       `hcl:"x509_svid_cache_max_size"`
 	JWTSVIDCacheMaxSize           int       `hcl:"jwt_svid_cache_max_size"`
 
-	AuthorizedDelegates []string `hcl:"authorized_delegates"`
+	AuthorizedDelegates      []string                        `hcl:"authorized_delegates"`
+	AuthorizedDelegateScopes []authorizedDelegateScopeConfig `hcl:"authorized_delegate_scopes"`
+	AuthorizedDelegatePolicy *authorizedDelegatePolicyConfig `hcl:"authorized_delegate_policy"`
+
+	AuthorizedCacheAdmins []string `hcl:"authorized_cache_admins"`
+
+	OfflineCache *offlineCacheConfig `hcl:"offline_cache"`
 
 	ConfigPath string
 	ExpandEnv  bool
@@ -118,6 +127,29 @@ type sdsConfig struct {
 	DisableSPIFFECertValidation bool   `hcl:"disable_spiffe_cert_validation"`
 }
 
+type authorizedDelegateScopeConfig struct {
+	Delegate         string   `hcl:"delegate"`
+	SelectorTypes    []string `hcl:"selector_types"`
+	Selectors        []string `hcl:"selectors"`
+	SPIFFEIDPrefixes []string `hcl:"spiffe_id_prefixes"`
+	TrustDomains     []string `hcl:"trust_domains"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type authorizedDelegatePolicyConfig struct {
+	RegoPath       string `hcl:"rego_path"`
+	PolicyDataPath string `hcl:"policy_data_path"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type offlineCacheConfig struct {
+	KeyFile string `hcl:"key_file"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
 type experimentalConfig struct {
 	SyncInterval             string `hcl:"sync_interval"`
 	NamedPipeName            string `hcl:"named_pipe_name"`
@@ -619,6 +651,36 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 
 	ac.AuthorizedDelegates = c.Agent.AuthorizedDelegates
 
+	ac.AuthorizedDelegateScopes, err = parseAuthorizedDelegateScopes(c.Agent.AuthorizedDelegateScopes, c.Agent.AuthorizedDelegates)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, authorizedCacheAdmin := range c.Agent.AuthorizedCacheAdmins {
+		if _, err := idutil.MemberFromString(ac.TrustDomain, authorizedCacheAdmin); err != nil {
+			return nil, fmt.Errorf("error validating authorized cache admin: %w", err)
+		}
+	}
+
+	ac.AuthorizedCacheAdmins = c.Agent.AuthorizedCacheAdmins
+
+	if p := c.Agent.AuthorizedDelegatePolicy; p != nil {
+		if p.RegoPath == "" {
+			return nil, errors.New("authorized_delegate_policy rego_path must be set")
+		}
+		ac.AuthorizedDelegatePolicy = &delegatedidentityv1.PolicyEngineConfig{
+			RegoPath:       p.RegoPath,
+			PolicyDataPath: p.PolicyDataPath,
+		}
+	}
+
+	if c.Agent.OfflineCache != nil {
+		if c.Agent.OfflineCache.KeyFile == "" {
+			return nil, errors.New("offline_cache key_file must be set")
+		}
+		ac.OfflineCacheKeyFile = c.Agent.OfflineCache.KeyFile
+	}
+
 	if c.Agent.AvailabilityTarget != "" {
 		t, err := time.ParseDuration(c.Agent.AvailabilityTarget)
 		if err != nil {
@@ -647,6 +709,55 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	return ac, nil
 }
 
+func parseAuthorizedDelegateScopes(configs []authorizedDelegateScopeConfig, authorizedDelegates []string) (map[spiffeid.ID]delegatedidentityv1.DelegateScope, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	scopes := make(map[spiffeid.ID]delegatedidentityv1.DelegateScope, len(configs))
+	for _, c := range configs {
+		if !slices.Contains(authorizedDelegates, c.Delegate) {
+			return nil, fmt.Errorf("authorized_delegate_scopes delegate %q is not an authorized delegate", c.Delegate)
+		}
+		delegateID, err := spiffeid.FromString(c.Delegate)
+		if err != nil {
+			return nil, fmt.Errorf("authorized_delegate_scopes delegate %q is invalid: %w", c.Delegate, err)
+		}
+		if _, ok := scopes[delegateID]; ok {
+			return nil, fmt.Errorf("authorized_delegate_scopes delegate %q is configured more than once", c.Delegate)
+		}
+
+		scope := delegatedidentityv1.DelegateScope{
+			SelectorTypes: c.SelectorTypes,
+		}
+		for _, selector := range c.Selectors {
+			selectorType, selectorValue, ok := strings.Cut(selector, ":")
+			if !ok || selectorType == "" || selectorValue == "" {
+				return nil, fmt.Errorf("authorized_delegate_scopes selector %q must be formatted as type:value", selector)
+			}
+			scope.Selectors = append(scope.Selectors, &common.Selector{Type: selectorType, Value: selectorValue})
+		}
+		for _, prefix := range c.SPIFFEIDPrefixes {
+			// Prefixes match whole path segments, so a trailing slash is
+			// only a separator.
+			prefixID, err := spiffeid.FromString(strings.TrimSuffix(prefix, "/"))
+			if err != nil {
+				return nil, fmt.Errorf("authorized_delegate_scopes SPIFFE ID prefix %q is invalid: %w", prefix, err)
+			}
+			scope.SPIFFEIDPrefixes = append(scope.SPIFFEIDPrefixes, prefixID)
+		}
+		for _, trustDomain := range c.TrustDomains {
+			td, err := spiffeid.TrustDomainFromString(trustDomain)
+			if err != nil {
+				return nil, fmt.Errorf("authorized_delegate_scopes trust domain %q is invalid: %w", trustDomain, err)
+			}
+			scope.TrustDomains = append(scope.TrustDomains, td)
+		}
+		scopes[delegateID] = scope
+	}
+	return scopes, nil
+}
+
 func validateConfig(c *Config) error {
 	if c.Plugins == nil {
 		return errors.New("plugins section must be configured")
@@ -678,6 +789,22 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		detectedUnknown("agent", a.UnusedKeyPositions)
 	}
 
+	if a := c.Agent; a != nil && a.OfflineCache != nil && len(a.OfflineCache.UnusedKeyPositions) != 0 {
+		detectedUnknown("offline_cache", a.OfflineCache.UnusedKeyPositions)
+	}
+
+	if a := c.Agent; a != nil {
+		for _, scope := range a.AuthorizedDelegateScopes {
+			if len(scope.UnusedKeyPositions) != 0 {
+				detectedUnknown("authorized_delegate_scopes", scope.UnusedKeyPositions)
+			}
+		}
+	}
+
+	if a := c.Agent; a != nil && a.AuthorizedDelegatePolicy != nil && len(a.AuthorizedDelegatePolicy.UnusedKeyPositions) != 0 {
+		detectedUnknown("authorized_delegate_policy", a.AuthorizedDelegatePolicy.UnusedKeyPositions)
+	}
+
 	// TODO: Re-enable unused key detection for telemetry. See
 	// https://github.com/spiffe/spire/issues/1101 for more information
 	//
@@ -707,10 +834,20 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		}
 	}
 
+	for _, v := range c.Telemetry.OTLP {
+		if len(v.UnusedKeyPositions) != 0 {
+			detectedUnknown("OTLP", v.UnusedKeyPositions)
+		}
+	}
+
 	if p := c.Telemetry.InMem; p != nil && len(p.UnusedKeyPositions) != 0 {
 		detectedUnknown("InMem", p.UnusedKeyPositions)
 	}
 
+	if p := c.Telemetry.Tracing; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("Tracing", p.UnusedKeyPositions)
+	}
+
 	if len(c.HealthChecks.UnusedKeyPositions) != 0 {
 		detectedUnknown("health check", c.HealthChecks.UnusedKeyPositions)
 	}
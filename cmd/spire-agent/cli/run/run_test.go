@@ -15,9 +15,12 @@ import (
 	"github.com/hashicorp/hcl/hcl/ast"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/agent"
+	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	"github.com/spiffe/spire/pkg/agent/workloadkey"
 	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -1097,6 +1100,186 @@ func TestNewAgentConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg:   "offline_cache is disabled by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Empty(t, c.OfflineCacheKeyFile)
+			},
+		},
+		{
+			msg: "offline_cache key_file is parsed",
+			input: func(c *Config) {
+				c.Agent.OfflineCache = &offlineCacheConfig{KeyFile: "/path/to/key"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "/path/to/key", c.OfflineCacheKeyFile)
+			},
+		},
+		{
+			msg:         "offline_cache requires key_file",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.OfflineCache = &offlineCacheConfig{}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "authorized_delegate_scopes are parsed",
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{
+						Delegate:         "spiffe://example.org/cni",
+						SelectorTypes:    []string{"unix"},
+						Selectors:        []string{"k8s:ns:kube-system", "k8s:sa:cni-*"},
+						SPIFFEIDPrefixes: []string{"spiffe://example.org/ns/kube-system/", "spiffe://domain.test"},
+						TrustDomains:     []string{"example.org"},
+					},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, map[spiffeid.ID]delegatedidentityv1.DelegateScope{
+					spiffeid.RequireFromString("spiffe://example.org/cni"): {
+						SelectorTypes: []string{"unix"},
+						Selectors: []*common.Selector{
+							{Type: "k8s", Value: "ns:kube-system"},
+							{Type: "k8s", Value: "sa:cni-*"},
+						},
+						SPIFFEIDPrefixes: []spiffeid.ID{
+							spiffeid.RequireFromString("spiffe://example.org/ns/kube-system"),
+							spiffeid.RequireFromString("spiffe://domain.test"),
+						},
+						TrustDomains: []spiffeid.TrustDomain{spiffeid.RequireTrustDomainFromString("example.org")},
+					},
+				}, c.AuthorizedDelegateScopes)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes delegate must be authorized",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/other"},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes delegate configured more than once",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/cni"},
+					{Delegate: "spiffe://example.org/cni"},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes selector must have a type and value",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/cni", Selectors: []string{"k8s"}},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes SPIFFE ID prefix must be a SPIFFE ID",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/cni", SPIFFEIDPrefixes: []string{"/ns/kube-system"}},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes SPIFFE ID prefix must have a valid path",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/cni", SPIFFEIDPrefixes: []string{"spiffe://example.org/ns/*"}},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "authorized_delegate_scopes trust domain must be valid",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegates = []string{"spiffe://example.org/cni"}
+				c.Agent.AuthorizedDelegateScopes = []authorizedDelegateScopeConfig{
+					{Delegate: "spiffe://example.org/cni", TrustDomains: []string{"Example.org"}},
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "authorized_delegate_policy is parsed",
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegatePolicy = &authorizedDelegatePolicyConfig{
+					RegoPath:       "/path/to/policy.rego",
+					PolicyDataPath: "/path/to/data.json",
+				}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, &delegatedidentityv1.PolicyEngineConfig{
+					RegoPath:       "/path/to/policy.rego",
+					PolicyDataPath: "/path/to/data.json",
+				}, c.AuthorizedDelegatePolicy)
+			},
+		},
+		{
+			msg:         "authorized_delegate_policy requires rego_path",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedDelegatePolicy = &authorizedDelegatePolicyConfig{}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "authorized_cache_admins are parsed",
+			input: func(c *Config) {
+				c.Agent.AuthorizedCacheAdmins = []string{"spiffe://example.org/admin"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, []string{"spiffe://example.org/admin"}, c.AuthorizedCacheAdmins)
+			},
+		},
+		{
+			msg:         "authorized_cache_admins must be members of the trust domain",
+			expectError: true,
+			input: func(c *Config) {
+				c.Agent.AuthorizedCacheAdmins = []string{"spiffe://otherdomain.test/admin"}
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Nil(t, c)
+			},
+		},
 
 		{
 			msg:   "require PQ KEM is disabled (default)",
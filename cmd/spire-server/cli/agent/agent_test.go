@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -13,12 +14,14 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
@@ -43,6 +46,10 @@ var (
 			},
 		},
 	}
+	testAgentsWithAttestationTypes = []*types.Agent{
+		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent1"}, AttestationType: "join_token"},
+		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent2"}, AttestationType: "x509pop", CanReattest: true},
+	}
 	availableFormats = []string{"pretty", "json"}
 )
 
@@ -77,6 +84,8 @@ func TestBan(t *testing.T) {
 		expectStdoutPretty string
 		expectStdoutJSON   string
 		expectStderr       string
+		existentAgents     []*types.Agent
+		stdin              string
 		serverErr          error
 	}{
 		{
@@ -89,7 +98,7 @@ func TestBan(t *testing.T) {
 		{
 			name:             "no spiffe id",
 			expectReturnCode: 1,
-			expectStderr:     "Error: a SPIFFE ID is required\n",
+			expectStderr:     "Error: a SPIFFE ID or at least one filter flag is required\n",
 		},
 		{
 			name: "wrong UDS path",
@@ -107,11 +116,66 @@ func TestBan(t *testing.T) {
 			expectReturnCode: 1,
 			expectStderr:     "Error: rpc error: code = Internal desc = internal server error\n",
 		},
+		{
+			name:               "batch ban after confirmation",
+			args:               []string{"-attestationType", "join_token"},
+			existentAgents:     testAgentsWithAttestationTypes,
+			stdin:              "y\n",
+			expectReturnCode:   0,
+			expectStdoutPretty: "Agents banned:\nSPIFFE ID         : spiffe://example.org/spire/agent/agent1\n",
+			expectStdoutJSON:   `{"results":[{"id":"spiffe://example.org/spire/agent/agent1","attestation_type":"join_token","x509_svid_expires_at":"0","banned":false,"can_reattest":false,"status_code":0,"status_message":""}]}`,
+			expectStderr:       "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent1\n\nBan 1 agent? [y/N]: ",
+		},
+		{
+			name:             "batch ban canceled",
+			args:             []string{"-attestationType", "join_token"},
+			existentAgents:   testAgentsWithAttestationTypes,
+			stdin:            "n\n",
+			expectReturnCode: 1,
+			expectStderr:     "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent1\n\nBan 1 agent? [y/N]: Error: operation canceled\n",
+		},
+		{
+			name:               "batch ban dry run",
+			args:               []string{"-canReattest", "true", "-dryRun"},
+			existentAgents:     testAgentsWithAttestationTypes,
+			expectReturnCode:   0,
+			expectStdoutPretty: "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent2\nAttestation type  : x509pop\n",
+			expectStdoutJSON:   `{"results":[{"id":"spiffe://example.org/spire/agent/agent2","attestation_type":"x509pop","x509_svid_expires_at":"0","banned":false,"can_reattest":true,"status_code":0,"status_message":""}]}`,
+		},
+		{
+			name:               "batch ban without matching agents",
+			args:               []string{"-attestationType", "tpm", "-yes"},
+			existentAgents:     testAgentsWithAttestationTypes,
+			expectReturnCode:   0,
+			expectStdoutPretty: "No agents matched the filter\n",
+			expectStdoutJSON:   `{"results":[]}`,
+		},
+		{
+			name:             "batch ban server error",
+			args:             []string{"-attestationType", "join_token", "-yes"},
+			serverErr:        status.Error(codes.Internal, "internal server error"),
+			expectReturnCode: 1,
+			expectStderr:     "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+		{
+			name:             "spiffe id with filter flags",
+			args:             []string{"-spiffeID", "spiffe://example.org/spire/agent/agent1", "-attestationType", "join_token"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: -spiffeID cannot be combined with filter flags\n",
+		},
+		{
+			name:             "dry run without filter flags",
+			args:             []string{"-spiffeID", "spiffe://example.org/spire/agent/agent1", "-dryRun"},
+			expectReturnCode: 1,
+			expectStderr:     "Error: -dryRun and -yes require filter flags\n",
+		},
 	} {
 		for _, format := range availableFormats {
 			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
 				test := setupTest(t, agent.NewBanCommandWithEnv)
 				test.server.err = tt.serverErr
+				test.server.agents = tt.existentAgents
+				test.stdin.WriteString(tt.stdin)
 				args := tt.args
 				args = append(args, "-output", format)
 
@@ -140,7 +204,10 @@ func TestEvict(t *testing.T) {
 		expectedStdoutPretty string
 		expectedStdoutJSON   string
 		expectedStderr       string
+		existentAgents       []*types.Agent
+		stdin                string
 		serverErr            error
+		batchErr             error
 	}{
 		{
 			name:                 "success",
@@ -152,7 +219,7 @@ func TestEvict(t *testing.T) {
 		{
 			name:               "no spiffe id",
 			expectedReturnCode: 1,
-			expectedStderr:     "Error: a SPIFFE ID is required\n",
+			expectedStderr:     "Error: a SPIFFE ID or at least one filter flag is required\n",
 		},
 		{
 			name: "wrong UDS path",
@@ -170,11 +237,46 @@ func TestEvict(t *testing.T) {
 			expectedReturnCode: 1,
 			expectedStderr:     "Error: rpc error: code = Internal desc = internal server error\n",
 		},
+		{
+			name:                 "batch evict after confirmation",
+			args:                 []string{"-attestationType", "x509pop"},
+			existentAgents:       testAgentsWithAttestationTypes,
+			stdin:                "yes\n",
+			expectedReturnCode:   0,
+			expectedStdoutPretty: "Agents evicted:\nSPIFFE ID         : spiffe://example.org/spire/agent/agent2\n",
+			expectedStdoutJSON:   `{"results":[{"id":"spiffe://example.org/spire/agent/agent2","attestation_type":"x509pop","x509_svid_expires_at":"0","banned":false,"can_reattest":true,"status_code":0,"status_message":""}]}`,
+			expectedStderr:       "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent2\n\nEvict 1 agent? [y/N]: ",
+		},
+		{
+			name:                 "batch evict with agent errors",
+			args:                 []string{"-banned", "false", "-yes"},
+			existentAgents:       testAgentsWithAttestationTypes,
+			serverErr:            status.Error(codes.Internal, "failed to remove agent"),
+			expectedReturnCode:   0,
+			expectedStdoutPretty: "Agents not evicted:\nSPIFFE ID         : spiffe://example.org/spire/agent/agent1\nError             : failed to remove agent\nSPIFFE ID         : spiffe://example.org/spire/agent/agent2\nError             : failed to remove agent\n",
+			expectedStdoutJSON:   `{"results":[{"id":"spiffe://example.org/spire/agent/agent1","attestation_type":"join_token","x509_svid_expires_at":"0","banned":false,"can_reattest":false,"status_code":13,"status_message":"failed to remove agent"},{"id":"spiffe://example.org/spire/agent/agent2","attestation_type":"x509pop","x509_svid_expires_at":"0","banned":false,"can_reattest":true,"status_code":13,"status_message":"failed to remove agent"}]}`,
+		},
+		{
+			name:               "batch evict server error",
+			args:               []string{"-attestationType", "x509pop", "-yes"},
+			batchErr:           status.Error(codes.Internal, "internal server error"),
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+		{
+			name:               "invalid expiration time",
+			args:               []string{"-expiresBefore", "tomorrow"},
+			expectedReturnCode: 1,
+			expectedStderr:     "Error: date is not valid: parsing time \"tomorrow\" as \"2006-01-02 15:04:05 -0700 -07\": cannot parse \"tomorrow\" as \"2006\"\n",
+		},
 	} {
 		for _, format := range availableFormats {
 			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
 				test := setupTest(t, agent.NewEvictCommandWithEnv)
 				test.server.deleteErr = tt.serverErr
+				test.server.err = tt.batchErr
+				test.server.agents = tt.existentAgents
+				test.stdin.WriteString(tt.stdin)
 				args := tt.args
 				args = append(args, "-output", format)
 
@@ -496,9 +598,9 @@ func TestPurge(t *testing.T) {
 	td := spiffeid.RequireTrustDomainFromString("example.org")
 
 	expiredAgents := []*types.Agent{
-		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent1"}, CanReattest: true, X509SvidExpiresAt: now.Add(-time.Hour).Unix()},
-		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent2"}, CanReattest: true, X509SvidExpiresAt: now.Add(-24 * time.Hour).Unix()},
-		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent3"}, CanReattest: true, X509SvidExpiresAt: now.Add(-720 * time.Hour).Unix()},
+		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent1"}, CanReattest: true, X509SvidExpiresAt: now.Add(-time.Hour - time.Minute).Unix()},
+		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent2"}, CanReattest: true, X509SvidExpiresAt: now.Add(-24*time.Hour - time.Minute).Unix()},
+		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent3"}, AttestationType: "aws_iid", CanReattest: true, X509SvidExpiresAt: now.Add(-720*time.Hour - time.Minute).Unix()},
 	}
 	activeAgents := []*types.Agent{
 		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent6"}, CanReattest: true, X509SvidExpiresAt: now.Add(time.Hour).Unix()},
@@ -506,6 +608,18 @@ func TestPurge(t *testing.T) {
 		{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent8"}, CanReattest: true, X509SvidExpiresAt: now.Add(3 * time.Hour).Unix()},
 	}
 
+	const oneMonth = int64(720 * time.Hour / time.Second)
+	const oneDay = int64(24 * time.Hour / time.Second)
+
+	agent1ID := spiffeid.RequireFromPath(td, expiredAgents[0].Id.Path).String()
+	agent2ID := spiffeid.RequireFromPath(td, expiredAgents[1].Id.Path).String()
+	agent3ID := spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String()
+
+	legacyListReq := &agentv1.ListAgentsRequest{
+		Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
+		OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+	}
+
 	for _, tt := range []struct {
 		name                 string
 		args                 []string
@@ -513,23 +627,25 @@ func TestPurge(t *testing.T) {
 		expectedStdoutPretty string
 		expectedStdoutJSON   string
 		expectedStderr       string
+		stdin                string
+		expectBatchReqs      []*agentbatchv1.BatchPurgeAgentsRequest
 		expectListReq        *agentv1.ListAgentsRequest
 		expectDeleteReqs     []*agentv1.DeleteAgentRequest
 		existentAgents       []*types.Agent
 		expectedFormat       string
+		batchUnimplemented   bool
 		serverErr            error
 		deleteErr            error
 	}{
 		{
-			name:           "error listing agents",
+			name:           "error purging agents",
 			args:           []string{},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
 			},
 			serverErr:          status.Error(codes.Internal, "some error"),
-			expectedStderr:     "Error: failed to list agents: rpc error: code = Internal desc = some error\n",
+			expectedStderr:     "Error: failed to purge agents: rpc error: code = Internal desc = some error\n",
 			expectedReturnCode: 1,
 		},
 		{
@@ -541,67 +657,49 @@ func TestPurge(t *testing.T) {
 		},
 		{
 			name:           "error deleting expired agents",
-			args:           []string{"-expiredFor", "24h"},
+			args:           []string{"-expiredFor", "24h", "-yes"},
 			existentAgents: append(activeAgents, expiredAgents...),
 			deleteErr:      status.Error(codes.Internal, "some error when deleting agent"),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
-			},
-			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
-				{Id: expiredAgents[1].Id},
-				{Id: expiredAgents[2].Id},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneDay, DryRun: true},
+				{ExpiredFor: oneDay, Ids: []string{agent2ID, agent3ID}},
 			},
 			expectedStdoutPretty: `Found 2 expired agents
 
 Agents not purged:
 SPIFFE ID         : spiffe://example.org/spire/agent/agent2
-Error             : rpc error: code = Internal desc = some error when deleting agent
+Error             : some error when deleting agent
 SPIFFE ID         : spiffe://example.org/spire/agent/agent3
-Error             : rpc error: code = Internal desc = some error when deleting agent
+Error             : some error when deleting agent
 `,
 			expectedStdoutJSON: fmt.Sprintf(
 				`[{"expired_agents":[
-{"agent_id":"%s","deleted":false,"error":"rpc error: code = Internal desc = some error when deleting agent"},
-{"agent_id":"%s","deleted":false,"error":"rpc error: code = Internal desc = some error when deleting agent"}
-]}]`,
-				spiffeid.RequireFromPath(td, expiredAgents[1].Id.Path).String(),
-				spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String(),
-			),
+{"agent_id":"%s","deleted":false,"error":"some error when deleting agent"},
+{"agent_id":"%s","deleted":false,"error":"some error when deleting agent"}
+]}]`, agent2ID, agent3ID),
 		},
 		{
 			name:           "no args using default expiration for purging agents that expired for one month",
-			args:           []string{},
+			args:           []string{"-yes"},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
-			},
-			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
-				{Id: expiredAgents[2].Id},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+				{ExpiredFor: oneMonth, Ids: []string{agent3ID}},
 			},
 			expectedStdoutPretty: `Found 1 expired agent
 
 Agents purged:
 SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 `,
-			expectedStdoutJSON: fmt.Sprintf(
-				`[{"expired_agents":[{"agent_id":"%s","deleted":true}]}]`,
-				spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String(),
-			),
+			expectedStdoutJSON: fmt.Sprintf(`[{"expired_agents":[{"agent_id":"%s","deleted":true}]}]`, agent3ID),
 		},
 		{
 			name:           "providing expiration time for purging agents that has expired for 1 hour",
-			args:           []string{"-expiredFor", "1h"},
+			args:           []string{"-expiredFor", "1h", "-yes"},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
-			},
-			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
-				{Id: expiredAgents[0].Id},
-				{Id: expiredAgents[1].Id},
-				{Id: expiredAgents[2].Id},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: 3600, DryRun: true},
+				{ExpiredFor: 3600, Ids: []string{agent1ID, agent2ID, agent3ID}},
 			},
 			expectedStdoutPretty: `Found 3 expired agents
 
@@ -612,22 +710,15 @@ SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 `,
 			expectedStdoutJSON: fmt.Sprintf(
 				`[{"expired_agents":[{"agent_id":"%s","deleted":true},{"agent_id":"%s","deleted":true},{"agent_id":"%s","deleted":true}]}]`,
-				spiffeid.RequireFromPath(td, expiredAgents[0].Id.Path).String(),
-				spiffeid.RequireFromPath(td, expiredAgents[1].Id.Path).String(),
-				spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String(),
-			),
+				agent1ID, agent2ID, agent3ID),
 		},
 		{
 			name:           "providing expiration time for purging agents that has expired for 2 hours",
-			args:           []string{"-expiredFor", "2h30m30s"},
+			args:           []string{"-expiredFor", "2h30m30s", "-yes"},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
-			},
-			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
-				{Id: expiredAgents[1].Id},
-				{Id: expiredAgents[2].Id},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: 9030, DryRun: true},
+				{ExpiredFor: 9030, Ids: []string{agent2ID, agent3ID}},
 			},
 			expectedStdoutPretty: `Found 2 expired agents
 
@@ -637,17 +728,14 @@ SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 `,
 			expectedStdoutJSON: fmt.Sprintf(
 				`[{"expired_agents":[{"agent_id":"%s","deleted":true},{"agent_id":"%s","deleted":true}]}]`,
-				spiffeid.RequireFromPath(td, expiredAgents[1].Id.Path).String(),
-				spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String(),
-			),
+				agent2ID, agent3ID),
 		},
 		{
 			name:           "providing expiration time for purging agents that has expired for 2 months",
-			args:           []string{"-expiredFor", "1440h"},
+			args:           []string{"-expiredFor", "1440h", "-yes"},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: 2 * oneMonth, DryRun: true},
 			},
 			expectedStdoutPretty: `No agents to purge.`,
 			expectedStdoutJSON:   `[{"expired_agents":[]}]`,
@@ -656,9 +744,8 @@ SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 			name:           "using dry run",
 			args:           []string{"-dryRun", "-expiredFor", "24h"},
 			existentAgents: append(activeAgents, expiredAgents...),
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneDay, DryRun: true},
 			},
 			expectedStdoutPretty: `Found 2 expired agents
 
@@ -669,21 +756,182 @@ SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 `,
 			expectedStdoutJSON: fmt.Sprintf(
 				`[{"expired_agents":[{"agent_id":"%s","deleted":false},{"agent_id":"%s","deleted":false}]}]`,
-				spiffeid.RequireFromPath(td, expiredAgents[1].Id.Path).String(),
-				spiffeid.RequireFromPath(td, expiredAgents[2].Id.Path).String(),
-			),
+				agent2ID, agent3ID),
 		},
 		{
 			name:           "no expired agent found",
 			args:           []string{},
 			existentAgents: activeAgents,
-			expectListReq: &agentv1.ListAgentsRequest{
-				Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-				OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
 			},
 			expectedStdoutPretty: `No agents to purge.`,
 			expectedStdoutJSON:   `[{"expired_agents":[]}]`,
 		},
+		{
+			name:           "filtering by selectors and attestation type",
+			args:           []string{"-expiredFor", "1h", "-selector", "aws_iid:tag:pool:blue", "-matchSelectorsOn", "exact", "-attestationType", "aws_iid", "-yes"},
+			existentAgents: append(activeAgents, expiredAgents...),
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{
+					ExpiredFor: 3600,
+					BySelectorMatch: &agentbatchv1.SelectorMatch{
+						Selectors: []*agentbatchv1.Selector{{Type: "aws_iid", Value: "tag:pool:blue"}},
+						Match:     agentbatchv1.SelectorMatch_MATCH_EXACT,
+					},
+					ByAttestationType: "aws_iid",
+					DryRun:            true,
+				},
+				{
+					ExpiredFor: 3600,
+					BySelectorMatch: &agentbatchv1.SelectorMatch{
+						Selectors: []*agentbatchv1.Selector{{Type: "aws_iid", Value: "tag:pool:blue"}},
+						Match:     agentbatchv1.SelectorMatch_MATCH_EXACT,
+					},
+					ByAttestationType: "aws_iid",
+					Ids:               []string{agent3ID},
+				},
+			},
+			expectedStdoutPretty: `Found 1 expired agent
+
+Agents purged:
+SPIFFE ID         : spiffe://example.org/spire/agent/agent3
+`,
+			expectedStdoutJSON: fmt.Sprintf(`[{"expired_agents":[{"agent_id":"%s","deleted":true}]}]`, agent3ID),
+		},
+		{
+			name:               "malformed selector",
+			args:               []string{"-selector", "aws_iid"},
+			existentAgents:     append(activeAgents, expiredAgents...),
+			expectedStderr:     "Error: error parsing selector \"aws_iid\": selector \"aws_iid\" must be formatted as type:value\n",
+			expectedReturnCode: 1,
+		},
+		{
+			name:           "purging after confirmation",
+			args:           []string{},
+			stdin:          "y\n",
+			existentAgents: append(activeAgents, expiredAgents...),
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+				{ExpiredFor: oneMonth, Ids: []string{agent3ID}},
+			},
+			expectedStdoutPretty: `Found 1 expired agent
+
+Agents purged:
+SPIFFE ID         : spiffe://example.org/spire/agent/agent3
+`,
+			expectedStdoutJSON: fmt.Sprintf(`[{"expired_agents":[{"agent_id":"%s","deleted":true}]}]`, agent3ID),
+			expectedStderr:     "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent3\n\nPurge 1 agent? [y/N]: ",
+		},
+		{
+			name:           "canceling purge",
+			args:           []string{},
+			stdin:          "n\n",
+			existentAgents: append(activeAgents, expiredAgents...),
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+			},
+			expectedStderr:     "Purge 1 agent? [y/N]: Error: operation canceled\n",
+			expectedReturnCode: 1,
+		},
+		{
+			name:               "purging with the agent API on servers without the batch API",
+			args:               []string{"-expiredFor", "24h", "-yes"},
+			existentAgents:     append(activeAgents, expiredAgents...),
+			batchUnimplemented: true,
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneDay, DryRun: true},
+			},
+			expectListReq: legacyListReq,
+			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
+				{Id: expiredAgents[1].Id},
+				{Id: expiredAgents[2].Id},
+			},
+			expectedStdoutPretty: `Found 2 expired agents
+
+Agents purged:
+SPIFFE ID         : spiffe://example.org/spire/agent/agent2
+SPIFFE ID         : spiffe://example.org/spire/agent/agent3
+`,
+			expectedStdoutJSON: fmt.Sprintf(
+				`[{"expired_agents":[{"agent_id":"%s","deleted":true},{"agent_id":"%s","deleted":true}]}]`,
+				agent2ID, agent3ID),
+		},
+		{
+			name:               "purging with the agent API after confirmation",
+			args:               []string{},
+			stdin:              "y\n",
+			existentAgents:     append(activeAgents, expiredAgents...),
+			batchUnimplemented: true,
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+			},
+			expectListReq: legacyListReq,
+			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
+				{Id: expiredAgents[2].Id},
+			},
+			expectedStdoutPretty: `Found 1 expired agent
+
+Agents purged:
+SPIFFE ID         : spiffe://example.org/spire/agent/agent3
+`,
+			expectedStdoutJSON: fmt.Sprintf(`[{"expired_agents":[{"agent_id":"%s","deleted":true}]}]`, agent3ID),
+			expectedStderr:     "Found 1 matching agent:\n\nSPIFFE ID         : spiffe://example.org/spire/agent/agent3\n\nPurge 1 agent? [y/N]: ",
+		},
+		{
+			name:               "canceling purge with the agent API",
+			args:               []string{},
+			stdin:              "n\n",
+			existentAgents:     append(activeAgents, expiredAgents...),
+			batchUnimplemented: true,
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+			},
+			expectListReq:      legacyListReq,
+			expectedStderr:     "Purge 1 agent? [y/N]: Error: operation canceled\n",
+			expectedReturnCode: 1,
+		},
+		{
+			name:               "error deleting expired agents with the agent API",
+			args:               []string{"-expiredFor", "24h", "-yes"},
+			existentAgents:     append(activeAgents, expiredAgents...),
+			batchUnimplemented: true,
+			deleteErr:          status.Error(codes.Internal, "some error when deleting agent"),
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneDay, DryRun: true},
+			},
+			expectListReq: legacyListReq,
+			expectDeleteReqs: []*agentv1.DeleteAgentRequest{
+				{Id: expiredAgents[1].Id},
+				{Id: expiredAgents[2].Id},
+			},
+			expectedStdoutPretty: `Found 2 expired agents
+
+Agents not purged:
+SPIFFE ID         : spiffe://example.org/spire/agent/agent2
+Error             : rpc error: code = Internal desc = some error when deleting agent
+SPIFFE ID         : spiffe://example.org/spire/agent/agent3
+Error             : rpc error: code = Internal desc = some error when deleting agent
+`,
+			expectedStdoutJSON: fmt.Sprintf(
+				`[{"expired_agents":[
+{"agent_id":"%s","deleted":false,"error":"rpc error: code = Internal desc = some error when deleting agent"},
+{"agent_id":"%s","deleted":false,"error":"rpc error: code = Internal desc = some error when deleting agent"}
+]}]`, agent2ID, agent3ID),
+		},
+		{
+			name:               "error listing agents with the agent API",
+			args:               []string{},
+			existentAgents:     append(activeAgents, expiredAgents...),
+			batchUnimplemented: true,
+			serverErr:          status.Error(codes.Internal, "some error"),
+			expectBatchReqs: []*agentbatchv1.BatchPurgeAgentsRequest{
+				{ExpiredFor: oneMonth, DryRun: true},
+			},
+			expectListReq:      legacyListReq,
+			expectedStderr:     "Error: failed to list agents: rpc error: code = Internal desc = some error\n",
+			expectedReturnCode: 1,
+		},
 	} {
 		for _, format := range availableFormats {
 			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
@@ -691,12 +939,15 @@ SPIFFE ID         : spiffe://example.org/spire/agent/agent3
 				test.server.agents = tt.existentAgents
 				test.server.err = tt.serverErr
 				test.server.deleteErr = tt.deleteErr
+				test.server.batchPurgeUnimplemented = tt.batchUnimplemented
+				test.stdin.WriteString(tt.stdin)
 				args := tt.args
 				args = append(args, "-output", format)
 
 				returnCode := test.client.Run(append(test.args, args...))
 
 				requireOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutPretty, tt.expectedStdoutJSON)
+				spiretest.RequireProtoListEqual(t, tt.expectBatchReqs, test.server.gotBatchPurgeRequests)
 				spiretest.RequireProtoEqual(t, tt.expectListReq, test.server.gotListAgentRequest)
 				spiretest.RequireProtoListEqual(t, tt.expectDeleteReqs, test.server.gotDeleteAgentRequests)
 				require.Contains(t, test.stderr.String(), tt.expectedStderr)
@@ -794,6 +1045,7 @@ func setupTest(t *testing.T, newClient func(*commoncli.Env) cli.Command) *agentT
 
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		agentv1.RegisterAgentServer(s, server)
+		agentbatchv1.RegisterAgentBatchServer(s, server)
 	})
 
 	stdin := new(bytes.Buffer)
@@ -824,12 +1076,20 @@ func setupTest(t *testing.T, newClient func(*commoncli.Env) cli.Command) *agentT
 
 type fakeAgentServer struct {
 	agentv1.UnimplementedAgentServer
+	agentbatchv1.UnimplementedAgentBatchServer
 
 	agents                 []*types.Agent
 	gotListAgentRequest    *agentv1.ListAgentsRequest
 	gotDeleteAgentRequests []*agentv1.DeleteAgentRequest
+	gotBatchBanRequests    []*agentbatchv1.BatchBanAgentsRequest
+	gotBatchEvictRequests  []*agentbatchv1.BatchEvictAgentsRequest
+	gotBatchPurgeRequests  []*agentbatchv1.BatchPurgeAgentsRequest
 	deleteErr              error
 	err                    error
+
+	// batchPurgeUnimplemented makes BatchPurgeAgents behave as on servers
+	// without the RPC.
+	batchPurgeUnimplemented bool
 }
 
 func (s *fakeAgentServer) BanAgent(context.Context, *agentv1.BanAgentRequest) (*emptypb.Empty, error) {
@@ -862,6 +1122,79 @@ func (s *fakeAgentServer) GetAgent(context.Context, *agentv1.GetAgentRequest) (*
 	return nil, s.err
 }
 
+func (s *fakeAgentServer) BatchBanAgents(_ context.Context, req *agentbatchv1.BatchBanAgentsRequest) (*agentbatchv1.BatchBanAgentsResponse, error) {
+	s.gotBatchBanRequests = append(s.gotBatchBanRequests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &agentbatchv1.BatchBanAgentsResponse{
+		Results: s.batchResults(req.Filter, req.Ids, req.DryRun, s.err),
+	}, nil
+}
+
+func (s *fakeAgentServer) BatchEvictAgents(_ context.Context, req *agentbatchv1.BatchEvictAgentsRequest) (*agentbatchv1.BatchEvictAgentsResponse, error) {
+	s.gotBatchEvictRequests = append(s.gotBatchEvictRequests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &agentbatchv1.BatchEvictAgentsResponse{
+		Results: s.batchResults(req.Filter, req.Ids, req.DryRun, s.deleteErr),
+	}, nil
+}
+
+func (s *fakeAgentServer) BatchPurgeAgents(_ context.Context, req *agentbatchv1.BatchPurgeAgentsRequest) (*agentbatchv1.BatchPurgeAgentsResponse, error) {
+	s.gotBatchPurgeRequests = append(s.gotBatchPurgeRequests, req)
+	if s.batchPurgeUnimplemented {
+		return nil, status.Error(codes.Unimplemented, "method BatchPurgeAgents not implemented")
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	filter := &agentbatchv1.AgentFilter{
+		ByAttestationType: req.ByAttestationType,
+		ByCanReattest:     proto.Bool(true),
+		ByExpiresBefore:   time.Now().Add(-time.Duration(req.ExpiredFor) * time.Second).Unix(),
+	}
+	return &agentbatchv1.BatchPurgeAgentsResponse{
+		Results: s.batchResults(filter, req.Ids, req.DryRun, s.deleteErr),
+	}, nil
+}
+
+// batchResults returns the results for the agents matching the filter. Only
+// the attestation type, banned, can re-attest and expiration filters are
+// supported.
+func (s *fakeAgentServer) batchResults(filter *agentbatchv1.AgentFilter, ids []string, dryRun bool, opErr error) []*agentbatchv1.AgentResult {
+	var results []*agentbatchv1.AgentResult
+	for _, agent := range s.agents {
+		switch {
+		case filter.ByAttestationType != "" && agent.AttestationType != filter.ByAttestationType,
+			filter.ByBanned != nil && agent.Banned != *filter.ByBanned,
+			filter.ByCanReattest != nil && agent.CanReattest != *filter.ByCanReattest,
+			filter.ByExpiresBefore != 0 && agent.X509SvidExpiresAt >= filter.ByExpiresBefore:
+			continue
+		}
+
+		id := spiffeid.RequireFromPath(spiffeid.RequireTrustDomainFromString(agent.Id.TrustDomain), agent.Id.Path).String()
+		if ids != nil && !slices.Contains(ids, id) {
+			continue
+		}
+
+		result := &agentbatchv1.AgentResult{
+			Id:                id,
+			AttestationType:   agent.AttestationType,
+			X509SvidExpiresAt: agent.X509SvidExpiresAt,
+			Banned:            agent.Banned,
+			CanReattest:       agent.CanReattest,
+		}
+		if !dryRun {
+			st := status.Convert(opErr)
+			result.StatusCode, result.StatusMessage = int32(st.Code()), st.Message()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 func requireOutputBasedOnFormat(t *testing.T, format, stdoutString string, expectedStdoutPretty, expectedStdoutJSON string) {
 	switch format {
 	case "pretty":
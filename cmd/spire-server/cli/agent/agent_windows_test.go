@@ -4,14 +4,22 @@ package agent_test
 
 var (
 	purgeUsage = `Usage of agent purge:
+  -attestationType string
+    	Select agents by attestation type, like join_token or x509pop.
   -dryRun
     	Indicates that the command will not perform any action, but will print the agents that would be purged.
   -expiredFor duration
     	Amount of time that has passed since the agent's SVID has expired. It is used to determine which agents to purge. (default 720h0m0s)
+  -matchSelectorsOn string
+    	The match mode used when selecting agents by selectors. Options: exact, any, superset and subset (default "superset")
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -selector value
+    	A colon-delimited type:value selector used to select agents. Can be used more than once
+  -yes
+    	Do not ask for confirmation before purging the expired agents
 `
 	listUsage = `Usage of agent list:
   -attestationType string
@@ -32,20 +40,52 @@ var (
     	A colon-delimited type:value selector. Can be used more than once
 `
 	banUsage = `Usage of agent ban:
+  -attestationType string
+    	Select agents by attestation type, like join_token or x509pop.
+  -banned value
+    	Select agents based on string received, 'true': banned agents, 'false': not banned agents, other value will select all.
+  -canReattest value
+    	Select agents based on string received, 'true': agents that can reattest, 'false': agents that can't reattest, other value will select all.
+  -dryRun
+    	Print the agents matching the filter flags without banning them
+  -expiresBefore string
+    	Select agents by expiration time (format: "2006-01-02 15:04:05 -0700 -07")
+  -matchSelectorsOn string
+    	The match mode used when selecting agents by selectors. Options: exact, any, superset and subset (default "superset")
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -selector value
+    	A colon-delimited type:value selector used to select agents. Can be used more than once
   -spiffeID string
     	The SPIFFE ID of the agent to ban (agent identity)
+  -yes
+    	Do not ask for confirmation before banning the agents matching the filter flags
 `
 	evictUsage = `Usage of agent evict:
+  -attestationType string
+    	Select agents by attestation type, like join_token or x509pop.
+  -banned value
+    	Select agents based on string received, 'true': banned agents, 'false': not banned agents, other value will select all.
+  -canReattest value
+    	Select agents based on string received, 'true': agents that can reattest, 'false': agents that can't reattest, other value will select all.
+  -dryRun
+    	Print the agents matching the filter flags without evicting them
+  -expiresBefore string
+    	Select agents by expiration time (format: "2006-01-02 15:04:05 -0700 -07")
+  -matchSelectorsOn string
+    	The match mode used when selecting agents by selectors. Options: exact, any, superset and subset (default "superset")
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -selector value
+    	A colon-delimited type:value selector used to select agents. Can be used more than once
   -spiffeID string
     	The SPIFFE ID of the agent to evict (agent identity)
+  -yes
+    	Do not ask for confirmation before evicting the agents matching the filter flags
 `
 	countUsage = `Usage of agent count:
   -attestationType string
@@ -12,13 +12,18 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/server/api"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
 )
 
 type banCommand struct {
 	env *commoncli.Env
 	// SPIFFE ID of agent being banned
 	spiffeID string
-	printer  cliprinter.Printer
+	// Filters used to select the agents being banned
+	filter  filterFlags
+	dryRun  bool
+	yes     bool
+	printer cliprinter.Printer
 }
 
 // NewBanCommand creates a new "ban" subcommand for "agent" command.
@@ -37,13 +42,22 @@ func (*banCommand) Name() string {
 }
 
 func (*banCommand) Synopsis() string {
-	return "Ban an attested agent given its SPIFFE ID"
+	return "Ban an attested agent given its SPIFFE ID, or the agents matching a filter"
 }
 
-// Run ban an agent given its SPIFFE ID
+// Run ban an agent given its SPIFFE ID, or the agents matching the filter flags
 func (c *banCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
 	if c.spiffeID == "" {
-		return errors.New("a SPIFFE ID is required")
+		if !c.filter.isSet() {
+			return errors.New("a SPIFFE ID or at least one filter flag is required")
+		}
+		return c.runBatch(ctx, serverClient)
+	}
+	if c.filter.isSet() {
+		return errors.New("-spiffeID cannot be combined with filter flags")
+	}
+	if c.dryRun || c.yes {
+		return errors.New("-dryRun and -yes require filter flags")
 	}
 
 	id, err := spiffeid.FromString(c.spiffeID)
@@ -62,12 +76,44 @@ func (c *banCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient uti
 	return c.printer.PrintProto(banResponse)
 }
 
+// runBatch bans the agents matching the filter flags
+func (c *banCommand) runBatch(ctx context.Context, serverClient util.ServerClient) error {
+	filter, err := c.filter.toProto()
+	if err != nil {
+		return err
+	}
+
+	batchClient := serverClient.NewAgentBatchClient()
+	results, err := runBatch(ctx, c.env, "Ban", c.dryRun, c.yes, func(ctx context.Context, ids []string, dryRun bool) ([]*agentbatchv1.AgentResult, error) {
+		resp, err := batchClient.BatchBanAgents(ctx, &agentbatchv1.BatchBanAgentsRequest{
+			Filter: filter,
+			Ids:    ids,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Results, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.printer.PrintProto(&agentbatchv1.BatchBanAgentsResponse{Results: results})
+}
+
 func (c *banCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent to ban (agent identity)")
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintBanResult)
+	c.filter.appendFlags(fs)
+	fs.BoolVar(&c.dryRun, "dryRun", false, "Print the agents matching the filter flags without banning them")
+	fs.BoolVar(&c.yes, "yes", false, "Do not ask for confirmation before banning the agents matching the filter flags")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintBanResult)
 }
 
-func prettyPrintBanResult(env *commoncli.Env, _ ...any) error {
+func (c *banCommand) prettyPrintBanResult(env *commoncli.Env, results ...any) error {
+	if resp, ok := results[0].(*agentbatchv1.BatchBanAgentsResponse); ok {
+		return prettyPrintBatchResults(env, resp.Results, c.dryRun, "banned")
+	}
 	env.Println("Agent banned successfully")
 	return nil
 }
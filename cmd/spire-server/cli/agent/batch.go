@@ -0,0 +1,210 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// filterFlags are the flags used to select the agents of batch operations.
+// They are the same as the filters of the "agent list" command.
+type filterFlags struct {
+	// Type and value are delimited by a colon (:)
+	// ex. "aws_iid:tag:pool:blue"
+	selectors commoncli.StringsFlag
+
+	// Match used when filtering by selectors
+	matchSelectorsOn string
+
+	// Filters agents to those that are banned.
+	banned commoncli.BoolFlag
+
+	// Filters agents by those that expire before this value.
+	expiresBefore string
+
+	// Filters agents to those matching the attestation type.
+	attestationType string
+
+	// Filters agents that can re-attest.
+	canReattest commoncli.BoolFlag
+}
+
+func (f *filterFlags) appendFlags(fs *flag.FlagSet) {
+	f.appendSelectorFlags(fs)
+	fs.Var(&f.canReattest, "canReattest", "Select agents based on string received, 'true': agents that can reattest, 'false': agents that can't reattest, other value will select all.")
+	fs.Var(&f.banned, "banned", "Select agents based on string received, 'true': banned agents, 'false': not banned agents, other value will select all.")
+	fs.StringVar(&f.expiresBefore, "expiresBefore", "", "Select agents by expiration time (format: \"2006-01-02 15:04:05 -0700 -07\")")
+}
+
+// appendSelectorFlags only appends the flags selecting agents by selectors
+// and attestation type.
+func (f *filterFlags) appendSelectorFlags(fs *flag.FlagSet) {
+	fs.Var(&f.selectors, "selector", "A colon-delimited type:value selector used to select agents. Can be used more than once")
+	fs.StringVar(&f.attestationType, "attestationType", "", "Select agents by attestation type, like join_token or x509pop.")
+	fs.StringVar(&f.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when selecting agents by selectors. Options: exact, any, superset and subset")
+}
+
+func (f *filterFlags) isSet() bool {
+	return len(f.selectors) > 0 || f.attestationType != "" || f.canReattest != commoncli.BoolFlagAll ||
+		f.banned != commoncli.BoolFlagAll || f.expiresBefore != ""
+}
+
+func (f *filterFlags) toProto() (*agentbatchv1.AgentFilter, error) {
+	filter := &agentbatchv1.AgentFilter{
+		ByAttestationType: f.attestationType,
+	}
+
+	if len(f.selectors) > 0 {
+		matchBehavior, err := parseToSelectorMatch(f.matchSelectorsOn)
+		if err != nil {
+			return nil, err
+		}
+
+		selectors := make([]*agentbatchv1.Selector, len(f.selectors))
+		for i, sel := range f.selectors {
+			selector, err := util.ParseSelector(sel)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing selector %q: %w", sel, err)
+			}
+			selectors[i] = &agentbatchv1.Selector{Type: selector.Type, Value: selector.Value}
+		}
+		filter.BySelectorMatch = &agentbatchv1.SelectorMatch{
+			Selectors: selectors,
+			Match:     agentbatchv1.SelectorMatch_MatchBehavior(matchBehavior),
+		}
+	}
+
+	if f.expiresBefore != "" {
+		expiresBefore, err := time.Parse("2006-01-02 15:04:05 -0700 -07", f.expiresBefore)
+		if err != nil {
+			return nil, fmt.Errorf("date is not valid: %w", err)
+		}
+		filter.ByExpiresBefore = expiresBefore.Unix()
+	}
+
+	// 0: all, 1: can't reattest, 2: can reattest
+	switch f.canReattest {
+	case commoncli.BoolFlagFalse:
+		filter.ByCanReattest = proto.Bool(false)
+	case commoncli.BoolFlagTrue:
+		filter.ByCanReattest = proto.Bool(true)
+	}
+
+	// 0: all, 1: no-banned, 2: banned
+	switch f.banned {
+	case commoncli.BoolFlagFalse:
+		filter.ByBanned = proto.Bool(false)
+	case commoncli.BoolFlagTrue:
+		filter.ByBanned = proto.Bool(true)
+	}
+
+	return filter, nil
+}
+
+// batchFunc runs a batch operation, optionally restricted to the given agent
+// IDs.
+type batchFunc func(ctx context.Context, ids []string, dryRun bool) ([]*agentbatchv1.AgentResult, error)
+
+// runBatch previews the agents matching the filter and, unless it is a dry
+// run, asks for confirmation before running the operation on exactly the
+// previewed agents. The preview and the prompt are written to stderr so that
+// the output remains parsable. The confirmation is skipped when yes is true.
+func runBatch(ctx context.Context, env *commoncli.Env, action string, dryRun, yes bool, run batchFunc) ([]*agentbatchv1.AgentResult, error) {
+	preview, err := run(ctx, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun || len(preview) == 0 {
+		return preview, nil
+	}
+
+	ids := make([]string, 0, len(preview))
+	for _, result := range preview {
+		ids = append(ids, result.Id)
+	}
+	if !yes {
+		if err := confirm(env, "matching", action, ids); err != nil {
+			return nil, err
+		}
+	}
+	return run(ctx, ids, false)
+}
+
+// confirm lists the agents an operation is about to act on and asks for
+// confirmation before running it. The list and the prompt are written to
+// stderr. Anything other than "y" or "yes", including no answer at all, is a
+// refusal.
+func confirm(env *commoncli.Env, kind, action string, ids []string) error {
+	msg := fmt.Sprintf("Found %d %s ", len(ids), kind)
+	msg = util.Pluralizer(msg, "agent", "agents", len(ids))
+	_ = env.ErrPrintf("%s:\n\n", msg)
+	for _, id := range ids {
+		_ = env.ErrPrintf("SPIFFE ID         : %s\n", id)
+	}
+	_ = env.ErrPrintf("\n%s %d %s? [y/N]: ", action, len(ids), util.Pluralizer("", "agent", "agents", len(ids)))
+
+	answer, _ := bufio.NewReader(env.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return errors.New("operation canceled")
+	}
+}
+
+// prettyPrintBatchResults prints the results of a batch operation. Dry run
+// results list the agents that the operation would act on.
+func prettyPrintBatchResults(env *commoncli.Env, results []*agentbatchv1.AgentResult, dryRun bool, pastAction string) error {
+	if len(results) == 0 {
+		return env.Println("No agents matched the filter")
+	}
+
+	var succeeded, failed []*agentbatchv1.AgentResult
+	for _, result := range results {
+		if codes.Code(result.StatusCode) == codes.OK {
+			succeeded = append(succeeded, result)
+		} else {
+			failed = append(failed, result)
+		}
+	}
+
+	if dryRun {
+		msg := fmt.Sprintf("Found %d matching ", len(succeeded))
+		msg = util.Pluralizer(msg, "agent", "agents", len(succeeded))
+		env.Printf("%s:\n\n", msg)
+		for _, result := range succeeded {
+			env.Printf("SPIFFE ID         : %s\n", result.Id)
+			env.Printf("Attestation type  : %s\n", result.AttestationType)
+			env.Printf("Expiration time   : %s\n", time.Unix(result.X509SvidExpiresAt, 0))
+			env.Printf("Banned            : %t\n", result.Banned)
+			env.Printf("Can re-attest     : %t\n", result.CanReattest)
+			env.Println()
+		}
+		return nil
+	}
+
+	if len(succeeded) > 0 {
+		env.Printf("Agents %s:\n", pastAction)
+		for _, result := range succeeded {
+			env.Printf("SPIFFE ID         : %s\n", result.Id)
+		}
+	}
+	if len(failed) > 0 {
+		env.Printf("Agents not %s:\n", pastAction)
+		for _, result := range failed {
+			env.Printf("SPIFFE ID         : %s\n", result.Id)
+			env.Printf("Error             : %s\n", result.StatusMessage)
+		}
+	}
+	return nil
+}
@@ -12,13 +12,18 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/server/api"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
 )
 
 type evictCommand struct {
 	env *commoncli.Env
 	// SPIFFE ID of the agent being evicted
 	spiffeID string
-	printer  cliprinter.Printer
+	// Filters used to select the agents being evicted
+	filter  filterFlags
+	dryRun  bool
+	yes     bool
+	printer cliprinter.Printer
 }
 
 // NewEvictCommand creates a new "evict" subcommand for "agent" command.
@@ -37,13 +42,22 @@ func (*evictCommand) Name() string {
 }
 
 func (*evictCommand) Synopsis() string {
-	return "Evicts an attested agent given its SPIFFE ID"
+	return "Evicts an attested agent given its SPIFFE ID, or the agents matching a filter"
 }
 
-// Run evicts an agent given its SPIFFE ID
+// Run evicts an agent given its SPIFFE ID, or the agents matching the filter flags
 func (c *evictCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
 	if c.spiffeID == "" {
-		return errors.New("a SPIFFE ID is required")
+		if !c.filter.isSet() {
+			return errors.New("a SPIFFE ID or at least one filter flag is required")
+		}
+		return c.runBatch(ctx, serverClient)
+	}
+	if c.filter.isSet() {
+		return errors.New("-spiffeID cannot be combined with filter flags")
+	}
+	if c.dryRun || c.yes {
+		return errors.New("-dryRun and -yes require filter flags")
 	}
 
 	id, err := spiffeid.FromString(c.spiffeID)
@@ -60,12 +74,44 @@ func (c *evictCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient u
 	return c.printer.PrintProto(delAgentResponse)
 }
 
+// runBatch evicts the agents matching the filter flags
+func (c *evictCommand) runBatch(ctx context.Context, serverClient util.ServerClient) error {
+	filter, err := c.filter.toProto()
+	if err != nil {
+		return err
+	}
+
+	batchClient := serverClient.NewAgentBatchClient()
+	results, err := runBatch(ctx, c.env, "Evict", c.dryRun, c.yes, func(ctx context.Context, ids []string, dryRun bool) ([]*agentbatchv1.AgentResult, error) {
+		resp, err := batchClient.BatchEvictAgents(ctx, &agentbatchv1.BatchEvictAgentsRequest{
+			Filter: filter,
+			Ids:    ids,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Results, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.printer.PrintProto(&agentbatchv1.BatchEvictAgentsResponse{Results: results})
+}
+
 func (c *evictCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent to evict (agent identity)")
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintEvictResult)
+	c.filter.appendFlags(fs)
+	fs.BoolVar(&c.dryRun, "dryRun", false, "Print the agents matching the filter flags without evicting them")
+	fs.BoolVar(&c.yes, "yes", false, "Do not ask for confirmation before evicting the agents matching the filter flags")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintEvictResult)
 }
 
-func prettyPrintEvictResult(env *commoncli.Env, _ ...any) error {
+func (c *evictCommand) prettyPrintEvictResult(env *commoncli.Env, results ...any) error {
+	if resp, ok := results[0].(*agentbatchv1.BatchEvictAgentsResponse); ok {
+		return prettyPrintBatchResults(env, resp.Results, c.dryRun, "evicted")
+	}
 	env.Println("Agent evicted successfully")
 	return nil
 }
@@ -14,6 +14,9 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/idutil"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -21,7 +24,10 @@ type purgeCommand struct {
 	env        *commoncli.Env
 	expiredFor time.Duration
 	dryRun     bool
-	printer    cliprinter.Printer
+	yes        bool
+	// Filters used to restrict the expired agents being purged
+	filter  filterFlags
+	printer cliprinter.Printer
 }
 
 func NewPurgeCommand() cli.Command {
@@ -40,20 +46,77 @@ func (*purgeCommand) Synopsis() string {
 	return "Purge expired agents that were attested using a non-TOFU security model based on a given time"
 }
 
-func (c *purgeCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) (err error) {
+func (c *purgeCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	// Only the selector and attestation type flags are registered, so the
+	// remaining criteria of the filter are unset.
+	filter, err := c.filter.toProto()
+	if err != nil {
+		return err
+	}
+
+	batchClient := serverClient.NewAgentBatchClient()
+	results, err := runBatch(ctx, c.env, "Purge", c.dryRun, c.yes, func(ctx context.Context, ids []string, dryRun bool) ([]*agentbatchv1.AgentResult, error) {
+		resp, err := batchClient.BatchPurgeAgents(ctx, &agentbatchv1.BatchPurgeAgentsRequest{
+			ExpiredFor:        int64(c.expiredFor / time.Second),
+			BySelectorMatch:   filter.BySelectorMatch,
+			ByAttestationType: filter.ByAttestationType,
+			Ids:               ids,
+			DryRun:            dryRun,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge agents: %w", err)
+		}
+		return resp.Results, nil
+	})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		// The server does not implement the BatchPurgeAgents RPC
+		return c.runWithAgentAPI(ctx, serverClient)
+	case err != nil:
+		return err
+	}
+
+	expiredAgents := &expiredAgents{Agents: []*expiredAgent{}}
+	for _, result := range results {
+		id, err := spiffeid.FromString(result.Id)
+		if err != nil {
+			return err
+		}
+		agent := &expiredAgent{AgentID: id}
+		if !c.dryRun {
+			if codes.Code(result.StatusCode) == codes.OK {
+				agent.Deleted = true
+			} else {
+				agent.Error = result.StatusMessage
+			}
+		}
+		expiredAgents.Agents = append(expiredAgents.Agents, agent)
+	}
+
+	return c.printer.PrintStruct(expiredAgents)
+}
+
+// runWithAgentAPI purges the expired agents by listing and deleting them
+// one at a time through the agent API. It is only used with servers that do
+// not implement the BatchPurgeAgents RPC.
+func (c *purgeCommand) runWithAgentAPI(ctx context.Context, serverClient util.ServerClient) error {
+	filter, err := c.listFilter()
+	if err != nil {
+		return err
+	}
+
 	agentClient := serverClient.NewAgentClient()
 	resp, err := agentClient.ListAgents(ctx, &agentv1.ListAgentsRequest{
-		Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
+		Filter:     filter,
 		OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to list agents: %w", err)
 	}
 
-	agents := resp.GetAgents()
 	expiredAgents := &expiredAgents{Agents: []*expiredAgent{}}
-
-	for _, agent := range agents {
+	var expired []*types.Agent
+	for _, agent := range resp.GetAgents() {
 		id, err := idutil.IDFromProto(agent.Id)
 		if err != nil {
 			return err
@@ -62,25 +125,71 @@ func (c *purgeCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient u
 		expirationTime := time.Unix(agent.X509SvidExpiresAt, 0)
 
 		if time.Since(expirationTime) > c.expiredFor {
-			result := &expiredAgent{AgentID: id}
-
-			if !c.dryRun {
-				if _, err := agentClient.DeleteAgent(ctx, &agentv1.DeleteAgentRequest{Id: agent.Id}); err != nil {
-					result.Error = err.Error()
-				} else {
-					result.Deleted = true
-				}
+			expiredAgents.Agents = append(expiredAgents.Agents, &expiredAgent{AgentID: id})
+			expired = append(expired, agent)
+		}
+	}
+
+	if !c.yes && !c.dryRun && len(expired) > 0 {
+		ids := make([]string, 0, len(expiredAgents.Agents))
+		for _, result := range expiredAgents.Agents {
+			ids = append(ids, result.AgentID.String())
+		}
+		if err := confirm(c.env, "matching", "Purge", ids); err != nil {
+			return err
+		}
+	}
+
+	if !c.dryRun {
+		for i, agent := range expired {
+			result := expiredAgents.Agents[i]
+			if _, err := agentClient.DeleteAgent(ctx, &agentv1.DeleteAgentRequest{Id: agent.Id}); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Deleted = true
 			}
-			expiredAgents.Agents = append(expiredAgents.Agents, result)
 		}
 	}
 
 	return c.printer.PrintStruct(expiredAgents)
 }
 
+// listFilter returns the filter used to list the agents that can be purged,
+// restricted by the selector and attestation type flags.
+func (c *purgeCommand) listFilter() (*agentv1.ListAgentsRequest_Filter, error) {
+	filter := &agentv1.ListAgentsRequest_Filter{
+		ByCanReattest:     wrapperspb.Bool(true),
+		ByAttestationType: c.filter.attestationType,
+	}
+
+	if len(c.filter.selectors) > 0 {
+		matchBehavior, err := parseToSelectorMatch(c.filter.matchSelectorsOn)
+		if err != nil {
+			return nil, err
+		}
+
+		selectors := make([]*types.Selector, len(c.filter.selectors))
+		for i, sel := range c.filter.selectors {
+			selector, err := util.ParseSelector(sel)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing selector %q: %w", sel, err)
+			}
+			selectors[i] = selector
+		}
+		filter.BySelectorMatch = &types.SelectorMatch{
+			Selectors: selectors,
+			Match:     matchBehavior,
+		}
+	}
+
+	return filter, nil
+}
+
 func (c *purgeCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.DurationVar(&c.expiredFor, "expiredFor", 30*24*time.Hour, "Amount of time that has passed since the agent's SVID has expired. It is used to determine which agents to purge.")
 	fs.BoolVar(&c.dryRun, "dryRun", false, "Indicates that the command will not perform any action, but will print the agents that would be purged.")
+	fs.BoolVar(&c.yes, "yes", false, "Do not ask for confirmation before purging the expired agents")
+	c.filter.appendSelectorFlags(fs)
 
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintPurgeResult)
 }
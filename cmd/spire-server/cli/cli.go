@@ -7,6 +7,7 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	"github.com/spiffe/spire/cmd/spire-server/cli/bundle"
+	"github.com/spiffe/spire/cmd/spire-server/cli/datastore"
 	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
 	"github.com/spiffe/spire/cmd/spire-server/cli/federation"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
@@ -82,6 +83,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"entry show": func() (cli.Command, error) {
 			return entry.NewShowCommand(), nil
 		},
+		"entry sync": func() (cli.Command, error) {
+			return entry.NewSyncCommand(), nil
+		},
 		"federation create": func() (cli.Command, error) {
 			return federation.NewCreateCommand(), nil
 		},
@@ -121,12 +125,21 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"x509 mint": func() (cli.Command, error) {
 			return x509.NewMintCommand(), nil
 		},
+		"x509 revoke": func() (cli.Command, error) {
+			return x509.NewRevokeCommand(), nil
+		},
 		"jwt mint": func() (cli.Command, error) {
 			return jwt.NewMintCommand(), nil
 		},
 		"validate": func() (cli.Command, error) {
 			return validate.NewValidateCommand(), nil
 		},
+		"datastore export": func() (cli.Command, error) {
+			return datastore.NewExportCommand(), nil
+		},
+		"datastore import": func() (cli.Command, error) {
+			return datastore.NewImportCommand(), nil
+		},
 		"localauthority x509 show": func() (cli.Command, error) {
 			return localauthority_x509.NewX509ShowCommand(), nil
 		},
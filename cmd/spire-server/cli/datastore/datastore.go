@@ -0,0 +1,80 @@
+// Package datastore implements the commands that export the contents of the
+// DataStore to an archive and import them back, for backup and migration
+// between database dialects. The commands operate on the DataStore directly
+// and are intended to be run while the server is stopped.
+package datastore
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/log"
+	"github.com/spiffe/spire/pkg/server"
+	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+)
+
+// configFlags are the flags used to locate the server configuration, from
+// which the DataStore configuration is read.
+type configFlags struct {
+	configPath string
+	expandEnv  bool
+}
+
+func (f *configFlags) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.configPath, "config", "", "Path to a SPIRE server configuration file")
+	fs.BoolVar(&f.expandEnv, "expandEnv", false, "Expand environment variables in SPIRE server configuration file")
+}
+
+// loadDataStore loads the server configuration and the DataStore it
+// configures. Logs are written to stderr so they are kept apart from an
+// archive written to stdout.
+func (f *configFlags) loadDataStore(ctx context.Context, name string, env *commoncli.Env) (*server.Config, catalog.DataStore, error) {
+	var args []string
+	if f.configPath != "" {
+		args = append(args, "-config", f.configPath)
+	}
+	if f.expandEnv {
+		args = append(args, "-expandEnv")
+	}
+
+	logOptions := []log.Option{
+		func(logger *log.Logger) error {
+			logger.SetOutput(env.Stderr)
+			return nil
+		},
+	}
+	config, err := run.LoadConfig(name, args, logOptions, env.Stderr, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load server configuration: %w", err)
+	}
+
+	ds, err := catalog.LoadDataStore(ctx, catalog.Config{
+		Log:           config.Log,
+		TrustDomain:   config.TrustDomain,
+		PluginConfigs: config.PluginConfigs,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load DataStore: %w", err)
+	}
+	return config, ds, nil
+}
+
+// printStats prints the number of records of each kind that were exported or
+// imported.
+func printStats(w io.Writer, action string, stats *archive.Stats) error {
+	_, err := fmt.Fprintf(w, `%s:
+  Bundles:                  %d
+  Federation relationships: %d
+  Registration entries:     %d
+  Attested nodes:           %d
+  Join tokens:              %d
+  CA journals:              %d
+  Revoked X509-SVIDs:       %d
+`, action, stats.Bundles, stats.FederationRelationships, stats.RegistrationEntries, stats.AttestedNodes, stats.JoinTokens, stats.CAJournals, stats.RevokedX509SVIDs)
+	return err
+}
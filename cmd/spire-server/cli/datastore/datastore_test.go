@@ -0,0 +1,143 @@
+package datastore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	archiveRecords = `{"kind":"bundle","data":{"trustDomainId":"spiffe://example.org","rootCas":[{"derBytes":"cm9vdA=="}]}}
+{"kind":"join_token","data":{"token":"token","expiry":4102444800}}
+`
+
+	serverConfig = `
+server {
+	trust_domain = "example.org"
+	data_dir = %q
+}
+
+plugins {
+	DataStore "sql" {
+		plugin_data {
+			database_type = "sqlite3"
+			connection_string = %q
+		}
+	}
+}
+`
+)
+
+func TestExportImport(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	srcConfig := writeServerConfig(t, dir, "src")
+	dstConfig := writeServerConfig(t, dir, "dst")
+	archivePath := filepath.Join(dir, "archive.ndjson")
+
+	// Seed the source DataStore from stdin
+	stdin := strings.NewReader(`{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}` + "\n" + archiveRecords)
+	stdout, stderr, code := runCommand(newImportCommand, stdin, "-config", srcConfig)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Imported:\n  Bundles:                  1\n")
+	assert.Contains(t, stdout, "  Join tokens:              1\n")
+
+	// Export to stdout; the summary goes to stderr
+	stdout, stderr, code = runCommand(newExportCommand, nil, "-config", srcConfig)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, archiveRecords)
+	assert.Contains(t, stderr, "Exported:\n  Bundles:                  1\n")
+
+	// Export to a file, import it into the destination DataStore and export
+	// it again
+	stdout, stderr, code = runCommand(newExportCommand, nil, "-config", srcConfig, "-output", archivePath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Exported:\n")
+	info, err := os.Stat(archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	stdout, stderr, code = runCommand(newImportCommand, nil, "-config", dstConfig, "-input", archivePath)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "Imported:\n")
+
+	stdout, stderr, code = runCommand(newExportCommand, nil, "-config", dstConfig)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, archiveRecords)
+
+	// Importing again fails since the records already exist
+	_, stderr, code = runCommand(newImportCommand, nil, "-config", dstConfig, "-input", archivePath)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "Error: join token #1 already exists\n")
+}
+
+func TestCommandErrors(t *testing.T) {
+	dir := spiretest.TempDir(t)
+
+	_, stderr, code := runCommand(newExportCommand, nil, "-badflag")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "flag provided but not defined: -badflag")
+
+	_, stderr, code = runCommand(newExportCommand, nil, "-config", filepath.Join(dir, "missing.conf"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "Error: failed to load server configuration: ")
+
+	_, stderr, code = runCommand(newImportCommand, nil, "-input", filepath.Join(dir, "missing.ndjson"))
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "Error: failed to open archive: ")
+}
+
+func TestHelp(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	cmd := newExportCommand(&commoncli.Env{Stderr: stderr})
+	assert.Equal(t, "flag: help requested", cmd.Help())
+	assert.Contains(t, stderr.String(), "Usage of datastore export:")
+	assert.Contains(t, stderr.String(), "-output")
+	assert.Equal(t, "Exports the contents of the DataStore to an archive", cmd.Synopsis())
+
+	stderr.Reset()
+	importCmd := newImportCommand(&commoncli.Env{Stderr: stderr})
+	assert.Equal(t, "flag: help requested", importCmd.Help())
+	assert.Contains(t, stderr.String(), "Usage of datastore import:")
+	assert.Contains(t, stderr.String(), "-input")
+	assert.Equal(t, "Imports the contents of an archive into an empty DataStore", importCmd.Synopsis())
+}
+
+type command interface {
+	Run(args []string) int
+}
+
+func runCommand[T command](newCommand func(*commoncli.Env) T, stdin *strings.Reader, args ...string) (string, string, int) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	env := &commoncli.Env{
+		Stdin:  strings.NewReader(""),
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+	if stdin != nil {
+		env.Stdin = stdin
+	}
+	code := newCommand(env).Run(args)
+	// Feature flags are loaded along with the server configuration and can
+	// only be loaded once
+	_ = fflag.Unload()
+	return stdout.String(), stderr.String(), code
+}
+
+func writeServerConfig(t *testing.T, dir, name string) string {
+	dataDir := filepath.Join(dir, name)
+	require.NoError(t, os.Mkdir(dataDir, 0755))
+	path := filepath.Join(dir, name+".conf")
+	config := fmt.Sprintf(serverConfig, dataDir, filepath.Join(dataDir, "datastore.sqlite3"))
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+	return path
+}
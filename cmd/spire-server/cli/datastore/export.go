@@ -0,0 +1,98 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+)
+
+const exportCommandName = "datastore export"
+
+func NewExportCommand() cli.Command {
+	return newExportCommand(commoncli.DefaultEnv)
+}
+
+func newExportCommand(env *commoncli.Env) *exportCommand {
+	return &exportCommand{
+		env: env,
+	}
+}
+
+type exportCommand struct {
+	env *commoncli.Env
+	configFlags
+
+	// output is the path of the file the archive is written to. If empty,
+	// the archive is written to stdout.
+	output string
+}
+
+// Help prints the command usage
+func (c *exportCommand) Help() string {
+	err := c.parseFlags([]string{"-h"})
+	// Error is always present because -h is passed
+	return err.Error()
+}
+
+func (c *exportCommand) Synopsis() string {
+	return "Exports the contents of the DataStore to an archive"
+}
+
+func (c *exportCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 1
+	}
+	if err := c.run(context.Background()); err != nil {
+		_ = c.env.ErrPrintf("Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *exportCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(exportCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	c.appendFlags(fs)
+	fs.StringVar(&c.output, "output", "", "Path of the file to write the archive to. Writes to stdout if unset")
+	return fs.Parse(args)
+}
+
+func (c *exportCommand) run(ctx context.Context) (err error) {
+	config, ds, err := c.loadDataStore(ctx, exportCommandName, c.env)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	// The summary is written to stderr when the archive goes to stdout.
+	w, summary := c.env.Stdout, c.env.Stderr
+	if c.output != "" {
+		// The archive holds sensitive material (e.g. join tokens and CA
+		// journals) so it is only readable by the owner.
+		f, err := os.OpenFile(c.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close archive: %w", closeErr)
+			}
+		}()
+		w, summary = f, c.env.Stdout
+	}
+
+	stats, err := archive.Export(ctx, ds, config.TrustDomain, w)
+	if err != nil {
+		return err
+	}
+	return printStats(summary, "Exported", stats)
+}
@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mitchellh/cli"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+)
+
+const importCommandName = "datastore import"
+
+func NewImportCommand() cli.Command {
+	return newImportCommand(commoncli.DefaultEnv)
+}
+
+func newImportCommand(env *commoncli.Env) *importCommand {
+	return &importCommand{
+		env: env,
+	}
+}
+
+type importCommand struct {
+	env *commoncli.Env
+	configFlags
+
+	// input is the path of the file the archive is read from. If empty, the
+	// archive is read from stdin.
+	input string
+}
+
+// Help prints the command usage
+func (c *importCommand) Help() string {
+	err := c.parseFlags([]string{"-h"})
+	// Error is always present because -h is passed
+	return err.Error()
+}
+
+func (c *importCommand) Synopsis() string {
+	return "Imports the contents of an archive into an empty DataStore"
+}
+
+func (c *importCommand) Run(args []string) int {
+	if err := c.parseFlags(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 1
+	}
+	if err := c.run(context.Background()); err != nil {
+		_ = c.env.ErrPrintf("Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (c *importCommand) parseFlags(args []string) error {
+	fs := flag.NewFlagSet(importCommandName, flag.ContinueOnError)
+	fs.SetOutput(c.env.Stderr)
+	c.appendFlags(fs)
+	fs.StringVar(&c.input, "input", "", "Path of the file to read the archive from. Reads from stdin if unset")
+	return fs.Parse(args)
+}
+
+func (c *importCommand) run(ctx context.Context) error {
+	r := c.env.Stdin
+	if c.input != "" {
+		f, err := os.Open(c.input)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	config, ds, err := c.loadDataStore(ctx, importCommandName, c.env)
+	if err != nil {
+		return err
+	}
+	defer ds.Close()
+
+	stats, err := archive.Import(ctx, ds, config.TrustDomain, r)
+	if err != nil {
+		return err
+	}
+	return printStats(c.env.Stdout, "Imported", stats)
+}
@@ -74,6 +74,9 @@ type createCommand struct {
 	// storeSVID determines if the issued SVID must be stored through an SVIDStore plugin
 	storeSVID bool
 
+	// dryRun reports the changes that would be made without applying them
+	dryRun bool
+
 	printer cliprinter.Printer
 
 	env *commoncli.Env
@@ -90,7 +93,7 @@ func (*createCommand) Synopsis() string {
 func (c *createCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "A custom ID for this registration entry (optional). If not set, a new entry ID will be generated")
 	f.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of this record's parent")
-	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents")
+	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents. May be a template with {{<type>:<key>}} placeholders expanded from the node selectors of the agent and the entry selectors")
 	f.IntVar(&c.x509SVIDTTL, "x509SVIDTTL", 0, "The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.")
 	f.IntVar(&c.jwtSVIDTTL, "jwtSVIDTTL", 0, "The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.")
 	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.")
@@ -103,6 +106,7 @@ func (c *createCommand) AppendFlags(f *flag.FlagSet) {
 	f.Int64Var(&c.entryExpiry, "entryExpiry", 0, "An expiry, from epoch in seconds, for the resulting registration entry to be pruned")
 	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once")
 	f.StringVar(&c.hint, "hint", "", "The entry hint, used to disambiguate entries with the same SPIFFE ID")
+	f.BoolVar(&c.dryRun, "dryRun", false, dryRunUsage)
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintCreate)
 }
 
@@ -122,6 +126,14 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return err
 	}
 
+	if c.dryRun {
+		resp, err := dryRunCreateEntries(ctx, serverClient.NewEntryDryRunClient(), entries)
+		if err != nil {
+			return err
+		}
+		return c.printer.PrintProto(resp)
+	}
+
 	resp, err := createEntries(ctx, serverClient.NewEntryClient(), entries)
 	if err != nil {
 		return err
@@ -167,7 +179,7 @@ func (c *createCommand) validate() (err error) {
 
 // parseConfig builds a registration entry from the given config
 func (c *createCommand) parseConfig() ([]*types.Entry, error) {
-	spiffeID, err := idStringToProto(c.spiffeID)
+	spiffeID, err := entrySPIFFEIDStringToProto(c.spiffeID)
 	if err != nil {
 		return nil, err
 	}
@@ -245,6 +257,10 @@ func getParentID(config *createCommand, td string) (*types.SPIFFEID, error) {
 }
 
 func prettyPrintCreate(env *commoncli.Env, results ...any) error {
+	if resp, ok := results[0].(dryRunResponse); ok {
+		return prettyPrintDryRun(env, resp)
+	}
+
 	var succeeded, failed []*entryv1.BatchCreateEntryResponse_Result
 	createResp, ok := results[0].(*entryv1.BatchCreateEntryResponse)
 	if !ok {
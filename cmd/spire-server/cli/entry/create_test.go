@@ -8,6 +8,7 @@ import (
 
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 )
@@ -698,3 +699,105 @@ Error: failed to create one or more entries
 		}
 	}
 }
+
+func TestCreateDryRun(t *testing.T) {
+	resp := &entrydryrunv1.DryRunBatchCreateEntryResponse{
+		Results: []*entrydryrunv1.EntryResult{
+			{
+				Status:   &types.Status{},
+				Action:   entrydryrunv1.Action_ACTION_CREATE,
+				SpiffeId: "spiffe://example.org/workload",
+				Diffs: []*entrydryrunv1.FieldDiff{
+					{Field: "spiffe_id", New: "spiffe://example.org/workload"},
+					{Field: "parent_id", New: "spiffe://example.org/parent"},
+					{Field: "selectors", New: "[unix:uid:1000]"},
+				},
+			},
+		},
+		AuthorizationChanges: []*entrydryrunv1.AuthorizationChange{
+			{
+				SpiffeId:     "spiffe://example.org/workload",
+				AgentsGained: []string{"spiffe://example.org/parent"},
+			},
+		},
+	}
+
+	expReq := &entrydryrunv1.DryRunBatchCreateEntryRequest{
+		Entries: []*types.Entry{
+			{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+		},
+	}
+
+	args := []string{
+		"-dryRun",
+		"-spiffeID", "spiffe://example.org/workload",
+		"-parentID", "spiffe://example.org/parent",
+		"-selector", "unix:uid:1000",
+	}
+
+	t.Run("pretty", func(t *testing.T) {
+		test := setupTest(t, newCreateCommand)
+		test.server.expDryRunBatchCreateEntryReq = expReq
+		test.server.dryRunBatchCreateEntryResp = resp
+
+		rc := test.client.Run(test.args(args...))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Equal(t, `Would create   : (none) (spiffe://example.org/workload)
+  spiffe_id     : (none) -> spiffe://example.org/workload
+  parent_id     : (none) -> spiffe://example.org/parent
+  selectors     : (none) -> [unix:uid:1000]
+
+Agent authorization changes:
+(none) (spiffe://example.org/workload)
+  + spiffe://example.org/parent
+
+Dry run: no changes were made.
+`, test.stdout.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		test := setupTest(t, newCreateCommand)
+		test.server.expDryRunBatchCreateEntryReq = expReq
+		test.server.dryRunBatchCreateEntryResp = resp
+
+		rc := test.client.Run(test.args(append(args, "-output", "json")...))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.JSONEq(t, `{
+  "results": [
+    {
+      "status": {"code": 0, "message": ""},
+      "action": "ACTION_CREATE",
+      "entry_id": "",
+      "spiffe_id": "spiffe://example.org/workload",
+      "diffs": [
+        {"field": "spiffe_id", "old": "", "new": "spiffe://example.org/workload"},
+        {"field": "parent_id", "old": "", "new": "spiffe://example.org/parent"},
+        {"field": "selectors", "old": "", "new": "[unix:uid:1000]"}
+      ]
+    }
+  ],
+  "authorization_changes": [
+    {
+      "entry_id": "",
+      "spiffe_id": "spiffe://example.org/workload",
+      "agents_gained": ["spiffe://example.org/parent"],
+      "agents_lost": []
+    }
+  ]
+}`, test.stdout.String())
+	})
+
+	t.Run("server without dry-run support", func(t *testing.T) {
+		// The entries must not be created instead: the fake server fails
+		// the test if they are.
+		test := setupTest(t, newCreateCommand)
+
+		rc := test.client.Run(test.args(args...))
+		require.Equal(t, 1, rc)
+		require.Equal(t, "Error: server does not support dry-run requests; no changes were made\n", test.stderr.String())
+	})
+}
@@ -0,0 +1,92 @@
+package entry
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const dryRunUsage = "Indicates that the command will not perform any action, but will print the changes that would be made to each entry and the agents that would gain or lose authorization."
+
+// dryRunResponse is implemented by the responses of the EntryDryRun service.
+type dryRunResponse interface {
+	GetResults() []*entrydryrunv1.EntryResult
+	GetAuthorizationChanges() []*entrydryrunv1.AuthorizationChange
+}
+
+func dryRunCreateEntries(ctx context.Context, c entrydryrunv1.EntryDryRunClient, entries []*types.Entry) (*entrydryrunv1.DryRunBatchCreateEntryResponse, error) {
+	resp, err := c.DryRunBatchCreateEntry(ctx, &entrydryrunv1.DryRunBatchCreateEntryRequest{Entries: entries})
+	if err != nil {
+		return nil, dryRunError(err)
+	}
+	return resp, nil
+}
+
+func dryRunUpdateEntries(ctx context.Context, c entrydryrunv1.EntryDryRunClient, entries []*types.Entry) (*entrydryrunv1.DryRunBatchUpdateEntryResponse, error) {
+	resp, err := c.DryRunBatchUpdateEntry(ctx, &entrydryrunv1.DryRunBatchUpdateEntryRequest{Entries: entries})
+	if err != nil {
+		return nil, dryRunError(err)
+	}
+	return resp, nil
+}
+
+func dryRunError(err error) error {
+	if status.Code(err) == codes.Unimplemented {
+		return errors.New("server does not support dry-run requests; no changes were made")
+	}
+	return err
+}
+
+func prettyPrintDryRun(env *commoncli.Env, resp dryRunResponse) error {
+	failed := 0
+	for _, r := range resp.GetResults() {
+		entryID := printableEntryID(r.EntryId)
+		switch r.Action {
+		case entrydryrunv1.Action_ACTION_FAILED:
+			failed++
+			env.ErrPrintf("Would fail     : %s (%s): %s\n", entryID, r.SpiffeId, r.Status.GetMessage())
+			continue
+		case entrydryrunv1.Action_ACTION_CREATE:
+			env.Printf("Would create   : %s (%s)\n", entryID, r.SpiffeId)
+		case entrydryrunv1.Action_ACTION_UPDATE:
+			env.Printf("Would update   : %s (%s)\n", entryID, r.SpiffeId)
+		default:
+			env.Printf("Unchanged      : %s (%s)\n", entryID, r.SpiffeId)
+		}
+		for _, diff := range r.Diffs {
+			env.Printf("  %-14s: %s -> %s\n", diff.Field, printableValue(diff.Old), printableValue(diff.New))
+		}
+	}
+
+	if changes := resp.GetAuthorizationChanges(); len(changes) > 0 {
+		env.Println("\nAgent authorization changes:")
+		for _, change := range changes {
+			env.Printf("%s (%s)\n", printableEntryID(change.EntryId), change.SpiffeId)
+			for _, agentID := range change.AgentsGained {
+				env.Printf("  + %s\n", agentID)
+			}
+			for _, agentID := range change.AgentsLost {
+				env.Printf("  - %s\n", agentID)
+			}
+		}
+	}
+
+	env.Println("\nDry run: no changes were made.")
+
+	if failed > 0 {
+		return errors.New("one or more entries would fail")
+	}
+	return nil
+}
+
+func printableValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,409 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/common/entrydryrun"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// syncBatchSize is the maximum number of entries sent in a single batch
+// request when applying a sync plan.
+const syncBatchSize = 100
+
+// NewSyncCommand creates a new "sync" subcommand for "entry" command.
+func NewSyncCommand() cli.Command {
+	return newSyncCommand(commoncli.DefaultEnv)
+}
+
+func newSyncCommand(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &syncCommand{env: env})
+}
+
+type syncCommand struct {
+	// Path to the file holding the desired set of entries
+	path string
+
+	// Parent ID the sync is scoped to
+	parentID string
+
+	// Hint the sync is scoped to
+	hint string
+
+	// apply applies the plan instead of only printing it
+	apply bool
+
+	printer cliprinter.Printer
+
+	env *commoncli.Env
+}
+
+type syncAction string
+
+const (
+	syncActionCreate    syncAction = "create"
+	syncActionUpdate    syncAction = "update"
+	syncActionDelete    syncAction = "delete"
+	syncActionUnchanged syncAction = "unchanged"
+)
+
+type syncResult struct {
+	Action   syncAction              `json:"action"`
+	EntryID  string                  `json:"entry_id,omitempty"`
+	SPIFFEID string                  `json:"spiffe_id"`
+	Diffs    []entrydryrun.FieldDiff `json:"diffs,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+
+	entry *types.Entry
+}
+
+type syncReport struct {
+	Applied bool          `json:"applied"`
+	Results []*syncResult `json:"results"`
+}
+
+func (*syncCommand) Name() string {
+	return "entry sync"
+}
+
+func (*syncCommand) Synopsis() string {
+	return "Makes the registration entries within a scope match a file"
+}
+
+func (c *syncCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.path, "file", "", "Path to a file containing the desired registration entries in JSON, in the same format as 'entry create -data'. If set to '-', read the JSON from stdin.")
+	f.StringVar(&c.parentID, "parentID", "", "Limits the sync to entries with this parent ID")
+	f.StringVar(&c.hint, "hint", "", "Limits the sync to entries with this hint")
+	f.BoolVar(&c.apply, "apply", false, "Applies the changes. If not set, the changes that would be made are printed without applying them")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintSync)
+}
+
+func (c *syncCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	filter := &entryv1.ListEntriesRequest_Filter{}
+	if c.parentID != "" {
+		parentID, err := idStringToProto(c.parentID)
+		if err != nil {
+			return fmt.Errorf("error parsing parent ID %q: %w", c.parentID, err)
+		}
+		filter.ByParentId = parentID
+	}
+	if c.hint != "" {
+		filter.ByHint = wrapperspb.String(c.hint)
+	}
+
+	desired, err := parseFile(c.path)
+	if err != nil {
+		return err
+	}
+	if err := checkSyncScope(desired, filter); err != nil {
+		return err
+	}
+
+	client := serverClient.NewEntryClient()
+	current, err := listScopedEntries(ctx, client, filter)
+	if err != nil {
+		return err
+	}
+
+	results, err := planSync(desired, current)
+	if err != nil {
+		return err
+	}
+
+	report := &syncReport{Results: results}
+	if c.apply {
+		if err := applySync(ctx, client, results); err != nil {
+			return err
+		}
+		report.Applied = true
+	}
+
+	if err := c.printer.PrintStruct(report); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(report.Results, (*syncResult).failed) {
+		return errors.New("failed to sync one or more entries")
+	}
+	return nil
+}
+
+func (c *syncCommand) validate() error {
+	if c.path == "" {
+		return errors.New("a file is required")
+	}
+
+	// Without a scope, every entry in the server that is not in the file
+	// would be deleted.
+	if c.parentID == "" && c.hint == "" {
+		return errors.New("a scope is required; set -parentID, -hint or both")
+	}
+
+	return nil
+}
+
+// checkSyncScope verifies that the desired entries are within the scope,
+// otherwise they would be created again on every sync since they are never
+// listed.
+func checkSyncScope(desired []*types.Entry, filter *entryv1.ListEntriesRequest_Filter) error {
+	for _, e := range desired {
+		if filter.ByParentId != nil && protoToIDString(e.ParentId) != protoToIDString(filter.ByParentId) {
+			return fmt.Errorf("entry %q is outside of the sync scope: parent ID %q does not match %q",
+				protoToIDString(e.SpiffeId), protoToIDString(e.ParentId), protoToIDString(filter.ByParentId))
+		}
+		if filter.ByHint != nil && e.Hint != filter.ByHint.Value {
+			return fmt.Errorf("entry %q is outside of the sync scope: hint %q does not match %q",
+				protoToIDString(e.SpiffeId), e.Hint, filter.ByHint.Value)
+		}
+	}
+	return nil
+}
+
+func listScopedEntries(ctx context.Context, client entryv1.EntryClient, filter *entryv1.ListEntriesRequest_Filter) ([]*types.Entry, error) {
+	var entries []*types.Entry
+	pageToken := ""
+	for {
+		resp, err := client.ListEntries(ctx, &entryv1.ListEntriesRequest{
+			PageSize:  listEntriesRequestPageSize,
+			PageToken: pageToken,
+			Filter:    filter,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching entries: %w", err)
+		}
+		entries = append(entries, resp.Entries...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			return entries, nil
+		}
+	}
+}
+
+// planSync matches the desired entries against the current ones. Desired
+// entries with an ID are matched by ID. Otherwise, they are matched by SPIFFE
+// ID, parent ID and selectors, which is what makes two entries similar to the
+// server. Current entries that are not matched are deleted.
+func planSync(desired, current []*types.Entry) ([]*syncResult, error) {
+	byID := make(map[string]*types.Entry, len(current))
+	byKey := make(map[string]*types.Entry, len(current))
+	for _, e := range current {
+		byID[e.Id] = e
+		byKey[syncKey(e)] = e
+	}
+
+	matched := make(map[string]bool)
+	seenKeys := make(map[string]bool)
+	var results []*syncResult
+	for _, e := range desired {
+		key := syncKey(e)
+		if seenKeys[key] {
+			return nil, fmt.Errorf("entry %q is defined more than once in the file", protoToIDString(e.SpiffeId))
+		}
+		seenKeys[key] = true
+
+		existing := byKey[key]
+		if e.Id != "" {
+			existing = byID[e.Id]
+		}
+		if existing == nil || matched[existing.Id] {
+			results = append(results, &syncResult{
+				Action:   syncActionCreate,
+				EntryID:  e.Id,
+				SPIFFEID: protoToIDString(e.SpiffeId),
+				Diffs:    entrydryrun.Diff(nil, e),
+				entry:    e,
+			})
+			continue
+		}
+		matched[existing.Id] = true
+
+		result := &syncResult{
+			Action:   syncActionUnchanged,
+			EntryID:  existing.Id,
+			SPIFFEID: protoToIDString(e.SpiffeId),
+			Diffs:    entrydryrun.Diff(existing, e),
+		}
+		if len(result.Diffs) > 0 {
+			result.Action = syncActionUpdate
+			result.entry = cloneEntryWithID(e, existing.Id)
+		}
+		results = append(results, result)
+	}
+
+	for _, e := range current {
+		if !matched[e.Id] {
+			results = append(results, &syncResult{
+				Action:   syncActionDelete,
+				EntryID:  e.Id,
+				SPIFFEID: protoToIDString(e.SpiffeId),
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// applySync applies the plan in batches. Entries are created and updated
+// before stale entries are deleted, so that workloads moving between entries
+// are not left without an identity. For the same reason, no entry is deleted
+// if any entry failed to be created or updated.
+func applySync(ctx context.Context, client entryv1.EntryClient, results []*syncResult) error {
+	creates := filterSyncResults(results, syncActionCreate)
+	for batch := range slices.Chunk(creates, syncBatchSize) {
+		entries := make([]*types.Entry, 0, len(batch))
+		for _, r := range batch {
+			entries = append(entries, r.entry)
+		}
+		resp, err := client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: entries})
+		if err != nil {
+			return fmt.Errorf("failed to create entries: %w", err)
+		}
+		if len(resp.Results) != len(batch) {
+			return fmt.Errorf("failed to create entries: expected %d results, got %d", len(batch), len(resp.Results))
+		}
+		for i, r := range resp.Results {
+			if r.Status.Code != int32(codes.OK) {
+				batch[i].Error = r.Status.Message
+				continue
+			}
+			batch[i].EntryID = r.Entry.Id
+		}
+	}
+
+	updates := filterSyncResults(results, syncActionUpdate)
+	for batch := range slices.Chunk(updates, syncBatchSize) {
+		entries := make([]*types.Entry, 0, len(batch))
+		for _, r := range batch {
+			entries = append(entries, r.entry)
+		}
+		resp, err := client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{Entries: entries})
+		if err != nil {
+			return fmt.Errorf("failed to update entries: %w", err)
+		}
+		if len(resp.Results) != len(batch) {
+			return fmt.Errorf("failed to update entries: expected %d results, got %d", len(batch), len(resp.Results))
+		}
+		for i, r := range resp.Results {
+			if r.Status.Code != int32(codes.OK) {
+				batch[i].Error = r.Status.Message
+			}
+		}
+	}
+
+	deletes := filterSyncResults(results, syncActionDelete)
+	if slices.ContainsFunc(results, (*syncResult).failed) {
+		for _, r := range deletes {
+			r.Error = "skipped because one or more entries failed to be created or updated"
+		}
+		return nil
+	}
+	for batch := range slices.Chunk(deletes, syncBatchSize) {
+		ids := make([]string, 0, len(batch))
+		for _, r := range batch {
+			ids = append(ids, r.EntryID)
+		}
+		resp, err := client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{Ids: ids})
+		if err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+		if len(resp.Results) != len(batch) {
+			return fmt.Errorf("failed to delete entries: expected %d results, got %d", len(batch), len(resp.Results))
+		}
+		for i, r := range resp.Results {
+			if r.Status.Code != int32(codes.OK) {
+				batch[i].Error = r.Status.Message
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *syncResult) failed() bool {
+	return r.Error != ""
+}
+
+func filterSyncResults(results []*syncResult, action syncAction) []*syncResult {
+	var filtered []*syncResult
+	for _, r := range results {
+		if r.Action == action {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+func syncKey(e *types.Entry) string {
+	selectors := make([]string, 0, len(e.Selectors))
+	for _, s := range e.Selectors {
+		selectors = append(selectors, s.Type+":"+s.Value)
+	}
+	slices.Sort(selectors)
+	return protoToIDString(e.SpiffeId) + "\x00" + protoToIDString(e.ParentId) + "\x00" + strings.Join(selectors, "\x00")
+}
+
+func cloneEntryWithID(e *types.Entry, id string) *types.Entry {
+	clone := proto.Clone(e).(*types.Entry)
+	clone.Id = id
+	return clone
+}
+
+func prettyPrintSync(env *commoncli.Env, results ...any) error {
+	report, ok := results[0].([]any)[0].(*syncReport)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+
+	counts := make(map[syncAction]int)
+	failed := 0
+	for _, r := range report.Results {
+		if r.failed() {
+			failed++
+			env.ErrPrintf("Failed to %s entry %s (%s): %s\n", r.Action, printableEntryID(r.EntryID), r.SPIFFEID, r.Error)
+			continue
+		}
+		counts[r.Action]++
+		if r.Action == syncActionUnchanged {
+			continue
+		}
+
+		env.Printf("%-10s: %s (%s)\n", capitalize(string(r.Action)), printableEntryID(r.EntryID), r.SPIFFEID)
+		if r.Action == syncActionUpdate {
+			for _, diff := range r.Diffs {
+				env.Printf("  %-14s: %s -> %s\n", diff.Field, printableValue(diff.Old), printableValue(diff.New))
+			}
+		}
+	}
+
+	if report.Applied {
+		env.Printf("\nApplied: %d created, %d updated, %d deleted, %d unchanged, %d failed.\n",
+			counts[syncActionCreate], counts[syncActionUpdate], counts[syncActionDelete], counts[syncActionUnchanged], failed)
+	} else {
+		env.Printf("\nPlan: %d to create, %d to update, %d to delete, %d unchanged. Run with -apply to apply the changes.\n",
+			counts[syncActionCreate], counts[syncActionUpdate], counts[syncActionDelete], counts[syncActionUnchanged])
+	}
+
+	return nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
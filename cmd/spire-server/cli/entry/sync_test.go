@@ -0,0 +1,345 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const syncEntriesJSON = `{
+  "entries": [
+    {
+      "spiffe_id": "spiffe://example.org/unchanged",
+      "parent_id": "spiffe://example.org/agent",
+      "selectors": [{"type": "unix", "value": "uid:1000"}],
+      "hint": "team-a"
+    },
+    {
+      "spiffe_id": "spiffe://example.org/updated",
+      "parent_id": "spiffe://example.org/agent",
+      "selectors": [{"type": "unix", "value": "uid:1001"}],
+      "x509_svid_ttl": 60,
+      "hint": "team-a"
+    },
+    {
+      "spiffe_id": "spiffe://example.org/created",
+      "parent_id": "spiffe://example.org/agent",
+      "selectors": [{"type": "unix", "value": "uid:1002"}],
+      "hint": "team-a"
+    }
+  ]
+}`
+
+func TestSyncHelp(t *testing.T) {
+	test := setupTest(t, newSyncCommand)
+	test.client.Help()
+
+	require.Equal(t, syncUsage, test.stderr.String())
+}
+
+func TestSyncSynopsis(t *testing.T) {
+	test := setupTest(t, newSyncCommand)
+	require.Equal(t, "Makes the registration entries within a scope match a file", test.client.Synopsis())
+}
+
+func TestSync(t *testing.T) {
+	agentID := &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"}
+	unchanged := &types.Entry{
+		Id:        "unchanged-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/unchanged"},
+		ParentId:  agentID,
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		Hint:      "team-a",
+	}
+	updated := &types.Entry{
+		Id:        "updated-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/updated"},
+		ParentId:  agentID,
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+		Hint:      "team-a",
+	}
+	deleted := &types.Entry{
+		Id:        "deleted-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/deleted"},
+		ParentId:  agentID,
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1003"}},
+		Hint:      "team-a",
+	}
+
+	dataPath := filepath.Join(t.TempDir(), "entries.json")
+	require.NoError(t, os.WriteFile(dataPath, []byte(syncEntriesJSON), 0600))
+
+	expListReq := &entryv1.ListEntriesRequest{
+		PageSize: listEntriesRequestPageSize,
+		Filter: &entryv1.ListEntriesRequest_Filter{
+			ByParentId: agentID,
+			ByHint:     wrapperspb.String("team-a"),
+		},
+	}
+	listResp := &entryv1.ListEntriesResponse{
+		Entries: []*types.Entry{unchanged, updated, deleted},
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		test := setupTest(t, newSyncCommand)
+		test.server.expListEntriesReq = expListReq
+		test.server.listEntriesResp = listResp
+
+		rc := test.client.Run(test.args("-file", dataPath, "-parentID", "spiffe://example.org/agent", "-hint", "team-a"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Equal(t, `Update    : updated-id (spiffe://example.org/updated)
+  x509_svid_ttl : 0 -> 60
+Create    : (none) (spiffe://example.org/created)
+Delete    : deleted-id (spiffe://example.org/deleted)
+
+Plan: 1 to create, 1 to update, 1 to delete, 1 unchanged. Run with -apply to apply the changes.
+`, test.stdout.String())
+	})
+
+	t.Run("plan using json format", func(t *testing.T) {
+		test := setupTest(t, newSyncCommand)
+		test.server.expListEntriesReq = expListReq
+		test.server.listEntriesResp = listResp
+
+		rc := test.client.Run(test.args("-file", dataPath, "-parentID", "spiffe://example.org/agent", "-hint", "team-a", "-output", "json"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.JSONEq(t, `[{
+  "applied": false,
+  "results": [
+    {"action": "unchanged", "entry_id": "unchanged-id", "spiffe_id": "spiffe://example.org/unchanged"},
+    {
+      "action": "update",
+      "entry_id": "updated-id",
+      "spiffe_id": "spiffe://example.org/updated",
+      "diffs": [{"field": "x509_svid_ttl", "old": "0", "new": "60"}]
+    },
+    {
+      "action": "create",
+      "spiffe_id": "spiffe://example.org/created",
+      "diffs": [
+        {"field": "spiffe_id", "old": "", "new": "spiffe://example.org/created"},
+        {"field": "parent_id", "old": "", "new": "spiffe://example.org/agent"},
+        {"field": "selectors", "old": "", "new": "[unix:uid:1002]"},
+        {"field": "hint", "old": "", "new": "team-a"}
+      ]
+    },
+    {"action": "delete", "entry_id": "deleted-id", "spiffe_id": "spiffe://example.org/deleted"}
+  ]
+}]`, test.stdout.String())
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		test := setupTest(t, newSyncCommand)
+		test.server.expListEntriesReq = expListReq
+		test.server.listEntriesResp = listResp
+		test.server.expBatchCreateEntryReq = &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/created"},
+					ParentId:  agentID,
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+					Hint:      "team-a",
+				},
+			},
+		}
+		test.server.batchCreateEntryResp = &entryv1.BatchCreateEntryResponse{
+			Results: []*entryv1.BatchCreateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+					Entry:  &types.Entry{Id: "created-id"},
+				},
+			},
+		}
+		test.server.expBatchUpdateEntryReq = &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					Id:          "updated-id",
+					SpiffeId:    updated.SpiffeId,
+					ParentId:    agentID,
+					Selectors:   updated.Selectors,
+					X509SvidTtl: 60,
+					Hint:        "team-a",
+				},
+			},
+		}
+		test.server.batchUpdateEntryResp = &entryv1.BatchUpdateEntryResponse{
+			Results: []*entryv1.BatchUpdateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+				},
+			},
+		}
+		test.server.expBatchDeleteEntryReq = &entryv1.BatchDeleteEntryRequest{
+			Ids: []string{"deleted-id"},
+		}
+		test.server.batchDeleteEntryResp = &entryv1.BatchDeleteEntryResponse{
+			Results: []*entryv1.BatchDeleteEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.NotFound), Message: "entry not found"},
+					Id:     "deleted-id",
+				},
+			},
+		}
+
+		rc := test.client.Run(test.args("-file", dataPath, "-parentID", "spiffe://example.org/agent", "-hint", "team-a", "-apply"))
+		require.Equal(t, 1, rc)
+		require.Equal(t, `Update    : updated-id (spiffe://example.org/updated)
+  x509_svid_ttl : 0 -> 60
+Create    : created-id (spiffe://example.org/created)
+
+Applied: 1 created, 1 updated, 0 deleted, 1 unchanged, 1 failed.
+`, test.stdout.String())
+		require.Equal(t, `Failed to delete entry deleted-id (spiffe://example.org/deleted): entry not found
+Error: failed to sync one or more entries
+`, test.stderr.String())
+	})
+
+	t.Run("apply skips deletes when an update fails", func(t *testing.T) {
+		test := setupTest(t, newSyncCommand)
+		test.server.expListEntriesReq = expListReq
+		test.server.listEntriesResp = listResp
+		test.server.expBatchCreateEntryReq = &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/created"},
+					ParentId:  agentID,
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+					Hint:      "team-a",
+				},
+			},
+		}
+		test.server.batchCreateEntryResp = &entryv1.BatchCreateEntryResponse{
+			Results: []*entryv1.BatchCreateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+					Entry:  &types.Entry{Id: "created-id"},
+				},
+			},
+		}
+		test.server.expBatchUpdateEntryReq = &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					Id:          "updated-id",
+					SpiffeId:    updated.SpiffeId,
+					ParentId:    agentID,
+					Selectors:   updated.Selectors,
+					X509SvidTtl: 60,
+					Hint:        "team-a",
+				},
+			},
+		}
+		test.server.batchUpdateEntryResp = &entryv1.BatchUpdateEntryResponse{
+			Results: []*entryv1.BatchUpdateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.Internal), Message: "failed to update entry"},
+				},
+			},
+		}
+
+		// The fake server fails the test if entries are deleted
+		rc := test.client.Run(test.args("-file", dataPath, "-parentID", "spiffe://example.org/agent", "-hint", "team-a", "-apply", "-output", "json"))
+		require.Equal(t, 1, rc)
+		require.JSONEq(t, `[{
+  "applied": true,
+  "results": [
+    {"action": "unchanged", "entry_id": "unchanged-id", "spiffe_id": "spiffe://example.org/unchanged"},
+    {
+      "action": "update",
+      "entry_id": "updated-id",
+      "spiffe_id": "spiffe://example.org/updated",
+      "diffs": [{"field": "x509_svid_ttl", "old": "0", "new": "60"}],
+      "error": "failed to update entry"
+    },
+    {
+      "action": "create",
+      "entry_id": "created-id",
+      "spiffe_id": "spiffe://example.org/created",
+      "diffs": [
+        {"field": "spiffe_id", "old": "", "new": "spiffe://example.org/created"},
+        {"field": "parent_id", "old": "", "new": "spiffe://example.org/agent"},
+        {"field": "selectors", "old": "", "new": "[unix:uid:1002]"},
+        {"field": "hint", "old": "", "new": "team-a"}
+      ]
+    },
+    {
+      "action": "delete",
+      "entry_id": "deleted-id",
+      "spiffe_id": "spiffe://example.org/deleted",
+      "error": "skipped because one or more entries failed to be created or updated"
+    }
+  ]
+}]`, test.stdout.String())
+		require.Equal(t, "Error: failed to sync one or more entries\n", test.stderr.String())
+	})
+
+	t.Run("apply fails when the results do not match the entries", func(t *testing.T) {
+		test := setupTest(t, newSyncCommand)
+		test.server.expListEntriesReq = expListReq
+		test.server.listEntriesResp = listResp
+		test.server.expBatchCreateEntryReq = &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/created"},
+					ParentId:  agentID,
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1002"}},
+					Hint:      "team-a",
+				},
+			},
+		}
+		test.server.batchCreateEntryResp = &entryv1.BatchCreateEntryResponse{}
+
+		rc := test.client.Run(test.args("-file", dataPath, "-parentID", "spiffe://example.org/agent", "-hint", "team-a", "-apply"))
+		require.Equal(t, 1, rc)
+		require.Equal(t, "Error: failed to create entries: expected 1 results, got 0\n", test.stderr.String())
+	})
+
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		expErr string
+	}{
+		{
+			name:   "missing file",
+			args:   []string{"-parentID", "spiffe://example.org/agent"},
+			expErr: "Error: a file is required\n",
+		},
+		{
+			name:   "missing scope",
+			args:   []string{"-file", dataPath},
+			expErr: "Error: a scope is required; set -parentID, -hint or both\n",
+		},
+		{
+			name:   "entry outside of parent ID scope",
+			args:   []string{"-file", dataPath, "-parentID", "spiffe://example.org/other"},
+			expErr: "Error: entry \"spiffe://example.org/unchanged\" is outside of the sync scope: parent ID \"spiffe://example.org/agent\" does not match \"spiffe://example.org/other\"\n",
+		},
+		{
+			name:   "entry outside of hint scope",
+			args:   []string{"-file", dataPath, "-hint", "team-b"},
+			expErr: "Error: entry \"spiffe://example.org/unchanged\" is outside of the sync scope: hint \"team-a\" does not match \"team-b\"\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newSyncCommand)
+			rc := test.client.Run(test.args(tt.args...))
+			require.Equal(t, 1, rc)
+			require.Equal(t, tt.expErr, test.stderr.String())
+		})
+	}
+}
+
+func TestPlanSyncDuplicateEntries(t *testing.T) {
+	entry := &types.Entry{
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	_, err := planSync([]*types.Entry{entry, entry}, nil)
+	require.EqualError(t, err, `entry "spiffe://example.org/workload" is defined more than once in the file`)
+}
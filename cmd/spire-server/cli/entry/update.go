@@ -70,6 +70,9 @@ type updateCommand struct {
 	// Entry hint, used to disambiguate entries with the same SPIFFE ID
 	hint string
 
+	// dryRun reports the changes that would be made without applying them
+	dryRun bool
+
 	printer cliprinter.Printer
 
 	env *commoncli.Env
@@ -86,7 +89,7 @@ func (*updateCommand) Synopsis() string {
 func (c *updateCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the record to update")
 	f.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of this record's parent")
-	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents")
+	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents. May be a template with {{<type>:<key>}} placeholders expanded from the node selectors of the agent and the entry selectors")
 	f.IntVar(&c.x509SvidTTL, "x509SVIDTTL", 0, "The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.")
 	f.IntVar(&c.jwtSvidTTL, "jwtSVIDTTL", 0, "The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.")
 	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.")
@@ -98,6 +101,7 @@ func (c *updateCommand) AppendFlags(f *flag.FlagSet) {
 	f.Int64Var(&c.entryExpiry, "entryExpiry", 0, "An expiry, from epoch in seconds, for the resulting registration entry to be pruned")
 	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once")
 	f.StringVar(&c.hint, "hint", "", "The entry hint, used to disambiguate entries with the same SPIFFE ID")
+	f.BoolVar(&c.dryRun, "dryRun", false, dryRunUsage)
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintUpdate)
 }
 
@@ -117,6 +121,14 @@ func (c *updateCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return err
 	}
 
+	if c.dryRun {
+		resp, err := dryRunUpdateEntries(ctx, serverClient.NewEntryDryRunClient(), entries)
+		if err != nil {
+			return err
+		}
+		return c.printer.PrintProto(resp)
+	}
+
 	resp, err := updateEntries(ctx, serverClient.NewEntryClient(), entries)
 	if err != nil {
 		return err
@@ -166,7 +178,7 @@ func (c *updateCommand) parseConfig() ([]*types.Entry, error) {
 	if err != nil {
 		return nil, err
 	}
-	spiffeID, err := idStringToProto(c.spiffeID)
+	spiffeID, err := entrySPIFFEIDStringToProto(c.spiffeID)
 	if err != nil {
 		return nil, err
 	}
@@ -230,6 +242,10 @@ func updateEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.
 }
 
 func prettyPrintUpdate(env *commoncli.Env, results ...any) error {
+	if resp, ok := results[0].(dryRunResponse); ok {
+		return prettyPrintDryRun(env, resp)
+	}
+
 	var succeeded, failed []*entryv1.BatchUpdateEntryResponse_Result
 	updateResp, ok := results[0].(*entryv1.BatchUpdateEntryResponse)
 	if !ok {
@@ -3,11 +3,14 @@ package entry
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/proto"
@@ -652,3 +655,98 @@ Error: failed to update one or more entries
 		}
 	}
 }
+
+func TestUpdateDryRun(t *testing.T) {
+	test := setupTest(t, newUpdateCommand)
+	test.server.expDryRunBatchUpdateEntryReq = &entrydryrunv1.DryRunBatchUpdateEntryRequest{
+		Entries: []*types.Entry{
+			{
+				Id:        "entry-id",
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+			},
+			{
+				Id:        "entry-id-2",
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload2"},
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+			},
+		},
+	}
+	test.server.dryRunBatchUpdateEntryResp = &entrydryrunv1.DryRunBatchUpdateEntryResponse{
+		Results: []*entrydryrunv1.EntryResult{
+			{
+				Status:   &types.Status{},
+				Action:   entrydryrunv1.Action_ACTION_UPDATE,
+				EntryId:  "entry-id",
+				SpiffeId: "spiffe://example.org/workload",
+				Diffs: []*entrydryrunv1.FieldDiff{
+					{Field: "parent_id", Old: "spiffe://example.org/other", New: "spiffe://example.org/parent"},
+				},
+			},
+			{
+				Status:  &types.Status{Code: int32(codes.NotFound), Message: "entry not found"},
+				Action:  entrydryrunv1.Action_ACTION_FAILED,
+				EntryId: "entry-id-2",
+			},
+		},
+		AuthorizationChanges: []*entrydryrunv1.AuthorizationChange{
+			{
+				EntryId:      "entry-id",
+				SpiffeId:     "spiffe://example.org/workload",
+				AgentsGained: []string{"spiffe://example.org/parent"},
+				AgentsLost:   []string{"spiffe://example.org/other"},
+			},
+		},
+	}
+
+	dataPath := filepath.Join(t.TempDir(), "entries.json")
+	require.NoError(t, os.WriteFile(dataPath, []byte(`{
+  "entries": [
+    {
+      "entry_id": "entry-id",
+      "spiffe_id": "spiffe://example.org/workload",
+      "parent_id": "spiffe://example.org/parent",
+      "selectors": [{"type": "unix", "value": "uid:1000"}]
+    },
+    {
+      "entry_id": "entry-id-2",
+      "spiffe_id": "spiffe://example.org/workload2",
+      "parent_id": "spiffe://example.org/parent",
+      "selectors": [{"type": "unix", "value": "uid:1001"}]
+    }
+  ]
+}`), 0600))
+
+	rc := test.client.Run(test.args("-dryRun", "-data", dataPath))
+	require.Equal(t, 1, rc)
+	require.Equal(t, `Would update   : entry-id (spiffe://example.org/workload)
+  parent_id     : spiffe://example.org/other -> spiffe://example.org/parent
+
+Agent authorization changes:
+entry-id (spiffe://example.org/workload)
+  + spiffe://example.org/parent
+  - spiffe://example.org/other
+
+Dry run: no changes were made.
+`, test.stdout.String())
+	require.Equal(t, `Would fail     : entry-id-2 (): entry not found
+Error: one or more entries would fail
+`, test.stderr.String())
+}
+
+func TestUpdateDryRunWithoutServerSupport(t *testing.T) {
+	// The entries must not be updated instead: the fake server fails the
+	// test if they are.
+	test := setupTest(t, newUpdateCommand)
+
+	rc := test.client.Run(test.args("-dryRun",
+		"-entryID", "entry-id",
+		"-spiffeID", "spiffe://example.org/workload",
+		"-parentID", "spiffe://example.org/parent",
+		"-selector", "unix:uid:1000",
+	))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: server does not support dry-run requests; no changes were made\n", test.stderr.String())
+}
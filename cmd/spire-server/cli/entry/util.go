@@ -9,6 +9,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/proto/spire/common"
 )
@@ -78,6 +79,22 @@ func idStringToProto(id string) (*types.SPIFFEID, error) {
 	}, nil
 }
 
+// entrySPIFFEIDStringToProto is like idStringToProto but also accepts SPIFFE
+// ID templates, e.g. "spiffe://example.org/ns/{{k8s:ns}}"
+func entrySPIFFEIDStringToProto(id string) (*types.SPIFFEID, error) {
+	if !entrytemplate.IsTemplate(id) {
+		return idStringToProto(id)
+	}
+	tmpl, err := entrytemplate.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SPIFFE ID template: %w", err)
+	}
+	return &types.SPIFFEID{
+		TrustDomain: tmpl.TrustDomain().Name(),
+		Path:        tmpl.Path(),
+	}, nil
+}
+
 func printableEntryID(id string) string {
 	if id == "" {
 		return "(none)"
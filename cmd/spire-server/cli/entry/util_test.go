@@ -11,12 +11,15 @@ import (
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var availableFormats = []string{"pretty", "json"}
@@ -135,6 +138,20 @@ func TestIDStringToProto(t *testing.T) {
 	require.Nil(t, id)
 }
 
+func TestEntrySPIFFEIDStringToProto(t *testing.T) {
+	id, err := entrySPIFFEIDStringToProto("spiffe://example.org/host")
+	require.NoError(t, err)
+	require.Equal(t, &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"}, id)
+
+	id, err = entrySPIFFEIDStringToProto("spiffe://example.org/ns/{{k8s:ns}}")
+	require.NoError(t, err)
+	require.Equal(t, &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}"}, id)
+
+	id, err = entrySPIFFEIDStringToProto("spiffe://example.org/ns/{{k8s}}")
+	require.EqualError(t, err, `invalid SPIFFE ID template: invalid placeholder "k8s": expected {{<type>:<key>}}`)
+	require.Nil(t, id)
+}
+
 type entryTest struct {
 	stdin  *bytes.Buffer
 	stdout *bytes.Buffer
@@ -159,6 +176,7 @@ func (e *entryTest) args(extra ...string) []string {
 
 type fakeEntryServer struct {
 	*entryv1.UnimplementedEntryServer
+	*entrydryrunv1.UnimplementedEntryDryRunServer
 
 	t   *testing.T
 	err error
@@ -169,12 +187,20 @@ type fakeEntryServer struct {
 	expBatchCreateEntryReq *entryv1.BatchCreateEntryRequest
 	expBatchUpdateEntryReq *entryv1.BatchUpdateEntryRequest
 
+	expDryRunBatchCreateEntryReq *entrydryrunv1.DryRunBatchCreateEntryRequest
+	expDryRunBatchUpdateEntryReq *entrydryrunv1.DryRunBatchUpdateEntryRequest
+
 	getEntryResp         *types.Entry
 	countEntriesResp     *entryv1.CountEntriesResponse
 	listEntriesResp      *entryv1.ListEntriesResponse
 	batchDeleteEntryResp *entryv1.BatchDeleteEntryResponse
 	batchCreateEntryResp *entryv1.BatchCreateEntryResponse
 	batchUpdateEntryResp *entryv1.BatchUpdateEntryResponse
+
+	// Dry-run requests fail as unimplemented when no response is set, as
+	// on servers without dry-run support.
+	dryRunBatchCreateEntryResp *entrydryrunv1.DryRunBatchCreateEntryResponse
+	dryRunBatchUpdateEntryResp *entrydryrunv1.DryRunBatchUpdateEntryResponse
 }
 
 func (f fakeEntryServer) CountEntries(context.Context, *entryv1.CountEntriesRequest) (*entryv1.CountEntriesResponse, error) {
@@ -224,6 +250,22 @@ func (f fakeEntryServer) BatchUpdateEntry(_ context.Context, req *entryv1.BatchU
 	return f.batchUpdateEntryResp, nil
 }
 
+func (f fakeEntryServer) DryRunBatchCreateEntry(_ context.Context, req *entrydryrunv1.DryRunBatchCreateEntryRequest) (*entrydryrunv1.DryRunBatchCreateEntryResponse, error) {
+	if f.dryRunBatchCreateEntryResp == nil {
+		return nil, status.Error(codes.Unimplemented, "method DryRunBatchCreateEntry not implemented")
+	}
+	spiretest.AssertProtoEqual(f.t, f.expDryRunBatchCreateEntryReq, req)
+	return f.dryRunBatchCreateEntryResp, nil
+}
+
+func (f fakeEntryServer) DryRunBatchUpdateEntry(_ context.Context, req *entrydryrunv1.DryRunBatchUpdateEntryRequest) (*entrydryrunv1.DryRunBatchUpdateEntryResponse, error) {
+	if f.dryRunBatchUpdateEntryResp == nil {
+		return nil, status.Error(codes.Unimplemented, "method DryRunBatchUpdateEntry not implemented")
+	}
+	spiretest.AssertProtoEqual(f.t, f.expDryRunBatchUpdateEntryReq, req)
+	return f.dryRunBatchUpdateEntryResp, nil
+}
+
 func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entryTest {
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
@@ -238,6 +280,7 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entry
 	server := &fakeEntryServer{t: t}
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		entryv1.RegisterEntryServer(s, server)
+		entrydryrunv1.RegisterEntryDryRunServer(s, server)
 	})
 
 	test := &entryTest{
@@ -12,6 +12,8 @@ const (
     	A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
+  -dryRun
+    	Indicates that the command will not perform any action, but will print the changes that would be made to each entry and the agents that would gain or lose authorization.
   -entryExpiry int
     	An expiry, from epoch in seconds, for the resulting registration entry to be pruned
   -entryID string
@@ -33,7 +35,7 @@ const (
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -spiffeID string
-    	The SPIFFE ID that this record represents
+    	The SPIFFE ID that this record represents. May be a template with {{<type>:<key>}} placeholders expanded from the node selectors of the agent and the entry selectors
   -storeSVID
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
   -x509SVIDTTL int
@@ -72,6 +74,8 @@ const (
     	A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
+  -dryRun
+    	Indicates that the command will not perform any action, but will print the changes that would be made to each entry and the agents that would gain or lose authorization.
   -entryExpiry int
     	An expiry, from epoch in seconds, for the resulting registration entry to be pruned
   -entryID string
@@ -91,7 +95,7 @@ const (
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -spiffeID string
-    	The SPIFFE ID that this record represents
+    	The SPIFFE ID that this record represents. May be a template with {{<type>:<key>}} placeholders expanded from the node selectors of the agent and the entry selectors
   -storeSVID
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
   -x509SVIDTTL int
@@ -106,6 +110,20 @@ const (
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+`
+	syncUsage = `Usage of entry sync:
+  -apply
+    	Applies the changes. If not set, the changes that would be made are printed without applying them
+  -file string
+    	Path to a file containing the desired registration entries in JSON, in the same format as 'entry create -data'. If set to '-', read the JSON from stdin.
+  -hint string
+    	Limits the sync to entries with this hint
+  -namedPipeName string
+    	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -parentID string
+    	Limits the sync to entries with this parent ID
 `
 	countUsage = `Usage of entry count:
   -downstream
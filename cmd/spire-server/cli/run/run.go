@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -44,7 +45,9 @@ import (
 	"github.com/spiffe/spire/pkg/server/ca/manager"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
+	"github.com/spiffe/spire/pkg/server/k8ssigner"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
+	"github.com/spiffe/spire/pkg/server/revocation"
 )
 
 const (
@@ -117,6 +120,23 @@ type experimentalConfig struct {
 
 	NamedPipeName string `hcl:"named_pipe_name"`
 
+	KubernetesSigner *kubernetesSignerConfig `hcl:"kubernetes_signer"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type kubernetesSignerConfig struct {
+	KubeConfigFile     string                   `hcl:"kube_config_file"`
+	ResyncInterval     string                   `hcl:"resync_interval"`
+	SignerName         string                   `hcl:"signer_name"`
+	CertManagerIssuer  *certManagerIssuerConfig `hcl:"cert_manager_issuer"`
+	UnusedKeyPositions map[string][]token.Pos   `hcl:",unusedKeyPositions"`
+}
+
+type certManagerIssuerConfig struct {
+	Name               string                 `hcl:"name"`
+	Kind               string                 `hcl:"kind"`
+	Group              string                 `hcl:"group"`
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
@@ -137,10 +157,19 @@ type bundleEndpointConfig struct {
 	Address     string `hcl:"address"`
 	Port        int    `hcl:"port"`
 	RefreshHint string `hcl:"refresh_hint"`
+	SignBundle  bool   `hcl:"sign_bundle"`
 
 	ACME    *bundleEndpointACMEConfig `hcl:"acme"`
 	Profile ast.Node                  `hcl:"profile"`
 
+	X509SVIDRevocation *x509SVIDRevocationConfig `hcl:"x509_svid_revocation"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+type x509SVIDRevocationConfig struct {
+	URL                string                 `hcl:"url"`
+	TTL                string                 `hcl:"ttl"`
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
@@ -175,6 +204,7 @@ type bundleEndpointACMEConfig struct {
 type federatesWithConfig struct {
 	BundleEndpointURL     string                 `hcl:"bundle_endpoint_url"`
 	BundleEndpointProfile ast.Node               `hcl:"bundle_endpoint_profile"`
+	BundleSignatureURL    string                 `hcl:"bundle_signature_url"`
 	UnusedKeyPositions    map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
@@ -482,6 +512,16 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 					return nil, err
 				}
 			}
+
+			if c.Server.Federation.BundleEndpoint.X509SVIDRevocation != nil {
+				revocationConfig, err := parseX509SVIDRevocationConfig(c.Server.Federation.BundleEndpoint.X509SVIDRevocation)
+				if err != nil {
+					return nil, err
+				}
+				sc.Federation.BundleEndpoint.X509SVIDRevocation = revocationConfig
+			}
+
+			sc.Federation.BundleEndpoint.SignBundle = c.Server.Federation.BundleEndpoint.SignBundle
 		}
 
 		federatesWith := map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{}
@@ -677,6 +717,11 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	if err != nil {
 		return nil, err
 	}
+	if sc.CAKeyType == keymanager.Ed25519 || sc.JWTKeyType == keymanager.Ed25519 {
+		if err := checkEd25519KeyManager(sc.PluginConfigs); err != nil {
+			return nil, err
+		}
+	}
 	sc.Telemetry = c.Telemetry
 	sc.HealthChecks = c.HealthChecks
 
@@ -721,6 +766,13 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	sc.EventsBasedCache = c.Server.Experimental.EventsBasedCache
 	sc.AuthOpaPolicyEngineConfig = c.Server.Experimental.AuthOpaPolicyEngine
 
+	if c.Server.Experimental.KubernetesSigner != nil {
+		sc.KubernetesSigner, err = parseKubernetesSignerConfig(c.Server.Experimental.KubernetesSigner)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for _, f := range c.Server.Experimental.Flags {
 		sc.Log.Warnf("Developer feature flag %q has been enabled", f)
 	}
@@ -811,6 +863,71 @@ func configToDiskCertManager(serviceCertFile *bundleEndpointServingCertFile, log
 	)
 }
 
+func parseKubernetesSignerConfig(config *kubernetesSignerConfig) (*server.KubernetesSignerConfig, error) {
+	if config.SignerName == "" && config.CertManagerIssuer == nil {
+		return nil, errors.New("experimental.kubernetes_signer requires signer_name or cert_manager_issuer to be configured")
+	}
+	if config.SignerName != "" && !strings.Contains(config.SignerName, "/") {
+		return nil, fmt.Errorf("experimental.kubernetes_signer.signer_name must be a domain-qualified name like example.org/signer; found %q", config.SignerName)
+	}
+
+	signerConfig := &server.KubernetesSignerConfig{
+		KubeConfigFilePath: config.KubeConfigFile,
+		ResyncInterval:     k8ssigner.DefaultResyncInterval,
+		SignerName:         config.SignerName,
+	}
+	if config.ResyncInterval != "" {
+		var err error
+		signerConfig.ResyncInterval, err = time.ParseDuration(config.ResyncInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse experimental.kubernetes_signer.resync_interval %q: %w", config.ResyncInterval, err)
+		}
+		if signerConfig.ResyncInterval <= 0 {
+			return nil, fmt.Errorf("experimental.kubernetes_signer.resync_interval must be positive; found %q", config.ResyncInterval)
+		}
+	}
+	if config.CertManagerIssuer != nil {
+		if config.CertManagerIssuer.Group == "" {
+			return nil, errors.New("experimental.kubernetes_signer.cert_manager_issuer.group must be configured")
+		}
+		signerConfig.CertManagerIssuer = &k8ssigner.IssuerRef{
+			Name:  config.CertManagerIssuer.Name,
+			Kind:  config.CertManagerIssuer.Kind,
+			Group: config.CertManagerIssuer.Group,
+		}
+	}
+	return signerConfig, nil
+}
+
+func parseX509SVIDRevocationConfig(config *x509SVIDRevocationConfig) (*bundle.X509SVIDRevocationConfig, error) {
+	u, err := url.Parse(config.URL)
+	switch {
+	case config.URL == "":
+		return nil, errors.New("federation.bundle_endpoint.x509_svid_revocation.url must be configured")
+	case err != nil:
+		return nil, fmt.Errorf("could not parse federation.bundle_endpoint.x509_svid_revocation.url %q: %w", config.URL, err)
+	case u.Scheme != "http" && u.Scheme != "https":
+		return nil, fmt.Errorf("federation.bundle_endpoint.x509_svid_revocation.url must use the HTTP or HTTPS protocol; URL found: %q", config.URL)
+	case u.Host == "" || u.RawQuery != "" || u.Fragment != "":
+		return nil, fmt.Errorf("federation.bundle_endpoint.x509_svid_revocation.url must be an absolute URL without query or fragment; URL found: %q", config.URL)
+	}
+
+	revocationConfig := &bundle.X509SVIDRevocationConfig{
+		URL: strings.TrimSuffix(config.URL, "/"),
+		TTL: revocation.DefaultTTL,
+	}
+	if config.TTL != "" {
+		revocationConfig.TTL, err = time.ParseDuration(config.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse federation.bundle_endpoint.x509_svid_revocation.ttl %q: %w", config.TTL, err)
+		}
+		if revocationConfig.TTL <= 0 {
+			return nil, fmt.Errorf("federation.bundle_endpoint.x509_svid_revocation.ttl must be positive; found %q", config.TTL)
+		}
+	}
+	return revocationConfig, nil
+}
+
 func parseBundleEndpointProfile(config federatesWithConfig) (trustDomainConfig *bundleClient.TrustDomainConfig, err error) {
 	configString, err := parseBundleEndpointProfileASTNode(config.BundleEndpointProfile)
 	if err != nil {
@@ -839,6 +956,7 @@ func parseBundleEndpointProfile(config federatesWithConfig) (trustDomainConfig *
 	return &bundleClient.TrustDomainConfig{
 		EndpointURL:     config.BundleEndpointURL,
 		EndpointProfile: endpointProfile,
+		SignatureURL:    config.BundleSignatureURL,
 	}, nil
 }
 
@@ -900,6 +1018,8 @@ func validateConfig(c *Config) error {
 				return fmt.Errorf("federation.federates_with[\"%s\"].bundle_endpoint_url must be configured", td)
 			case !strings.HasPrefix(strings.ToLower(tdConfig.BundleEndpointURL), "https://"):
 				return fmt.Errorf("federation.federates_with[\"%s\"].bundle_endpoint_url must use the HTTPS protocol; URL found: %q", td, tdConfig.BundleEndpointURL)
+			case tdConfig.BundleSignatureURL != "" && !strings.HasPrefix(strings.ToLower(tdConfig.BundleSignatureURL), "https://"):
+				return fmt.Errorf("federation.federates_with[\"%s\"].bundle_signature_url must use the HTTPS protocol; URL found: %q", td, tdConfig.BundleSignatureURL)
 			}
 		}
 	}
@@ -946,6 +1066,16 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		//	detectedUnknown("experimental", c.Server.Experimental.UnusedKeyPositions)
 		// }
 
+		if ks := c.Server.Experimental.KubernetesSigner; ks != nil {
+			if len(ks.UnusedKeyPositions) != 0 {
+				detectedUnknown("kubernetes signer", ks.UnusedKeyPositions)
+			}
+
+			if cmi := ks.CertManagerIssuer; cmi != nil && len(cmi.UnusedKeyPositions) != 0 {
+				detectedUnknown("kubernetes signer cert-manager issuer", cmi.UnusedKeyPositions)
+			}
+		}
+
 		if c.Server.Federation != nil {
 			// TODO: Re-enable unused key detection for federation config. See
 			// https://github.com/spiffe/spire/issues/1101 for more information
@@ -962,6 +1092,10 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 				if bea := c.Server.Federation.BundleEndpoint.ACME; bea != nil && len(bea.UnusedKeyPositions) != 0 {
 					detectedUnknown("bundle endpoint ACME", bea.UnusedKeyPositions)
 				}
+
+				if rc := c.Server.Federation.BundleEndpoint.X509SVIDRevocation; rc != nil && len(rc.UnusedKeyPositions) != 0 {
+					detectedUnknown("bundle endpoint X509-SVID revocation", rc.UnusedKeyPositions)
+				}
 			}
 
 			// TODO: Re-enable unused key detection for bundle endpoint profile config. See
@@ -1004,10 +1138,20 @@ func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
 		}
 	}
 
+	for _, v := range c.Telemetry.OTLP {
+		if len(v.UnusedKeyPositions) != 0 {
+			detectedUnknown("OTLP", v.UnusedKeyPositions)
+		}
+	}
+
 	if p := c.Telemetry.InMem; p != nil && len(p.UnusedKeyPositions) != 0 {
 		detectedUnknown("InMem", p.UnusedKeyPositions)
 	}
 
+	if p := c.Telemetry.Tracing; p != nil && len(p.UnusedKeyPositions) != 0 {
+		detectedUnknown("Tracing", p.UnusedKeyPositions)
+	}
+
 	if len(c.HealthChecks.UnusedKeyPositions) != 0 {
 		detectedUnknown("health check", c.HealthChecks.UnusedKeyPositions)
 	}
@@ -1038,9 +1182,28 @@ func keyTypeFromString(s string) (keymanager.KeyType, error) {
 		return keymanager.ECP256, nil
 	case "ec-p384":
 		return keymanager.ECP384, nil
+	case "ed25519":
+		return keymanager.Ed25519, nil
 	default:
-		return keymanager.KeyTypeUnset, fmt.Errorf("key type %q is unknown; must be one of [rsa-2048, rsa-4096, ec-p256, ec-p384]", s)
+		return keymanager.KeyTypeUnset, fmt.Errorf("key type %q is unknown; must be one of [rsa-2048, rsa-4096, ec-p256, ec-p384, ed25519]", s)
+	}
+}
+
+// checkEd25519KeyManager checks that the configured KeyManager supports
+// Ed25519 keys. The KeyManager plugin interface does not define a key type
+// for Ed25519 keys, so only the built-in disk and memory KeyManager plugins
+// support them.
+func checkEd25519KeyManager(pluginConfigs catalog.PluginConfigs) error {
+	keyManagerConfigs, _ := pluginConfigs.FilterByType("KeyManager")
+	for _, keyManagerConfig := range keyManagerConfigs {
+		if !keyManagerConfig.IsEnabled() {
+			continue
+		}
+		if keyManagerConfig.IsExternal() || (keyManagerConfig.Name != "disk" && keyManagerConfig.Name != "memory") {
+			return fmt.Errorf("key type %q is only supported by the built-in disk and memory KeyManager plugins, not by %q", keymanager.Ed25519, keyManagerConfig.Name)
+		}
 	}
+	return nil
 }
 
 // hasCompatibleTTL checks if we can guarantee the configured SVID TTL given the
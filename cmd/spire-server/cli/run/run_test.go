@@ -20,6 +20,7 @@ import (
 	bundleClient "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
+	"github.com/spiffe/spire/pkg/server/k8ssigner"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -666,6 +667,22 @@ func TestNewServerConfig(t *testing.T) {
 				require.Equal(t, "192.168.1.1", c.Federation.BundleEndpoint.Address.IP.String())
 				require.Equal(t, 1337, c.Federation.BundleEndpoint.Address.Port)
 				require.Equal(t, 10*time.Minute, c.Federation.BundleEndpoint.RefreshHint)
+				require.False(t, c.Federation.BundleEndpoint.SignBundle)
+			},
+		},
+		{
+			msg: "bundle endpoint signature is enabled",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						Address:    "192.168.1.1",
+						Port:       1337,
+						SignBundle: true,
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.Federation.BundleEndpoint.SignBundle)
 			},
 		},
 		{
@@ -796,6 +813,87 @@ func TestNewServerConfig(t *testing.T) {
 				require.Equal(t, 5*time.Minute, c.Federation.BundleEndpoint.RefreshHint)
 			},
 		},
+		{
+			msg: "bundle endpoint has X509-SVID revocation",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						X509SVIDRevocation: &x509SVIDRevocationConfig{
+							URL: "https://example.org:8443/",
+							TTL: "30m",
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &bundle.X509SVIDRevocationConfig{
+					URL: "https://example.org:8443",
+					TTL: 30 * time.Minute,
+				}, c.Federation.BundleEndpoint.X509SVIDRevocation)
+			},
+		},
+		{
+			msg: "bundle endpoint has X509-SVID revocation with default TTL",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						X509SVIDRevocation: &x509SVIDRevocationConfig{
+							URL: "https://example.org:8443",
+						},
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, time.Hour, c.Federation.BundleEndpoint.X509SVIDRevocation.TTL)
+			},
+		},
+		{
+			msg: "bundle endpoint X509-SVID revocation requires a URL",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						X509SVIDRevocation: &x509SVIDRevocationConfig{},
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "bundle endpoint X509-SVID revocation URL must use HTTP or HTTPS",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						X509SVIDRevocation: &x509SVIDRevocationConfig{
+							URL: "ftp://example.org",
+						},
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "bundle endpoint X509-SVID revocation TTL must be positive",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					BundleEndpoint: &bundleEndpointConfig{
+						X509SVIDRevocation: &x509SVIDRevocationConfig{
+							URL: "https://example.org",
+							TTL: "-1m",
+						},
+					},
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "bundle federates with section is parsed and configured correctly",
 			input: func(c *Config) {
@@ -821,6 +919,27 @@ func TestNewServerConfig(t *testing.T) {
 				}, c.Federation.FederatesWith)
 			},
 		},
+		{
+			msg: "bundle federates with signature URL is parsed",
+			input: func(c *Config) {
+				federatesWith := webPKIConfigTest(t)
+				federatesWith.BundleSignatureURL = "https://192.168.1.1:1337/bundle.jws"
+				c.Server.Federation = &federationConfig{
+					FederatesWith: map[string]federatesWithConfig{
+						"domain1.test": federatesWith,
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, map[spiffeid.TrustDomain]bundleClient.TrustDomainConfig{
+					spiffeid.RequireTrustDomainFromString("domain1.test"): {
+						EndpointURL:     "https://192.168.1.1:1337",
+						EndpointProfile: bundleClient.HTTPSWebProfile{},
+						SignatureURL:    "https://192.168.1.1:1337/bundle.jws",
+					},
+				}, c.Federation.FederatesWith)
+			},
+		},
 		{
 			msg: "default_x509_svid_ttl is correctly parsed",
 			input: func(c *Config) {
@@ -979,6 +1098,59 @@ func TestNewServerConfig(t *testing.T) {
 				require.Equal(t, keymanager.ECP256, c.JWTKeyType)
 			},
 		},
+		{
+			msg: "ed25519 ca_key_type is correctly parsed and is set as default for jwt key",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "ed25519"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.Ed25519, c.CAKeyType)
+				require.Equal(t, keymanager.Ed25519, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ed25519 jwt_key_type is correctly parsed and ca_key_type is unspecified",
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "ed25519"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.ECP256, c.CAKeyType)
+				require.Equal(t, keymanager.Ed25519, c.JWTKeyType)
+			},
+		},
+		{
+			msg: "ed25519 ca_key_type is supported by the built-in disk KeyManager",
+			input: func(c *Config) {
+				c.Server.CAKeyType = "ed25519"
+				c.Plugins = mustParsePlugins(`KeyManager "disk" { plugin_data { keys_path = "keys.json" } }`)
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, keymanager.Ed25519, c.CAKeyType)
+				require.Equal(t, keymanager.Ed25519, c.JWTKeyType)
+			},
+		},
+		{
+			msg:         "ed25519 ca_key_type is rejected for other KeyManagers",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.CAKeyType = "ed25519"
+				c.Plugins = mustParsePlugins(`KeyManager "aws_kms" { plugin_data {} }`)
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "ed25519 jwt_key_type is rejected for external KeyManagers",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.JWTKeyType = "ed25519"
+				c.Plugins = mustParsePlugins(`KeyManager "memory" { plugin_cmd = "./km" plugin_data {} }`)
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "ca_ttl is correctly parsed",
 			input: func(c *Config) {
@@ -1144,6 +1316,92 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg: "kubernetes_signer is correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{
+					KubeConfigFile: "/path/to/kubeconfig",
+					ResyncInterval: "30s",
+					SignerName:     "spiffe.io/spire",
+					CertManagerIssuer: &certManagerIssuerConfig{
+						Name:  "spire",
+						Kind:  "ClusterIssuer",
+						Group: "spire.spiffe.io",
+					},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, &server.KubernetesSignerConfig{
+					KubeConfigFilePath: "/path/to/kubeconfig",
+					ResyncInterval:     30 * time.Second,
+					SignerName:         "spiffe.io/spire",
+					CertManagerIssuer: &k8ssigner.IssuerRef{
+						Name:  "spire",
+						Kind:  "ClusterIssuer",
+						Group: "spire.spiffe.io",
+					},
+				}, c.KubernetesSigner)
+			},
+		},
+		{
+			msg: "kubernetes_signer uses the default resync interval",
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{
+					SignerName: "spiffe.io/spire",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, k8ssigner.DefaultResyncInterval, c.KubernetesSigner.ResyncInterval)
+				require.Nil(t, c.KubernetesSigner.CertManagerIssuer)
+			},
+		},
+		{
+			msg:         "kubernetes_signer requires a signer name or a cert-manager issuer",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "kubernetes_signer signer name must be domain-qualified",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{
+					SignerName: "spire",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "kubernetes_signer cert-manager issuer requires a group",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{
+					CertManagerIssuer: &certManagerIssuerConfig{Name: "spire"},
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid kubernetes_signer resync_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.KubernetesSigner = &kubernetesSignerConfig{
+					SignerName:     "spiffe.io/spire",
+					ResyncInterval: "b",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "audit_log_enabled is enabled",
 			input: func(c *Config) {
@@ -1233,6 +1491,14 @@ func TestNewServerConfig(t *testing.T) {
 	}
 }
 
+func mustParsePlugins(s string) ast.Node {
+	file, err := hcl.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return file.Node
+}
+
 // defaultValidConfig returns the bare minimum config required to
 // pass validation etc
 func defaultValidConfig() *Config {
@@ -1330,6 +1596,20 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectedErr: `federation.federates_with["domain.test"].bundle_endpoint_url must use the HTTPS protocol; URL found: "http://example.org/test"`,
 		},
+		{
+			name: "bundle_signature_url must use the HTTPS protocol",
+			applyConf: func(c *Config) {
+				federatesWith := make(map[string]federatesWithConfig)
+				federatesWith["domain.test"] = federatesWithConfig{
+					BundleEndpointURL:  "https://example.org/test",
+					BundleSignatureURL: "http://example.org/test.jws",
+				}
+				c.Server.Federation = &federationConfig{
+					FederatesWith: federatesWith,
+				}
+			},
+			expectedErr: `federation.federates_with["domain.test"].bundle_signature_url must use the HTTPS protocol; URL found: "http://example.org/test.jws"`,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -0,0 +1,78 @@
+package x509
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	serverutil "github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
+)
+
+func NewRevokeCommand() cli.Command {
+	return newRevokeCommand(commoncli.DefaultEnv)
+}
+
+func newRevokeCommand(env *commoncli.Env) cli.Command {
+	return serverutil.AdaptCommand(env, &revokeCommand{env: env})
+}
+
+type revokeCommand struct {
+	authorityID  string
+	serialNumber string
+	env          *commoncli.Env
+	printer      cliprinter.Printer
+}
+
+func (c *revokeCommand) Name() string {
+	return "x509 revoke"
+}
+
+func (c *revokeCommand) Synopsis() string {
+	return "Revokes an X509-SVID, publishing its revocation through the CRLs and OCSP responder of the server"
+}
+
+func (c *revokeCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.authorityID, "authorityID", "", "Authority ID of the X.509 authority that issued the X509-SVID, i.e. its authority key identifier, in hexadecimal")
+	fs.StringVar(&c.serialNumber, "serial", "", "Serial number of the X509-SVID to revoke, in hexadecimal (e.g. 4a:3b:01)")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintRevoke)
+}
+
+func (c *revokeCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if c.authorityID == "" {
+		return errors.New("authorityID must be specified")
+	}
+	if c.serialNumber == "" {
+		return errors.New("serial must be specified")
+	}
+
+	client := serverClient.NewSVIDRevocationClient()
+	resp, err := client.RevokeX509SVID(ctx, &svidrevocationv1.RevokeX509SVIDRequest{
+		AuthorityId:  c.authorityID,
+		SerialNumber: c.serialNumber,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to revoke X509-SVID: %w", err)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+func prettyPrintRevoke(env *commoncli.Env, results ...any) error {
+	r, ok := results[0].(*svidrevocationv1.RevokeX509SVIDResponse)
+	if !ok || r.RevokedX509Svid == nil {
+		return errors.New("internal error: cli printer; please report this bug")
+	}
+
+	env.Println("Revoked X509-SVID:")
+	env.Printf("  Authority ID: %s\n", r.RevokedX509Svid.AuthorityId)
+	env.Printf("  Serial number: %s\n", r.RevokedX509Svid.SerialNumber)
+	env.Printf("  Revoked at: %s\n", time.Unix(r.RevokedX509Svid.RevokedAt, 0).UTC())
+	env.Printf("  Revocation expires at: %s\n", time.Unix(r.RevokedX509Svid.ExpiresAt, 0).UTC())
+	return nil
+}
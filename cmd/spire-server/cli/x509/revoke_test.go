@@ -0,0 +1,132 @@
+package x509
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevokeSynopsis(t *testing.T) {
+	cmd := NewRevokeCommand()
+	assert.Equal(t, "Revokes an X509-SVID, publishing its revocation through the CRLs and OCSP responder of the server", cmd.Synopsis())
+}
+
+func TestRevokeHelp(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := newRevokeCommand(&common_cli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	assert.Equal(t, "flag: help requested", cmd.Help())
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "Usage of x509 revoke:")
+	assert.Contains(t, stderr.String(), `  -authorityID string
+    	Authority ID of the X.509 authority that issued the X509-SVID, i.e. its authority key identifier, in hexadecimal
+`)
+	assert.Contains(t, stderr.String(), `  -serial string
+    	Serial number of the X509-SVID to revoke, in hexadecimal (e.g. 4a:3b:01)
+`)
+}
+
+func TestRevokeRun(t *testing.T) {
+	server := new(fakeSVIDRevocationServer)
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		svidrevocationv1.RegisterSVIDRevocationServer(s, server)
+	})
+
+	for _, tt := range []struct {
+		name            string
+		args            []string
+		serverErr       error
+		expCode         int
+		expStdoutPretty string
+		expStdoutJSON   string
+		expStderr       string
+	}{
+		{
+			name:    "success",
+			args:    []string{"-authorityID", "0a1b", "-serial", "4a3b01"},
+			expCode: 0,
+			expStdoutPretty: `Revoked X509-SVID:
+  Authority ID: 0a1b
+  Serial number: 4a3b01
+  Revoked at: 1970-01-01 00:16:40 +0000 UTC
+  Revocation expires at: 1970-01-01 00:33:20 +0000 UTC
+`,
+			expStdoutJSON: `{"revoked_x509_svid":{"serial_number":"4a3b01","revoked_at":"1000","expires_at":"2000","authority_id":"0a1b"}}`,
+		},
+		{
+			name:      "missing authority ID",
+			args:      []string{"-serial", "0a"},
+			expCode:   1,
+			expStderr: "Error: authorityID must be specified\n",
+		},
+		{
+			name:      "missing serial",
+			args:      []string{"-authorityID", "0a1b"},
+			expCode:   1,
+			expStderr: "Error: serial must be specified\n",
+		},
+		{
+			name:      "server error",
+			args:      []string{"-authorityID", "0a1b", "-serial", "0a"},
+			serverErr: status.Error(codes.AlreadyExists, "X509-SVID is already revoked"),
+			expCode:   1,
+			expStderr: "Error: unable to revoke X509-SVID: rpc error: code = AlreadyExists desc = X509-SVID is already revoked\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				server.err = tt.serverErr
+				stdout := new(bytes.Buffer)
+				stderr := new(bytes.Buffer)
+				cmd := newRevokeCommand(&common_cli.Env{
+					Stdin:  new(bytes.Buffer),
+					Stdout: stdout,
+					Stderr: stderr,
+				})
+
+				args := []string{clitest.AddrArg, clitest.GetAddr(addr), "-output", format}
+				code := cmd.Run(append(args, tt.args...))
+
+				assert.Equal(t, tt.expCode, code, "exit code does not match")
+				assert.Equal(t, tt.expStderr, stderr.String(), "stderr does not match")
+				if tt.expCode == 0 {
+					requireOutputBasedOnFormat(t, format, stdout.String(), tt.expStdoutPretty, tt.expStdoutJSON)
+				}
+			})
+		}
+	}
+}
+
+type fakeSVIDRevocationServer struct {
+	svidrevocationv1.UnimplementedSVIDRevocationServer
+
+	err error
+}
+
+func (f *fakeSVIDRevocationServer) RevokeX509SVID(_ context.Context, req *svidrevocationv1.RevokeX509SVIDRequest) (*svidrevocationv1.RevokeX509SVIDResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &svidrevocationv1.RevokeX509SVIDResponse{
+		RevokedX509Svid: &svidrevocationv1.RevokedX509SVID{
+			AuthorityId:  req.AuthorityId,
+			SerialNumber: req.SerialNumber,
+			RevokedAt:    1000,
+			ExpiresAt:    2000,
+		},
+	}, nil
+}
@@ -20,6 +20,9 @@ import (
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/jwtutil"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -43,13 +46,16 @@ func NewGRPCClient(addr string) (*grpc.ClientConn, error) {
 type ServerClient interface {
 	Release()
 	NewAgentClient() agentv1.AgentClient
+	NewAgentBatchClient() agentbatchv1.AgentBatchClient
 	NewBundleClient() bundlev1.BundleClient
 	NewEntryClient() entryv1.EntryClient
+	NewEntryDryRunClient() entrydryrunv1.EntryDryRunClient
 	NewLoggerClient() loggerv1.LoggerClient
 	NewSVIDClient() svidv1.SVIDClient
 	NewTrustDomainClient() trustdomainv1.TrustDomainClient
 	NewLocalAuthorityClient() localauthorityv1.LocalAuthorityClient
 	NewHealthClient() grpc_health_v1.HealthClient
+	NewSVIDRevocationClient() svidrevocationv1.SVIDRevocationClient
 }
 
 func NewServerClient(addr string) (ServerClient, error) {
@@ -72,6 +78,10 @@ func (c *serverClient) NewAgentClient() agentv1.AgentClient {
 	return agentv1.NewAgentClient(c.conn)
 }
 
+func (c *serverClient) NewAgentBatchClient() agentbatchv1.AgentBatchClient {
+	return agentbatchv1.NewAgentBatchClient(c.conn)
+}
+
 func (c *serverClient) NewBundleClient() bundlev1.BundleClient {
 	return bundlev1.NewBundleClient(c.conn)
 }
@@ -80,6 +90,10 @@ func (c *serverClient) NewEntryClient() entryv1.EntryClient {
 	return entryv1.NewEntryClient(c.conn)
 }
 
+func (c *serverClient) NewEntryDryRunClient() entrydryrunv1.EntryDryRunClient {
+	return entrydryrunv1.NewEntryDryRunClient(c.conn)
+}
+
 func (c *serverClient) NewLoggerClient() loggerv1.LoggerClient {
 	return loggerv1.NewLoggerClient(c.conn)
 }
@@ -100,6 +114,10 @@ func (c *serverClient) NewLocalAuthorityClient() localauthorityv1.LocalAuthority
 	return localauthorityv1.NewLocalAuthorityClient(c.conn)
 }
 
+func (c *serverClient) NewSVIDRevocationClient() svidrevocationv1.SVIDRevocationClient {
+	return svidrevocationv1.NewSVIDRevocationClient(c.conn)
+}
+
 // Pluralizer concatenates `singular` to `msg` when `val` is one, and
 // `plural` on all other occasions. It is meant to facilitate friendlier
 // CLI output.
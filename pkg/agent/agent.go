@@ -14,6 +14,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	admin_api "github.com/spiffe/spire/pkg/agent/api"
+	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	node_attestor "github.com/spiffe/spire/pkg/agent/attestor/node"
 	workload_attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
 	"github.com/spiffe/spire/pkg/agent/catalog"
@@ -73,15 +74,28 @@ func (a *Agent) Run(ctx context.Context) error {
 		defer stopProfiling()
 	}
 
-	metrics, err := telemetry.NewMetrics(&telemetry.MetricsConfig{
+	telemetryConfig := &telemetry.MetricsConfig{
 		FileConfig:  a.c.Telemetry,
 		Logger:      a.c.Log.WithField(telemetry.SubsystemName, telemetry.Telemetry),
 		ServiceName: telemetry.SpireAgent,
 		TrustDomain: a.c.TrustDomain.Name(),
-	})
+	}
+
+	metrics, err := telemetry.NewMetrics(telemetryConfig)
 	if err != nil {
 		return err
 	}
+
+	shutdownTracing, err := telemetry.InitTracing(ctx, telemetryConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			a.c.Log.WithError(err).Warn("Failed to shut down tracing")
+		}
+	}()
+
 	telemetry.EmitStarted(metrics, a.c.TrustDomain)
 	uptime.ReportMetrics(ctx, metrics)
 
@@ -177,7 +191,10 @@ func (a *Agent) Run(ctx context.Context) error {
 	}
 
 	if a.c.AdminBindAddress != nil {
-		adminEndpoints := a.newAdminEndpoints(metrics, manager, workloadAttestor, a.c.AuthorizedDelegates)
+		adminEndpoints, err := a.newAdminEndpoints(ctx, metrics, manager, workloadAttestor)
+		if err != nil {
+			return err
+		}
 		tasks = append(tasks, adminEndpoints.ListenAndServe)
 	}
 
@@ -267,6 +284,15 @@ func (a *Agent) attest(ctx context.Context, sto storage.Storage, cat catalog.Cat
 }
 
 func (a *Agent) newManager(ctx context.Context, sto storage.Storage, cat catalog.Catalog, metrics telemetry.Metrics, as *node_attestor.AttestationResult, cache *storecache.Cache, na nodeattestor.NodeAttestor) (manager.Manager, error) {
+	var workloadCache *storage.WorkloadCache
+	if a.c.OfflineCacheKeyFile != "" {
+		var err error
+		workloadCache, err = storage.OpenWorkloadCache(a.c.DataDir, a.c.OfflineCacheKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open offline cache: %w", err)
+		}
+	}
+
 	config := &manager.Config{
 		SVID:                     as.SVID,
 		SVIDKey:                  as.Key,
@@ -279,6 +305,7 @@ func (a *Agent) newManager(ctx context.Context, sto storage.Storage, cat catalog
 		Metrics:                  metrics,
 		WorkloadKeyType:          a.c.WorkloadKeyType,
 		Storage:                  sto,
+		WorkloadCache:            workloadCache,
 		SyncInterval:             a.c.SyncInterval,
 		UseSyncAuthorizedEntries: a.c.UseSyncAuthorizedEntries,
 		X509SVIDCacheMaxSize:     a.c.X509SVIDCacheMaxSize,
@@ -372,19 +399,29 @@ func (a *Agent) newEndpoints(metrics telemetry.Metrics, mgr manager.Manager, att
 	})
 }
 
-func (a *Agent) newAdminEndpoints(metrics telemetry.Metrics, mgr manager.Manager, attestor workload_attestor.Attestor, authorizedDelegates []string) admin_api.Server {
+func (a *Agent) newAdminEndpoints(ctx context.Context, metrics telemetry.Metrics, mgr manager.Manager, attestor workload_attestor.Attestor) (admin_api.Server, error) {
 	config := &admin_api.Config{
-		BindAddr:            a.c.AdminBindAddress,
-		Manager:             mgr,
-		Log:                 a.c.Log,
-		Metrics:             metrics,
-		TrustDomain:         a.c.TrustDomain,
-		Uptime:              uptime.Uptime,
-		Attestor:            attestor,
-		AuthorizedDelegates: authorizedDelegates,
+		BindAddr:                 a.c.AdminBindAddress,
+		Manager:                  mgr,
+		Log:                      a.c.Log,
+		Metrics:                  metrics,
+		TrustDomain:              a.c.TrustDomain,
+		Uptime:                   uptime.Uptime,
+		Attestor:                 attestor,
+		AuthorizedDelegates:      a.c.AuthorizedDelegates,
+		AuthorizedDelegateScopes: a.c.AuthorizedDelegateScopes,
+		AuthorizedCacheAdmins:    a.c.AuthorizedCacheAdmins,
+	}
+
+	if a.c.AuthorizedDelegatePolicy != nil {
+		policyEngine, err := delegatedidentityv1.NewPolicyEngine(ctx, *a.c.AuthorizedDelegatePolicy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create authorized delegate policy engine: %w", err)
+		}
+		config.AuthorizedDelegateAuthorizer = policyEngine
 	}
 
-	return admin_api.New(config)
+	return admin_api.New(config), nil
 }
 
 // CheckHealth is used as a top-level health check for the agent.
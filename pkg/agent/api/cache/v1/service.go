@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	managercache "github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/util"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers cache service on provided server
+func RegisterService(s grpc.ServiceRegistrar, service *Service) {
+	cachev1.RegisterCacheServer(s, service)
+}
+
+// Attestor attests the caller of the service
+type Attestor interface {
+	Attest(ctx context.Context) ([]*common.Selector, error)
+}
+
+// Config configurations for cache service
+type Config struct {
+	Log      logrus.FieldLogger
+	Manager  manager.Manager
+	Attestor Attestor
+
+	// AuthorizedAdmins are the SPIFFE IDs of the workloads that are
+	// authorized to use the service. If empty, no caller is authorized.
+	AuthorizedAdmins []string
+}
+
+// New creates a new cache service
+func New(config Config) *Service {
+	authorizedAdmins := make(map[string]bool)
+	for _, admin := range config.AuthorizedAdmins {
+		authorizedAdmins[admin] = true
+	}
+
+	return &Service{
+		log:              config.Log,
+		m:                config.Manager,
+		attestor:         config.Attestor,
+		authorizedAdmins: authorizedAdmins,
+	}
+}
+
+// Service implements cache server
+type Service struct {
+	cachev1.UnsafeCacheServer
+
+	log      logrus.FieldLogger
+	m        manager.Manager
+	attestor Attestor
+
+	// SPIFFE IDs of the workloads that are authorized to use this API
+	authorizedAdmins map[string]bool
+}
+
+// ListEntries lists the registration entries cached by the agent
+func (s *Service) ListEntries(ctx context.Context, _ *cachev1.ListEntriesRequest) (*cachev1.ListEntriesResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	cachedEntries := s.m.CachedEntries()
+
+	entries := make([]*cachev1.Entry, 0, len(cachedEntries))
+	for _, cachedEntry := range cachedEntries {
+		entry, err := entryToProto(cachedEntry)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		entries = append(entries, entry)
+	}
+
+	return &cachev1.ListEntriesResponse{
+		Entries: entries,
+	}, nil
+}
+
+// GetEntry gets a registration entry cached by the agent
+func (s *Service) GetEntry(ctx context.Context, req *cachev1.GetEntryRequest) (*cachev1.GetEntryResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing entry ID")
+	}
+
+	cachedEntry, ok := s.m.CachedEntry(req.Id)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "entry not found")
+	}
+
+	entry, err := entryToProto(cachedEntry)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &cachev1.GetEntryResponse{
+		Entry: entry,
+	}, nil
+}
+
+// ListSubscribers lists the workload selector sets with active subscribers
+func (s *Service) ListSubscribers(ctx context.Context, _ *cachev1.ListSubscribersRequest) (*cachev1.ListSubscribersResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	subscriberSets := s.m.CacheSubscribers()
+
+	resp := &cachev1.ListSubscribersResponse{
+		SubscriberSets: make([]*cachev1.SubscriberSet, 0, len(subscriberSets)),
+	}
+	for _, subscriberSet := range subscriberSets {
+		count, err := util.CheckedCast[int32](subscriberSet.Count)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "out of range value for subscribers count: %v", err)
+		}
+		resp.SubscriberSets = append(resp.SubscriberSets, &cachev1.SubscriberSet{
+			Selectors: selectorsToProto(subscriberSet.Selectors),
+			Count:     count,
+		})
+	}
+
+	return resp, nil
+}
+
+// Resync synchronizes the authorized entries and bundles with the server
+func (s *Service) Resync(ctx context.Context, _ *cachev1.ResyncRequest) (*cachev1.ResyncResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.m.Resync(ctx); err != nil {
+		s.log.WithError(err).Error("Failed to synchronize")
+		return nil, status.Errorf(codes.Internal, "failed to synchronize: %v", err)
+	}
+
+	s.log.Info("Synchronized authorized entries on request")
+	return &cachev1.ResyncResponse{
+		LastSync: s.m.GetLastSync().UTC().Unix(),
+	}, nil
+}
+
+// RotateX509SVID forces the rotation of the X509-SVID cached for an entry
+func (s *Service) RotateX509SVID(ctx context.Context, req *cachev1.RotateX509SVIDRequest) (*cachev1.RotateX509SVIDResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing entry ID")
+	}
+
+	if err := s.m.RotateX509SVID(req.Id); err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to rotate X509-SVID: %v", err)
+	}
+
+	return &cachev1.RotateX509SVIDResponse{}, nil
+}
+
+// EvictStaleJWTSVIDs removes the stale JWT-SVIDs from the JWT-SVID cache
+func (s *Service) EvictStaleJWTSVIDs(ctx context.Context, _ *cachev1.EvictStaleJWTSVIDsRequest) (*cachev1.EvictStaleJWTSVIDsResponse, error) {
+	if err := s.authorizeCaller(ctx); err != nil {
+		return nil, err
+	}
+
+	evicted := s.m.EvictStaleJWTSVIDs()
+	s.log.WithField(telemetry.Count, evicted).Info("Evicted stale JWT-SVIDs from cache")
+
+	count, err := util.CheckedCast[int32](evicted)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "out of range value for evicted JWT-SVIDs count: %v", err)
+	}
+
+	return &cachev1.EvictStaleJWTSVIDsResponse{
+		Evicted: count,
+	}, nil
+}
+
+// authorizeCaller attests the caller and checks that it is issued the SPIFFE
+// ID of an authorized admin.
+func (s *Service) authorizeCaller(ctx context.Context) error {
+	selectors, err := s.attestor.Attest(ctx)
+	if err != nil {
+		s.log.WithError(err).Error("Workload attestation failed")
+		return status.Error(codes.Internal, "workload attestation failed")
+	}
+
+	for _, entry := range s.m.MatchingRegistrationEntries(selectors) {
+		if s.authorizedAdmins[entry.SpiffeId] {
+			return nil
+		}
+	}
+
+	s.log.WithField(telemetry.Selectors, selectors).Error("Permission denied; caller not configured as an authorized cache admin")
+	return status.Error(codes.PermissionDenied, "caller not configured as an authorized cache admin")
+}
+
+func entryToProto(cachedEntry *managercache.CachedEntry) (*cachev1.Entry, error) {
+	subscribers, err := util.CheckedCast[int32](cachedEntry.Subscribers)
+	if err != nil {
+		return nil, fmt.Errorf("out of range value for subscribers count: %w", err)
+	}
+
+	entry := cachedEntry.Entry
+	var expiresAt int64
+	if !cachedEntry.SVIDExpiresAt.IsZero() {
+		expiresAt = cachedEntry.SVIDExpiresAt.Unix()
+	}
+
+	return &cachev1.Entry{
+		Id:                entry.EntryId,
+		SpiffeId:          entry.SpiffeId,
+		ParentId:          entry.ParentId,
+		Selectors:         selectorsToProto(entry.Selectors),
+		Hint:              entry.Hint,
+		FederatesWith:     entry.FederatesWith,
+		RevisionNumber:    entry.RevisionNumber,
+		X509SvidExpiresAt: expiresAt,
+		Stale:             cachedEntry.Stale,
+		Subscribers:       subscribers,
+	}, nil
+}
+
+func selectorsToProto(selectors []*common.Selector) []*cachev1.Selector {
+	out := make([]*cachev1.Selector, 0, len(selectors))
+	for _, selector := range selectors {
+		out = append(out, &cachev1.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	return out
+}
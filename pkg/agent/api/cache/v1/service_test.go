@@ -0,0 +1,468 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	cache "github.com/spiffe/spire/pkg/agent/api/cache/v1"
+	"github.com/spiffe/spire/pkg/agent/manager"
+	managercache "github.com/spiffe/spire/pkg/agent/manager/cache"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	cachev1 "github.com/spiffe/spire/proto/spire/api/agent/cache/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/grpctest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ctx = context.Background()
+
+	expiresAt = time.Unix(1700000000, 0)
+
+	adminID         = "spiffe://example.org/admin"
+	callerSelectors = []*common.Selector{{Type: "unix", Value: "uid:0"}}
+
+	fooEntry = &managercache.CachedEntry{
+		Entry: &common.RegistrationEntry{
+			EntryId:        "FOO",
+			SpiffeId:       "spiffe://example.org/foo",
+			ParentId:       "spiffe://example.org/spire/agent/foo",
+			Selectors:      []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			Hint:           "external",
+			FederatesWith:  []string{"spiffe://domain.test"},
+			RevisionNumber: 3,
+		},
+		SVIDExpiresAt: expiresAt,
+		Subscribers:   2,
+	}
+	barEntry = &managercache.CachedEntry{
+		Entry: &common.RegistrationEntry{
+			EntryId:   "BAR",
+			SpiffeId:  "spiffe://example.org/bar",
+			ParentId:  "spiffe://example.org/spire/agent/foo",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+		},
+		Stale: true,
+	}
+
+	fooProto = &cachev1.Entry{
+		Id:                "FOO",
+		SpiffeId:          "spiffe://example.org/foo",
+		ParentId:          "spiffe://example.org/spire/agent/foo",
+		Selectors:         []*cachev1.Selector{{Type: "unix", Value: "uid:1000"}},
+		Hint:              "external",
+		FederatesWith:     []string{"spiffe://domain.test"},
+		RevisionNumber:    3,
+		X509SvidExpiresAt: expiresAt.Unix(),
+		Subscribers:       2,
+	}
+	barProto = &cachev1.Entry{
+		Id:        "BAR",
+		SpiffeId:  "spiffe://example.org/bar",
+		ParentId:  "spiffe://example.org/spire/agent/foo",
+		Selectors: []*cachev1.Selector{{Type: "unix", Value: "uid:1001"}},
+		Stale:     true,
+	}
+)
+
+func TestAuthorization(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		attestErr     error
+		callerEntries []*common.RegistrationEntry
+		code          codes.Code
+		err           string
+		expectedLogs  []spiretest.LogEntry
+	}{
+		{
+			name:          "authorized admin",
+			callerEntries: []*common.RegistrationEntry{{SpiffeId: "spiffe://example.org/workload"}, {SpiffeId: adminID}},
+		},
+		{
+			name:      "attestation fails",
+			attestErr: errors.New("oh no"),
+			code:      codes.Internal,
+			err:       "workload attestation failed",
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Workload attestation failed",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "oh no",
+					},
+				},
+			},
+		},
+		{
+			name:          "caller is not an authorized admin",
+			callerEntries: []*common.RegistrationEntry{{SpiffeId: "spiffe://example.org/workload"}},
+			code:          codes.PermissionDenied,
+			err:           "caller not configured as an authorized cache admin",
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Permission denied; caller not configured as an authorized cache admin",
+					Data: logrus.Fields{
+						telemetry.Selectors: fmt.Sprint(callerSelectors),
+					},
+				},
+			},
+		},
+		{
+			name: "caller has no identity",
+			code: codes.PermissionDenied,
+			err:  "caller not configured as an authorized cache admin",
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Permission denied; caller not configured as an authorized cache admin",
+					Data: logrus.Fields{
+						telemetry.Selectors: fmt.Sprint(callerSelectors),
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+			test.attestor.err = tt.attestErr
+			test.m.callerEntries = tt.callerEntries
+			test.m.staleJWTSVIDs = 1
+
+			_, err := test.client.EvictStaleJWTSVIDs(ctx, &cachev1.EvictStaleJWTSVIDsRequest{})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectedLogs)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestListEntries(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	resp, err := test.client.ListEntries(ctx, &cachev1.ListEntriesRequest{})
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, &cachev1.ListEntriesResponse{Entries: []*cachev1.Entry{}}, resp)
+
+	test.m.entries = []*managercache.CachedEntry{barEntry, fooEntry}
+	resp, err = test.client.ListEntries(ctx, &cachev1.ListEntriesRequest{})
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, &cachev1.ListEntriesResponse{
+		Entries: []*cachev1.Entry{barProto, fooProto},
+	}, resp)
+}
+
+func TestGetEntry(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		id         string
+		code       codes.Code
+		err        string
+		expectResp *cachev1.GetEntryResponse
+	}{
+		{
+			name:       "success",
+			id:         "FOO",
+			expectResp: &cachev1.GetEntryResponse{Entry: fooProto},
+		},
+		{
+			name: "missing entry ID",
+			code: codes.InvalidArgument,
+			err:  "missing entry ID",
+		},
+		{
+			name: "entry not found",
+			id:   "UNKNOWN",
+			code: codes.NotFound,
+			err:  "entry not found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+			test.m.entries = []*managercache.CachedEntry{barEntry, fooEntry}
+
+			resp, err := test.client.GetEntry(ctx, &cachev1.GetEntryRequest{Id: tt.id})
+			if tt.err != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestListSubscribers(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	test.m.subscribers = []*managercache.SubscriberSet{
+		{
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}, {Type: "unix", Value: "gid:1000"}},
+			Count:     2,
+		},
+		{
+			Selectors: []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+			Count:     1,
+		},
+	}
+
+	resp, err := test.client.ListSubscribers(ctx, &cachev1.ListSubscribersRequest{})
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, &cachev1.ListSubscribersResponse{
+		SubscriberSets: []*cachev1.SubscriberSet{
+			{
+				Selectors: []*cachev1.Selector{{Type: "unix", Value: "uid:1000"}, {Type: "unix", Value: "gid:1000"}},
+				Count:     2,
+			},
+			{
+				Selectors: []*cachev1.Selector{{Type: "k8s", Value: "ns:default"}},
+				Count:     1,
+			},
+		},
+	}, resp)
+}
+
+func TestResync(t *testing.T) {
+	lastSync := time.Unix(1700000000, 0)
+
+	for _, tt := range []struct {
+		name         string
+		resyncErr    error
+		code         codes.Code
+		err          string
+		expectResp   *cachev1.ResyncResponse
+		expectedLogs []spiretest.LogEntry
+	}{
+		{
+			name:       "success",
+			expectResp: &cachev1.ResyncResponse{LastSync: lastSync.Unix()},
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Synchronized authorized entries on request",
+				},
+			},
+		},
+		{
+			name:      "synchronization fails",
+			resyncErr: errors.New("oh no"),
+			code:      codes.Internal,
+			err:       "failed to synchronize: oh no",
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to synchronize",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "oh no",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+			test.m.lastSync = lastSync
+			test.m.resyncErr = tt.resyncErr
+
+			resp, err := test.client.Resync(ctx, &cachev1.ResyncRequest{})
+			require.True(t, test.m.resynced)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectedLogs)
+			if tt.err != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.RequireProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestRotateX509SVID(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		id            string
+		code          codes.Code
+		err           string
+		expectRotated []string
+	}{
+		{
+			name:          "success",
+			id:            "FOO",
+			expectRotated: []string{"FOO"},
+		},
+		{
+			name: "missing entry ID",
+			code: codes.InvalidArgument,
+			err:  "missing entry ID",
+		},
+		{
+			name: "entry not found",
+			id:   "UNKNOWN",
+			code: codes.NotFound,
+			err:  `failed to rotate X509-SVID: no SVID can be rotated for entry "UNKNOWN"`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+			test.m.entries = []*managercache.CachedEntry{barEntry, fooEntry}
+
+			resp, err := test.client.RotateX509SVID(ctx, &cachev1.RotateX509SVIDRequest{Id: tt.id})
+			require.Equal(t, tt.expectRotated, test.m.rotated)
+			if tt.err != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.RequireProtoEqual(t, &cachev1.RotateX509SVIDResponse{}, resp)
+		})
+	}
+}
+
+func TestEvictStaleJWTSVIDs(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+	test.m.staleJWTSVIDs = 3
+
+	resp, err := test.client.EvictStaleJWTSVIDs(ctx, &cachev1.EvictStaleJWTSVIDsRequest{})
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, &cachev1.EvictStaleJWTSVIDsResponse{Evicted: 3}, resp)
+	spiretest.AssertLogs(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Evicted stale JWT-SVIDs from cache",
+			Data: logrus.Fields{
+				"count": "3",
+			},
+		},
+	})
+}
+
+type serviceTest struct {
+	client cachev1.CacheClient
+	done   func()
+
+	logHook  *test.Hook
+	m        *fakeManager
+	attestor *fakeAttestor
+}
+
+func (s *serviceTest) Cleanup() {
+	s.done()
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	manager := &fakeManager{
+		callerEntries: []*common.RegistrationEntry{{SpiffeId: adminID}},
+	}
+	attestor := &fakeAttestor{
+		selectors: callerSelectors,
+	}
+	log, logHook := test.NewNullLogger()
+	log.Level = logrus.DebugLevel
+
+	service := cache.New(cache.Config{
+		Log:              log,
+		Manager:          manager,
+		Attestor:         attestor,
+		AuthorizedAdmins: []string{adminID},
+	})
+
+	test := &serviceTest{
+		logHook:  logHook,
+		m:        manager,
+		attestor: attestor,
+	}
+
+	registerFn := func(s grpc.ServiceRegistrar) {
+		cache.RegisterService(s, service)
+	}
+	server := grpctest.StartServer(t, registerFn)
+	test.done = server.Stop
+	test.client = cachev1.NewCacheClient(server.NewGRPCClient(t))
+
+	return test
+}
+
+type fakeAttestor struct {
+	selectors []*common.Selector
+	err       error
+}
+
+func (a *fakeAttestor) Attest(context.Context) ([]*common.Selector, error) {
+	return a.selectors, a.err
+}
+
+type fakeManager struct {
+	manager.Manager
+
+	callerEntries []*common.RegistrationEntry
+	entries       []*managercache.CachedEntry
+	subscribers   []*managercache.SubscriberSet
+	lastSync      time.Time
+	resyncErr     error
+	resynced      bool
+	rotated       []string
+	staleJWTSVIDs int
+}
+
+func (m *fakeManager) MatchingRegistrationEntries([]*common.Selector) []*common.RegistrationEntry {
+	return m.callerEntries
+}
+
+func (m *fakeManager) CachedEntries() []*managercache.CachedEntry {
+	return m.entries
+}
+
+func (m *fakeManager) CachedEntry(id string) (*managercache.CachedEntry, bool) {
+	for _, entry := range m.entries {
+		if entry.Entry.EntryId == id {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+func (m *fakeManager) CacheSubscribers() []*managercache.SubscriberSet {
+	return m.subscribers
+}
+
+func (m *fakeManager) Resync(context.Context) error {
+	m.resynced = true
+	return m.resyncErr
+}
+
+func (m *fakeManager) RotateX509SVID(id string) error {
+	if _, ok := m.CachedEntry(id); !ok {
+		return fmt.Errorf("no SVID can be rotated for entry %q", id)
+	}
+	m.rotated = append(m.rotated, id)
+	return nil
+}
+
+func (m *fakeManager) EvictStaleJWTSVIDs() int {
+	return m.staleJWTSVIDs
+}
+
+func (m *fakeManager) GetLastSync() time.Time {
+	return m.lastSync
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	attestor "github.com/spiffe/spire/pkg/agent/attestor/workload"
 	"github.com/spiffe/spire/pkg/agent/manager"
 	"github.com/spiffe/spire/pkg/common/peertracker"
@@ -29,6 +30,17 @@ type Config struct {
 	Attestor attestor.Attestor
 
 	AuthorizedDelegates []string
+
+	// AuthorizedDelegateScopes limits the SVIDs authorized delegates can get
+	AuthorizedDelegateScopes map[spiffeid.ID]delegatedidentityv1.DelegateScope
+
+	// AuthorizedDelegateAuthorizer, if set, further limits the SVIDs
+	// authorized delegates can get
+	AuthorizedDelegateAuthorizer delegatedidentityv1.Authorizer
+
+	// AuthorizedCacheAdmins are the SPIFFE IDs of the workloads that can use
+	// the Cache API
+	AuthorizedCacheAdmins []string
 }
 
 func New(c *Config) *Endpoints {
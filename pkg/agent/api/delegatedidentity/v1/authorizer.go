@@ -0,0 +1,126 @@
+package delegatedidentity
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+// SVIDType is the type of SVID a delegate gets.
+type SVIDType string
+
+const (
+	X509SVIDType SVIDType = "x509"
+	JWTSVIDType  SVIDType = "jwt"
+)
+
+// Authorizer limits what authorized delegates can get SVIDs for. It is
+// consulted after the caller has been authorized as a delegate.
+type Authorizer interface {
+	// AuthorizeSelectors returns true if the delegate can request SVIDs
+	// for the selectors, whether they were provided by the delegate or
+	// attested by the agent from a PID provided by the delegate.
+	AuthorizeSelectors(ctx context.Context, delegateID spiffeid.ID, selectors []*common.Selector) (bool, error)
+
+	// AuthorizeSVID returns true if the delegate can get an SVID of the
+	// given type for the SPIFFE ID.
+	AuthorizeSVID(ctx context.Context, delegateID spiffeid.ID, svidType SVIDType, id spiffeid.ID) (bool, error)
+}
+
+// DelegateScope limits the SVIDs an authorized delegate can get. Empty
+// fields do not limit the delegate.
+type DelegateScope struct {
+	// SelectorTypes are the types of the selectors the delegate can
+	// provide, with any value.
+	SelectorTypes []string
+
+	// Selectors are the selectors the delegate can provide. A value ending
+	// with "*" matches any value with that prefix.
+	Selectors []*common.Selector
+
+	// SPIFFEIDPrefixes are the prefixes of the SPIFFE IDs the delegate can
+	// get SVIDs for. A SPIFFE ID matches a prefix if it is in the same trust
+	// domain and its path is the prefix path or is below it, matching whole
+	// path segments.
+	SPIFFEIDPrefixes []spiffeid.ID
+
+	// TrustDomains are the trust domains of the SPIFFE IDs the delegate can
+	// get SVIDs for.
+	TrustDomains []spiffeid.TrustDomain
+}
+
+func (s DelegateScope) allowsSelector(selector *common.Selector) bool {
+	if len(s.SelectorTypes) == 0 && len(s.Selectors) == 0 {
+		return true
+	}
+	if slices.Contains(s.SelectorTypes, selector.Type) {
+		return true
+	}
+	for _, allowed := range s.Selectors {
+		if allowed.Type != selector.Type {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(allowed.Value, "*"); ok {
+			if strings.HasPrefix(selector.Value, prefix) {
+				return true
+			}
+		} else if allowed.Value == selector.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s DelegateScope) allowsID(id spiffeid.ID) bool {
+	if len(s.TrustDomains) > 0 && !slices.Contains(s.TrustDomains, id.TrustDomain()) {
+		return false
+	}
+	if len(s.SPIFFEIDPrefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(s.SPIFFEIDPrefixes, func(prefix spiffeid.ID) bool {
+		return hasIDPrefix(id, prefix)
+	})
+}
+
+// hasIDPrefix returns true if the ID is the prefix, or is below the prefix
+// path in the same trust domain. The path is matched by whole segments, so
+// the prefix spiffe://example.org/app does not match
+// spiffe://example.org/app-admin.
+func hasIDPrefix(id, prefix spiffeid.ID) bool {
+	if id.TrustDomain() != prefix.TrustDomain() {
+		return false
+	}
+	if prefix.Path() == "" || id.Path() == prefix.Path() {
+		return true
+	}
+	return strings.HasPrefix(id.Path(), prefix.Path()+"/")
+}
+
+// scopeAuthorizer authorizes delegates with their scopes. Delegates without
+// a scope are not limited.
+type scopeAuthorizer map[spiffeid.ID]DelegateScope
+
+func (a scopeAuthorizer) AuthorizeSelectors(_ context.Context, delegateID spiffeid.ID, selectors []*common.Selector) (bool, error) {
+	scope, ok := a[delegateID]
+	if !ok {
+		return true, nil
+	}
+	for _, selector := range selectors {
+		if !scope.allowsSelector(selector) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (a scopeAuthorizer) AuthorizeSVID(_ context.Context, delegateID spiffeid.ID, _ SVIDType, id spiffeid.ID) (bool, error) {
+	scope, ok := a[delegateID]
+	if !ok {
+		return true, nil
+	}
+	return scope.allowsID(id), nil
+}
@@ -0,0 +1,147 @@
+package delegatedidentity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopeAuthorizer(t *testing.T) {
+	delegateID := spiffeid.RequireFromPath(trustDomain1, "/cni")
+	unscopedID := spiffeid.RequireFromPath(trustDomain1, "/unscoped")
+	prefixOnlyID := spiffeid.RequireFromPath(trustDomain1, "/prefix-only")
+
+	authorizer := scopeAuthorizer{
+		delegateID: {
+			SelectorTypes: []string{"unix"},
+			Selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:kube-system"},
+				{Type: "k8s", Value: "sa:cni-*"},
+			},
+			SPIFFEIDPrefixes: []spiffeid.ID{
+				spiffeid.RequireFromString("spiffe://example.org/ns/kube-system"),
+				spiffeid.RequireFromString("spiffe://domain.test"),
+			},
+			TrustDomains: []spiffeid.TrustDomain{trustDomain1},
+		},
+		prefixOnlyID: {
+			SPIFFEIDPrefixes: []spiffeid.ID{spiffeid.RequireFromString("spiffe://domain.test")},
+		},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		delegate  spiffeid.ID
+		selectors []*common.Selector
+		expect    bool
+	}{
+		{
+			name:      "selector type allowed",
+			delegate:  delegateID,
+			selectors: []*common.Selector{{Type: "unix", Value: "uid:0"}},
+			expect:    true,
+		},
+		{
+			name:     "selectors allowed",
+			delegate: delegateID,
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:kube-system"},
+				{Type: "k8s", Value: "sa:cni-agent"},
+			},
+			expect: true,
+		},
+		{
+			name:      "selector value not allowed",
+			delegate:  delegateID,
+			selectors: []*common.Selector{{Type: "k8s", Value: "ns:default"}},
+			expect:    false,
+		},
+		{
+			name:     "one selector not allowed",
+			delegate: delegateID,
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:kube-system"},
+				{Type: "docker", Value: "label:foo"},
+			},
+			expect: false,
+		},
+		{
+			name:      "delegate without scope",
+			delegate:  unscopedID,
+			selectors: []*common.Selector{{Type: "docker", Value: "label:foo"}},
+			expect:    true,
+		},
+	} {
+		t.Run("AuthorizeSelectors "+tt.name, func(t *testing.T) {
+			allowed, err := authorizer.AuthorizeSelectors(context.Background(), tt.delegate, tt.selectors)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, allowed)
+		})
+	}
+
+	for _, tt := range []struct {
+		name     string
+		delegate spiffeid.ID
+		id       string
+		expect   bool
+	}{
+		{
+			name:     "SPIFFE ID allowed",
+			delegate: delegateID,
+			id:       "spiffe://example.org/ns/kube-system/sa/coredns",
+			expect:   true,
+		},
+		{
+			name:     "SPIFFE ID prefix not matched",
+			delegate: delegateID,
+			id:       "spiffe://example.org/ns/default/sa/api",
+			expect:   false,
+		},
+		{
+			name:     "SPIFFE ID equal to prefix allowed",
+			delegate: delegateID,
+			id:       "spiffe://example.org/ns/kube-system",
+			expect:   true,
+		},
+		{
+			name:     "SPIFFE ID prefix matches whole path segments",
+			delegate: delegateID,
+			id:       "spiffe://example.org/ns/kube-system-admin/sa/api",
+			expect:   false,
+		},
+		{
+			name:     "SPIFFE ID prefix matches whole trust domain",
+			delegate: prefixOnlyID,
+			id:       "spiffe://domain.test.evil/api",
+			expect:   false,
+		},
+		{
+			name:     "SPIFFE ID prefix without path allowed",
+			delegate: prefixOnlyID,
+			id:       "spiffe://domain.test/api",
+			expect:   true,
+		},
+		{
+			name:     "trust domain not allowed",
+			delegate: delegateID,
+			id:       "spiffe://domain.test/api",
+			expect:   false,
+		},
+		{
+			name:     "delegate without scope",
+			delegate: unscopedID,
+			id:       "spiffe://domain.test/api",
+			expect:   true,
+		},
+	} {
+		t.Run("AuthorizeSVID "+tt.name, func(t *testing.T) {
+			allowed, err := authorizer.AuthorizeSVID(context.Background(), tt.delegate, X509SVIDType, spiffeid.RequireFromString(tt.id))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, allowed)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package delegatedidentity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/util"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+const (
+	allowSelectorsQuery = "data.spire.delegatedidentity.allow_selectors"
+	allowSVIDQuery      = "data.spire.delegatedidentity.allow_svid"
+)
+
+// PolicyEngineConfig configures a policy engine from local files.
+type PolicyEngineConfig struct {
+	// RegoPath is the path to the rego.v1 policy
+	RegoPath string
+
+	// PolicyDataPath is the path to the JSON data used by the policy. It is
+	// optional.
+	PolicyDataPath string
+}
+
+// PolicyEngine is an Authorizer that evaluates an OPA rego policy. The
+// policy is in the "spire.delegatedidentity" package and defines the
+// "allow_selectors" and "allow_svid" rules. An undefined rule denies.
+type PolicyEngine struct {
+	allowSelectors rego.PreparedEvalQuery
+	allowSVID      rego.PreparedEvalQuery
+}
+
+// PolicySelectorsInput is the input of the "allow_selectors" rule.
+type PolicySelectorsInput struct {
+	// Delegate is the SPIFFE ID of the delegate
+	Delegate string `json:"delegate"`
+
+	// Selectors are the selectors provided by the delegate, or attested by
+	// the agent from the PID provided by the delegate
+	Selectors []PolicySelector `json:"selectors"`
+}
+
+// PolicySVIDInput is the input of the "allow_svid" rule.
+type PolicySVIDInput struct {
+	// Delegate is the SPIFFE ID of the delegate
+	Delegate string `json:"delegate"`
+
+	// SVIDType is the type of SVID, "x509" or "jwt"
+	SVIDType SVIDType `json:"svid_type"`
+
+	// SPIFFEID is the SPIFFE ID of the SVID
+	SPIFFEID string `json:"spiffe_id"`
+}
+
+type PolicySelector struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// NewPolicyEngine returns a policy engine for the policy and data files of
+// the configuration.
+func NewPolicyEngine(ctx context.Context, config PolicyEngineConfig) (*PolicyEngine, error) {
+	module, err := os.ReadFile(config.RegoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]any{}
+	if config.PolicyDataPath != "" {
+		dataFile, err := os.Open(config.PolicyDataPath)
+		if err != nil {
+			return nil, err
+		}
+		defer dataFile.Close()
+
+		if err := util.NewJSONDecoder(dataFile).Decode(&data); err != nil {
+			return nil, fmt.Errorf("error decoding JSON databindings: %w", err)
+		}
+	}
+
+	return NewPolicyEngineFromRego(ctx, string(module), inmem.NewFromObject(data))
+}
+
+// NewPolicyEngineFromRego returns a policy engine for the rego.v1 policy.
+func NewPolicyEngineFromRego(ctx context.Context, regoPolicy string, store storage.Store) (*PolicyEngine, error) {
+	prepare := func(query string) (rego.PreparedEvalQuery, error) {
+		return rego.New(
+			rego.Query(query),
+			rego.Module("delegatedidentity.rego", regoPolicy),
+			rego.Store(store),
+			rego.SetRegoVersion(ast.RegoV1),
+		).PrepareForEval(ctx)
+	}
+
+	allowSelectors, err := prepare(allowSelectorsQuery)
+	if err != nil {
+		return nil, err
+	}
+	allowSVID, err := prepare(allowSVIDQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PolicyEngine{
+		allowSelectors: allowSelectors,
+		allowSVID:      allowSVID,
+	}, nil
+}
+
+func (e *PolicyEngine) AuthorizeSelectors(ctx context.Context, delegateID spiffeid.ID, selectors []*common.Selector) (bool, error) {
+	input := PolicySelectorsInput{
+		Delegate:  delegateID.String(),
+		Selectors: []PolicySelector{},
+	}
+	for _, selector := range selectors {
+		input.Selectors = append(input.Selectors, PolicySelector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	return eval(ctx, e.allowSelectors, input)
+}
+
+func (e *PolicyEngine) AuthorizeSVID(ctx context.Context, delegateID spiffeid.ID, svidType SVIDType, id spiffeid.ID) (bool, error) {
+	return eval(ctx, e.allowSVID, PolicySVIDInput{
+		Delegate: delegateID.String(),
+		SVIDType: svidType,
+		SPIFFEID: id.String(),
+	})
+}
+
+func eval(ctx context.Context, query rego.PreparedEvalQuery, input any) (bool, error) {
+	rs, err := query.Eval(ctx, rego.EvalInput(input))
+	if err != nil {
+		return false, err
+	}
+
+	// The rule is undefined for the input
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return false, nil
+	}
+
+	allow, ok := rs[0].Expressions[0].Value.(bool)
+	if !ok {
+		return false, errors.New("policy: rule did not evaluate to a bool value")
+	}
+	return allow, nil
+}
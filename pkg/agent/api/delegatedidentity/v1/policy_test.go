@@ -0,0 +1,113 @@
+package delegatedidentity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+package spire.delegatedidentity
+
+allow_selectors if {
+	some selector in input.selectors
+	selector.type == "k8s"
+	input.delegate == data.delegates[_].id
+}
+
+allow_svid if {
+	some delegate in data.delegates
+	delegate.id == input.delegate
+	delegate.svid_type == input.svid_type
+	startswith(input.spiffe_id, delegate.prefix)
+}
+`
+
+const testPolicyData = `
+{
+	"delegates": [
+		{
+			"id": "spiffe://example.org/cni",
+			"svid_type": "x509",
+			"prefix": "spiffe://example.org/ns/kube-system/"
+		}
+	]
+}
+`
+
+func TestPolicyEngine(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	regoPath := filepath.Join(dir, "policy.rego")
+	dataPath := filepath.Join(dir, "data.json")
+	require.NoError(t, os.WriteFile(regoPath, []byte(testPolicy), 0600))
+	require.NoError(t, os.WriteFile(dataPath, []byte(testPolicyData), 0600))
+
+	engine, err := NewPolicyEngine(ctx, PolicyEngineConfig{
+		RegoPath:       regoPath,
+		PolicyDataPath: dataPath,
+	})
+	require.NoError(t, err)
+
+	delegateID := spiffeid.RequireFromPath(trustDomain1, "/cni")
+	otherID := spiffeid.RequireFromPath(trustDomain1, "/other")
+	kubeSystemID := spiffeid.RequireFromPath(trustDomain1, "/ns/kube-system/sa/coredns")
+	defaultID := spiffeid.RequireFromPath(trustDomain1, "/ns/default/sa/api")
+
+	t.Run("AuthorizeSelectors", func(t *testing.T) {
+		allowed, err := engine.AuthorizeSelectors(ctx, delegateID, []*common.Selector{{Type: "k8s", Value: "ns:kube-system"}})
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = engine.AuthorizeSelectors(ctx, delegateID, []*common.Selector{{Type: "unix", Value: "uid:0"}})
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = engine.AuthorizeSelectors(ctx, otherID, []*common.Selector{{Type: "k8s", Value: "ns:kube-system"}})
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("AuthorizeSVID", func(t *testing.T) {
+		allowed, err := engine.AuthorizeSVID(ctx, delegateID, X509SVIDType, kubeSystemID)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = engine.AuthorizeSVID(ctx, delegateID, JWTSVIDType, kubeSystemID)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = engine.AuthorizeSVID(ctx, delegateID, X509SVIDType, defaultID)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+}
+
+func TestNewPolicyEngine(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing policy file", func(t *testing.T) {
+		_, err := NewPolicyEngine(ctx, PolicyEngineConfig{RegoPath: filepath.Join(t.TempDir(), "missing.rego")})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err := NewPolicyEngineFromRego(ctx, "package spire.delegatedidentity\n\nallow_svid if {", inmem.New())
+		require.Error(t, err)
+	})
+
+	t.Run("non bool rule", func(t *testing.T) {
+		engine, err := NewPolicyEngineFromRego(ctx, "package spire.delegatedidentity\n\nallow_svid := \"yes\"", inmem.New())
+		require.NoError(t, err)
+
+		_, err = engine.AuthorizeSVID(ctx, spiffeid.RequireFromPath(trustDomain1, "/cni"), X509SVIDType, id1)
+		require.EqualError(t, err, "policy: rule did not evaluate to a bool value")
+	})
+}
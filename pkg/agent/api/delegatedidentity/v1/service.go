@@ -23,6 +23,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/telemetry/agent/adminapi"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/audit"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -44,6 +45,13 @@ type Config struct {
 	Manager             manager.Manager
 	Attestor            workloadattestor.Attestor
 	AuthorizedDelegates []string
+
+	// DelegateScopes limits the SVIDs authorized delegates can get
+	DelegateScopes map[spiffeid.ID]DelegateScope
+
+	// Authorizer, if set, further limits the SVIDs authorized delegates can
+	// get
+	Authorizer Authorizer
 }
 
 func New(config Config) *Service {
@@ -53,12 +61,21 @@ func New(config Config) *Service {
 		AuthorizedDelegates[delegate] = true
 	}
 
+	var authorizers []Authorizer
+	if len(config.DelegateScopes) > 0 {
+		authorizers = append(authorizers, scopeAuthorizer(config.DelegateScopes))
+	}
+	if config.Authorizer != nil {
+		authorizers = append(authorizers, config.Authorizer)
+	}
+
 	return &Service{
 		manager:                  config.Manager,
 		peerAttestor:             endpoints.PeerTrackerAttestor{Attestor: config.Attestor},
 		delegateWorkloadAttestor: config.Attestor,
 		metrics:                  config.Metrics,
 		authorizedDelegates:      AuthorizedDelegates,
+		authorizers:              authorizers,
 	}
 }
 
@@ -73,10 +90,14 @@ type Service struct {
 
 	// SPIFFE IDs of delegates that are authorized to use this API
 	authorizedDelegates map[string]bool
+
+	// authorizers limit what authorized delegates can get SVIDs for
+	authorizers []Authorizer
 }
 
 // isCallerAuthorized attests the caller based on the authorized delegates map.
-func (s *Service) isCallerAuthorized(ctx context.Context, log logrus.FieldLogger, cachedSelectors []*common.Selector) ([]*common.Selector, error) {
+// It returns the selectors and the SPIFFE ID of the delegate.
+func (s *Service) isCallerAuthorized(ctx context.Context, log logrus.FieldLogger, cachedSelectors []*common.Selector) ([]*common.Selector, spiffeid.ID, error) {
 	var err error
 	callerSelectors := cachedSelectors
 
@@ -84,7 +105,7 @@ func (s *Service) isCallerAuthorized(ctx context.Context, log logrus.FieldLogger
 		callerSelectors, err = s.peerAttestor.Attest(ctx)
 		if err != nil {
 			log.WithError(err).Error("Workload attestation failed")
-			return nil, status.Error(codes.Internal, "workload attestation failed")
+			return nil, spiffeid.ID{}, status.Error(codes.Internal, "workload attestation failed")
 		}
 	}
 
@@ -94,13 +115,18 @@ func (s *Service) isCallerAuthorized(ctx context.Context, log logrus.FieldLogger
 
 	if numRegisteredEntries == 0 {
 		log.Error("no identity issued")
-		return nil, status.Error(codes.PermissionDenied, "no identity issued")
+		return nil, spiffeid.ID{}, status.Error(codes.PermissionDenied, "no identity issued")
 	}
 
 	for _, entry := range entries {
 		if _, ok := s.authorizedDelegates[entry.SpiffeId]; ok {
+			delegateID, err := spiffeid.FromString(entry.SpiffeId)
+			if err != nil {
+				log.WithError(err).WithField("delegate_id", entry.SpiffeId).Error("Invalid delegate SPIFFE ID")
+				return nil, spiffeid.ID{}, status.Error(codes.Internal, "invalid delegate SPIFFE ID")
+			}
 			log.WithField("delegate_id", entry.SpiffeId).Debug("Caller authorized as delegate")
-			return callerSelectors, nil
+			return callerSelectors, delegateID, nil
 		}
 	}
 
@@ -110,10 +136,90 @@ func (s *Service) isCallerAuthorized(ctx context.Context, log logrus.FieldLogger
 		"default_id":             entries[0].SpiffeId,
 	}).Error("Permission denied; caller not configured as an authorized delegate.")
 
-	return nil, status.Error(codes.PermissionDenied, "caller not configured as an authorized delegate")
+	return nil, spiffeid.ID{}, status.Error(codes.PermissionDenied, "caller not configured as an authorized delegate")
+}
+
+// authorizeSelectors checks that the delegate can request SVIDs for the
+// selectors it provided. Denials are audit logged.
+func (s *Service) authorizeSelectors(ctx context.Context, log logrus.FieldLogger, delegateID spiffeid.ID, selectors []*common.Selector) error {
+	for _, authorizer := range s.authorizers {
+		allowed, err := authorizer.AuthorizeSelectors(ctx, delegateID, selectors)
+		if err != nil {
+			log.WithError(err).Error("Failed to authorize selectors")
+			return status.Error(codes.Internal, "failed to authorize selectors")
+		}
+		if !allowed {
+			err := status.Error(codes.PermissionDenied, "delegate not authorized for the provided selectors")
+			audit.New(log.WithFields(logrus.Fields{
+				"delegate_id":       delegateID.String(),
+				"request_selectors": selectors,
+			})).AuditWithError(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeSVID returns true if the delegate can get an SVID of the given type
+// for the SPIFFE ID. Denials are audit logged.
+func (s *Service) authorizeSVID(ctx context.Context, log logrus.FieldLogger, delegateID spiffeid.ID, svidType SVIDType, id spiffeid.ID) (bool, error) {
+	for _, authorizer := range s.authorizers {
+		allowed, err := authorizer.AuthorizeSVID(ctx, delegateID, svidType, id)
+		if err != nil {
+			log.WithError(err).WithField(telemetry.SPIFFEID, id.String()).Error("Failed to authorize SVID")
+			return false, status.Error(codes.Internal, "failed to authorize SVID")
+		}
+		if !allowed {
+			audit.New(log.WithFields(logrus.Fields{
+				"delegate_id":      delegateID.String(),
+				"svid_type":        string(svidType),
+				telemetry.SPIFFEID: id.String(),
+			})).AuditWithError(status.Error(codes.PermissionDenied, "delegate not authorized for the SVID"))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// authorizedUpdate returns the update with the X509-SVIDs the delegate is
+// authorized to get. The authorization decisions are recorded in decisions,
+// which is kept for the lifetime of the stream, so that each SPIFFE ID is
+// authorized, and each denial audit logged, only once per stream.
+func (s *Service) authorizedUpdate(ctx context.Context, log logrus.FieldLogger, delegateID spiffeid.ID, update *cache.WorkloadUpdate, decisions map[spiffeid.ID]bool) (*cache.WorkloadUpdate, error) {
+	if len(s.authorizers) == 0 {
+		return update, nil
+	}
+
+	authorized := *update
+	authorized.Identities = nil
+	for _, identity := range update.Identities {
+		// Admin and downstream SVIDs are not sent to the caller anyway
+		if identity.Entry.Admin || identity.Entry.Downstream {
+			authorized.Identities = append(authorized.Identities, identity)
+			continue
+		}
+
+		id, err := spiffeid.FromString(identity.Entry.SpiffeId)
+		if err != nil {
+			log.WithField(telemetry.SPIFFEID, identity.Entry.SpiffeId).WithError(err).Error("Invalid SPIFFE ID")
+			return nil, status.Errorf(codes.Internal, "invalid SPIFFE ID: %v", err)
+		}
+		allowed, ok := decisions[id]
+		if !ok {
+			allowed, err = s.authorizeSVID(ctx, log, delegateID, X509SVIDType, id)
+			if err != nil {
+				return nil, err
+			}
+			decisions[id] = allowed
+		}
+		if allowed {
+			authorized.Identities = append(authorized.Identities, identity)
+		}
+	}
+	return &authorized, nil
 }
 
-func (s *Service) constructValidSelectorsFromReq(ctx context.Context, log logrus.FieldLogger, reqPid int32, reqSelectors []*types.Selector) ([]*common.Selector, error) {
+func (s *Service) constructValidSelectorsFromReq(ctx context.Context, log logrus.FieldLogger, delegateID spiffeid.ID, reqPid int32, reqSelectors []*types.Selector) ([]*common.Selector, error) {
 	// If you set
 	// - both pid and selector args
 	// - neither of them
@@ -145,6 +251,12 @@ func (s *Service) constructValidSelectorsFromReq(ctx context.Context, log logrus
 		}
 	}
 
+	// The selectors attested from a PID are authorized like the ones
+	// provided by the delegate, since the delegate chooses the PID.
+	if err := s.authorizeSelectors(ctx, log, delegateID, selectors); err != nil {
+		return nil, err
+	}
+
 	return selectors, nil
 }
 
@@ -166,12 +278,12 @@ func (s *Service) SubscribeToX509SVIDs(req *delegatedidentityv1.SubscribeToX509S
 	log := rpccontext.Logger(ctx)
 	var receivedFirstUpdate bool
 
-	cachedSelectors, err := s.isCallerAuthorized(ctx, log, nil)
+	cachedSelectors, delegateID, err := s.isCallerAuthorized(ctx, log, nil)
 	if err != nil {
 		return err
 	}
 
-	selectors, err := s.constructValidSelectorsFromReq(ctx, log, req.Pid, req.Selectors)
+	selectors, err := s.constructValidSelectorsFromReq(ctx, log, delegateID, req.Pid, req.Selectors)
 	if err != nil {
 		return err
 	}
@@ -188,6 +300,7 @@ func (s *Service) SubscribeToX509SVIDs(req *delegatedidentityv1.SubscribeToX509S
 	}
 	defer subscriber.Finish()
 
+	decisions := make(map[spiffeid.ID]bool)
 	for {
 		select {
 		case update := <-subscriber.Updates():
@@ -197,7 +310,12 @@ func (s *Service) SubscribeToX509SVIDs(req *delegatedidentityv1.SubscribeToX509S
 				receivedFirstUpdate = true
 			}
 
-			if _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
+			if _, _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
+				return err
+			}
+
+			update, err := s.authorizedUpdate(ctx, log, delegateID, update, decisions)
+			if err != nil {
 				return err
 			}
 
@@ -292,7 +410,7 @@ func (s *Service) SubscribeToX509Bundles(_ *delegatedidentityv1.SubscribeToX509B
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	cachedSelectors, err := s.isCallerAuthorized(ctx, log, nil)
+	cachedSelectors, _, err := s.isCallerAuthorized(ctx, log, nil)
 	if err != nil {
 		return err
 	}
@@ -316,7 +434,7 @@ func (s *Service) SubscribeToX509Bundles(_ *delegatedidentityv1.SubscribeToX509B
 	for {
 		select {
 		case <-subscriber.Changes():
-			if _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
+			if _, _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
 				return err
 			}
 
@@ -357,11 +475,12 @@ func (s *Service) FetchJWTSVIDs(ctx context.Context, req *delegatedidentityv1.Fe
 		return nil, status.Error(codes.InvalidArgument, "audience must be specified")
 	}
 
-	if _, err = s.isCallerAuthorized(ctx, log, nil); err != nil {
+	_, delegateID, err := s.isCallerAuthorized(ctx, log, nil)
+	if err != nil {
 		return nil, err
 	}
 
-	selectors, err := s.constructValidSelectorsFromReq(ctx, log, req.Pid, req.Selectors)
+	selectors, err := s.constructValidSelectorsFromReq(ctx, log, delegateID, req.Pid, req.Selectors)
 	if err != nil {
 		return nil, err
 	}
@@ -376,6 +495,14 @@ func (s *Service) FetchJWTSVIDs(ctx context.Context, req *delegatedidentityv1.Fe
 			return nil, status.Errorf(codes.InvalidArgument, "invalid requested SPIFFE ID: %v", err)
 		}
 
+		allowed, err := s.authorizeSVID(ctx, log, delegateID, JWTSVIDType, spiffeID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+
 		loopLog := log.WithField(telemetry.SPIFFEID, spiffeID.String())
 
 		var svid *client.JWTSVID
@@ -411,7 +538,7 @@ func (s *Service) SubscribeToJWTBundles(_ *delegatedidentityv1.SubscribeToJWTBun
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	cachedSelectors, err := s.isCallerAuthorized(ctx, log, nil)
+	cachedSelectors, _, err := s.isCallerAuthorized(ctx, log, nil)
 	if err != nil {
 		return err
 	}
@@ -438,7 +565,7 @@ func (s *Service) SubscribeToJWTBundles(_ *delegatedidentityv1.SubscribeToJWTBun
 	for {
 		select {
 		case <-subscriber.Changes():
-			if _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
+			if _, _, err := s.isCallerAuthorized(ctx, log, cachedSelectors); err != nil {
 				return err
 			}
 			for td, bundle := range subscriber.Next() {
@@ -74,6 +74,7 @@ func TestSubscribeToX509SVIDs(t *testing.T) {
 		identities    []cache.Identity
 		updates       []*cache.WorkloadUpdate
 		authSpiffeID  []string
+		scopes        map[spiffeid.ID]DelegateScope
 		expectCode    codes.Code
 		expectMsg     string
 		attestErr     error
@@ -326,6 +327,53 @@ func TestSubscribeToX509SVIDs(t *testing.T) {
 			},
 			expectMetrics: generateSubscribeToX509SVIDMetrics(),
 		},
+		{
+			testName:     "selectors outside of delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {SelectorTypes: []string{"k8s"}},
+			},
+			identities: []cache.Identity{
+				identities[0],
+			},
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "delegate not authorized for the provided selectors",
+		},
+		{
+			testName:     "workload update limited to delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {
+					Selectors:        []*common.Selector{{Type: "sa", Value: "f*"}},
+					SPIFFEIDPrefixes: []spiffeid.ID{spiffeid.RequireFromString("spiffe://example.org/one")},
+				},
+			},
+			identities: []cache.Identity{
+				identities[0],
+			},
+			updates: []*cache.WorkloadUpdate{
+				{
+					Identities: []cache.Identity{
+						identities[0],
+						identities[1],
+					},
+					Bundle: bundle,
+				},
+			},
+			expectResp: &delegatedidentityv1.SubscribeToX509SVIDsResponse{
+				X509Svids: []*delegatedidentityv1.X509SVIDWithKey{
+					{
+						X509Svid: &types.X509SVID{
+							Id:        utilIDProtoFromString(t, x509SVID1.ID.String()),
+							CertChain: x509util.RawCertsFromCertificates(x509SVID1.Certificates),
+							ExpiresAt: x509SVID1.Certificates[0].NotAfter.Unix(),
+						},
+						X509SvidKey: pkcs8FromSigner(t, x509SVID1.PrivateKey),
+					},
+				},
+			},
+			expectMetrics: generateSubscribeToX509SVIDMetrics(),
+		},
 	} {
 		t.Run(tt.testName, func(t *testing.T) {
 			metrics := fakemetrics.New()
@@ -334,6 +382,7 @@ func TestSubscribeToX509SVIDs(t *testing.T) {
 				Identities:   tt.identities,
 				Updates:      tt.updates,
 				AuthSpiffeID: tt.authSpiffeID,
+				Scopes:       tt.scopes,
 				AttestErr:    tt.attestErr,
 				ManagerErr:   tt.managerErr,
 				Metrics:      metrics,
@@ -360,6 +409,44 @@ func TestSubscribeToX509SVIDs(t *testing.T) {
 	}
 }
 
+func TestAuthorizedUpdateAuthorizesOncePerStream(t *testing.T) {
+	ca := testca.New(t, trustDomain1)
+	identities := []cache.Identity{
+		identityFromX509SVID(ca.CreateX509SVID(id1)),
+		identityFromX509SVID(ca.CreateX509SVID(id2)),
+	}
+
+	log, logHook := test.NewNullLogger()
+	authorizer := &fakeAuthorizer{allowed: map[spiffeid.ID]bool{id1: true}}
+	service := New(Config{
+		Authorizer: authorizer,
+	})
+
+	decisions := make(map[spiffeid.ID]bool)
+	for range 3 {
+		update, err := service.authorizedUpdate(context.Background(), log, id1, &cache.WorkloadUpdate{Identities: identities}, decisions)
+		require.NoError(t, err)
+		require.Equal(t, identities[:1], update.Identities)
+	}
+
+	require.Equal(t, 2, authorizer.svidCalls)
+	spiretest.AssertLogs(t, logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "API accessed",
+			Data: logrus.Fields{
+				telemetry.Type:          "audit",
+				telemetry.Status:        "error",
+				telemetry.StatusCode:    "PermissionDenied",
+				telemetry.StatusMessage: "delegate not authorized for the SVID",
+				"delegate_id":           id1.String(),
+				"svid_type":             "x509",
+				telemetry.SPIFFEID:      id2.String(),
+			},
+		},
+	})
+}
+
 func TestSubscribeToX509Bundles(t *testing.T) {
 	ca := testca.New(t, trustDomain1)
 
@@ -474,9 +561,11 @@ func TestFetchJWTSVIDs(t *testing.T) {
 		identities   []cache.Identity
 		jwtSVIDsResp map[spiffeid.ID]*client.JWTSVID
 		authSpiffeID []string
+		scopes       map[spiffeid.ID]DelegateScope
 		audience     []string
 		selectors    []*types.Selector
 		pid          int32
+		pidSelectors []*common.Selector
 		expectCode   codes.Code
 		expectMsg    string
 		attestErr    error
@@ -665,12 +754,123 @@ func TestFetchJWTSVIDs(t *testing.T) {
 				},
 			},
 		},
+		{
+			testName:     "selectors outside of delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {Selectors: []*common.Selector{{Type: "sa", Value: "bar"}}},
+			},
+			selectors:  []*types.Selector{{Type: "sa", Value: "foo"}},
+			audience:   []string{"AUDIENCE"},
+			identities: identities,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "delegate not authorized for the provided selectors",
+		},
+		{
+			testName:     "identities limited to delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {SPIFFEIDPrefixes: []spiffeid.ID{spiffeid.RequireFromString("spiffe://example.org/two")}},
+			},
+			selectors:  []*types.Selector{{Type: "sa", Value: "foo"}},
+			audience:   []string{"AUDIENCE"},
+			identities: identities,
+			jwtSVIDsResp: map[spiffeid.ID]*client.JWTSVID{
+				id1: {
+					Token:     jwtSVID1Token,
+					ExpiresAt: time.Unix(1680786600, 0),
+					IssuedAt:  time.Unix(1680783000, 0),
+				},
+				id2: {
+					Token:     jwtSVID2Token,
+					ExpiresAt: time.Unix(1680786600, 0),
+					IssuedAt:  time.Unix(1680783000, 0),
+				},
+			},
+			expectResp: &delegatedidentityv1.FetchJWTSVIDsResponse{
+				Svids: []*types.JWTSVID{
+					{
+						Token:     jwtSVID2Token,
+						Id:        api.ProtoFromID(id2),
+						ExpiresAt: 1680786600,
+						IssuedAt:  1680783000,
+					},
+				},
+			},
+		},
+		{
+			testName:     "no identity in delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {TrustDomains: []spiffeid.TrustDomain{trustDomain2}},
+			},
+			pid:        447,
+			audience:   []string{"AUDIENCE"},
+			identities: identities,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "no identity issued",
+		},
+		{
+			testName:     "PID attested selectors outside of delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {Selectors: []*common.Selector{{Type: "sa", Value: "bar"}}},
+			},
+			pid:          447,
+			pidSelectors: []*common.Selector{{Type: "sa", Value: "foo"}},
+			audience:     []string{"AUDIENCE"},
+			identities:   identities,
+			expectCode:   codes.PermissionDenied,
+			expectMsg:    "delegate not authorized for the provided selectors",
+		},
+		{
+			testName:     "PID attested selectors in delegate scope",
+			authSpiffeID: []string{"spiffe://example.org/one"},
+			scopes: map[spiffeid.ID]DelegateScope{
+				id1: {Selectors: []*common.Selector{{Type: "sa", Value: "f*"}}},
+			},
+			pid:          447,
+			pidSelectors: []*common.Selector{{Type: "sa", Value: "foo"}},
+			audience:     []string{"AUDIENCE"},
+			identities:   identities,
+			jwtSVIDsResp: map[spiffeid.ID]*client.JWTSVID{
+				id1: {
+					Token:     jwtSVID1Token,
+					ExpiresAt: time.Unix(1680786600, 0),
+					IssuedAt:  time.Unix(1680783000, 0),
+				},
+				id2: {
+					Token:     jwtSVID2Token,
+					ExpiresAt: time.Unix(1680786600, 0),
+					IssuedAt:  time.Unix(1680783000, 0),
+				},
+			},
+			expectResp: &delegatedidentityv1.FetchJWTSVIDsResponse{
+				Svids: []*types.JWTSVID{
+					{
+						Token:     jwtSVID1Token,
+						Id:        api.ProtoFromID(id1),
+						Hint:      "internal",
+						ExpiresAt: 1680786600,
+						IssuedAt:  1680783000,
+					},
+					{
+						Token:     jwtSVID2Token,
+						Id:        api.ProtoFromID(id2),
+						ExpiresAt: 1680786600,
+						IssuedAt:  1680783000,
+					},
+				},
+			},
+		},
 	} {
 		t.Run(tt.testName, func(t *testing.T) {
 			params := testParams{
 				CA:           ca,
 				Identities:   tt.identities,
 				AuthSpiffeID: tt.authSpiffeID,
+				Scopes:       tt.scopes,
+				PIDSelectors: tt.pidSelectors,
 				AttestErr:    tt.attestErr,
 				ManagerErr:   tt.managerErr,
 				JwtSVIDS:     tt.jwtSVIDsResp,
@@ -799,6 +999,8 @@ type testParams struct {
 	CacheUpdates map[spiffeid.TrustDomain]*cache.Bundle
 	JwtSVIDS     map[spiffeid.ID]*client.JWTSVID
 	AuthSpiffeID []string
+	Scopes       map[spiffeid.ID]DelegateScope
+	PIDSelectors []*common.Selector
 	AttestErr    error
 	ManagerErr   error
 	Metrics      *fakemetrics.FakeMetrics
@@ -823,6 +1025,7 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 		Manager:             manager,
 		Metrics:             params.Metrics,
 		AuthorizedDelegates: params.AuthSpiffeID,
+		DelegateScopes:      params.Scopes,
 	})
 
 	service.peerAttestor = FakeAttestor{
@@ -830,7 +1033,8 @@ func runTest(t *testing.T, params testParams, fn func(ctx context.Context, clien
 	}
 
 	service.delegateWorkloadAttestor = FakeWorkloadPIDAttestor{
-		err: params.AttestErr,
+		selectors: params.PIDSelectors,
+		err:       params.AttestErr,
 	}
 
 	unaryInterceptor, streamInterceptor := middleware.Interceptors(middleware.WithLogger(log))
@@ -870,6 +1074,20 @@ func (fa FakeWorkloadPIDAttestor) Attest(_ context.Context, _ int) ([]*common.Se
 	return fa.selectors, fa.err
 }
 
+type fakeAuthorizer struct {
+	allowed   map[spiffeid.ID]bool
+	svidCalls int
+}
+
+func (a *fakeAuthorizer) AuthorizeSelectors(context.Context, spiffeid.ID, []*common.Selector) (bool, error) {
+	return true, nil
+}
+
+func (a *fakeAuthorizer) AuthorizeSVID(_ context.Context, _ spiffeid.ID, _ SVIDType, id spiffeid.ID) (bool, error) {
+	a.svidCalls++
+	return a.allowed[id], nil
+}
+
 type FakeManager struct {
 	manager.Manager
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
+	cachev1 "github.com/spiffe/spire/pkg/agent/api/cache/v1"
 	debugv1 "github.com/spiffe/spire/pkg/agent/api/debug/v1"
 	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	"github.com/spiffe/spire/pkg/agent/endpoints"
@@ -36,6 +37,7 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	)
 
 	e.registerDebugAPI(server)
+	e.registerCacheAPI(server)
 	e.registerDelegatedIdentityAPI(server)
 
 	l, err := e.createListener()
@@ -77,11 +79,24 @@ func (e *Endpoints) registerDebugAPI(server *grpc.Server) {
 	debugv1.RegisterService(server, service)
 }
 
+func (e *Endpoints) registerCacheAPI(server *grpc.Server) {
+	service := cachev1.New(cachev1.Config{
+		Log:              e.c.Log.WithField(telemetry.SubsystemName, telemetry.CacheAPI),
+		Manager:          e.c.Manager,
+		Attestor:         endpoints.PeerTrackerAttestor{Attestor: e.c.Attestor},
+		AuthorizedAdmins: e.c.AuthorizedCacheAdmins,
+	})
+
+	cachev1.RegisterService(server, service)
+}
+
 func (e *Endpoints) registerDelegatedIdentityAPI(server *grpc.Server) {
 	service := delegatedidentityv1.New(delegatedidentityv1.Config{
 		Manager:             e.c.Manager,
 		Attestor:            e.c.Attestor,
 		AuthorizedDelegates: e.c.AuthorizedDelegates,
+		DelegateScopes:      e.c.AuthorizedDelegateScopes,
+		Authorizer:          e.c.AuthorizedDelegateAuthorizer,
 		Metrics:             e.c.Metrics,
 		Log:                 e.c.Log.WithField(telemetry.SubsystemName, telemetry.DelegatedIdentityAPI),
 	})
@@ -3,7 +3,9 @@ package catalog
 import (
 	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
 	"github.com/spiffe/spire/pkg/agent/plugin/svidstore/awssecretsmanager"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore/filesystem"
 	"github.com/spiffe/spire/pkg/agent/plugin/svidstore/gcpsecretmanager"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore/vaultkv"
 	"github.com/spiffe/spire/pkg/common/catalog"
 )
 
@@ -27,6 +29,8 @@ func (repo *svidStoreRepository) BuiltIns() []catalog.BuiltIn {
 	return []catalog.BuiltIn{
 		awssecretsmanager.BuiltIn(),
 		gcpsecretmanager.BuiltIn(),
+		vaultkv.BuiltIn(),
+		filesystem.BuiltIn(),
 	}
 }
 
@@ -327,6 +327,27 @@ func TestSyncUpdatesEntries(t *testing.T) {
 	syncAndAssertEntries(t, 4, 1, 3, 0, entryA1, entryB1, entryC1, entryD1)
 }
 
+func TestSyncUpdatesDiscardsTemplateEntries(t *testing.T) {
+	client, tc := createClient(t)
+
+	tc.bundleServer.serverBundle = makeAPIBundle("example.org")
+
+	// Template entries are expanded by the server, so an entry with a
+	// SPIFFE ID template is invalid and discarded.
+	entryA := makeEntry("A", 1, time.Now())
+	templateEntry := makeEntry("T", 1, time.Now())
+	templateEntry.SpiffeId.Path = "/ns/{{k8s:ns}}/sa/{{k8s:sa}}"
+	tc.entryServer.SetEntries(entryA, templateEntry)
+
+	cachedBundles := make(map[string]*common.Bundle)
+	cachedEntries := make(map[string]*common.RegistrationEntry)
+	_, err := client.SyncUpdates(ctx, cachedEntries, cachedBundles)
+	require.NoError(t, err)
+
+	require.Len(t, cachedEntries, 1)
+	require.Contains(t, cachedEntries, "A")
+}
+
 func TestRenewSVID(t *testing.T) {
 	client, tc := createClient(t)
 
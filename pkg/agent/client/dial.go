@@ -14,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/tlspolicy"
 	"github.com/spiffe/spire/pkg/common/x509util"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
@@ -70,6 +71,8 @@ func NewServerGRPCClient(config ServerClientConfig) (*grpc.ClientConn, error) {
 			grpc.WithDefaultServiceConfig(roundRobinServiceConfig),
 			grpc.WithDisableServiceConfig(),
 			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+			// Propagate the trace context of agent requests to the server
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
 		}
 	}
 
@@ -0,0 +1,46 @@
+// Package imageverifier contains the pieces shared by the image verifiers
+// used by the workload attestors to produce selectors from the signatures
+// and attestations of container images.
+package imageverifier
+
+import (
+	"context"
+	"fmt"
+)
+
+type Verifier interface {
+	// Verify verifies an image and returns a list of selectors.
+	Verify(ctx context.Context, imageID string) ([]string, error)
+}
+
+// Chain is a Verifier that verifies images with each of the verifiers added
+// to it, in order. The verification succeeds only if all of them succeed, in
+// which case the selectors of every verifier are returned.
+type Chain struct {
+	names     []string
+	verifiers []Verifier
+}
+
+// Add appends a verifier to the chain. The name is used to identify the
+// verifier on errors.
+func (c *Chain) Add(name string, verifier Verifier) {
+	c.names = append(c.names, name)
+	c.verifiers = append(c.verifiers, verifier)
+}
+
+// Len returns the number of verifiers in the chain.
+func (c *Chain) Len() int {
+	return len(c.verifiers)
+}
+
+func (c *Chain) Verify(ctx context.Context, imageID string) ([]string, error) {
+	var selectors []string
+	for i, verifier := range c.verifiers {
+		verifierSelectors, err := verifier.Verify(ctx, imageID)
+		if err != nil {
+			return nil, fmt.Errorf("%s verification failed: %w", c.names[i], err)
+		}
+		selectors = append(selectors, verifierSelectors...)
+	}
+	return selectors, nil
+}
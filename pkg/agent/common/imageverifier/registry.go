@@ -0,0 +1,128 @@
+package imageverifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/hashicorp/go-hclog"
+)
+
+type RegistryCredential struct {
+	Username string `hcl:"username,omitempty" json:"username,omitempty"`
+	Password string `hcl:"password,omitempty" json:"password,omitempty"`
+}
+
+// Registry fetches the artifacts attached to images from their registries.
+type Registry struct {
+	authOptions map[string]remote.Option
+}
+
+// NewRegistry returns a Registry that authenticates with the given credentials,
+// keyed by registry host. Registries without credentials are accessed with the
+// credentials of the default keychain.
+func NewRegistry(credentials map[string]*RegistryCredential, log hclog.Logger) *Registry {
+	authOptions := make(map[string]remote.Option)
+	for registry, creds := range credentials {
+		if creds == nil {
+			continue
+		}
+
+		usernameProvided := creds.Username != ""
+		passwordProvided := creds.Password != ""
+		switch {
+		case usernameProvided && passwordProvided:
+			authOptions[registry] = remote.WithAuth(&authn.Basic{
+				Username: creds.Username,
+				Password: creds.Password,
+			})
+		case usernameProvided || passwordProvided:
+			log.Warn("Incomplete credentials for registry. Both username and password must be provided.", "registry", registry)
+		}
+	}
+
+	return &Registry{
+		authOptions: authOptions,
+	}
+}
+
+// ParseDigest parses an image ID into a reference to the image manifest. The
+// image ID must be in the format "repository@sha256:digest".
+func ParseDigest(imageID string) (name.Digest, error) {
+	ref, err := name.ParseReference(imageID)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("failed to parse image reference: %w", err)
+	}
+	digest, ok := ref.(name.Digest)
+	if !ok {
+		return name.Digest{}, fmt.Errorf("image reference %q does not include a digest", imageID)
+	}
+	return digest, nil
+}
+
+// Referrers returns the manifests of the artifacts that refer to the image
+// with one of the given artifact types.
+func (r *Registry) Referrers(ctx context.Context, digest name.Digest, artifactTypes ...string) ([]v1.Image, error) {
+	index, err := remote.Referrers(digest, r.options(ctx, digest)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list referrers: %w", err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read referrers: %w", err)
+	}
+
+	var artifacts []v1.Image
+	for _, desc := range manifest.Manifests {
+		if !slices.Contains(artifactTypes, desc.ArtifactType) {
+			continue
+		}
+		artifact, err := remote.Image(digest.Context().Digest(desc.Digest.String()), r.options(ctx, digest)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch referrer %s: %w", desc.Digest, err)
+		}
+		artifacts = append(artifacts, artifact)
+	}
+	return artifacts, nil
+}
+
+// Image returns the image with the given reference, or nil if it does not
+// exist.
+func (r *Registry) Image(ctx context.Context, ref name.Reference) (v1.Image, error) {
+	image, err := remote.Image(ref, r.options(ctx, ref)...)
+	var transportErr *transport.Error
+	switch {
+	case errors.As(err, &transportErr) && transportErr.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to fetch %s: %w", ref.Name(), err)
+	}
+	return image, nil
+}
+
+// ReadBlob reads the content of a layer of an artifact, as stored in the
+// registry.
+func ReadBlob(layer v1.Layer) ([]byte, error) {
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func (r *Registry) options(ctx context.Context, ref name.Reference) []remote.Option {
+	authOption, ok := r.authOptions[ref.Context().RegistryStr()]
+	if !ok {
+		authOption = remote.WithAuthFromKeychain(authn.DefaultKeychain)
+	}
+	return []remote.Option{authOption, remote.WithContext(ctx)}
+}
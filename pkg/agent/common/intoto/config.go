@@ -0,0 +1,61 @@
+package intoto
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+)
+
+// Config holds configuration for the ImageVerifier.
+type Config struct {
+	PolicyFile          string
+	SkippedImages       map[string]struct{}
+	RegistryCredentials map[string]*imageverifier.RegistryCredential
+
+	Logger hclog.Logger
+}
+
+func NewConfig() *Config {
+	return &Config{
+		SkippedImages: make(map[string]struct{}),
+	}
+}
+
+type HCLConfig struct {
+	// PolicyFile is the path to the JSON policy that provenance attestations must satisfy.
+	PolicyFile string `hcl:"policy_file" json:"policy_file"`
+
+	// SkippedImages is a list of images that should skip in-toto verification
+	SkippedImages []string `hcl:"skipped_images" json:"skipped_images"`
+
+	// RegistryCredentials is a map of credentials keyed by registry URL
+	RegistryCredentials map[string]*imageverifier.RegistryCredential `hcl:"registry_credentials,omitempty" json:"registry_credentials,omitempty"`
+}
+
+func NewConfigFromHCL(hclConfig *HCLConfig, log hclog.Logger) *Config {
+	config := NewConfig()
+	config.Logger = log
+	config.PolicyFile = hclConfig.PolicyFile
+	config.RegistryCredentials = hclConfig.RegistryCredentials
+
+	for _, image := range hclConfig.SkippedImages {
+		config.SkippedImages[image] = struct{}{}
+	}
+
+	return config
+}
+
+// Policy is the policy, loaded from the policy file, that provenance
+// attestations must satisfy.
+type Policy struct {
+	// TrustedKeys is a list of PEM encoded public keys trusted to sign
+	// attestations.
+	TrustedKeys []string `json:"trusted_keys"`
+
+	// BuilderIDs is a list of the builders allowed to build images. If empty,
+	// any builder is allowed.
+	BuilderIDs []string `json:"builder_ids,omitempty"`
+
+	// BuildTypes is a list of the build types allowed to build images. If
+	// empty, any build type is allowed.
+	BuildTypes []string `json:"build_types,omitempty"`
+}
@@ -0,0 +1,385 @@
+package intoto
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const (
+	provenanceVerifiedSelector = "slsa-provenance:verified"
+
+	dsseEnvelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+	inTotoPayloadType     = "application/vnd.in-toto+json"
+
+	slsaProvenanceV02 = "https://slsa.dev/provenance/v0.2"
+	slsaProvenanceV1  = "https://slsa.dev/provenance/v1"
+)
+
+// ImageVerifier verifies the in-toto SLSA provenance attestations attached to
+// images against a local policy. It implements the imageverifier.Verifier
+// interface.
+type ImageVerifier struct {
+	config *Config
+
+	verificationCache sync.Map
+	registry          *imageverifier.Registry
+	policy            *Policy
+	trustedKeys       []crypto.PublicKey
+}
+
+func NewVerifier(config *Config) *ImageVerifier {
+	if config.Logger == nil {
+		config.Logger = hclog.Default()
+	}
+
+	return &ImageVerifier{
+		config:   config,
+		registry: imageverifier.NewRegistry(config.RegistryCredentials, config.Logger),
+	}
+}
+
+// Init prepares the verifier by loading the policy file.
+func (v *ImageVerifier) Init(context.Context) error {
+	if v.config.PolicyFile == "" {
+		return errors.New("policy_file is required")
+	}
+
+	policyBytes, err := os.ReadFile(v.config.PolicyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read policy file: %w", err)
+	}
+	policy := new(Policy)
+	if err := json.Unmarshal(policyBytes, policy); err != nil {
+		return fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	if len(policy.TrustedKeys) == 0 {
+		return errors.New("policy must contain at least one trusted key")
+	}
+
+	trustedKeys := make([]crypto.PublicKey, 0, len(policy.TrustedKeys))
+	for i, keyPEM := range policy.TrustedKeys {
+		key, err := pemutil.ParsePublicKey([]byte(keyPEM))
+		if err != nil {
+			return fmt.Errorf("failed to parse trusted key %d: %w", i, err)
+		}
+		trustedKeys = append(trustedKeys, key)
+	}
+
+	v.policy = policy
+	v.trustedKeys = trustedKeys
+	return nil
+}
+
+// Verify validates the SLSA provenance attestations of an image. The imageID
+// parameter is expected to be in the format "repository@sha256:digest".
+// Attestations are looked up through the referrers of the image and the
+// attestations tag used by cosign. At least one provenance attestation must
+// be signed by a trusted key and satisfy the policy. It returns selectors
+// with the builders and build types of the verified attestations.
+// If the image is in the skip list, it bypasses verification and returns an
+// empty list of selectors.
+func (v *ImageVerifier) Verify(ctx context.Context, imageID string) ([]string, error) {
+	v.config.Logger.Debug("Verifying image with in-toto", telemetry.ImageID, imageID)
+
+	if _, ok := v.config.SkippedImages[imageID]; ok {
+		return []string{}, nil
+	}
+
+	if cachedSelectors, ok := v.verificationCache.Load(imageID); ok {
+		v.config.Logger.Debug("In-toto verifier cache hit", telemetry.ImageID, imageID)
+		return cachedSelectors.([]string), nil
+	}
+
+	digest, err := imageverifier.ParseDigest(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	envelopes, err := v.fetchEnvelopes(ctx, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	var provenances []*provenance
+	var allErrors []string
+	for _, envelope := range envelopes {
+		p, err := v.verifyEnvelope(envelope, digest)
+		if err != nil {
+			allErrors = append(allErrors, err.Error())
+			continue
+		}
+		if p != nil {
+			provenances = append(provenances, p)
+		}
+	}
+
+	if len(provenances) == 0 {
+		if len(allErrors) == 0 {
+			return nil, fmt.Errorf("no provenance attestation found for image %s", imageID)
+		}
+		return nil, fmt.Errorf("no valid provenance attestation found for image %s: %s", imageID, strings.Join(allErrors, "; "))
+	}
+
+	selectors := []string{provenanceVerifiedSelector}
+	for _, p := range provenances {
+		for _, selector := range []string{
+			"slsa-builder-id:" + p.BuilderID,
+			"slsa-build-type:" + p.BuildType,
+		} {
+			if !slices.Contains(selectors, selector) {
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+
+	v.verificationCache.Store(imageID, selectors)
+
+	return selectors, nil
+}
+
+// fetchEnvelopes returns the DSSE envelopes attached to the image.
+func (v *ImageVerifier) fetchEnvelopes(ctx context.Context, digest name.Digest) ([][]byte, error) {
+	artifacts, err := v.registry.Referrers(ctx, digest, inTotoPayloadType, dsseEnvelopeMediaType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+
+	// cosign stores the attestations of an image in a tag derived from its
+	// digest, e.g. sha256-<hex>.att
+	cosignTag := digest.Context().Tag(strings.Replace(digest.DigestStr(), ":", "-", 1) + ".att")
+	cosignAttestations, err := v.registry.Image(ctx, cosignTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attestations: %w", err)
+	}
+	if cosignAttestations != nil {
+		artifacts = append(artifacts, cosignAttestations)
+	}
+
+	var envelopes [][]byte
+	for _, artifact := range artifacts {
+		layers, err := artifact.Layers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attestation: %w", err)
+		}
+		for _, layer := range layers {
+			envelope, err := readEnvelope(layer)
+			if err != nil {
+				return nil, err
+			}
+			if envelope != nil {
+				envelopes = append(envelopes, envelope)
+			}
+		}
+	}
+	return envelopes, nil
+}
+
+func readEnvelope(layer v1.Layer) ([]byte, error) {
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation media type: %w", err)
+	}
+	if mediaType != dsseEnvelopeMediaType {
+		return nil, nil
+	}
+	envelope, err := imageverifier.ReadBlob(layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attestation envelope: %w", err)
+	}
+	return envelope, nil
+}
+
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		KeyID     string `json:"keyid"`
+		Signature string `json:"sig"`
+	} `json:"signatures"`
+}
+
+type statement struct {
+	Type    string `json:"_type"`
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+type provenanceV02Predicate struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType string `json:"buildType"`
+}
+
+type provenanceV1Predicate struct {
+	BuildDefinition struct {
+		BuildType string `json:"buildType"`
+	} `json:"buildDefinition"`
+	RunDetails struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+	} `json:"runDetails"`
+}
+
+type provenance struct {
+	BuilderID string
+	BuildType string
+}
+
+// verifyEnvelope verifies a DSSE envelope holding an in-toto statement about
+// the image. It returns the provenance in the statement, or nil if the
+// statement is not a SLSA provenance.
+func (v *ImageVerifier) verifyEnvelope(data []byte, digest name.Digest) (*provenance, error) {
+	envelope := new(dsseEnvelope)
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return nil, fmt.Errorf("malformed attestation envelope: %w", err)
+	}
+	if envelope.PayloadType != inTotoPayloadType {
+		return nil, fmt.Errorf("unsupported attestation payload type %q", envelope.PayloadType)
+	}
+	payload, err := decodeBase64(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed attestation payload: %w", err)
+	}
+	if !v.isSignedByTrustedKey(envelope, payload) {
+		return nil, errors.New("attestation is not signed by a trusted key")
+	}
+
+	st := new(statement)
+	if err := json.Unmarshal(payload, st); err != nil {
+		return nil, fmt.Errorf("malformed attestation statement: %w", err)
+	}
+	if !st.hasSubject(digest) {
+		return nil, errors.New("attestation subject does not match the image")
+	}
+
+	p := new(provenance)
+	switch st.PredicateType {
+	case slsaProvenanceV02:
+		predicate := new(provenanceV02Predicate)
+		if err := json.Unmarshal(st.Predicate, predicate); err != nil {
+			return nil, fmt.Errorf("malformed provenance: %w", err)
+		}
+		p.BuilderID = predicate.Builder.ID
+		p.BuildType = predicate.BuildType
+	case slsaProvenanceV1:
+		predicate := new(provenanceV1Predicate)
+		if err := json.Unmarshal(st.Predicate, predicate); err != nil {
+			return nil, fmt.Errorf("malformed provenance: %w", err)
+		}
+		p.BuilderID = predicate.RunDetails.Builder.ID
+		p.BuildType = predicate.BuildDefinition.BuildType
+	default:
+		// Other kinds of attestations (e.g. SBOMs) are not checked
+		return nil, nil
+	}
+
+	if p.BuilderID == "" {
+		return nil, errors.New("provenance does not contain a builder ID")
+	}
+	if len(v.policy.BuilderIDs) > 0 && !slices.Contains(v.policy.BuilderIDs, p.BuilderID) {
+		return nil, fmt.Errorf("builder %q is not allowed", p.BuilderID)
+	}
+	if len(v.policy.BuildTypes) > 0 && !slices.Contains(v.policy.BuildTypes, p.BuildType) {
+		return nil, fmt.Errorf("build type %q is not allowed", p.BuildType)
+	}
+
+	return p, nil
+}
+
+func (s *statement) hasSubject(digest name.Digest) bool {
+	algorithm, hex, ok := strings.Cut(digest.DigestStr(), ":")
+	if !ok {
+		return false
+	}
+	for _, subject := range s.Subject {
+		if subject.Digest[algorithm] == hex {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *ImageVerifier) isSignedByTrustedKey(envelope *dsseEnvelope, payload []byte) bool {
+	pae := preAuthEncoding(envelope.PayloadType, payload)
+	for _, signature := range envelope.Signatures {
+		sig, err := decodeBase64(signature.Signature)
+		if err != nil {
+			continue
+		}
+		for _, key := range v.trustedKeys {
+			if verifySignature(key, pae, sig) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preAuthEncoding returns the DSSE pre-authentication encoding of the payload,
+// which is what the signatures of the envelope are computed over.
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	return fmt.Appendf(nil, "DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload)
+}
+
+func verifySignature(publicKey crypto.PublicKey, message, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch key.Curve {
+		case elliptic.P384():
+			sum := sha512.Sum384(message)
+			digest = sum[:]
+		case elliptic.P521():
+			sum := sha512.Sum512(message)
+			digest = sum[:]
+		default:
+			sum := sha256.Sum256(message)
+			digest = sum[:]
+		}
+		return ecdsa.VerifyASN1(key, digest, signature)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil ||
+			rsa.VerifyPSS(key, crypto.SHA256, digest[:], signature, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, signature)
+	default:
+		return false
+	}
+}
+
+// decodeBase64 decodes both standard and URL safe base64, as DSSE allows
+// either encoding.
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,368 @@
+package intoto
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	builderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v1.9.0"
+	buildType = "https://github.com/slsa-framework/slsa-github-generator/container@v1"
+)
+
+func TestNewConfigFromHCL(t *testing.T) {
+	config := NewConfigFromHCL(&HCLConfig{
+		PolicyFile:    "/path/to/policy.json",
+		SkippedImages: []string{"registry/image@sha256:examplehash"},
+	}, hclog.NewNullLogger())
+
+	assert.Equal(t, "/path/to/policy.json", config.PolicyFile)
+	assert.Equal(t, map[string]struct{}{"registry/image@sha256:examplehash": {}}, config.SkippedImages)
+}
+
+func TestInit(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name       string
+		noPolicy   bool
+		policy     string
+		expectErr  string
+		expectKeys int
+	}{
+		{
+			name:       "valid policy",
+			policy:     policyJSON(t, &Policy{TrustedKeys: []string{encodePublicKey(t, key.Public())}, BuilderIDs: []string{builderID}}),
+			expectKeys: 1,
+		},
+		{
+			name:      "no policy file",
+			noPolicy:  true,
+			expectErr: "policy_file is required",
+		},
+		{
+			name:      "malformed policy",
+			policy:    "{",
+			expectErr: "failed to parse policy file",
+		},
+		{
+			name:      "no trusted keys",
+			policy:    policyJSON(t, &Policy{BuilderIDs: []string{builderID}}),
+			expectErr: "policy must contain at least one trusted key",
+		},
+		{
+			name:      "malformed trusted key",
+			policy:    policyJSON(t, &Policy{TrustedKeys: []string{"not-a-key"}}),
+			expectErr: "failed to parse trusted key 0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig()
+			config.Logger = hclog.NewNullLogger()
+			if !tt.noPolicy {
+				config.PolicyFile = writePolicy(t, tt.policy)
+			}
+
+			verifier := NewVerifier(config)
+			err := verifier.Init(context.Background())
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, verifier.trustedKeys, tt.expectKeys)
+		})
+	}
+
+	t.Run("missing policy file", func(t *testing.T) {
+		config := NewConfig()
+		config.PolicyFile = filepath.Join(t.TempDir(), "missing.json")
+		err := NewVerifier(config).Init(context.Background())
+		require.ErrorContains(t, err, "failed to read policy file")
+	})
+}
+
+func TestVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	untrustedKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	provenanceV02 := func(builderID, buildType string) (string, any) {
+		return slsaProvenanceV02, map[string]any{
+			"builder":   map[string]any{"id": builderID},
+			"buildType": buildType,
+		}
+	}
+	provenanceV1 := func(builderID, buildType string) (string, any) {
+		return slsaProvenanceV1, map[string]any{
+			"buildDefinition": map[string]any{"buildType": buildType},
+			"runDetails":      map[string]any{"builder": map[string]any{"id": builderID}},
+		}
+	}
+	expectedSelectors := []string{
+		"slsa-provenance:verified",
+		"slsa-builder-id:" + builderID,
+		"slsa-build-type:" + buildType,
+	}
+
+	for _, tt := range []struct {
+		name            string
+		policy          *Policy
+		skipped         bool
+		attach          func(t *testing.T, imageID name.Digest, imageDigest v1.Hash)
+		expectSelectors []string
+		expectErr       string
+	}{
+		{
+			name: "SLSA v0.2 provenance attached as referrer",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV02(builderID, buildType)
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectSelectors: expectedSelectors,
+		},
+		{
+			name: "SLSA v1 provenance attached by cosign",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV1(builderID, buildType)
+				pushCosignAttestation(t, imageID, signEnvelope(t, edKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectSelectors: expectedSelectors,
+		},
+		{
+			name:    "skipped image",
+			skipped: true,
+		},
+		{
+			name:      "no attestations",
+			expectErr: "no provenance attestation found for image",
+		},
+		{
+			name: "only attestations that are not provenances",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, imageDigest, "https://spdx.dev/Document", map[string]any{})))
+			},
+			expectErr: "no provenance attestation found for image",
+		},
+		{
+			name: "builder not allowed",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV02("https://other-builder.example.org", buildType)
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectErr: `builder "https://other-builder.example.org" is not allowed`,
+		},
+		{
+			name: "build type not allowed",
+			policy: &Policy{
+				BuildTypes: []string{"https://example.org/build-type"},
+			},
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV1(builderID, buildType)
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectErr: `build type "` + buildType + `" is not allowed`,
+		},
+		{
+			name: "provenance without builder",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV1("", buildType)
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectErr: "provenance does not contain a builder ID",
+		},
+		{
+			name: "attestation signed by an untrusted key",
+			attach: func(t *testing.T, imageID name.Digest, imageDigest v1.Hash) {
+				predicateType, predicate := provenanceV02(builderID, buildType)
+				pushReferrer(t, imageID, signEnvelope(t, untrustedKey, statementJSON(t, imageDigest, predicateType, predicate)))
+			},
+			expectErr: "attestation is not signed by a trusted key",
+		},
+		{
+			name: "attestation about another image",
+			attach: func(t *testing.T, imageID name.Digest, _ v1.Hash) {
+				predicateType, predicate := provenanceV02(builderID, buildType)
+				otherDigest := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+				pushReferrer(t, imageID, signEnvelope(t, ecKey, statementJSON(t, otherDigest, predicateType, predicate)))
+			},
+			expectErr: "attestation subject does not match the image",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			host := startRegistry(t)
+			imageID, imageDigest := pushImage(t, host)
+			if tt.attach != nil {
+				tt.attach(t, imageID, imageDigest)
+			}
+
+			policy := tt.policy
+			if policy == nil {
+				policy = &Policy{BuilderIDs: []string{builderID}}
+			}
+			policy.TrustedKeys = []string{
+				encodePublicKey(t, ecKey.Public()),
+				encodePublicKey(t, edKey.Public()),
+			}
+			config := NewConfigFromHCL(&HCLConfig{
+				PolicyFile: writePolicy(t, policyJSON(t, policy)),
+			}, hclog.NewNullLogger())
+			if tt.skipped {
+				config.SkippedImages[imageID.String()] = struct{}{}
+			}
+			verifier := NewVerifier(config)
+			require.NoError(t, verifier.Init(context.Background()))
+
+			selectors, err := verifier.Verify(context.Background(), imageID.String())
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				require.Nil(t, selectors)
+				return
+			}
+			require.NoError(t, err)
+			if tt.skipped {
+				require.Empty(t, selectors)
+				return
+			}
+			require.ElementsMatch(t, tt.expectSelectors, selectors)
+		})
+	}
+}
+
+func startRegistry(t *testing.T) string {
+	server := httptest.NewServer(registry.New(registry.WithReferrersSupport(true), registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func pushImage(t *testing.T, host string) (name.Digest, v1.Hash) {
+	image, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := image.Digest()
+	require.NoError(t, err)
+
+	imageID, err := name.NewDigest(host + "/workload@" + digest.String())
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imageID, image))
+	return imageID, digest
+}
+
+func attestationImage(t *testing.T, envelope []byte) v1.Image {
+	image := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	image = mutate.ConfigMediaType(image, inTotoPayloadType)
+	image, err := mutate.Append(image, mutate.Addendum{
+		Layer: static.NewLayer(envelope, dsseEnvelopeMediaType),
+	})
+	require.NoError(t, err)
+	return image
+}
+
+func pushReferrer(t *testing.T, imageID name.Digest, envelope []byte) {
+	desc, err := remote.Head(imageID)
+	require.NoError(t, err)
+
+	attestation, ok := mutate.Subject(attestationImage(t, envelope), *desc).(v1.Image)
+	require.True(t, ok)
+	attestationDigest, err := attestation.Digest()
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imageID.Context().Digest(attestationDigest.String()), attestation))
+}
+
+func pushCosignAttestation(t *testing.T, imageID name.Digest, envelope []byte) {
+	tag := imageID.Context().Tag(strings.Replace(imageID.DigestStr(), ":", "-", 1) + ".att")
+	require.NoError(t, remote.Write(tag, attestationImage(t, envelope)))
+}
+
+func statementJSON(t *testing.T, subjectDigest v1.Hash, predicateType string, predicate any) []byte {
+	statement, err := json.Marshal(map[string]any{
+		"_type": "https://in-toto.io/Statement/v1",
+		"subject": []map[string]any{
+			{
+				"name":   "workload",
+				"digest": map[string]string{subjectDigest.Algorithm: subjectDigest.Hex},
+			},
+		},
+		"predicateType": predicateType,
+		"predicate":     predicate,
+	})
+	require.NoError(t, err)
+	return statement
+}
+
+func signEnvelope(t *testing.T, key crypto.Signer, payload []byte) []byte {
+	pae := preAuthEncoding(inTotoPayloadType, payload)
+
+	var signature []byte
+	var err error
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256(pae)
+		signature, err = ecdsa.SignASN1(rand.Reader, key, digest[:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, pae)
+	default:
+		t.Fatalf("unsupported key type %T", key)
+	}
+	require.NoError(t, err)
+
+	envelope, err := json.Marshal(map[string]any{
+		"payloadType": inTotoPayloadType,
+		"payload":     base64.StdEncoding.EncodeToString(payload),
+		"signatures": []map[string]any{
+			{"sig": base64.StdEncoding.EncodeToString(signature)},
+		},
+	})
+	require.NoError(t, err)
+	return envelope
+}
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func policyJSON(t *testing.T, policy *Policy) string {
+	policyBytes, err := json.Marshal(policy)
+	require.NoError(t, err)
+	return string(policyBytes)
+}
+
+func writePolicy(t *testing.T, policy string) string {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(policy), 0600))
+	return path
+}
@@ -0,0 +1,55 @@
+package notation
+
+import (
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+)
+
+// Config holds configuration for the ImageVerifier.
+type Config struct {
+	TrustStores         []string
+	TrustedIdentities   map[string]struct{}
+	SkippedImages       map[string]struct{}
+	RegistryCredentials map[string]*imageverifier.RegistryCredential
+
+	Logger hclog.Logger
+}
+
+func NewConfig() *Config {
+	return &Config{
+		TrustedIdentities: make(map[string]struct{}),
+		SkippedImages:     make(map[string]struct{}),
+	}
+}
+
+type HCLConfig struct {
+	// TrustStores is a list of paths to PEM files, or directories of PEM files, with the root certificates trusted to sign images.
+	TrustStores []string `hcl:"trust_stores" json:"trust_stores"`
+
+	// TrustedIdentities is a list of subjects of the signing certificates that are allowed to sign images, in the
+	// RFC 2253 format (e.g. "CN=signer,O=example.org"). The "*" wildcard trusts any certificate issued by the trust stores.
+	TrustedIdentities []string `hcl:"trusted_identities" json:"trusted_identities"`
+
+	// SkippedImages is a list of images that should skip notation verification
+	SkippedImages []string `hcl:"skipped_images" json:"skipped_images"`
+
+	// RegistryCredentials is a map of credentials keyed by registry URL
+	RegistryCredentials map[string]*imageverifier.RegistryCredential `hcl:"registry_credentials,omitempty" json:"registry_credentials,omitempty"`
+}
+
+func NewConfigFromHCL(hclConfig *HCLConfig, log hclog.Logger) *Config {
+	config := NewConfig()
+	config.Logger = log
+	config.TrustStores = hclConfig.TrustStores
+	config.RegistryCredentials = hclConfig.RegistryCredentials
+
+	for _, identity := range hclConfig.TrustedIdentities {
+		config.TrustedIdentities[identity] = struct{}{}
+	}
+
+	for _, image := range hclConfig.SkippedImages {
+		config.SkippedImages[image] = struct{}{}
+	}
+
+	return config
+}
@@ -0,0 +1,381 @@
+package notation
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+const (
+	imageSignatureVerifiedSelector = "image-signature:verified"
+
+	// Media types defined by the Notary Project specifications.
+	signatureArtifactType = "application/vnd.cncf.notary.signature"
+	jwsEnvelopeMediaType  = "application/jose+json"
+	payloadContentType    = "application/vnd.cncf.notary.payload.v1+json"
+
+	// Protected header parameters of the JWS envelope.
+	headerSigningScheme = "io.cncf.notary.signingScheme"
+	headerSigningTime   = "io.cncf.notary.signingTime"
+	headerExpiry        = "io.cncf.notary.expiry"
+
+	signingSchemeX509 = "notary.x509"
+	anyIdentity       = "*"
+)
+
+// ImageVerifier verifies the Notary Project signatures attached to images
+// against local trust stores. It implements the imageverifier.Verifier
+// interface.
+type ImageVerifier struct {
+	config *Config
+
+	verificationCache sync.Map
+	registry          *imageverifier.Registry
+	roots             *x509.CertPool
+	now               func() time.Time
+}
+
+func NewVerifier(config *Config) *ImageVerifier {
+	if config.Logger == nil {
+		config.Logger = hclog.Default()
+	}
+
+	return &ImageVerifier{
+		config:   config,
+		registry: imageverifier.NewRegistry(config.RegistryCredentials, config.Logger),
+		now:      time.Now,
+	}
+}
+
+// Init prepares the verifier by loading the certificates of the trust stores.
+func (v *ImageVerifier) Init(context.Context) error {
+	if len(v.config.TrustStores) == 0 {
+		return errors.New("at least one trust store is required")
+	}
+	if len(v.config.TrustedIdentities) == 0 {
+		return errors.New("at least one trusted identity is required")
+	}
+
+	v.roots = x509.NewCertPool()
+	for _, trustStore := range v.config.TrustStores {
+		certs, err := loadTrustStore(trustStore)
+		if err != nil {
+			return fmt.Errorf("failed to load trust store %q: %w", trustStore, err)
+		}
+		if len(certs) == 0 {
+			return fmt.Errorf("trust store %q does not contain certificates", trustStore)
+		}
+		for _, cert := range certs {
+			v.roots.AddCert(cert)
+		}
+	}
+
+	return nil
+}
+
+// Verify validates the Notary Project signatures of an image. The imageID
+// parameter is expected to be in the format "repository@sha256:digest".
+// At least one of the signatures must be valid and made by a trusted
+// identity. It returns selectors with the signers of the valid signatures.
+// If the image is in the skip list, it bypasses verification and returns an
+// empty list of selectors.
+func (v *ImageVerifier) Verify(ctx context.Context, imageID string) ([]string, error) {
+	v.config.Logger.Debug("Verifying image with notation", telemetry.ImageID, imageID)
+
+	if _, ok := v.config.SkippedImages[imageID]; ok {
+		return []string{}, nil
+	}
+
+	if cachedSelectors, ok := v.verificationCache.Load(imageID); ok {
+		v.config.Logger.Debug("Notation verifier cache hit", telemetry.ImageID, imageID)
+		return cachedSelectors.([]string), nil
+	}
+
+	digest, err := imageverifier.ParseDigest(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures, err := v.registry.Referrers(ctx, digest, signatureArtifactType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signatures: %w", err)
+	}
+
+	var verifiedCerts []*x509.Certificate
+	var allErrors []string
+	for _, signature := range signatures {
+		layers, err := signature.Layers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signature: %w", err)
+		}
+		for _, layer := range layers {
+			mediaType, err := layer.MediaType()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signature media type: %w", err)
+			}
+			if mediaType != jwsEnvelopeMediaType {
+				allErrors = append(allErrors, fmt.Sprintf("unsupported signature envelope %q", mediaType))
+				continue
+			}
+
+			envelope, err := imageverifier.ReadBlob(layer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signature envelope: %w", err)
+			}
+			cert, err := v.verifyEnvelope(envelope, digest.DigestStr())
+			if err != nil {
+				allErrors = append(allErrors, err.Error())
+				continue
+			}
+			verifiedCerts = append(verifiedCerts, cert)
+		}
+	}
+
+	if len(verifiedCerts) == 0 {
+		if len(allErrors) == 0 {
+			return nil, fmt.Errorf("no signature found for image %s", imageID)
+		}
+		return nil, fmt.Errorf("no valid signature found for image %s: %s", imageID, strings.Join(allErrors, "; "))
+	}
+
+	selectors := []string{imageSignatureVerifiedSelector}
+	for _, cert := range verifiedCerts {
+		for _, selector := range []string{
+			"image-signature-subject:" + cert.Subject.String(),
+			"image-signature-issuer:" + cert.Issuer.String(),
+		} {
+			if !slices.Contains(selectors, selector) {
+				selectors = append(selectors, selector)
+			}
+		}
+	}
+
+	v.verificationCache.Store(imageID, selectors)
+
+	return selectors, nil
+}
+
+type jwsEnvelope struct {
+	Payload   string `json:"payload"`
+	Protected string `json:"protected"`
+	Header    struct {
+		CertChain [][]byte `json:"x5c"`
+	} `json:"header"`
+	Signature string `json:"signature"`
+}
+
+type jwsProtectedHeader struct {
+	Algorithm     string     `json:"alg"`
+	ContentType   string     `json:"cty"`
+	Critical      []string   `json:"crit"`
+	SigningScheme string     `json:"io.cncf.notary.signingScheme"`
+	SigningTime   *time.Time `json:"io.cncf.notary.signingTime"`
+	Expiry        *time.Time `json:"io.cncf.notary.expiry"`
+}
+
+type payload struct {
+	TargetArtifact struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Size      int64  `json:"size"`
+	} `json:"targetArtifact"`
+}
+
+// verifyEnvelope verifies a JWS signature envelope for the image with the
+// given digest and returns the signing certificate.
+func (v *ImageVerifier) verifyEnvelope(data []byte, imageDigest string) (*x509.Certificate, error) {
+	envelope := new(jwsEnvelope)
+	if err := json.Unmarshal(data, envelope); err != nil {
+		return nil, fmt.Errorf("malformed signature envelope: %w", err)
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(envelope.Protected)
+	if err != nil {
+		return nil, fmt.Errorf("malformed protected header: %w", err)
+	}
+	header := new(jwsProtectedHeader)
+	if err := json.Unmarshal(headerBytes, header); err != nil {
+		return nil, fmt.Errorf("malformed protected header: %w", err)
+	}
+	if err := v.checkProtectedHeader(header); err != nil {
+		return nil, err
+	}
+
+	if len(envelope.Header.CertChain) == 0 {
+		return nil, errors.New("signature envelope does not contain a certificate chain")
+	}
+	certs := make([]*x509.Certificate, 0, len(envelope.Header.CertChain))
+	for _, der := range envelope.Header.CertChain {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("malformed certificate chain: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	leaf := certs[0]
+
+	// Verify the signature before looking into the certificate chain, so
+	// tampered envelopes are reported as such
+	signature, err := base64.RawURLEncoding.DecodeString(envelope.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+	signingInput := envelope.Protected + "." + envelope.Payload
+	if err := verifySignature(header.Algorithm, leaf.PublicKey, []byte(signingInput), signature); err != nil {
+		return nil, err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.now(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return nil, fmt.Errorf("signing certificate is not trusted: %w", err)
+	}
+	if !v.isTrustedIdentity(leaf) {
+		return nil, fmt.Errorf("signing identity %q is not trusted", leaf.Subject.String())
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("malformed payload: %w", err)
+	}
+	p := new(payload)
+	if err := json.Unmarshal(payloadBytes, p); err != nil {
+		return nil, fmt.Errorf("malformed payload: %w", err)
+	}
+	if p.TargetArtifact.Digest != imageDigest {
+		return nil, fmt.Errorf("signature is for artifact %s", p.TargetArtifact.Digest)
+	}
+
+	return leaf, nil
+}
+
+func (v *ImageVerifier) checkProtectedHeader(header *jwsProtectedHeader) error {
+	if header.ContentType != payloadContentType {
+		return fmt.Errorf("unsupported payload content type %q", header.ContentType)
+	}
+	if header.SigningScheme != signingSchemeX509 {
+		return fmt.Errorf("unsupported signing scheme %q", header.SigningScheme)
+	}
+	for _, critical := range header.Critical {
+		switch critical {
+		case headerSigningScheme, headerExpiry:
+		default:
+			return fmt.Errorf("unsupported critical header %q", critical)
+		}
+	}
+	if header.SigningTime == nil {
+		return fmt.Errorf("missing %s header", headerSigningTime)
+	}
+	if header.Expiry != nil && v.now().After(*header.Expiry) {
+		return fmt.Errorf("signature expired at %s", header.Expiry.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (v *ImageVerifier) isTrustedIdentity(cert *x509.Certificate) bool {
+	if _, ok := v.config.TrustedIdentities[anyIdentity]; ok {
+		return true
+	}
+	_, ok := v.config.TrustedIdentities[cert.Subject.String()]
+	return ok
+}
+
+// verifySignature verifies a JWS signature. Only the algorithms allowed by the
+// Notary Project signature specification are supported.
+func verifySignature(alg string, publicKey crypto.PublicKey, signingInput, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "PS256", "ES256":
+		hash = crypto.SHA256
+	case "PS384", "ES384":
+		hash = crypto.SHA384
+	case "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write(signingInput)
+	digest := h.Sum(nil)
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "PS") {
+			return fmt.Errorf("signature algorithm %q does not match an RSA key", alg)
+		}
+		if err := rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return errors.New("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("signature algorithm %q does not match an ECDSA key", alg)
+		}
+		// JWS ECDSA signatures are the concatenation of R and S
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported signing key type %T", publicKey)
+	}
+	return nil
+}
+
+// loadTrustStore loads the certificates of a trust store, which is either a
+// PEM file or a directory of PEM files.
+func loadTrustStore(path string) ([]*x509.Certificate, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return pemutil.LoadCertificates(path)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		fileCerts, err := pemutil.LoadCertificates(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, fileCerts...)
+	}
+	return certs, nil
+}
@@ -0,0 +1,367 @@
+package notation
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signerSubject = "CN=signer,O=example.org"
+
+func TestNewConfigFromHCL(t *testing.T) {
+	config := NewConfigFromHCL(&HCLConfig{
+		TrustStores:       []string{"/path/to/ca.pem"},
+		TrustedIdentities: []string{signerSubject},
+		SkippedImages:     []string{"registry/image@sha256:examplehash"},
+	}, hclog.NewNullLogger())
+
+	assert.Equal(t, []string{"/path/to/ca.pem"}, config.TrustStores)
+	assert.Equal(t, map[string]struct{}{signerSubject: {}}, config.TrustedIdentities)
+	assert.Equal(t, map[string]struct{}{"registry/image@sha256:examplehash": {}}, config.SkippedImages)
+}
+
+func TestInit(t *testing.T) {
+	dir := t.TempDir()
+	ca, _ := testca.CreateCACertificate(t, nil, nil)
+	caPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pemutil.EncodeCertificate(ca), 0600))
+	emptyDir := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(emptyDir, 0700))
+	trustStoreDir := filepath.Join(dir, "truststore")
+	require.NoError(t, os.Mkdir(trustStoreDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(trustStoreDir, "ca.pem"), pemutil.EncodeCertificate(ca), 0600))
+
+	for _, tt := range []struct {
+		name              string
+		trustStores       []string
+		trustedIdentities []string
+		expectErr         string
+	}{
+		{
+			name:              "trust store file",
+			trustStores:       []string{caPath},
+			trustedIdentities: []string{signerSubject},
+		},
+		{
+			name:              "trust store directory",
+			trustStores:       []string{trustStoreDir},
+			trustedIdentities: []string{anyIdentity},
+		},
+		{
+			name:              "no trust stores",
+			trustedIdentities: []string{signerSubject},
+			expectErr:         "at least one trust store is required",
+		},
+		{
+			name:        "no trusted identities",
+			trustStores: []string{caPath},
+			expectErr:   "at least one trusted identity is required",
+		},
+		{
+			name:              "missing trust store",
+			trustStores:       []string{filepath.Join(dir, "missing.pem")},
+			trustedIdentities: []string{signerSubject},
+			expectErr:         "failed to load trust store",
+		},
+		{
+			name:              "empty trust store",
+			trustStores:       []string{emptyDir},
+			trustedIdentities: []string{signerSubject},
+			expectErr:         "does not contain certificates",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier(NewConfigFromHCL(&HCLConfig{
+				TrustStores:       tt.trustStores,
+				TrustedIdentities: tt.trustedIdentities,
+			}, hclog.NewNullLogger()))
+
+			err := verifier.Init(context.Background())
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ca, caKey := testca.CreateCACertificate(t, nil, nil)
+	leaf, leafKey := testca.CreateX509Certificate(t, ca, caKey, testca.WithSubject(pkix.Name{
+		CommonName:   "signer",
+		Organization: []string{"example.org"},
+	}))
+	otherCA, otherCAKey := testca.CreateCACertificate(t, nil, nil)
+	otherLeaf, otherLeafKey := testca.CreateX509Certificate(t, otherCA, otherCAKey, testca.WithSubject(pkix.Name{
+		CommonName:   "signer",
+		Organization: []string{"example.org"},
+	}))
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pemutil.EncodeCertificate(ca), 0600))
+
+	now := time.Now()
+
+	for _, tt := range []struct {
+		name              string
+		trustedIdentities []string
+		skippedImages     []string
+		unsigned          bool
+		sign              func(imageDigest string) []byte
+		expectSelectors   []string
+		expectErr         string
+	}{
+		{
+			name: "valid signature",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf, ca}, nil, imageDigest)
+			},
+			expectSelectors: []string{
+				"image-signature:verified",
+				"image-signature-subject:CN=signer,O=example.org",
+				"image-signature-issuer:" + ca.Subject.String(),
+			},
+		},
+		{
+			name:              "valid signature with any identity trusted",
+			trustedIdentities: []string{anyIdentity},
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, nil, imageDigest)
+			},
+			expectSelectors: []string{
+				"image-signature:verified",
+				"image-signature-subject:CN=signer,O=example.org",
+				"image-signature-issuer:" + ca.Subject.String(),
+			},
+		},
+		{
+			name:          "skipped image",
+			skippedImages: []string{"IMAGE_ID"},
+			unsigned:      true,
+		},
+		{
+			name:      "no signatures",
+			unsigned:  true,
+			expectErr: "no signature found for image",
+		},
+		{
+			name:              "untrusted identity",
+			trustedIdentities: []string{"CN=other"},
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, nil, imageDigest)
+			},
+			expectErr: `signing identity "CN=signer,O=example.org" is not trusted`,
+		},
+		{
+			name: "untrusted certificate authority",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, otherLeafKey, []*x509.Certificate{otherLeaf, otherCA}, nil, imageDigest)
+			},
+			expectErr: "signing certificate is not trusted",
+		},
+		{
+			name: "signature for another artifact",
+			sign: func(string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, nil, "sha256:"+strings.Repeat("0", 64))
+			},
+			expectErr: "signature is for artifact sha256:" + strings.Repeat("0", 64),
+		},
+		{
+			name: "signature made with another key",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, otherLeafKey, []*x509.Certificate{leaf}, nil, imageDigest)
+			},
+			expectErr: "invalid signature",
+		},
+		{
+			name: "expired signature",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, map[string]any{
+					headerExpiry: now.Add(-time.Second).Format(time.RFC3339),
+					"crit":       []string{headerSigningScheme, headerExpiry},
+				}, imageDigest)
+			},
+			expectErr: "signature expired at",
+		},
+		{
+			name: "unsupported critical header",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, map[string]any{
+					"crit": []string{headerSigningScheme, "io.cncf.notary.authenticSigningTime"},
+				}, imageDigest)
+			},
+			expectErr: `unsupported critical header "io.cncf.notary.authenticSigningTime"`,
+		},
+		{
+			name: "unsupported signing scheme",
+			sign: func(imageDigest string) []byte {
+				return signEnvelope(t, leafKey, []*x509.Certificate{leaf}, map[string]any{
+					headerSigningScheme: "notary.x509.signingAuthority",
+				}, imageDigest)
+			},
+			expectErr: `unsupported signing scheme "notary.x509.signingAuthority"`,
+		},
+		{
+			name: "malformed envelope",
+			sign: func(string) []byte {
+				return []byte("not-json")
+			},
+			expectErr: "malformed signature envelope",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			host := startRegistry(t)
+			imageID, imageDigest := pushImage(t, host)
+			if !tt.unsigned {
+				pushSignature(t, imageID, tt.sign(imageDigest.String()))
+			}
+
+			trustedIdentities := tt.trustedIdentities
+			if trustedIdentities == nil {
+				trustedIdentities = []string{signerSubject}
+			}
+			var skippedImages []string
+			for _, image := range tt.skippedImages {
+				skippedImages = append(skippedImages, strings.ReplaceAll(image, "IMAGE_ID", imageID.String()))
+			}
+			verifier := NewVerifier(NewConfigFromHCL(&HCLConfig{
+				TrustStores:       []string{caPath},
+				TrustedIdentities: trustedIdentities,
+				SkippedImages:     skippedImages,
+			}, hclog.NewNullLogger()))
+			verifier.now = func() time.Time { return now }
+			require.NoError(t, verifier.Init(context.Background()))
+
+			selectors, err := verifier.Verify(context.Background(), imageID.String())
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				require.Nil(t, selectors)
+				return
+			}
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.expectSelectors, selectors)
+
+			// Verifications are cached
+			cached, err := verifier.Verify(context.Background(), imageID.String())
+			require.NoError(t, err)
+			require.ElementsMatch(t, tt.expectSelectors, cached)
+		})
+	}
+}
+
+func startRegistry(t *testing.T) string {
+	server := httptest.NewServer(registry.New(registry.WithReferrersSupport(true), registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func pushImage(t *testing.T, host string) (name.Digest, v1.Hash) {
+	image, err := random.Image(64, 1)
+	require.NoError(t, err)
+	digest, err := image.Digest()
+	require.NoError(t, err)
+
+	imageID, err := name.NewDigest(host + "/workload@" + digest.String())
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imageID, image))
+	return imageID, digest
+}
+
+func pushSignature(t *testing.T, imageID name.Digest, envelope []byte) {
+	desc, err := remote.Head(imageID)
+	require.NoError(t, err)
+
+	signature := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	signature = mutate.ConfigMediaType(signature, signatureArtifactType)
+	signature, err = mutate.Append(signature, mutate.Addendum{
+		Layer: static.NewLayer(envelope, jwsEnvelopeMediaType),
+	})
+	require.NoError(t, err)
+	signature, ok := mutate.Subject(signature, *desc).(v1.Image)
+	require.True(t, ok)
+
+	signatureDigest, err := signature.Digest()
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(imageID.Context().Digest(signatureDigest.String()), signature))
+}
+
+// signEnvelope creates a JWS signature envelope for the artifact with the
+// given digest. Protected header parameters can be overridden.
+func signEnvelope(t *testing.T, key crypto.Signer, chain []*x509.Certificate, headerOverrides map[string]any, artifactDigest string) []byte {
+	header := map[string]any{
+		"alg":               "ES256",
+		"cty":               payloadContentType,
+		"crit":              []string{headerSigningScheme},
+		headerSigningScheme: signingSchemeX509,
+		headerSigningTime:   time.Now().Add(-time.Minute).Format(time.RFC3339),
+	}
+	for k, v := range headerOverrides {
+		header[k] = v
+	}
+	headerBytes, err := json.Marshal(header)
+	require.NoError(t, err)
+
+	payloadBytes, err := json.Marshal(map[string]any{
+		"targetArtifact": map[string]any{
+			"mediaType": string(types.OCIManifestSchema1),
+			"digest":    artifactDigest,
+			"size":      1024,
+		},
+	})
+	require.NoError(t, err)
+
+	protected := base64.RawURLEncoding.EncodeToString(headerBytes)
+	payload := base64.RawURLEncoding.EncodeToString(payloadBytes)
+	digest := sha256.Sum256([]byte(protected + "." + payload))
+	r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+	require.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	var certChain [][]byte
+	for _, cert := range chain {
+		certChain = append(certChain, cert.Raw)
+	}
+	envelope, err := json.Marshal(map[string]any{
+		"payload":   payload,
+		"protected": protected,
+		"header": map[string]any{
+			"x5c": certChain,
+		},
+		"signature": base64.RawURLEncoding.EncodeToString(signature),
+	})
+	require.NoError(t, err)
+	return envelope
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	delegatedidentityv1 "github.com/spiffe/spire/pkg/agent/api/delegatedidentity/v1"
 	"github.com/spiffe/spire/pkg/agent/workloadkey"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
@@ -102,9 +103,25 @@ type Config struct {
 
 	AuthorizedDelegates []string
 
+	// AuthorizedDelegateScopes limits the SVIDs authorized delegates can get
+	AuthorizedDelegateScopes map[spiffeid.ID]delegatedidentityv1.DelegateScope
+
+	// AuthorizedDelegatePolicy configures a policy engine that limits the
+	// SVIDs authorized delegates can get
+	AuthorizedDelegatePolicy *delegatedidentityv1.PolicyEngineConfig
+
+	// AuthorizedCacheAdmins are the SPIFFE IDs of the workloads that can use
+	// the Cache API
+	AuthorizedCacheAdmins []string
+
 	// AvailabilityTarget controls how frequently rotate SVIDs
 	AvailabilityTarget time.Duration
 
+	// OfflineCacheKeyFile is the path to the key used to encrypt the workload
+	// SVIDs persisted to the data directory. If empty, workload SVIDs are not
+	// persisted.
+	OfflineCacheKeyFile string
+
 	// TLSPolicy determines the post-quantum-safe TLS policy to apply to all TLS connections.
 	TLSPolicy tlspolicy.Policy
 }
@@ -20,6 +20,7 @@ const (
 
 func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics) middleware.Middleware {
 	return middleware.Chain(
+		middleware.WithTracing(),
 		middleware.WithLogger(log),
 		middleware.WithMetrics(metrics),
 		withPerServiceConnectionMetrics(metrics),
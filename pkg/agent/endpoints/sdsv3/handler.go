@@ -3,11 +3,13 @@ package sdsv3
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"maps"
+	"slices"
 	"sort"
 	"strconv"
 
@@ -25,12 +27,16 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
 	disableSPIFFECertValidationKey = "disable_spiffe_cert_validation"
+
+	// wildcardResourceName subscribes delta clients to all the resources
+	wildcardResourceName = "*"
 )
 
 type Attestor interface {
@@ -220,8 +226,238 @@ func subListChanged(oldSubs []string, newSubs []string) (b bool) {
 	return false
 }
 
-func (h *Handler) DeltaSecrets(secret_v3.SecretDiscoveryService_DeltaSecretsServer) error {
-	return status.Error(codes.Unimplemented, "Method is not implemented")
+func (h *Handler) DeltaSecrets(stream secret_v3.SecretDiscoveryService_DeltaSecretsServer) error {
+	log := rpccontext.Logger(stream.Context())
+
+	selectors, err := h.c.Attestor.Attest(stream.Context())
+	if err != nil {
+		log.WithError(err).Error("Failed to attest the workload")
+		return err
+	}
+
+	sub, err := h.c.Manager.SubscribeToCacheChanges(stream.Context(), selectors)
+	if err != nil {
+		log.WithError(err).Error("Subscribe to cache changes failed")
+		return err
+	}
+	defer sub.Finish()
+
+	updch := sub.Updates()
+	reqch := make(chan *discovery_v3.DeltaDiscoveryRequest, 1)
+	errch := make(chan error, 1)
+
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				if status.Code(err) == codes.Canceled || errors.Is(err, io.EOF) {
+					err = nil
+				}
+				errch <- err
+				return
+			}
+			reqch <- req
+		}
+	}()
+
+	var versionCounter int64
+	var upd *cache.WorkloadUpdate
+	var state *deltaState
+	for {
+		select {
+		case newReq := <-reqch:
+			log.WithFields(logrus.Fields{
+				telemetry.ResourceNames:             newReq.ResourceNamesSubscribe,
+				telemetry.UnsubscribedResourceNames: newReq.ResourceNamesUnsubscribe,
+				telemetry.Nonce:                     newReq.ResponseNonce,
+			}).Debug("Received DeltaSecrets request")
+			h.triggerReceivedHook()
+
+			// If there's error detail, always log it
+			if newReq.ErrorDetail != nil {
+				log.WithFields(logrus.Fields{
+					telemetry.Nonce: newReq.ResponseNonce,
+					telemetry.Error: newReq.ErrorDetail.Message,
+				}).Error("Envoy reported errors applying secrets")
+			}
+
+			if state == nil {
+				state = newDeltaState(newReq)
+			} else {
+				state.update(newReq)
+			}
+
+			if upd == nil {
+				// Workload update has not been received yet, defer sending updates until then
+				continue
+			}
+
+		case upd = <-updch:
+			versionCounter++
+			if state == nil {
+				// Nothing has been requested yet.
+				continue
+			}
+		case err := <-errch:
+			if err != nil {
+				log.WithError(err).Error("Received error from delta secrets server")
+			}
+			return err
+		}
+
+		resp, err := h.buildDeltaResponse(strconv.FormatInt(versionCounter, 10), state, upd)
+		if err != nil {
+			log.WithError(err).Error("Error building delta secrets response")
+			return err
+		}
+		if resp == nil {
+			// The client is up to date
+			continue
+		}
+
+		log.WithFields(logrus.Fields{
+			telemetry.VersionInfo:          resp.SystemVersionInfo,
+			telemetry.Nonce:                resp.Nonce,
+			telemetry.Count:                len(resp.Resources),
+			telemetry.RemovedResourceNames: resp.RemovedResources,
+		}).Debug("Sending DeltaSecrets response")
+		if err := stream.Send(resp); err != nil {
+			log.WithError(err).Error("Error sending secrets over stream")
+			return err
+		}
+	}
+}
+
+// deltaState tracks the resources a DeltaSecrets client is subscribed to and
+// the versions of the resources the client has.
+type deltaState struct {
+	typeURL string
+	node    *core_v3.Node
+
+	// wildcard is set when the client is subscribed to all the resources,
+	// including when it does not subscribe to any resource on its first
+	// request.
+	wildcard bool
+
+	// subscribed are the resources the client explicitly subscribed to
+	subscribed map[string]bool
+
+	// versions are the versions of the resources the client has
+	versions map[string]string
+}
+
+func newDeltaState(req *discovery_v3.DeltaDiscoveryRequest) *deltaState {
+	state := &deltaState{
+		typeURL:    req.TypeUrl,
+		node:       req.Node,
+		wildcard:   len(req.ResourceNamesSubscribe) == 0,
+		subscribed: make(map[string]bool),
+		versions:   make(map[string]string, len(req.InitialResourceVersions)),
+	}
+	// On reconnection, the client tells which resources it already has so
+	// that only the ones that changed in the meantime are sent.
+	maps.Copy(state.versions, req.InitialResourceVersions)
+	state.update(req)
+	return state
+}
+
+func (s *deltaState) update(req *discovery_v3.DeltaDiscoveryRequest) {
+	// Only the first request is required to contain node information
+	if req.Node != nil {
+		s.node = req.Node
+	}
+
+	for _, name := range req.ResourceNamesSubscribe {
+		if name == wildcardResourceName {
+			s.wildcard = true
+			continue
+		}
+		s.subscribed[name] = true
+	}
+
+	// The client drops the resources it unsubscribes from, so it is not told
+	// about their removal.
+	for _, name := range req.ResourceNamesUnsubscribe {
+		if name == wildcardResourceName {
+			s.wildcard = false
+			continue
+		}
+		delete(s.subscribed, name)
+		delete(s.versions, name)
+	}
+}
+
+// buildDeltaResponse builds a response holding the resources that changed
+// since they were last sent to the client, and the resources the client has
+// that no longer exist. A nil response is returned when the client is up to
+// date.
+func (h *Handler) buildDeltaResponse(versionInfo string, state *deltaState, upd *cache.WorkloadUpdate) (*discovery_v3.DeltaDiscoveryResponse, error) {
+	current := make(map[string]*discovery_v3.Resource)
+	if state.wildcard {
+		resources, _, err := h.buildResources(state.node, nil, upd)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			current[resource.Name] = resource
+		}
+	}
+	if len(state.subscribed) > 0 {
+		// Subscribed resources that don't exist are not an error on delta
+		// streams. They are sent if they come to exist.
+		resources, _, err := h.buildResources(state.node, slices.Collect(maps.Keys(state.subscribed)), upd)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			current[resource.Name] = resource
+		}
+	}
+
+	resp := &discovery_v3.DeltaDiscoveryResponse{
+		TypeUrl:           state.typeURL,
+		SystemVersionInfo: versionInfo,
+	}
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		resource := current[name]
+		version, err := resourceVersion(resource.Resource)
+		if err != nil {
+			return nil, err
+		}
+		if state.versions[name] == version {
+			continue
+		}
+		resource.Version = version
+		state.versions[name] = version
+		resp.Resources = append(resp.Resources, resource)
+	}
+	for _, name := range slices.Sorted(maps.Keys(state.versions)) {
+		if _, ok := current[name]; !ok {
+			delete(state.versions, name)
+			resp.RemovedResources = append(resp.RemovedResources, name)
+		}
+	}
+
+	if len(resp.Resources) == 0 && len(resp.RemovedResources) == 0 {
+		return nil, nil
+	}
+
+	var err error
+	if resp.Nonce, err = nextNonce(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// resourceVersion returns a version derived from the resource content, so
+// that resources are only sent again when their content changes.
+func resourceVersion(resource *anypb.Any) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(resource)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
 }
 
 func (h *Handler) FetchSecrets(ctx context.Context, req *discovery_v3.DiscoveryRequest) (*discovery_v3.DiscoveryResponse, error) {
@@ -261,18 +497,45 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		}
 	}
 
+	resources, missing, err := h.buildResources(req.Node, req.ResourceNames, upd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "workload is not authorized for the requested identities %q", sortedNames(missing))
+	}
+
+	for _, resource := range resources {
+		resp.Resources = append(resp.Resources, resource.Resource)
+	}
+	return resp, nil
+}
+
+// buildResources builds the named secrets out of the workload update, or all
+// of them when no names are given. The names that don't match any secret are
+// returned alongside the secrets.
+func (h *Handler) buildResources(node *core_v3.Node, resourceNames []string, upd *cache.WorkloadUpdate) ([]*discovery_v3.Resource, map[string]bool, error) {
+	var resources []*discovery_v3.Resource
+	appendResource := func(name string, resource *anypb.Any) {
+		resources = append(resources, &discovery_v3.Resource{
+			Name:     name,
+			Resource: resource,
+		})
+	}
+
 	// build a convenient set of names for lookups
 	names := make(map[string]bool)
-	for _, name := range req.ResourceNames {
+	for _, name := range resourceNames {
 		if name != "" {
 			names[name] = true
 		}
 	}
 	returnAllEntries := len(names) == 0
 
-	builder, err := h.getValidationContextBuilder(req, upd)
+	builder, err := h.getValidationContextBuilder(node, upd)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// TODO: verify the type url
@@ -281,29 +544,29 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		case returnAllEntries || names[upd.Bundle.TrustDomain().IDString()]:
 			validationContext, err := builder.buildOne(upd.Bundle.TrustDomain().IDString(), upd.Bundle.TrustDomain().IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, upd.Bundle.TrustDomain().IDString())
-			resp.Resources = append(resp.Resources, validationContext)
+			appendResource(upd.Bundle.TrustDomain().IDString(), validationContext)
 
 		case names[h.c.DefaultBundleName]:
 			validationContext, err := builder.buildOne(h.c.DefaultBundleName, upd.Bundle.TrustDomain().IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, h.c.DefaultBundleName)
-			resp.Resources = append(resp.Resources, validationContext)
+			appendResource(h.c.DefaultBundleName, validationContext)
 
 		case names[h.c.DefaultAllBundlesName]:
 			validationContext, err := builder.buildAll(h.c.DefaultAllBundlesName)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			delete(names, h.c.DefaultAllBundlesName)
-			resp.Resources = append(resp.Resources, validationContext)
+			appendResource(h.c.DefaultAllBundlesName, validationContext)
 		}
 	}
 
@@ -311,10 +574,10 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		if returnAllEntries || names[federatedBundle.TrustDomain().IDString()] {
 			validationContext, err := builder.buildOne(td.IDString(), td.IDString())
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, federatedBundle.TrustDomain().IDString())
-			resp.Resources = append(resp.Resources, validationContext)
+			appendResource(td.IDString(), validationContext)
 		}
 	}
 
@@ -323,25 +586,21 @@ func (h *Handler) buildResponse(versionInfo string, req *discovery_v3.DiscoveryR
 		case returnAllEntries || names[identity.Entry.SpiffeId]:
 			tlsCertificate, err := buildTLSCertificate(identity, "")
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, identity.Entry.SpiffeId)
-			resp.Resources = append(resp.Resources, tlsCertificate)
+			appendResource(identity.Entry.SpiffeId, tlsCertificate)
 		case i == 0 && names[h.c.DefaultSVIDName]:
 			tlsCertificate, err := buildTLSCertificate(identity, h.c.DefaultSVIDName)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			delete(names, h.c.DefaultSVIDName)
-			resp.Resources = append(resp.Resources, tlsCertificate)
+			appendResource(h.c.DefaultSVIDName, tlsCertificate)
 		}
 	}
 
-	if len(names) > 0 {
-		return nil, status.Errorf(codes.InvalidArgument, "workload is not authorized for the requested identities %q", sortedNames(names))
-	}
-
-	return resp, nil
+	return resources, names, nil
 }
 
 func (h *Handler) triggerReceivedHook() {
@@ -355,10 +614,10 @@ type validationContextBuilder interface {
 	buildAll(resourceName string) (*anypb.Any, error)
 }
 
-func (h *Handler) getValidationContextBuilder(req *discovery_v3.DiscoveryRequest, upd *cache.WorkloadUpdate) (validationContextBuilder, error) {
+func (h *Handler) getValidationContextBuilder(node *core_v3.Node, upd *cache.WorkloadUpdate) (validationContextBuilder, error) {
 	federatedBundles := make(map[spiffeid.TrustDomain]*spiffebundle.Bundle)
 	maps.Copy(federatedBundles, upd.FederatedBundles)
-	if !h.isSPIFFECertValidationDisabled(req) && supportsSPIFFEAuthExtension(req) {
+	if !h.isSPIFFECertValidationDisabled(node) && supportsSPIFFEAuthExtension(node) {
 		return newSpiffeBuilder(upd.Bundle, federatedBundles)
 	}
 
@@ -511,8 +770,8 @@ func (b *spiffeBuilder) buildAll(resourceName string) (*anypb.Any, error) {
 	})
 }
 
-func supportsSPIFFEAuthExtension(req *discovery_v3.DiscoveryRequest) bool {
-	if buildVersion := req.Node.GetUserAgentBuildVersion(); buildVersion != nil {
+func supportsSPIFFEAuthExtension(node *core_v3.Node) bool {
+	if buildVersion := node.GetUserAgentBuildVersion(); buildVersion != nil {
 		version := buildVersion.Version
 		return (version.MajorNumber == 1 && version.MinorNumber > 17) || version.MajorNumber > 1
 	}
@@ -520,9 +779,9 @@ func supportsSPIFFEAuthExtension(req *discovery_v3.DiscoveryRequest) bool {
 	return true
 }
 
-func (h *Handler) isSPIFFECertValidationDisabled(req *discovery_v3.DiscoveryRequest) bool {
+func (h *Handler) isSPIFFECertValidationDisabled(node *core_v3.Node) bool {
 	disabled := h.c.DisableSPIFFECertValidation
-	if v, ok := req.Node.GetMetadata().GetFields()[disableSPIFFECertValidationKey]; ok {
+	if v, ok := node.GetMetadata().GetFields()[disableSPIFFECertValidationKey]; ok {
 		// error means that field have some unexpected value
 		// so it would be safer to assume that key doesn't exist in envoy node metadata
 		if override, err := parseBool(v); err == nil {
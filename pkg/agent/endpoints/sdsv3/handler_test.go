@@ -1198,12 +1198,195 @@ func TestFetchSecrets(t *testing.T) {
 	}
 }
 
-func DeltaSecretsTest(t *testing.T) {
+func TestDeltaSecretsWildcard(t *testing.T) {
 	test := setupTest(t)
-	defer test.cleanup()
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.SystemVersionInfo)
+	require.NotEmpty(t, resp.Nonce)
+	requireDeltaSecrets(t, resp, tdValidationContext, workloadTLSCertificate1, fedValidationContext)
+	require.Empty(t, resp.RemovedResources)
+
+	// Only the rotated certificate is sent
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResponseNonce: resp.Nonce,
+	})
+	test.setWorkloadUpdate(workloadCert2)
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+	require.Empty(t, resp.RemovedResources)
+
+	// Resources that no longer exist are removed
+	test.manager.SetWorkloadUpdate(&cache.WorkloadUpdate{
+		Identities: []cache.Identity{
+			{
+				Entry: &common.RegistrationEntry{
+					SpiffeId: "spiffe://domain.test/workload",
+				},
+				SVID:       []*x509.Certificate{workloadCert2},
+				PrivateKey: workloadKey,
+			},
+		},
+		Bundle: tdBundle,
+	})
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Resources)
+	require.Equal(t, []string{"spiffe://otherdomain.test"}, resp.RemovedResources)
+}
+
+func TestDeltaSecretsSubscriptions(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	// The node information of the first request is kept, so SPIFFE cert
+	// validation remains disabled for later responses.
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"default", "spiffe://domain.test/unknown"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV18,
+			Metadata: &structpb.Struct{Fields: map[string]*structpb.Value{
+				disableSPIFFECertValidationKey: structpb.NewBoolValue(true),
+			}},
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate3)
+
+	// Only the newly subscribed resource is sent
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test"},
+		ResponseNonce:          resp.Nonce,
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext)
+
+	// Unsubscribed resources are neither updated nor removed
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesUnsubscribe: []string{"default"},
+		ResponseNonce:            resp.Nonce,
+	})
+	test.setWorkloadUpdate(workloadCert2)
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://otherdomain.test"},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, fedValidationContext)
+	require.Empty(t, resp.RemovedResources)
+}
+
+func TestDeltaSecretsInitialResourceVersions(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test", "spiffe://domain.test/workload"},
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, tdValidationContext, workloadTLSCertificate1)
+	require.NoError(t, stream.CloseSend())
 
-	resp, err := test.handler.DeltaSecrets(context.Background())
-	spiretest.RequireGRPCStatus(t, err, codes.Unimplemented, "Method is not implemented")
+	versions := map[string]string{
+		"spiffe://domain.test/removed": "1234",
+	}
+	for _, resource := range resp.Resources {
+		versions[resource.Name] = resource.Version
+	}
+
+	// On reconnection, resources the client already has are not sent again
+	stream, err = test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe:  []string{"spiffe://domain.test", "spiffe://domain.test/workload", "spiffe://domain.test/removed"},
+		InitialResourceVersions: versions,
+		Node: &core_v3.Node{
+			UserAgentVersionType: userAgentVersionTypeV17,
+		},
+	})
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	require.Empty(t, resp.Resources)
+	require.Equal(t, []string{"spiffe://domain.test/removed"}, resp.RemovedResources)
+
+	test.setWorkloadUpdate(workloadCert2)
+
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsRequestReceivedBeforeWorkloadUpdate(t *testing.T) {
+	test := setupTest(t)
+	defer test.server.Stop()
+
+	test.setWorkloadUpdate(nil)
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	test.sendDeltaAndWait(stream, &discovery_v3.DeltaDiscoveryRequest{
+		ResourceNamesSubscribe: []string{"spiffe://domain.test/workload"},
+	})
+
+	test.setWorkloadUpdate(workloadCert2)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	requireDeltaSecrets(t, resp, workloadTLSCertificate2)
+}
+
+func TestDeltaSecretsErrInSubscribeToCacheChanges(t *testing.T) {
+	test := setupErrTest(t)
+	defer test.server.Stop()
+
+	stream, err := test.handler.DeltaSecrets(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, stream.CloseSend())
+	}()
+
+	resp, err := stream.Recv()
+	require.Error(t, err)
 	require.Nil(t, resp)
 }
 
@@ -1310,6 +1493,17 @@ func (h *handlerTest) sendAndWait(stream secret_v3.SecretDiscoveryService_Stream
 	}
 }
 
+func (h *handlerTest) sendDeltaAndWait(stream secret_v3.SecretDiscoveryService_DeltaSecretsClient, req *discovery_v3.DeltaDiscoveryRequest) {
+	require.NoError(h.t, stream.Send(req))
+	timer := time.NewTimer(time.Second)
+	defer timer.Stop()
+	select {
+	case <-h.received:
+	case <-timer.C:
+		assert.Fail(h.t, "timed out waiting for request to be received")
+	}
+}
+
 type FakeAttestor []*common.Selector
 
 func (a FakeAttestor) Attest(context.Context) ([]*common.Selector, error) {
@@ -1350,6 +1544,8 @@ func (m *FakeManager) SubscribeToCacheChanges(_ context.Context, selectors cache
 	m.next++
 	m.subs[key] = updch
 	return NewFakeSubscriber(updch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
 		delete(m.subs, key)
 		close(updch)
 	}), nil
@@ -1440,3 +1636,16 @@ func requireSecrets(t *testing.T, resp *discovery_v3.DiscoveryResponse, expected
 
 	spiretest.RequireProtoListEqual(t, expectedSecrets, actualSecrets)
 }
+
+func requireDeltaSecrets(t *testing.T, resp *discovery_v3.DeltaDiscoveryResponse, expectedSecrets ...*tls_v3.Secret) {
+	var actualSecrets []*tls_v3.Secret
+	for _, resource := range resp.Resources {
+		secret := new(tls_v3.Secret)
+		require.NoError(t, resource.Resource.UnmarshalTo(secret))
+		require.Equal(t, secret.Name, resource.Name)
+		require.NotEmpty(t, resource.Version)
+		actualSecrets = append(actualSecrets, secret)
+	}
+
+	spiretest.RequireProtoListEqual(t, expectedSecrets, actualSecrets)
+}
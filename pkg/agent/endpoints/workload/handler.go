@@ -22,6 +22,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/proto/spire/common"
+	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -76,7 +77,7 @@ func (h *Handler) FetchJWTSVID(ctx context.Context, req *workload.JWTSVIDRequest
 		}
 	}
 
-	selectors, err := h.c.Attestor.Attest(ctx)
+	selectors, err := h.attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
 		return nil, err
@@ -115,7 +116,7 @@ func (h *Handler) FetchJWTBundles(_ *workload.JWTBundlesRequest, stream workload
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	selectors, err := h.c.Attestor.Attest(ctx)
+	selectors, err := h.attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
 		return err
@@ -155,7 +156,7 @@ func (h *Handler) ValidateJWTSVID(ctx context.Context, req *workload.ValidateJWT
 
 	log = log.WithField(telemetry.Audience, req.Audience)
 
-	selectors, err := h.c.Attestor.Attest(ctx)
+	selectors, err := h.attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
 		return nil, err
@@ -212,7 +213,7 @@ func (h *Handler) FetchX509SVID(_ *workload.X509SVIDRequest, stream workload.Spi
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	selectors, err := h.c.Attestor.Attest(ctx)
+	selectors, err := h.attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
 		return err
@@ -246,7 +247,7 @@ func (h *Handler) FetchX509Bundles(_ *workload.X509BundlesRequest, stream worklo
 	ctx := stream.Context()
 	log := rpccontext.Logger(ctx)
 
-	selectors, err := h.c.Attestor.Attest(ctx)
+	selectors, err := h.attest(ctx)
 	if err != nil {
 		log.WithError(err).Error("Workload attestation failed")
 		return err
@@ -276,7 +277,26 @@ func (h *Handler) FetchX509Bundles(_ *workload.X509BundlesRequest, stream worklo
 	}
 }
 
-func (h *Handler) fetchJWTSVID(ctx context.Context, log logrus.FieldLogger, entry *common.RegistrationEntry, audience []string) (*workload.JWTSVID, error) {
+// attest attests the calling workload, returning its selectors.
+func (h *Handler) attest(ctx context.Context) (_ []*common.Selector, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "workload.Attest")
+	defer telemetry.EndSpan(span, &err)
+
+	selectors, err := h.c.Attestor.Attest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("selectors", len(selectors)))
+	return selectors, nil
+}
+
+func (h *Handler) fetchJWTSVID(ctx context.Context, log logrus.FieldLogger, entry *common.RegistrationEntry, audience []string) (_ *workload.JWTSVID, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "workload.FetchJWTSVIDForEntry",
+		attribute.String("entry_id", entry.EntryId),
+		attribute.String("spiffe_id", entry.SpiffeId),
+	)
+	defer telemetry.EndSpan(span, &err)
+
 	spiffeID, err := spiffeid.FromString(entry.SpiffeId)
 	if err != nil {
 		log.WithError(err).Error("Invalid requested SPIFFE ID")
@@ -346,6 +366,11 @@ func composeX509BundlesResponse(update *cache.WorkloadUpdate) (*workload.X509Bun
 }
 
 func sendX509SVIDResponse(update *cache.WorkloadUpdate, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer, log logrus.FieldLogger, quietLogging bool) (err error) {
+	_, span := telemetry.StartSpan(stream.Context(), "workload.SendX509SVIDResponse",
+		attribute.Int("identities", len(update.Identities)),
+	)
+	defer telemetry.EndSpan(span, &err)
+
 	if len(update.Identities) == 0 {
 		if !quietLogging {
 			log.WithField(telemetry.Registered, false).Error("No identity issued")
@@ -141,6 +141,23 @@ func (c *JWTSVIDCache) TaintJWTSVIDs(ctx context.Context, taintedJWTAuthorities
 	agent.AddCacheManagerTaintedJWTSVIDsSample(c.metrics, agent.CacheTypeWorkload, float32(totalCount))
 }
 
+// RemoveStaleJWTSVIDs removes the cached JWT-SVIDs for which isStale returns
+// true. It returns the amount of JWT-SVIDs removed.
+func (c *JWTSVIDCache) RemoveStaleJWTSVIDs(isStale func(svid *client.JWTSVID) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, element := range c.svids {
+		if isStale(element.Value.(jwtSvidElement).svid) {
+			delete(c.svids, key)
+			c.lruList.Remove(element)
+			removed++
+		}
+	}
+	return removed
+}
+
 func getKeyIDFromSVIDToken(svidToken string) (string, error) {
 	token, err := jwt.ParseSigned(svidToken, jwtsvid.AllowedSignatureAlgorithms)
 	if err != nil {
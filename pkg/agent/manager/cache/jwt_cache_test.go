@@ -262,6 +262,35 @@ func TestJWTSVIDCacheSize(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestJWTSVIDCacheRemoveStaleJWTSVIDs(t *testing.T) {
+	log, _ := test.NewNullLogger()
+	cache := NewJWTSVIDCache(log, fakemetrics.New(), 8)
+
+	now := time.Now()
+	expired := &client.JWTSVID{Token: "1", IssuedAt: now.Add(-time.Hour), ExpiresAt: now.Add(-time.Minute)}
+	valid := &client.JWTSVID{Token: "2", IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	spiffeID := spiffeid.RequireFromString("spiffe://example.org/blog")
+	cache.SetJWTSVID(spiffeID, []string{"audience-1"}, expired)
+	cache.SetJWTSVID(spiffeID, []string{"audience-2"}, valid)
+
+	removed := cache.RemoveStaleJWTSVIDs(func(svid *client.JWTSVID) bool {
+		return !svid.ExpiresAt.After(now)
+	})
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, 1, cache.CountJWTSVIDs())
+
+	_, ok := cache.GetJWTSVID(spiffeID, []string{"audience-1"})
+	assert.False(t, ok)
+
+	actual, ok := cache.GetJWTSVID(spiffeID, []string{"audience-2"})
+	assert.True(t, ok)
+	assert.Equal(t, valid, actual)
+
+	// The removed SVID no longer takes room in the cache
+	assert.Equal(t, 1, cache.lruList.Len())
+}
+
 func TestJWTSVIDCacheKeyHashing(t *testing.T) {
 	spiffeID := spiffeid.RequireFromString("spiffe://example.org/blog")
 	now := time.Now()
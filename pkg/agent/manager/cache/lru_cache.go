@@ -58,6 +58,27 @@ type StaleEntry struct {
 	SVIDExpiresAt time.Time
 }
 
+// CachedEntry holds a cached registration entry with the state of its SVID
+type CachedEntry struct {
+	// Entry cached registration entry
+	Entry *common.RegistrationEntry
+	// SVIDExpiresAt expiration time of the cached SVID, unset if the entry
+	// does not have an SVID cached
+	SVIDExpiresAt time.Time
+	// Stale is set when the entry is waiting for a new SVID
+	Stale bool
+	// Subscribers amount of subscribers the entry is served to
+	Subscribers int
+}
+
+// SubscriberSet holds the amount of subscribers for a workload selector set
+type SubscriberSet struct {
+	// Selectors workload selectors of the subscribers
+	Selectors []*common.Selector
+	// Count amount of subscribers with these selectors
+	Count int
+}
+
 // Cache caches each registration entry, bundles, and JWT SVIDs for the agent.
 // The signed X509-SVIDs for those entries are stored in LRU-like cache.
 // It allows subscriptions by (workload) selector sets and notifies subscribers when:
@@ -192,7 +213,7 @@ func (c *LRUCache) Identities() []Identity {
 			// from the cache.
 			continue
 		}
-		out = append(out, makeNewIdentity(record, svid))
+		out = append(out, makeNewIdentity(record.entry, svid))
 	}
 	sortIdentities(out)
 	return out
@@ -261,6 +282,58 @@ func (c *LRUCache) NewSubscriber(selectors []*common.Selector) Subscriber {
 	return sub
 }
 
+// Snapshot returns a copy of the bundles, registration entries and X509-SVIDs
+// held by the cache so they can be persisted and restored on agent restart.
+func (c *LRUCache) Snapshot() *Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snapshot := &Snapshot{
+		Bundles:   make(map[spiffeid.TrustDomain]*spiffebundle.Bundle, len(c.bundles)),
+		Entries:   make(map[string]*common.RegistrationEntry, len(c.records)),
+		X509SVIDs: make(map[string]*X509SVID, len(c.svids)),
+	}
+	for td, bundle := range c.bundles {
+		snapshot.Bundles[td] = bundle
+	}
+	for id, record := range c.records {
+		snapshot.Entries[id] = record.entry
+	}
+	for id, svid := range c.svids {
+		snapshot.X509SVIDs[id] = svid
+	}
+	return snapshot
+}
+
+// Restore populates the cache with a snapshot taken by a previous agent run.
+// The bundle for the agent trust domain is not restored, since the agent
+// bundle is loaded on startup. X509-SVIDs for entries that are not in the
+// snapshot are discarded.
+func (c *LRUCache) Restore(snapshot *Snapshot) {
+	bundles := make(map[spiffeid.TrustDomain]*spiffebundle.Bundle, len(snapshot.Bundles))
+	for td, bundle := range snapshot.Bundles {
+		bundles[td] = bundle
+	}
+	bundles[c.trustDomain] = c.Bundle()
+
+	c.UpdateEntries(&UpdateEntries{
+		Bundles:             bundles,
+		RegistrationEntries: snapshot.Entries,
+	}, nil)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id, svid := range snapshot.X509SVIDs {
+		if _, ok := c.records[id]; !ok {
+			c.log.WithField(telemetry.RegistrationID, id).Debug("Discarding restored SVID for unknown entry")
+			continue
+		}
+		c.svids[id] = svid
+		delete(c.staleEntries, id)
+	}
+}
+
 // UpdateEntries updates the cache with the provided registration entries and bundles and
 // notifies impacted subscribers. The checkSVID callback, if provided, is used to determine
 // if the SVID for the entry is stale, or otherwise in need of rotation. Entries marked stale
@@ -576,6 +649,100 @@ func (c *LRUCache) GetStaleEntries() []*StaleEntry {
 	return staleEntries
 }
 
+// CachedEntries returns the cached registration entries, ordered by entry ID.
+func (c *LRUCache) CachedEntries() []*CachedEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]*CachedEntry, 0, len(c.records))
+	for _, record := range c.records {
+		out = append(out, c.cachedEntry(record.entry))
+	}
+	sortCachedEntries(out)
+	return out
+}
+
+// CachedEntry returns the cached registration entry for the given entry ID.
+func (c *LRUCache) CachedEntry(id string) (*CachedEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	record, ok := c.records[id]
+	if !ok {
+		return nil, false
+	}
+	return c.cachedEntry(record.entry), true
+}
+
+func (c *LRUCache) cachedEntry(entry *common.RegistrationEntry) *CachedEntry {
+	cachedEntry := &CachedEntry{
+		Entry: entry,
+		Stale: c.staleEntries[entry.EntryId],
+	}
+	if svid, ok := c.svids[entry.EntryId]; ok && len(svid.Chain) > 0 {
+		cachedEntry.SVIDExpiresAt = svid.Chain[0].NotAfter
+	}
+
+	set, setDone := allocSelectorSet(entry.Selectors...)
+	defer setDone()
+	subs, subsDone := c.getSubscribers(set)
+	defer subsDone()
+	for sub := range subs {
+		if sub.set.SuperSetOf(set) {
+			cachedEntry.Subscribers++
+		}
+	}
+	return cachedEntry
+}
+
+// Subscribers returns the amount of active subscribers for each workload
+// selector set, ordered by selectors.
+func (c *LRUCache) Subscribers() []*SubscriberSet {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	subs, subsDone := c.allSubscribers()
+	defer subsDone()
+
+	sets := make(map[string]*SubscriberSet)
+	for sub := range subs {
+		selectors := sub.set.Selectors()
+		sortSelectors(selectors)
+		key := selectorsKey(selectors)
+		if set, ok := sets[key]; ok {
+			set.Count++
+			continue
+		}
+		sets[key] = &SubscriberSet{
+			Selectors: selectors,
+			Count:     1,
+		}
+	}
+
+	out := make([]*SubscriberSet, 0, len(sets))
+	for _, set := range sets {
+		out = append(out, set)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		return selectorsKey(out[a].Selectors) < selectorsKey(out[b].Selectors)
+	})
+	return out
+}
+
+// MarkX509SVIDStale marks the entry with the given ID as stale so that a new
+// SVID is minted for it in the next SVID sync. The cached SVID is served
+// until it is replaced. It returns false if the entry is not cached.
+func (c *LRUCache) MarkX509SVIDStale(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.records[id]; !ok {
+		return false
+	}
+	c.staleEntries[id] = true
+	return true
+}
+
 // SyncSVIDsWithSubscribers will sync svid cache:
 // entries with active subscribers which are not cached will be put in staleEntries map
 // records which are not cached for remainder of max cache size will also be put in staleEntries map
@@ -966,7 +1133,7 @@ func (c *LRUCache) matchingIdentities(set selectorSet) []Identity {
 	out := make([]Identity, 0, len(records))
 	for record := range records {
 		if svid, ok := c.svids[record.entry.EntryId]; ok {
-			out = append(out, makeNewIdentity(record, svid))
+			out = append(out, makeNewIdentity(record.entry, svid))
 		}
 	}
 	sortIdentities(out)
@@ -1110,9 +1277,9 @@ func sortByTimestamps(records []recordAccessEvent) {
 	})
 }
 
-func makeNewIdentity(record *lruCacheRecord, svid *X509SVID) Identity {
+func makeNewIdentity(entry *common.RegistrationEntry, svid *X509SVID) Identity {
 	return Identity{
-		Entry:      record.entry,
+		Entry:      entry,
 		SVID:       svid.Chain,
 		PrivateKey: svid.PrivateKey,
 	}
@@ -810,6 +810,144 @@ func TestSyncSVIDsWithSubscribers(t *testing.T) {
 	assert.Equal(t, svidCacheMaxSize, cache.CountX509SVIDs())
 }
 
+func TestLRUCacheSnapshotRestore(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "A")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV2, otherBundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar),
+	}, nil)
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo, bar),
+	})
+
+	snapshot := cache.Snapshot()
+	assert.Equal(t, makeBundles(bundleV2, otherBundleV1), snapshot.Bundles)
+	assert.Equal(t, makeRegistrationEntries(foo, bar), snapshot.Entries)
+	assert.Equal(t, makeX509SVIDs(foo, bar), snapshot.X509SVIDs)
+
+	// SVIDs that do not belong to an entry in the snapshot are discarded
+	snapshot.X509SVIDs["BAZ"] = &X509SVID{}
+
+	restored := newTestLRUCache(t)
+	restored.Restore(snapshot)
+	assert.Equal(t, 2, restored.CountX509SVIDs())
+	assert.Empty(t, restored.GetStaleEntries())
+
+	// The bundle for the agent trust domain is not restored
+	assert.Equal(t, bundleV1, restored.Bundle())
+	assert.Equal(t, makeBundles(bundleV1, otherBundleV1), restored.Snapshot().Bundles)
+
+	// Restored SVIDs are served without waiting for them to be cached
+	sub, err := restored.SubscribeToWorkloadUpdates(context.Background(), makeSelectors("A"))
+	require.NoError(t, err)
+	defer sub.Finish()
+	assertWorkloadUpdateEqual(t, sub, &WorkloadUpdate{
+		Bundle:     bundleV1,
+		Identities: []Identity{{Entry: bar}, {Entry: foo}},
+	})
+}
+
+func TestLRUCacheCachedEntries(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "B")
+	baz := makeRegistrationEntry("BAZ", "C")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar, baz),
+	}, nil)
+
+	subA := cache.NewSubscriber(makeSelectors("A"))
+	defer subA.Finish()
+	subB := cache.NewSubscriber(makeSelectors("B"))
+	defer subB.Finish()
+	cache.SyncSVIDsWithSubscribers()
+
+	expiresAt := time.Now().Truncate(time.Second)
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: map[string]*X509SVID{
+			foo.EntryId: {Chain: []*x509.Certificate{{NotAfter: expiresAt}}},
+			baz.EntryId: {Chain: []*x509.Certificate{{NotAfter: expiresAt}}},
+		},
+	})
+
+	assert.Equal(t, []*CachedEntry{
+		{Entry: bar, Stale: true, Subscribers: 1},
+		{Entry: baz, SVIDExpiresAt: expiresAt},
+		{Entry: foo, SVIDExpiresAt: expiresAt, Subscribers: 1},
+	}, cache.CachedEntries())
+
+	cachedEntry, ok := cache.CachedEntry(foo.EntryId)
+	assert.True(t, ok)
+	assert.Equal(t, &CachedEntry{Entry: foo, SVIDExpiresAt: expiresAt, Subscribers: 1}, cachedEntry)
+
+	cachedEntry, ok = cache.CachedEntry(bar.EntryId)
+	assert.True(t, ok)
+	assert.Equal(t, &CachedEntry{Entry: bar, Stale: true, Subscribers: 1}, cachedEntry)
+
+	_, ok = cache.CachedEntry("UNKNOWN")
+	assert.False(t, ok)
+}
+
+func TestLRUCacheSubscribers(t *testing.T) {
+	cache := newTestLRUCache(t)
+	assert.Empty(t, cache.Subscribers())
+
+	subAB1 := cache.NewSubscriber(makeSelectors("B", "A"))
+	defer subAB1.Finish()
+	subAB2 := cache.NewSubscriber(makeSelectors("A", "B"))
+	defer subAB2.Finish()
+	subC := cache.NewSubscriber(makeSelectors("C"))
+
+	assert.Equal(t, []*SubscriberSet{
+		{Selectors: makeSelectors("A", "B"), Count: 2},
+		{Selectors: makeSelectors("C"), Count: 1},
+	}, cache.Subscribers())
+
+	subC.Finish()
+	assert.Equal(t, []*SubscriberSet{
+		{Selectors: makeSelectors("A", "B"), Count: 2},
+	}, cache.Subscribers())
+}
+
+func TestLRUCacheMarkX509SVIDStale(t *testing.T) {
+	cache := newTestLRUCache(t)
+
+	foo := makeRegistrationEntry("FOO", "A")
+	bar := makeRegistrationEntry("BAR", "A")
+	cache.UpdateEntries(&UpdateEntries{
+		Bundles:             makeBundles(bundleV1),
+		RegistrationEntries: makeRegistrationEntries(foo, bar),
+	}, nil)
+
+	expiresAt := time.Now()
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: map[string]*X509SVID{
+			foo.EntryId: {Chain: []*x509.Certificate{{NotAfter: expiresAt}}},
+			bar.EntryId: {Chain: []*x509.Certificate{{NotAfter: expiresAt}}},
+		},
+	})
+	assert.Empty(t, cache.GetStaleEntries())
+
+	// Unknown entries have no SVID to rotate
+	assert.False(t, cache.MarkX509SVIDStale("UNKNOWN"))
+	assert.Empty(t, cache.GetStaleEntries())
+
+	assert.True(t, cache.MarkX509SVIDStale(foo.EntryId))
+	assert.Equal(t, []*StaleEntry{{Entry: foo, SVIDExpiresAt: expiresAt}}, cache.GetStaleEntries())
+
+	// The SVID is still served until it is replaced
+	assert.Equal(t, 2, cache.CountX509SVIDs())
+	cache.UpdateSVIDs(&UpdateSVIDs{
+		X509SVIDs: makeX509SVIDs(foo),
+	})
+	assert.Empty(t, cache.GetStaleEntries())
+}
+
 func TestNotifySubscriberWhenSVIDIsAvailable(t *testing.T) {
 	cache := newTestLRUCache(t)
 
@@ -106,6 +106,14 @@ func (set selectorSet) In(ss ...*common.Selector) bool {
 	return true
 }
 
+func (set selectorSet) Selectors() []*common.Selector {
+	selectors := make([]*common.Selector, 0, len(set))
+	for s := range set {
+		selectors = append(selectors, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+	return selectors
+}
+
 func (set selectorSet) SuperSetOf(other selectorSet) bool {
 	for k := range other {
 		if _, ok := set[k]; !ok {
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/spiffe/spire/proto/spire/common"
 )
@@ -17,3 +18,30 @@ func sortIdentities(identities []Identity) {
 		return identities[a].Entry.EntryId < identities[b].Entry.EntryId
 	})
 }
+
+func sortCachedEntries(entries []*CachedEntry) {
+	sort.Slice(entries, func(a, b int) bool {
+		return entries[a].Entry.EntryId < entries[b].Entry.EntryId
+	})
+}
+
+func sortSelectors(selectors []*common.Selector) {
+	sort.Slice(selectors, func(a, b int) bool {
+		if selectors[a].Type != selectors[b].Type {
+			return selectors[a].Type < selectors[b].Type
+		}
+		return selectors[a].Value < selectors[b].Value
+	})
+}
+
+// selectorsKey returns a key identifying the given sorted selectors
+func selectorsKey(selectors []*common.Selector) string {
+	var b strings.Builder
+	for _, s := range selectors {
+		b.WriteString(s.Type)
+		b.WriteByte(0)
+		b.WriteString(s.Value)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -41,3 +41,17 @@ type X509SVID struct {
 	Chain      []*x509.Certificate
 	PrivateKey crypto.Signer
 }
+
+// Snapshot holds the bundles, registration entries and X509-SVIDs of the
+// cache, used to persist the cache across agent restarts.
+type Snapshot struct {
+	// Bundles is the set of trust bundles, keyed by trust domain
+	Bundles map[spiffeid.TrustDomain]*spiffebundle.Bundle
+
+	// Entries is the set of registration entries, keyed by registration
+	// entry ID
+	Entries map[string]*common.RegistrationEntry
+
+	// X509SVIDs is the set of X509-SVIDs, keyed by registration entry ID
+	X509SVIDs map[string]*X509SVID
+}
@@ -34,6 +34,7 @@ type Config struct {
 	Metrics                  telemetry.Metrics
 	ServerAddr               string
 	Storage                  storage.Storage
+	WorkloadCache            *storage.WorkloadCache
 	WorkloadKeyType          workloadkey.KeyType
 	SyncInterval             time.Duration
 	UseSyncAuthorizedEntries bool
@@ -98,6 +99,7 @@ func newManager(c *Config) *manager {
 		client:         client,
 		clk:            c.Clk,
 		svidStoreCache: c.SVIDStoreCache,
+		syncRequests:   make(chan chan error),
 
 		processedTaintedX509Authorities: make(map[string]struct{}),
 		processedTaintedJWTAuthorities:  make(map[string]struct{}),
@@ -92,6 +92,29 @@ type Manager interface {
 
 	// GetBundle get latest cached bundle
 	GetBundle() *cache.Bundle
+
+	// CachedEntries returns the registration entries in the workload cache
+	CachedEntries() []*cache.CachedEntry
+
+	// CachedEntry returns the registration entry in the workload cache with
+	// the given entry ID or materialized entry ID
+	CachedEntry(id string) (*cache.CachedEntry, bool)
+
+	// CacheSubscribers returns the amount of subscribers to the workload cache
+	// for each workload selector set
+	CacheSubscribers() []*cache.SubscriberSet
+
+	// Resync synchronizes the authorized entries and bundles with the server
+	// immediately. It blocks until the synchronization finishes.
+	Resync(ctx context.Context) error
+
+	// RotateX509SVID forces the rotation of the X509-SVID of the cached entry
+	// with the given ID on the next SVID sync
+	RotateX509SVID(id string) error
+
+	// EvictStaleJWTSVIDs removes the JWT-SVIDs that are expired or about to
+	// expire from the JWT-SVID cache. It returns the amount of JWT-SVIDs removed.
+	EvictStaleJWTSVIDs() int
 }
 
 // Cache stores each registration entry, signed X509-SVIDs for those entries,
@@ -134,6 +157,27 @@ type Cache interface {
 
 	// Identities get all identities in cache
 	Identities() []cache.Identity
+
+	// Snapshot gets the bundles, registration entries and X509-SVIDs in cache
+	Snapshot() *cache.Snapshot
+
+	// Restore populates the cache from a snapshot
+	Restore(snapshot *cache.Snapshot)
+
+	// CachedEntries gets all registration entries with the state of their SVIDs
+	CachedEntries() []*cache.CachedEntry
+
+	// CachedEntry gets a registration entry with the state of its SVID
+	CachedEntry(id string) (*cache.CachedEntry, bool)
+
+	// Subscribers gets the amount of subscribers for each selector set
+	Subscribers() []*cache.SubscriberSet
+
+	// MarkX509SVIDStale marks an entry as stale to force the rotation of its SVID
+	MarkX509SVIDStale(id string) bool
+
+	// RemoveStaleJWTSVIDs removes stale JWT-SVIDs from cache
+	RemoveStaleJWTSVIDs(isStale func(svid *client.JWTSVID) bool) int
 }
 
 type manager struct {
@@ -163,6 +207,10 @@ type manager struct {
 	// Saves last success sync
 	lastSync time.Time
 
+	// syncRequests receives requests for an immediate synchronization. The
+	// result of the synchronization is sent on the request channel.
+	syncRequests chan chan error
+
 	// Cache for 'storable' SVIDs
 	svidStoreCache *storecache.Cache
 
@@ -195,14 +243,24 @@ func (m *manager) Initialize(ctx context.Context) error {
 	m.syncedEntries = make(map[string]*common.RegistrationEntry)
 	m.syncedBundles = make(map[string]*common.Bundle)
 
+	restored := m.restoreWorkloadCache()
+
 	err := m.synchronize(ctx)
 	if nodeutil.ShouldAgentReattest(err) {
 		m.c.Log.WithError(err).Error("Agent needs to re-attest: removing SVID and shutting down")
 		m.deleteSVID()
+		return err
 	}
 	if nodeutil.ShouldAgentShutdown(err) {
 		m.c.Log.WithError(err).Error("Agent is banned: removing SVID and shutting down")
 		m.deleteSVID()
+		return err
+	}
+	if err != nil && restored {
+		// Keep serving the restored SVIDs; synchronization is retried
+		// once the manager runs.
+		m.c.Log.WithError(err).Warn("Initial synchronization failed; serving workload SVIDs from the workload cache")
+		return nil
 	}
 	return err
 }
@@ -281,6 +339,33 @@ func (m *manager) CountSVIDStoreX509SVIDs() int {
 	return m.svidStoreCache.CountX509SVIDs()
 }
 
+func (m *manager) CachedEntries() []*cache.CachedEntry {
+	return m.cache.CachedEntries()
+}
+
+func (m *manager) CachedEntry(id string) (*cache.CachedEntry, bool) {
+	return m.cache.CachedEntry(id)
+}
+
+func (m *manager) CacheSubscribers() []*cache.SubscriberSet {
+	return m.cache.Subscribers()
+}
+
+func (m *manager) RotateX509SVID(id string) error {
+	if !m.cache.MarkX509SVIDStale(id) {
+		return fmt.Errorf("no SVID can be rotated for entry %q", id)
+	}
+	m.c.Log.WithField(telemetry.RegistrationID, id).Info("Forcing X509-SVID rotation")
+	return nil
+}
+
+func (m *manager) EvictStaleJWTSVIDs() int {
+	now := m.clk.Now()
+	return m.cache.RemoveStaleJWTSVIDs(func(svid *client.JWTSVID) bool {
+		return m.c.RotationStrategy.JWTSVIDExpiresSoon(svid, now)
+	})
+}
+
 // FetchWorkloadUpdates gets the latest workload update for the selectors
 func (m *manager) FetchWorkloadUpdate(selectors []*common.Selector) *cache.WorkloadUpdate {
 	return m.cache.FetchWorkloadUpdate(selectors)
@@ -317,13 +402,18 @@ func (m *manager) FetchJWTSVID(ctx context.Context, entry *common.RegistrationEn
 func (m *manager) runSynchronizer(ctx context.Context) error {
 	syncInterval := min(m.synchronizeBackoff.NextBackOff(), defaultSyncInterval)
 	for {
+		var syncRequest chan error
 		select {
 		case <-m.clk.After(syncInterval):
+		case syncRequest = <-m.syncRequests:
 		case <-ctx.Done():
 			return nil
 		}
 
 		err := m.synchronize(ctx)
+		if syncRequest != nil {
+			syncRequest <- err
+		}
 		switch {
 		case x509util.IsUnknownAuthorityError(err):
 			m.c.Log.WithError(err).Info("Synchronize failed, non-recoverable error")
@@ -350,6 +440,24 @@ func (m *manager) runSynchronizer(ctx context.Context) error {
 	}
 }
 
+// Resync requests an immediate synchronization to the synchronizer and waits
+// for its result.
+func (m *manager) Resync(ctx context.Context) error {
+	syncRequest := make(chan error, 1)
+	select {
+	case m.syncRequests <- syncRequest:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-syncRequest:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (m *manager) runSyncSVIDs(ctx context.Context) error {
 	for {
 		select {
@@ -436,4 +544,75 @@ func (m *manager) deleteSVID() {
 	if err := m.storage.DeleteSVID(); err != nil {
 		m.c.Log.WithError(err).Error("Failed to remove SVID")
 	}
+	// The workload SVIDs cannot be trusted either without a valid agent SVID
+	if m.c.WorkloadCache != nil {
+		if err := m.c.WorkloadCache.Delete(); err != nil {
+			m.c.Log.WithError(err).Error("Failed to remove workload cache")
+		}
+	}
+}
+
+// restoreWorkloadCache populates the cache with the workload cache persisted
+// by a previous agent run, if the workload cache is enabled. Expired X509-SVIDs
+// are not restored. It returns true if any X509-SVID was restored.
+func (m *manager) restoreWorkloadCache() bool {
+	if m.c.WorkloadCache == nil {
+		return false
+	}
+
+	data, err := m.c.WorkloadCache.Load()
+	switch {
+	case errors.Is(err, storage.ErrNotCached):
+		return false
+	case err != nil:
+		m.c.Log.WithError(err).Warn("Could not load workload cache")
+		return false
+	}
+
+	now := m.clk.Now()
+	snapshot := &cache.Snapshot{
+		Bundles:   data.Bundles,
+		Entries:   data.Entries,
+		X509SVIDs: make(map[string]*cache.X509SVID, len(data.X509SVIDs)),
+	}
+	for id, svid := range data.X509SVIDs {
+		if !now.Before(svid.Chain[0].NotAfter) {
+			continue
+		}
+		snapshot.X509SVIDs[id] = &cache.X509SVID{
+			Chain:      svid.Chain,
+			PrivateKey: svid.PrivateKey,
+		}
+	}
+	m.cache.Restore(snapshot)
+
+	count := m.cache.CountX509SVIDs()
+	m.c.Log.WithField(telemetry.Count, count).Info("Restored workload cache")
+	return count > 0
+}
+
+// storeWorkloadCache persists the cache, if the workload cache is enabled.
+func (m *manager) storeWorkloadCache() {
+	if m.c.WorkloadCache == nil {
+		return
+	}
+
+	snapshot := m.cache.Snapshot()
+	data := &storage.WorkloadCacheData{
+		Bundles:   snapshot.Bundles,
+		Entries:   snapshot.Entries,
+		X509SVIDs: make(map[string]*storage.WorkloadX509SVID, len(snapshot.X509SVIDs)),
+	}
+	for id, svid := range snapshot.X509SVIDs {
+		if len(svid.Chain) == 0 || svid.PrivateKey == nil {
+			continue
+		}
+		data.X509SVIDs[id] = &storage.WorkloadX509SVID{
+			Chain:      svid.Chain,
+			PrivateKey: svid.PrivateKey,
+		}
+	}
+	if err := m.c.WorkloadCache.Store(data); err != nil {
+		m.c.Log.WithError(err).Warn("Could not store workload cache")
+	}
 }
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -1591,6 +1592,148 @@ func TestFetchJWTSVID(t *testing.T) {
 	svid, err = m.FetchJWTSVID(context.Background(), regEntriesMap["resp2"][0], audience)
 	require.Error(t, err)
 	require.Nil(t, svid)
+
+	// the expired JWT is evicted from the cache
+	require.Equal(t, 1, m.CountJWTSVIDs())
+	require.Equal(t, 1, m.EvictStaleJWTSVIDs())
+	require.Equal(t, 0, m.CountJWTSVIDs())
+}
+
+func TestResync(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		Storage:          openStorage(t, dir),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		RotationInterval: time.Hour,
+		SyncInterval:     time.Hour,
+		Clk:              clk,
+		Catalog:          cat,
+		WorkloadKeyType:  workloadkey.ECP256,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		RotationStrategy: rotationutil.NewRotationStrategy(0),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+	initialSync := m.GetLastSync()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- m.runSynchronizer(ctx)
+	}()
+
+	// Synchronization happens right away instead of waiting for the
+	// sync interval
+	clk.Add(time.Second)
+	require.NoError(t, m.Resync(context.Background()))
+	require.Equal(t, initialSync.Add(time.Second), m.GetLastSync())
+
+	cancel()
+	require.NoError(t, <-errCh)
+
+	// Resync gives up when the context is done and the synchronizer is not
+	// running
+	resyncCtx, resyncCancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer resyncCancel()
+	require.ErrorIs(t, m.Resync(resyncCtx), context.DeadlineExceeded)
+}
+
+func TestRotateX509SVID(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	c := &Config{
+		ServerAddr:       api.addr,
+		SVID:             baseSVID,
+		SVIDKey:          baseSVIDKey,
+		Log:              testLogger,
+		TrustDomain:      trustDomain,
+		Storage:          openStorage(t, dir),
+		Bundle:           api.bundle,
+		Metrics:          &telemetry.Blackhole{},
+		RotationInterval: time.Hour,
+		SyncInterval:     time.Hour,
+		Clk:              clk,
+		Catalog:          cat,
+		WorkloadKeyType:  workloadkey.ECP256,
+		SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+		RotationStrategy: rotationutil.NewRotationStrategy(0),
+	}
+
+	m := newManager(c)
+	require.NoError(t, m.Initialize(context.Background()))
+
+	entries := m.CachedEntries()
+	require.NotEmpty(t, entries)
+	entryID := entries[0].Entry.EntryId
+	require.False(t, entries[0].Stale)
+	identitiesBefore := identitiesByEntryID(m.cache.Identities())
+
+	require.EqualError(t, m.RotateX509SVID("unknown"), `no SVID can be rotated for entry "unknown"`)
+
+	// The entry is marked as stale and keeps its SVID until the next SVID sync
+	require.NoError(t, m.RotateX509SVID(entryID))
+	entry, ok := m.CachedEntry(entryID)
+	require.True(t, ok)
+	require.True(t, entry.Stale)
+	require.Equal(t, identitiesBefore[entryID], identitiesByEntryID(m.cache.Identities())[entryID])
+
+	require.NoError(t, m.syncSVIDs(context.Background()))
+	entry, ok = m.CachedEntry(entryID)
+	require.True(t, ok)
+	require.False(t, entry.Stale)
+
+	identitiesAfter := identitiesByEntryID(m.cache.Identities())
+	for id, identity := range identitiesBefore {
+		if id == entryID {
+			require.NotEqual(t, identity, identitiesAfter[id], "SVID was not rotated")
+			continue
+		}
+		require.Equal(t, identity, identitiesAfter[id], "SVID was unexpectedly rotated")
+	}
 }
 
 func TestStorableSVIDsSync(t *testing.T) {
@@ -1679,6 +1822,81 @@ func TestStorableSVIDsSync(t *testing.T) {
 	validateResponse(records, entries)
 }
 
+func TestWorkloadCacheRestoresSVIDs(t *testing.T) {
+	dir := spiretest.TempDir(t)
+	km := fakeagentkeymanager.New(t, dir)
+
+	clk := clock.NewMock(t)
+	api := newMockAPI(t, &mockAPIConfig{
+		km: km,
+		getAuthorizedEntries: func(*mockAPI, int32, *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
+			return makeGetAuthorizedEntriesResponse(t, "resp1", "resp2"), nil
+		},
+		batchNewX509SVIDEntries: func(*mockAPI, int32) []*common.RegistrationEntry {
+			return makeBatchNewX509SVIDEntries("resp1", "resp2")
+		},
+		svidTTL: 200,
+		clk:     clk,
+	})
+
+	baseSVID, baseSVIDKey := api.newSVID(joinTokenID, 1*time.Hour)
+	cat := fakeagentcatalog.New()
+	cat.SetKeyManager(km)
+
+	newConfig := func(serverAddr string) *Config {
+		workloadCache, err := storage.OpenWorkloadCache(dir, filepath.Join(dir, "workload-cache.key"))
+		require.NoError(t, err)
+		return &Config{
+			ServerAddr:       serverAddr,
+			SVID:             baseSVID,
+			SVIDKey:          baseSVIDKey,
+			Log:              testLogger,
+			TrustDomain:      trustDomain,
+			Storage:          openStorage(t, dir),
+			WorkloadCache:    workloadCache,
+			Bundle:           api.bundle,
+			Metrics:          &telemetry.Blackhole{},
+			Clk:              clk,
+			Catalog:          cat,
+			WorkloadKeyType:  workloadkey.ECP256,
+			SVIDStoreCache:   storecache.New(&storecache.Config{TrustDomain: trustDomain, Log: testLogger}),
+			RotationStrategy: rotationutil.NewRotationStrategy(0),
+		}
+	}
+
+	m := initializeNewManager(t, newConfig(api.addr))
+	expected := identitiesByEntryID(m.cache.Identities())
+	require.Len(t, expected, 3)
+
+	// The restarted agent cannot reach the server but still serves the
+	// SVIDs from the workload cache.
+	m = initializeNewManager(t, newConfig(""))
+	actual := identitiesByEntryID(m.cache.Identities())
+	require.Len(t, actual, len(expected))
+	for id, identity := range expected {
+		require.Contains(t, actual, id)
+		spiretest.RequireProtoEqual(t, identity.Entry, actual[id].Entry)
+		require.True(t, svidsEqual(identity.SVID, actual[id].SVID), "SVID for %q was not restored", id)
+		require.Equal(t, identity.PrivateKey, actual[id].PrivateKey)
+	}
+
+	sub, err := m.SubscribeToCacheChanges(context.Background(), cache.Selectors{
+		{Type: "unix", Value: "uid:1111"},
+		{Type: "spiffe_id", Value: joinTokenID.String()},
+	})
+	require.NoError(t, err)
+	defer sub.Finish()
+	u := <-sub.Updates()
+	require.Len(t, u.Identities, 3)
+	require.True(t, u.Bundle.Equal(api.bundle))
+
+	// Expired SVIDs are not restored
+	clk.Add(201 * time.Second)
+	m = newManager(newConfig(""))
+	require.Error(t, m.Initialize(context.Background()))
+	require.Zero(t, m.cache.CountX509SVIDs())
+}
+
 func makeGetAuthorizedEntriesResponse(t *testing.T, respKeys ...string) *entryv1.GetAuthorizedEntriesResponse {
 	var entries []*types.Entry
 	for _, respKey := range respKeys {
@@ -121,6 +121,8 @@ func (m *manager) synchronize(ctx context.Context) (err error) {
 
 	// Set last success sync
 	m.setLastSync()
+
+	m.storeWorkloadCache()
 	return nil
 }
 
@@ -23,6 +23,10 @@ import (
 
 const BaseTPMDir = "/dev"
 
+// DefaultEventLogPath is the path where the Linux kernel exposes the TCG event
+// log recorded by the firmware.
+const DefaultEventLogPath = "/sys/kernel/security/tpm0/binary_bios_measurements"
+
 // Functions defined here are overridden in test files to facilitate unit testing
 var (
 	AutoDetectTPMPath func(string) (string, error)                           = tpmutil.AutoDetectTPMPath
@@ -48,8 +52,9 @@ type Config struct {
 	OwnerHierarchyPassword       string `hcl:"owner_hierarchy_password"`
 	EndorsementHierarchyPassword string `hcl:"endorsement_hierarchy_password"`
 
-	DevicePath string `hcl:"tpm_device_path"`
-	Autodetect bool
+	DevicePath   string `hcl:"tpm_device_path"`
+	EventLogPath string `hcl:"event_log_path"`
+	Autodetect   bool
 }
 
 func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Config {
@@ -79,15 +84,20 @@ func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginco
 		newConfig.Autodetect = true
 	}
 
+	if newConfig.EventLogPath == "" && runtime.GOOS != "windows" {
+		newConfig.EventLogPath = DefaultEventLogPath
+	}
+
 	return newConfig
 }
 
 type config struct {
-	devicePath string
-	devIDCert  [][]byte
-	devIDPub   []byte
-	devIDPriv  []byte
-	passwords  tpmutil.TPMPasswords
+	devicePath   string
+	eventLogPath string
+	devIDCert    [][]byte
+	devIDPub     []byte
+	devIDPriv    []byte
+	passwords    tpmutil.TPMPasswords
 }
 
 type Plugin struct {
@@ -201,10 +211,20 @@ func (p *Plugin) AidAttestation(stream nodeattestorv1.NodeAttestor_AidAttestatio
 		return status.Errorf(codes.Internal, "unable to solve proof of residency challenge: %v", err)
 	}
 
+	// Solve quote challenge (report the measured boot state)
+	var quoteResp *common_devid.QuoteResponse
+	if challenges.Quote != nil {
+		quoteResp, err = quotePCRs(tpm, conf.eventLogPath, challenges.Quote)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Marshal challenges responses
 	marshalledChallengeResp, err := json.Marshal(common_devid.ChallengeResponse{
 		DevID:          devIDChallengeResp,
 		CredActivation: credActChallengeResp,
+		Quote:          quoteResp,
 	})
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to marshal challenge response: %v", err)
@@ -242,6 +262,7 @@ func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*
 	defer p.m.Unlock()
 
 	p.c.devicePath = newConfig.DevicePath
+	p.c.eventLogPath = newConfig.EventLogPath
 
 	err = p.loadDevIDFiles(newConfig)
 	if err != nil {
@@ -274,6 +295,36 @@ func (p *Plugin) getConfig() *config {
 	return p.c
 }
 
+func quotePCRs(tpm *tpmutil.Session, eventLogPath string, req *common_devid.QuoteRequest) (*common_devid.QuoteResponse, error) {
+	bank, err := common_devid.PCRBankAlgorithm(req.PCRBank)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid quote challenge: %v", err)
+	}
+
+	pcrs, quote, signature, err := tpm.QuotePCRs(bank, req.PCRs, req.Nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "unable to quote PCRs: %v", err)
+	}
+
+	resp := &common_devid.QuoteResponse{
+		Quote:     quote,
+		Signature: signature,
+		PCRs:      pcrs,
+	}
+
+	if req.EventLog {
+		if eventLogPath == "" {
+			return nil, status.Error(codes.FailedPrecondition, "server requested the event log but event_log_path is not configured")
+		}
+		resp.EventLog, err = os.ReadFile(eventLogPath)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to read event log: %v", err)
+		}
+	}
+
+	return resp, nil
+}
+
 func (p *Plugin) loadDevIDFiles(c *Config) error {
 	certs, err := util.LoadCertificates(c.DevIDCertPath)
 	if err != nil {
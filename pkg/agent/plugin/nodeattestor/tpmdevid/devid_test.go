@@ -13,6 +13,7 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/google/go-attestation/attest"
 	"github.com/google/go-tpm/legacy/tpm2"
 	"github.com/hashicorp/go-hclog"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -41,6 +42,7 @@ var (
 	devIDPrivPath                 string
 	devIDPubPath                  string
 	devIDWithoutIntermediatesPath string
+	eventLogPath                  string
 
 	tpmPasswords = tpmutil.TPMPasswords{
 		EndorsementHierarchy: "endorsement-hierarchy-pass",
@@ -93,6 +95,7 @@ func writeDevIDFiles(t *testing.T) {
 	devIDPrivPath = path.Join(dir, "devid-priv-path")
 	devIDPubPath = path.Join(dir, "devid-pub-path")
 	devIDWithoutIntermediatesPath = path.Join(dir, "devid-without-intermediates.pem")
+	eventLogPath = path.Join(dir, "binary_bios_measurements")
 
 	require.NoError(t, os.WriteFile(
 		devIDCertPath,
@@ -589,6 +592,147 @@ func TestAidAttestationSucceeds(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAidAttestationQuote(t *testing.T) {
+	tests := []struct {
+		name          string
+		quoteRequest  *common_devid.QuoteRequest
+		writeEventLog bool
+		expErr        string
+	}{
+		{
+			name: "AidAttestation succeeds quoting PCRs",
+			quoteRequest: &common_devid.QuoteRequest{
+				Nonce:   []byte("quote-nonce"),
+				PCRBank: "sha256",
+				PCRs:    []int{4, 7},
+			},
+		},
+		{
+			name: "AidAttestation succeeds quoting PCRs and sending the event log",
+			quoteRequest: &common_devid.QuoteRequest{
+				Nonce:    []byte("quote-nonce"),
+				PCRBank:  "sha1",
+				PCRs:     []int{4},
+				EventLog: true,
+			},
+			writeEventLog: true,
+		},
+		{
+			name: "AidAttestation fails if the PCR bank is not supported",
+			quoteRequest: &common_devid.QuoteRequest{
+				Nonce:   []byte("quote-nonce"),
+				PCRBank: "sm3_256",
+				PCRs:    []int{7},
+			},
+			expErr: `rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): invalid quote challenge: unsupported PCR bank "sm3_256"`,
+		},
+		{
+			name: "AidAttestation fails if PCRs cannot be quoted",
+			quoteRequest: &common_devid.QuoteRequest{
+				Nonce:   make([]byte, 1025),
+				PCRBank: "sha256",
+				PCRs:    []int{7},
+			},
+			expErr: "rpc error: code = Internal desc = nodeattestor(tpm_devid): unable to quote PCRs",
+		},
+		{
+			name: "AidAttestation fails if the event log cannot be read",
+			quoteRequest: &common_devid.QuoteRequest{
+				Nonce:    []byte("quote-nonce"),
+				PCRBank:  "sha256",
+				PCRs:     []int{4},
+				EventLog: true,
+			},
+			expErr: "rpc error: code = Internal desc = nodeattestor(tpm_devid): unable to read event log",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := setupSimulator(t)
+			require.NoError(t, sim.MeasureEvent(4, 0x80000003, []byte("kernel")))
+			if tt.writeEventLog {
+				require.NoError(t, os.WriteFile(eventLogPath, sim.EventLog(), 0600))
+			}
+
+			var session *tpmutil.Session
+			tpmdevid.NewSession = func(scfg *tpmutil.SessionConfig) (*tpmutil.Session, error) {
+				s, err := tpmutil.NewSession(scfg)
+				session = s
+				return s, err
+			}
+			t.Cleanup(func() {
+				tpmdevid.NewSession = tpmutil.NewSession
+			})
+
+			ss := streamBuilder.
+				Handle(func([]byte) ([]byte, error) {
+					// The session is started by the plugin once the payload is sent
+					akPub, err := tpm2.DecodePublic(session.GetAKPublic())
+					require.NoError(t, err)
+					ekPubBytes, err := session.GetEKPublic()
+					require.NoError(t, err)
+					ekPub, err := tpm2.DecodePublic(ekPubBytes)
+					require.NoError(t, err)
+					porChallenge, _, err := server_devid.NewCredActivationChallenge(akPub, ekPub)
+					require.NoError(t, err)
+
+					return json.Marshal(common_devid.ChallengeRequest{
+						DevID:          []byte("nonce"),
+						CredActivation: porChallenge,
+						Quote:          tt.quoteRequest,
+					})
+				}).
+				Handle(func(challengeResponse []byte) ([]byte, error) {
+					response := new(common_devid.ChallengeResponse)
+					if err := json.Unmarshal(challengeResponse, response); err != nil {
+						return nil, err
+					}
+					if response.Quote == nil {
+						return nil, errors.New("missing quote")
+					}
+
+					akPub, err := attest.ParseAKPublic(attest.TPMVersion20, session.GetAKPublic())
+					require.NoError(t, err)
+
+					bank, err := common_devid.PCRBankAlgorithm(tt.quoteRequest.PCRBank)
+					require.NoError(t, err)
+					hash, err := bank.Hash()
+					require.NoError(t, err)
+
+					var pcrs []attest.PCR
+					for index, digest := range response.Quote.PCRs {
+						pcrs = append(pcrs, attest.PCR{Index: index, Digest: digest, DigestAlg: hash})
+					}
+					require.Len(t, pcrs, len(tt.quoteRequest.PCRs))
+					err = akPub.Verify(attest.Quote{
+						Version:   attest.TPMVersion20,
+						Quote:     response.Quote.Quote,
+						Signature: response.Quote.Signature,
+					}, pcrs, tt.quoteRequest.Nonce)
+					if err != nil {
+						return nil, err
+					}
+
+					if tt.quoteRequest.EventLog {
+						require.Equal(t, sim.EventLog(), response.Quote.EventLog)
+					} else {
+						require.Empty(t, response.Quote.EventLog)
+					}
+					return nil, nil
+				}).Build()
+
+			p := loadAndConfigurePlugin(t, tpmPasswords)
+			err := p.Attest(context.Background(), ss)
+			if tt.expErr != "" {
+				require.ErrorContains(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
 func loadAndConfigurePlugin(t *testing.T, passwords tpmutil.TPMPasswords) nodeattestor.NodeAttestor {
 	devicePath := tpmDevicePath
 	if isWindows {
@@ -601,7 +745,8 @@ func loadAndConfigurePlugin(t *testing.T, passwords tpmutil.TPMPasswords) nodeat
 		devid_pub_path = %q
 		devid_password = %q
 		owner_hierarchy_password = %q
-		endorsement_hierarchy_password = %q`,
+		endorsement_hierarchy_password = %q
+		event_log_path = %q`,
 
 		devicePath,
 		devIDCertPath,
@@ -610,6 +755,7 @@ func loadAndConfigurePlugin(t *testing.T, passwords tpmutil.TPMPasswords) nodeat
 		passwords.DevIDKey,
 		passwords.OwnerHierarchy,
 		passwords.EndorsementHierarchy,
+		eventLogPath,
 	)
 
 	return loadPlugin(t, plugintest.CoreConfig(catalog.CoreConfig{
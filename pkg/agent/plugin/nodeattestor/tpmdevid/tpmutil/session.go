@@ -213,6 +213,27 @@ func (c *Session) CertifyDevIDKey() ([]byte, []byte, error) {
 	return c.ak.Certify(c.devID.Handle, c.devID.password)
 }
 
+// QuotePCRs reads the given PCRs of the given bank and quotes them using the
+// attestation key, including the nonce as qualifying data. It returns the PCR
+// values keyed by index, along with the quote and its signature.
+func (c *Session) QuotePCRs(bank tpm2.Algorithm, pcrs []int, nonce []byte) (map[int][]byte, []byte, []byte, error) {
+	values := make(map[int][]byte, len(pcrs))
+	for _, pcr := range pcrs {
+		value, err := tpm2.ReadPCR(c.rwc, pcr, bank)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to read PCR %d: %w", pcr, err)
+		}
+		values[pcr] = value
+	}
+
+	quote, signature, err := c.ak.Quote(tpm2.PCRSelection{Hash: bank, PCRs: pcrs}, nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return values, quote, signature, nil
+}
+
 // GetEKCert returns TPM endorsement certificate.
 func (c *Session) GetEKCert() ([]byte, error) {
 	ekCertAndTrailingBytes, err := tpm2.NVRead(c.rwc, EKCertificateHandleRSA)
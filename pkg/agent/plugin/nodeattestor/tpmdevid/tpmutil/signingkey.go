@@ -83,6 +83,28 @@ func (k *SigningKey) Certify(object tpmutil.Handle, objectPassword string) ([]by
 	return nil, nil, fmt.Errorf("max attempts reached while trying to certify key: %w", err)
 }
 
+// Quote calls tpm2.QuoteRaw using the current key as signer. It returns the
+// encoded attestation data and signature.
+func (k *SigningKey) Quote(sel tpm2.PCRSelection, nonce []byte) ([]byte, []byte, error) {
+	var err error
+	for i := 1; i <= maxAttempts; i++ {
+		quote, signature, err := tpm2.QuoteRaw(k.rw, k.Handle, k.password, "", nonce, sel, tpm2.AlgNull)
+		switch {
+		case err == nil:
+			return quote, signature, nil
+
+		case isRetry(err):
+			k.log.Warn(fmt.Sprintf("TPM was not able to start the command 'Quote'. Retrying: attempt (%d/%d)", i, maxAttempts))
+			time.Sleep(time.Millisecond * 500)
+
+		default:
+			return nil, nil, fmt.Errorf("tpm2.Quote failed: %w", err)
+		}
+	}
+
+	return nil, nil, fmt.Errorf("max attempts reached while trying to quote PCRs: %w", err)
+}
+
 // SRKTemplateHighRSA returns the default high range SRK template (called H-1 in the specification).
 // https://trustedcomputinggroup.org/wp-content/uploads/TCG_IWG_EKCredentialProfile_v2p3_r2_pub.pdf#page=41
 func SRKTemplateHighRSA() tpm2.Public {
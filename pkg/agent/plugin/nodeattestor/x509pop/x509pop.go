@@ -5,6 +5,7 @@ import (
 	"crypto"
 	"crypto/tls"
 	"encoding/json"
+	"os"
 	"strings"
 	"sync"
 
@@ -42,6 +43,7 @@ type Config struct {
 	PrivateKeyPath    string `hcl:"private_key_path"`
 	CertificatePath   string `hcl:"certificate_path"`
 	IntermediatesPath string `hcl:"intermediates_path"`
+	OCSPStaplePath    string `hcl:"ocsp_staple_path"`
 }
 
 func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Config {
@@ -180,8 +182,19 @@ func loadConfigData(config *Config) (*configData, error) {
 		}
 	}
 
+	// The OCSP response is read on every attestation since it is expected
+	// to be refreshed by an external process before it expires.
+	var ocspStaple []byte
+	if config.OCSPStaplePath != "" {
+		ocspStaple, err = os.ReadFile(config.OCSPStaplePath)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "unable to load OCSP staple: %v", err)
+		}
+	}
+
 	attestationPayload, err := json.Marshal(x509pop.AttestationData{
 		Certificates: certificates,
+		OCSPStaple:   ocspStaple,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "unable to marshal attestation data: %v", err)
@@ -6,6 +6,8 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -68,6 +70,38 @@ func (s *Suite) TestAttestSuccessWithIntermediates() {
 	s.testAttestSuccess(p, s.bundleWithIntermediate)
 }
 
+func (s *Suite) TestAttestSuccessWithOCSPStaple() {
+	staplePath := filepath.Join(s.T().TempDir(), "ocsp.der")
+	s.Require().NoError(os.WriteFile(staplePath, []byte("ocsp-response"), 0600))
+
+	p := s.loadPlugin(
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString(trustDomain),
+		}),
+		plugintest.Configuref(`
+			private_key_path = %q
+			certificate_path = %q
+			ocsp_staple_path = %q`, leafKeyPath, leafCertPath, staplePath),
+	)
+
+	challenge, err := x509pop.GenerateChallenge(s.leafCert)
+	s.Require().NoError(err)
+
+	err = p.Attest(context.Background(), streamBuilder.
+		ExpectThenChallenge(s.marshal(x509pop.AttestationData{
+			Certificates: s.bundleWithoutIntermediate,
+			OCSPStaple:   []byte("ocsp-response"),
+		}), s.marshal(challenge)).
+		Handle(func(challengeResponse []byte) ([]byte, error) {
+			response := new(x509pop.Response)
+			if err := json.Unmarshal(challengeResponse, response); err != nil {
+				return nil, err
+			}
+			return nil, x509pop.VerifyChallengeResponse(s.leafCert.PublicKey, challenge, response)
+		}).Build())
+	s.Require().NoError(err)
+}
+
 func (s *Suite) TestAttestFailure() {
 	// not configured
 	err := s.loadPlugin().Attest(context.Background(), streamBuilder.Build())
@@ -137,6 +171,18 @@ func (s *Suite) TestConfigure() {
 			intermediates_path = "blah"`, leafKeyPath, leafCertPath),
 	)
 	s.RequireGRPCStatusContains(err, codes.InvalidArgument, "unable to load intermediate certificates")
+
+	// cannot load OCSP staple
+	s.loadPlugin(plugintest.CaptureConfigureError(&err),
+		plugintest.CoreConfig(catalog.CoreConfig{
+			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+		}),
+		plugintest.Configuref(`
+			private_key_path = %q
+			certificate_path = %q
+			ocsp_staple_path = "blah"`, leafKeyPath, leafCertPath),
+	)
+	s.RequireGRPCStatusContains(err, codes.InvalidArgument, "unable to load OCSP staple")
 }
 
 func (s *Suite) loadPlugin(options ...plugintest.Option) nodeattestor.NodeAttestor {
@@ -0,0 +1,581 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	pluginName = "filesystem"
+
+	formatPEM    = "pem"
+	formatPKCS12 = "pkcs12"
+
+	defaultFileMode = 0600
+	defaultDirMode  = 0700
+
+	// markerFileName is the name of the file used to distinguish the
+	// directories handled by SPIRE. It contains the trust domain of the agent.
+	markerFileName = ".spire-svid"
+
+	svidFileName             = "svid.pem"
+	svidKeyFileName          = "svid_key.pem"
+	bundleFileName           = "bundle.pem"
+	federatedBundlesFileName = "federated_bundles.pem"
+	svidPKCS12FileName       = "svid.p12"
+	bundlePKCS12FileName     = "bundle.p12"
+)
+
+var (
+	// pemFiles are the files written when the PEM format is used.
+	pemFiles = []string{svidFileName, svidKeyFileName, bundleFileName, federatedBundlesFileName}
+	// pkcs12Files are the files written when the PKCS#12 format is used.
+	pkcs12Files = []string{svidPKCS12FileName, bundlePKCS12FileName}
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *FilesystemPlugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *FilesystemPlugin {
+	p := &FilesystemPlugin{}
+	p.hooks.chown = os.Chown
+
+	return p
+}
+
+type Configuration struct {
+	// Directory is the base directory where SVIDs are stored. Each SVID is
+	// stored in a subdirectory named by the `name` selector.
+	Directory string `hcl:"directory" json:"directory"`
+	// Format is the default format used to store SVIDs, either "pem" or "pkcs12".
+	Format string `hcl:"format" json:"format"`
+	// FileMode is the default permission bits of the stored files, in octal.
+	FileMode string `hcl:"file_mode" json:"file_mode"`
+	// DirMode is the permission bits of the directories created, in octal.
+	DirMode string `hcl:"dir_mode" json:"dir_mode"`
+	// UID is the default user ID that owns the stored files.
+	UID *int `hcl:"uid" json:"uid"`
+	// GID is the default group ID that owns the stored files.
+	GID *int `hcl:"gid" json:"gid"`
+	// PKCS12Password is the password used to encrypt PKCS#12 files.
+	PKCS12Password string `hcl:"pkcs12_password" json:"pkcs12_password"`
+}
+
+type storeConfig struct {
+	directory      string
+	trustDomain    string
+	pkcs12Password string
+	defaults       fileOptions
+	dirMode        fs.FileMode
+}
+
+type fileOptions struct {
+	format string
+	mode   fs.FileMode
+	uid    int
+	gid    int
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *storeConfig {
+	newConfig := &Configuration{}
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if newConfig.Directory == "" {
+		status.ReportError("directory is required")
+	}
+
+	defaults := fileOptions{
+		format: formatPEM,
+		mode:   defaultFileMode,
+		uid:    -1,
+		gid:    -1,
+	}
+	if newConfig.Format != "" {
+		format, err := parseFormat(newConfig.Format)
+		if err != nil {
+			status.ReportError(err.Error())
+		}
+		defaults.format = format
+	}
+	if newConfig.FileMode != "" {
+		mode, err := parseMode(newConfig.FileMode)
+		if err != nil {
+			status.ReportErrorf("invalid file_mode: %v", err)
+		}
+		defaults.mode = mode
+	}
+	if newConfig.UID != nil {
+		defaults.uid = *newConfig.UID
+	}
+	if newConfig.GID != nil {
+		defaults.gid = *newConfig.GID
+	}
+
+	dirMode := fs.FileMode(defaultDirMode)
+	if newConfig.DirMode != "" {
+		mode, err := parseMode(newConfig.DirMode)
+		if err != nil {
+			status.ReportErrorf("invalid dir_mode: %v", err)
+		}
+		dirMode = mode
+	}
+
+	return &storeConfig{
+		directory:      newConfig.Directory,
+		trustDomain:    coreConfig.TrustDomain.Name(),
+		pkcs12Password: newConfig.PKCS12Password,
+		defaults:       defaults,
+		dirMode:        dirMode,
+	}
+}
+
+type FilesystemPlugin struct {
+	svidstorev1.UnsafeSVIDStoreServer
+	configv1.UnsafeConfigServer
+
+	log    hclog.Logger
+	mtx    sync.Mutex
+	config *storeConfig
+
+	hooks struct {
+		chown func(name string, uid, gid int) error
+	}
+}
+
+func (p *FilesystemPlugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+// Configure configures the FilesystemPlugin.
+func (p *FilesystemPlugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = newConfig
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *FilesystemPlugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// PutX509SVID writes the specified X509-SVID to the directory selected by the
+// entry metadata
+func (p *FilesystemPlugin) PutX509SVID(_ context.Context, req *svidstorev1.PutX509SVIDRequest) (*svidstorev1.PutX509SVIDResponse, error) {
+	// Writes to the filesystem are serialized to avoid races on the same directory
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromSecretData(config, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := encodeFiles(req, opt.format, config.pkcs12Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.prepareDir(config, opt); err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedKeys(files) {
+		if err := p.writeFile(filepath.Join(opt.dir, name), files[name], opt.fileOptions); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to write %q: %v", name, err)
+		}
+	}
+
+	// Remove stale files, like the files of a different format or the
+	// federated bundles when there are no longer federated trust domains
+	for _, name := range append(append([]string{}, pemFiles...), pkcs12Files...) {
+		if _, ok := files[name]; ok {
+			continue
+		}
+		if err := removeFile(filepath.Join(opt.dir, name)); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to remove stale file %q: %v", name, err)
+		}
+	}
+
+	p.log.With("dir", opt.dir).With("format", opt.format).Debug("SVID stored")
+	return &svidstorev1.PutX509SVIDResponse{}, nil
+}
+
+// DeleteX509SVID removes the files written for an X509-SVID and its directory
+// when it is empty
+func (p *FilesystemPlugin) DeleteX509SVID(_ context.Context, req *svidstorev1.DeleteX509SVIDRequest) (*svidstorev1.DeleteX509SVIDResponse, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opt, err := optionsFromSecretData(config, req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := validateMarker(opt.dir, config.trustDomain)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		p.log.With("dir", opt.dir).Debug("SVID to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	}
+
+	for _, name := range append(append([]string{}, pemFiles...), pkcs12Files...) {
+		if err := removeFile(filepath.Join(opt.dir, name)); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to remove %q: %v", name, err)
+		}
+	}
+	if err := removeFile(filepath.Join(opt.dir, markerFileName)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to remove %q: %v", markerFileName, err)
+	}
+
+	// Only remove the directory if there are no other files in it
+	if err := os.Remove(opt.dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		p.log.With("dir", opt.dir).With("reason", err).Debug("SVID directory not removed")
+	}
+
+	p.log.With("dir", opt.dir).Debug("SVID deleted")
+	return &svidstorev1.DeleteX509SVIDResponse{}, nil
+}
+
+func (p *FilesystemPlugin) getConfig() (*storeConfig, error) {
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "plugin not configured")
+	}
+	return p.config, nil
+}
+
+// prepareDir creates the SVID directory and its marker if it does not exist,
+// or validates that the existing directory is handled by SPIRE
+func (p *FilesystemPlugin) prepareDir(config *storeConfig, opt *secretOptions) error {
+	found, err := validateMarker(opt.dir, config.trustDomain)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	if err := os.MkdirAll(opt.dir, config.dirMode); err != nil {
+		return status.Errorf(codes.Internal, "failed to create directory: %v", err)
+	}
+	entries, err := os.ReadDir(opt.dir)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to read directory: %v", err)
+	}
+	// Refuse to write into directories that were not created by SPIRE
+	if len(entries) > 0 {
+		return status.Error(codes.InvalidArgument, "directory is not managed by this SPIRE deployment")
+	}
+	if err := os.Chmod(opt.dir, config.dirMode); err != nil {
+		return status.Errorf(codes.Internal, "failed to set directory mode: %v", err)
+	}
+	if err := p.chown(opt.dir, opt.fileOptions); err != nil {
+		return status.Errorf(codes.Internal, "failed to set directory owner: %v", err)
+	}
+
+	if err := p.writeFile(filepath.Join(opt.dir, markerFileName), []byte(config.trustDomain), opt.fileOptions); err != nil {
+		return status.Errorf(codes.Internal, "failed to write %q: %v", markerFileName, err)
+	}
+	p.log.With("dir", opt.dir).Debug("SVID directory created")
+	return nil
+}
+
+// writeFile atomically writes the data to the file with the given mode and
+// ownership. The ownership is set before the file is moved in place so
+// readers never observe a file they are not able to read.
+func (p *FilesystemPlugin) writeFile(path string, data []byte, opt fileOptions) error {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	tmpPath := path + "." + hex.EncodeToString(suffix) + ".tmp"
+
+	if err := writeTmpFile(tmpPath, data, opt.mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := p.chown(tmpPath, opt); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func (p *FilesystemPlugin) chown(path string, opt fileOptions) error {
+	if opt.uid == -1 && opt.gid == -1 {
+		return nil
+	}
+	return p.hooks.chown(path, opt.uid, opt.gid)
+}
+
+func writeTmpFile(path string, data []byte, mode fs.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	// The mode is set explicitly since the one used on creation is affected
+	// by the umask
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// validateMarker validates that the directory was created by this SPIRE
+// deployment, returns false if the marker file does not exist
+func validateMarker(dir, trustDomain string) (bool, error) {
+	marker, err := os.ReadFile(filepath.Join(dir, markerFileName))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, status.Errorf(codes.Internal, "failed to read %q: %v", markerFileName, err)
+	}
+
+	if strings.TrimSpace(string(marker)) != trustDomain {
+		return false, status.Error(codes.InvalidArgument, "directory is not managed by this SPIRE deployment")
+	}
+	return true, nil
+}
+
+// encodeFiles encodes the X509-SVID in the given format, returns the
+// contents of the files to write keyed by file name
+func encodeFiles(req *svidstorev1.PutX509SVIDRequest, format, pkcs12Password string) (map[string][]byte, error) {
+	switch format {
+	case formatPKCS12:
+		return encodePKCS12Files(req, pkcs12Password)
+	default:
+		return encodePEMFiles(req)
+	}
+}
+
+func encodePEMFiles(req *svidstorev1.PutX509SVIDRequest) (map[string][]byte, error) {
+	secretData, err := svidstore.SecretFromProto(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: %v", err)
+	}
+
+	files := map[string][]byte{
+		svidFileName:    []byte(secretData.X509SVID),
+		svidKeyFileName: []byte(secretData.X509SVIDKey),
+		bundleFileName:  []byte(secretData.Bundle),
+	}
+
+	if len(secretData.FederatedBundles) > 0 {
+		var federatedBundles strings.Builder
+		for _, td := range sortedKeys(secretData.FederatedBundles) {
+			federatedBundles.WriteString(secretData.FederatedBundles[td])
+		}
+		files[federatedBundlesFileName] = []byte(federatedBundles.String())
+	}
+
+	return files, nil
+}
+
+func encodePKCS12Files(req *svidstorev1.PutX509SVIDRequest, password string) (map[string][]byte, error) {
+	certChain, err := x509util.RawCertsToCertificates(req.Svid.CertChain)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: failed to parse CertChain: %v", err)
+	}
+	if len(certChain) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "failed to parse request: CertChain is empty")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(req.Svid.PrivateKey)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: failed to parse key: %v", err)
+	}
+
+	trustStore, err := x509util.RawCertsToCertificates(req.Svid.Bundle)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: failed to parse Bundle: %v", err)
+	}
+	for _, td := range sortedKeys(req.FederatedBundles) {
+		federatedBundle, err := x509.ParseCertificates(req.FederatedBundles[td])
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: failed to parse FederatedBundle %q: %v", td, err)
+		}
+		trustStore = append(trustStore, federatedBundle...)
+	}
+
+	svidPKCS12, err := pkcs12.Modern.Encode(key, certChain[0], certChain[1:], password)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode PKCS#12 SVID: %v", err)
+	}
+
+	bundlePKCS12, err := pkcs12.Modern.EncodeTrustStore(trustStore, password)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode PKCS#12 bundle: %v", err)
+	}
+
+	return map[string][]byte{
+		svidPKCS12FileName:   svidPKCS12,
+		bundlePKCS12FileName: bundlePKCS12,
+	}, nil
+}
+
+type secretOptions struct {
+	fileOptions
+	dir string
+}
+
+func optionsFromSecretData(config *storeConfig, metadata []string) (*secretOptions, error) {
+	data, err := svidstore.ParseMetadata(metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	name, ok := data["name"]
+	if !ok || name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	// The SVID directory must be inside the configured directory
+	if !filepath.IsLocal(name) {
+		return nil, status.Errorf(codes.InvalidArgument, "name must be a relative path inside the configured directory: %q", name)
+	}
+
+	opt := &secretOptions{
+		fileOptions: config.defaults,
+		dir:         filepath.Join(config.directory, name),
+	}
+
+	if value, ok := data["format"]; ok {
+		if opt.format, err = parseFormat(value); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if value, ok := data["mode"]; ok {
+		if opt.mode, err = parseMode(value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid mode: %v", err)
+		}
+	}
+	if value, ok := data["uid"]; ok {
+		if opt.uid, err = parseID(value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid uid: %v", err)
+		}
+	}
+	if value, ok := data["gid"]; ok {
+		if opt.gid, err = parseID(value); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid gid: %v", err)
+		}
+	}
+
+	return opt, nil
+}
+
+func parseFormat(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case formatPEM:
+		return formatPEM, nil
+	case formatPKCS12:
+		return formatPKCS12, nil
+	default:
+		return "", fmt.Errorf("unsupported format %q: expected %q or %q", format, formatPEM, formatPKCS12)
+	}
+}
+
+func parseMode(mode string) (fs.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an octal number", mode)
+	}
+	if value&^uint64(fs.ModePerm) != 0 {
+		return 0, fmt.Errorf("%q contains bits other than the permission bits", mode)
+	}
+	return fs.FileMode(value), nil
+}
+
+func parseID(id string) (int, error) {
+	value, err := strconv.Atoi(id)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("%q is not a valid ID", id)
+	}
+	return value, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,457 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	td        = spiffeid.RequireTrustDomainFromString("example.org")
+	federated = spiffeid.RequireTrustDomainFromString("federated.org")
+)
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		config          string
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectConfig    *storeConfig
+	}{
+		{
+			name:   "defaults",
+			config: `directory = "/run/spire/svids"`,
+			expectConfig: &storeConfig{
+				directory:   "/run/spire/svids",
+				trustDomain: "example.org",
+				defaults: fileOptions{
+					format: formatPEM,
+					mode:   0600,
+					uid:    -1,
+					gid:    -1,
+				},
+				dirMode: 0700,
+			},
+		},
+		{
+			name: "custom configuration",
+			config: `
+				directory = "/run/spire/svids"
+				format = "PKCS12"
+				file_mode = "0640"
+				dir_mode = "0750"
+				uid = 1000
+				gid = 2000
+				pkcs12_password = "password"`,
+			expectConfig: &storeConfig{
+				directory:      "/run/spire/svids",
+				trustDomain:    "example.org",
+				pkcs12Password: "password",
+				defaults: fileOptions{
+					format: formatPKCS12,
+					mode:   0640,
+					uid:    1000,
+					gid:    2000,
+				},
+				dirMode: 0750,
+			},
+		},
+		{
+			name:            "no directory",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "directory is required",
+		},
+		{
+			name: "unsupported format",
+			config: `
+				directory = "/run/spire/svids"
+				format = "jks"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `unsupported format "jks": expected "pem" or "pkcs12"`,
+		},
+		{
+			name: "invalid file mode",
+			config: `
+				directory = "/run/spire/svids"
+				file_mode = "rw-r--r--"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid file_mode: "rw-r--r--" is not an octal number`,
+		},
+		{
+			name: "invalid dir mode",
+			config: `
+				directory = "/run/spire/svids"
+				dir_mode = "1777"`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: `invalid dir_mode: "1777" contains bits other than the permission bits`,
+		},
+		{
+			name:            "malformed configuration",
+			config:          "{ not a config }",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to decode configuration: ",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			p := New()
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(tt.config),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			require.Equal(t, tt.expectConfig, p.config)
+		})
+	}
+}
+
+func TestPutX509SVID(t *testing.T) {
+	ca := testca.New(t, td)
+	federatedCA := testca.New(t, federated)
+	svid := ca.ChildCA().CreateX509SVID(spiffeid.RequireFromPath(td, "/db"))
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name             string
+		metadata         []string
+		config           string
+		federatedBundles bool
+		setup            func(t *testing.T, dir string)
+		expectCode       codes.Code
+		expectMsg        string
+		expectPEM        bool
+		expectPKCS12     bool
+		expectMode       fs.FileMode
+		expectChown      []string
+	}{
+		{
+			name:             "PEM files",
+			metadata:         []string{"name:db"},
+			federatedBundles: true,
+			expectPEM:        true,
+			expectMode:       0600,
+		},
+		{
+			name:         "PKCS#12 files",
+			metadata:     []string{"name:db", "format:pkcs12"},
+			expectPKCS12: true,
+			expectMode:   0600,
+		},
+		{
+			name:             "PKCS#12 files by default",
+			metadata:         []string{"name:db"},
+			config:           `format = "pkcs12"`,
+			federatedBundles: true,
+			expectPKCS12:     true,
+			expectMode:       0600,
+		},
+		{
+			name:       "mode and ownership from configuration",
+			metadata:   []string{"name:db"},
+			config:     "file_mode = \"0640\"\nuid = 1000\ngid = 2000",
+			expectPEM:  true,
+			expectMode: 0640,
+			expectChown: []string{
+				"db 1000:2000",
+				".spire-svid 1000:2000",
+				"bundle.pem 1000:2000",
+				"svid.pem 1000:2000",
+				"svid_key.pem 1000:2000",
+			},
+		},
+		{
+			name:       "mode and ownership from selectors",
+			metadata:   []string{"name:db", "mode:0644", "uid:1001", "gid:2001"},
+			config:     "file_mode = \"0640\"\nuid = 1000\ngid = 2000",
+			expectPEM:  true,
+			expectMode: 0644,
+			expectChown: []string{
+				"db 1001:2001",
+				".spire-svid 1001:2001",
+				"bundle.pem 1001:2001",
+				"svid.pem 1001:2001",
+				"svid_key.pem 1001:2001",
+			},
+		},
+		{
+			name:     "update files in a different format",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", markerFileName), []byte("example.org"), 0600))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", svidPKCS12FileName), []byte("old"), 0600))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", bundlePKCS12FileName), []byte("old"), 0600))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", federatedBundlesFileName), []byte("old"), 0600))
+			},
+			expectPEM:  true,
+			expectMode: 0600,
+		},
+		{
+			name:     "directory not managed by SPIRE",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", "config.yaml"), []byte("config"), 0600))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(filesystem): directory is not managed by this SPIRE deployment",
+		},
+		{
+			name:     "directory managed by another trust domain",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(dir, "db"), 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "db", markerFileName), []byte("other.org"), 0600))
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(filesystem): directory is not managed by this SPIRE deployment",
+		},
+		{
+			name:       "no name",
+			metadata:   []string{"format:pem"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(filesystem): name is required",
+		},
+		{
+			name:       "name outside of the directory",
+			metadata:   []string{"name:../db"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `svidstore(filesystem): name must be a relative path inside the configured directory: "../db"`,
+		},
+		{
+			name:       "unsupported format",
+			metadata:   []string{"name:db", "format:der"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `svidstore(filesystem): unsupported format "der": expected "pem" or "pkcs12"`,
+		},
+		{
+			name:       "invalid mode",
+			metadata:   []string{"name:db", "mode:777a"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `svidstore(filesystem): invalid mode: "777a" is not an octal number`,
+		},
+		{
+			name:       "invalid uid",
+			metadata:   []string{"name:db", "uid:-3"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `svidstore(filesystem): invalid uid: "-3" is not a valid ID`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			var chowns []string
+			p := New()
+			p.hooks.chown = func(name string, uid, gid int) error {
+				// Files are owned before being moved in place, strip the temporary suffix
+				name = filepath.Base(name)
+				if tmpName, ok := strings.CutSuffix(name, ".tmp"); ok {
+					name = strings.TrimSuffix(tmpName, filepath.Ext(tmpName))
+				}
+				chowns = append(chowns, fmt.Sprintf("%s %d:%d", name, uid, gid))
+				return nil
+			}
+			ss := new(svidstore.V1)
+			plugintest.Load(t, builtin(p), ss,
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(`directory = "`+filepath.ToSlash(dir)+`"`+"\n"+tt.config),
+			)
+
+			req := &svidstore.X509SVID{
+				SVID: &svidstore.SVID{
+					SPIFFEID:   svid.ID,
+					CertChain:  svid.Certificates,
+					PrivateKey: svid.PrivateKey,
+					Bundle:     ca.X509Authorities(),
+					ExpiresAt:  svid.Certificates[0].NotAfter,
+				},
+				Metadata: tt.metadata,
+			}
+			if tt.federatedBundles {
+				req.FederatedBundles = map[string][]*x509.Certificate{
+					federated.IDString(): federatedCA.X509Authorities(),
+				}
+			}
+
+			err := ss.PutX509SVID(context.Background(), req)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			if tt.expectCode != codes.OK {
+				return
+			}
+
+			svidDir := filepath.Join(dir, "db")
+			marker, err := os.ReadFile(filepath.Join(svidDir, markerFileName))
+			require.NoError(t, err)
+			require.Equal(t, "example.org", string(marker))
+
+			var expectFiles []string
+			if tt.expectPEM {
+				expectFiles = append(expectFiles, bundleFileName, svidFileName, svidKeyFileName)
+				requireFile(t, svidDir, svidFileName, pemutil.EncodeCertificates(svid.Certificates), tt.expectMode)
+				requireFile(t, svidDir, svidKeyFileName, keyPEM, tt.expectMode)
+				requireFile(t, svidDir, bundleFileName, pemutil.EncodeCertificates(ca.X509Authorities()), tt.expectMode)
+				if tt.federatedBundles {
+					expectFiles = append(expectFiles, federatedBundlesFileName)
+					requireFile(t, svidDir, federatedBundlesFileName, pemutil.EncodeCertificates(federatedCA.X509Authorities()), tt.expectMode)
+				}
+			}
+			if tt.expectPKCS12 {
+				expectFiles = append(expectFiles, bundlePKCS12FileName, svidPKCS12FileName)
+				svidPKCS12 := requireFile(t, svidDir, svidPKCS12FileName, nil, tt.expectMode)
+				key, cert, caCerts, err := pkcs12.DecodeChain(svidPKCS12, "")
+				require.NoError(t, err)
+				require.Equal(t, svid.PrivateKey, key)
+				require.Equal(t, svid.Certificates[0], cert)
+				require.Equal(t, svid.Certificates[1:], caCerts)
+
+				bundlePKCS12 := requireFile(t, svidDir, bundlePKCS12FileName, nil, tt.expectMode)
+				trustStore, err := pkcs12.DecodeTrustStore(bundlePKCS12, "")
+				require.NoError(t, err)
+				expectTrustStore := ca.X509Authorities()
+				if tt.federatedBundles {
+					expectTrustStore = append(expectTrustStore, federatedCA.X509Authorities()...)
+				}
+				require.Equal(t, expectTrustStore, trustStore)
+			}
+
+			entries, err := os.ReadDir(svidDir)
+			require.NoError(t, err)
+			var files []string
+			for _, entry := range entries {
+				if entry.Name() != markerFileName {
+					files = append(files, entry.Name())
+				}
+			}
+			require.ElementsMatch(t, expectFiles, files)
+			require.Equal(t, tt.expectChown, chowns)
+		})
+	}
+}
+
+func TestDeleteX509SVID(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		metadata        []string
+		setup           func(t *testing.T, dir string)
+		expectCode      codes.Code
+		expectMsg       string
+		expectRemaining []string
+		expectDirGone   bool
+	}{
+		{
+			name:     "delete SVID",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				writeSVIDDir(t, dir, "example.org", svidFileName, svidKeyFileName, bundleFileName)
+			},
+			expectDirGone: true,
+		},
+		{
+			name:     "delete SVID and keep other files",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				writeSVIDDir(t, dir, "example.org", svidPKCS12FileName, bundlePKCS12FileName, "app.conf")
+			},
+			expectRemaining: []string{"app.conf"},
+		},
+		{
+			name:          "SVID not found",
+			metadata:      []string{"name:db"},
+			expectDirGone: true,
+		},
+		{
+			name:     "directory not managed by SPIRE",
+			metadata: []string{"name:db"},
+			setup: func(t *testing.T, dir string) {
+				writeSVIDDir(t, dir, "other.org", svidFileName)
+			},
+			expectCode:      codes.InvalidArgument,
+			expectMsg:       "svidstore(filesystem): directory is not managed by this SPIRE deployment",
+			expectRemaining: []string{markerFileName, svidFileName},
+		},
+		{
+			name:          "no name",
+			metadata:      []string{"format:pem"},
+			expectCode:    codes.InvalidArgument,
+			expectMsg:     "svidstore(filesystem): name is required",
+			expectDirGone: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, dir)
+			}
+
+			ss := new(svidstore.V1)
+			plugintest.Load(t, BuiltIn(), ss,
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(`directory = "`+filepath.ToSlash(dir)+`"`),
+			)
+
+			err := ss.DeleteX509SVID(context.Background(), tt.metadata)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+
+			entries, err := os.ReadDir(filepath.Join(dir, "db"))
+			if tt.expectDirGone {
+				require.ErrorIs(t, err, fs.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			require.ElementsMatch(t, tt.expectRemaining, files)
+		})
+	}
+}
+
+func writeSVIDDir(t *testing.T, dir, trustDomain string, files ...string) {
+	svidDir := filepath.Join(dir, "db")
+	require.NoError(t, os.MkdirAll(svidDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(svidDir, markerFileName), []byte(trustDomain), 0600))
+	for _, file := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(svidDir, file), []byte("data"), 0600))
+	}
+}
+
+func requireFile(t *testing.T, dir, name string, expectData []byte, expectMode fs.FileMode) []byte {
+	path := filepath.Join(dir, name)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	if expectData != nil {
+		require.Equal(t, string(expectData), string(data))
+	}
+
+	// Permission bits are not supported on Windows
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.Equal(t, expectMode, info.Mode().Perm())
+	}
+	return data
+}
@@ -0,0 +1,107 @@
+package vaultkv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	vapi "github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultAppRoleMountPoint = "approle"
+	defaultK8sMountPoint     = "kubernetes"
+
+	// tokenRenewalMargin is how long before the expiration of the client
+	// token the plugin logs in again.
+	tokenRenewalMargin = time.Minute
+)
+
+// vaultClient is a Vault client authenticated with the configured method.
+type vaultClient struct {
+	client *vapi.Client
+	// expiresAt is the expiration time of the client token. It is zero if
+	// the token does not expire or it was provided by configuration.
+	expiresAt time.Time
+}
+
+func newVaultClient(ctx context.Context, config *Configuration, now time.Time) (*vaultClient, error) {
+	vaultConfig := vapi.DefaultConfig()
+	if vaultConfig.Error != nil {
+		return nil, vaultConfig.Error
+	}
+	vaultConfig.Address = config.VaultAddr
+	if err := vaultConfig.ConfigureTLS(&vapi.TLSConfig{
+		CACert:   config.CACertPath,
+		Insecure: config.InsecureSkipVerify,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
+	client, err := vapi.NewClient(vaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Ignore any token picked from the environment by the Vault client,
+	// the token is set according to the configured authentication method.
+	client.ClearToken()
+	if config.Namespace != "" {
+		client.SetNamespace(config.Namespace)
+	}
+
+	vc := &vaultClient{client: client}
+	switch {
+	case config.TokenAuth != nil:
+		client.SetToken(config.TokenAuth.Token)
+		return vc, nil
+	case config.AppRoleAuth != nil:
+		return vc, vc.login(ctx, config.AppRoleAuth.AppRoleMountPoint, defaultAppRoleMountPoint, map[string]any{
+			"role_id":   config.AppRoleAuth.RoleID,
+			"secret_id": config.AppRoleAuth.SecretID,
+		}, now)
+	case config.K8sAuth != nil:
+		token, err := os.ReadFile(config.K8sAuth.TokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read k8s service account token: %w", err)
+		}
+		return vc, vc.login(ctx, config.K8sAuth.K8sAuthMountPoint, defaultK8sMountPoint, map[string]any{
+			"role": config.K8sAuth.K8sAuthRoleName,
+			"jwt":  string(token),
+		}, now)
+	default:
+		// Purely defensive, the configuration is validated before
+		return nil, errors.New("no authentication method configured")
+	}
+}
+
+func (c *vaultClient) login(ctx context.Context, mountPoint, defaultMountPoint string, data map[string]any, now time.Time) error {
+	if mountPoint == "" {
+		mountPoint = defaultMountPoint
+	}
+
+	secret, err := c.client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", mountPoint), data)
+	if err != nil {
+		return fmt.Errorf("failed to login to Vault: %w", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return errors.New("failed to login to Vault: no client token in response")
+	}
+
+	c.client.SetToken(secret.Auth.ClientToken)
+	if secret.Auth.LeaseDuration > 0 {
+		c.expiresAt = now.Add(time.Duration(secret.Auth.LeaseDuration) * time.Second)
+	}
+	return nil
+}
+
+// expired returns true if the client token is about to expire and a new login
+// is required.
+func (c *vaultClient) expired(now time.Time) bool {
+	return !c.expiresAt.IsZero() && now.Add(tokenRenewalMargin).After(c.expiresAt)
+}
+
+func (c *vaultClient) kv(mountPoint string) *vapi.KVv2 {
+	return c.client.KVv2(mountPoint)
+}
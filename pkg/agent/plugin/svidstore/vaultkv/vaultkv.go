@@ -0,0 +1,392 @@
+package vaultkv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl"
+	vapi "github.com/hashicorp/vault/api"
+	svidstorev1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/svidstore/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	pluginName = "vault_kv"
+
+	defaultKVMountPoint = "secret"
+
+	// spireSVIDMetadataKey is the custom metadata key used to distinguish the
+	// secrets handled by SPIRE. Its value is the trust domain of the agent.
+	spireSVIDMetadataKey = "spire-svid"
+
+	envVaultAddr      = "VAULT_ADDR"
+	envVaultToken     = "VAULT_TOKEN"
+	envVaultCACert    = "VAULT_CACERT"
+	envVaultNamespace = "VAULT_NAMESPACE"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtin(New())
+}
+
+func builtin(p *VaultKVPlugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		svidstorev1.SVIDStorePluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+func New() *VaultKVPlugin {
+	p := &VaultKVPlugin{
+		clock: clock.New(),
+	}
+	p.hooks.lookupEnv = os.LookupEnv
+
+	return p
+}
+
+type Configuration struct {
+	// A URL of Vault server. (e.g., https://vault.example.com:8443/)
+	VaultAddr string `hcl:"vault_addr" json:"vault_addr"`
+	// Name of the mount point where the KV v2 secret engine is mounted.
+	KVMountPoint string `hcl:"kv_mount_point" json:"kv_mount_point"`
+	// Configuration for the Token authentication method
+	TokenAuth *TokenAuthConfig `hcl:"token_auth" json:"token_auth,omitempty"`
+	// Configuration for the AppRole authentication method
+	AppRoleAuth *AppRoleAuthConfig `hcl:"approle_auth" json:"approle_auth,omitempty"`
+	// Configuration for the Kubernetes authentication method
+	K8sAuth *K8sAuthConfig `hcl:"k8s_auth" json:"k8s_auth,omitempty"`
+	// Path to a CA certificate file that the client verifies the server certificate.
+	// Only PEM format is supported.
+	CACertPath string `hcl:"ca_cert_path" json:"ca_cert_path"`
+	// If true, vault client accepts any server certificates.
+	// It should be used only test environment so on.
+	InsecureSkipVerify bool `hcl:"insecure_skip_verify" json:"insecure_skip_verify"`
+	// Name of the Vault namespace
+	Namespace string `hcl:"namespace" json:"namespace"`
+}
+
+// TokenAuthConfig represents parameters for token auth method
+type TokenAuthConfig struct {
+	// Token string to set into "X-Vault-Token" header
+	Token string `hcl:"token" json:"token"`
+}
+
+// AppRoleAuthConfig represents parameters for AppRole auth method.
+type AppRoleAuthConfig struct {
+	// Name of the mount point where AppRole auth method is mounted. (e.g., /auth/<mount_point>/login)
+	// If the value is empty, use default mount point (/auth/approle)
+	AppRoleMountPoint string `hcl:"approle_auth_mount_point" json:"approle_auth_mount_point"`
+	// An identifier that selects the AppRole
+	RoleID string `hcl:"approle_id" json:"approle_id"`
+	// A credential that is required for login.
+	SecretID string `hcl:"approle_secret_id" json:"approle_secret_id"`
+}
+
+// K8sAuthConfig represents parameters for Kubernetes auth method.
+type K8sAuthConfig struct {
+	// Name of the mount point where Kubernetes auth method is mounted. (e.g., /auth/<mount_point>/login)
+	// If the value is empty, use default mount point (/auth/kubernetes)
+	K8sAuthMountPoint string `hcl:"k8s_auth_mount_point" json:"k8s_auth_mount_point"`
+	// Name of the Vault role.
+	// The plugin authenticates against the named role.
+	K8sAuthRoleName string `hcl:"k8s_auth_role_name" json:"k8s_auth_role_name"`
+	// Path to the Kubernetes Service Account Token to use authentication with the Vault.
+	TokenPath string `hcl:"token_path" json:"token_path"`
+}
+
+func (p *VaultKVPlugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *Configuration {
+	newConfig := &Configuration{}
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	newConfig.VaultAddr = p.getEnvOrDefault(envVaultAddr, newConfig.VaultAddr)
+	newConfig.CACertPath = p.getEnvOrDefault(envVaultCACert, newConfig.CACertPath)
+	newConfig.Namespace = p.getEnvOrDefault(envVaultNamespace, newConfig.Namespace)
+	if newConfig.KVMountPoint == "" {
+		newConfig.KVMountPoint = defaultKVMountPoint
+	}
+
+	if newConfig.VaultAddr == "" {
+		status.ReportError("vault_addr is required")
+	}
+
+	authMethods := 0
+	if newConfig.TokenAuth != nil {
+		authMethods++
+		newConfig.TokenAuth.Token = p.getEnvOrDefault(envVaultToken, newConfig.TokenAuth.Token)
+		if newConfig.TokenAuth.Token == "" {
+			status.ReportError("token is required")
+		}
+	}
+	if newConfig.AppRoleAuth != nil {
+		authMethods++
+		if newConfig.AppRoleAuth.RoleID == "" {
+			status.ReportError("approle_id is required")
+		}
+		if newConfig.AppRoleAuth.SecretID == "" {
+			status.ReportError("approle_secret_id is required")
+		}
+	}
+	if newConfig.K8sAuth != nil {
+		authMethods++
+		if newConfig.K8sAuth.K8sAuthRoleName == "" {
+			status.ReportError("k8s_auth_role_name is required")
+		}
+		if newConfig.K8sAuth.TokenPath == "" {
+			status.ReportError("token_path is required")
+		}
+	}
+	switch authMethods {
+	case 0:
+		status.ReportError("one of the authentication methods 'token_auth', 'approle_auth' or 'k8s_auth' must be configured")
+	case 1:
+	default:
+		status.ReportError("only one authentication method can be configured")
+	}
+
+	return newConfig
+}
+
+type VaultKVPlugin struct {
+	svidstorev1.UnsafeSVIDStoreServer
+	configv1.UnsafeConfigServer
+
+	log         hclog.Logger
+	clock       clock.Clock
+	mtx         sync.Mutex
+	config      *Configuration
+	trustDomain string
+	vc          *vaultClient
+
+	hooks struct {
+		lookupEnv func(string) (string, bool)
+	}
+}
+
+func (p *VaultKVPlugin) SetLogger(log hclog.Logger) {
+	p.log = log
+}
+
+// Configure configures the VaultKVPlugin.
+func (p *VaultKVPlugin) Configure(ctx context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, p.buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	vc, err := newVaultClient(ctx, newConfig, p.clock.Now())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create vault client: %v", err)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.config = newConfig
+	p.trustDomain = req.CoreConfiguration.TrustDomain
+	p.vc = vc
+
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *VaultKVPlugin) Validate(ctx context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, p.buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, nil
+}
+
+// PutX509SVID puts the specified X509-SVID in the configured Vault KV secret engine
+func (p *VaultKVPlugin) PutX509SVID(ctx context.Context, req *svidstorev1.PutX509SVIDRequest) (*svidstorev1.PutX509SVIDResponse, error) {
+	opt, err := p.optionsFromSecretData(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	secretData, err := svidstore.SecretFromProto(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to parse request: %v", err)
+	}
+
+	data, err := secretDataToMap(secretData)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal payload: %v", err)
+	}
+
+	kv, trustDomain, err := p.getKV(ctx, opt.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := getSecretMetadata(ctx, kv, opt.path, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret not found, create its metadata before the data so the secret is
+	// never stored without the label that identifies it as managed by SPIRE
+	if !found {
+		if err := kv.PutMetadata(ctx, opt.path, vapi.KVMetadataPutInput{
+			CustomMetadata: map[string]any{
+				spireSVIDMetadataKey: trustDomain,
+			},
+		}); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create secret: %v", err)
+		}
+		p.log.With("path", opt.path).With("mount_point", opt.mountPoint).Debug("Secret created")
+	}
+
+	secret, err := kv.Put(ctx, opt.path, data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to put secret data: %v", err)
+	}
+
+	logger := p.log.With("path", opt.path).With("mount_point", opt.mountPoint)
+	if secret.VersionMetadata != nil {
+		logger = logger.With("version", secret.VersionMetadata.Version)
+	}
+	logger.Debug("Secret data updated")
+
+	return &svidstorev1.PutX509SVIDResponse{}, nil
+}
+
+// DeleteX509SVID deletes all the versions and the metadata of a secret in the
+// configured Vault KV secret engine
+func (p *VaultKVPlugin) DeleteX509SVID(ctx context.Context, req *svidstorev1.DeleteX509SVIDRequest) (*svidstorev1.DeleteX509SVIDResponse, error) {
+	opt, err := p.optionsFromSecretData(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, trustDomain, err := p.getKV(ctx, opt.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := getSecretMetadata(ctx, kv, opt.path, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		p.log.With("path", opt.path).With("mount_point", opt.mountPoint).Debug("Secret to delete not found")
+		return &svidstorev1.DeleteX509SVIDResponse{}, nil
+	}
+
+	if err := kv.DeleteMetadata(ctx, opt.path); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete secret: %v", err)
+	}
+
+	p.log.With("path", opt.path).With("mount_point", opt.mountPoint).Debug("Secret deleted")
+	return &svidstorev1.DeleteX509SVIDResponse{}, nil
+}
+
+// getKV returns the KV v2 client for the given mount point, logging in to
+// Vault again if the current token is about to expire.
+func (p *VaultKVPlugin) getKV(ctx context.Context, mountPoint string) (*vapi.KVv2, string, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.vc == nil {
+		return nil, "", status.Error(codes.FailedPrecondition, "plugin not configured")
+	}
+
+	now := p.clock.Now()
+	if p.vc.expired(now) {
+		vc, err := newVaultClient(ctx, p.config, now)
+		if err != nil {
+			return nil, "", status.Errorf(codes.Internal, "failed to renew vault client: %v", err)
+		}
+		p.vc = vc
+		p.log.Debug("Vault client token renewed")
+	}
+
+	return p.vc.kv(mountPoint), p.trustDomain, nil
+}
+
+func (p *VaultKVPlugin) getEnvOrDefault(envKey, fallback string) string {
+	if value, ok := p.hooks.lookupEnv(envKey); ok {
+		return value
+	}
+	return fallback
+}
+
+// getSecretMetadata gets the secret metadata from Vault and validates that
+// the secret is managed by SPIRE, returns false if the secret is not found
+func getSecretMetadata(ctx context.Context, kv *vapi.KVv2, path, trustDomain string) (bool, error) {
+	metadata, err := kv.GetMetadata(ctx, path)
+	switch {
+	case errors.Is(err, vapi.ErrSecretNotFound):
+		return false, nil
+	case err != nil:
+		return false, status.Errorf(codes.Internal, "failed to get secret metadata: %v", err)
+	}
+
+	if value, ok := metadata.CustomMetadata[spireSVIDMetadataKey]; !ok || value != trustDomain {
+		return false, status.Error(codes.InvalidArgument, "secret is not managed by this SPIRE deployment")
+	}
+
+	return true, nil
+}
+
+type secretOptions struct {
+	path       string
+	mountPoint string
+}
+
+func (p *VaultKVPlugin) optionsFromSecretData(metadata []string) (*secretOptions, error) {
+	data, err := svidstore.ParseMetadata(metadata)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %v", err)
+	}
+
+	path := strings.Trim(data["path"], "/")
+	if path == "" {
+		return nil, status.Error(codes.InvalidArgument, "path is required")
+	}
+
+	mountPoint := data["mount"]
+	if mountPoint == "" {
+		p.mtx.Lock()
+		if p.config != nil {
+			mountPoint = p.config.KVMountPoint
+		}
+		p.mtx.Unlock()
+	}
+
+	return &secretOptions{
+		path:       path,
+		mountPoint: mountPoint,
+	}, nil
+}
+
+// secretDataToMap converts the secret data into the map of key-value pairs
+// stored in the KV secret engine
+func secretDataToMap(secretData *svidstore.Data) (map[string]any, error) {
+	secretBinary, err := json.Marshal(secretData)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any)
+	if err := json.Unmarshal(secretBinary, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,525 @@
+package vaultkv
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/plugin/svidstore"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	td        = spiffeid.RequireTrustDomainFromString("example.org")
+	federated = spiffeid.RequireTrustDomainFromString("federated.org")
+)
+
+func TestConfigure(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("k8s-sa-token"), 0600))
+
+	for _, tt := range []struct {
+		name            string
+		envs            map[string]string
+		config          string
+		expectCode      codes.Code
+		expectMsgPrefix string
+		expectToken     string
+		expectLogins    []string
+	}{
+		{
+			name:        "token auth",
+			config:      `token_auth { token = "config-token" }`,
+			expectToken: "config-token",
+		},
+		{
+			name:        "token auth from environment",
+			envs:        map[string]string{envVaultToken: "env-token"},
+			config:      `token_auth {}`,
+			expectToken: "env-token",
+		},
+		{
+			name:         "approle auth",
+			config:       `approle_auth { approle_id = "role-id" approle_secret_id = "secret-id" }`,
+			expectToken:  "approle-token",
+			expectLogins: []string{"approle"},
+		},
+		{
+			name:         "approle auth on custom mount point",
+			config:       `approle_auth { approle_auth_mount_point = "other-approle" approle_id = "role-id" approle_secret_id = "secret-id" }`,
+			expectToken:  "approle-token",
+			expectLogins: []string{"other-approle"},
+		},
+		{
+			name:            "approle auth fails",
+			config:          `approle_auth { approle_id = "role-id" approle_secret_id = "wrong-secret-id" }`,
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "failed to create vault client: failed to login to Vault: ",
+			expectLogins:    []string{"approle"},
+		},
+		{
+			name:         "k8s auth",
+			config:       `k8s_auth { k8s_auth_role_name = "role" token_path = "` + filepath.ToSlash(tokenPath) + `" }`,
+			expectToken:  "k8s-token",
+			expectLogins: []string{"kubernetes"},
+		},
+		{
+			name:            "k8s auth token not found",
+			config:          `k8s_auth { k8s_auth_role_name = "role" token_path = "` + filepath.ToSlash(filepath.Join(t.TempDir(), "missing")) + `" }`,
+			expectCode:      codes.Internal,
+			expectMsgPrefix: "failed to create vault client: failed to read k8s service account token: ",
+		},
+		{
+			name:            "k8s auth without role",
+			config:          `k8s_auth { token_path = "` + filepath.ToSlash(tokenPath) + `" }`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "k8s_auth_role_name is required",
+		},
+		{
+			name:            "approle auth without secret id",
+			config:          `approle_auth { approle_id = "role-id" }`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "approle_secret_id is required",
+		},
+		{
+			name:            "no authentication method",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "one of the authentication methods 'token_auth', 'approle_auth' or 'k8s_auth' must be configured",
+		},
+		{
+			name: "multiple authentication methods",
+			config: `token_auth { token = "config-token" }
+			approle_auth { approle_id = "role-id" approle_secret_id = "secret-id" }`,
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "only one authentication method can be configured",
+		},
+		{
+			name:            "malformed configuration",
+			config:          "{ not a config }",
+			expectCode:      codes.InvalidArgument,
+			expectMsgPrefix: "unable to decode configuration: ",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newFakeVault(t)
+
+			config := tt.config
+			if !strings.HasPrefix(config, "{") {
+				config = `vault_addr = "` + vault.addr + `"` + "\n" + config
+			}
+
+			var err error
+			p := New()
+			p.hooks.lookupEnv = func(key string) (string, bool) {
+				value, ok := tt.envs[key]
+				return value, ok
+			}
+			plugintest.Load(t, builtin(p), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(config),
+			)
+			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsgPrefix)
+			require.Equal(t, tt.expectLogins, vault.logins)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, p.vc)
+				return
+			}
+			require.Equal(t, tt.expectToken, p.vc.client.Token())
+			require.Equal(t, defaultKVMountPoint, p.config.KVMountPoint)
+		})
+	}
+
+	t.Run("vault address is required", func(t *testing.T) {
+		var err error
+		p := New()
+		p.hooks.lookupEnv = func(string) (string, bool) { return "", false }
+		plugintest.Load(t, builtin(p), nil,
+			plugintest.CaptureConfigureError(&err),
+			plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+			plugintest.Configure(`token_auth { token = "config-token" }`),
+		)
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "vault_addr is required")
+	})
+}
+
+func TestPutX509SVID(t *testing.T) {
+	req, expectData := newPutRequest(t, "path:workloads/db")
+
+	for _, tt := range []struct {
+		name           string
+		metadata       []string
+		setup          func(vault *fakeVault)
+		expectCode     codes.Code
+		expectMsg      string
+		expectPath     string
+		expectVersions int
+	}{
+		{
+			name:           "create secret",
+			expectPath:     "secret/workloads/db",
+			expectVersions: 1,
+		},
+		{
+			name: "update secret",
+			setup: func(vault *fakeVault) {
+				vault.putSecret("secret/workloads/db", td.Name(), map[string]any{"spiffeID": "spiffe://example.org/old"})
+			},
+			expectPath:     "secret/workloads/db",
+			expectVersions: 2,
+		},
+		{
+			name:           "secret on custom mount",
+			metadata:       []string{"path:/workloads/db/", "mount:kv"},
+			expectPath:     "kv/workloads/db",
+			expectVersions: 1,
+		},
+		{
+			name: "secret not managed by SPIRE",
+			setup: func(vault *fakeVault) {
+				vault.putSecret("secret/workloads/db", "", map[string]any{"password": "secret"})
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name: "secret managed by another trust domain",
+			setup: func(vault *fakeVault) {
+				vault.putSecret("secret/workloads/db", "other.org", map[string]any{})
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+		},
+		{
+			name:       "no path",
+			metadata:   []string{"mount:kv"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(vault_kv): path is required",
+		},
+		{
+			name:       "invalid metadata",
+			metadata:   []string{"path"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `svidstore(vault_kv): invalid metadata: metadata does not contain a colon: "path"`,
+		},
+		{
+			name: "vault fails",
+			setup: func(vault *fakeVault) {
+				vault.failRequests = true
+			},
+			expectCode: codes.Internal,
+			expectMsg:  "svidstore(vault_kv): failed to get secret metadata: ",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newFakeVault(t)
+			if tt.setup != nil {
+				tt.setup(vault)
+			}
+			ss, _ := loadPlugin(t, vault, `token_auth { token = "config-token" }`)
+
+			putReq := *req
+			if tt.metadata != nil {
+				putReq.Metadata = tt.metadata
+			}
+			err := ss.PutX509SVID(context.Background(), &putReq)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			secret := vault.secrets[tt.expectPath]
+			require.NotNil(t, secret)
+			require.Equal(t, map[string]any{spireSVIDMetadataKey: td.Name()}, secret.customMetadata)
+			require.Equal(t, expectData, secret.data)
+			require.Equal(t, tt.expectVersions, secret.versions)
+		})
+	}
+}
+
+func TestDeleteX509SVID(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		metadata     []string
+		setup        func(vault *fakeVault)
+		expectCode   codes.Code
+		expectMsg    string
+		expectSecret bool
+	}{
+		{
+			name:     "delete secret",
+			metadata: []string{"path:workloads/db"},
+			setup: func(vault *fakeVault) {
+				vault.putSecret("secret/workloads/db", td.Name(), map[string]any{})
+			},
+		},
+		{
+			name:     "secret not found",
+			metadata: []string{"path:workloads/db"},
+		},
+		{
+			name:     "secret not managed by SPIRE",
+			metadata: []string{"path:workloads/db"},
+			setup: func(vault *fakeVault) {
+				vault.putSecret("secret/workloads/db", "", map[string]any{})
+			},
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    "svidstore(vault_kv): secret is not managed by this SPIRE deployment",
+			expectSecret: true,
+		},
+		{
+			name:       "no path",
+			metadata:   []string{"mount:kv"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "svidstore(vault_kv): path is required",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			vault := newFakeVault(t)
+			if tt.setup != nil {
+				tt.setup(vault)
+			}
+			ss, _ := loadPlugin(t, vault, `token_auth { token = "config-token" }`)
+
+			err := ss.DeleteX509SVID(context.Background(), tt.metadata)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+
+			_, ok := vault.secrets["secret/workloads/db"]
+			require.Equal(t, tt.expectSecret, ok)
+		})
+	}
+}
+
+func TestTokenRenewal(t *testing.T) {
+	vault := newFakeVault(t)
+	ss, clk := loadPlugin(t, vault, `approle_auth { approle_id = "role-id" approle_secret_id = "secret-id" }`)
+	require.Equal(t, []string{"approle"}, vault.logins)
+
+	req, _ := newPutRequest(t, "path:workloads/db")
+
+	// The token is still valid, no login is needed
+	require.NoError(t, ss.PutX509SVID(context.Background(), req))
+	require.Equal(t, []string{"approle"}, vault.logins)
+
+	// The token is about to expire, the plugin logs in again
+	clk.Add(time.Hour - tokenRenewalMargin + time.Second)
+	require.NoError(t, ss.PutX509SVID(context.Background(), req))
+	require.Equal(t, []string{"approle", "approle"}, vault.logins)
+}
+
+func loadPlugin(t *testing.T, vault *fakeVault, authConfig string) (*svidstore.V1, *clock.Mock) {
+	clk := clock.NewMock(t)
+	p := New()
+	p.clock = clk
+	p.hooks.lookupEnv = func(string) (string, bool) { return "", false }
+
+	ss := new(svidstore.V1)
+	plugintest.Load(t, builtin(p), ss,
+		plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+		plugintest.Configure(`vault_addr = "`+vault.addr+`"`+"\n"+authConfig),
+	)
+	return ss, clk
+}
+
+func newPutRequest(t *testing.T, metadata ...string) (*svidstore.X509SVID, map[string]any) {
+	ca := testca.New(t, td)
+	federatedCA := testca.New(t, federated)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/db"))
+
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+
+	req := &svidstore.X509SVID{
+		SVID: &svidstore.SVID{
+			SPIFFEID:   svid.ID,
+			CertChain:  svid.Certificates,
+			PrivateKey: svid.PrivateKey,
+			Bundle:     ca.X509Authorities(),
+			ExpiresAt:  svid.Certificates[0].NotAfter,
+		},
+		Metadata: metadata,
+		FederatedBundles: map[string][]*x509.Certificate{
+			federated.IDString(): federatedCA.X509Authorities(),
+		},
+	}
+	expectData := map[string]any{
+		"spiffeID":    svid.ID.String(),
+		"x509SVID":    string(pemutil.EncodeCertificates(svid.Certificates)),
+		"x509SVIDKey": string(keyPEM),
+		"bundle":      string(pemutil.EncodeCertificates(ca.X509Authorities())),
+		"federatedBundles": map[string]any{
+			federated.IDString(): string(pemutil.EncodeCertificates(federatedCA.X509Authorities())),
+		},
+	}
+	return req, expectData
+}
+
+type fakeSecret struct {
+	customMetadata map[string]any
+	data           map[string]any
+	versions       int
+}
+
+// fakeVault is a minimal implementation of the Vault HTTP API with the
+// AppRole and Kubernetes auth methods and the KV v2 secret engine.
+type fakeVault struct {
+	t    *testing.T
+	addr string
+
+	mtx          sync.Mutex
+	logins       []string
+	secrets      map[string]*fakeSecret
+	failRequests bool
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	vault := &fakeVault{
+		t:       t,
+		secrets: make(map[string]*fakeSecret),
+	}
+	server := httptest.NewServer(http.HandlerFunc(vault.serveHTTP))
+	t.Cleanup(server.Close)
+	vault.addr = server.URL
+	return vault
+}
+
+func (v *fakeVault) putSecret(path, trustDomain string, data map[string]any) {
+	customMetadata := map[string]any{}
+	if trustDomain != "" {
+		customMetadata[spireSVIDMetadataKey] = trustDomain
+	}
+	v.secrets[path] = &fakeSecret{
+		customMetadata: customMetadata,
+		data:           data,
+		versions:       1,
+	}
+}
+
+func (v *fakeVault) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mtx.Lock()
+	defer v.mtx.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	if mountPoint, ok := strings.CutSuffix(strings.TrimPrefix(path, "auth/"), "/login"); ok && strings.HasPrefix(path, "auth/") {
+		v.login(w, r, mountPoint)
+		return
+	}
+
+	if v.failRequests {
+		http.Error(w, `{"errors":["invalid request"]}`, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Header.Get("X-Vault-Token") {
+	case "config-token", "approle-token", "k8s-token":
+	default:
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+		return
+	}
+
+	mountPoint, rest, _ := strings.Cut(path, "/")
+	kind, secretPath, _ := strings.Cut(rest, "/")
+	key := mountPoint + "/" + secretPath
+	secret := v.secrets[key]
+
+	var body map[string]any
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch {
+	case kind == "metadata" && r.Method == http.MethodGet:
+		if secret == nil {
+			http.Error(w, `{"errors":[]}`, http.StatusNotFound)
+			return
+		}
+		v.writeJSON(w, map[string]any{
+			"data": map[string]any{
+				"custom_metadata": secret.customMetadata,
+				"current_version": secret.versions,
+				"versions":        map[string]any{},
+			},
+		})
+	case kind == "metadata" && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		if secret == nil {
+			secret = &fakeSecret{}
+			v.secrets[key] = secret
+		}
+		customMetadata, _ := body["custom_metadata"].(map[string]any)
+		secret.customMetadata = customMetadata
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "metadata" && r.Method == http.MethodDelete:
+		delete(v.secrets, key)
+		w.WriteHeader(http.StatusNoContent)
+	case kind == "data" && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		if secret == nil {
+			secret = &fakeSecret{}
+			v.secrets[key] = secret
+		}
+		data, _ := body["data"].(map[string]any)
+		secret.data = data
+		secret.versions++
+		v.writeJSON(w, map[string]any{
+			"data": map[string]any{
+				"created_time": time.Now().UTC().Format(time.RFC3339Nano),
+				"version":      secret.versions,
+			},
+		})
+	default:
+		http.Error(w, `{"errors":["unsupported path"]}`, http.StatusMethodNotAllowed)
+	}
+}
+
+func (v *fakeVault) login(w http.ResponseWriter, r *http.Request, mountPoint string) {
+	v.logins = append(v.logins, mountPoint)
+
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var token string
+	switch {
+	case body["role_id"] == "role-id" && body["secret_id"] == "secret-id":
+		token = "approle-token"
+	case body["role"] == "role" && body["jwt"] == "k8s-sa-token":
+		token = "k8s-token"
+	default:
+		http.Error(w, `{"errors":["invalid credentials"]}`, http.StatusBadRequest)
+		return
+	}
+
+	v.writeJSON(w, map[string]any{
+		"auth": map[string]any{
+			"client_token":   token,
+			"lease_duration": int((time.Hour).Seconds()),
+			"renewable":      true,
+		},
+	})
+}
+
+func (v *fakeVault) writeJSON(w http.ResponseWriter, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(v.t, json.NewEncoder(w).Encode(body))
+}
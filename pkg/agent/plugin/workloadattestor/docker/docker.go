@@ -15,6 +15,9 @@ import (
 	"github.com/hashicorp/hcl/hcl/token"
 	workloadattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/workloadattestor/v1"
 	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+	"github.com/spiffe/spire/pkg/agent/common/intoto"
+	"github.com/spiffe/spire/pkg/agent/common/notation"
 	"github.com/spiffe/spire/pkg/agent/common/sigstore"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/pluginconf"
@@ -54,10 +57,10 @@ type Plugin struct {
 	log     hclog.Logger
 	retryer *retryer
 
-	mtx              sync.RWMutex
-	docker           Docker
-	c                *containerHelper
-	sigstoreVerifier sigstore.Verifier
+	mtx           sync.RWMutex
+	docker        Docker
+	c             *containerHelper
+	imageVerifier imageverifier.Verifier
 }
 
 func New() *Plugin {
@@ -79,11 +82,19 @@ type dockerPluginConfig struct {
 	containerHelper *containerHelper
 	dockerOpts      []dockerclient.Opt
 	sigstoreConfig  *sigstore.Config
+	notationConfig  *notation.Config
+	inTotoConfig    *intoto.Config
 }
 
 type experimentalConfig struct {
 	// Sigstore contains sigstore specific configs.
 	Sigstore *sigstore.HCLConfig `hcl:"sigstore,omitempty"`
+
+	// Notation contains Notary Project (notation) signature specific configs.
+	Notation *notation.HCLConfig `hcl:"notation,omitempty"`
+
+	// InToto contains in-toto provenance attestation specific configs.
+	InToto *intoto.HCLConfig `hcl:"in_toto,omitempty"`
 }
 
 func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *dockerPluginConfig {
@@ -119,6 +130,12 @@ func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, stat
 	if newConfig.Experimental.Sigstore != nil {
 		newConfig.sigstoreConfig = sigstore.NewConfigFromHCL(newConfig.Experimental.Sigstore, p.log)
 	}
+	if newConfig.Experimental.Notation != nil {
+		newConfig.notationConfig = notation.NewConfigFromHCL(newConfig.Experimental.Notation, p.log)
+	}
+	if newConfig.Experimental.InToto != nil {
+		newConfig.inTotoConfig = intoto.NewConfigFromHCL(newConfig.Experimental.InToto, p.log)
+	}
 
 	return newConfig
 }
@@ -154,7 +171,7 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 
 	selectors := getSelectorValuesFromConfig(container.Config)
 
-	if p.sigstoreVerifier != nil {
+	if p.imageVerifier != nil {
 		imageName := container.Config.Image
 		imageJSON, _, err := p.docker.ImageInspectWithRaw(ctx, imageName)
 		if err != nil {
@@ -162,7 +179,7 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 		}
 
 		if len(imageJSON.RepoDigests) == 0 {
-			return nil, fmt.Errorf("sigstore signature verification failed: no repo digest found for image %s", imageName)
+			return nil, fmt.Errorf("image verification failed: no repo digest found for image %s", imageName)
 		}
 
 		var verified bool
@@ -171,19 +188,19 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 		// refer to the same image.
 		var allErrors []string
 		for _, digest := range imageJSON.RepoDigests {
-			sigstoreSelectors, err := p.sigstoreVerifier.Verify(ctx, digest)
+			imageSelectors, err := p.imageVerifier.Verify(ctx, digest)
 			if err != nil {
-				p.log.Warn("Error verifying sigstore image signature", telemetry.ImageID, digest, telemetry.Error, err)
+				p.log.Warn("Error verifying image", telemetry.ImageID, digest, telemetry.Error, err)
 				allErrors = append(allErrors, fmt.Sprintf("%s %s: %v", telemetry.ImageID, digest, err))
 				continue
 			}
-			selectors = append(selectors, sigstoreSelectors...)
+			selectors = append(selectors, imageSelectors...)
 			verified = true
 			break
 		}
 
 		if !verified {
-			return nil, fmt.Errorf("sigstore signature verification failed for image %s: %v", imageName, fmt.Sprintf("errors: %s", strings.Join(allErrors, "; ")))
+			return nil, fmt.Errorf("image verification failed for image %s: %v", imageName, fmt.Sprintf("errors: %s", strings.Join(allErrors, "; ")))
 		}
 	}
 
@@ -217,25 +234,52 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 		return nil, err
 	}
 
-	var sigstoreVerifier sigstore.Verifier
-	if newConfig.sigstoreConfig != nil {
-		verifier := sigstore.NewVerifier(newConfig.sigstoreConfig)
-		err = verifier.Init(ctx)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "error initializing sigstore verifier: %v", err)
-		}
-		sigstoreVerifier = verifier
+	imageVerifier, err := newImageVerifier(ctx, newConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
 	p.docker = docker
 	p.c = newConfig.containerHelper
-	p.sigstoreVerifier = sigstoreVerifier
+	p.imageVerifier = imageVerifier
 
 	return &configv1.ConfigureResponse{}, nil
 }
 
+// newImageVerifier returns a verifier that runs all the configured image
+// verifiers, or nil if none is configured.
+func newImageVerifier(ctx context.Context, config *dockerPluginConfig) (imageverifier.Verifier, error) {
+	chain := new(imageverifier.Chain)
+	if config.sigstoreConfig != nil {
+		verifier := sigstore.NewVerifier(config.sigstoreConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing sigstore verifier: %v", err)
+		}
+		chain.Add("sigstore", verifier)
+	}
+	if config.notationConfig != nil {
+		verifier := notation.NewVerifier(config.notationConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing notation verifier: %v", err)
+		}
+		chain.Add("notation", verifier)
+	}
+	if config.inTotoConfig != nil {
+		verifier := intoto.NewVerifier(config.inTotoConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing in-toto verifier: %v", err)
+		}
+		chain.Add("in-toto", verifier)
+	}
+
+	if chain.Len() == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
+
 func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
 	_, notes, err := pluginconf.Build(req, p.buildConfig)
 
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"testing"
 	"time"
@@ -13,12 +15,15 @@ import (
 	dockerclient "github.com/docker/docker/client"
 	"github.com/hashicorp/go-hclog"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
 	"github.com/spiffe/spire/pkg/agent/common/sigstore"
 	"github.com/spiffe/spire/pkg/agent/plugin/workloadattestor"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -156,13 +161,18 @@ func TestDockerErrorContextCancel(t *testing.T) {
 }
 
 func TestDockerConfig(t *testing.T) {
+	dir := t.TempDir()
+	trustStorePath := filepath.Join(dir, "notation-ca.pem")
+	ca, _ := testca.CreateCACertificate(t, nil, nil)
+	require.NoError(t, os.WriteFile(trustStorePath, pemutil.EncodeCertificate(ca), 0600))
+
 	for _, tt := range []struct {
 		name               string
 		trustDomain        string
 		expectCode         codes.Code
 		expectMsg          string
 		config             string
-		sigstoreConfigured bool
+		verifierConfigured bool
 	}{
 		{
 			name:        "success configuration",
@@ -188,7 +198,41 @@ func TestDockerConfig(t *testing.T) {
         					registry_password = "pass"
     					}
 			}`,
-			sigstoreConfigured: true,
+			verifierConfigured: true,
+		},
+		{
+			name:        "notation configuration",
+			trustDomain: "example.org",
+			config: fmt.Sprintf(`
+					experimental {
+						notation {
+							trust_stores = [%q]
+							trusted_identities = ["CN=signer,O=example.org"]
+						}
+					}`, trustStorePath),
+			verifierConfigured: true,
+		},
+		{
+			name:        "notation configuration without trusted identities",
+			trustDomain: "example.org",
+			config: fmt.Sprintf(`
+					experimental {
+						notation {
+							trust_stores = [%q]
+						}
+					}`, trustStorePath),
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "error initializing notation verifier: at least one trusted identity is required",
+		},
+		{
+			name:        "in-toto configuration without policy file",
+			trustDomain: "example.org",
+			config: `
+					experimental {
+						in_toto {}
+					}`,
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "error initializing in-toto verifier: policy_file is required",
 		},
 		{
 			name:        "bad hcl",
@@ -222,10 +266,10 @@ invalid2 = "/no/"`,
 
 			spiretest.RequireGRPCStatusHasPrefix(t, err, tt.expectCode, tt.expectMsg)
 
-			if tt.sigstoreConfigured {
-				assert.NotNil(t, p.sigstoreVerifier)
+			if tt.verifierConfigured {
+				assert.NotNil(t, p.imageVerifier)
 			} else {
-				assert.Nil(t, p.sigstoreVerifier)
+				assert.Nil(t, p.imageVerifier)
 			}
 		})
 	}
@@ -308,8 +352,8 @@ func TestNewConfigFromHCL(t *testing.T) {
 	}
 }
 
-func TestSigstoreVerifier(t *testing.T) {
-	fakeVerifier := &fakeSigstoreVerifier{
+func TestImageVerifier(t *testing.T) {
+	fakeVerifier := &fakeImageVerifier{
 		expectedImageID: testImageID,
 		selectors:       []string{"sigstore:selector"},
 		err:             nil,
@@ -321,7 +365,7 @@ func TestSigstoreVerifier(t *testing.T) {
 		Env:    []string{"VAR=val"},
 	}
 
-	p := newTestPlugin(t, withDocker(fakeDocker), withDefaultDataOpt(t), withSigstoreVerifier(fakeVerifier))
+	p := newTestPlugin(t, withDocker(fakeDocker), withDefaultDataOpt(t), withImageVerifier(fakeVerifier))
 
 	// Run attestation
 	selectors, err := doAttest(t, p)
@@ -386,9 +430,9 @@ func withDisabledRetryer() testPluginOpt {
 	}
 }
 
-func withSigstoreVerifier(v sigstore.Verifier) testPluginOpt {
+func withImageVerifier(v imageverifier.Verifier) testPluginOpt {
 	return func(p *Plugin) {
-		p.sigstoreVerifier = v
+		p.imageVerifier = v
 	}
 }
 
@@ -429,13 +473,13 @@ func (f fakeContainer) ImageInspectWithRaw(_ context.Context, imageName string)
 	return image.InspectResponse{ID: imageName, RepoDigests: []string{testImageID}}, nil, nil
 }
 
-type fakeSigstoreVerifier struct {
+type fakeImageVerifier struct {
 	expectedImageID string
 	selectors       []string
 	err             error
 }
 
-func (f *fakeSigstoreVerifier) Verify(_ context.Context, imageID string) ([]string, error) {
+func (f *fakeImageVerifier) Verify(_ context.Context, imageID string) ([]string, error) {
 	if imageID != f.expectedImageID {
 		return nil, fmt.Errorf("unexpected image ID: %s", imageID)
 	}
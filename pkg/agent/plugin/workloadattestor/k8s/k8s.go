@@ -22,6 +22,9 @@ import (
 	"github.com/hashicorp/hcl"
 	workloadattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/agent/workloadattestor/v1"
 	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/agent/common/imageverifier"
+	"github.com/spiffe/spire/pkg/agent/common/intoto"
+	"github.com/spiffe/spire/pkg/agent/common/notation"
 	"github.com/spiffe/spire/pkg/agent/common/sigstore"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/pemutil"
@@ -140,6 +143,12 @@ type HCLConfig struct {
 type experimentalK8SConfig struct {
 	// Sigstore contains sigstore specific configs.
 	Sigstore *sigstore.HCLConfig `hcl:"sigstore,omitempty"`
+
+	// Notation contains Notary Project (notation) signature specific configs.
+	Notation *notation.HCLConfig `hcl:"notation,omitempty"`
+
+	// InToto contains in-toto provenance attestation specific configs.
+	InToto *intoto.HCLConfig `hcl:"in_toto,omitempty"`
 }
 
 // k8sConfig holds the configuration distilled from HCL
@@ -159,6 +168,8 @@ type k8sConfig struct {
 	DisableContainerSelectors  bool
 	ContainerHelper            ContainerHelper
 	sigstoreConfig             *sigstore.Config
+	notationConfig             *notation.Config
+	inTotoConfig               *intoto.Config
 
 	Client     *kubeletClient
 	LastReload time.Time
@@ -235,6 +246,16 @@ func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, stat
 		sigstoreConfig = sigstore.NewConfigFromHCL(newConfig.Experimental.Sigstore, p.log)
 	}
 
+	var notationConfig *notation.Config
+	if newConfig.Experimental.Notation != nil {
+		notationConfig = notation.NewConfigFromHCL(newConfig.Experimental.Notation, p.log)
+	}
+
+	var inTotoConfig *intoto.Config
+	if newConfig.Experimental.InToto != nil {
+		inTotoConfig = intoto.NewConfigFromHCL(newConfig.Experimental.InToto, p.log)
+	}
+
 	// return the kubelet client
 	return &k8sConfig{
 		Secure:                     secure,
@@ -252,6 +273,8 @@ func (p *Plugin) buildConfig(coreConfig catalog.CoreConfig, hclText string, stat
 		DisableContainerSelectors:  newConfig.DisableContainerSelectors,
 		ContainerHelper:            containerHelper,
 		sigstoreConfig:             sigstoreConfig,
+		notationConfig:             notationConfig,
+		inTotoConfig:               inTotoConfig,
 	}
 }
 
@@ -269,10 +292,10 @@ type Plugin struct {
 	rootDir string
 	getenv  func(string) string
 
-	mu               sync.RWMutex
-	config           *k8sConfig
-	containerHelper  ContainerHelper
-	sigstoreVerifier sigstore.Verifier
+	mu              sync.RWMutex
+	config          *k8sConfig
+	containerHelper ContainerHelper
+	imageVerifier   imageverifier.Verifier
 
 	cachedPodList           map[string]*fastjson.Value
 	cachedPodListValidUntil time.Time
@@ -291,7 +314,7 @@ func (p *Plugin) SetLogger(log hclog.Logger) {
 }
 
 func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestRequest) (*workloadattestorv1.AttestResponse, error) {
-	config, containerHelper, sigstoreVerifier, err := p.getConfig()
+	config, containerHelper, imageVerifier, err := p.getConfig()
 	if err != nil {
 		return nil, err
 	}
@@ -354,13 +377,13 @@ func (p *Plugin) Attest(ctx context.Context, req *workloadattestorv1.AttestReque
 					selectorValues = append(selectorValues, getSelectorValuesFromWorkloadContainerStatus(containerStatus)...)
 				}
 
-				if sigstoreVerifier != nil {
-					log.Debug("Attempting to verify sigstore image signature", "image", containerStatus.Image)
-					sigstoreSelectors, err := p.sigstoreVerifier.Verify(ctx, containerStatus.ImageID)
+				if imageVerifier != nil {
+					log.Debug("Attempting to verify image", "image", containerStatus.Image)
+					imageSelectors, err := imageVerifier.Verify(ctx, containerStatus.ImageID)
 					if err != nil {
-						return nil, status.Errorf(codes.Internal, "error verifying sigstore image signature for imageID %s: %v", containerStatus.ImageID, err)
+						return nil, status.Errorf(codes.Internal, "error verifying image for imageID %s: %v", containerStatus.ImageID, err)
 					}
-					selectorValues = append(selectorValues, sigstoreSelectors...)
+					selectorValues = append(selectorValues, imageSelectors...)
 				}
 
 			case podKnown && config.DisableContainerSelectors:
@@ -410,21 +433,16 @@ func (p *Plugin) Configure(ctx context.Context, req *configv1.ConfigureRequest)
 		return nil, err
 	}
 
-	var sigstoreVerifier sigstore.Verifier
-	if newConfig.sigstoreConfig != nil {
-		verifier := sigstore.NewVerifier(newConfig.sigstoreConfig)
-		err = verifier.Init(ctx)
-		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "error initializing sigstore verifier: %v", err)
-		}
-		sigstoreVerifier = verifier
+	imageVerifier, err := newImageVerifier(ctx, newConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.config = newConfig
 	p.containerHelper = newConfig.ContainerHelper
-	p.sigstoreVerifier = sigstoreVerifier
+	p.imageVerifier = imageVerifier
 
 	return &configv1.ConfigureResponse{}, nil
 }
@@ -438,7 +456,7 @@ func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (res
 	}, nil
 }
 
-func (p *Plugin) getConfig() (*k8sConfig, ContainerHelper, sigstore.Verifier, error) {
+func (p *Plugin) getConfig() (*k8sConfig, ContainerHelper, imageverifier.Verifier, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -448,7 +466,39 @@ func (p *Plugin) getConfig() (*k8sConfig, ContainerHelper, sigstore.Verifier, er
 	if err := p.reloadKubeletClient(p.config); err != nil {
 		p.log.Warn("Unable to load kubelet client", "err", err)
 	}
-	return p.config, p.containerHelper, p.sigstoreVerifier, nil
+	return p.config, p.containerHelper, p.imageVerifier, nil
+}
+
+// newImageVerifier returns a verifier that runs all the configured image
+// verifiers, or nil if none is configured.
+func newImageVerifier(ctx context.Context, config *k8sConfig) (imageverifier.Verifier, error) {
+	chain := new(imageverifier.Chain)
+	if config.sigstoreConfig != nil {
+		verifier := sigstore.NewVerifier(config.sigstoreConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing sigstore verifier: %v", err)
+		}
+		chain.Add("sigstore", verifier)
+	}
+	if config.notationConfig != nil {
+		verifier := notation.NewVerifier(config.notationConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing notation verifier: %v", err)
+		}
+		chain.Add("notation", verifier)
+	}
+	if config.inTotoConfig != nil {
+		verifier := intoto.NewVerifier(config.inTotoConfig)
+		if err := verifier.Init(ctx); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "error initializing in-toto verifier: %v", err)
+		}
+		chain.Add("in-toto", verifier)
+	}
+
+	if chain.Len() == 0 {
+		return nil, nil
+	}
+	return chain, nil
 }
 
 func (p *Plugin) setPodListCache(podList map[string]*fastjson.Value, cacheFor time.Duration) {
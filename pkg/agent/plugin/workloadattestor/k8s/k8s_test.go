@@ -7,6 +7,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"math/big"
 	"net"
@@ -698,9 +699,80 @@ func (s *Suite) TestConfigureWithSigstore() {
 			}
 			require.NoError(t, err)
 
-			_, _, sigstoreVerifier, err := p.getConfig()
+			_, _, imageVerifier, err := p.getConfig()
 			require.NoError(t, err)
-			assert.NotNil(t, sigstoreVerifier)
+			assert.NotNil(t, imageVerifier)
+		})
+	}
+}
+
+func (s *Suite) TestConfigureWithImageVerifiers() {
+	s.generateCerts("")
+	s.writeCert("notation-ca.pem", s.kubeletCert)
+	publicKey, err := x509.MarshalPKIXPublicKey(clientKey.Public())
+	s.Require().NoError(err)
+	s.writeFile("in-toto-policy.json", fmt.Sprintf(`{"trusted_keys": [%q], "builder_ids": ["https://builder.example.org"]}`,
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})))
+
+	cases := []struct {
+		name          string
+		hcl           string
+		expectedError string
+	}{
+		{
+			name: "notation and in-toto configuration",
+			hcl: fmt.Sprintf(`
+				skip_kubelet_verification = true
+				experimental {
+					notation {
+						trust_stores = [%q]
+						trusted_identities = ["CN=signer,O=example.org"]
+						skipped_images = ["registry/image@sha256:examplehash"]
+					}
+					in_toto {
+						policy_file = %q
+					}
+				}`, filepath.Join(s.dir, "notation-ca.pem"), filepath.Join(s.dir, "in-toto-policy.json")),
+		},
+		{
+			name: "notation without trust stores",
+			hcl: `
+				skip_kubelet_verification = true
+				experimental { notation { trusted_identities = ["*"] } }
+			`,
+			expectedError: "error initializing notation verifier: at least one trust store is required",
+		},
+		{
+			name: "in-toto with missing policy file",
+			hcl: fmt.Sprintf(`
+				skip_kubelet_verification = true
+				experimental { in_toto { policy_file = %q } }
+			`, filepath.Join(s.dir, "missing-policy.json")),
+			expectedError: "error initializing in-toto verifier: failed to read policy file",
+		},
+	}
+
+	for _, tc := range cases {
+		s.T().Run(tc.name, func(t *testing.T) {
+			p := s.newPlugin()
+
+			var err error
+			plugintest.Load(s.T(), builtin(p), nil,
+				plugintest.CoreConfig(catalog.CoreConfig{
+					TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+				}),
+				plugintest.Configure(tc.hcl),
+				plugintest.CaptureConfigureError(&err))
+
+			if tc.expectedError != "" {
+				s.RequireGRPCStatusContains(err, codes.InvalidArgument, tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			_, _, imageVerifier, err := p.getConfig()
+			require.NoError(t, err)
+			assert.NotNil(t, imageVerifier)
 		})
 	}
 }
@@ -760,9 +832,9 @@ func (s *Suite) loadPlugin(configuration string) workloadattestor.WorkloadAttest
 		p.setContainerHelper(cHelper)
 	}
 
-	// if sigstore is configured, override with fake
-	if p.sigstoreVerifier != nil {
-		p.sigstoreVerifier = newFakeSigstoreVerifier(map[string][]string{imageID: {"sigstore:selector"}})
+	// if an image verifier is configured, override with fake
+	if p.imageVerifier != nil {
+		p.imageVerifier = newFakeImageVerifier(map[string][]string{imageID: {"sigstore:selector"}})
 	}
 	return v1
 }
@@ -997,19 +1069,19 @@ func (s *Suite) podListResponseCount() int {
 	return len(s.podList)
 }
 
-type fakeSigstoreVerifier struct {
+type fakeImageVerifier struct {
 	mu sync.Mutex
 
 	SigDetailsSets map[string][]string
 }
 
-func newFakeSigstoreVerifier(selectors map[string][]string) *fakeSigstoreVerifier {
-	return &fakeSigstoreVerifier{
+func newFakeImageVerifier(selectors map[string][]string) *fakeImageVerifier {
+	return &fakeImageVerifier{
 		SigDetailsSets: selectors,
 	}
 }
 
-func (v *fakeSigstoreVerifier) Verify(_ context.Context, imageID string) ([]string, error) {
+func (v *fakeImageVerifier) Verify(_ context.Context, imageID string) ([]string, error) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// workloadCacheKeySize is the size of the AES-256 key used to encrypt the
+	// workload cache.
+	workloadCacheKeySize = 32
+
+	// workloadCacheAAD is authenticated along with the workload cache to bind
+	// the ciphertext to its purpose and format version.
+	workloadCacheAAD = "spire-agent-workload-cache-v1"
+)
+
+// WorkloadCache is an encrypted on-disk store for the registration entries,
+// bundles and workload X509-SVIDs cached by the agent. It allows the agent to
+// serve workload SVIDs after a restart while the server is unreachable.
+type WorkloadCache struct {
+	path string
+	aead cipher.AEAD
+
+	mtx        sync.Mutex
+	lastDigest [sha256.Size]byte
+}
+
+// WorkloadCacheData is the data persisted in the workload cache.
+type WorkloadCacheData struct {
+	// Bundles is the set of trust bundles, keyed by trust domain
+	Bundles map[spiffeid.TrustDomain]*spiffebundle.Bundle
+
+	// Entries is the set of registration entries, keyed by registration
+	// entry ID
+	Entries map[string]*common.RegistrationEntry
+
+	// X509SVIDs is the set of workload X509-SVIDs, keyed by registration
+	// entry ID
+	X509SVIDs map[string]*WorkloadX509SVID
+}
+
+// WorkloadX509SVID is a workload X509-SVID persisted in the workload cache.
+type WorkloadX509SVID struct {
+	Chain      []*x509.Certificate
+	PrivateKey crypto.Signer
+}
+
+// OpenWorkloadCache opens the workload cache in the given directory. The
+// cache is encrypted with the AES-256 key read from keyPath. If the key file
+// does not exist, a new key is generated and written to it.
+func OpenWorkloadCache(dir, keyPath string) (*WorkloadCache, error) {
+	key, err := loadOrCreateWorkloadCacheKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cache cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cache cipher: %w", err)
+	}
+
+	return &WorkloadCache{
+		path: workloadCachePath(dir),
+		aead: aead,
+	}, nil
+}
+
+// Load loads the workload cache. Returns ErrNotCached if the workload cache
+// does not exist.
+func (w *WorkloadCache) Load() (*WorkloadCacheData, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	sealed, err := os.ReadFile(w.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, ErrNotCached
+	case err != nil:
+		return nil, fmt.Errorf("failed to read workload cache: %w", err)
+	}
+
+	nonceSize := w.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, errors.New("failed to decrypt workload cache: data is too short")
+	}
+	marshaled, err := w.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(workloadCacheAAD))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt workload cache: %w", err)
+	}
+
+	data := new(WorkloadCacheData)
+	if err := json.Unmarshal(marshaled, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workload cache: %w", err)
+	}
+
+	w.lastDigest = sha256.Sum256(marshaled)
+	return data, nil
+}
+
+// Store stores the workload cache. The cache is not written if the data did
+// not change since it was last loaded or stored.
+func (w *WorkloadCache) Store(data *WorkloadCacheData) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	marshaled, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workload cache: %w", err)
+	}
+
+	digest := sha256.Sum256(marshaled)
+	if digest == w.lastDigest {
+		return nil
+	}
+
+	nonce := make([]byte, w.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := w.aead.Seal(nonce, nonce, marshaled, []byte(workloadCacheAAD))
+
+	if err := diskutil.AtomicWritePrivateFile(w.path, sealed); err != nil {
+		return fmt.Errorf("failed to write workload cache: %w", err)
+	}
+
+	w.lastDigest = digest
+	return nil
+}
+
+// Delete deletes the workload cache.
+func (w *WorkloadCache) Delete() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if err := os.Remove(w.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove workload cache: %w", err)
+	}
+
+	w.lastDigest = [sha256.Size]byte{}
+	return nil
+}
+
+type workloadCacheJSON struct {
+	Bundles   map[string][]byte               `json:"bundles"`
+	Entries   [][]byte                        `json:"entries"`
+	X509SVIDs map[string]workloadX509SVIDJSON `json:"x509_svids"`
+}
+
+type workloadX509SVIDJSON struct {
+	Chain      [][]byte `json:"chain"`
+	PrivateKey []byte   `json:"private_key"`
+}
+
+func (d *WorkloadCacheData) MarshalJSON() ([]byte, error) {
+	j := workloadCacheJSON{
+		Bundles:   make(map[string][]byte, len(d.Bundles)),
+		X509SVIDs: make(map[string]workloadX509SVIDJSON, len(d.X509SVIDs)),
+	}
+
+	for td, bundle := range d.Bundles {
+		bundleBytes, err := bundle.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal bundle for %q: %w", td, err)
+		}
+		j.Bundles[td.Name()] = bundleBytes
+	}
+
+	for _, entry := range d.Entries {
+		entryBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry %q: %w", entry.EntryId, err)
+		}
+		j.Entries = append(j.Entries, entryBytes)
+	}
+
+	for id, svid := range d.X509SVIDs {
+		var chain [][]byte
+		for _, cert := range svid.Chain {
+			chain = append(chain, cert.Raw)
+		}
+		privateKey, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal private key for %q: %w", id, err)
+		}
+		j.X509SVIDs[id] = workloadX509SVIDJSON{
+			Chain:      chain,
+			PrivateKey: privateKey,
+		}
+	}
+
+	// Entries are serialized in a stable order so that unchanged data has
+	// the same digest.
+	slices.SortFunc(j.Entries, bytes.Compare)
+
+	return json.Marshal(j)
+}
+
+func (d *WorkloadCacheData) UnmarshalJSON(b []byte) error {
+	j := new(workloadCacheJSON)
+	if err := json.Unmarshal(b, j); err != nil {
+		return err
+	}
+
+	bundles := make(map[spiffeid.TrustDomain]*spiffebundle.Bundle, len(j.Bundles))
+	for name, bundleBytes := range j.Bundles {
+		td, err := spiffeid.TrustDomainFromString(name)
+		if err != nil {
+			return fmt.Errorf("invalid bundle trust domain %q: %w", name, err)
+		}
+		bundle, err := spiffebundle.Parse(td, bundleBytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse bundle for %q: %w", name, err)
+		}
+		bundles[td] = bundle
+	}
+
+	entries := make(map[string]*common.RegistrationEntry, len(j.Entries))
+	for _, entryBytes := range j.Entries {
+		entry := new(common.RegistrationEntry)
+		if err := proto.Unmarshal(entryBytes, entry); err != nil {
+			return fmt.Errorf("failed to parse entry: %w", err)
+		}
+		entries[entry.EntryId] = entry
+	}
+
+	svids := make(map[string]*WorkloadX509SVID, len(j.X509SVIDs))
+	for id, svid := range j.X509SVIDs {
+		var chain []*x509.Certificate
+		for _, certBytes := range svid.Chain {
+			cert, err := x509.ParseCertificate(certBytes)
+			if err != nil {
+				return fmt.Errorf("failed to parse X509-SVID for %q: %w", id, err)
+			}
+			chain = append(chain, cert)
+		}
+		if len(chain) == 0 {
+			return fmt.Errorf("X509-SVID for %q is empty", id)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(svid.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("failed to parse private key for %q: %w", id, err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return fmt.Errorf("private key for %q is not a signer", id)
+		}
+		svids[id] = &WorkloadX509SVID{
+			Chain:      chain,
+			PrivateKey: signer,
+		}
+	}
+
+	d.Bundles = bundles
+	d.Entries = entries
+	d.X509SVIDs = svids
+	return nil
+}
+
+func loadOrCreateWorkloadCacheKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		key = make([]byte, workloadCacheKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate workload cache key: %w", err)
+		}
+		if err := diskutil.AtomicWritePrivateFile(path, key); err != nil {
+			return nil, fmt.Errorf("failed to write workload cache key: %w", err)
+		}
+		return key, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read workload cache key: %w", err)
+	case len(key) != workloadCacheKeySize:
+		return nil, fmt.Errorf("invalid workload cache key: expected %d bytes, got %d", workloadCacheKeySize, len(key))
+	}
+	return key, nil
+}
+
+func workloadCachePath(dir string) string {
+	return filepath.Join(dir, "workload-cache.bin")
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+)
+
+var workloadKey = testkey.MustEC256()
+
+func TestWorkloadCache(t *testing.T) {
+	t.Run("load from empty cache", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+
+		wc := openWorkloadCache(t, dir)
+		actual, err := wc.Load()
+		require.True(t, errors.Is(err, ErrNotCached))
+		require.Nil(t, actual)
+	})
+
+	t.Run("load from new cache instance", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+		data := makeWorkloadCacheData(t)
+
+		wc := openWorkloadCache(t, dir)
+		require.NoError(t, wc.Store(data))
+
+		wc = openWorkloadCache(t, dir)
+		actual, err := wc.Load()
+		require.NoError(t, err)
+		requireWorkloadCacheDataEqual(t, data, actual)
+	})
+
+	t.Run("unchanged data is not written", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+		data := makeWorkloadCacheData(t)
+
+		wc := openWorkloadCache(t, dir)
+		require.NoError(t, wc.Store(data))
+		require.NoError(t, os.Remove(workloadCachePath(dir)))
+
+		require.NoError(t, wc.Store(makeWorkloadCacheData(t)))
+		require.NoFileExists(t, workloadCachePath(dir))
+
+		data.Entries["entry-2"] = &common.RegistrationEntry{EntryId: "entry-2", SpiffeId: "spiffe://example.org/bar"}
+		require.NoError(t, wc.Store(data))
+		require.FileExists(t, workloadCachePath(dir))
+	})
+
+	t.Run("load with a different key", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+
+		wc := openWorkloadCache(t, dir)
+		require.NoError(t, wc.Store(makeWorkloadCacheData(t)))
+
+		wc, err := OpenWorkloadCache(dir, filepath.Join(dir, "other.key"))
+		require.NoError(t, err)
+		_, err = wc.Load()
+		require.EqualError(t, err, "failed to decrypt workload cache: cipher: message authentication failed")
+	})
+
+	t.Run("load tampered cache", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+
+		wc := openWorkloadCache(t, dir)
+		require.NoError(t, wc.Store(makeWorkloadCacheData(t)))
+
+		sealed, err := os.ReadFile(workloadCachePath(dir))
+		require.NoError(t, err)
+		sealed[len(sealed)-1] ^= 0xff
+		require.NoError(t, os.WriteFile(workloadCachePath(dir), sealed, 0600))
+
+		_, err = wc.Load()
+		require.EqualError(t, err, "failed to decrypt workload cache: cipher: message authentication failed")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+
+		wc := openWorkloadCache(t, dir)
+		require.NoError(t, wc.Delete())
+		require.NoError(t, wc.Store(makeWorkloadCacheData(t)))
+		require.NoError(t, wc.Delete())
+
+		actual, err := wc.Load()
+		require.True(t, errors.Is(err, ErrNotCached))
+		require.Nil(t, actual)
+
+		// The digest is reset, so the same data is written again
+		require.NoError(t, wc.Store(makeWorkloadCacheData(t)))
+		require.FileExists(t, workloadCachePath(dir))
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		dir := spiretest.TempDir(t)
+		keyPath := filepath.Join(dir, "workload-cache.key")
+		require.NoError(t, os.WriteFile(keyPath, []byte("too short"), 0600))
+
+		_, err := OpenWorkloadCache(dir, keyPath)
+		require.EqualError(t, err, "invalid workload cache key: expected 32 bytes, got 9")
+	})
+}
+
+func openWorkloadCache(t *testing.T, dir string) *WorkloadCache {
+	wc, err := OpenWorkloadCache(dir, filepath.Join(dir, "workload-cache.key"))
+	require.NoError(t, err)
+	return wc
+}
+
+// makeWorkloadCacheData returns workload cache data that is equal across
+// calls.
+func makeWorkloadCacheData(t *testing.T) *WorkloadCacheData {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	return &WorkloadCacheData{
+		Bundles: map[spiffeid.TrustDomain]*spiffebundle.Bundle{
+			td: spiffebundle.FromX509Authorities(td, certs),
+		},
+		Entries: map[string]*common.RegistrationEntry{
+			"entry-1": {
+				EntryId:   "entry-1",
+				ParentId:  "spiffe://example.org/agent",
+				SpiffeId:  "spiffe://example.org/workload",
+				Selectors: []*common.Selector{{Type: "k8s", Value: "sa:web"}},
+			},
+		},
+		X509SVIDs: map[string]*WorkloadX509SVID{
+			"entry-1": {
+				Chain:      certs,
+				PrivateKey: workloadKey,
+			},
+		},
+	}
+}
+
+func requireWorkloadCacheDataEqual(t *testing.T, expected, actual *WorkloadCacheData) {
+	require.Len(t, actual.Bundles, len(expected.Bundles))
+	for td, bundle := range expected.Bundles {
+		require.True(t, bundle.Equal(actual.Bundles[td]), "bundle for %q does not match", td)
+	}
+
+	require.Len(t, actual.Entries, len(expected.Entries))
+	for id, entry := range expected.Entries {
+		spiretest.RequireProtoEqual(t, entry, actual.Entries[id])
+	}
+
+	require.Len(t, actual.X509SVIDs, len(expected.X509SVIDs))
+	for id, svid := range expected.X509SVIDs {
+		require.Contains(t, actual.X509SVIDs, id)
+		require.Equal(t, svid.Chain, actual.X509SVIDs[id].Chain)
+		require.Equal(t, svid.PrivateKey, actual.X509SVIDs[id].PrivateKey)
+	}
+}
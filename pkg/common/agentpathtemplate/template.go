@@ -3,6 +3,7 @@ package agentpathtemplate
 import (
 	"bytes"
 	"fmt"
+	"maps"
 	"text/template"
 
 	sprig "github.com/Masterminds/sprig/v3"
@@ -142,6 +143,13 @@ func init() {
 	}
 }
 
+// FuncMap returns the functions available to agent path templates. They
+// exclude the sprig functions with access to the environment or the network,
+// so other templates rendered by SPIRE can use them as well.
+func FuncMap() template.FuncMap {
+	return maps.Clone(ourMap)
+}
+
 // Parse parses an agent path template. It changes the behavior for missing
 // keys to return an error instead of the default behavior, which renders a
 // value that requires percent-encoding to include in a URI, which is against
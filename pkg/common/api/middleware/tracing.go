@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// WithTracing returns middleware that starts a server span for each RPC. The
+// trace context propagated by the caller in the gRPC metadata, if any, is
+// used as the parent of the span. The span is ended after the handler
+// returns and records the gRPC status code of the call. If unset, it also
+// provides name metadata on to the handler context.
+func WithTracing() Middleware {
+	return tracingMiddleware{}
+}
+
+type tracingMiddleware struct{}
+
+func (tracingMiddleware) Preprocess(ctx context.Context, fullMethod string, _ any) (context.Context, error) {
+	ctx, names := withNames(ctx, fullMethod)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	ctx, _ = telemetry.Tracer().Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "grpc"),
+			attribute.String("rpc.service", names.RawService),
+			attribute.String("rpc.method", names.Method),
+		),
+	)
+	return ctx, nil
+}
+
+func (tracingMiddleware) Postprocess(ctx context.Context, _ string, _ bool, rpcErr error) {
+	span := trace.SpanFromContext(ctx)
+	telemetry.SetSpanStatus(span, rpcErr)
+	span.End()
+}
+
+// metadataCarrier adapts gRPC metadata to the OpenTelemetry TextMapCarrier
+// interface.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
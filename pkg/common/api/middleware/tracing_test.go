@@ -0,0 +1,105 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/api/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := setupTracing(t)
+
+	parentTraceID := trace.TraceID{0x01}
+	parentSpanID := trace.SpanID{0x02}
+
+	for _, tt := range []struct {
+		name           string
+		md             metadata.MD
+		rpcErr         error
+		expectParent   bool
+		expectCode     codes.Code
+		expectStatus   otelcodes.Code
+		expectErrEvent bool
+	}{
+		{
+			name:         "success",
+			expectCode:   codes.OK,
+			expectStatus: otelcodes.Unset,
+		},
+		{
+			name:           "failure",
+			rpcErr:         status.Error(codes.PermissionDenied, "ohno"),
+			expectCode:     codes.PermissionDenied,
+			expectStatus:   otelcodes.Error,
+			expectErrEvent: true,
+		},
+		{
+			name:         "with propagated trace context",
+			md:           metadata.Pairs("traceparent", "00-01000000000000000000000000000000-0200000000000000-01"),
+			expectParent: true,
+			expectCode:   codes.OK,
+			expectStatus: otelcodes.Unset,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder.Reset()
+
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			m := middleware.WithTracing()
+			ctx, err := m.Preprocess(ctx, fakeFullMethod, nil)
+			require.NoError(t, err)
+			assert.True(t, trace.SpanFromContext(ctx).IsRecording())
+			m.Postprocess(ctx, fakeFullMethod, true, tt.rpcErr)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, "spire.api.server.foo.v1.Foo/SomeMethod", span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Contains(t, span.Attributes(), attribute.String("rpc.service", "spire.api.server.foo.v1.Foo"))
+			assert.Contains(t, span.Attributes(), attribute.String("rpc.method", "SomeMethod"))
+			assert.Contains(t, span.Attributes(), attribute.Int64("rpc.grpc.status_code", int64(tt.expectCode)))
+			assert.Equal(t, tt.expectStatus, span.Status().Code)
+			assert.Equal(t, tt.expectErrEvent, len(span.Events()) == 1)
+			if tt.expectParent {
+				assert.Equal(t, parentTraceID, span.SpanContext().TraceID())
+				assert.Equal(t, parentSpanID, span.Parent().SpanID())
+				assert.True(t, span.Parent().IsRemote())
+			} else {
+				assert.False(t, span.Parent().IsValid())
+			}
+		})
+	}
+}
+
+func setupTracing(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	oldProvider := otel.GetTracerProvider()
+	oldPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(oldProvider)
+		otel.SetTextMapPropagator(oldPropagator)
+	})
+	return recorder
+}
@@ -1,6 +1,7 @@
 package bundleutil
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/base64"
@@ -37,6 +38,19 @@ func createCertificate(t *testing.T, tmpl *x509.Certificate) *x509.Certificate {
 	return cert
 }
 
+func createCertificateWithKey(t *testing.T, key crypto.Signer) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(0),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(certDER)
+	require.NoError(t, err)
+	return cert
+}
+
 func x5c(cert *x509.Certificate) string {
 	return base64.StdEncoding.EncodeToString(cert.Raw)
 }
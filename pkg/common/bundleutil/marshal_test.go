@@ -1,12 +1,15 @@
 package bundleutil
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 )
 
@@ -155,3 +158,43 @@ func TestMarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalEd25519Keys(t *testing.T) {
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+
+	ed25519Key := testkey.NewEd25519(t)
+	ed25519CA := createCertificateWithKey(t, ed25519Key)
+
+	bundle := spiffebundle.New(trustDomain)
+	bundle.AddX509Authority(ed25519CA)
+	require.NoError(t, bundle.AddJWTAuthority("FOO", ed25519Key.Public()))
+
+	bundleBytes, err := Marshal(bundle)
+	require.NoError(t, err)
+	require.JSONEq(t, fmt.Sprintf(`{
+		"keys": [
+			{
+				"use": "x509-svid",
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"x": "%s",
+				"x5c": ["%s"]
+			},
+			{
+				"use": "jwt-svid",
+				"kid": "FOO",
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"x": "%s"
+			}
+		]
+	}`,
+		base64.RawURLEncoding.EncodeToString(ed25519Key.Public().(ed25519.PublicKey)), x5c(ed25519CA),
+		base64.RawURLEncoding.EncodeToString(ed25519Key.Public().(ed25519.PublicKey)),
+	), string(bundleBytes))
+
+	unmarshaled, err := Unmarshal(trustDomain, bundleBytes)
+	require.NoError(t, err)
+	require.Equal(t, bundle.X509Authorities(), unmarshaled.X509Authorities())
+	require.Equal(t, bundle.JWTAuthorities(), unmarshaled.JWTAuthorities())
+}
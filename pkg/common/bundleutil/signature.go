@@ -0,0 +1,68 @@
+package bundleutil
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/cryptosigner"
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/spire/pkg/common/cryptoutil"
+	"github.com/spiffe/spire/pkg/common/jwtsvid"
+)
+
+// SignBundle returns a detached JWS (RFC 7515, appendix F) over the given
+// bundle document, signed with a JWT authority of the trust domain.
+func SignBundle(doc []byte, signer crypto.Signer, kid string) (string, error) {
+	alg, err := cryptoutil.JoseAlgFromPublicKey(signer.Public())
+	if err != nil {
+		return "", fmt.Errorf("failed to determine JWT key algorithm: %w", err)
+	}
+
+	jwsSigner, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: alg,
+			Key: jose.JSONWebKey{
+				Key:   cryptosigner.Opaque(signer),
+				KeyID: kid,
+			},
+		},
+		new(jose.SignerOptions).WithContentType("application/json"),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to configure bundle signer: %w", err)
+	}
+
+	jws, err := jwsSigner.Sign(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign bundle: %w", err)
+	}
+	return jws.DetachedCompactSerialize()
+}
+
+// VerifyBundleSignature verifies a detached JWS over the given bundle
+// document. The JWS must be signed by one of the JWT authorities of the
+// trusted bundle.
+func VerifyBundleSignature(doc []byte, signature string, trusted *spiffebundle.Bundle) error {
+	jws, err := jose.ParseDetached(signature, doc, jwtsvid.AllowedSignatureAlgorithms)
+	if err != nil {
+		return fmt.Errorf("failed to parse bundle signature: %w", err)
+	}
+	if len(jws.Signatures) != 1 {
+		return errors.New("bundle signature must have exactly one signature")
+	}
+
+	kid := jws.Signatures[0].Header.KeyID
+	if kid == "" {
+		return errors.New("bundle signature is missing the key ID")
+	}
+	key, ok := trusted.FindJWTAuthority(kid)
+	if !ok {
+		return fmt.Errorf("bundle signature key ID %q is not a JWT authority of trust domain %q", kid, trusted.TrustDomain())
+	}
+	if _, err := jws.Verify(key); err != nil {
+		return fmt.Errorf("failed to verify bundle signature: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package bundleutil
+
+import (
+	"crypto"
+	"strings"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleSignature(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("domain.test")
+	ecKey := testkey.MustEC256()
+	edKey := testkey.MustEd25519()
+	otherKey := testkey.MustEC256()
+
+	trusted := spiffebundle.New(td)
+	require.NoError(t, trusted.AddJWTAuthority("EC", ecKey.Public()))
+	require.NoError(t, trusted.AddJWTAuthority("ED", edKey.Public()))
+
+	doc := []byte(`{"keys":[],"spiffe_refresh_hint":60}`)
+
+	for _, tt := range []struct {
+		name      string
+		signer    crypto.Signer
+		kid       string
+		doc       []byte
+		mutate    func(string) string
+		expectErr string
+	}{
+		{
+			name:   "EC key",
+			signer: ecKey,
+			kid:    "EC",
+		},
+		{
+			name:   "Ed25519 key",
+			signer: edKey,
+			kid:    "ED",
+		},
+		{
+			name:      "unknown key ID",
+			signer:    ecKey,
+			kid:       "UNKNOWN",
+			expectErr: `bundle signature key ID "UNKNOWN" is not a JWT authority of trust domain "domain.test"`,
+		},
+		{
+			name:      "missing key ID",
+			signer:    ecKey,
+			expectErr: "bundle signature is missing the key ID",
+		},
+		{
+			name:      "signed by another key",
+			signer:    otherKey,
+			kid:       "EC",
+			expectErr: "failed to verify bundle signature",
+		},
+		{
+			name:      "document modified",
+			signer:    ecKey,
+			kid:       "EC",
+			doc:       []byte(`{"keys":[],"spiffe_refresh_hint":1}`),
+			expectErr: "failed to verify bundle signature",
+		},
+		{
+			name:   "attached payload",
+			signer: ecKey,
+			kid:    "EC",
+			mutate: func(signature string) string {
+				parts := strings.Split(signature, ".")
+				return parts[0] + ".e30." + parts[2]
+			},
+			expectErr: "failed to parse bundle signature",
+		},
+		{
+			name:      "malformed signature",
+			signer:    ecKey,
+			kid:       "EC",
+			mutate:    func(string) string { return "not a JWS" },
+			expectErr: "failed to parse bundle signature",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := SignBundle(doc, tt.signer, tt.kid)
+			require.NoError(t, err)
+			require.Empty(t, strings.Split(signature, ".")[1], "payload should be detached")
+
+			if tt.mutate != nil {
+				signature = tt.mutate(signature)
+			}
+			verifyDoc := doc
+			if tt.doc != nil {
+				verifyDoc = tt.doc
+			}
+
+			err = VerifyBundleSignature(verifyDoc, signature, trusted)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
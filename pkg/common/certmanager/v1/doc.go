@@ -2,6 +2,8 @@ package v1
 
 // This package contains API code copied from the cert-manager project:
 // https://github.com/jetstack/cert-manager/tree/release-1.3/pkg/apis
+// It is used by the cert-manager UpstreamAuthority plugin and by the server
+// Kubernetes signer.
 
 // This is required for preventing go mod dependency issues when importing
 // https://github.com/jetstack/cert-manager, forcing Kubernetes version bumps
@@ -3,6 +3,7 @@ package cryptoutil
 import (
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"fmt"
 
@@ -33,6 +34,8 @@ func PublicKeyEqual(a, b crypto.PublicKey) (bool, error) {
 	case *ecdsa.PublicKey:
 		ecdsaPublicKey, ok := b.(*ecdsa.PublicKey)
 		return ok && ECDSAPublicKeyEqual(a, ecdsaPublicKey), nil
+	case ed25519.PublicKey:
+		return a.Equal(b), nil
 	default:
 		return false, fmt.Errorf("unsupported public key type %T", a)
 	}
@@ -46,6 +49,8 @@ func KeyMatches(privateKey crypto.PrivateKey, publicKey crypto.PublicKey) (bool,
 	case *ecdsa.PrivateKey:
 		ecdsaPublicKey, ok := publicKey.(*ecdsa.PublicKey)
 		return ok && ECDSAKeyMatches(privateKey, ecdsaPublicKey), nil
+	case ed25519.PrivateKey:
+		return privateKey.Public().(ed25519.PublicKey).Equal(publicKey), nil
 	default:
 		return false, fmt.Errorf("unsupported private key type %T", privateKey)
 	}
@@ -60,6 +65,8 @@ func JoseAlgFromPublicKey(publicKey any) (jose.SignatureAlgorithm, error) {
 			return "", fmt.Errorf("unsupported RSA key size: %d", publicKey.Size())
 		}
 		alg = jose.RS256
+	case ed25519.PublicKey:
+		alg = jose.EdDSA
 	case *ecdsa.PublicKey:
 		params := publicKey.Params()
 		switch params.BitSize {
@@ -1,6 +1,7 @@
 package cryptoutil
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -38,6 +39,40 @@ func TestJoseAlgFromPublicKey(t *testing.T) {
 	algo, err = JoseAlgFromPublicKey(genEC(elliptic.P521()).Public())
 	require.EqualError(t, err, "unable to determine signature algorithm for EC public key size 521")
 	require.Empty(t, algo)
+
+	algo, err = JoseAlgFromPublicKey(testkey.NewEd25519(t).Public())
+	require.NoError(t, err)
+	require.Equal(t, algo, jose.EdDSA)
+}
+
+func TestKeyMatches(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		key   crypto.Signer
+		other crypto.Signer
+	}{
+		{name: "rsa", key: testkey.NewRSA2048(t), other: testkey.NewRSA2048(t)},
+		{name: "ecdsa", key: testkey.NewEC256(t), other: testkey.NewEC256(t)},
+		{name: "ed25519", key: testkey.NewEd25519(t), other: testkey.NewEd25519(t)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := KeyMatches(tt.key, tt.key.Public())
+			require.NoError(t, err)
+			require.True(t, matches)
+
+			matches, err = KeyMatches(tt.key, tt.other.Public())
+			require.NoError(t, err)
+			require.False(t, matches)
+
+			equal, err := PublicKeyEqual(tt.key.Public(), tt.key.Public())
+			require.NoError(t, err)
+			require.True(t, equal)
+
+			equal, err = PublicKeyEqual(tt.key.Public(), tt.other.Public())
+			require.NoError(t, err)
+			require.False(t, equal)
+		})
+	}
 }
 
 func genRSA(bits int) *rsa.PrivateKey {
@@ -0,0 +1,79 @@
+// Package entrydryrun compares entries to report the changes of entry dry
+// runs.
+package entrydryrun
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+// FieldDiff is a change to a single entry field.
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff returns the field-level differences between the old and new entry.
+// The old entry may be nil, in which case every field set on the new entry
+// is returned. The entry ID, revision number and creation time are not
+// compared.
+func Diff(oldEntry, newEntry *types.Entry) []FieldDiff {
+	if oldEntry == nil {
+		oldEntry = &types.Entry{}
+	}
+
+	var diffs []FieldDiff
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			diffs = append(diffs, FieldDiff{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("spiffe_id", formatID(oldEntry.SpiffeId), formatID(newEntry.SpiffeId))
+	add("parent_id", formatID(oldEntry.ParentId), formatID(newEntry.ParentId))
+	add("selectors", formatSelectors(oldEntry.Selectors), formatSelectors(newEntry.Selectors))
+	add("federates_with", formatSet(oldEntry.FederatesWith), formatSet(newEntry.FederatesWith))
+	add("admin", strconv.FormatBool(oldEntry.Admin), strconv.FormatBool(newEntry.Admin))
+	add("downstream", strconv.FormatBool(oldEntry.Downstream), strconv.FormatBool(newEntry.Downstream))
+	add("expires_at", strconv.FormatInt(oldEntry.ExpiresAt, 10), strconv.FormatInt(newEntry.ExpiresAt, 10))
+	// The order of DNS names is significant since the first one is used as
+	// the X509-SVID common name.
+	add("dns_names", formatList(oldEntry.DnsNames), formatList(newEntry.DnsNames))
+	add("store_svid", strconv.FormatBool(oldEntry.StoreSvid), strconv.FormatBool(newEntry.StoreSvid))
+	add("x509_svid_ttl", strconv.FormatInt(int64(oldEntry.X509SvidTtl), 10), strconv.FormatInt(int64(newEntry.X509SvidTtl), 10))
+	add("jwt_svid_ttl", strconv.FormatInt(int64(oldEntry.JwtSvidTtl), 10), strconv.FormatInt(int64(newEntry.JwtSvidTtl), 10))
+	add("hint", oldEntry.Hint, newEntry.Hint)
+	return diffs
+}
+
+func formatID(id *types.SPIFFEID) string {
+	if id == nil {
+		return ""
+	}
+	return "spiffe://" + id.TrustDomain + id.Path
+}
+
+func formatSelectors(selectors []*types.Selector) string {
+	values := make([]string, 0, len(selectors))
+	for _, selector := range selectors {
+		values = append(values, selector.Type+":"+selector.Value)
+	}
+	return formatSet(values)
+}
+
+func formatSet(values []string) string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return formatList(slices.Compact(values))
+}
+
+func formatList(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
@@ -0,0 +1,71 @@
+package entrydryrun_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrydryrun"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	entry := &types.Entry{
+		Id:       "id",
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+		Selectors: []*types.Selector{
+			{Type: "unix", Value: "uid:1000"},
+			{Type: "unix", Value: "gid:1000"},
+		},
+		FederatesWith:  []string{"b.org", "a.org"},
+		DnsNames:       []string{"b.example.org", "a.example.org"},
+		X509SvidTtl:    60,
+		RevisionNumber: 1,
+	}
+
+	t.Run("create", func(t *testing.T) {
+		assert.Equal(t, []entrydryrun.FieldDiff{
+			{Field: "spiffe_id", New: "spiffe://example.org/workload"},
+			{Field: "parent_id", New: "spiffe://example.org/agent"},
+			{Field: "selectors", New: "[unix:gid:1000, unix:uid:1000]"},
+			{Field: "federates_with", New: "[a.org, b.org]"},
+			{Field: "dns_names", New: "[b.example.org, a.example.org]"},
+			{Field: "x509_svid_ttl", Old: "0", New: "60"},
+		}, entrydryrun.Diff(nil, entry))
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		reordered := &types.Entry{
+			Id:       "other",
+			SpiffeId: entry.SpiffeId,
+			ParentId: entry.ParentId,
+			Selectors: []*types.Selector{
+				{Type: "unix", Value: "gid:1000"},
+				{Type: "unix", Value: "uid:1000"},
+			},
+			FederatesWith:  []string{"a.org", "b.org"},
+			DnsNames:       entry.DnsNames,
+			X509SvidTtl:    60,
+			RevisionNumber: 2,
+		}
+		assert.Empty(t, entrydryrun.Diff(entry, reordered))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated := &types.Entry{
+			SpiffeId:  entry.SpiffeId,
+			ParentId:  entry.ParentId,
+			Selectors: entry.Selectors,
+			DnsNames:  []string{"a.example.org", "b.example.org"},
+			Admin:     true,
+			Hint:      "hint",
+		}
+		assert.Equal(t, []entrydryrun.FieldDiff{
+			{Field: "federates_with", Old: "[a.org, b.org]"},
+			{Field: "admin", Old: "false", New: "true"},
+			{Field: "dns_names", Old: "[b.example.org, a.example.org]", New: "[a.example.org, b.example.org]"},
+			{Field: "x509_svid_ttl", Old: "60", New: "0"},
+			{Field: "hint", New: "hint"},
+		}, entrydryrun.Diff(entry, updated))
+	})
+}
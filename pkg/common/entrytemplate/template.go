@@ -0,0 +1,259 @@
+// Package entrytemplate implements registration entry templates, i.e.
+// registration entries whose SPIFFE ID contains placeholders that are
+// expanded by the server for each agent the entry is authorized for, using
+// the selectors of the agent and of the entry.
+//
+// A placeholder has the form {{<type>:<key>}} and can only appear in the
+// path of the SPIFFE ID. It expands to the value of the selector of the
+// given type whose value is prefixed by "<key>:". For example, the template
+// spiffe://example.org/cluster/{{k8s_psat:cluster}}/ns/{{k8s:ns}} expands to
+// spiffe://example.org/cluster/prod/ns/default for an entry with the
+// k8s:ns:default selector authorized for an agent with the
+// k8s_psat:cluster:prod selector.
+package entrytemplate
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+const (
+	placeholderStart = "{{"
+	placeholderEnd   = "}}"
+
+	// materializedSeparator separates the template entry ID from the
+	// expanded SPIFFE ID in a materialized entry ID. It is not a valid
+	// entry ID character, so materialized entry IDs never conflict with
+	// regular entry IDs.
+	materializedSeparator = "#"
+
+	// sampleValue is used in place of placeholders to validate templates.
+	sampleValue = "x"
+)
+
+var (
+	// valueRE matches the values a placeholder can expand to. Values are
+	// restricted to characters that are valid within a single SPIFFE ID path
+	// segment so that an expansion cannot add path segments.
+	valueRE = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+	// ErrNoMatchingSelector is returned when there is no selector to expand
+	// a placeholder with.
+	ErrNoMatchingSelector = errors.New("no matching selector")
+)
+
+// Template is a SPIFFE ID template.
+type Template struct {
+	td    spiffeid.TrustDomain
+	path  string
+	parts []part
+}
+
+type part struct {
+	literal string
+
+	// selectorType and key are set for placeholders
+	selectorType string
+	key          string
+}
+
+func (p part) isPlaceholder() bool {
+	return p.selectorType != ""
+}
+
+// IsTemplate returns true if the given SPIFFE ID, or SPIFFE ID path,
+// contains placeholders.
+func IsTemplate(s string) bool {
+	return strings.Contains(s, placeholderStart)
+}
+
+// Parse parses a SPIFFE ID template, e.g.
+// "spiffe://example.org/ns/{{k8s:ns}}".
+func Parse(s string) (*Template, error) {
+	rest, ok := strings.CutPrefix(s, "spiffe://")
+	if !ok {
+		return nil, errors.New("scheme is missing or invalid")
+	}
+
+	tdName, path, ok := strings.Cut(rest, "/")
+	if !ok {
+		return nil, errors.New("template path is empty")
+	}
+
+	td, err := spiffeid.TrustDomainFromString(tdName)
+	if err != nil {
+		return nil, err
+	}
+	return FromPath(td, "/"+path)
+}
+
+// FromPath parses a SPIFFE ID template from the trust domain and the
+// template path, e.g. "/ns/{{k8s:ns}}".
+func FromPath(td spiffeid.TrustDomain, path string) (*Template, error) {
+	if td.IsZero() {
+		return nil, errors.New("trust domain is missing")
+	}
+
+	var parts []part
+	var sample strings.Builder
+
+	rest := path
+	placeholders := 0
+	for rest != "" {
+		start := strings.Index(rest, placeholderStart)
+		if start < 0 {
+			parts = append(parts, part{literal: rest})
+			sample.WriteString(rest)
+			break
+		}
+		if start > 0 {
+			literal := rest[:start]
+			parts = append(parts, part{literal: literal})
+			sample.WriteString(literal)
+		}
+		rest = rest[start+len(placeholderStart):]
+
+		end := strings.Index(rest, placeholderEnd)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in template path %q", path)
+		}
+		placeholder := rest[:end]
+		rest = rest[end+len(placeholderEnd):]
+
+		selectorType, key, ok := strings.Cut(placeholder, ":")
+		switch {
+		case !ok || selectorType == "" || key == "":
+			return nil, fmt.Errorf("invalid placeholder %q: expected {{<type>:<key>}}", placeholder)
+		case strings.ContainsAny(placeholder, "{}"):
+			return nil, fmt.Errorf("invalid placeholder %q: nested braces are not allowed", placeholder)
+		}
+		parts = append(parts, part{selectorType: selectorType, key: key})
+		sample.WriteString(sampleValue)
+		placeholders++
+	}
+
+	if placeholders == 0 {
+		return nil, fmt.Errorf("template path %q has no placeholders", path)
+	}
+
+	// Validate the template by expanding it with a sample value. Since
+	// placeholder values are restricted to a single path segment, every
+	// expansion of a valid template that does not yield a dot segment is
+	// also valid.
+	if _, err := spiffeid.FromPath(td, sample.String()); err != nil {
+		return nil, fmt.Errorf("invalid template path %q: %w", path, err)
+	}
+
+	return &Template{
+		td:    td,
+		path:  path,
+		parts: parts,
+	}, nil
+}
+
+// TrustDomain returns the trust domain of the template.
+func (t *Template) TrustDomain() spiffeid.TrustDomain {
+	return t.td
+}
+
+// Path returns the template path.
+func (t *Template) Path() string {
+	return t.path
+}
+
+// String returns the template in its string form, e.g.
+// "spiffe://example.org/ns/{{k8s:ns}}".
+func (t *Template) String() string {
+	return t.td.IDString() + t.path
+}
+
+// Expand expands the template using the given selectors. If there is no
+// selector for one of the placeholders, ErrNoMatchingSelector is returned.
+func (t *Template) Expand(selectors []*common.Selector) (spiffeid.ID, error) {
+	var path strings.Builder
+	for _, p := range t.parts {
+		if !p.isPlaceholder() {
+			path.WriteString(p.literal)
+			continue
+		}
+
+		value, err := p.expand(selectors)
+		if err != nil {
+			return spiffeid.ID{}, err
+		}
+		path.WriteString(value)
+	}
+	return spiffeid.FromPath(t.td, path.String())
+}
+
+func (p part) expand(selectors []*common.Selector) (string, error) {
+	prefix := p.key + ":"
+
+	var value string
+	for _, s := range selectors {
+		if s.Type != p.selectorType {
+			continue
+		}
+		v, ok := strings.CutPrefix(s.Value, prefix)
+		if !ok {
+			continue
+		}
+		if value != "" && value != v {
+			return "", fmt.Errorf("ambiguous value for placeholder {{%s:%s}}", p.selectorType, p.key)
+		}
+		value = v
+	}
+
+	switch {
+	case value == "":
+		return "", fmt.Errorf("%w for placeholder {{%s:%s}}", ErrNoMatchingSelector, p.selectorType, p.key)
+	case !valueRE.MatchString(value):
+		return "", fmt.Errorf("invalid value %q for placeholder {{%s:%s}}", value, p.selectorType, p.key)
+	}
+	return value, nil
+}
+
+// ExpandEntry expands the SPIFFE ID of a template entry for an agent, using
+// the node selectors of the agent and the selectors of the entry. Workload
+// selectors are only known to the agent, so they cannot be used: the server
+// must be able to derive every SPIFFE ID it signs on its own. It returns
+// false if the entry is not a valid template entry or the template does not
+// expand for the agent, in which case the entry does not apply to the agent.
+func ExpandEntry(entry *types.Entry, nodeSelectors []*types.Selector) (spiffeid.ID, bool) {
+	td, err := spiffeid.TrustDomainFromString(entry.GetSpiffeId().GetTrustDomain())
+	if err != nil {
+		return spiffeid.ID{}, false
+	}
+	tmpl, err := FromPath(td, entry.GetSpiffeId().GetPath())
+	if err != nil {
+		return spiffeid.ID{}, false
+	}
+
+	selectors := make([]*common.Selector, 0, len(nodeSelectors)+len(entry.Selectors))
+	for _, s := range nodeSelectors {
+		selectors = append(selectors, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+	for _, s := range entry.Selectors {
+		selectors = append(selectors, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+
+	id, err := tmpl.Expand(selectors)
+	if err != nil {
+		return spiffeid.ID{}, false
+	}
+	return id, true
+}
+
+// MaterializedEntryID returns the ID of the entry materialized from the
+// template entry for the given SPIFFE ID. The server hands materialized
+// entries to the agent in place of the template entry, so that each
+// expansion is cached, and its SVIDs requested, separately.
+func MaterializedEntryID(entryID string, id spiffeid.ID) string {
+	return entryID + materializedSeparator + id.String()
+}
@@ -0,0 +1,262 @@
+package entrytemplate_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var td = spiffeid.RequireTrustDomainFromString("domain.test")
+
+func TestIsTemplate(t *testing.T) {
+	assert.True(t, entrytemplate.IsTemplate("spiffe://domain.test/ns/{{k8s:ns}}"))
+	assert.True(t, entrytemplate.IsTemplate("/ns/{{k8s:ns}}"))
+	assert.False(t, entrytemplate.IsTemplate("spiffe://domain.test/ns/default"))
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		template  string
+		expectErr string
+	}{
+		{
+			name:     "single placeholder",
+			template: "spiffe://domain.test/ns/{{k8s:ns}}",
+		},
+		{
+			name:     "multiple placeholders",
+			template: "spiffe://domain.test/ns/{{k8s:ns}}/sa/{{k8s:sa}}",
+		},
+		{
+			name:     "placeholder within segment",
+			template: "spiffe://domain.test/uid-{{unix:uid}}.{{unix:gid}}",
+		},
+		{
+			name:     "key with colon",
+			template: "spiffe://domain.test/{{k8s:pod-label:app}}",
+		},
+		{
+			name:      "invalid scheme",
+			template:  "http://domain.test/ns/{{k8s:ns}}",
+			expectErr: "scheme is missing or invalid",
+		},
+		{
+			name:      "missing path",
+			template:  "spiffe://domain.test",
+			expectErr: "template path is empty",
+		},
+		{
+			name:      "invalid trust domain",
+			template:  "spiffe://DOMAIN.test/ns/{{k8s:ns}}",
+			expectErr: "trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores",
+		},
+		{
+			name:      "no placeholders",
+			template:  "spiffe://domain.test/ns/default",
+			expectErr: `template path "/ns/default" has no placeholders`,
+		},
+		{
+			name:      "unterminated placeholder",
+			template:  "spiffe://domain.test/ns/{{k8s:ns",
+			expectErr: `unterminated placeholder in template path "/ns/{{k8s:ns"`,
+		},
+		{
+			name:      "placeholder without key",
+			template:  "spiffe://domain.test/ns/{{k8s}}",
+			expectErr: `invalid placeholder "k8s": expected {{<type>:<key>}}`,
+		},
+		{
+			name:      "placeholder with empty type",
+			template:  "spiffe://domain.test/ns/{{:ns}}",
+			expectErr: `invalid placeholder ":ns": expected {{<type>:<key>}}`,
+		},
+		{
+			name:      "nested placeholder",
+			template:  "spiffe://domain.test/ns/{{k8s:{{ns}}}}",
+			expectErr: `invalid placeholder "k8s:{{ns": nested braces are not allowed`,
+		},
+		{
+			name:      "invalid literal",
+			template:  "spiffe://domain.test/ns//{{k8s:ns}}",
+			expectErr: `invalid template path "/ns//{{k8s:ns}}": path cannot contain empty segments`,
+		},
+		{
+			name:      "trailing slash",
+			template:  "spiffe://domain.test/ns/{{k8s:ns}}/",
+			expectErr: `invalid template path "/ns/{{k8s:ns}}/": path cannot have a trailing slash`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := entrytemplate.Parse(tt.template)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.template, tmpl.String())
+			assert.Equal(t, td, tmpl.TrustDomain())
+		})
+	}
+}
+
+func TestFromPath(t *testing.T) {
+	tmpl, err := entrytemplate.FromPath(td, "/ns/{{k8s:ns}}")
+	require.NoError(t, err)
+	assert.Equal(t, "/ns/{{k8s:ns}}", tmpl.Path())
+	assert.Equal(t, "spiffe://domain.test/ns/{{k8s:ns}}", tmpl.String())
+
+	_, err = entrytemplate.FromPath(spiffeid.TrustDomain{}, "/ns/{{k8s:ns}}")
+	require.EqualError(t, err, "trust domain is missing")
+}
+
+func TestExpand(t *testing.T) {
+	tmpl, err := entrytemplate.Parse("spiffe://domain.test/ns/{{k8s:ns}}/sa/{{k8s:sa}}")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		selectors []*common.Selector
+		expectID  string
+		expectErr string
+	}{
+		{
+			name: "success",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:default"},
+				{Type: "k8s", Value: "sa:web"},
+				{Type: "k8s", Value: "pod-name:web-1234"},
+				{Type: "unix", Value: "ns:other"},
+			},
+			expectID: "spiffe://domain.test/ns/default/sa/web",
+		},
+		{
+			name: "same value more than once",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:default"},
+				{Type: "k8s", Value: "ns:default"},
+				{Type: "k8s", Value: "sa:web"},
+			},
+			expectID: "spiffe://domain.test/ns/default/sa/web",
+		},
+		{
+			name: "missing selector",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:default"},
+			},
+			expectErr: "no matching selector for placeholder {{k8s:sa}}",
+		},
+		{
+			name: "ambiguous selector",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:default"},
+				{Type: "k8s", Value: "ns:other"},
+				{Type: "k8s", Value: "sa:web"},
+			},
+			expectErr: "ambiguous value for placeholder {{k8s:ns}}",
+		},
+		{
+			name: "value with slash",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:default/sa/admin"},
+				{Type: "k8s", Value: "sa:web"},
+			},
+			expectErr: `invalid value "default/sa/admin" for placeholder {{k8s:ns}}`,
+		},
+		{
+			name: "dot segment",
+			selectors: []*common.Selector{
+				{Type: "k8s", Value: "ns:.."},
+				{Type: "k8s", Value: "sa:web"},
+			},
+			expectErr: "path cannot contain dot segments",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tmpl.Expand(tt.selectors)
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectID, id.String())
+		})
+	}
+
+	_, err = tmpl.Expand(nil)
+	require.ErrorIs(t, err, entrytemplate.ErrNoMatchingSelector)
+}
+
+func TestExpandEntry(t *testing.T) {
+	nodeSelectors := []*types.Selector{
+		{Type: "k8s_psat", Value: "cluster:prod"},
+		{Type: "k8s_psat", Value: "agent_node_name:node-1"},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		entry         *types.Entry
+		nodeSelectors []*types.Selector
+		expectID      string
+	}{
+		{
+			name: "node and entry selectors",
+			entry: &types.Entry{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/cluster/{{k8s_psat:cluster}}/ns/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+			nodeSelectors: nodeSelectors,
+			expectID:      "spiffe://domain.test/cluster/prod/ns/default",
+		},
+		{
+			name: "no matching selector",
+			entry: &types.Entry{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/ns/{{k8s:ns}}/sa/{{k8s:sa}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+			nodeSelectors: nodeSelectors,
+		},
+		{
+			name: "ambiguous node and entry selectors",
+			entry: &types.Entry{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/cluster/{{k8s_psat:cluster}}"},
+				Selectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:dev"}},
+			},
+			nodeSelectors: nodeSelectors,
+		},
+		{
+			name: "no node selectors",
+			entry: &types.Entry{
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/cluster/{{k8s_psat:cluster}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+		},
+		{
+			name: "not a template",
+			entry: &types.Entry{
+				SpiffeId: &types.SPIFFEID{TrustDomain: "domain.test", Path: "/workload"},
+			},
+			nodeSelectors: nodeSelectors,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := entrytemplate.ExpandEntry(tt.entry, tt.nodeSelectors)
+			if tt.expectID == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.expectID, id.String())
+		})
+	}
+}
+
+func TestMaterializedEntryID(t *testing.T) {
+	id := spiffeid.RequireFromString("spiffe://domain.test/ns/default")
+	assert.Equal(t, "entry-1#spiffe://domain.test/ns/default", entrytemplate.MaterializedEntryID("entry-1", id))
+}
@@ -12,4 +12,5 @@ var AllowedSignatureAlgorithms = []jose.SignatureAlgorithm{
 	jose.PS256,
 	jose.PS384,
 	jose.PS512,
+	jose.EdDSA,
 }
@@ -27,6 +27,7 @@ var (
 	ec384Key   = testkey.MustEC384()
 	rsa2048Key = testkey.MustRSA2048()
 	rsa4096Key = testkey.MustRSA4096()
+	ed25519Key = testkey.MustEd25519()
 )
 
 func TestToken(t *testing.T) {
@@ -47,6 +48,7 @@ func (s *TokenSuite) SetupTest() {
 			"ec384Key":   ec384Key.Public(),
 			"rsa2048Key": rsa2048Key.Public(),
 			"rsa4096Key": rsa4096Key.Public(),
+			"ed25519Key": ed25519Key.Public(),
 		},
 	})
 	s.clock = clock.NewMock(s.T())
@@ -73,6 +75,10 @@ func (s *TokenSuite) TestDifferentKeys() {
 			kid: "rsa4096Key",
 			key: rsa4096Key,
 		},
+		{
+			kid: "ed25519Key",
+			key: ed25519Key,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -101,7 +107,7 @@ func (s *TokenSuite) TestValidateBadAlgorithm() {
 	token := s.signToken(jose.HS256, key, jwt.Claims{})
 
 	spiffeID, claims, err := ValidateToken(ctx, token, s.bundle, fakeAudience[0:1])
-	s.Require().EqualError(err, `unable to parse JWT token: unexpected signature algorithm "HS256"; expected ["ES256" "ES384" "ES512" "RS256" "RS384" "RS512" "PS256" "PS384" "PS512"]`)
+	s.Require().EqualError(err, `unable to parse JWT token: unexpected signature algorithm "HS256"; expected ["ES256" "ES384" "ES512" "RS256" "RS384" "RS512" "PS256" "PS384" "PS512" "EdDSA"]`)
 	s.Require().Empty(spiffeID)
 	s.Require().Nil(claims)
 }
@@ -0,0 +1,212 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha1" //nolint: gosec // SHA-1 is mandated by the JKS format
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// The JKS format is undocumented. The encoding below follows the one of
+// sun.security.provider.JavaKeyStore and sun.security.provider.KeyProtector.
+const (
+	jksMagic   = 0xfeedfeed
+	jksVersion = 2
+
+	jksPrivateKeyTag  = 1
+	jksTrustedCertTag = 2
+
+	jksCertType = "X.509"
+
+	// jksIntegritySalt is mixed with the password to compute the keystore
+	// integrity digest.
+	jksIntegritySalt = "Mighty Aphrodite"
+
+	jksSaltLen = sha1.Size
+)
+
+var (
+	// oidJKSKeyProtector identifies the proprietary algorithm used by JKS to
+	// protect private keys.
+	oidJKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+	// jksNow and jksRand are test hooks
+	jksNow            = time.Now
+	jksRand io.Reader = rand.Reader
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+func encodeJKSKeyStore(alias string, key crypto.PrivateKey, chain []*x509.Certificate, password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("JKS keystores require a password")
+	}
+
+	plainKey, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	protectedKey, err := protectJKSKey(plainKey, jksPassword(password))
+	if err != nil {
+		return nil, err
+	}
+
+	w := newJKSWriter(1)
+	w.writeUint32(jksPrivateKeyTag)
+	if err := w.writeEntryHeader(alias); err != nil {
+		return nil, err
+	}
+	w.writeBytes(protectedKey)
+	w.writeUint32(uint32(len(chain)))
+	for _, cert := range chain {
+		if err := w.writeCert(cert); err != nil {
+			return nil, err
+		}
+	}
+	return w.finish(password), nil
+}
+
+func encodeJKSTrustStore(entries []TrustStoreEntry, password string) ([]byte, error) {
+	if password == "" {
+		return nil, errors.New("JKS truststores require a password")
+	}
+
+	w := newJKSWriter(len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		alias := strings.ToLower(entry.Alias)
+		if seen[alias] {
+			return nil, fmt.Errorf("duplicate truststore alias %q", alias)
+		}
+		seen[alias] = true
+
+		w.writeUint32(jksTrustedCertTag)
+		if err := w.writeEntryHeader(alias); err != nil {
+			return nil, err
+		}
+		if err := w.writeCert(entry.Cert); err != nil {
+			return nil, err
+		}
+	}
+	return w.finish(password), nil
+}
+
+// protectJKSKey encrypts the PKCS#8 encoded key with the JKS key protector
+// and wraps the result in an EncryptedPrivateKeyInfo structure. The key is
+// XORed with a SHA-1 based keystream derived from the password and a random
+// salt, and followed by a SHA-1 digest of the password and the plain key.
+func protectJKSKey(plainKey, password []byte) ([]byte, error) {
+	salt := make([]byte, jksSaltLen)
+	if _, err := io.ReadFull(jksRand, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	encrypted := make([]byte, 0, jksSaltLen+len(plainKey)+sha1.Size)
+	encrypted = append(encrypted, salt...)
+
+	digest := salt
+	for offset := 0; offset < len(plainKey); offset += sha1.Size {
+		digest = sha1Sum(password, digest)
+		for i := 0; i < sha1.Size && offset+i < len(plainKey); i++ {
+			encrypted = append(encrypted, plainKey[offset+i]^digest[i])
+		}
+	}
+	encrypted = append(encrypted, sha1Sum(password, plainKey)...)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidJKSKeyProtector,
+			Parameters: asn1.NullRawValue,
+		},
+		EncryptedData: encrypted,
+	})
+}
+
+// jksPassword returns the password as the big-endian UTF-16 bytes used by
+// JKS.
+func jksPassword(password string) []byte {
+	var b []byte
+	for _, c := range utf16.Encode([]rune(password)) {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	return b
+}
+
+func sha1Sum(parts ...[]byte) []byte {
+	h := sha1.New() //nolint: gosec // SHA-1 is mandated by the JKS format
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+type jksWriter struct {
+	buf       bytes.Buffer
+	timestamp uint64
+}
+
+func newJKSWriter(count int) *jksWriter {
+	w := &jksWriter{
+		timestamp: uint64(jksNow().UnixMilli()), //nolint: gosec // timestamps are positive
+	}
+	w.writeUint32(jksMagic)
+	w.writeUint32(jksVersion)
+	w.writeUint32(uint32(count)) //nolint: gosec // the number of entries is small
+	return w
+}
+
+func (w *jksWriter) writeUint32(v uint32) {
+	_ = binary.Write(&w.buf, binary.BigEndian, v)
+}
+
+func (w *jksWriter) writeUTF(s string) error {
+	if len(s) > 0xffff {
+		return fmt.Errorf("string %q is too long", s)
+	}
+	_ = binary.Write(&w.buf, binary.BigEndian, uint16(len(s)))
+	w.buf.WriteString(s)
+	return nil
+}
+
+func (w *jksWriter) writeBytes(b []byte) {
+	w.writeUint32(uint32(len(b))) //nolint: gosec // keys and certificates are small
+	w.buf.Write(b)
+}
+
+func (w *jksWriter) writeEntryHeader(alias string) error {
+	if alias == "" {
+		return errors.New("alias is required")
+	}
+	if err := w.writeUTF(alias); err != nil {
+		return err
+	}
+	_ = binary.Write(&w.buf, binary.BigEndian, w.timestamp)
+	return nil
+}
+
+func (w *jksWriter) writeCert(cert *x509.Certificate) error {
+	if err := w.writeUTF(jksCertType); err != nil {
+		return err
+	}
+	w.writeBytes(cert.Raw)
+	return nil
+}
+
+// finish appends the integrity digest and returns the encoded keystore.
+func (w *jksWriter) finish(password string) []byte {
+	data := w.buf.Bytes()
+	return append(data, sha1Sum(jksPassword(password), []byte(jksIntegritySalt), data)...)
+}
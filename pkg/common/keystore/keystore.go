@@ -0,0 +1,79 @@
+// Package keystore encodes X509-SVIDs and trust bundles as Java keystores
+// and truststores, in either the PKCS#12 or the JKS format.
+package keystore
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	// FormatPKCS12 is the PKCS#12 format, the default keystore type since
+	// Java 9.
+	FormatPKCS12 = "pkcs12"
+
+	// FormatJKS is the proprietary Java KeyStore format.
+	FormatJKS = "jks"
+)
+
+// TrustStoreEntry is a trusted certificate of a truststore.
+type TrustStoreEntry struct {
+	// Alias is the name of the entry. JKS aliases are case-insensitive and
+	// are lowercased.
+	Alias string
+
+	// Cert is the trusted certificate.
+	Cert *x509.Certificate
+}
+
+// ValidateFormat returns an error if the format is not supported.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatPKCS12, FormatJKS:
+		return nil
+	default:
+		return fmt.Errorf("unsupported keystore format %q: expected %q or %q", format, FormatPKCS12, FormatJKS)
+	}
+}
+
+// EncodeKeyStore encodes a keystore holding the private key and certificate
+// chain under the given alias, protected with the given password.
+func EncodeKeyStore(format, alias string, key crypto.PrivateKey, chain []*x509.Certificate, password string) ([]byte, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("certificate chain is empty")
+	}
+
+	switch format {
+	case FormatPKCS12:
+		return pkcs12.Modern.Encode(key, chain[0], chain[1:], password)
+	case FormatJKS:
+		return encodeJKSKeyStore(strings.ToLower(alias), key, chain, password)
+	default:
+		return nil, ValidateFormat(format)
+	}
+}
+
+// EncodeTrustStore encodes a truststore holding the given trusted
+// certificates, protected with the given password.
+func EncodeTrustStore(format string, entries []TrustStoreEntry, password string) ([]byte, error) {
+	switch format {
+	case FormatPKCS12:
+		pkcs12Entries := make([]pkcs12.TrustStoreEntry, 0, len(entries))
+		for _, entry := range entries {
+			pkcs12Entries = append(pkcs12Entries, pkcs12.TrustStoreEntry{
+				Cert:         entry.Cert,
+				FriendlyName: entry.Alias,
+			})
+		}
+		return pkcs12.Modern.EncodeTrustStoreEntries(pkcs12Entries, password)
+	case FormatJKS:
+		return encodeJKSTrustStore(entries, password)
+	default:
+		return nil, ValidateFormat(format)
+	}
+}
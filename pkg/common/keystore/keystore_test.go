@@ -0,0 +1,198 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var td = spiffeid.RequireTrustDomainFromString("domain.test")
+
+func TestValidateFormat(t *testing.T) {
+	require.NoError(t, ValidateFormat(FormatPKCS12))
+	require.NoError(t, ValidateFormat(FormatJKS))
+	require.EqualError(t, ValidateFormat("pem"), `unsupported keystore format "pem": expected "pkcs12" or "jks"`)
+}
+
+func TestEncodePKCS12(t *testing.T) {
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromPath(td, "/workload"))
+
+	keyStore, err := EncodeKeyStore(FormatPKCS12, "svid", svid.PrivateKey, svid.Certificates, "password")
+	require.NoError(t, err)
+	key, cert, caCerts, err := pkcs12.DecodeChain(keyStore, "password")
+	require.NoError(t, err)
+	require.Equal(t, svid.PrivateKey, key)
+	require.Equal(t, svid.Certificates[0], cert)
+	require.Empty(t, caCerts)
+
+	trustStore, err := EncodeTrustStore(FormatPKCS12, []TrustStoreEntry{
+		{Alias: "domain.test-0", Cert: ca.X509Authorities()[0]},
+	}, "password")
+	require.NoError(t, err)
+	certs, err := pkcs12.DecodeTrustStore(trustStore, "password")
+	require.NoError(t, err)
+	require.Equal(t, ca.X509Authorities(), certs)
+}
+
+func TestEncodeJKSKeyStore(t *testing.T) {
+	setJKSHooks(t)
+	ca := testca.New(t, td)
+	svid := ca.ChildCA().CreateX509SVID(spiffeid.RequireFromPath(td, "/workload"))
+
+	keyStore, err := EncodeKeyStore(FormatJKS, "SVID", svid.PrivateKey, svid.Certificates, "password")
+	require.NoError(t, err)
+
+	entries := decodeJKS(t, keyStore, "password")
+	require.Len(t, entries, 1)
+	require.Equal(t, jksPrivateKeyTag, entries[0].tag)
+	require.Equal(t, "svid", entries[0].alias)
+	require.Equal(t, svid.Certificates, entries[0].certs)
+
+	expectedKey, err := x509.MarshalPKCS8PrivateKey(svid.PrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, expectedKey, entries[0].key)
+
+	_, err = EncodeKeyStore(FormatJKS, "svid", svid.PrivateKey, svid.Certificates, "")
+	require.EqualError(t, err, "JKS keystores require a password")
+
+	_, err = EncodeKeyStore(FormatJKS, "svid", svid.PrivateKey, nil, "password")
+	require.EqualError(t, err, "certificate chain is empty")
+}
+
+func TestEncodeJKSTrustStore(t *testing.T) {
+	setJKSHooks(t)
+	ca1 := testca.New(t, td)
+	ca2 := testca.New(t, spiffeid.RequireTrustDomainFromString("federated.test"))
+
+	trustStore, err := EncodeTrustStore(FormatJKS, []TrustStoreEntry{
+		{Alias: "domain.test-0", Cert: ca1.X509Authorities()[0]},
+		{Alias: "federated.test-0", Cert: ca2.X509Authorities()[0]},
+	}, "password")
+	require.NoError(t, err)
+
+	entries := decodeJKS(t, trustStore, "password")
+	require.Len(t, entries, 2)
+	require.Equal(t, jksTrustedCertTag, entries[0].tag)
+	require.Equal(t, "domain.test-0", entries[0].alias)
+	require.Equal(t, ca1.X509Authorities(), entries[0].certs)
+	require.Equal(t, jksTrustedCertTag, entries[1].tag)
+	require.Equal(t, "federated.test-0", entries[1].alias)
+	require.Equal(t, ca2.X509Authorities(), entries[1].certs)
+
+	_, err = EncodeTrustStore(FormatJKS, []TrustStoreEntry{
+		{Alias: "ca", Cert: ca1.X509Authorities()[0]},
+		{Alias: "CA", Cert: ca2.X509Authorities()[0]},
+	}, "password")
+	require.EqualError(t, err, `duplicate truststore alias "ca"`)
+}
+
+func setJKSHooks(t *testing.T) {
+	jksNow = func() time.Time { return time.Unix(1700000000, 0) }
+	jksRand = bytes.NewReader(bytes.Repeat([]byte{0x42}, 1024))
+	t.Cleanup(func() {
+		jksNow = time.Now
+		jksRand = rand.Reader
+	})
+}
+
+type jksEntry struct {
+	tag   int
+	alias string
+	key   []byte
+	certs []*x509.Certificate
+}
+
+// decodeJKS decodes a JKS keystore the way sun.security.provider.JavaKeyStore
+// does, verifying the integrity digest and recovering the private keys.
+func decodeJKS(t *testing.T, data []byte, password string) []jksEntry {
+	require.Greater(t, len(data), 20)
+	body, digest := data[:len(data)-20], data[len(data)-20:]
+	require.Equal(t, sha1Sum(jksPassword(password), []byte(jksIntegritySalt), body), digest, "integrity digest mismatch")
+
+	r := bytes.NewReader(body)
+	readUint32 := func() uint32 {
+		var v uint32
+		require.NoError(t, binary.Read(r, binary.BigEndian, &v))
+		return v
+	}
+	readN := func(n int) []byte {
+		b := make([]byte, n)
+		_, err := io.ReadFull(r, b)
+		require.NoError(t, err)
+		return b
+	}
+	readUTF := func() string {
+		var n uint16
+		require.NoError(t, binary.Read(r, binary.BigEndian, &n))
+		return string(readN(int(n)))
+	}
+	readCert := func() *x509.Certificate {
+		require.Equal(t, jksCertType, readUTF())
+		cert, err := x509.ParseCertificate(readN(int(readUint32())))
+		require.NoError(t, err)
+		return cert
+	}
+
+	require.Equal(t, uint32(jksMagic), readUint32())
+	require.Equal(t, uint32(jksVersion), readUint32())
+
+	var entries []jksEntry
+	for i := readUint32(); i > 0; i-- {
+		entry := jksEntry{tag: int(readUint32())}
+		entry.alias = readUTF()
+		var timestamp int64
+		require.NoError(t, binary.Read(r, binary.BigEndian, &timestamp))
+		require.Equal(t, int64(1700000000000), timestamp)
+
+		switch entry.tag {
+		case jksPrivateKeyTag:
+			entry.key = recoverJKSKey(t, readN(int(readUint32())), password)
+			for j := readUint32(); j > 0; j-- {
+				entry.certs = append(entry.certs, readCert())
+			}
+		case jksTrustedCertTag:
+			entry.certs = append(entry.certs, readCert())
+		default:
+			require.Failf(t, "unexpected tag", "tag %d", entry.tag)
+		}
+		entries = append(entries, entry)
+	}
+	require.Zero(t, r.Len(), "trailing data")
+	return entries
+}
+
+func recoverJKSKey(t *testing.T, protectedKey []byte, password string) []byte {
+	var info encryptedPrivateKeyInfo
+	rest, err := asn1.Unmarshal(protectedKey, &info)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+	require.True(t, info.Algo.Algorithm.Equal(oidJKSKeyProtector))
+
+	encrypted := info.EncryptedData
+	salt := encrypted[:jksSaltLen]
+	encryptedKey := encrypted[jksSaltLen : len(encrypted)-20]
+	check := encrypted[len(encrypted)-20:]
+
+	passwordBytes := jksPassword(password)
+	plainKey := make([]byte, len(encryptedKey))
+	digest := salt
+	for offset := 0; offset < len(encryptedKey); offset += 20 {
+		digest = sha1Sum(passwordBytes, digest)
+		for i := 0; i < 20 && offset+i < len(encryptedKey); i++ {
+			plainKey[offset+i] = encryptedKey[offset+i] ^ digest[i]
+		}
+	}
+	require.Equal(t, sha1Sum(passwordBytes, plainKey), check, "key integrity check mismatch")
+	return plainKey
+}
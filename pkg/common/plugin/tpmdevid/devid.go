@@ -1,6 +1,11 @@
 package tpmdevid
 
-import "crypto/rand"
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/google/go-tpm/legacy/tpm2"
+)
 
 const PluginName = "tpm_devid"
 
@@ -20,6 +25,23 @@ type AttestationRequest struct {
 type ChallengeRequest struct {
 	DevID          []byte
 	CredActivation *CredActivation
+
+	// Quote is only set when the server verifies the measured boot state of
+	// the node.
+	Quote *QuoteRequest `json:",omitempty"`
+}
+
+// QuoteRequest asks the agent to quote a set of PCRs using the attestation
+// key.
+type QuoteRequest struct {
+	// Nonce is the qualifying data that must be included in the quote.
+	Nonce []byte
+	// PCRBank is the name of the PCR bank to quote (e.g. "sha256").
+	PCRBank string
+	// PCRs are the indexes of the PCRs to quote.
+	PCRs []int
+	// EventLog is true when the agent must also send the TCG event log.
+	EventLog bool
 }
 
 type CredActivation struct {
@@ -30,6 +52,34 @@ type CredActivation struct {
 type ChallengeResponse struct {
 	DevID          []byte
 	CredActivation []byte
+
+	Quote *QuoteResponse `json:",omitempty"`
+}
+
+// QuoteResponse holds the PCR quote requested by the server.
+type QuoteResponse struct {
+	// Quote is the TPMS_ATTEST structure produced by TPM2_Quote.
+	Quote []byte
+	// Signature is the TPMT_SIGNATURE of the quote made with the attestation
+	// key.
+	Signature []byte
+	// PCRs are the values of the quoted PCRs, keyed by PCR index.
+	PCRs map[int][]byte
+	// EventLog is the TCG event log of the node, if requested.
+	EventLog []byte `json:",omitempty"`
+}
+
+// PCRBankAlgorithm returns the TPM hash algorithm of the PCR bank with the
+// given name.
+func PCRBankAlgorithm(name string) (tpm2.Algorithm, error) {
+	switch name {
+	case "sha1":
+		return tpm2.AlgSHA1, nil
+	case "sha256":
+		return tpm2.AlgSHA256, nil
+	default:
+		return tpm2.AlgNull, fmt.Errorf("unsupported PCR bank %q", name)
+	}
 }
 
 func GetRandomBytes(size int) ([]byte, error) {
@@ -43,6 +43,10 @@ type AttestationData struct {
 	// DER encoded x509 certificate chain leading back to the trusted root. The
 	// leaf certificate comes first.
 	Certificates [][]byte `json:"certificates"`
+
+	// OCSPStaple is an optional DER encoded OCSP response for the leaf
+	// certificate, used by the server to check its revocation status.
+	OCSPStaple []byte `json:"ocsp_staple,omitempty"`
 }
 
 type RSASignatureChallenge struct {
@@ -19,6 +19,9 @@ type FileConfig struct {
 	Statsd     []StatsdConfig    `hcl:"Statsd"`
 	M3         []M3Config        `hcl:"M3"`
 	InMem      *InMem            `hcl:"InMem"`
+	OTLP       []OTLPConfig      `hcl:"OTLP"`
+
+	Tracing *TracingConfig `hcl:"Tracing"`
 
 	MetricPrefix           string   `hcl:"MetricPrefix"`
 	EnableTrustDomainLabel *bool    `hcl:"EnableTrustDomainLabel"`
@@ -53,6 +56,15 @@ type M3Config struct {
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
 
+type OTLPConfig struct {
+	Protocol           string                 `hcl:"protocol"`
+	Endpoint           string                 `hcl:"endpoint"`
+	Insecure           bool                   `hcl:"insecure"`
+	Headers            map[string]string      `hcl:"headers"`
+	ExportInterval     string                 `hcl:"export_interval"`
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
 type InMem struct {
 	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
 }
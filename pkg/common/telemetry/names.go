@@ -266,6 +266,9 @@ const (
 	// Downstream tags if entry is a downstream
 	Downstream = "downstream"
 
+	// DryRun tags if a request is a dry-run
+	DryRun = "dry_run"
+
 	// ElapsedTime tags some duration of time.
 	ElapsedTime = "elapsed_time"
 
@@ -482,6 +485,9 @@ const (
 	// RegistrationEntryEvent is a notice a registration entry has been created, modified, or deleted
 	RegistrationEntryEvent = "registration_entry_event"
 
+	// RemovedResourceNames tags some group of resources by name that have been removed
+	RemovedResourceNames = "removed_resource_names"
+
 	// RequestID tags a request identifier
 	RequestID = "request_id"
 
@@ -494,6 +500,9 @@ const (
 	// RevisionNumber tags a registration entry revision number
 	RevisionNumber = "revision_number"
 
+	// RevokedX509SVID is a revoked X509-SVID record
+	RevokedX509SVID = "revoked_x509_svid"
+
 	// Schema tags database schema version
 	Schema = "schema"
 
@@ -604,6 +613,9 @@ const (
 	// TrustDomainID tags the ID of some trust domain
 	TrustDomainID = "trust_domain_id"
 
+	// UnsubscribedResourceNames tags some group of resources by name that are no longer subscribed to
+	UnsubscribedResourceNames = "unsubscribed_resource_names"
+
 	// Unknown tags some unknown caller, entity, or status
 	Unknown = "unknown"
 
@@ -814,6 +826,9 @@ const (
 	// AuthorizeCall functionality related to authorizing an incoming call
 	AuthorizeCall = "authorize_call"
 
+	// CacheAPI functionality related to agent cache endpoints
+	CacheAPI = "cache_api"
+
 	// CreateFederatedBundle functionality related to creating a federated bundle
 	CreateFederatedBundle = "create_federated_bundle"
 
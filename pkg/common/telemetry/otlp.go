@@ -0,0 +1,301 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/version"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	// OTLPProtocolGRPC exports telemetry using OTLP over gRPC
+	OTLPProtocolGRPC = "grpc"
+
+	// OTLPProtocolHTTP exports telemetry using OTLP over HTTP
+	OTLPProtocolHTTP = "http"
+
+	defaultOTLPExportInterval = 10 * time.Second
+	otlpShutdownTimeout       = 5 * time.Second
+)
+
+var (
+	otlpForbiddenChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// durationBucketBoundaries are the histogram boundaries, in
+	// milliseconds, used for timers. They match the buckets used for M3.
+	durationBucketBoundaries = func() []float64 {
+		boundaries := make([]float64, 0, len(durationBuckets))
+		for _, d := range durationBuckets {
+			boundaries = append(boundaries, float64(d/timerGranularity))
+		}
+		return boundaries
+	}()
+)
+
+type otlpSink struct {
+	provider *sdkmetric.MeterProvider
+	meter    metric.Meter
+
+	mu         sync.Mutex
+	counters   map[string]metric.Float64Counter
+	gauges     map[string]metric.Float64Gauge
+	histograms map[string]metric.Float64Histogram
+}
+
+func newOTLPSink(ctx context.Context, serviceName string, c OTLPConfig) (*otlpSink, error) {
+	exportInterval := defaultOTLPExportInterval
+	if c.ExportInterval != "" {
+		var err error
+		exportInterval, err = time.ParseDuration(c.ExportInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP export_interval %q: %w", c.ExportInterval, err)
+		}
+		if exportInterval <= 0 {
+			return nil, fmt.Errorf("invalid OTLP export_interval %q: must be greater than zero", c.ExportInterval)
+		}
+	}
+
+	exporter, err := newOTLPMetricExporter(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version.Version()),
+	)
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(exportInterval))),
+	)
+
+	return newOTLPSinkWithProvider(provider), nil
+}
+
+func newOTLPSinkWithProvider(provider *sdkmetric.MeterProvider) *otlpSink {
+	return &otlpSink{
+		provider:   provider,
+		meter:      provider.Meter(tracerName),
+		counters:   make(map[string]metric.Float64Counter),
+		gauges:     make(map[string]metric.Float64Gauge),
+		histograms: make(map[string]metric.Float64Histogram),
+	}
+}
+
+func newOTLPMetricExporter(ctx context.Context, c OTLPConfig) (sdkmetric.Exporter, error) {
+	switch otlpProtocol(c.Protocol) {
+	case OTLPProtocolGRPC:
+		var opts []otlpmetricgrpc.Option
+		if c.Endpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlpmetricgrpc.WithHeaders(c.Headers))
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case OTLPProtocolHTTP:
+		var opts []otlpmetrichttp.Option
+		if c.Endpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(c.Endpoint))
+		}
+		if c.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlpmetrichttp.WithHeaders(c.Headers))
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q; expected %q or %q", c.Protocol, OTLPProtocolGRPC, OTLPProtocolHTTP)
+	}
+}
+
+// otlpProtocol returns the normalized OTLP protocol, defaulting to gRPC.
+func otlpProtocol(protocol string) string {
+	if protocol == "" {
+		return OTLPProtocolGRPC
+	}
+	return strings.ToLower(protocol)
+}
+
+func (o *otlpSink) SetGauge(key []string, val float32) {
+	o.SetGaugeWithLabels(key, val, nil)
+}
+
+func (o *otlpSink) SetGaugeWithLabels(key []string, val float32, labels []Label) {
+	gauge, err := o.getGauge(o.flattenKey(key))
+	if err != nil {
+		return
+	}
+	gauge.Record(context.Background(), float64(val), labelsToAttributes(labels))
+}
+
+// Not implemented for OTLP
+func (o *otlpSink) EmitKey([]string, float32) {}
+
+// Counters should accumulate values
+func (o *otlpSink) IncrCounter(key []string, val float32) {
+	o.IncrCounterWithLabels(key, val, nil)
+}
+
+func (o *otlpSink) IncrCounterWithLabels(key []string, val float32, labels []Label) {
+	counter, err := o.getCounter(o.flattenKey(key))
+	if err != nil {
+		return
+	}
+	counter.Add(context.Background(), float64(val), labelsToAttributes(labels))
+}
+
+// Samples are for timing information, where quantiles are used
+func (o *otlpSink) AddSample(key []string, val float32) {
+	o.AddSampleWithLabels(key, val, nil)
+}
+
+func (o *otlpSink) AddSampleWithLabels(key []string, val float32, labels []Label) {
+	histogram, err := o.getHistogram(o.flattenKey(key), key[1] == "timer")
+	if err != nil {
+		return
+	}
+	histogram.Record(context.Background(), float64(val), labelsToAttributes(labels))
+}
+
+func (o *otlpSink) Shutdown() {
+}
+
+// flattenKey flattens the key into an instrument name. The type of metric is
+// removed from the name, i.e. "spire_server_foo_bar" is preferred over
+// "spire_server_counter_foo_bar", so instrument names match the metric names
+// exposed by the Prometheus sink.
+func (o *otlpSink) flattenKey(parts []string) string {
+	name := strings.Join(append([]string{parts[0]}, parts[2:]...), "_")
+	return otlpForbiddenChars.ReplaceAllString(name, "_")
+}
+
+func (o *otlpSink) getCounter(name string) (metric.Float64Counter, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if counter, ok := o.counters[name]; ok {
+		return counter, nil
+	}
+	counter, err := o.meter.Float64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	o.counters[name] = counter
+	return counter, nil
+}
+
+func (o *otlpSink) getGauge(name string) (metric.Float64Gauge, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if gauge, ok := o.gauges[name]; ok {
+		return gauge, nil
+	}
+	gauge, err := o.meter.Float64Gauge(name)
+	if err != nil {
+		return nil, err
+	}
+	o.gauges[name] = gauge
+	return gauge, nil
+}
+
+func (o *otlpSink) getHistogram(name string, isTimer bool) (metric.Float64Histogram, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if histogram, ok := o.histograms[name]; ok {
+		return histogram, nil
+	}
+
+	var opts []metric.Float64HistogramOption
+	if isTimer {
+		opts = append(opts, metric.WithUnit("ms"), metric.WithExplicitBucketBoundaries(durationBucketBoundaries...))
+	} else {
+		opts = append(opts, metric.WithExplicitBucketBoundaries(exponentialValueBuckets...))
+	}
+	histogram, err := o.meter.Float64Histogram(name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	o.histograms[name] = histogram
+	return histogram, nil
+}
+
+func labelsToAttributes(labels []Label) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		attrs = append(attrs, attribute.String(l.Name, l.Value))
+	}
+	return metric.WithAttributes(attrs...)
+}
+
+var _ Sink = (*otlpSink)(nil)
+
+type otlpRunner struct {
+	loadedSinks []*otlpSink
+}
+
+func newOTLPRunner(c *MetricsConfig) (sinkRunner, error) {
+	runner := &otlpRunner{}
+	for _, conf := range c.FileConfig.OTLP {
+		sink, err := newOTLPSink(context.Background(), c.ServiceName, conf)
+		if err != nil {
+			return runner, err
+		}
+
+		runner.loadedSinks = append(runner.loadedSinks, sink)
+	}
+
+	return runner, nil
+}
+
+func (r *otlpRunner) isConfigured() bool {
+	return len(r.loadedSinks) > 0
+}
+
+func (r *otlpRunner) sinks() []Sink {
+	s := make([]Sink, len(r.loadedSinks))
+	for i, v := range r.loadedSinks {
+		s[i] = v
+	}
+
+	return s
+}
+
+func (r *otlpRunner) run(ctx context.Context) error {
+	if !r.isConfigured() {
+		return nil
+	}
+
+	<-ctx.Done()
+
+	// Flush any pending metrics before returning
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), otlpShutdownTimeout)
+	defer cancel()
+	for _, s := range r.loadedSinks {
+		_ = s.provider.Shutdown(shutdownCtx)
+	}
+
+	return ctx.Err()
+}
+
+func (r *otlpRunner) requiresTypePrefix() bool {
+	return true
+}
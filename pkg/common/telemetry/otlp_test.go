@@ -0,0 +1,178 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestNewOTLPRunner(t *testing.T) {
+	config := testOTLPConfig()
+	runner, err := newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.True(t, runner.isConfigured())
+	assert.True(t, runner.requiresTypePrefix())
+
+	config.FileConfig.OTLP = []OTLPConfig{}
+	runner, err = newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.False(t, runner.isConfigured())
+}
+
+func TestNewOTLPRunnerErrors(t *testing.T) {
+	config := testOTLPConfig()
+	config.FileConfig.OTLP[0].Protocol = "zipkin"
+	_, err := newOTLPRunner(config)
+	require.EqualError(t, err, `unsupported OTLP protocol "zipkin"; expected "grpc" or "http"`)
+
+	config = testOTLPConfig()
+	config.FileConfig.OTLP[0].ExportInterval = "soon"
+	_, err = newOTLPRunner(config)
+	require.EqualError(t, err, `invalid OTLP export_interval "soon": time: invalid duration "soon"`)
+
+	for _, interval := range []string{"0s", "-10s"} {
+		config = testOTLPConfig()
+		config.FileConfig.OTLP[0].ExportInterval = interval
+		_, err = newOTLPRunner(config)
+		require.EqualError(t, err, fmt.Sprintf(`invalid OTLP export_interval %q: must be greater than zero`, interval))
+	}
+}
+
+func TestMultipleOTLPSinks(t *testing.T) {
+	config := testOTLPConfig()
+	config.FileConfig.OTLP = append(config.FileConfig.OTLP, OTLPConfig{
+		Protocol: "http",
+		Endpoint: "localhost:4318",
+		Headers:  map[string]string{"key": "value"},
+	})
+	runner, err := newOTLPRunner(config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(runner.sinks()))
+}
+
+func TestRunOTLP(t *testing.T) {
+	config := testOTLPConfig()
+
+	runner, err := newOTLPRunner(config)
+	require.NoError(t, err)
+
+	errCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		errCh <- runner.run(ctx)
+	}()
+
+	// It stops when it's supposed to
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Minute):
+		t.Fatal("timeout waiting for shutdown")
+	}
+
+	config.FileConfig.OTLP = nil
+	runner, err = newOTLPRunner(config)
+	require.NoError(t, err)
+
+	go func() {
+		errCh <- runner.run(context.Background())
+	}()
+
+	// It doesn't run if it's not configured
+	select {
+	case err := <-errCh:
+		assert.Nil(t, err, "should be nil if not configured")
+	case <-time.After(time.Minute):
+		t.Fatal("otlp running but not configured")
+	}
+}
+
+func TestOTLPSinkInstruments(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	sink := newOTLPSinkWithProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	labels := []Label{{Name: "status", Value: "OK"}}
+	sink.IncrCounterWithLabels([]string{"spire_server", "counter", "rpc", "call"}, 1, labels)
+	sink.IncrCounterWithLabels([]string{"spire_server", "counter", "rpc", "call"}, 2, labels)
+	sink.IncrCounter([]string{"spire_server", "counter", "started"}, 1)
+	sink.SetGaugeWithLabels([]string{"spire_server", "gauge", "entries"}, 5, labels)
+	sink.SetGauge([]string{"spire_server", "gauge", "uptime_in_ms"}, 7)
+	sink.AddSampleWithLabels([]string{"spire_server", "timer", "rpc", "call", "elapsed_time"}, 30, labels)
+	sink.AddSample([]string{"spire_server", "sample", "some.value"}, 500)
+	sink.EmitKey([]string{"spire_server", "kv", "ignored"}, 1)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	collected := make(map[string]metricdata.Metrics)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		collected[m.Name] = m
+	}
+	require.Len(t, collected, 6)
+
+	statusAttr := attribute.NewSet(attribute.String("status", "OK"))
+
+	counter, ok := collected["spire_server_rpc_call"].Data.(metricdata.Sum[float64])
+	require.True(t, ok, "rpc call should be a sum")
+	assert.True(t, counter.IsMonotonic)
+	require.Len(t, counter.DataPoints, 1)
+	assert.Equal(t, 3.0, counter.DataPoints[0].Value)
+	assert.Equal(t, statusAttr, counter.DataPoints[0].Attributes)
+
+	started, ok := collected["spire_server_started"].Data.(metricdata.Sum[float64])
+	require.True(t, ok, "started should be a sum")
+	require.Len(t, started.DataPoints, 1)
+	assert.Equal(t, 0, started.DataPoints[0].Attributes.Len())
+
+	gauge, ok := collected["spire_server_entries"].Data.(metricdata.Gauge[float64])
+	require.True(t, ok, "entries should be a gauge")
+	require.Len(t, gauge.DataPoints, 1)
+	assert.Equal(t, 5.0, gauge.DataPoints[0].Value)
+	assert.Equal(t, statusAttr, gauge.DataPoints[0].Attributes)
+
+	_, ok = collected["spire_server_uptime_in_ms"].Data.(metricdata.Gauge[float64])
+	require.True(t, ok, "uptime should be a gauge")
+
+	timer := collected["spire_server_rpc_call_elapsed_time"]
+	assert.Equal(t, "ms", timer.Unit)
+	timerHistogram, ok := timer.Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "elapsed time should be a histogram")
+	require.Len(t, timerHistogram.DataPoints, 1)
+	assert.Equal(t, durationBucketBoundaries, timerHistogram.DataPoints[0].Bounds)
+	assert.Equal(t, uint64(1), timerHistogram.DataPoints[0].Count)
+	assert.Equal(t, 30.0, timerHistogram.DataPoints[0].Sum)
+	assert.Equal(t, statusAttr, timerHistogram.DataPoints[0].Attributes)
+
+	valueHistogram, ok := collected["spire_server_some_value"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "value sample should be a histogram")
+	require.Len(t, valueHistogram.DataPoints, 1)
+	assert.Equal(t, []float64(exponentialValueBuckets), valueHistogram.DataPoints[0].Bounds)
+}
+
+func testOTLPConfig() *MetricsConfig {
+	l, _ := test.NewNullLogger()
+
+	return &MetricsConfig{
+		Logger:      l,
+		ServiceName: "foo",
+		TrustDomain: "test.org",
+		FileConfig: FileConfig{
+			OTLP: []OTLPConfig{
+				{
+					Endpoint: "localhost:4317",
+					Insecure: true,
+				},
+			},
+		},
+	}
+}
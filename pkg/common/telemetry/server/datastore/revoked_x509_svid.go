@@ -0,0 +1,23 @@
+package datastore
+
+import (
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// StartRevokeX509SVIDCall return metric for server's datastore, on revoking
+// an X509-SVID.
+func StartRevokeX509SVIDCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RevokedX509SVID, telemetry.Create)
+}
+
+// StartListRevokedX509SVIDsCall return metric for server's datastore, on
+// listing revoked X509-SVIDs.
+func StartListRevokedX509SVIDsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RevokedX509SVID, telemetry.List)
+}
+
+// StartPruneRevokedX509SVIDsCall return metric for server's datastore, on
+// pruning revoked X509-SVIDs.
+func StartPruneRevokedX509SVIDsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RevokedX509SVID, telemetry.Prune)
+}
@@ -197,6 +197,12 @@ func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBund
 	return w.ds.ListBundles(ctx, req)
 }
 
+func (w metricsWrapper) ListJoinTokens(ctx context.Context) (_ []*datastore.JoinToken, err error) {
+	callCounter := StartListJoinTokensCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListJoinTokens(ctx)
+}
+
 func (w metricsWrapper) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (_ *datastore.ListNodeSelectorsResponse, err error) {
 	callCounter := StartListNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
@@ -335,10 +341,10 @@ func (w metricsWrapper) FetchCAJournal(ctx context.Context, activeX509AuthorityI
 	return w.ds.FetchCAJournal(ctx, activeX509AuthorityID)
 }
 
-func (w metricsWrapper) ListCAJournalsForTesting(ctx context.Context) (_ []*datastore.CAJournal, err error) {
-	callCounter := StartListCAJournalsForTesting(w.m)
+func (w metricsWrapper) ListCAJournals(ctx context.Context) (_ []*datastore.CAJournal, err error) {
+	callCounter := StartListCAJournalsCall(w.m)
 	defer callCounter.Done(&err)
-	return w.ds.ListCAJournalsForTesting(ctx)
+	return w.ds.ListCAJournals(ctx)
 }
 
 func (w metricsWrapper) PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) (err error) {
@@ -346,3 +352,21 @@ func (w metricsWrapper) PruneCAJournals(ctx context.Context, allCAsExpireBefore
 	defer callCounter.Done(&err)
 	return w.ds.PruneCAJournals(ctx, allCAsExpireBefore)
 }
+
+func (w metricsWrapper) RevokeX509SVID(ctx context.Context, revoked *datastore.RevokedX509SVID) (_ *datastore.RevokedX509SVID, err error) {
+	callCounter := StartRevokeX509SVIDCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.RevokeX509SVID(ctx, revoked)
+}
+
+func (w metricsWrapper) ListRevokedX509SVIDs(ctx context.Context) (_ []*datastore.RevokedX509SVID, err error) {
+	callCounter := StartListRevokedX509SVIDsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListRevokedX509SVIDs(ctx)
+}
+
+func (w metricsWrapper) PruneRevokedX509SVIDs(ctx context.Context, expiresBefore time.Time) (err error) {
+	callCounter := StartPruneRevokedX509SVIDsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneRevokedX509SVIDs(ctx, expiresBefore)
+}
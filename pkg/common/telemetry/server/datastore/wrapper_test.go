@@ -161,6 +161,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.list",
 			methodName: "ListBundles",
 		},
+		{
+			key:        "datastore.join_token.list",
+			methodName: "ListJoinTokens",
+		},
 		{
 			key:        "datastore.node.selectors.list",
 			methodName: "ListNodeSelectors",
@@ -251,7 +255,19 @@ func TestWithMetrics(t *testing.T) {
 		},
 		{
 			key:        "datastore.ca_journal.list",
-			methodName: "ListCAJournalsForTesting",
+			methodName: "ListCAJournals",
+		},
+		{
+			key:        "datastore.revoked_x509_svid.create",
+			methodName: "RevokeX509SVID",
+		},
+		{
+			key:        "datastore.revoked_x509_svid.list",
+			methodName: "ListRevokedX509SVIDs",
+		},
+		{
+			key:        "datastore.revoked_x509_svid.prune",
+			methodName: "PruneRevokedX509SVIDs",
 		},
 	} {
 		methodType, ok := wt.MethodByName(tt.methodName)
@@ -460,6 +476,10 @@ func (ds *fakeDataStore) ListBundles(context.Context, *datastore.ListBundlesRequ
 	return &datastore.ListBundlesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListJoinTokens(context.Context) ([]*datastore.JoinToken, error) {
+	return []*datastore.JoinToken{}, ds.err
+}
+
 func (ds *fakeDataStore) ListNodeSelectors(context.Context, *datastore.ListNodeSelectorsRequest) (*datastore.ListNodeSelectorsResponse, error) {
 	return &datastore.ListNodeSelectorsResponse{}, ds.err
 }
@@ -540,10 +560,22 @@ func (ds *fakeDataStore) FetchCAJournal(context.Context, string) (*datastore.CAJ
 	return &datastore.CAJournal{}, ds.err
 }
 
-func (ds *fakeDataStore) ListCAJournalsForTesting(context.Context) ([]*datastore.CAJournal, error) {
+func (ds *fakeDataStore) ListCAJournals(context.Context) ([]*datastore.CAJournal, error) {
 	return []*datastore.CAJournal{}, ds.err
 }
 
 func (ds *fakeDataStore) PruneCAJournals(context.Context, int64) error {
 	return ds.err
 }
+
+func (ds *fakeDataStore) RevokeX509SVID(context.Context, *datastore.RevokedX509SVID) (*datastore.RevokedX509SVID, error) {
+	return &datastore.RevokedX509SVID{}, ds.err
+}
+
+func (ds *fakeDataStore) ListRevokedX509SVIDs(context.Context) ([]*datastore.RevokedX509SVID, error) {
+	return []*datastore.RevokedX509SVID{}, ds.err
+}
+
+func (ds *fakeDataStore) PruneRevokedX509SVIDs(context.Context, time.Time) error {
+	return ds.err
+}
@@ -0,0 +1,166 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/spiffe/spire/pkg/common/version"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "github.com/spiffe/spire"
+
+// TracingConfig configures the export of traces using the OpenTelemetry
+// protocol (OTLP).
+type TracingConfig struct {
+	// Protocol is the OTLP protocol used to export spans, either "grpc"
+	// (default) or "http".
+	Protocol string `hcl:"protocol"`
+
+	// Endpoint is the host and port of the OTLP collector. If unset, the
+	// OpenTelemetry defaults (including the OTEL_EXPORTER_OTLP_* environment
+	// variables) apply.
+	Endpoint string `hcl:"endpoint"`
+
+	// Insecure disables TLS when talking to the collector.
+	Insecure bool `hcl:"insecure"`
+
+	// Headers are additional headers sent with each export request.
+	Headers map[string]string `hcl:"headers"`
+
+	// SampleRatio is the ratio of traces that are sampled, between 0 and 1.
+	// Defaults to 1. Traces with a sampled parent are always sampled.
+	SampleRatio *float64 `hcl:"sample_ratio"`
+
+	UnusedKeyPositions map[string][]token.Pos `hcl:",unusedKeyPositions"`
+}
+
+// InitTracing configures the global OpenTelemetry tracer provider and
+// propagator according to the Tracing section of the telemetry
+// configuration. If tracing is not configured, spans are not recorded and
+// the returned shutdown function is a no-op. The shutdown function flushes
+// any pending spans and must be called before the process exits.
+func InitTracing(ctx context.Context, c *MetricsConfig) (func(context.Context) error, error) {
+	tc := c.FileConfig.Tracing
+	if tc == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	sampleRatio := 1.0
+	if tc.SampleRatio != nil {
+		sampleRatio = *tc.SampleRatio
+		if sampleRatio < 0 || sampleRatio > 1 {
+			return nil, fmt.Errorf("tracing sample_ratio must be between 0 and 1, got %v", sampleRatio)
+		}
+	}
+
+	exporter, err := newTraceExporter(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(c.ServiceName),
+		semconv.ServiceVersion(version.Version()),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if c.Logger != nil {
+		c.Logger.WithField("protocol", otlpProtocol(tc.Protocol)).Info("Tracing enabled")
+	}
+
+	return provider.Shutdown, nil
+}
+
+func newTraceExporter(ctx context.Context, tc *TracingConfig) (sdktrace.SpanExporter, error) {
+	switch otlpProtocol(tc.Protocol) {
+	case OTLPProtocolGRPC:
+		var opts []otlptracegrpc.Option
+		if tc.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(tc.Endpoint))
+		}
+		if tc.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(tc.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(tc.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case OTLPProtocolHTTP:
+		var opts []otlptracehttp.Option
+		if tc.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(tc.Endpoint))
+		}
+		if tc.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(tc.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(tc.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing protocol %q; expected %q or %q", tc.Protocol, OTLPProtocolGRPC, OTLPProtocolHTTP)
+	}
+}
+
+// Tracer returns the tracer used to create SPIRE spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartSpan starts an internal span with the given name. It is intended to be
+// scoped to a function with a defer and a named error value, like so:
+//
+//	func Foo(ctx context.Context) (err error) {
+//	    ctx, span := StartSpan(ctx, "foo")
+//	    defer EndSpan(span, &err)
+//	}
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends the span, recording the gRPC status code of the given error
+// and, if the error is not nil, marking the span as failed.
+func EndSpan(span trace.Span, errp *error) {
+	var err error
+	if errp != nil {
+		err = *errp
+	}
+	SetSpanStatus(span, err)
+	span.End()
+}
+
+// SetSpanStatus records the gRPC status code of the given error on the span
+// and, if the error is not nil, marks the span as failed.
+func SetSpanStatus(span trace.Span, err error) {
+	span.SetAttributes(attribute.Int64("rpc.grpc.status_code", int64(status.Code(err))))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, status.Convert(err).Message())
+	}
+}
@@ -0,0 +1,131 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestInitTracing(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		config       *TracingConfig
+		expectErr    string
+		expectSDKSet bool
+	}{
+		{
+			name: "not configured",
+		},
+		{
+			name:         "grpc by default",
+			config:       &TracingConfig{Endpoint: "localhost:4317", Insecure: true},
+			expectSDKSet: true,
+		},
+		{
+			name:         "http",
+			config:       &TracingConfig{Protocol: "http", Endpoint: "localhost:4318", Headers: map[string]string{"key": "value"}},
+			expectSDKSet: true,
+		},
+		{
+			name:         "sample ratio",
+			config:       &TracingConfig{SampleRatio: ptr(0.5)},
+			expectSDKSet: true,
+		},
+		{
+			name:      "unsupported protocol",
+			config:    &TracingConfig{Protocol: "zipkin"},
+			expectErr: `unsupported tracing protocol "zipkin"; expected "grpc" or "http"`,
+		},
+		{
+			name:      "sample ratio out of range",
+			config:    &TracingConfig{SampleRatio: ptr(1.5)},
+			expectErr: "tracing sample_ratio must be between 0 and 1, got 1.5",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreGlobalTracing(t)
+
+			log, _ := test.NewNullLogger()
+			shutdown, err := InitTracing(context.Background(), &MetricsConfig{
+				FileConfig:  FileConfig{Tracing: tt.config},
+				Logger:      log,
+				ServiceName: "foo",
+			})
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			_, isSDK := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+			assert.Equal(t, tt.expectSDKSet, isSDK)
+			assert.NoError(t, shutdown(context.Background()))
+		})
+	}
+}
+
+func TestSpans(t *testing.T) {
+	restoreGlobalTracing(t)
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	succeed := func(ctx context.Context) (err error) {
+		_, span := StartSpan(ctx, "succeed", attribute.String("key", "value"))
+		defer EndSpan(span, &err)
+		return nil
+	}
+	fail := func(ctx context.Context) (err error) {
+		_, span := StartSpan(ctx, "fail")
+		defer EndSpan(span, &err)
+		return status.Error(codes.NotFound, "ohno")
+	}
+
+	require.NoError(t, succeed(context.Background()))
+	require.Error(t, fail(context.Background()))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "succeed", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("key", "value"))
+	assert.Contains(t, spans[0].Attributes(), attribute.Int64("rpc.grpc.status_code", int64(codes.OK)))
+	assert.Equal(t, otelcodes.Unset, spans[0].Status().Code)
+	assert.Empty(t, spans[0].Events())
+
+	assert.Equal(t, "fail", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.Int64("rpc.grpc.status_code", int64(codes.NotFound)))
+	assert.Equal(t, otelcodes.Error, spans[1].Status().Code)
+	assert.Equal(t, "ohno", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "exception", spans[1].Events()[0].Name)
+
+	// A nil error pointer is treated as success
+	_, span := StartSpan(context.Background(), "nil")
+	EndSpan(span, nil)
+	require.Len(t, recorder.Ended(), 3)
+	assert.Equal(t, otelcodes.Unset, recorder.Ended()[2].Status().Code)
+
+}
+
+func restoreGlobalTracing(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
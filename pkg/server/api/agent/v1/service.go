@@ -5,6 +5,8 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -25,11 +27,13 @@ import (
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -42,9 +46,10 @@ type Config struct {
 	TrustDomain spiffeid.TrustDomain
 }
 
-// Service implements the v1 agent service
+// Service implements the v1 agent service, along with the agent batch service
 type Service struct {
 	agentv1.UnsafeAgentServer
+	agentbatchv1.UnsafeAgentBatchServer
 
 	cat catalog.Catalog
 	clk clock.Clock
@@ -67,6 +72,7 @@ func New(config Config) *Service {
 // RegisterService registers the agent service on the gRPC server/
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	agentv1.RegisterAgentServer(s, service)
+	agentbatchv1.RegisterAgentBatchServer(s, service)
 }
 
 // CountAgents returns the total number of agents.
@@ -265,15 +271,7 @@ func (s *Service) BanAgent(ctx context.Context, req *agentv1.BanAgentRequest) (*
 
 	log = log.WithField(telemetry.SPIFFEID, id.String())
 
-	// The agent "Banned" state is pointed out by setting its
-	// serial numbers (current and new) to empty strings.
-	banned := &common.AttestedNode{SpiffeId: id.String()}
-	mask := &common.AttestedNodeMask{
-		CertSerialNumber:    true,
-		NewCertSerialNumber: true,
-	}
-	_, err = s.ds.UpdateAttestedNode(ctx, banned, mask)
-
+	err = s.banAgent(ctx, id.String())
 	switch status.Code(err) {
 	case codes.OK:
 		log.Info("Agent banned")
@@ -286,6 +284,183 @@ func (s *Service) BanAgent(ctx context.Context, req *agentv1.BanAgentRequest) (*
 	}
 }
 
+// BatchBanAgents bans the agents matching the filter.
+func (s *Service) BatchBanAgents(ctx context.Context, req *agentbatchv1.BatchBanAgentsRequest) (*agentbatchv1.BatchBanAgentsResponse, error) {
+	results, err := s.batchAgents(ctx, req.Filter, req.Ids, req.DryRun, func(ctx context.Context, log logrus.FieldLogger, id string) *types.Status {
+		err := s.banAgent(ctx, id)
+		switch status.Code(err) {
+		case codes.OK:
+			log.Info("Agent banned")
+			return api.OK()
+		case codes.NotFound:
+			return api.MakeStatus(log, codes.NotFound, "agent not found", err)
+		default:
+			return api.MakeStatus(log, codes.Internal, "failed to ban agent", err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &agentbatchv1.BatchBanAgentsResponse{Results: results}, nil
+}
+
+// BatchEvictAgents removes the agents matching the filter.
+func (s *Service) BatchEvictAgents(ctx context.Context, req *agentbatchv1.BatchEvictAgentsRequest) (*agentbatchv1.BatchEvictAgentsResponse, error) {
+	results, err := s.batchAgents(ctx, req.Filter, req.Ids, req.DryRun, func(ctx context.Context, log logrus.FieldLogger, id string) *types.Status {
+		_, err := s.ds.DeleteAttestedNode(ctx, id)
+		switch status.Code(err) {
+		case codes.OK:
+			log.Info("Agent deleted")
+			return api.OK()
+		case codes.NotFound:
+			return api.MakeStatus(log, codes.NotFound, "agent not found", err)
+		default:
+			return api.MakeStatus(log, codes.Internal, "failed to remove agent", err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &agentbatchv1.BatchEvictAgentsResponse{Results: results}, nil
+}
+
+// BatchPurgeAgents removes the agents that can re-attest and whose X509-SVID
+// expired at least the given time ago.
+func (s *Service) BatchPurgeAgents(ctx context.Context, req *agentbatchv1.BatchPurgeAgentsRequest) (*agentbatchv1.BatchPurgeAgentsResponse, error) {
+	if req.ExpiredFor < 0 {
+		return nil, api.MakeErr(rpccontext.Logger(ctx), codes.InvalidArgument, "invalid filter", errors.New("expired for time cannot be negative"))
+	}
+
+	// Agents that cannot re-attest are never purged, since they could not
+	// attest again once removed.
+	filter := &agentbatchv1.AgentFilter{
+		BySelectorMatch:   req.BySelectorMatch,
+		ByAttestationType: req.ByAttestationType,
+		ByCanReattest:     proto.Bool(true),
+		ByExpiresBefore:   s.clk.Now().Add(-time.Duration(req.ExpiredFor) * time.Second).Unix(),
+	}
+	results, err := s.batchAgents(ctx, filter, req.Ids, req.DryRun, func(ctx context.Context, log logrus.FieldLogger, id string) *types.Status {
+		_, err := s.ds.DeleteAttestedNode(ctx, id)
+		switch status.Code(err) {
+		case codes.OK:
+			log.Info("Agent purged")
+			return api.OK()
+		case codes.NotFound:
+			return api.MakeStatus(log, codes.NotFound, "agent not found", err)
+		default:
+			return api.MakeStatus(log, codes.Internal, "failed to purge agent", err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &agentbatchv1.BatchPurgeAgentsResponse{Results: results}, nil
+}
+
+// batchAgents applies the operation to each agent matching the filter, and
+// returns the per-agent results ordered by SPIFFE ID. When IDs are provided,
+// the operation is restricted to those agents. Nothing is applied on dry runs.
+func (s *Service) batchAgents(ctx context.Context, filter *agentbatchv1.AgentFilter, ids []string, dryRun bool, op func(context.Context, logrus.FieldLogger, string) *types.Status) ([]*agentbatchv1.AgentResult, error) {
+	log := rpccontext.Logger(ctx)
+
+	listReq, err := listRequestFromAgentFilter(filter)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid filter", err)
+	}
+	fields := fieldsFromAgentFilter(filter)
+	fields[telemetry.DryRun] = dryRun
+	rpccontext.AddRPCAuditFields(ctx, fields)
+
+	var restrictTo map[string]bool
+	if len(ids) > 0 {
+		restrictTo = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			agentID, err := spiffeid.FromString(id)
+			if err != nil {
+				return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+			}
+			if err := api.VerifyTrustDomainAgentID(s.td, agentID); err != nil {
+				return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+			}
+			restrictTo[agentID.String()] = true
+		}
+	}
+
+	dsResp, err := s.ds.ListAttestedNodes(ctx, listReq)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list agents", err)
+	}
+	nodes := dsResp.Nodes
+	slices.SortFunc(nodes, func(a, b *common.AttestedNode) int {
+		return strings.Compare(a.SpiffeId, b.SpiffeId)
+	})
+
+	var results []*agentbatchv1.AgentResult
+	matched := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		if restrictTo != nil && !restrictTo[node.SpiffeId] {
+			continue
+		}
+		matched[node.SpiffeId] = true
+
+		result := &agentbatchv1.AgentResult{
+			Id:                node.SpiffeId,
+			AttestationType:   node.AttestationDataType,
+			X509SvidExpiresAt: node.CertNotAfter,
+			Banned:            nodeutil.IsAgentBanned(node),
+			CanReattest:       node.CanReattest,
+		}
+		if !dryRun {
+			st := op(ctx, log.WithField(telemetry.SPIFFEID, node.SpiffeId), node.SpiffeId)
+			result.StatusCode, result.StatusMessage = st.Code, st.Message
+			rpccontext.AuditRPCWithTypesStatus(ctx, st, func() logrus.Fields {
+				return logrus.Fields{telemetry.SPIFFEID: node.SpiffeId}
+			})
+		}
+		results = append(results, result)
+	}
+
+	// Report the requested agents that no longer match the filter
+	var unmatched []string
+	for id := range restrictTo {
+		if !matched[id] {
+			unmatched = append(unmatched, id)
+		}
+	}
+	slices.Sort(unmatched)
+	for _, id := range unmatched {
+		st := api.MakeStatus(log.WithField(telemetry.SPIFFEID, id), codes.FailedPrecondition, "agent does not match the filter", nil)
+		results = append(results, &agentbatchv1.AgentResult{
+			Id:            id,
+			StatusCode:    st.Code,
+			StatusMessage: st.Message,
+		})
+		if !dryRun {
+			rpccontext.AuditRPCWithTypesStatus(ctx, st, func() logrus.Fields {
+				return logrus.Fields{telemetry.SPIFFEID: id}
+			})
+		}
+	}
+
+	if dryRun {
+		rpccontext.AuditRPC(ctx)
+	}
+	return results, nil
+}
+
+// banAgent sets the agent to the banned state. The agent "Banned" state is
+// pointed out by setting its serial numbers (current and new) to empty
+// strings.
+func (s *Service) banAgent(ctx context.Context, id string) error {
+	banned := &common.AttestedNode{SpiffeId: id}
+	mask := &common.AttestedNodeMask{
+		CertSerialNumber:    true,
+		NewCertSerialNumber: true,
+	}
+	_, err := s.ds.UpdateAttestedNode(ctx, banned, mask)
+	return err
+}
+
 // AttestAgent attests the authenticity of the given agent.
 func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 	ctx := stream.Context()
@@ -747,6 +922,29 @@ func fieldsFromListAgentsRequest(filter *agentv1.ListAgentsRequest_Filter) logru
 	return fields
 }
 
+func fieldsFromAgentFilter(filter *agentbatchv1.AgentFilter) logrus.Fields {
+	fields := logrus.Fields{}
+
+	if filter.ByAttestationType != "" {
+		fields[telemetry.NodeAttestorType] = filter.ByAttestationType
+	}
+
+	if filter.ByBanned != nil {
+		fields[telemetry.ByBanned] = *filter.ByBanned
+	}
+
+	if filter.ByCanReattest != nil {
+		fields[telemetry.ByCanReattest] = *filter.ByCanReattest
+	}
+
+	if filter.BySelectorMatch != nil {
+		fields[telemetry.BySelectorMatch] = filter.BySelectorMatch.Match.String()
+		fields[telemetry.BySelectors] = api.SelectorFieldFromProto(protoFromAgentFilterSelectors(filter.BySelectorMatch.Selectors))
+	}
+
+	return fields
+}
+
 func fieldsFromCountAgentsRequest(filter *agentv1.CountAgentsRequest_Filter) logrus.Fields {
 	fields := logrus.Fields{}
 
@@ -773,3 +971,51 @@ func fieldsFromCountAgentsRequest(filter *agentv1.CountAgentsRequest_Filter) log
 func joinTokenID(td spiffeid.TrustDomain, token string) (spiffeid.ID, error) {
 	return spiffeid.FromSegments(td, "spire", "agent", "join_token", token)
 }
+
+// listRequestFromAgentFilter converts a batch filter into a datastore request.
+// Unlike the ListAgents filter, the batch filter cannot be empty, to prevent
+// acting on every agent by mistake.
+func listRequestFromAgentFilter(filter *agentbatchv1.AgentFilter) (*datastore.ListAttestedNodesRequest, error) {
+	if filter == nil {
+		return nil, errors.New("filter is required")
+	}
+
+	listReq := &datastore.ListAttestedNodesRequest{
+		ByAttestationType: filter.ByAttestationType,
+		ByBanned:          filter.ByBanned,
+		ByCanReattest:     filter.ByCanReattest,
+	}
+	if filter.ByExpiresBefore < 0 {
+		return nil, errors.New("expires before time cannot be negative")
+	}
+	if filter.ByExpiresBefore > 0 {
+		listReq.ByExpiresBefore = time.Unix(filter.ByExpiresBefore, 0)
+	}
+	if filter.BySelectorMatch != nil {
+		if len(filter.BySelectorMatch.Selectors) == 0 {
+			return nil, errors.New("selector match requires at least one selector")
+		}
+		selectors, err := api.SelectorsFromProto(protoFromAgentFilterSelectors(filter.BySelectorMatch.Selectors))
+		if err != nil {
+			return nil, err
+		}
+		listReq.BySelectorMatch = &datastore.BySelectors{
+			Match:     datastore.MatchBehavior(filter.BySelectorMatch.Match),
+			Selectors: selectors,
+		}
+	}
+
+	if listReq.ByAttestationType == "" && listReq.ByBanned == nil && listReq.ByCanReattest == nil &&
+		listReq.ByExpiresBefore.IsZero() && listReq.BySelectorMatch == nil {
+		return nil, errors.New("at least one filter criteria is required")
+	}
+	return listReq, nil
+}
+
+func protoFromAgentFilterSelectors(selectors []*agentbatchv1.Selector) []*types.Selector {
+	protos := make([]*types.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		protos = append(protos, &types.Selector{Type: s.Type, Value: s.Value})
+	}
+	return protos
+}
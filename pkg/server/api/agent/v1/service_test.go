@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -1206,6 +1208,330 @@ func TestBanAgent(t *testing.T) {
 	}
 }
 
+func TestBatchBanAgents(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		filter        *agentbatchv1.AgentFilter
+		ids           []string
+		dryRun        bool
+		dsError       error
+		expectCode    codes.Code
+		expectMsg     string
+		expectResults []*agentbatchv1.AgentResult
+		expectBanned  []string
+		expectLogs    []spiretest.LogEntry
+	}{
+		{
+			name:   "dry run",
+			filter: &agentbatchv1.AgentFilter{ByAttestationType: "type-1"},
+			dryRun: true,
+			expectResults: []*agentbatchv1.AgentResult{
+				{Id: agent1, AttestationType: "type-1", X509SvidExpiresAt: 1},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.NodeAttestorType: "type-1",
+						telemetry.DryRun:           "true",
+					},
+				},
+			},
+		},
+		{
+			name: "ban agents matching selectors",
+			filter: &agentbatchv1.AgentFilter{
+				BySelectorMatch: &agentbatchv1.SelectorMatch{
+					Selectors: []*agentbatchv1.Selector{{Type: "node-selector-type-1", Value: "node-selector-value-1"}},
+					Match:     agentbatchv1.SelectorMatch_MATCH_SUPERSET,
+				},
+			},
+			expectResults: []*agentbatchv1.AgentResult{
+				{Id: agent1, AttestationType: "type-1", X509SvidExpiresAt: 1, StatusMessage: "OK"},
+			},
+			expectBanned: []string{agent1},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Agent banned",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: agent1,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: agent1,
+					},
+				},
+			},
+		},
+		{
+			name:   "restricted to IDs",
+			filter: &agentbatchv1.AgentFilter{ByBanned: proto.Bool(true)},
+			ids:    []string{agent1, agent2},
+			expectResults: []*agentbatchv1.AgentResult{
+				{Id: agent2, AttestationType: "type-2", X509SvidExpiresAt: 3, Banned: true, StatusMessage: "OK"},
+				{Id: agent1, StatusCode: int32(codes.FailedPrecondition), StatusMessage: "agent does not match the filter"},
+			},
+			expectBanned: []string{agent2},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Agent banned",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: agent2,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: agent2,
+					},
+				},
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent does not match the filter",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: agent1,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      agent1,
+						telemetry.StatusCode:    "FailedPrecondition",
+						telemetry.StatusMessage: "agent does not match the filter",
+					},
+				},
+			},
+		},
+		{
+			name:       "empty filter",
+			filter:     &agentbatchv1.AgentFilter{},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "invalid filter: at least one filter criteria is required",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid filter",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "at least one filter criteria is required",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid filter: at least one filter criteria is required",
+					},
+				},
+			},
+		},
+		{
+			name:       "invalid ID",
+			filter:     &agentbatchv1.AgentFilter{ByAttestationType: "type-1"},
+			ids:        []string{"spiffe://example.org/workload"},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  `invalid agent ID: "spiffe://example.org/workload" is not an agent in trust domain "example.org"; path is not in the agent namespace`,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid agent ID",
+					Data: logrus.Fields{
+						logrus.ErrorKey: `"spiffe://example.org/workload" is not an agent in trust domain "example.org"; path is not in the agent namespace`,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.NodeAttestorType: "type-1",
+						telemetry.DryRun:           "false",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    `invalid agent ID: "spiffe://example.org/workload" is not an agent in trust domain "example.org"; path is not in the agent namespace`,
+					},
+				},
+			},
+		},
+		{
+			name:       "datastore error",
+			filter:     &agentbatchv1.AgentFilter{ByAttestationType: "type-1"},
+			dsError:    errors.New("some error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to list agents: some error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list agents",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "some error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.NodeAttestorType: "type-1",
+						telemetry.DryRun:           "false",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "failed to list agents: some error",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+			test.createTestNodes(ctx, t)
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.batchClient.BatchBanAgents(ctx, &agentbatchv1.BatchBanAgentsRequest{
+				Filter: tt.filter,
+				Ids:    tt.ids,
+				DryRun: tt.dryRun,
+			})
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				require.Nil(t, resp)
+				return
+			}
+			spiretest.RequireProtoListEqual(t, tt.expectResults, resp.Results)
+
+			for id := range testNodes {
+				attestedNode, err := test.ds.FetchAttestedNode(ctx, id)
+				require.NoError(t, err)
+				require.Equal(t, slices.Contains(tt.expectBanned, id) || testNodes[id].CertSerialNumber == "", attestedNode.CertSerialNumber == "")
+			}
+		})
+	}
+}
+
+func TestBatchEvictAgents(t *testing.T) {
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+	test.createTestNodes(ctx, t)
+
+	filter := &agentbatchv1.AgentFilter{ByExpiresBefore: 2}
+
+	// Dry runs leave agents untouched
+	resp, err := test.batchClient.BatchEvictAgents(ctx, &agentbatchv1.BatchEvictAgentsRequest{
+		Filter: filter,
+		DryRun: true,
+	})
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, []*agentbatchv1.AgentResult{
+		{Id: agent1, AttestationType: "type-1", X509SvidExpiresAt: 1},
+	}, resp.Results)
+	attestedNode, err := test.ds.FetchAttestedNode(ctx, agent1)
+	require.NoError(t, err)
+	require.NotNil(t, attestedNode)
+
+	resp, err = test.batchClient.BatchEvictAgents(ctx, &agentbatchv1.BatchEvictAgentsRequest{
+		Filter: filter,
+	})
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, []*agentbatchv1.AgentResult{
+		{Id: agent1, AttestationType: "type-1", X509SvidExpiresAt: 1, StatusMessage: "OK"},
+	}, resp.Results)
+
+	attestedNode, err = test.ds.FetchAttestedNode(ctx, agent1)
+	require.NoError(t, err)
+	require.Nil(t, attestedNode)
+	attestedNode, err = test.ds.FetchAttestedNode(ctx, agent2)
+	require.NoError(t, err)
+	require.NotNil(t, attestedNode)
+}
+
+func TestBatchPurgeAgents(t *testing.T) {
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+
+	now := test.clk.Now()
+	expired := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/expired",
+		AttestationDataType: "type-1",
+		CertSerialNumber:    "CertSerialNumber-expired",
+		CertNotAfter:        now.Add(-2 * time.Hour).Unix(),
+		CanReattest:         true,
+	}
+	recentlyExpired := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/recently-expired",
+		AttestationDataType: "type-1",
+		CertSerialNumber:    "CertSerialNumber-recently-expired",
+		CertNotAfter:        now.Add(-time.Minute).Unix(),
+		CanReattest:         true,
+	}
+	cannotReattest := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/cannot-reattest",
+		AttestationDataType: "type-1",
+		CertSerialNumber:    "CertSerialNumber-cannot-reattest",
+		CertNotAfter:        now.Add(-2 * time.Hour).Unix(),
+	}
+	for _, node := range []*common.AttestedNode{expired, recentlyExpired, cannotReattest} {
+		_, err := test.ds.CreateAttestedNode(ctx, node)
+		require.NoError(t, err)
+	}
+
+	_, err := test.batchClient.BatchPurgeAgents(ctx, &agentbatchv1.BatchPurgeAgentsRequest{
+		ExpiredFor: -1,
+	})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "invalid filter: expired for time cannot be negative")
+
+	// Dry runs leave agents untouched
+	resp, err := test.batchClient.BatchPurgeAgents(ctx, &agentbatchv1.BatchPurgeAgentsRequest{
+		ExpiredFor: int64(time.Hour / time.Second),
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, []*agentbatchv1.AgentResult{
+		{Id: expired.SpiffeId, AttestationType: "type-1", X509SvidExpiresAt: expired.CertNotAfter, CanReattest: true},
+	}, resp.Results)
+	attestedNode, err := test.ds.FetchAttestedNode(ctx, expired.SpiffeId)
+	require.NoError(t, err)
+	require.NotNil(t, attestedNode)
+
+	resp, err = test.batchClient.BatchPurgeAgents(ctx, &agentbatchv1.BatchPurgeAgentsRequest{
+		ExpiredFor: int64(time.Hour / time.Second),
+		Ids:        []string{expired.SpiffeId, cannotReattest.SpiffeId},
+	})
+	require.NoError(t, err)
+	spiretest.RequireProtoListEqual(t, []*agentbatchv1.AgentResult{
+		{Id: expired.SpiffeId, AttestationType: "type-1", X509SvidExpiresAt: expired.CertNotAfter, CanReattest: true, StatusMessage: "OK"},
+		{Id: cannotReattest.SpiffeId, StatusCode: int32(codes.FailedPrecondition), StatusMessage: "agent does not match the filter"},
+	}, resp.Results)
+
+	attestedNode, err = test.ds.FetchAttestedNode(ctx, expired.SpiffeId)
+	require.NoError(t, err)
+	require.Nil(t, attestedNode)
+	for _, id := range []string{recentlyExpired.SpiffeId, cannotReattest.SpiffeId} {
+		attestedNode, err = test.ds.FetchAttestedNode(ctx, id)
+		require.NoError(t, err)
+		require.NotNil(t, attestedNode)
+	}
+}
+
 func TestDeleteAgent(t *testing.T) {
 	node1 := &common.AttestedNode{
 		SpiffeId: "spiffe://example.org/spire/agent/node1",
@@ -3195,6 +3521,7 @@ func TestAttestAgent(t *testing.T) {
 
 type serviceTest struct {
 	client       agentv1.AgentClient
+	batchClient  agentbatchv1.AgentBatchClient
 	done         func()
 	ds           *fakedatastore.DataStore
 	ca           *fakeserverca.CA
@@ -3262,6 +3589,7 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration) *serviceTest {
 	conn := server.NewGRPCClient(t)
 
 	test.client = agentv1.NewAgentClient(conn)
+	test.batchClient = agentbatchv1.NewAgentBatchClient(conn)
 	test.done = server.Stop
 
 	return test
@@ -8,6 +8,8 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
+	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -39,7 +41,7 @@ func RegistrationEntryToProto(e *common.RegistrationEntry) (*types.Entry, error)
 		return nil, errors.New("missing registration entry")
 	}
 
-	spiffeID, err := spiffeid.FromString(e.SpiffeId)
+	spiffeID, err := spiffeIDProtoFromString(e.SpiffeId)
 	if err != nil {
 		return nil, fmt.Errorf("invalid SPIFFE ID: %w", err)
 	}
@@ -63,7 +65,7 @@ func RegistrationEntryToProto(e *common.RegistrationEntry) (*types.Entry, error)
 
 	return &types.Entry{
 		Id:             e.EntryId,
-		SpiffeId:       ProtoFromID(spiffeID),
+		SpiffeId:       spiffeID,
 		ParentId:       ProtoFromID(parentID),
 		Selectors:      ProtoFromSelectors(e.Selectors),
 		X509SvidTtl:    e.X509SvidTtl,
@@ -107,11 +109,21 @@ func ProtoToRegistrationEntryWithMask(ctx context.Context, td spiffeid.TrustDoma
 		}
 	}
 
-	var spiffeID spiffeid.ID
+	var spiffeID string
+	var isTemplate bool
 	if mask.SpiffeId {
-		spiffeID, err = TrustDomainWorkloadIDFromProto(ctx, td, e.SpiffeId)
-		if err != nil {
-			return nil, fmt.Errorf("invalid spiffe ID: %w", err)
+		if isTemplate = entrytemplate.IsTemplate(e.SpiffeId.GetPath()); isTemplate {
+			tmpl, err := TrustDomainWorkloadIDTemplateFromProto(ctx, td, e.SpiffeId)
+			if err != nil {
+				return nil, fmt.Errorf("invalid spiffe ID template: %w", err)
+			}
+			spiffeID = tmpl.String()
+		} else {
+			id, err := TrustDomainWorkloadIDFromProto(ctx, td, e.SpiffeId)
+			if err != nil {
+				return nil, fmt.Errorf("invalid spiffe ID: %w", err)
+			}
+			spiffeID = id.String()
 		}
 	}
 
@@ -191,10 +203,27 @@ func ProtoToRegistrationEntryWithMask(ctx context.Context, td spiffeid.TrustDoma
 		}
 		hint = e.Hint
 	}
+
+	if isTemplate {
+		// Template entries are expanded for each agent they are authorized
+		// for, so they cannot be used to identify nodes, admins, downstream
+		// servers or SVIDStore workloads.
+		switch {
+		case parentID.Path() == idutil.ServerIDPath:
+			return nil, errors.New("template entries cannot be node entries")
+		case admin:
+			return nil, errors.New("template entries cannot be admin entries")
+		case downstream:
+			return nil, errors.New("template entries cannot be downstream entries")
+		case storeSVID:
+			return nil, errors.New("template entries cannot store SVIDs")
+		}
+	}
+
 	return &common.RegistrationEntry{
 		EntryId:        e.Id,
 		ParentId:       parentID.String(),
-		SpiffeId:       spiffeID.String(),
+		SpiffeId:       spiffeID,
 		Admin:          admin,
 		DnsNames:       dnsNames,
 		Downstream:     downstream,
@@ -208,3 +237,24 @@ func ProtoToRegistrationEntryWithMask(ctx context.Context, td spiffeid.TrustDoma
 		Hint:           hint,
 	}, nil
 }
+
+// spiffeIDProtoFromString converts the SPIFFE ID of a registration entry,
+// which may be a SPIFFE ID template, into its proto representation.
+func spiffeIDProtoFromString(s string) (*types.SPIFFEID, error) {
+	if entrytemplate.IsTemplate(s) {
+		tmpl, err := entrytemplate.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return &types.SPIFFEID{
+			TrustDomain: tmpl.TrustDomain().Name(),
+			Path:        tmpl.Path(),
+		}, nil
+	}
+
+	id, err := spiffeid.FromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ProtoFromID(id), nil
+}
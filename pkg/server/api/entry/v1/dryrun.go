@@ -0,0 +1,423 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrydryrun"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+)
+
+// dryRunEntryIDPrefix prefixes the placeholder IDs given to entries that
+// would be created with a server generated ID, so they can be tracked in the
+// authorized entries snapshot. It contains a character that is not valid in
+// entry IDs so it cannot conflict with real entry IDs.
+const dryRunEntryIDPrefix = "dry-run#"
+
+// entryServiceName is the name of the Entry service, whose methods dry-runs
+// report on.
+const entryServiceName = "spire.api.server.entry.v1.Entry"
+
+// DryRunBatchCreateEntry reports the changes that BatchCreateEntry would make
+// for the given entries, without making them.
+func (s *Service) DryRunBatchCreateEntry(ctx context.Context, req *entrydryrunv1.DryRunBatchCreateEntryRequest) (*entrydryrunv1.DryRunBatchCreateEntryResponse, error) {
+	dr := startDryRun(ctx, "BatchCreateEntry")
+	for _, eachEntry := range req.Entries {
+		r := s.createEntry(ctx, eachEntry, nil, dr)
+		dr.endEntry(r.Status)
+		rpccontext.AuditRPCWithTypesStatus(ctx, r.Status, func() logrus.Fields {
+			return fieldsFromEntryProto(ctx, eachEntry, nil)
+		})
+	}
+
+	changes, err := s.finishDryRun(ctx, dr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entrydryrunv1.DryRunBatchCreateEntryResponse{
+		Results:              dr.results,
+		AuthorizationChanges: changes,
+	}, nil
+}
+
+// DryRunBatchUpdateEntry reports the changes that BatchUpdateEntry would make
+// for the given entries, without making them.
+func (s *Service) DryRunBatchUpdateEntry(ctx context.Context, req *entrydryrunv1.DryRunBatchUpdateEntryRequest) (*entrydryrunv1.DryRunBatchUpdateEntryResponse, error) {
+	dr := startDryRun(ctx, "BatchUpdateEntry")
+	for _, eachEntry := range req.Entries {
+		r := s.updateEntry(ctx, eachEntry, req.InputMask, nil, dr)
+		dr.endEntry(r.Status)
+		rpccontext.AuditRPCWithTypesStatus(ctx, r.Status, func() logrus.Fields {
+			return fieldsFromEntryProto(ctx, eachEntry, req.InputMask)
+		})
+	}
+
+	changes, err := s.finishDryRun(ctx, dr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entrydryrunv1.DryRunBatchUpdateEntryResponse{
+		Results:              dr.results,
+		AuthorizationChanges: changes,
+	}, nil
+}
+
+// dryRun tracks the state of a dry-run request. Changes planned for earlier
+// entries of the batch are taken into account for later ones, so the outcome
+// matches that of applying the batch.
+type dryRun struct {
+	// method is the Entry service method that the dry-run reports on
+	method string
+
+	results []*entrydryrunv1.EntryResult
+
+	// current holds the outcome planned for the entry being processed, if
+	// any
+	current *entrydryrunv1.EntryResult
+
+	// created holds the entries that would be created
+	created []*common.RegistrationEntry
+
+	// updated holds the entries that would be updated, keyed by entry ID
+	updated map[string]*common.RegistrationEntry
+}
+
+func startDryRun(ctx context.Context, method string) *dryRun {
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.DryRun: true})
+	return &dryRun{
+		method:  method,
+		updated: make(map[string]*common.RegistrationEntry),
+	}
+}
+
+// finishDryRun returns the agent authorization changes of the dry-run, if the
+// authorized entries are available.
+func (s *Service) finishDryRun(ctx context.Context, dr *dryRun) ([]*entrydryrunv1.AuthorizationChange, error) {
+	if s.aes == nil || (len(dr.created) == 0 && len(dr.updated) == 0) {
+		return nil, nil
+	}
+
+	changes, err := s.authorizationChanges(ctx, dr)
+	if err != nil {
+		return nil, api.MakeErr(rpccontext.Logger(ctx), codes.Internal, "failed to determine authorization changes", err)
+	}
+	return changes, nil
+}
+
+// endEntry records the outcome of the current entry. Entries that fail
+// before being planned are recorded as failed.
+func (dr *dryRun) endEntry(status *types.Status) {
+	result := dr.current
+	dr.current = nil
+	if result == nil {
+		result = &entrydryrunv1.EntryResult{
+			Action: entrydryrunv1.Action_ACTION_FAILED,
+		}
+	}
+	result.Status = status
+	dr.results = append(dr.results, result)
+}
+
+// authorizationContext returns the context used to authorize entries. Entries
+// of a dry-run are authorized as for the Entry service method it reports on,
+// so the policy rules for that method apply. It can be called on a nil
+// dry-run.
+func (dr *dryRun) authorizationContext(ctx context.Context) context.Context {
+	if dr == nil {
+		return ctx
+	}
+	names, _ := rpccontext.Names(ctx)
+	names.RawService = entryServiceName
+	names.Method = dr.method
+	return rpccontext.WithNames(ctx, names)
+}
+
+// fetchEntry fetches the entry with the given ID, as it would be after the
+// changes planned so far. It can be called on a nil dry-run.
+func (dr *dryRun) fetchEntry(ctx context.Context, ds datastore.DataStore, entryID string) (*common.RegistrationEntry, error) {
+	if dr != nil {
+		if entry, ok := dr.updated[entryID]; ok {
+			return entry, nil
+		}
+	}
+	return ds.FetchRegistrationEntry(ctx, entryID)
+}
+
+func (s *Service) dryRunCreateEntry(ctx context.Context, log logrus.FieldLogger, dr *dryRun, cEntry *common.RegistrationEntry, outputMask *types.EntryMask) *entryv1.BatchCreateEntryResponse_Result {
+	if err := datastore.ValidateRegistrationEntry(cEntry); err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "failed to create entry", err),
+		}
+	}
+
+	similar, err := s.findSimilarEntry(ctx, dr, cEntry)
+	if err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.Internal, "failed to create entry", err),
+		}
+	}
+	if similar != nil {
+		tEntry, err := api.RegistrationEntryToProto(similar)
+		if err != nil {
+			return &entryv1.BatchCreateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.Internal, "failed to convert entry", err),
+			}
+		}
+		dr.current = &entrydryrunv1.EntryResult{
+			Action:   entrydryrunv1.Action_ACTION_UNCHANGED,
+			EntryId:  similar.EntryId,
+			SpiffeId: similar.SpiffeId,
+		}
+		applyMask(tEntry, outputMask)
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.CreateStatus(codes.AlreadyExists, "similar entry already exists"),
+			Entry:  tEntry,
+		}
+	}
+
+	if cEntry.EntryId != "" {
+		existing, err := dr.fetchEntry(ctx, s.ds, cEntry.EntryId)
+		if err == nil && existing == nil {
+			existing = findEntry(dr.created, func(e *common.RegistrationEntry) bool { return e.EntryId == cEntry.EntryId })
+		}
+		switch {
+		case err != nil:
+			return &entryv1.BatchCreateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.Internal, "failed to fetch entry", err),
+			}
+		case existing != nil:
+			return &entryv1.BatchCreateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.AlreadyExists, "failed to create entry", errors.New("entry ID is already in use")),
+			}
+		}
+	}
+
+	tEntry, err := api.RegistrationEntryToProto(cEntry)
+	if err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.Internal, "failed to convert entry", err),
+		}
+	}
+
+	dr.created = append(dr.created, cEntry)
+	dr.current = &entrydryrunv1.EntryResult{
+		Action:   entrydryrunv1.Action_ACTION_CREATE,
+		EntryId:  cEntry.EntryId,
+		SpiffeId: cEntry.SpiffeId,
+		Diffs:    diffsToProto(entrydryrun.Diff(nil, tEntry)),
+	}
+
+	applyMask(tEntry, outputMask)
+	return &entryv1.BatchCreateEntryResponse_Result{
+		Status: api.OK(),
+		Entry:  tEntry,
+	}
+}
+
+func dryRunUpdateEntry(log logrus.FieldLogger, dr *dryRun, existing, convEntry, merged *common.RegistrationEntry, mask *common.RegistrationEntryMask, outputMask *types.EntryMask) *entryv1.BatchUpdateEntryResponse_Result {
+	if err := datastore.ValidateRegistrationEntryForUpdate(convEntry, mask); err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "failed to update entry", err),
+		}
+	}
+	// The update may leave the entry invalid even if the updated fields are
+	// valid, e.g. when storing SVIDs of an entry with mixed selector types.
+	if err := datastore.ValidateRegistrationEntry(merged); err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.InvalidArgument, "failed to update entry", err),
+		}
+	}
+
+	merged.CreatedAt = existing.CreatedAt
+	merged.RevisionNumber = existing.RevisionNumber
+
+	tExisting, err := api.RegistrationEntryToProto(existing)
+	if err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.Internal, "failed to convert entry", err),
+		}
+	}
+	tEntry, err := api.RegistrationEntryToProto(merged)
+	if err != nil {
+		return &entryv1.BatchUpdateEntryResponse_Result{
+			Status: api.MakeStatus(log, codes.Internal, "failed to convert entry", err),
+		}
+	}
+
+	action := entrydryrunv1.Action_ACTION_UNCHANGED
+	diffs := entrydryrun.Diff(tExisting, tEntry)
+	if len(diffs) > 0 {
+		action = entrydryrunv1.Action_ACTION_UPDATE
+		merged.RevisionNumber++
+		tEntry.RevisionNumber++
+		dr.updated[merged.EntryId] = merged
+	}
+
+	dr.current = &entrydryrunv1.EntryResult{
+		Action:   action,
+		EntryId:  merged.EntryId,
+		SpiffeId: merged.SpiffeId,
+		Diffs:    diffsToProto(diffs),
+	}
+
+	applyMask(tEntry, outputMask)
+	return &entryv1.BatchUpdateEntryResponse_Result{
+		Status: api.OK(),
+		Entry:  tEntry,
+	}
+}
+
+// findSimilarEntry returns the entry, if any, that the datastore would return
+// instead of creating the given entry, taking into account the entries that
+// would be created earlier in the batch.
+func (s *Service) findSimilarEntry(ctx context.Context, dr *dryRun, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	isSimilar := func(e *common.RegistrationEntry) bool {
+		return datastore.IsSimilarEntry(e, entry)
+	}
+
+	if similar := findEntry(dr.created, isSimilar); similar != nil {
+		return similar, nil
+	}
+
+	resp, err := s.ds.ListRegistrationEntries(ctx, datastore.SimilarEntriesRequest(entry))
+	if err != nil {
+		return nil, err
+	}
+	return findEntry(resp.Entries, isSimilar), nil
+}
+
+// authorizationChanges applies the planned changes to a snapshot of the
+// authorized entries cache and returns the entries whose set of authorized
+// agents changes.
+func (s *Service) authorizationChanges(ctx context.Context, dr *dryRun) ([]*entrydryrunv1.AuthorizationChange, error) {
+	cache, err := s.aes.SnapshotAuthorizedEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	before := cache.AuthorizedAgents()
+
+	spiffeIDs := make(map[string]string)
+	apply := func(entryID string, entry *common.RegistrationEntry) error {
+		tEntry, err := api.RegistrationEntryToProto(entry)
+		if err != nil {
+			return fmt.Errorf("failed to convert entry: %w", err)
+		}
+		tEntry.Id = entryID
+		cache.UpdateEntry(tEntry)
+		spiffeIDs[entryID] = entry.SpiffeId
+		return nil
+	}
+	for i, entry := range dr.created {
+		entryID := entry.EntryId
+		if entryID == "" {
+			entryID = fmt.Sprintf("%s%d", dryRunEntryIDPrefix, i)
+		}
+		if err := apply(entryID, entry); err != nil {
+			return nil, err
+		}
+	}
+	for entryID, entry := range dr.updated {
+		if err := apply(entryID, entry); err != nil {
+			return nil, err
+		}
+	}
+
+	after := cache.AuthorizedAgents()
+
+	var changes []*entrydryrunv1.AuthorizationChange
+	var unknownEntryIDs []string
+	for _, entryID := range slices.Sorted(maps.Keys(union(before, after))) {
+		gained := difference(after[entryID], before[entryID])
+		lost := difference(before[entryID], after[entryID])
+		if len(gained) == 0 && len(lost) == 0 {
+			continue
+		}
+		if _, ok := spiffeIDs[entryID]; !ok {
+			unknownEntryIDs = append(unknownEntryIDs, entryID)
+		}
+		changes = append(changes, &entrydryrunv1.AuthorizationChange{
+			EntryId:      entryID,
+			AgentsGained: gained,
+			AgentsLost:   lost,
+		})
+	}
+
+	// Entries that are not part of the request are affected through one of
+	// their ancestors. Look up their SPIFFE IDs to make the report readable.
+	if len(unknownEntryIDs) > 0 {
+		entries, err := s.ds.FetchRegistrationEntries(ctx, unknownEntryIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch entries: %w", err)
+		}
+		for entryID, entry := range entries {
+			spiffeIDs[entryID] = entry.SpiffeId
+		}
+	}
+
+	for _, change := range changes {
+		change.SpiffeId = spiffeIDs[change.EntryId]
+		if strings.HasPrefix(change.EntryId, dryRunEntryIDPrefix) {
+			change.EntryId = ""
+		}
+	}
+	return changes, nil
+}
+
+func findEntry(entries []*common.RegistrationEntry, match func(*common.RegistrationEntry) bool) *common.RegistrationEntry {
+	if i := slices.IndexFunc(entries, match); i >= 0 {
+		return entries[i]
+	}
+	return nil
+}
+
+func diffsToProto(diffs []entrydryrun.FieldDiff) []*entrydryrunv1.FieldDiff {
+	var pbDiffs []*entrydryrunv1.FieldDiff
+	for _, diff := range diffs {
+		pbDiffs = append(pbDiffs, &entrydryrunv1.FieldDiff{
+			Field: diff.Field,
+			Old:   diff.Old,
+			New:   diff.New,
+		})
+	}
+	return pbDiffs
+}
+
+// union returns the set of keys in either map.
+func union(a, b map[string][]string) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+// difference returns the values of a that are not in b. Both slices must be
+// sorted.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, value := range a {
+		if _, found := slices.BinarySearch(b, value); !found {
+			diff = append(diff, value)
+		}
+	}
+	return diff
+}
@@ -15,30 +15,64 @@ import (
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/authorizedentries"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const defaultEntryPageSize = 500
 
+// EntryAuthorizer authorizes the caller to act on an individual entry of a
+// batch request. The existing entry is only provided for updates.
+//
+// Updated and deleted entries are fetched and authorized before being
+// written, but not atomically with the write, since the datastore has no
+// conditional writes. A change made concurrently to the same entry by another
+// caller is not taken into account: an update applies its fields on top of
+// the entry as it is at the time of the write, and a delete removes it
+// whatever it has become.
+type EntryAuthorizer interface {
+	AuthorizeEntry(ctx context.Context, entry, existing *types.Entry) error
+}
+
+// AuthorizedEntriesSnapshotter provides snapshots of the authorized entries
+// cache. Snapshots are modified by dry-run requests to determine how the
+// requested changes affect agent authorization.
+type AuthorizedEntriesSnapshotter interface {
+	SnapshotAuthorizedEntries(ctx context.Context) (*authorizedentries.Cache, error)
+}
+
 // Config defines the service configuration.
 type Config struct {
 	TrustDomain   spiffeid.TrustDomain
 	EntryFetcher  api.AuthorizedEntryFetcher
 	DataStore     datastore.DataStore
 	EntryPageSize int
+
+	// EntryAuthorizer, if set, is consulted for each entry of batch create,
+	// update and delete requests.
+	EntryAuthorizer EntryAuthorizer
+
+	// AuthorizedEntries, if set, is used to report the agent authorization
+	// changes of dry-run requests.
+	AuthorizedEntries AuthorizedEntriesSnapshotter
 }
 
 // Service defines the v1 entry service.
 type Service struct {
 	entryv1.UnsafeEntryServer
+	entrydryrunv1.UnsafeEntryDryRunServer
 
 	td            spiffeid.TrustDomain
 	ds            datastore.DataStore
 	ef            api.AuthorizedEntryFetcher
+	ea            EntryAuthorizer
+	aes           AuthorizedEntriesSnapshotter
 	entryPageSize int
 }
 
@@ -51,6 +85,8 @@ func New(config Config) *Service {
 		td:            config.TrustDomain,
 		ds:            config.DataStore,
 		ef:            config.EntryFetcher,
+		ea:            config.EntryAuthorizer,
+		aes:           config.AuthorizedEntries,
 		entryPageSize: config.EntryPageSize,
 	}
 }
@@ -58,6 +94,7 @@ func New(config Config) *Service {
 // RegisterService registers the entry service on the gRPC server.
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	entryv1.RegisterEntryServer(s, service)
+	entrydryrunv1.RegisterEntryDryRunServer(s, service)
 }
 
 // CountEntries returns the total number of entries.
@@ -263,7 +300,7 @@ func (s *Service) GetEntry(ctx context.Context, req *entryv1.GetEntryRequest) (*
 func (s *Service) BatchCreateEntry(ctx context.Context, req *entryv1.BatchCreateEntryRequest) (*entryv1.BatchCreateEntryResponse, error) {
 	var results []*entryv1.BatchCreateEntryResponse_Result
 	for _, eachEntry := range req.Entries {
-		r := s.createEntry(ctx, eachEntry, req.OutputMask)
+		r := s.createEntry(ctx, eachEntry, req.OutputMask, nil)
 		results = append(results, r)
 		rpccontext.AuditRPCWithTypesStatus(ctx, r.Status, func() logrus.Fields {
 			return fieldsFromEntryProto(ctx, eachEntry, nil)
@@ -275,7 +312,7 @@ func (s *Service) BatchCreateEntry(ctx context.Context, req *entryv1.BatchCreate
 	}, nil
 }
 
-func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *types.EntryMask) *entryv1.BatchCreateEntryResponse_Result {
+func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *types.EntryMask, dr *dryRun) *entryv1.BatchCreateEntryResponse_Result {
 	log := rpccontext.Logger(ctx)
 
 	cEntry, err := api.ProtoToRegistrationEntry(ctx, s.td, e)
@@ -287,6 +324,16 @@ func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *t
 
 	log = log.WithField(telemetry.SPIFFEID, cEntry.SpiffeId)
 
+	if err := s.authorizeEntry(dr.authorizationContext(ctx), cEntry, nil); err != nil {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Status: authorizationStatus(log, err),
+		}
+	}
+
+	if dr != nil {
+		return s.dryRunCreateEntry(ctx, log, dr, cEntry, outputMask)
+	}
+
 	resultStatus := api.OK()
 	regEntry, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, cEntry)
 	switch {
@@ -322,7 +369,7 @@ func (s *Service) BatchUpdateEntry(ctx context.Context, req *entryv1.BatchUpdate
 	var results []*entryv1.BatchUpdateEntryResponse_Result
 
 	for _, eachEntry := range req.Entries {
-		e := s.updateEntry(ctx, eachEntry, req.InputMask, req.OutputMask)
+		e := s.updateEntry(ctx, eachEntry, req.InputMask, req.OutputMask, nil)
 		results = append(results, e)
 		rpccontext.AuditRPCWithTypesStatus(ctx, e.Status, func() logrus.Fields {
 			return fieldsFromEntryProto(ctx, eachEntry, req.InputMask)
@@ -362,6 +409,30 @@ func (s *Service) deleteEntry(ctx context.Context, id string) *entryv1.BatchDele
 
 	log = log.WithField(telemetry.RegistrationID, id)
 
+	if s.ea != nil {
+		// The entry is authorized as fetched here. See EntryAuthorizer for
+		// concurrent changes before the deletion.
+		entry, err := s.ds.FetchRegistrationEntry(ctx, id)
+		switch {
+		case err != nil:
+			return &entryv1.BatchDeleteEntryResponse_Result{
+				Id:     id,
+				Status: api.MakeStatus(log, codes.Internal, "failed to fetch entry", err),
+			}
+		case entry == nil:
+			return &entryv1.BatchDeleteEntryResponse_Result{
+				Id:     id,
+				Status: api.MakeStatus(log, codes.NotFound, "entry not found", nil),
+			}
+		}
+		if err := s.authorizeEntry(ctx, entry, nil); err != nil {
+			return &entryv1.BatchDeleteEntryResponse_Result{
+				Id:     id,
+				Status: authorizationStatus(log, err),
+			}
+		}
+	}
+
 	_, err := s.ds.DeleteRegistrationEntry(ctx, id)
 	switch status.Code(err) {
 	case codes.OK:
@@ -635,7 +706,7 @@ func applyMask(e *types.Entry, mask *types.EntryMask) {
 	}
 }
 
-func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *types.EntryMask, outputMask *types.EntryMask) *entryv1.BatchUpdateEntryResponse_Result {
+func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *types.EntryMask, outputMask *types.EntryMask, dr *dryRun) *entryv1.BatchUpdateEntryResponse_Result {
 	log := rpccontext.Logger(ctx)
 	log = log.WithField(telemetry.RegistrationID, e.Id)
 
@@ -663,6 +734,32 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 			Hint:          inputMask.Hint,
 		}
 	}
+
+	if s.ea != nil || dr != nil {
+		// The update is authorized against the entry as fetched here. See
+		// EntryAuthorizer for concurrent changes before the update.
+		existing, err := dr.fetchEntry(ctx, s.ds, convEntry.EntryId)
+		switch {
+		case err != nil:
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.Internal, "failed to fetch entry", err),
+			}
+		case existing == nil:
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, codes.NotFound, "failed to update entry", errors.New("entry not found")),
+			}
+		}
+		merged := mergeEntry(existing, convEntry, mask)
+		if err := s.authorizeEntry(dr.authorizationContext(ctx), merged, existing); err != nil {
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: authorizationStatus(log, err),
+			}
+		}
+		if dr != nil {
+			return dryRunUpdateEntry(log, dr, existing, convEntry, merged, mask, outputMask)
+		}
+	}
+
 	dsEntry, err := s.ds.UpdateRegistrationEntry(ctx, convEntry, mask)
 	if err != nil {
 		statusCode := status.Code(err)
@@ -689,6 +786,82 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 	}
 }
 
+// authorizeEntry consults the entry authorizer, if any, for the given entry.
+func (s *Service) authorizeEntry(ctx context.Context, entry, existing *common.RegistrationEntry) error {
+	if s.ea == nil {
+		return nil
+	}
+
+	tEntry, err := api.RegistrationEntryToProto(entry)
+	if err != nil {
+		return err
+	}
+	var tExisting *types.Entry
+	if existing != nil {
+		tExisting, err = api.RegistrationEntryToProto(existing)
+		if err != nil {
+			return err
+		}
+	}
+	return s.ea.AuthorizeEntry(ctx, tEntry, tExisting)
+}
+
+func authorizationStatus(log logrus.FieldLogger, err error) *types.Status {
+	if status.Code(err) == codes.PermissionDenied {
+		return api.MakeStatus(log, codes.PermissionDenied, "entry is not authorized by policy", nil)
+	}
+	return api.MakeStatus(log, codes.Internal, "failed to authorize entry", err)
+}
+
+// mergeEntry returns the entry that results from applying the update to the
+// existing entry, honoring the mask in the same way the datastore does.
+func mergeEntry(existing, update *common.RegistrationEntry, mask *common.RegistrationEntryMask) *common.RegistrationEntry {
+	if mask == nil {
+		merged := proto.Clone(update).(*common.RegistrationEntry)
+		merged.EntryId = existing.EntryId
+		return merged
+	}
+
+	merged := proto.Clone(existing).(*common.RegistrationEntry)
+	if mask.SpiffeId {
+		merged.SpiffeId = update.SpiffeId
+	}
+	if mask.ParentId {
+		merged.ParentId = update.ParentId
+	}
+	if mask.Selectors {
+		merged.Selectors = update.Selectors
+	}
+	if mask.FederatesWith {
+		merged.FederatesWith = update.FederatesWith
+	}
+	if mask.Admin {
+		merged.Admin = update.Admin
+	}
+	if mask.Downstream {
+		merged.Downstream = update.Downstream
+	}
+	if mask.EntryExpiry {
+		merged.EntryExpiry = update.EntryExpiry
+	}
+	if mask.DnsNames {
+		merged.DnsNames = update.DnsNames
+	}
+	if mask.StoreSvid {
+		merged.StoreSvid = update.StoreSvid
+	}
+	if mask.X509SvidTtl {
+		merged.X509SvidTtl = update.X509SvidTtl
+	}
+	if mask.JwtSvidTtl {
+		merged.JwtSvidTtl = update.JwtSvidTtl
+	}
+	if mask.Hint {
+		merged.Hint = update.Hint
+	}
+	return merged
+}
+
 func fieldsFromEntryProto(ctx context.Context, proto *types.Entry, inputMask *types.EntryMask) logrus.Fields {
 	fields := logrus.Fields{}
 
@@ -12,6 +12,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -22,7 +23,9 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/entry/v1"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/authorizedentries"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/grpctest"
@@ -4683,6 +4686,385 @@ func TestBatchUpdateEntry(t *testing.T) {
 	}
 }
 
+func TestBatchEntryAuthorization(t *testing.T) {
+	parentID := spiffeid.RequireFromSegments(td, "host")
+	allowedID := spiffeid.RequireFromSegments(td, "ns", "team1", "workload")
+	deniedID := spiffeid.RequireFromSegments(td, "ns", "team2", "workload")
+
+	protoEntry := func(id spiffeid.ID) *types.Entry {
+		return &types.Entry{
+			ParentId:  api.ProtoFromID(parentID),
+			SpiffeId:  api.ProtoFromID(id),
+			Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		}
+	}
+	commonEntry := func(id spiffeid.ID) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			ParentId:  parentID.String(),
+			SpiffeId:  id.String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		}
+	}
+	deniedStatus := &types.Status{
+		Code:    int32(codes.PermissionDenied),
+		Message: "entry is not authorized by policy",
+	}
+
+	t.Run("create", func(t *testing.T) {
+		ea := &fakeEntryAuthorizer{allowedPrefix: "/ns/team1/"}
+		test := setupServiceTest(t, fakedatastore.New(t), withEntryAuthorizer(ea))
+		defer test.Cleanup()
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{protoEntry(allowedID), protoEntry(deniedID)},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[0].Status)
+		spiretest.AssertProtoEqual(t, deniedStatus, resp.Results[1].Status)
+		assert.Nil(t, resp.Results[1].Entry)
+
+		listResp, err := test.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, listResp.Entries, 1)
+		assert.Equal(t, allowedID.String(), listResp.Entries[0].SpiffeId)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		ea := &fakeEntryAuthorizer{allowedPrefix: "/ns/team1/"}
+		ds := fakedatastore.New(t)
+		test := setupServiceTest(t, ds, withEntryAuthorizer(ea))
+		defer test.Cleanup()
+
+		entries := createTestEntries(t, ds, commonEntry(allowedID), commonEntry(deniedID))
+		allowed := entries[allowedID.String()]
+		denied := entries[deniedID.String()]
+
+		resp, err := test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				// Allowed: stays in the namespace
+				{Id: allowed.EntryId, Hint: "updated"},
+				// Denied: the existing entry is outside of the namespace
+				{Id: denied.EntryId, Hint: "updated"},
+				// Allowed: moves the entry out of the namespace, but the SPIFFE ID is masked out
+				{Id: allowed.EntryId, SpiffeId: api.ProtoFromID(deniedID), Hint: "updated"},
+				// Entry does not exist
+				{Id: "missing", Hint: "updated"},
+			},
+			InputMask: &types.EntryMask{Hint: true, SpiffeId: false},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[0].Status)
+		spiretest.AssertProtoEqual(t, deniedStatus, resp.Results[1].Status)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[2].Status)
+		assert.Equal(t, int32(codes.NotFound), resp.Results[3].Status.Code)
+
+		// Denied: moves the entry out of the namespace
+
+		resp, err = test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries:   []*types.Entry{{Id: allowed.EntryId, SpiffeId: api.ProtoFromID(deniedID)}},
+			InputMask: &types.EntryMask{SpiffeId: true},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		spiretest.AssertProtoEqual(t, deniedStatus, resp.Results[0].Status)
+
+		require.Len(t, ea.existing, 4)
+		assert.Equal(t, allowedID.String(), spiffeIDString(ea.existing[3].SpiffeId))
+
+		fetched, err := ds.FetchRegistrationEntry(ctx, allowed.EntryId)
+		require.NoError(t, err)
+		assert.Equal(t, allowedID.String(), fetched.SpiffeId)
+		assert.Equal(t, "updated", fetched.Hint)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		ea := &fakeEntryAuthorizer{allowedPrefix: "/ns/team1/"}
+		ds := fakedatastore.New(t)
+		test := setupServiceTest(t, ds, withEntryAuthorizer(ea))
+		defer test.Cleanup()
+
+		entries := createTestEntries(t, ds, commonEntry(allowedID), commonEntry(deniedID))
+		allowed := entries[allowedID.String()]
+		denied := entries[deniedID.String()]
+
+		resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
+			Ids: []string{allowed.EntryId, denied.EntryId, "missing"},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 3)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[0].Status)
+		spiretest.AssertProtoEqual(t, deniedStatus, resp.Results[1].Status)
+		spiretest.AssertProtoEqual(t, &types.Status{
+			Code:    int32(codes.NotFound),
+			Message: "entry not found",
+		}, resp.Results[2].Status)
+
+		fetched, err := ds.FetchRegistrationEntry(ctx, denied.EntryId)
+		require.NoError(t, err)
+		require.NotNil(t, fetched)
+	})
+
+	t.Run("authorizer failure", func(t *testing.T) {
+		ea := &fakeEntryAuthorizer{err: status.Error(codes.Internal, "oh no")}
+		test := setupServiceTest(t, fakedatastore.New(t), withEntryAuthorizer(ea))
+		defer test.Cleanup()
+
+		resp, err := test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{
+			Entries: []*types.Entry{protoEntry(allowedID)},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		spiretest.AssertProtoEqual(t, &types.Status{
+			Code:    int32(codes.Internal),
+			Message: "failed to authorize entry: oh no",
+		}, resp.Results[0].Status)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		ea := &fakeEntryAuthorizer{allowedPrefix: "/ns/team1/"}
+		test := setupServiceTest(t, fakedatastore.New(t), withEntryAuthorizer(ea))
+		defer test.Cleanup()
+
+		resp, err := test.dryRunClient.DryRunBatchCreateEntry(ctx, &entrydryrunv1.DryRunBatchCreateEntryRequest{
+			Entries: []*types.Entry{protoEntry(allowedID), protoEntry(deniedID)},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[0].Status)
+		spiretest.AssertProtoEqual(t, deniedStatus, resp.Results[1].Status)
+
+		// Entries are authorized as for the method the dry run reports on
+		assert.Equal(t, []string{
+			"/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+			"/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+		}, ea.methods)
+	})
+}
+
+func TestBatchEntryDryRun(t *testing.T) {
+	agent1 := spiffeid.RequireFromPath(td, "/spire/agent/1")
+	agent2 := spiffeid.RequireFromPath(td, "/spire/agent/2")
+	workloadID := spiffeid.RequireFromPath(td, "/workload")
+	delegateID := spiffeid.RequireFromPath(td, "/delegate")
+	childID := spiffeid.RequireFromPath(td, "/child")
+	newID := spiffeid.RequireFromPath(td, "/new")
+
+	selectors := []*common.Selector{{Type: "unix", Value: "uid:1000"}}
+
+	setup := func(t *testing.T) (*serviceTest, map[string]*common.RegistrationEntry) {
+		ds := fakedatastore.New(t)
+		entries := createTestEntries(t, ds,
+			&common.RegistrationEntry{ParentId: agent1.String(), SpiffeId: workloadID.String(), Selectors: selectors},
+			&common.RegistrationEntry{ParentId: agent1.String(), SpiffeId: delegateID.String(), Selectors: selectors},
+			&common.RegistrationEntry{ParentId: delegateID.String(), SpiffeId: childID.String(), Selectors: selectors},
+		)
+
+		cache := authorizedentries.NewCache(clock.New())
+		for _, entry := range entries {
+			tEntry, err := api.RegistrationEntryToProto(entry)
+			require.NoError(t, err)
+			cache.UpdateEntry(tEntry)
+		}
+		cache.UpdateAgent(agent1.String(), time.Now().Add(time.Hour), nil)
+		cache.UpdateAgent(agent2.String(), time.Now().Add(time.Hour), nil)
+
+		test := setupServiceTest(t, ds, withAuthorizedEntries(&fakeAuthorizedEntries{cache: cache}))
+		t.Cleanup(test.Cleanup)
+		return test, entries
+	}
+
+	assertNoChanges := func(t *testing.T, test *serviceTest, expected map[string]*common.RegistrationEntry) {
+		resp, err := test.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		require.NoError(t, err)
+		require.Len(t, resp.Entries, len(expected))
+		for _, entry := range resp.Entries {
+			spiretest.AssertProtoEqual(t, expected[entry.SpiffeId], entry)
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		test, entries := setup(t)
+
+		resp, err := test.dryRunClient.DryRunBatchCreateEntry(ctx, &entrydryrunv1.DryRunBatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:    api.ProtoFromID(agent2),
+					SpiffeId:    api.ProtoFromID(newID),
+					Selectors:   []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+					X509SvidTtl: 60,
+				},
+				{
+					ParentId:  api.ProtoFromID(agent1),
+					SpiffeId:  api.ProtoFromID(workloadID),
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+				},
+				{
+					ParentId: api.ProtoFromID(agent1),
+					SpiffeId: api.ProtoFromID(newID),
+				},
+				// Rejected by the datastore validation
+				{
+					ParentId: api.ProtoFromID(agent1),
+					SpiffeId: api.ProtoFromID(newID),
+					Selectors: []*types.Selector{
+						{Type: "unix", Value: "uid:1000"},
+						{Type: "k8s", Value: "ns:default"},
+					},
+					StoreSvid: true,
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+		assert.Equal(t, int32(codes.InvalidArgument), resp.Results[2].Status.Code)
+		spiretest.AssertProtoEqual(t, &entrydryrunv1.DryRunBatchCreateEntryResponse{
+			Results: []*entrydryrunv1.EntryResult{
+				{
+					Status:   api.OK(),
+					Action:   entrydryrunv1.Action_ACTION_CREATE,
+					SpiffeId: newID.String(),
+					Diffs: []*entrydryrunv1.FieldDiff{
+						{Field: "spiffe_id", New: newID.String()},
+						{Field: "parent_id", New: agent2.String()},
+						{Field: "selectors", New: "[unix:uid:1000]"},
+						{Field: "x509_svid_ttl", Old: "0", New: "60"},
+					},
+				},
+				{
+					Status:   api.CreateStatus(codes.AlreadyExists, "similar entry already exists"),
+					Action:   entrydryrunv1.Action_ACTION_UNCHANGED,
+					EntryId:  entries[workloadID.String()].EntryId,
+					SpiffeId: workloadID.String(),
+				},
+				{
+					Status: resp.Results[2].Status,
+					Action: entrydryrunv1.Action_ACTION_FAILED,
+				},
+				{
+					Status: &types.Status{
+						Code:    int32(codes.InvalidArgument),
+						Message: "failed to create entry: invalid registration entry: selector types must be the same when store SVID is enabled",
+					},
+					Action: entrydryrunv1.Action_ACTION_FAILED,
+				},
+			},
+			AuthorizationChanges: []*entrydryrunv1.AuthorizationChange{
+				{
+					SpiffeId:     newID.String(),
+					AgentsGained: []string{agent2.String()},
+				},
+			},
+		}, resp)
+
+		assertNoChanges(t, test, entries)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		test, entries := setup(t)
+		delegate := entries[delegateID.String()]
+		child := entries[childID.String()]
+		workload := entries[workloadID.String()]
+
+		resp, err := test.dryRunClient.DryRunBatchUpdateEntry(ctx, &entrydryrunv1.DryRunBatchUpdateEntryRequest{
+			Entries: []*types.Entry{
+				{Id: delegate.EntryId, ParentId: api.ProtoFromID(agent2)},
+				{Id: workload.EntryId, ParentId: api.ProtoFromID(agent1)},
+				{Id: "missing", ParentId: api.ProtoFromID(agent1)},
+				// Later updates apply on top of earlier ones
+				{Id: delegate.EntryId, ParentId: api.ProtoFromID(agent2), Hint: "moved"},
+				// Rejected by the datastore validation
+				{Id: workload.EntryId, ParentId: api.ProtoFromID(agent1), X509SvidTtl: -1},
+			},
+			InputMask: &types.EntryMask{ParentId: true, Hint: true, X509SvidTtl: true},
+		})
+		require.NoError(t, err)
+		spiretest.AssertProtoEqual(t, &entrydryrunv1.DryRunBatchUpdateEntryResponse{
+			Results: []*entrydryrunv1.EntryResult{
+				{
+					Status:   api.OK(),
+					Action:   entrydryrunv1.Action_ACTION_UPDATE,
+					EntryId:  delegate.EntryId,
+					SpiffeId: delegateID.String(),
+					Diffs: []*entrydryrunv1.FieldDiff{
+						{Field: "parent_id", Old: agent1.String(), New: agent2.String()},
+					},
+				},
+				{
+					Status:   api.OK(),
+					Action:   entrydryrunv1.Action_ACTION_UNCHANGED,
+					EntryId:  workload.EntryId,
+					SpiffeId: workloadID.String(),
+				},
+				{
+					Status: &types.Status{
+						Code:    int32(codes.NotFound),
+						Message: "failed to update entry",
+					},
+					Action: entrydryrunv1.Action_ACTION_FAILED,
+				},
+				{
+					Status:   api.OK(),
+					Action:   entrydryrunv1.Action_ACTION_UPDATE,
+					EntryId:  delegate.EntryId,
+					SpiffeId: delegateID.String(),
+					Diffs: []*entrydryrunv1.FieldDiff{
+						{Field: "hint", New: "moved"},
+					},
+				},
+				{
+					Status: &types.Status{
+						Code:    int32(codes.InvalidArgument),
+						Message: "failed to update entry: invalid registration entry: X509SvidTtl is not set",
+					},
+					Action: entrydryrunv1.Action_ACTION_FAILED,
+				},
+			},
+			AuthorizationChanges: sortedAuthorizationChanges(
+				&entrydryrunv1.AuthorizationChange{
+					EntryId:      delegate.EntryId,
+					SpiffeId:     delegateID.String(),
+					AgentsGained: []string{agent2.String()},
+					AgentsLost:   []string{agent1.String()},
+				},
+				&entrydryrunv1.AuthorizationChange{
+					EntryId:      child.EntryId,
+					SpiffeId:     childID.String(),
+					AgentsGained: []string{agent2.String()},
+					AgentsLost:   []string{agent1.String()},
+				},
+			),
+		}, resp)
+
+		assertNoChanges(t, test, entries)
+	})
+
+	t.Run("without authorized entries", func(t *testing.T) {
+		test := setupServiceTest(t, fakedatastore.New(t))
+		defer test.Cleanup()
+
+		resp, err := test.dryRunClient.DryRunBatchCreateEntry(ctx, &entrydryrunv1.DryRunBatchCreateEntryRequest{
+			Entries: []*types.Entry{
+				{
+					ParentId:  api.ProtoFromID(agent1),
+					SpiffeId:  api.ProtoFromID(newID),
+					Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 1)
+		spiretest.AssertProtoEqual(t, api.OK(), resp.Results[0].Status)
+		assert.Equal(t, entrydryrunv1.Action_ACTION_CREATE, resp.Results[0].Action)
+		assert.Empty(t, resp.AuthorizationChanges)
+
+		count, err := test.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
 func createFederatedBundles(t *testing.T, ds datastore.DataStore) {
 	_, err := ds.CreateBundle(ctx, &common.Bundle{
 		TrustDomainId: federatedTd.IDString(),
@@ -4725,12 +5107,27 @@ func withEntryPageSize(v int) func(*serviceTestConfig) {
 	}
 }
 
+func withEntryAuthorizer(ea entry.EntryAuthorizer) func(*serviceTestConfig) {
+	return func(config *serviceTestConfig) {
+		config.entryAuthorizer = ea
+	}
+}
+
+func withAuthorizedEntries(aes entry.AuthorizedEntriesSnapshotter) func(*serviceTestConfig) {
+	return func(config *serviceTestConfig) {
+		config.authorizedEntries = aes
+	}
+}
+
 type serviceTestConfig struct {
-	entryPageSize int
+	entryPageSize     int
+	entryAuthorizer   entry.EntryAuthorizer
+	authorizedEntries entry.AuthorizedEntriesSnapshotter
 }
 
 type serviceTest struct {
 	client       entryv1.EntryClient
+	dryRunClient entrydryrunv1.EntryDryRunClient
 	ef           *entryFetcher
 	done         func()
 	ds           datastore.DataStore
@@ -4753,10 +5150,12 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore, options ...serviceTe
 
 	ef := &entryFetcher{}
 	service := entry.New(entry.Config{
-		TrustDomain:   td,
-		DataStore:     ds,
-		EntryFetcher:  ef,
-		EntryPageSize: config.entryPageSize,
+		TrustDomain:       td,
+		DataStore:         ds,
+		EntryFetcher:      ef,
+		EntryPageSize:     config.entryPageSize,
+		EntryAuthorizer:   config.entryAuthorizer,
+		AuthorizedEntries: config.authorizedEntries,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -4784,6 +5183,7 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore, options ...serviceTe
 	conn := server.NewGRPCClient(t)
 
 	test.client = entryv1.NewEntryClient(conn)
+	test.dryRunClient = entrydryrunv1.NewEntryDryRunClient(conn)
 	test.done = server.Stop
 
 	return test
@@ -4886,3 +5286,48 @@ func appendEntryIDs[T HasID](ids []string, entries []T) []string {
 	}
 	return ids
 }
+
+type fakeEntryAuthorizer struct {
+	allowedPrefix string
+	err           error
+	existing      []*types.Entry
+	methods       []string
+}
+
+func (f *fakeEntryAuthorizer) AuthorizeEntry(ctx context.Context, entry, existing *types.Entry) error {
+	if f.err != nil {
+		return f.err
+	}
+	if names, ok := rpccontext.Names(ctx); ok {
+		f.methods = append(f.methods, "/"+names.RawService+"/"+names.Method)
+	}
+	f.existing = append(f.existing, existing)
+	if existing != nil && !strings.HasPrefix(existing.SpiffeId.Path, f.allowedPrefix) {
+		return status.Error(codes.PermissionDenied, "denied")
+	}
+	if !strings.HasPrefix(entry.SpiffeId.Path, f.allowedPrefix) {
+		return status.Error(codes.PermissionDenied, "denied")
+	}
+	return nil
+}
+
+type fakeAuthorizedEntries struct {
+	cache *authorizedentries.Cache
+}
+
+func (f *fakeAuthorizedEntries) SnapshotAuthorizedEntries(context.Context) (*authorizedentries.Cache, error) {
+	return f.cache.Clone(), nil
+}
+
+// sortedAuthorizationChanges sorts the changes by entry ID, as they are
+// reported.
+func sortedAuthorizationChanges(changes ...*entrydryrunv1.AuthorizationChange) []*entrydryrunv1.AuthorizationChange {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].EntryId < changes[j].EntryId
+	})
+	return changes
+}
+
+func spiffeIDString(id *types.SPIFFEID) string {
+	return spiffeid.RequireFromPath(spiffeid.RequireTrustDomainFromString(id.TrustDomain), id.Path).String()
+}
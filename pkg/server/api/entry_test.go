@@ -74,6 +74,25 @@ func TestRegistrationEntryToProto(t *testing.T) {
 				CreatedAt:      1678731397,
 			},
 		},
+		{
+			name: "template",
+			entry: &common.RegistrationEntry{
+				EntryId:  "entry1",
+				ParentId: "spiffe://example.org/foo",
+				SpiffeId: "spiffe://example.org/ns/{{k8s:ns}}/sa/{{k8s:sa}}",
+				Selectors: []*common.Selector{
+					{Type: "k8s", Value: "pod-label:app:web"},
+				},
+			},
+			expectEntry: &types.Entry{
+				Id:       "entry1",
+				ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}/sa/{{k8s:sa}}"},
+				Selectors: []*types.Selector{
+					{Type: "k8s", Value: "pod-label:app:web"},
+				},
+			},
+		},
 		{
 			name: "missing entry",
 			err:  "missing registration entry",
@@ -536,6 +555,93 @@ func TestProtoToRegistrationEntry(t *testing.T) {
 				Hint:           "external",
 			},
 		},
+		{
+			name: "template",
+			entry: &types.Entry{
+				Id:       "entry1",
+				ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}/sa/{{k8s:sa}}"},
+				Selectors: []*types.Selector{
+					{Type: "k8s", Value: "pod-label:app:web"},
+				},
+			},
+			expectEntry: &common.RegistrationEntry{
+				EntryId:  "entry1",
+				ParentId: "spiffe://example.org/foo",
+				SpiffeId: "spiffe://example.org/ns/{{k8s:ns}}/sa/{{k8s:sa}}",
+				Selectors: []*common.Selector{
+					{Type: "k8s", Value: "pod-label:app:web"},
+				},
+				DnsNames:      []string{},
+				FederatesWith: []string{},
+			},
+		},
+		{
+			name: "malformed template",
+			err:  "invalid spiffe ID template: invalid placeholder \"k8s\": expected {{<type>:<key>}}",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+		},
+		{
+			name: "template in another trust domain",
+			err:  "invalid spiffe ID template: \"spiffe://other.org/ns/{{k8s:ns}}\" is not a member of trust domain \"example.org\"",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "other.org", Path: "/ns/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+		},
+		{
+			name: "template in reserved namespace",
+			err:  "invalid spiffe ID template: \"spiffe://example.org/spire/{{k8s:ns}}\" is not a workload in trust domain \"example.org\"; path is in the reserved namespace",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+		},
+		{
+			name: "node template",
+			err:  "template entries cannot be node entries",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+			},
+		},
+		{
+			name: "admin template",
+			err:  "template entries cannot be admin entries",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+				Admin:     true,
+			},
+		},
+		{
+			name: "downstream template",
+			err:  "template entries cannot be downstream entries",
+			entry: &types.Entry{
+				ParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}"},
+				Selectors:  []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+				Downstream: true,
+			},
+		},
+		{
+			name: "template storing SVIDs",
+			err:  "template entries cannot store SVIDs",
+			entry: &types.Entry{
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/foo"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/{{k8s:ns}}"},
+				Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+				StoreSvid: true,
+			},
+		},
 		{
 			name: "missing entry",
 			err:  "missing entry",
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 )
 
@@ -89,6 +90,29 @@ func VerifyTrustDomainWorkloadID(td spiffeid.TrustDomain, id spiffeid.ID) error
 	return nil
 }
 
+// TrustDomainWorkloadIDTemplateFromProto parses a SPIFFE ID template for
+// a workload in the given trust domain.
+func TrustDomainWorkloadIDTemplateFromProto(_ context.Context, td spiffeid.TrustDomain, protoID *types.SPIFFEID) (*entrytemplate.Template, error) {
+	if protoID == nil {
+		return nil, errors.New("request must specify SPIFFE ID")
+	}
+	idTD, err := spiffeid.TrustDomainFromString(protoID.TrustDomain)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := entrytemplate.FromPath(idTD, protoID.Path)
+	if err != nil {
+		return nil, err
+	}
+	if idTD != td {
+		return nil, fmt.Errorf("%q is not a member of trust domain %q", tmpl, td)
+	}
+	if idutil.IsReservedPath(tmpl.Path()) {
+		return nil, fmt.Errorf("%q is not a workload in trust domain %q; path is in the reserved namespace", tmpl, td)
+	}
+	return tmpl, nil
+}
+
 // ProtoFromID converts a SPIFFE ID from the given spiffeid.ID to
 // types.SPIFFEID
 func ProtoFromID(id spiffeid.ID) *types.SPIFFEID {
@@ -6,10 +6,12 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -30,6 +32,7 @@ func (m *authorizationMiddleware) opaAuth(ctx context.Context, req any, fullMeth
 		Caller:     spiffeID,
 		FullMethod: fullMethod,
 		Req:        req,
+		Entries:    entriesFromRequest(req),
 	}
 
 	result, err := m.authPolicyEngine.Eval(ctx, input)
@@ -45,6 +48,86 @@ func (m *authorizationMiddleware) opaAuth(ctx context.Context, req any, fullMeth
 	return ctx, allow, nil
 }
 
+// PolicyEntryAuthorizer authorizes the caller to act on the individual
+// entries of entry API batch requests using the allow_entry result of the
+// authorization policy.
+type PolicyEntryAuthorizer struct {
+	engine *authpolicy.Engine
+}
+
+// NewPolicyEntryAuthorizer returns an entry authorizer backed by the given
+// policy engine.
+func NewPolicyEntryAuthorizer(engine *authpolicy.Engine) *PolicyEntryAuthorizer {
+	return &PolicyEntryAuthorizer{engine: engine}
+}
+
+// AuthorizeEntry evaluates the policy for the given entry. The existing entry
+// is only provided for updates. A PermissionDenied status is returned if the
+// policy does not allow the entry.
+func (a *PolicyEntryAuthorizer) AuthorizeEntry(ctx context.Context, entry, existing *types.Entry) error {
+	var callerID string
+	if id, ok := rpccontext.CallerID(ctx); ok {
+		callerID = id.String()
+	}
+
+	var fullMethod string
+	if names, ok := rpccontext.Names(ctx); ok {
+		fullMethod = "/" + names.RawService + "/" + names.Method
+	}
+
+	input := authpolicy.Input{
+		Caller:     callerID,
+		FullMethod: fullMethod,
+	}
+	if entry != nil {
+		input.Entry = entryInput(entry)
+	}
+	if existing != nil {
+		input.ExistingEntry = entryInput(existing)
+	}
+
+	result, err := a.engine.Eval(ctx, input)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to evaluate entry authorization policy: %v", err)
+	}
+	if !result.AllowEntry {
+		return status.Error(codes.PermissionDenied, "entry is not authorized by policy")
+	}
+	return nil
+}
+
+func entriesFromRequest(req any) []authpolicy.Entry {
+	var entries []authpolicy.Entry
+	switch req := req.(type) {
+	case *entryv1.BatchCreateEntryRequest:
+		for _, entry := range req.Entries {
+			entries = append(entries, authpolicy.EntryFromProto(entry))
+		}
+	case *entryv1.BatchUpdateEntryRequest:
+		for _, entry := range req.Entries {
+			entries = append(entries, authpolicy.EntryFromProto(entry))
+		}
+	case *entrydryrunv1.DryRunBatchCreateEntryRequest:
+		for _, entry := range req.Entries {
+			entries = append(entries, authpolicy.EntryFromProto(entry))
+		}
+	case *entrydryrunv1.DryRunBatchUpdateEntryRequest:
+		for _, entry := range req.Entries {
+			entries = append(entries, authpolicy.EntryFromProto(entry))
+		}
+	case *entryv1.BatchDeleteEntryRequest:
+		for _, id := range req.Ids {
+			entries = append(entries, authpolicy.Entry{ID: id})
+		}
+	}
+	return entries
+}
+
+func entryInput(entry *types.Entry) *authpolicy.Entry {
+	e := authpolicy.EntryFromProto(entry)
+	return &e
+}
+
 func (m *authorizationMiddleware) reconcileResult(ctx context.Context, res authpolicy.Result) (context.Context, bool, error) {
 	ctx = setAuthorizationLogFields(ctx, "nobody", "")
 
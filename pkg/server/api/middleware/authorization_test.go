@@ -14,10 +14,13 @@ import (
 	"github.com/open-policy-agent/opa/v1/storage/inmem"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/api"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -300,6 +303,54 @@ func TestWithAuthorizationPreprocess(t *testing.T) {
 			expectCode: codes.PermissionDenied,
 			expectMsg:  fmt.Sprintf("authorization denied for method %s", fakeFullMethod),
 		},
+		{
+			name:       "check passing of batch entries positive test",
+			fullMethod: fakeFullMethod,
+			peer:       mtlsPeer,
+			request: &entryv1.BatchCreateEntryRequest{
+				Entries: []*types.Entry{
+					{SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/team1/workload"}},
+				},
+			},
+			rego:            condCheckRego("input.entries[0].spiffe_id == \"spiffe://example.org/ns/team1/workload\""),
+			agentAuthorizer: yesAgentAuthorizer,
+			expectCode:      codes.OK,
+		},
+		{
+			name:       "check passing of dry-run batch entries positive test",
+			fullMethod: fakeFullMethod,
+			peer:       mtlsPeer,
+			request: &entrydryrunv1.DryRunBatchUpdateEntryRequest{
+				Entries: []*types.Entry{
+					{SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/team1/workload"}},
+				},
+			},
+			rego:            condCheckRego("input.entries[0].spiffe_id == \"spiffe://example.org/ns/team1/workload\""),
+			agentAuthorizer: yesAgentAuthorizer,
+			expectCode:      codes.OK,
+		},
+		{
+			name:       "check passing of batch delete entries positive test",
+			fullMethod: fakeFullMethod,
+			peer:       mtlsPeer,
+			request: &entryv1.BatchDeleteEntryRequest{
+				Ids: []string{"entry1"},
+			},
+			rego:            condCheckRego("input.entries[0].id == \"entry1\""),
+			agentAuthorizer: yesAgentAuthorizer,
+			expectCode:      codes.OK,
+		},
+		{
+			name:       "check passing of batch entries negative test",
+			fullMethod: fakeFullMethod,
+			peer:       mtlsPeer,
+			request: map[string]string{
+				"foo": "bar",
+			},
+			rego:       condCheckRego("count(input.entries) > 0"),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  fmt.Sprintf("authorization denied for method %s", fakeFullMethod),
+		},
 		{
 			name:       "no peer",
 			fullMethod: fakeFullMethod,
@@ -372,6 +423,108 @@ func TestWithAuthorizationPreprocess(t *testing.T) {
 	}
 }
 
+func TestPolicyEntryAuthorizer(t *testing.T) {
+	const rego = `
+    package spire
+    result = {
+      "allow": false,
+      "allow_if_admin": false,
+      "allow_if_local": false,
+      "allow_if_downstream": false,
+      "allow_if_agent": false,
+      "allow_entry": allow_entry
+    }
+    default allow_entry = false
+
+    allow_entry if {
+        input.caller == "spiffe://example.org/team1-admin"
+        input.full_method == "/spire.api.server.entry.v1.Entry/BatchCreateEntry"
+        startswith(input.entry.spiffe_id, "spiffe://example.org/ns/team1/")
+        not input.existing_entry
+    }
+
+    allow_entry if {
+        input.caller == "spiffe://example.org/team1-admin"
+        startswith(input.entry.spiffe_id, "spiffe://example.org/ns/team1/")
+        startswith(input.existing_entry.spiffe_id, "spiffe://example.org/ns/team1/")
+    }
+    `
+
+	callerID := spiffeid.RequireFromString("spiffe://example.org/team1-admin")
+	inNamespace := &types.Entry{SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/team1/workload"}}
+	outOfNamespace := &types.Entry{SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/ns/team2/workload"}}
+
+	ctx := context.Background()
+	policyEngine, err := authpolicy.NewEngineFromRego(ctx, rego, inmem.NewFromObject(map[string]any{}), ast.RegoV1)
+	require.NoError(t, err)
+	authorizer := middleware.NewPolicyEntryAuthorizer(policyEngine)
+
+	ctx = rpccontext.WithCallerID(ctx, callerID)
+	ctx = rpccontext.WithNames(ctx, api.Names{
+		RawService: "spire.api.server.entry.v1.Entry",
+		Service:    "entry.v1.Entry",
+		Method:     "BatchCreateEntry",
+	})
+
+	for _, tt := range []struct {
+		name       string
+		ctx        context.Context
+		entry      *types.Entry
+		existing   *types.Entry
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:       "entry in namespace",
+			ctx:        ctx,
+			entry:      inNamespace,
+			expectCode: codes.OK,
+		},
+		{
+			name:       "entry out of namespace",
+			ctx:        ctx,
+			entry:      outOfNamespace,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "entry is not authorized by policy",
+		},
+		{
+			name:       "entry stays in namespace",
+			ctx:        ctx,
+			entry:      inNamespace,
+			existing:   inNamespace,
+			expectCode: codes.OK,
+		},
+		{
+			name:       "entry moved out of namespace",
+			ctx:        ctx,
+			entry:      outOfNamespace,
+			existing:   inNamespace,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "entry is not authorized by policy",
+		},
+		{
+			name:       "entry moved into namespace",
+			ctx:        ctx,
+			entry:      inNamespace,
+			existing:   outOfNamespace,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "entry is not authorized by policy",
+		},
+		{
+			name:       "different caller",
+			ctx:        rpccontext.WithCallerID(ctx, spiffeid.RequireFromString("spiffe://example.org/team2-admin")),
+			entry:      inNamespace,
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "entry is not authorized by policy",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorizer.AuthorizeEntry(tt.ctx, tt.entry, tt.existing)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+		})
+	}
+}
+
 func TestWithAuthorizationPostprocess(t *testing.T) {
 	// Postprocess doesn't do anything. Let's just make sure it doesn't panic.
 	ctx := context.Background()
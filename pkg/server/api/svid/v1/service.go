@@ -18,6 +18,8 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -35,6 +37,11 @@ type Config struct {
 	TrustDomain                  spiffeid.TrustDomain
 	DataStore                    datastore.DataStore
 	UseLegacyDownstreamX509CATTL bool
+
+	// HasCredentialComposers indicates that credential composers are
+	// configured. The node selectors of the calling agent are only fetched
+	// for them.
+	HasCredentialComposers bool
 }
 
 // New creates a new SVID service
@@ -45,6 +52,7 @@ func New(config Config) *Service {
 		td:                           config.TrustDomain,
 		ds:                           config.DataStore,
 		useLegacyDownstreamX509CATTL: config.UseLegacyDownstreamX509CATTL,
+		hasCredentialComposers:       config.HasCredentialComposers,
 	}
 }
 
@@ -57,6 +65,7 @@ type Service struct {
 	td                           spiffeid.TrustDomain
 	ds                           datastore.DataStore
 	useLegacyDownstreamX509CATTL bool
+	hasCredentialComposers       bool
 }
 
 func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequest) (*svidv1.MintX509SVIDResponse, error) {
@@ -146,7 +155,7 @@ func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequ
 
 func (s *Service) MintJWTSVID(ctx context.Context, req *svidv1.MintJWTSVIDRequest) (*svidv1.MintJWTSVIDResponse, error) {
 	rpccontext.AddRPCAuditFields(ctx, s.fieldsFromJWTSvidParams(ctx, req.Id, req.Audience, req.Ttl))
-	jwtsvid, err := s.mintJWTSVID(ctx, req.Id, req.Audience, req.Ttl)
+	jwtsvid, err := s.mintJWTSVID(ctx, req.Id, req.Audience, req.Ttl, credentialcomposer.WorkloadContext{})
 	if err != nil {
 		return nil, err
 	}
@@ -179,10 +188,15 @@ func (s *Service) BatchNewX509SVID(ctx context.Context, req *svidv1.BatchNewX509
 		return nil, err
 	}
 
+	nodeSelectors, err := s.callerNodeSelectors(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
 	var results []*svidv1.BatchNewX509SVIDResponse_Result
 	for _, svidParam := range req.Params {
 		//  Create new SVID
-		r := s.newX509SVID(ctx, svidParam, entriesMap)
+		r := s.newX509SVID(ctx, svidParam, entriesMap, nodeSelectors)
 		results = append(results, r)
 		spiffeID := ""
 		if r.Svid != nil {
@@ -223,8 +237,49 @@ func (s *Service) findEntries(ctx context.Context, log logrus.FieldLogger, entri
 	return foundEntries, nil
 }
 
+// callerNodeSelectors returns the selectors of the calling agent, which are
+// passed to the credential composers. Callers other than agents have none,
+// and the lookup is skipped when no credential composer is configured.
+func (s *Service) callerNodeSelectors(ctx context.Context, log logrus.FieldLogger) ([]*common.Selector, error) {
+	if !s.hasCredentialComposers || !rpccontext.CallerIsAgent(ctx) {
+		return nil, nil
+	}
+	callerID, ok := rpccontext.CallerID(ctx)
+	if !ok {
+		return nil, api.MakeErr(log, codes.Internal, "caller ID missing from request context", nil)
+	}
+
+	selectors, err := s.ds.GetNodeSelectors(ctx, callerID.String(), datastore.TolerateStale)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch caller node selectors", err)
+	}
+	return selectors, nil
+}
+
+// workloadContext describes the entry an SVID is minted for to the
+// credential composers.
+func workloadContext(ctx context.Context, entry *types.Entry, nodeSelectors []*common.Selector) credentialcomposer.WorkloadContext {
+	workload := credentialcomposer.WorkloadContext{
+		EntryID:       entry.GetId(),
+		Hint:          entry.GetHint(),
+		NodeSelectors: nodeSelectors,
+	}
+	for _, selector := range entry.GetSelectors() {
+		workload.Selectors = append(workload.Selectors, &common.Selector{
+			Type:  selector.Type,
+			Value: selector.Value,
+		})
+	}
+	// The parent ID is informational, so a malformed one is left out
+	// instead of failing the request.
+	if parentID, err := api.IDFromProto(ctx, entry.GetParentId()); err == nil {
+		workload.ParentID = parentID
+	}
+	return workload
+}
+
 // newX509SVID creates an X509-SVID using data from registration entry and key from CSR
-func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDParams, entries map[string]*types.Entry) *svidv1.BatchNewX509SVIDResponse_Result {
+func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDParams, entries map[string]*types.Entry, nodeSelectors []*common.Selector) *svidv1.BatchNewX509SVIDResponse_Result {
 	log := rpccontext.Logger(ctx)
 
 	switch {
@@ -274,6 +329,7 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 		PublicKey: csr.PublicKey,
 		DNSNames:  entry.GetDnsNames(),
 		TTL:       time.Duration(entry.GetX509SvidTtl()) * time.Second,
+		Workload:  workloadContext(ctx, entry, nodeSelectors),
 	})
 	if err != nil {
 		return &svidv1.BatchNewX509SVIDResponse_Result{
@@ -296,7 +352,7 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 	}
 }
 
-func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32) (*types.JWTSVID, error) {
+func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audience []string, ttl int32, workload credentialcomposer.WorkloadContext) (*types.JWTSVID, error) {
 	log := rpccontext.Logger(ctx)
 
 	id, err := api.TrustDomainWorkloadIDFromProto(ctx, s.td, protoID)
@@ -314,6 +370,7 @@ func (s *Service) mintJWTSVID(ctx context.Context, protoID *types.SPIFFEID, audi
 		SPIFFEID: id,
 		TTL:      time.Duration(ttl) * time.Second,
 		Audience: audience,
+		Workload: workload,
 	})
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to sign JWT-SVID", err)
@@ -363,7 +420,12 @@ func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest)
 		return nil, api.MakeErr(log, codes.NotFound, "entry not found or not authorized", nil)
 	}
 
-	jwtsvid, err := s.mintJWTSVID(ctx, entry.GetSpiffeId(), req.Audience, entry.GetJwtSvidTtl())
+	nodeSelectors, err := s.callerNodeSelectors(ctx, log)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtsvid, err := s.mintJWTSVID(ctx, entry.GetSpiffeId(), req.Audience, entry.GetJwtSvidTtl(), workloadContext(ctx, entry, nodeSelectors))
 	if err != nil {
 		return nil, err
 	}
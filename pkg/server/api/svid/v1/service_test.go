@@ -2,6 +2,7 @@ package svid_test
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -18,6 +19,8 @@ import (
 
 	svidv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/svid/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
@@ -27,6 +30,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	svid "github.com/spiffe/spire/pkg/server/api/svid/v1"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/fakes/fakeserverca"
@@ -1812,6 +1816,89 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 	}
 }
 
+func TestServiceNewSVIDsForTemplateEntry(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	// Template entries are expanded by the entry fetcher, which only hands
+	// out the entries materialized for the caller.
+	materializedID := spiffeid.RequireFromString("spiffe://example.org/ns/default/sa/web")
+	materializedEntry := &types.Entry{
+		Id:       entrytemplate.MaterializedEntryID("template", materializedID),
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: api.ProtoFromID(materializedID),
+	}
+	test.ef.entries = []*types.Entry{materializedEntry}
+	test.withCallerID = true
+
+	for _, tt := range []struct {
+		name       string
+		entryID    string
+		expectID   spiffeid.ID
+		expectCode codes.Code
+		expectMsg  string
+	}{
+		{
+			name:     "materialized identity",
+			entryID:  materializedEntry.Id,
+			expectID: materializedID,
+		},
+		{
+			name:       "materialized identity not expanded for the caller",
+			entryID:    entrytemplate.MaterializedEntryID("template", spiffeid.RequireFromString("spiffe://example.org/ns/kube-system/sa/admin")),
+			expectCode: codes.NotFound,
+			expectMsg:  "entry not found or not authorized",
+		},
+		{
+			name:       "template entry",
+			entryID:    "template",
+			expectCode: codes.NotFound,
+			expectMsg:  "entry not found or not authorized",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			test.rateLimiter.count = 1
+
+			x509Resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{
+				Params: []*svidv1.NewX509SVIDParams{
+					{
+						EntryId: tt.entryID,
+						Csr:     createCSR(t, &x509.CertificateRequest{}),
+					},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, x509Resp.Results, 1)
+			result := x509Resp.Results[0]
+
+			jwtResp, jwtErr := test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+				EntryId:  tt.entryID,
+				Audience: []string{"AUDIENCE"},
+			})
+
+			if tt.expectCode != codes.OK {
+				spiretest.AssertProtoEqual(t, &types.Status{
+					Code:    int32(tt.expectCode),
+					Message: tt.expectMsg,
+				}, result.Status)
+				require.Nil(t, result.Svid)
+				spiretest.RequireGRPCStatus(t, jwtErr, tt.expectCode, tt.expectMsg)
+				return
+			}
+
+			spiretest.AssertProtoEqual(t, api.OK(), result.Status)
+			spiretest.AssertProtoEqual(t, api.ProtoFromID(tt.expectID), result.Svid.Id)
+			certChain, err := x509util.RawCertsToCertificates(result.Svid.CertChain)
+			require.NoError(t, err)
+			require.Equal(t, []*url.URL{tt.expectID.URL()}, certChain[0].URIs)
+
+			require.NoError(t, jwtErr)
+			spiretest.AssertProtoEqual(t, api.ProtoFromID(tt.expectID), jwtResp.Svid.Id)
+		})
+	}
+}
+
 func TestNewDownstreamX509CA(t *testing.T) {
 	type downstreamCaTest struct {
 		name           string
@@ -2063,6 +2150,148 @@ func TestNewDownstreamX509CA(t *testing.T) {
 	}
 }
 
+func TestServiceWorkloadContext(t *testing.T) {
+	composer := &recordingComposer{}
+	test := setupServiceTestWithCAOptions(t, &fakeserverca.Options{
+		CredentialComposers: []credentialcomposer.CredentialComposer{composer},
+	})
+	defer test.Cleanup()
+	ctx := context.Background()
+
+	entry := &types.Entry{
+		Id:        "workload",
+		ParentId:  api.ProtoFromID(agentID),
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload1"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+		Hint:      "external",
+	}
+	test.ef.entries = []*types.Entry{entry}
+	test.withCallerID = true
+	test.rateLimiter.count = 1
+
+	nodeSelectors := []*common.Selector{{Type: "aws_iid", Value: "account:123"}}
+	require.NoError(t, test.ds.SetNodeSelectors(ctx, agentID.String(), nodeSelectors))
+
+	for _, tt := range []struct {
+		name           string
+		callerIsAgent  bool
+		expectWorkload credentialcomposer.WorkloadContext
+	}{
+		{
+			name:          "agent caller",
+			callerIsAgent: true,
+			expectWorkload: credentialcomposer.WorkloadContext{
+				EntryID:       "workload",
+				Selectors:     []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+				Hint:          "external",
+				ParentID:      agentID,
+				NodeSelectors: nodeSelectors,
+			},
+		},
+		{
+			name: "other caller",
+			expectWorkload: credentialcomposer.WorkloadContext{
+				EntryID:   "workload",
+				Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+				Hint:      "external",
+				ParentID:  agentID,
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test.withCallerAgent = tt.callerIsAgent
+
+			t.Run("BatchNewX509SVID", func(t *testing.T) {
+				composer.workload = credentialcomposer.WorkloadContext{}
+				resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{
+					Params: []*svidv1.NewX509SVIDParams{
+						{EntryId: entry.Id, Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{workloadID.URL()}})},
+					},
+				})
+				require.NoError(t, err)
+				require.Len(t, resp.Results, 1)
+				require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+				require.Equal(t, tt.expectWorkload, composer.workload)
+			})
+
+			t.Run("NewJWTSVID", func(t *testing.T) {
+				composer.workload = credentialcomposer.WorkloadContext{}
+				_, err := test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+					EntryId:  entry.Id,
+					Audience: []string{"AUDIENCE"},
+				})
+				require.NoError(t, err)
+				require.Equal(t, tt.expectWorkload, composer.workload)
+			})
+		})
+	}
+}
+
+func TestServiceSkipsNodeSelectorsWithoutComposers(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+	ctx := context.Background()
+
+	entry := &types.Entry{
+		Id:       "workload",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload1"},
+	}
+	test.ef.entries = []*types.Entry{entry}
+	test.withCallerID = true
+	test.withCallerAgent = true
+	test.rateLimiter.count = 1
+
+	// Any datastore access fails, so the requests only succeed if the node
+	// selectors of the caller are not fetched.
+	test.ds.SetNextError(errors.New("datastore should not be used"))
+
+	resp, err := test.client.BatchNewX509SVID(ctx, &svidv1.BatchNewX509SVIDRequest{
+		Params: []*svidv1.NewX509SVIDParams{
+			{EntryId: entry.Id, Csr: createCSR(t, &x509.CertificateRequest{URIs: []*url.URL{workloadID.URL()}})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+
+	_, err = test.client.NewJWTSVID(ctx, &svidv1.NewJWTSVIDRequest{
+		EntryId:  entry.Id,
+		Audience: []string{"AUDIENCE"},
+	})
+	require.NoError(t, err)
+}
+
+// recordingComposer records the workload context of the last workload SVID
+// and leaves the attributes unchanged.
+type recordingComposer struct {
+	catalog.PluginInfo
+
+	workload credentialcomposer.WorkloadContext
+}
+
+func (c *recordingComposer) ComposeServerX509CA(_ context.Context, attributes credentialcomposer.X509CAAttributes) (credentialcomposer.X509CAAttributes, error) {
+	return attributes, nil
+}
+
+func (c *recordingComposer) ComposeServerX509SVID(_ context.Context, attributes credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
+	return attributes, nil
+}
+
+func (c *recordingComposer) ComposeAgentX509SVID(_ context.Context, _ spiffeid.ID, _ crypto.PublicKey, attributes credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
+	return attributes, nil
+}
+
+func (c *recordingComposer) ComposeWorkloadX509SVID(_ context.Context, _ spiffeid.ID, _ crypto.PublicKey, workload credentialcomposer.WorkloadContext, attributes credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
+	c.workload = workload
+	return attributes, nil
+}
+
+func (c *recordingComposer) ComposeWorkloadJWTSVID(_ context.Context, _ spiffeid.ID, workload credentialcomposer.WorkloadContext, attributes credentialcomposer.JWTSVIDAttributes) (credentialcomposer.JWTSVIDAttributes, error) {
+	c.workload = workload
+	return attributes, nil
+}
+
 type serviceTest struct {
 	client       svidv1.SVIDClient
 	ef           *entryFetcher // Stores entries explicitly fetched using FetchAuthorizedEntries
@@ -2072,7 +2301,9 @@ type serviceTest struct {
 	logHook      *test.Hook
 	rateLimiter  *fakeRateLimiter
 	withCallerID bool
-	done         func()
+	// withCallerAgent marks the caller as an agent
+	withCallerAgent bool
+	done            func()
 }
 
 func (c *serviceTest) Cleanup() {
@@ -2080,18 +2311,23 @@ func (c *serviceTest) Cleanup() {
 }
 
 func setupServiceTest(t *testing.T) *serviceTest {
+	return setupServiceTestWithCAOptions(t, &fakeserverca.Options{})
+}
+
+func setupServiceTestWithCAOptions(t *testing.T, caOptions *fakeserverca.Options) *serviceTest {
 	trustDomain := spiffeid.RequireTrustDomainFromString("example.org")
-	ca := fakeserverca.New(t, trustDomain, &fakeserverca.Options{})
+	ca := fakeserverca.New(t, trustDomain, caOptions)
 	ef := &entryFetcher{}
 	downstream := &entryFetcher{}
 	ds := fakedatastore.New(t)
 
 	rateLimiter := &fakeRateLimiter{}
 	service := svid.New(svid.Config{
-		EntryFetcher: ef,
-		ServerCA:     ca,
-		TrustDomain:  trustDomain,
-		DataStore:    ds,
+		EntryFetcher:           ef,
+		ServerCA:               ca,
+		TrustDomain:            trustDomain,
+		DataStore:              ds,
+		HasCredentialComposers: len(caOptions.CredentialComposers) > 0,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -2109,6 +2345,9 @@ func setupServiceTest(t *testing.T) *serviceTest {
 		if test.withCallerID {
 			ctx = rpccontext.WithCallerID(ctx, agentID)
 		}
+		if test.withCallerAgent {
+			ctx = rpccontext.WithAgentCaller(ctx)
+		}
 		if test.downstream.entries != nil {
 			ctx = rpccontext.WithCallerDownstreamEntries(ctx, downstream.entries)
 		}
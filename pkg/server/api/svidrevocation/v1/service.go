@@ -0,0 +1,165 @@
+package svidrevocation
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/revocation"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterService registers the service on the gRPC server.
+func RegisterService(s grpc.ServiceRegistrar, service *Service) {
+	svidrevocationv1.RegisterSVIDRevocationServer(s, service)
+}
+
+// Config is the service configuration
+type Config struct {
+	TrustDomain spiffeid.TrustDomain
+	DataStore   datastore.DataStore
+	Clock       clock.Clock
+}
+
+// New creates a new SVIDRevocation service
+func New(config Config) *Service {
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
+	return &Service{
+		td:  config.TrustDomain,
+		ds:  config.DataStore,
+		clk: config.Clock,
+	}
+}
+
+// Service implements the v1 SVIDRevocation service
+type Service struct {
+	svidrevocationv1.UnsafeSVIDRevocationServer
+
+	td  spiffeid.TrustDomain
+	ds  datastore.DataStore
+	clk clock.Clock
+}
+
+func (s *Service) RevokeX509SVID(ctx context.Context, req *svidrevocationv1.RevokeX509SVIDRequest) (*svidrevocationv1.RevokeX509SVIDResponse, error) {
+	rpccontext.AddRPCAuditFields(ctx, buildAuditLogFields(req.AuthorityId, req.SerialNumber))
+	log := rpccontext.Logger(ctx)
+
+	authorityID, err := revocation.ParseAuthorityID(req.AuthorityId)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid authority ID", err)
+	}
+	serialNumber, err := revocation.ParseSerialNumber(req.SerialNumber)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid serial number", err)
+	}
+	log = log.WithFields(logrus.Fields{
+		telemetry.LocalAuthorityID: authorityID,
+		telemetry.SerialNumber:     serialNumber,
+	})
+
+	// The revocation only needs to be remembered for as long as an X.509
+	// authority that could have issued the X509-SVID is valid.
+	expiresAt, err := s.x509AuthoritiesExpiration(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch bundle", err)
+	}
+	now := s.clk.Now()
+	if !expiresAt.After(now) {
+		return nil, api.MakeErr(log, codes.FailedPrecondition, "no valid X.509 authority in the bundle", nil)
+	}
+
+	revoked, err := s.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+		AuthorityID:  authorityID,
+		SerialNumber: serialNumber,
+		RevokedAt:    now,
+		ExpiresAt:    expiresAt,
+	})
+	switch {
+	case status.Code(err) == codes.AlreadyExists:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "X509-SVID is already revoked", nil)
+	case err != nil:
+		return nil, api.MakeErr(log, codes.Internal, "failed to revoke X509-SVID", err)
+	}
+
+	rpccontext.AuditRPC(ctx)
+	log.Info("X509-SVID revoked successfully")
+
+	return &svidrevocationv1.RevokeX509SVIDResponse{
+		RevokedX509Svid: revokedX509SVIDToProto(revoked),
+	}, nil
+}
+
+func (s *Service) ListRevokedX509SVIDs(ctx context.Context, _ *svidrevocationv1.ListRevokedX509SVIDsRequest) (*svidrevocationv1.ListRevokedX509SVIDsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	revoked, err := s.ds.ListRevokedX509SVIDs(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list revoked X509-SVIDs", err)
+	}
+
+	resp := &svidrevocationv1.ListRevokedX509SVIDsResponse{}
+	for _, r := range revoked {
+		resp.RevokedX509Svids = append(resp.RevokedX509Svids, revokedX509SVIDToProto(r))
+	}
+
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// x509AuthoritiesExpiration returns the latest expiration time of the X.509
+// authorities in the bundle of the trust domain.
+func (s *Service) x509AuthoritiesExpiration(ctx context.Context) (time.Time, error) {
+	var expiresAt time.Time
+
+	bundle, err := s.ds.FetchBundle(ctx, s.td.IDString())
+	if err != nil {
+		return expiresAt, err
+	}
+	if bundle == nil {
+		return expiresAt, nil
+	}
+
+	for _, rootCA := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(rootCA.DerBytes)
+		if err != nil {
+			return expiresAt, err
+		}
+		if cert.NotAfter.After(expiresAt) {
+			expiresAt = cert.NotAfter
+		}
+	}
+	return expiresAt, nil
+}
+
+func revokedX509SVIDToProto(r *datastore.RevokedX509SVID) *svidrevocationv1.RevokedX509SVID {
+	return &svidrevocationv1.RevokedX509SVID{
+		AuthorityId:  r.AuthorityID,
+		SerialNumber: r.SerialNumber,
+		RevokedAt:    r.RevokedAt.Unix(),
+		ExpiresAt:    r.ExpiresAt.Unix(),
+	}
+}
+
+func buildAuditLogFields(authorityID, serialNumber string) logrus.Fields {
+	fields := logrus.Fields{}
+	if authorityID != "" {
+		fields[telemetry.LocalAuthorityID] = authorityID
+	}
+	if serialNumber != "" {
+		fields[telemetry.SerialNumber] = serialNumber
+	}
+	return fields
+}
@@ -0,0 +1,411 @@
+package svidrevocation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
+	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/api/svidrevocation/v1"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/grpctest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ctx               = context.Background()
+	serverTrustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+)
+
+func TestRevokeX509SVID(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		authorityID  string
+		serialNumber string
+		noBundle     bool
+		dsError      error
+		expectCode   codes.Code
+		expectMsg    string
+		expectLogs   []spiretest.LogEntry
+		expectResp   func(revokedAt, expiresAt time.Time) *svidrevocationv1.RevokeX509SVIDResponse
+	}{
+		{
+			name:         "success",
+			authorityID:  "0A:1B",
+			serialNumber: "0A:FF",
+			expectResp: func(revokedAt, expiresAt time.Time) *svidrevocationv1.RevokeX509SVIDResponse {
+				return &svidrevocationv1.RevokeX509SVIDResponse{
+					RevokedX509Svid: &svidrevocationv1.RevokedX509SVID{
+						AuthorityId:  "0a1b",
+						SerialNumber: "0aff",
+						RevokedAt:    revokedAt.Unix(),
+						ExpiresAt:    expiresAt.Unix(),
+					},
+				}
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: "0A:1B",
+						telemetry.SerialNumber:     "0A:FF",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "X509-SVID revoked successfully",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "0aff",
+					},
+				},
+			},
+		},
+		{
+			name:         "same serial number issued by another authority",
+			authorityID:  "0c",
+			serialNumber: "01",
+			expectResp: func(revokedAt, expiresAt time.Time) *svidrevocationv1.RevokeX509SVIDResponse {
+				return &svidrevocationv1.RevokeX509SVIDResponse{
+					RevokedX509Svid: &svidrevocationv1.RevokedX509SVID{
+						AuthorityId:  "0c",
+						SerialNumber: "01",
+						RevokedAt:    revokedAt.Unix(),
+						ExpiresAt:    expiresAt.Unix(),
+					},
+				}
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "success",
+						telemetry.Type:             "audit",
+						telemetry.LocalAuthorityID: "0c",
+						telemetry.SerialNumber:     "01",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "X509-SVID revoked successfully",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: "0c",
+						telemetry.SerialNumber:     "01",
+					},
+				},
+			},
+		},
+		{
+			name:         "missing authority ID",
+			serialNumber: "0a",
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    "invalid authority ID: authority ID is required",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid authority ID",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "authority ID is required",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid authority ID: authority ID is required",
+						telemetry.SerialNumber:  "0a",
+					},
+				},
+			},
+		},
+		{
+			name:         "invalid authority ID",
+			authorityID:  "xyz",
+			serialNumber: "0a",
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    "invalid authority ID: authority ID must be hexadecimal",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid authority ID",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "authority ID must be hexadecimal",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "invalid authority ID: authority ID must be hexadecimal",
+						telemetry.LocalAuthorityID: "xyz",
+						telemetry.SerialNumber:     "0a",
+					},
+				},
+			},
+		},
+		{
+			name:         "invalid serial number",
+			authorityID:  "0a1b",
+			serialNumber: "xyz",
+			expectCode:   codes.InvalidArgument,
+			expectMsg:    "invalid serial number: serial number must be hexadecimal",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid serial number",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "serial number must be hexadecimal",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "InvalidArgument",
+						telemetry.StatusMessage:    "invalid serial number: serial number must be hexadecimal",
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "xyz",
+					},
+				},
+			},
+		},
+		{
+			name:         "no bundle",
+			authorityID:  "0a1b",
+			serialNumber: "0a",
+			noBundle:     true,
+			expectCode:   codes.FailedPrecondition,
+			expectMsg:    "no valid X.509 authority in the bundle",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "No valid X.509 authority in the bundle",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "0a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "FailedPrecondition",
+						telemetry.StatusMessage:    "no valid X.509 authority in the bundle",
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "0a",
+					},
+				},
+			},
+		},
+		{
+			name:         "already revoked",
+			authorityID:  "0a1b",
+			serialNumber: "01",
+			expectCode:   codes.AlreadyExists,
+			expectMsg:    "X509-SVID is already revoked",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "X509-SVID is already revoked",
+					Data: logrus.Fields{
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "01",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "AlreadyExists",
+						telemetry.StatusMessage:    "X509-SVID is already revoked",
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "01",
+					},
+				},
+			},
+		},
+		{
+			name:         "fail to fetch bundle",
+			authorityID:  "0a1b",
+			serialNumber: "0a",
+			dsError:      errors.New("oh no"),
+			expectCode:   codes.Internal,
+			expectMsg:    "failed to fetch bundle: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch bundle",
+					Data: logrus.Fields{
+						logrus.ErrorKey:            "oh no",
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "0a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "failed to fetch bundle: oh no",
+						telemetry.LocalAuthorityID: "0a1b",
+						telemetry.SerialNumber:     "0a",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			expiresAt := test.clk.Now().Add(time.Hour).Truncate(time.Second)
+			if !tt.noBundle {
+				test.createBundle(t, expiresAt.Add(-time.Minute), expiresAt)
+			}
+			_, err := test.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+				AuthorityID:  "0a1b",
+				SerialNumber: "01",
+				RevokedAt:    test.clk.Now(),
+				ExpiresAt:    expiresAt,
+			})
+			require.NoError(t, err)
+			test.ds.SetNextError(tt.dsError)
+			test.logHook.Reset()
+
+			resp, err := test.client.RevokeX509SVID(ctx, &svidrevocationv1.RevokeX509SVIDRequest{
+				AuthorityId:  tt.authorityID,
+				SerialNumber: tt.serialNumber,
+			})
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp(test.clk.Now(), expiresAt), resp)
+		})
+	}
+}
+
+func TestListRevokedX509SVIDs(t *testing.T) {
+	test := setupServiceTest(t)
+	defer test.Cleanup()
+
+	now := test.clk.Now().Truncate(time.Second)
+	for _, serialNumber := range []string{"0b", "0a"} {
+		_, err := test.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+			AuthorityID:  "0a1b",
+			SerialNumber: serialNumber,
+			RevokedAt:    now,
+			ExpiresAt:    now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	resp, err := test.client.ListRevokedX509SVIDs(ctx, &svidrevocationv1.ListRevokedX509SVIDsRequest{})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &svidrevocationv1.ListRevokedX509SVIDsResponse{
+		RevokedX509Svids: []*svidrevocationv1.RevokedX509SVID{
+			{AuthorityId: "0a1b", SerialNumber: "0a", RevokedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+			{AuthorityId: "0a1b", SerialNumber: "0b", RevokedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix()},
+		},
+	}, resp)
+
+	test.ds.SetNextError(errors.New("oh no"))
+	resp, err = test.client.ListRevokedX509SVIDs(ctx, &svidrevocationv1.ListRevokedX509SVIDsRequest{})
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, "failed to list revoked X509-SVIDs: oh no")
+	require.Nil(t, resp)
+}
+
+func setupServiceTest(t *testing.T) *serviceTest {
+	ds := fakedatastore.New(t)
+	clk := clock.NewMock(t)
+
+	service := svidrevocation.New(svidrevocation.Config{
+		TrustDomain: serverTrustDomain,
+		DataStore:   ds,
+		Clock:       clk,
+	})
+
+	log, logHook := test.NewNullLogger()
+	log.Level = logrus.DebugLevel
+
+	test := &serviceTest{
+		ds:      ds,
+		clk:     clk,
+		logHook: logHook,
+	}
+
+	overrideContext := func(ctx context.Context) context.Context {
+		return rpccontext.WithLogger(ctx, log)
+	}
+
+	server := grpctest.StartServer(t, func(s grpc.ServiceRegistrar) {
+		svidrevocation.RegisterService(s, service)
+	},
+		grpctest.OverrideContext(overrideContext),
+		grpctest.Middleware(middleware.WithAuditLog(false)),
+	)
+
+	conn := server.NewGRPCClient(t)
+
+	test.done = server.Stop
+	test.client = svidrevocationv1.NewSVIDRevocationClient(conn)
+
+	return test
+}
+
+type serviceTest struct {
+	client  svidrevocationv1.SVIDRevocationClient
+	done    func()
+	ds      *fakedatastore.DataStore
+	clk     *clock.Mock
+	logHook *test.Hook
+}
+
+func (s *serviceTest) Cleanup() {
+	s.done()
+}
+
+func (s *serviceTest) createBundle(t *testing.T, notBefore, notAfter time.Time) {
+	cert, _ := testca.CreateCACertificate(t, nil, nil, testca.WithLifetime(notBefore, notAfter))
+	_, err := s.ds.CreateBundle(ctx, &common.Bundle{
+		TrustDomainId: serverTrustDomain.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: cert.Raw}},
+	})
+	require.NoError(t, err)
+}
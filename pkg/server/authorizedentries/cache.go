@@ -9,6 +9,7 @@ import (
 	"github.com/google/btree"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 )
 
@@ -77,11 +78,13 @@ func (c *Cache) LookupAuthorizedEntries(agentID spiffeid.ID, requestedEntries ma
 	parentSeen := allocStringSet()
 	defer freeStringSet(parentSeen)
 
-	c.addDescendants(foundEntries, agentID.String(), requestedEntries, parentSeen)
+	nodeSelectors := selectorSetToProto(agent.Selectors)
+
+	c.addDescendants(foundEntries, agentID.String(), nodeSelectors, requestedEntries, parentSeen)
 
 	agentAliases := c.getAgentAliases(agent.Selectors)
 	for _, alias := range agentAliases {
-		c.addDescendants(foundEntries, alias.AliasID, requestedEntries, parentSeen)
+		c.addDescendants(foundEntries, alias.AliasID, nodeSelectors, requestedEntries, parentSeen)
 	}
 
 	return foundEntries
@@ -113,7 +116,70 @@ func (c *Cache) GetAuthorizedEntries(agentID spiffeid.ID) []*types.Entry {
 		records = c.appendDescendents(records, alias.AliasID, parentSeen)
 	}
 
-	return cloneEntriesFromRecords(records)
+	return materializeTemplateEntries(cloneEntriesFromRecords(records), agent.Selectors)
+}
+
+// AuthorizedAgents returns the IDs of the unexpired agents that are
+// authorized for each entry, keyed by entry ID. Entries that are not
+// authorized for any agent are omitted. Since this crawls the authorized
+// entries of every agent, it is expensive and not meant for the hot path.
+func (c *Cache) AuthorizedAgents() map[string][]string {
+	now := c.clk.Now().Unix()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	parentSeen := allocStringSet()
+	defer freeStringSet(parentSeen)
+
+	records := allocRecordSlice()
+	defer func() { freeRecordSlice(records) }()
+
+	authorizedAgents := make(map[string][]string)
+	c.agentsByID.Ascend(func(agent agentRecord) bool {
+		if agent.ExpiresAt <= now {
+			return true
+		}
+
+		clearStringSet(parentSeen)
+		records = c.appendDescendents(records[:0], agent.ID, parentSeen)
+		for _, alias := range c.getAgentAliases(agent.Selectors) {
+			records = c.appendDescendents(records, alias.AliasID, parentSeen)
+		}
+		var nodeSelectors []*types.Selector
+		for _, record := range records {
+			if entrytemplate.IsTemplate(record.SPIFFEID) {
+				if nodeSelectors == nil {
+					nodeSelectors = selectorSetToProto(agent.Selectors)
+				}
+				if _, ok := entrytemplate.ExpandEntry(record.EntryCloneOnly, nodeSelectors); !ok {
+					continue
+				}
+			}
+			authorizedAgents[record.EntryID] = append(authorizedAgents[record.EntryID], agent.ID)
+		}
+		return true
+	})
+	return authorizedAgents
+}
+
+// Clone returns a copy of the cache. The copy is made lazily, so cloning is
+// cheap, and subsequent updates to either cache do not affect the other.
+func (c *Cache) Clone() *Cache {
+	// Cloning a btree mutates its copy-on-write state, so a write lock is
+	// required.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return &Cache{
+		clk:               c.clk,
+		agentsByID:        c.agentsByID.Clone(),
+		agentsByExpiresAt: c.agentsByExpiresAt.Clone(),
+		aliasesByEntryID:  c.aliasesByEntryID.Clone(),
+		aliasesBySelector: c.aliasesBySelector.Clone(),
+		entriesByEntryID:  c.entriesByEntryID.Clone(),
+		entriesByParentID: c.entriesByParentID.Clone(),
+	}
 }
 
 func (c *Cache) UpdateEntry(entry *types.Entry) {
@@ -187,12 +253,16 @@ func (c *Cache) appendDescendents(records []entryRecord, parentID string, parent
 	records = c.appendEntryRecordsForParentID(records, parentID)
 	// Crawl the children that were appended to get their descendents
 	for _, entry := range records[lenBefore:] {
+		if entrytemplate.IsTemplate(entry.SPIFFEID) {
+			// Template entries cannot parent other entries.
+			continue
+		}
 		records = c.appendDescendents(records, entry.SPIFFEID, parentSeen)
 	}
 	return records
 }
 
-func (c *Cache) addDescendants(foundEntries map[string]*types.Entry, parentID string, requestedEntries map[string]struct{}, parentSeen stringSet) {
+func (c *Cache) addDescendants(foundEntries map[string]*types.Entry, parentID string, nodeSelectors []*types.Selector, requestedEntries map[string]struct{}, parentSeen stringSet) {
 	if _, ok := parentSeen[parentID]; ok {
 		return
 	}
@@ -204,10 +274,19 @@ func (c *Cache) addDescendants(foundEntries map[string]*types.Entry, parentID st
 			return false
 		}
 
+		if entrytemplate.IsTemplate(record.SPIFFEID) {
+			// Template entries are requested by their materialized entry
+			// ID, which only the expansion for this agent can match.
+			if entry, ok := materializeTemplateEntry(record.EntryCloneOnly, nodeSelectors, requestedEntries); ok {
+				foundEntries[entry.Id] = entry
+			}
+			return true
+		}
+
 		if _, ok := requestedEntries[record.EntryID]; ok {
 			foundEntries[record.EntryID] = cloneEntry(record.EntryCloneOnly)
 		}
-		c.addDescendants(foundEntries, record.SPIFFEID, requestedEntries, parentSeen)
+		c.addDescendants(foundEntries, record.SPIFFEID, nodeSelectors, requestedEntries, parentSeen)
 		return true
 	})
 }
@@ -326,6 +405,47 @@ func (c *Cache) Stats() CacheStats {
 	}
 }
 
+// materializeTemplateEntries replaces the template entries with the entries
+// materialized from them for the agent. Template entries that do not expand
+// for the agent are dropped.
+func materializeTemplateEntries(entries []*types.Entry, agentSelectors selectorSet) []*types.Entry {
+	var nodeSelectors []*types.Selector
+	materialized := entries[:0]
+	for _, entry := range entries {
+		if entrytemplate.IsTemplate(entry.SpiffeId.Path) {
+			if nodeSelectors == nil {
+				nodeSelectors = selectorSetToProto(agentSelectors)
+			}
+			id, ok := entrytemplate.ExpandEntry(entry, nodeSelectors)
+			if !ok {
+				continue
+			}
+			entry.Id = entrytemplate.MaterializedEntryID(entry.Id, id)
+			entry.SpiffeId = &types.SPIFFEID{TrustDomain: id.TrustDomain().Name(), Path: id.Path()}
+		}
+		materialized = append(materialized, entry)
+	}
+	return materialized
+}
+
+// materializeTemplateEntry returns the entry materialized from the template
+// entry for the agent, if it is one of the requested entries.
+func materializeTemplateEntry(entry *types.Entry, nodeSelectors []*types.Selector, requestedEntries map[string]struct{}) (*types.Entry, bool) {
+	id, ok := entrytemplate.ExpandEntry(entry, nodeSelectors)
+	if !ok {
+		return nil, false
+	}
+	entryID := entrytemplate.MaterializedEntryID(entry.Id, id)
+	if _, ok := requestedEntries[entryID]; !ok {
+		return nil, false
+	}
+
+	materialized := cloneEntry(entry)
+	materialized.Id = entryID
+	materialized.SpiffeId = &types.SPIFFEID{TrustDomain: id.TrustDomain().Name(), Path: id.Path()}
+	return materialized, true
+}
+
 func spiffeIDFromProto(id *types.SPIFFEID) string {
 	return fmt.Sprintf("spiffe://%s%s", id.TrustDomain, id.Path)
 }
@@ -10,6 +10,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/test/clock"
@@ -168,6 +169,61 @@ func TestGetAuthorizedEntries(t *testing.T) {
 	})
 }
 
+func TestTemplateEntries(t *testing.T) {
+	var (
+		aliasEntry    = makeAlias(alias1, sel1)
+		templateEntry = makeTemplate(alias1)
+		// Entries cannot be parented by a template entry. This is not
+		// expected to happen since parent IDs are validated, but the
+		// template must not be crawled for descendants regardless.
+		childEntry = makeWorkload(spiffeid.ID{})
+
+		prodID = spiffeid.RequireFromPath(td, "/cluster/prod/ns/default")
+		devID  = spiffeid.RequireFromPath(td, "/cluster/dev/ns/default")
+	)
+	childEntry.ParentId = templateEntry.SpiffeId
+
+	_, cache := testCache().
+		withAgent(agent1, sel1, &types.Selector{Type: "node", Value: "cluster:prod"}).
+		withAgent(agent2, sel1, &types.Selector{Type: "node", Value: "cluster:dev"}).
+		withAgent(agent3, sel1).
+		withEntries(aliasEntry, templateEntry, childEntry).
+		hydrate(t)
+
+	materialize := func(id spiffeid.ID) *types.Entry {
+		entry := cloneEntry(templateEntry)
+		entry.Id = entrytemplate.MaterializedEntryID(templateEntry.Id, id)
+		entry.SpiffeId = api.ProtoFromID(id)
+		return entry
+	}
+	prodEntry := materialize(prodID)
+	devEntry := materialize(devID)
+
+	// The template is expanded with the node selectors of each agent
+	spiretest.AssertProtoListEqual(t, []*types.Entry{prodEntry}, cache.GetAuthorizedEntries(agent1))
+	spiretest.AssertProtoListEqual(t, []*types.Entry{devEntry}, cache.GetAuthorizedEntries(agent2))
+	assert.Empty(t, cache.GetAuthorizedEntries(agent3))
+
+	// Only the expansion for the agent can be looked up
+	requested := map[string]struct{}{
+		templateEntry.Id: {},
+		prodEntry.Id:     {},
+		devEntry.Id:      {},
+		childEntry.Id:    {},
+	}
+	found := cache.LookupAuthorizedEntries(agent1, requested)
+	require.Len(t, found, 1)
+	spiretest.AssertProtoEqual(t, prodEntry, found[prodEntry.Id])
+	found = cache.LookupAuthorizedEntries(agent2, requested)
+	require.Len(t, found, 1)
+	spiretest.AssertProtoEqual(t, devEntry, found[devEntry.Id])
+	assert.Empty(t, cache.LookupAuthorizedEntries(agent3, requested))
+
+	assert.Equal(t, map[string][]string{
+		templateEntry.Id: {agent1.String(), agent2.String()},
+	}, cache.AuthorizedAgents())
+}
+
 func TestCacheInternalStats(t *testing.T) {
 	// This test asserts that the internal indexes are properly maintained
 	// across various operations. The motivation is to ensure that as the cache
@@ -253,6 +309,60 @@ func TestCacheInternalStats(t *testing.T) {
 	})
 }
 
+func TestAuthorizedAgents(t *testing.T) {
+	var (
+		aliasEntry     = makeAlias(alias1, sel1, sel2)
+		delegateeEntry = makeDelegatee(agent1, delegatee)
+		workload1      = makeWorkload(agent1)
+		workload2      = makeWorkload(delegatee)
+		workload3      = makeWorkload(alias1)
+		workload4      = makeWorkload(agent4)
+		orphan         = makeWorkload(agent3)
+	)
+
+	_, cache := testCache().
+		withAgent(agent1, sel1).
+		withAgent(agent2, sel1, sel2).
+		withAgent(agent3, sel2, sel3).
+		withExpiredAgent(agent4, time.Minute, sel1, sel2).
+		withEntries(aliasEntry, delegateeEntry, workload1, workload2, workload3, workload4).
+		hydrate(t)
+	cache.UpdateEntry(orphan)
+	cache.RemoveAgent(agent3.String())
+
+	assert.Equal(t, map[string][]string{
+		delegateeEntry.Id: {agent1.String()},
+		workload1.Id:      {agent1.String()},
+		workload2.Id:      {agent1.String()},
+		workload3.Id:      {agent2.String()},
+	}, cache.AuthorizedAgents())
+}
+
+func TestClone(t *testing.T) {
+	workload1 := makeWorkload(agent1)
+	workload2 := makeWorkload(agent1)
+	test, cache := testCache().
+		withAgent(agent1, sel1).
+		withEntries(workload1).
+		hydrate(t)
+
+	clone := cache.Clone()
+	clone.UpdateEntry(workload2)
+	clone.UpdateAgent(agent2.String(), now.Add(time.Hour), nil)
+	cache.RemoveEntry(workload1.Id)
+
+	assertAuthorizedEntries(t, cache, agent1, test.entries)
+	test.withEntries(workload2)
+	assertAuthorizedEntries(t, clone, agent1, test.entries, workload1, workload2)
+	assert.Equal(t, CacheStats{AgentsByID: 1, AgentsByExpiresAt: 1}, cache.Stats())
+	assert.Equal(t, CacheStats{
+		AgentsByID:        2,
+		AgentsByExpiresAt: 2,
+		EntriesByEntryID:  2,
+		EntriesByParentID: 2,
+	}, clone.Stats())
+}
+
 func testCache() *cacheTest {
 	return &cacheTest{
 		entries: make(map[string]*types.Entry),
@@ -347,6 +457,15 @@ func makeWorkload(parent spiffeid.ID) *types.Entry {
 	}
 }
 
+func makeTemplate(parent spiffeid.ID) *types.Entry {
+	return &types.Entry{
+		Id:        fmt.Sprintf("template-%d(parent=%s)", makeEntryIDPrefix(), parent),
+		ParentId:  api.ProtoFromID(parent),
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/cluster/{{node:cluster}}/ns/{{k8s:ns}}"},
+		Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+	}
+}
+
 var nextEntryIDPrefix int32
 
 func makeEntryIDPrefix() int32 {
@@ -14,6 +14,14 @@ func selectorSetFromProto(selectors []*types.Selector) selectorSet {
 	return set
 }
 
+func selectorSetToProto(set selectorSet) []*types.Selector {
+	selectors := make([]*types.Selector, 0, len(set))
+	for selector := range set {
+		selectors = append(selectors, &types.Selector{Type: selector.Type, Value: selector.Value})
+	}
+	return selectors
+}
+
 // Returns true if sub is a subset of whole
 func isSubset(sub, whole selectorSet) bool {
 	if len(sub) > len(whole) {
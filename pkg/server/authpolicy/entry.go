@@ -0,0 +1,44 @@
+package authpolicy
+
+import (
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+)
+
+// Entry is the decoded form of a registration entry provided as policy
+// input. SPIFFE IDs are formatted as URIs and selectors as "type:value".
+type Entry struct {
+	ID            string   `json:"id,omitempty"`
+	SPIFFEID      string   `json:"spiffe_id,omitempty"`
+	ParentID      string   `json:"parent_id,omitempty"`
+	Selectors     []string `json:"selectors,omitempty"`
+	FederatesWith []string `json:"federates_with,omitempty"`
+	DNSNames      []string `json:"dns_names,omitempty"`
+	Admin         bool     `json:"admin"`
+	Downstream    bool     `json:"downstream"`
+	Hint          string   `json:"hint,omitempty"`
+}
+
+// EntryFromProto decodes an entry for use as policy input.
+func EntryFromProto(e *types.Entry) Entry {
+	entry := Entry{
+		ID:            e.Id,
+		SPIFFEID:      idFromProto(e.SpiffeId),
+		ParentID:      idFromProto(e.ParentId),
+		FederatesWith: e.FederatesWith,
+		DNSNames:      e.DnsNames,
+		Admin:         e.Admin,
+		Downstream:    e.Downstream,
+		Hint:          e.Hint,
+	}
+	for _, selector := range e.Selectors {
+		entry.Selectors = append(entry.Selectors, selector.Type+":"+selector.Value)
+	}
+	return entry
+}
+
+func idFromProto(id *types.SPIFFEID) string {
+	if id == nil {
+		return ""
+	}
+	return "spiffe://" + id.TrustDomain + id.Path
+}
@@ -20,11 +20,16 @@ const (
 	allowIfDownstreamKey = "allow_if_downstream"
 	allowIfAgentKey      = "allow_if_agent"
 	allowIfLocalKey      = "allow_if_local"
+	allowEntryKey        = "allow_entry"
 )
 
 // Engine drives policy management.
 type Engine struct {
 	rego rego.PartialResult
+
+	// definesAllowEntry is true if the allow_entry result of the policy can
+	// be false
+	definesAllowEntry bool
 }
 
 type OpaEngineConfig struct {
@@ -49,6 +54,20 @@ type Input struct {
 	// protobuf request object with fields that are serializable as JSON,
 	// since they will be used in policy definitions.
 	Req any `json:"req"`
+
+	// Entries holds the decoded entries of BatchCreateEntry,
+	// BatchUpdateEntry and BatchDeleteEntry requests. Only the entry IDs are
+	// available for BatchDeleteEntry requests.
+	Entries []Entry `json:"entries,omitempty"`
+
+	// Entry is set when the policy is evaluated for an individual entry of a
+	// batch entry request. For creates and updates, it is the entry as it
+	// will be stored. For deletes, it is the entry being deleted.
+	Entry *Entry `json:"entry,omitempty"`
+
+	// ExistingEntry is set along with Entry for updates and holds the entry
+	// as it is currently stored.
+	ExistingEntry *Entry `json:"existing_entry,omitempty"`
 }
 
 type Result struct {
@@ -57,6 +76,11 @@ type Result struct {
 	AllowIfLocal      bool `json:"allow_if_local"`
 	AllowIfDownstream bool `json:"allow_if_downstream"`
 	AllowIfAgent      bool `json:"allow_if_agent"`
+
+	// AllowEntry determines whether the caller may act on the entry in the
+	// input. It is only relevant when the input has an entry and defaults to
+	// true when the policy result does not define it.
+	AllowEntry bool `json:"allow_entry"`
 }
 
 // NewEngineFromConfigOrDefault returns a new policy engine. Or if no
@@ -126,8 +150,14 @@ func NewEngineFromRego(ctx context.Context, regoPolicy string, dataStore storage
 		return nil, err
 	}
 
+	module, err := ast.ParseModuleWithOpts("spire.rego", regoPolicy, ast.ParserOptions{RegoVersion: version})
+	if err != nil {
+		return nil, err
+	}
+
 	e := &Engine{
-		rego: pr,
+		rego:              pr,
+		definesAllowEntry: definesAllowEntry(module),
 	}
 
 	// Test policy with some simple calls to ensure that the
@@ -139,6 +169,14 @@ func NewEngineFromRego(ctx context.Context, regoPolicy string, dataStore storage
 	return e, nil
 }
 
+// DefinesAllowEntry returns true if the policy defines an allow_entry result
+// that can deny entries. Policies without an allow_entry result, or where it
+// is only given by a default rule set to true, as in the default policy,
+// allow every entry, so there is no need to evaluate them for each entry.
+func (e *Engine) DefinesAllowEntry() bool {
+	return e.definesAllowEntry
+}
+
 // Eval determines whether access should be allowed on a resource.
 func (e *Engine) Eval(ctx context.Context, input Input) (result Result, err error) {
 	rs, err := e.rego.Rego(rego.Input(input)).Eval(ctx)
@@ -184,5 +222,69 @@ func (e *Engine) Eval(ctx context.Context, input Input) (result Result, err erro
 		return Result{}, err
 	}
 
+	// allow_entry was introduced after the other keys, so it is optional to
+	// remain compatible with existing policies.
+	result.AllowEntry = true
+	if _, ok := resultMap[allowEntryKey]; ok {
+		if result.AllowEntry, err = getBoolValue(allowEntryKey); err != nil {
+			return Result{}, err
+		}
+	}
+
 	return result, nil
 }
+
+// definesAllowEntry returns true if the allow_entry value of the result
+// object can be other than true. Values that cannot be told apart from the
+// module alone are assumed to be able to deny entries.
+func definesAllowEntry(module *ast.Module) bool {
+	for _, rule := range module.Rules {
+		if ruleName(rule) != "result" {
+			continue
+		}
+		object, ok := rule.Head.Value.Value.(ast.Object)
+		if !ok {
+			return true
+		}
+		value := object.Get(ast.StringTerm(allowEntryKey))
+		if value == nil {
+			continue
+		}
+		switch v := value.Value.(type) {
+		case ast.Boolean:
+			if !v {
+				return true
+			}
+		case ast.Var:
+			if hasNonDefaultRule(module, string(v)) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// hasNonDefaultRule returns true if the module defines the named rule other
+// than with a default rule set to true.
+func hasNonDefaultRule(module *ast.Module, name string) bool {
+	for _, rule := range module.Rules {
+		if ruleName(rule) != name {
+			continue
+		}
+		if !rule.Default || !ast.BooleanTerm(true).Equal(rule.Head.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleName(rule *ast.Rule) string {
+	ref := rule.Head.Ref()
+	if len(ref) != 1 {
+		return ""
+	}
+	name, _ := ref[0].Value.(ast.Var)
+	return string(name)
+}
@@ -12,6 +12,9 @@ package spire
 #   only if the caller has a downstream SPIFFE ID
 # - `allow_if_agent`: a boolean that if true, will authorize the call only if
 #   the caller is an agent
+# - `allow_entry`: a boolean that if false, will deny the individual entry in
+#   `input.entry` of a BatchCreateEntry, BatchUpdateEntry or BatchDeleteEntry
+#   call
 
 result = {
   "allow": allow, 
@@ -19,6 +22,7 @@ result = {
   "allow_if_local": allow_if_local,
   "allow_if_downstream": allow_if_downstream,
   "allow_if_agent": allow_if_agent,
+  "allow_entry": allow_entry,
 }
 
 
@@ -29,6 +33,7 @@ default allow_if_downstream = false
 default allow_if_local = false
 default allow_if_agent = false
 default allow = false 
+default allow_entry = true
 
 
 # Admin allow check
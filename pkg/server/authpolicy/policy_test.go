@@ -48,6 +48,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -69,6 +70,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -91,6 +93,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: true,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -110,6 +113,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -129,6 +133,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -148,6 +153,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -167,6 +173,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -189,6 +196,7 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
 			},
 		},
 		{
@@ -208,6 +216,73 @@ func TestPolicy(t *testing.T) {
 				AllowIfLocal:      false,
 				AllowIfDownstream: false,
 				AllowIfAgent:      false,
+				AllowEntry:        true,
+			},
+		},
+		{
+			name:     "test entry in namespace",
+			rego:     entryNamespaceRego,
+			jsonData: entryNamespaceData,
+			input: authpolicy.Input{
+				Caller:     "spiffe://example.org/team1/admin",
+				FullMethod: "/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+				Entry: &authpolicy.Entry{
+					SPIFFEID:  "spiffe://example.org/ns/team1/workload",
+					ParentID:  "spiffe://example.org/node",
+					Selectors: []string{"unix:uid:1000"},
+				},
+			},
+			expectResult: authpolicy.Result{
+				AllowEntry: true,
+			},
+		},
+		{
+			name:     "test entry outside of namespace",
+			rego:     entryNamespaceRego,
+			jsonData: entryNamespaceData,
+			input: authpolicy.Input{
+				Caller:     "spiffe://example.org/team1/admin",
+				FullMethod: "/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+				Entry: &authpolicy.Entry{
+					SPIFFEID: "spiffe://example.org/ns/team2/workload",
+				},
+			},
+			expectResult: authpolicy.Result{
+				AllowEntry: false,
+			},
+		},
+		{
+			name:     "test entry moved out of namespace",
+			rego:     entryNamespaceRego,
+			jsonData: entryNamespaceData,
+			input: authpolicy.Input{
+				Caller:     "spiffe://example.org/team1/admin",
+				FullMethod: "/spire.api.server.entry.v1.Entry/BatchUpdateEntry",
+				Entry: &authpolicy.Entry{
+					SPIFFEID: "spiffe://example.org/ns/team2/workload",
+				},
+				ExistingEntry: &authpolicy.Entry{
+					SPIFFEID: "spiffe://example.org/ns/team1/workload",
+				},
+			},
+			expectResult: authpolicy.Result{
+				AllowEntry: false,
+			},
+		},
+		{
+			name:     "test batch entries in namespace",
+			rego:     entryNamespaceRego,
+			jsonData: entryNamespaceData,
+			input: authpolicy.Input{
+				Caller:     "spiffe://example.org/team1/admin",
+				FullMethod: "/spire.api.server.entry.v1.Entry/BatchCreateEntry",
+				Entries: []authpolicy.Entry{
+					{SPIFFEID: "spiffe://example.org/ns/team1/workload1"},
+					{SPIFFEID: "spiffe://example.org/ns/team1/workload2"},
+				},
+			},
+			expectResult: authpolicy.Result{
+				Allow: true,
 			},
 		},
 	} {
@@ -440,6 +515,81 @@ func TestNewEngineFromRego(t *testing.T) {
 	}
 }
 
+func TestDefinesAllowEntry(t *testing.T) {
+	resultWithAllowEntry := func(value string) string {
+		return `
+    package spire
+    result = {
+      "allow": false,
+      "allow_if_admin": false,
+      "allow_if_local": false,
+      "allow_if_downstream": false,
+      "allow_if_agent": false,
+      "allow_entry": ` + value + `
+    }
+    `
+	}
+
+	for _, tt := range []struct {
+		name   string
+		rego   string
+		expect bool
+	}{
+		{
+			name:   "without allow_entry",
+			rego:   simpleRego(map[string]bool{}),
+			expect: false,
+		},
+		{
+			name:   "allow_entry set to true",
+			rego:   resultWithAllowEntry("true"),
+			expect: false,
+		},
+		{
+			name:   "allow_entry set to false",
+			rego:   resultWithAllowEntry("false"),
+			expect: true,
+		},
+		{
+			name:   "allow_entry rule with only a true default",
+			rego:   resultWithAllowEntry("allow_entry") + "default allow_entry = true\n",
+			expect: false,
+		},
+		{
+			name:   "allow_entry rule with a false default",
+			rego:   resultWithAllowEntry("allow_entry") + "default allow_entry = false\n",
+			expect: true,
+		},
+		{
+			name: "allow_entry rule",
+			rego: resultWithAllowEntry("allow_entry") + `
+    default allow_entry = true
+    allow_entry = false if {
+        input.entry.admin
+    }
+    `,
+			expect: true,
+		},
+		{
+			name:   "allow_entry from a function call",
+			rego:   resultWithAllowEntry(`object.get(input, ["entry", "admin"], false)`),
+			expect: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := authpolicy.NewEngineFromRego(context.Background(), tt.rego, inmem.New(), ast.RegoV1)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, engine.DefinesAllowEntry())
+		})
+	}
+
+	t.Run("default policy", func(t *testing.T) {
+		engine, err := authpolicy.DefaultAuthPolicy(context.Background())
+		require.NoError(t, err)
+		require.False(t, engine.DefinesAllowEntry())
+	})
+}
+
 func condCheckRego(cond string) string {
 	regoTemplate := `
     package spire
@@ -485,3 +635,51 @@ var badEvalPolicy = `
         %s
     }
     `
+
+const (
+	entryNamespaceData = `{
+        "entry_namespaces": [
+            {
+                "user": "spiffe://example.org/team1/admin",
+                "prefix": "spiffe://example.org/ns/team1/"
+            }
+        ]
+    }`
+
+	entryNamespaceRego = `
+    package spire
+    result = {
+      "allow": allow,
+      "allow_if_admin": false,
+      "allow_if_local": false,
+      "allow_if_downstream": false,
+      "allow_if_agent": false,
+      "allow_entry": allow_entry
+    }
+    default allow = false
+    default allow_entry = false
+
+    in_namespace(entry) if {
+        ns := data.entry_namespaces[_]
+        ns.user == input.caller
+        startswith(entry.spiffe_id, ns.prefix)
+    }
+
+    allow = true if {
+        count(input.entries) > 0
+        every entry in input.entries {
+            in_namespace(entry)
+        }
+    }
+
+    allow_entry = true if {
+        in_namespace(input.entry)
+        not input.existing_entry
+    }
+
+    allow_entry = true if {
+        in_namespace(input.entry)
+        in_namespace(input.existing_entry)
+    }
+    `
+)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
 	"errors"
@@ -42,14 +43,44 @@ type ClientConfig struct { //revive:disable-line:exported name stutter is intent
 	// connections.
 	TLSPolicy tlspolicy.Policy
 
+	// ETag is the entity tag of the bundle previously fetched from the
+	// endpoint. If set, the bundle is fetched with a conditional request and
+	// FetchBundle returns ErrNotModified if it did not change.
+	ETag string
+
+	// SignatureURL, if set, is the URL of the detached JWS signature over the
+	// bundle. The signature is verified against the JWT authorities of
+	// TrustedBundle.
+	SignatureURL string
+
+	// TrustedBundle is the previously trusted bundle of the trust domain,
+	// required to verify the bundle signature.
+	TrustedBundle *spiffebundle.Bundle
+
 	// mutateTransportHook is a hook to influence the transport used during
 	// tests.
 	mutateTransportHook func(*http.Transport)
 }
 
+const (
+	// maxBundleSize is the maximum size of a bundle document. It is large
+	// enough for bundles with hundreds of authorities.
+	maxBundleSize = 4 * 1024 * 1024
+
+	// maxSignatureSize is the maximum size of a detached JWS signature.
+	maxSignatureSize = 64 * 1024
+)
+
+// ErrNotModified is returned by FetchBundle when the bundle served by the
+// endpoint still matches the configured entity tag.
+var ErrNotModified = errors.New("bundle not modified")
+
 // Client is used to fetch a bundle and metadata from a bundle endpoint
 type Client interface {
-	FetchBundle(context.Context) (*spiffebundle.Bundle, error)
+	// FetchBundle fetches the bundle from the endpoint. It returns the
+	// bundle and its entity tag, which is empty if the endpoint does not
+	// provide one.
+	FetchBundle(context.Context) (*spiffebundle.Bundle, string, error)
 }
 
 type client struct {
@@ -85,29 +116,91 @@ func NewClient(config ClientConfig) (Client, error) {
 	}, nil
 }
 
-func (c *client) FetchBundle(context.Context) (*spiffebundle.Bundle, error) {
-	resp, err := c.client.Get(c.c.EndpointURL)
+func (c *client) FetchBundle(ctx context.Context) (*spiffebundle.Bundle, string, error) {
+	if c.c.SignatureURL != "" && c.c.TrustedBundle == nil {
+		return nil, "", errors.New("a trusted bundle is required to verify the bundle signature")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.c.EndpointURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create bundle request: %w", err)
+	}
+	if c.c.ETag != "" {
+		req.Header.Set("If-None-Match", c.c.ETag)
+	}
+
+	resp, err := c.client.Do(req)
 	if err != nil {
 		var hostnameError x509.HostnameError
 		if errors.As(err, &hostnameError) && c.c.SPIFFEAuth == nil && len(hostnameError.Certificate.URIs) > 0 {
 			if id, idErr := spiffeid.FromString(hostnameError.Certificate.URIs[0].String()); idErr == nil {
-				return nil, fmt.Errorf("failed to authenticate bundle endpoint using web authentication but the server certificate contains SPIFFE ID %q: maybe use https_spiffe instead of https_web: %w", id, err)
+				return nil, "", fmt.Errorf("failed to authenticate bundle endpoint using web authentication but the server certificate contains SPIFFE ID %q: maybe use https_spiffe instead of https_web: %w", id, err)
 			}
 		}
-		return nil, fmt.Errorf("failed to fetch bundle: %w", err)
+		return nil, "", fmt.Errorf("failed to fetch bundle: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && c.c.ETag != "" {
+		return nil, "", ErrNotModified
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status %d fetching bundle: %s", resp.StatusCode, tryRead(resp.Body))
+		return nil, "", fmt.Errorf("unexpected status %d fetching bundle: %s", resp.StatusCode, tryRead(resp.Body))
 	}
 
-	b, err := bundleutil.Decode(c.c.TrustDomain, resp.Body)
+	doc, err := readAll(resp.Body, maxBundleSize)
 	if err != nil {
-		return nil, err
+		return nil, "", fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if c.c.SignatureURL != "" {
+		if err := c.verifySignature(ctx, doc); err != nil {
+			return nil, "", err
+		}
+	}
+
+	b, err := bundleutil.Decode(c.c.TrustDomain, bytes.NewReader(doc))
+	if err != nil {
+		return nil, "", err
 	}
 
-	return b, nil
+	return b, resp.Header.Get("ETag"), nil
+}
+
+func (c *client) verifySignature(ctx context.Context, doc []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.c.SignatureURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle signature request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch bundle signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching bundle signature: %s", resp.StatusCode, tryRead(resp.Body))
+	}
+
+	signature, err := readAll(resp.Body, maxSignatureSize)
+	if err != nil {
+		return fmt.Errorf("failed to read bundle signature: %w", err)
+	}
+
+	return bundleutil.VerifyBundleSignature(doc, string(bytes.TrimSpace(signature)), c.c.TrustedBundle)
+}
+
+// readAll reads r until EOF, failing if it has more than limit bytes.
+func readAll(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response exceeds %d bytes", limit)
+	}
+	return data, nil
 }
 
 func tryRead(r io.Reader) string {
@@ -15,8 +15,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 )
 
@@ -142,7 +145,7 @@ func TestClient(t *testing.T) {
 			}
 			require.NoError(t, err)
 
-			bundle, err := client.FetchBundle(context.Background())
+			bundle, _, err := client.FetchBundle(context.Background())
 			if testCase.fetchBundleErr != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), testCase.fetchBundleErr)
@@ -158,6 +161,158 @@ func TestClient(t *testing.T) {
 	}
 }
 
+func TestClientConditionalRequest(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t, serverID)
+
+	const etag = `"ETAG"`
+	var ifNoneMatch string
+	server := newTestServer(t, serverCert, serverKey, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ifNoneMatch = req.Header.Get("If-None-Match")
+		w.Header().Set("ETag", etag)
+		if ifNoneMatch == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"spiffe_refresh_hint": 10}`))
+	}))
+
+	config := ClientConfig{
+		TrustDomain: trustDomain,
+		EndpointURL: server.URL,
+		SPIFFEAuth: &SPIFFEAuthConfig{
+			EndpointSpiffeID: serverID,
+			RootCAs:          []*x509.Certificate{serverCert},
+		},
+	}
+
+	// No entity tag: the bundle is fetched unconditionally
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	bundle, gotETag, err := client.FetchBundle(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, bundle)
+	require.Equal(t, etag, gotETag)
+	require.Empty(t, ifNoneMatch)
+
+	// Matching entity tag: the bundle is not modified
+	config.ETag = etag
+	client, err = NewClient(config)
+	require.NoError(t, err)
+	bundle, _, err = client.FetchBundle(context.Background())
+	require.ErrorIs(t, err, ErrNotModified)
+	require.Nil(t, bundle)
+	require.Equal(t, etag, ifNoneMatch)
+
+	// Stale entity tag: the bundle is fetched again
+	config.ETag = `"STALE"`
+	client, err = NewClient(config)
+	require.NoError(t, err)
+	bundle, gotETag, err = client.FetchBundle(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, bundle)
+	require.Equal(t, etag, gotETag)
+	require.Equal(t, `"STALE"`, ifNoneMatch)
+}
+
+func TestClientBundleSignature(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t, serverID)
+
+	jwtKey := testkey.MustEC256()
+	otherKey := testkey.MustEC384()
+	trustedBundle := spiffebundle.New(trustDomain)
+	require.NoError(t, trustedBundle.AddJWTAuthority("KID", jwtKey.Public()))
+
+	doc := []byte(`{"spiffe_refresh_hint": 10}`)
+	validSignature, err := bundleutil.SignBundle(doc, jwtKey, "KID")
+	require.NoError(t, err)
+	untrustedSignature, err := bundleutil.SignBundle(doc, otherKey, "KID")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name            string
+		signature       string
+		signatureStatus int
+		trustedBundle   *spiffebundle.Bundle
+		expectErr       string
+	}{
+		{
+			name:            "valid signature",
+			signature:       validSignature,
+			signatureStatus: http.StatusOK,
+			trustedBundle:   trustedBundle,
+		},
+		{
+			name:            "signed by untrusted key",
+			signature:       untrustedSignature,
+			signatureStatus: http.StatusOK,
+			trustedBundle:   trustedBundle,
+			expectErr:       "failed to verify bundle signature",
+		},
+		{
+			name:            "signature not found",
+			signatureStatus: http.StatusNotFound,
+			trustedBundle:   trustedBundle,
+			expectErr:       "unexpected status 404 fetching bundle signature",
+		},
+		{
+			name:            "no trusted bundle",
+			signature:       validSignature,
+			signatureStatus: http.StatusOK,
+			expectErr:       "a trusted bundle is required to verify the bundle signature",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write(doc)
+			})
+			mux.HandleFunc("/bundle.jws", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tt.signatureStatus)
+				_, _ = w.Write([]byte(tt.signature))
+			})
+			server := newTestServer(t, serverCert, serverKey, mux)
+
+			client, err := NewClient(ClientConfig{
+				TrustDomain:   trustDomain,
+				EndpointURL:   server.URL,
+				SignatureURL:  server.URL + "/bundle.jws",
+				TrustedBundle: tt.trustedBundle,
+				SPIFFEAuth: &SPIFFEAuthConfig{
+					EndpointSpiffeID: serverID,
+					RootCAs:          []*x509.Certificate{serverCert},
+				},
+			})
+			require.NoError(t, err)
+
+			bundle, _, err := client.FetchBundle(context.Background())
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				require.Nil(t, bundle)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, bundle)
+		})
+	}
+}
+
+func newTestServer(t *testing.T, serverCert *x509.Certificate, serverKey crypto.Signer, handler http.Handler) *httptest.Server {
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{serverCert.Raw},
+				PrivateKey:  serverKey,
+			},
+		},
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
 func createServerCertificate(t *testing.T, serverID spiffeid.ID) (*x509.Certificate, crypto.Signer) {
 	return spiretest.SelfSignCertificate(t, &x509.Certificate{
 		SerialNumber: big.NewInt(0),
@@ -42,6 +42,11 @@ type TrustDomainConfig struct {
 	// EndpointProfile is the bundle endpoint profile used by the
 	// SPIFFE bundle endpoint server.
 	EndpointProfile EndpointProfileInfo
+
+	// SignatureURL, if set, is the URL used to fetch the detached JWS
+	// signature over the bundle served at EndpointURL. The signature must be
+	// made by a JWT authority of the previously trusted bundle.
+	SignatureURL string
 }
 
 type EndpointProfileInfo interface {
@@ -47,6 +47,13 @@ type bundleUpdater struct {
 
 	trustDomainConfigMtx sync.Mutex
 	trustDomainConfig    TrustDomainConfig
+
+	// etag is the entity tag of the last bundle fetched from the endpoint,
+	// and etagBundle the bundle it identifies. The etag is only used for
+	// conditional requests while etagBundle matches the local bundle.
+	etagMtx    sync.Mutex
+	etag       string
+	etagBundle *spiffebundle.Bundle
 }
 
 func NewBundleUpdater(config BundleUpdaterConfig) BundleUpdater {
@@ -64,22 +71,26 @@ func NewBundleUpdater(config BundleUpdaterConfig) BundleUpdater {
 func (u *bundleUpdater) UpdateBundle(ctx context.Context) (*spiffebundle.Bundle, *spiffebundle.Bundle, error) {
 	trustDomainConfig := u.GetTrustDomainConfig()
 
-	client, err := u.newClient(ctx, trustDomainConfig)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	localFederatedBundleOrNil, err := fetchBundleIfExists(ctx, u.ds, u.td)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to fetch local federated bundle: %w", err)
 	}
 
-	fetchedFederatedBundle, err := client.FetchBundle(ctx)
+	client, err := u.newClient(ctx, trustDomainConfig, localFederatedBundleOrNil)
 	if err != nil {
+		return nil, nil, err
+	}
+
+	fetchedFederatedBundle, etag, err := client.FetchBundle(ctx)
+	switch {
+	case errors.Is(err, ErrNotModified):
+		return localFederatedBundleOrNil, nil, nil
+	case err != nil:
 		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to fetch federated bundle from endpoint: %w", err)
 	}
 
 	if localFederatedBundleOrNil != nil && fetchedFederatedBundle.Equal(localFederatedBundleOrNil) {
+		u.setETag(etag, localFederatedBundleOrNil)
 		return localFederatedBundleOrNil, nil, nil
 	}
 
@@ -87,11 +98,17 @@ func (u *bundleUpdater) UpdateBundle(ctx context.Context) (*spiffebundle.Bundle,
 	if err != nil {
 		return nil, nil, err
 	}
-	_, err = u.ds.SetBundle(ctx, bundle)
+	storedBundle, err := u.ds.SetBundle(ctx, bundle)
 	if err != nil {
 		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to store fetched federated bundle: %w", err)
 	}
 
+	// Track the entity tag against the bundle as stored, which is what the
+	// next update fetches as the local bundle.
+	if storedFederatedBundle, err := bundleutil.SPIFFEBundleFromProto(storedBundle); err == nil {
+		u.setETag(etag, storedFederatedBundle)
+	}
+
 	return localFederatedBundleOrNil, fetchedFederatedBundle, nil
 }
 
@@ -107,15 +124,42 @@ func (u *bundleUpdater) SetTrustDomainConfig(trustDomainConfig TrustDomainConfig
 	defer u.trustDomainConfigMtx.Unlock()
 	if u.trustDomainConfig != trustDomainConfig {
 		u.trustDomainConfig = trustDomainConfig
+		u.setETag("", nil)
 		return true
 	}
 	return false
 }
 
-func (u *bundleUpdater) newClient(ctx context.Context, trustDomainConfig TrustDomainConfig) (Client, error) {
+// getETag returns the entity tag of the last fetched bundle if it still
+// matches the local bundle.
+func (u *bundleUpdater) getETag(localBundle *spiffebundle.Bundle) string {
+	u.etagMtx.Lock()
+	defer u.etagMtx.Unlock()
+	if localBundle == nil || u.etagBundle == nil || !u.etagBundle.Equal(localBundle) {
+		return ""
+	}
+	return u.etag
+}
+
+func (u *bundleUpdater) setETag(etag string, bundle *spiffebundle.Bundle) {
+	u.etagMtx.Lock()
+	defer u.etagMtx.Unlock()
+	u.etag = etag
+	u.etagBundle = bundle
+}
+
+func (u *bundleUpdater) newClient(ctx context.Context, trustDomainConfig TrustDomainConfig, localBundle *spiffebundle.Bundle) (Client, error) {
 	clientConfig := ClientConfig{
 		TrustDomain: u.td,
 		EndpointURL: trustDomainConfig.EndpointURL,
+		ETag:        u.getETag(localBundle),
+	}
+
+	// The bundle signature is verified against the previously trusted
+	// bundle. Without one, the first bundle is trusted as fetched.
+	if trustDomainConfig.SignatureURL != "" && localBundle != nil {
+		clientConfig.SignatureURL = trustDomainConfig.SignatureURL
+		clientConfig.TrustedBundle = localBundle
 	}
 
 	if spiffeAuth, ok := trustDomainConfig.EndpointProfile.(HTTPSSPIFFEProfile); ok {
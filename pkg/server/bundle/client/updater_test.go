@@ -147,6 +147,88 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 	}
 }
 
+func TestBundleUpdaterConditionalUpdate(t *testing.T) {
+	bundle1 := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "bundle1")})
+	bundle1.SetRefreshHint(0)
+	bundle1.SetSequenceNumber(1)
+	bundle2 := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "bundle2")})
+	bundle2.SetRefreshHint(time.Minute)
+	bundle2.SetSequenceNumber(2)
+
+	ds := fakedatastore.New(t)
+	bundle1Proto, err := bundleutil.SPIFFEBundleToProto(bundle1)
+	require.NoError(t, err)
+	_, err = ds.CreateBundle(context.Background(), bundle1Proto)
+	require.NoError(t, err)
+
+	var lastConfig ClientConfig
+	client := fakeClient{bundle: bundle2, etag: `"ETAG2"`}
+	trustDomainConfig := TrustDomainConfig{
+		EndpointURL:     "https://domain.test/bundle",
+		EndpointProfile: HTTPSWebProfile{},
+		SignatureURL:    "https://domain.test/bundle.jws",
+	}
+	updater := NewBundleUpdater(BundleUpdaterConfig{
+		DataStore:         ds,
+		TrustDomain:       trustDomain,
+		TrustDomainConfig: trustDomainConfig,
+		newClientHook: func(config ClientConfig) (Client, error) {
+			lastConfig = config
+			return client, nil
+		},
+	})
+
+	// The first update is unconditional and the signature is verified
+	// against the local bundle.
+	_, endpointBundle, err := updater.UpdateBundle(context.Background())
+	require.NoError(t, err)
+	require.True(t, endpointBundle.Equal(bundle2))
+	require.Empty(t, lastConfig.ETag)
+	require.Equal(t, "https://domain.test/bundle.jws", lastConfig.SignatureURL)
+	require.True(t, lastConfig.TrustedBundle.Equal(bundle1))
+
+	// The next update is conditional on the entity tag of the stored bundle
+	client.err = ErrNotModified
+	localBundle, endpointBundle, err := updater.UpdateBundle(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, endpointBundle)
+	require.True(t, localBundle.Equal(bundle2))
+	require.Equal(t, `"ETAG2"`, lastConfig.ETag)
+	require.True(t, lastConfig.TrustedBundle.Equal(bundle2))
+
+	// Changing the configuration resets the entity tag
+	trustDomainConfig.EndpointURL = "https://domain.test/other"
+	require.True(t, updater.SetTrustDomainConfig(trustDomainConfig))
+	_, _, err = updater.UpdateBundle(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, lastConfig.ETag)
+}
+
+func TestBundleUpdaterSignatureTrustOnFirstUse(t *testing.T) {
+	bundle := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "bundle")})
+
+	var lastConfig ClientConfig
+	updater := NewBundleUpdater(BundleUpdaterConfig{
+		DataStore:   fakedatastore.New(t),
+		TrustDomain: trustDomain,
+		TrustDomainConfig: TrustDomainConfig{
+			EndpointURL:     "https://domain.test/bundle",
+			EndpointProfile: HTTPSWebProfile{},
+			SignatureURL:    "https://domain.test/bundle.jws",
+		},
+		newClientHook: func(config ClientConfig) (Client, error) {
+			lastConfig = config
+			return fakeClient{bundle: bundle}, nil
+		},
+	})
+
+	_, endpointBundle, err := updater.UpdateBundle(context.Background())
+	require.NoError(t, err)
+	require.True(t, endpointBundle.Equal(bundle))
+	require.Empty(t, lastConfig.SignatureURL)
+	require.Nil(t, lastConfig.TrustedBundle)
+}
+
 func TestBundleUpdaterConfiguration(t *testing.T) {
 	configs := []TrustDomainConfig{
 		{
@@ -181,11 +263,12 @@ func TestBundleUpdaterConfiguration(t *testing.T) {
 
 type fakeClient struct {
 	bundle *spiffebundle.Bundle
+	etag   string
 	err    error
 }
 
-func (c fakeClient) FetchBundle(context.Context) (*spiffebundle.Bundle, error) {
-	return c.bundle, c.err
+func (c fakeClient) FetchBundle(context.Context) (*spiffebundle.Bundle, string, error) {
+	return c.bundle, c.etag, c.err
 }
 
 func createCACertificate(t *testing.T, cn string) *x509.Certificate {
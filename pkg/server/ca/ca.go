@@ -23,6 +23,8 @@ import (
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/credvalidator"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -82,6 +84,10 @@ type WorkloadX509SVIDParams struct {
 
 	// Subject of the SVID. Default subject is used if it is empty.
 	Subject pkix.Name
+
+	// Workload describes what the SVID is minted for. It is passed to the
+	// credential composers.
+	Workload credentialcomposer.WorkloadContext
 }
 
 // WorkloadJWTSVIDParams are parameters relevant to workload JWT-SVID creation
@@ -95,6 +101,10 @@ type WorkloadJWTSVIDParams struct {
 
 	// Audience is used for audience claims
 	Audience []string
+
+	// Workload describes what the SVID is minted for. It is passed to the
+	// credential composers.
+	Workload credentialcomposer.WorkloadContext
 }
 
 type X509CA struct {
@@ -204,7 +214,10 @@ func (ca *CA) TaintedAuthorities() <-chan []*x509.Certificate {
 	return ca.taintedAuthoritiesCh
 }
 
-func (ca *CA) SignDownstreamX509CA(ctx context.Context, params DownstreamX509CAParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignDownstreamX509CA(ctx context.Context, params DownstreamX509CAParams) (_ []*x509.Certificate, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "ca.SignDownstreamX509CA")
+	defer telemetry.EndSpan(span, &err)
+
 	x509CA, caChain, err := ca.getX509CA()
 	if err != nil {
 		return nil, err
@@ -233,7 +246,10 @@ func (ca *CA) SignDownstreamX509CA(ctx context.Context, params DownstreamX509CAP
 	return makeCertChain(x509CA, downstreamCA), nil
 }
 
-func (ca *CA) SignServerX509SVID(ctx context.Context, params ServerX509SVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignServerX509SVID(ctx context.Context, params ServerX509SVIDParams) (_ []*x509.Certificate, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "ca.SignServerX509SVID")
+	defer telemetry.EndSpan(span, &err)
+
 	x509CA, caChain, err := ca.getX509CA()
 	if err != nil {
 		return nil, err
@@ -259,7 +275,10 @@ func (ca *CA) SignServerX509SVID(ctx context.Context, params ServerX509SVIDParam
 	return svidChain, nil
 }
 
-func (ca *CA) SignAgentX509SVID(ctx context.Context, params AgentX509SVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignAgentX509SVID(ctx context.Context, params AgentX509SVIDParams) (_ []*x509.Certificate, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "ca.SignAgentX509SVID", attribute.String("spiffe_id", params.SPIFFEID.String()))
+	defer telemetry.EndSpan(span, &err)
+
 	x509CA, caChain, err := ca.getX509CA()
 	if err != nil {
 		return nil, err
@@ -286,7 +305,10 @@ func (ca *CA) SignAgentX509SVID(ctx context.Context, params AgentX509SVIDParams)
 	return svidChain, nil
 }
 
-func (ca *CA) SignWorkloadX509SVID(ctx context.Context, params WorkloadX509SVIDParams) ([]*x509.Certificate, error) {
+func (ca *CA) SignWorkloadX509SVID(ctx context.Context, params WorkloadX509SVIDParams) (_ []*x509.Certificate, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "ca.SignWorkloadX509SVID", attribute.String("spiffe_id", params.SPIFFEID.String()))
+	defer telemetry.EndSpan(span, &err)
+
 	x509CA, caChain, err := ca.getX509CA()
 	if err != nil {
 		return nil, err
@@ -299,6 +321,7 @@ func (ca *CA) SignWorkloadX509SVID(ctx context.Context, params WorkloadX509SVIDP
 		DNSNames:    params.DNSNames,
 		TTL:         params.TTL,
 		Subject:     params.Subject,
+		Workload:    params.Workload,
 	})
 	if err != nil {
 		return nil, err
@@ -316,7 +339,10 @@ func (ca *CA) SignWorkloadX509SVID(ctx context.Context, params WorkloadX509SVIDP
 	return svidChain, nil
 }
 
-func (ca *CA) SignWorkloadJWTSVID(ctx context.Context, params WorkloadJWTSVIDParams) (string, error) {
+func (ca *CA) SignWorkloadJWTSVID(ctx context.Context, params WorkloadJWTSVIDParams) (_ string, err error) {
+	ctx, span := telemetry.StartSpan(ctx, "ca.SignWorkloadJWTSVID", attribute.String("spiffe_id", params.SPIFFEID.String()))
+	defer telemetry.EndSpan(span, &err)
+
 	jwtKey := ca.JWTKey()
 	if jwtKey == nil {
 		return "", errors.New("JWT key is not available for signing")
@@ -327,6 +353,7 @@ func (ca *CA) SignWorkloadJWTSVID(ctx context.Context, params WorkloadJWTSVIDPar
 		Audience:      params.Audience,
 		TTL:           params.TTL,
 		ExpirationCap: jwtKey.NotAfter,
+		Workload:      params.Workload,
 	})
 	if err != nil {
 		return "", err
@@ -99,6 +99,11 @@ type Manager struct {
 	nextX509CA    *x509CASlot
 	x509CAMutex   sync.RWMutex
 
+	// previousX509CA is the X509 CA that was active before the last
+	// rotation. Its key is kept in the KeyManager until the next X509 CA is
+	// prepared in its slot.
+	previousX509CA *ca.X509CA
+
 	currentJWTKey *jwtKeySlot
 	nextJWTKey    *jwtKeySlot
 	jwtKeyMutex   sync.RWMutex
@@ -218,6 +223,22 @@ func (m *Manager) GetNextX509CASlot() Slot {
 	return m.nextX509CA
 }
 
+// X509Authorities returns the X509 CAs whose keys are held by the
+// KeyManager, including the one loaded from the journal or rotated out that is
+// no longer used to sign, until its key is replaced.
+func (m *Manager) X509Authorities() []*ca.X509CA {
+	m.x509CAMutex.RLock()
+	defer m.x509CAMutex.RUnlock()
+
+	var x509CAs []*ca.X509CA
+	for _, x509CA := range []*ca.X509CA{m.currentX509CA.x509CA, m.nextX509CA.x509CA, m.previousX509CA} {
+		if x509CA != nil {
+			x509CAs = append(x509CAs, x509CA)
+		}
+	}
+	return x509CAs
+}
+
 func (m *Manager) PrepareX509CA(ctx context.Context) (err error) {
 	counter := telemetry_server.StartServerCAManagerPrepareX509CACall(m.c.Metrics)
 	defer counter.Done(&err)
@@ -237,6 +258,9 @@ func (m *Manager) PrepareX509CA(ctx context.Context) (err error) {
 	log.Debug("Preparing X509 CA")
 
 	slot.Reset()
+	// The key of the previous X509 CA, if any, lives in this slot and is
+	// about to be replaced.
+	m.previousX509CA = nil
 
 	now := m.c.Clock.Now()
 	km := m.c.Catalog.GetKeyManager()
@@ -298,6 +322,7 @@ func (m *Manager) RotateX509CA(ctx context.Context) {
 	m.x509CAMutex.Lock()
 	defer m.x509CAMutex.Unlock()
 
+	m.previousX509CA = m.currentX509CA.x509CA
 	m.currentX509CA, m.nextX509CA = m.nextX509CA, m.currentX509CA
 	m.nextX509CA.Reset()
 	if err := m.journal.UpdateX509CAStatus(ctx, m.nextX509CA.AuthorityID(), journal.Status_OLD); err != nil {
@@ -482,6 +507,17 @@ func (m *Manager) PruneCAJournals(ctx context.Context) (err error) {
 	return nil
 }
 
+// PruneRevokedX509SVIDs removes the revoked X509-SVIDs that can no longer
+// be presented because every X.509 authority that could have issued them
+// has expired.
+func (m *Manager) PruneRevokedX509SVIDs(ctx context.Context) error {
+	ds := m.c.Catalog.GetDataStore()
+	if err := ds.PruneRevokedX509SVIDs(ctx, m.c.Clock.Now()); err != nil {
+		return fmt.Errorf("unable to prune revoked X509-SVIDs: %w", err)
+	}
+	return nil
+}
+
 // ProcessBundleUpdates Notify any bundle update, or process tainted authorities
 func (m *Manager) ProcessBundleUpdates(ctx context.Context) {
 	for {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -694,6 +695,28 @@ func TestX509CARotation(t *testing.T) {
 	require.Equal(t, journal.Status_OLD, test.nextX509CAStatus())
 }
 
+func TestX509Authorities(t *testing.T) {
+	ctx := context.Background()
+
+	test := setupTest(t)
+	test.initAndActivateSelfSignedManager(ctx)
+	first := test.currentX509CA()
+	assert.Equal(t, []*ca.X509CA{first}, test.m.X509Authorities())
+
+	require.NoError(t, test.m.PrepareX509CA(ctx))
+	second := test.nextX509CA()
+	assert.Equal(t, []*ca.X509CA{first, second}, test.m.X509Authorities())
+
+	// The previous X509CA is returned after the rotation, until its key is
+	// replaced when the next X509CA is prepared
+	test.m.RotateX509CA(ctx)
+	assert.Equal(t, []*ca.X509CA{second, first}, test.m.X509Authorities())
+
+	require.NoError(t, test.m.PrepareX509CA(ctx))
+	third := test.nextX509CA()
+	assert.Equal(t, []*ca.X509CA{second, third}, test.m.X509Authorities())
+}
+
 func TestX509CARotationMetric(t *testing.T) {
 	ctx := context.Background()
 	test := setupTest(t)
@@ -958,13 +981,37 @@ func TestPruneCAJournals(t *testing.T) {
 			}
 
 			require.NoError(t, test.m.PruneCAJournals(ctx))
-			caJournals, err := test.ds.ListCAJournalsForTesting(ctx)
+			caJournals, err := test.ds.ListCAJournals(ctx)
 			require.NoError(t, err)
 			require.ElementsMatch(t, expectedCAJournals, caJournals)
 		})
 	}
 }
 
+func TestPruneRevokedX509SVIDs(t *testing.T) {
+	ctx := context.Background()
+	test := setupTest(t)
+	test.initSelfSignedManager()
+
+	now := test.clock.Now()
+	expired := &datastore.RevokedX509SVID{AuthorityID: "authority", SerialNumber: "1a", RevokedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	valid := &datastore.RevokedX509SVID{AuthorityID: "authority", SerialNumber: "2b", RevokedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)}
+	for _, revoked := range []*datastore.RevokedX509SVID{expired, valid} {
+		_, err := test.ds.RevokeX509SVID(ctx, revoked)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, test.m.PruneRevokedX509SVIDs(ctx))
+
+	revoked, err := test.ds.ListRevokedX509SVIDs(ctx)
+	require.NoError(t, err)
+	require.Len(t, revoked, 1)
+	require.Equal(t, "2b", revoked[0].SerialNumber)
+
+	test.ds.SetNextError(errors.New("oh no"))
+	require.EqualError(t, test.m.PruneRevokedX509SVIDs(ctx), "unable to prune revoked X509-SVIDs: oh no")
+}
+
 func TestRunNotifiesBundleLoaded(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
@@ -1063,6 +1110,11 @@ func TestAlternateKeyTypes(t *testing.T) {
 		expectEC(t, signer, 384)
 	}
 
+	expectEd25519 := func(t *testing.T, signer crypto.Signer) {
+		_, ok := signer.Public().(ed25519.PublicKey)
+		assert.True(t, ok, "Signer is not Ed25519")
+	}
+
 	testCases := []struct {
 		name              string
 		upstreamAuthority bool
@@ -1106,6 +1158,13 @@ func TestAlternateKeyTypes(t *testing.T) {
 			checkX509CA:   expectEC384,
 			checkJWTKey:   expectRSA2048,
 		},
+		{
+			name:          "self-signed with Ed25519",
+			x509CAKeyType: keymanager.Ed25519,
+			jwtKeyType:    keymanager.Ed25519,
+			checkX509CA:   expectEd25519,
+			checkJWTKey:   expectEd25519,
+		},
 		{
 			name:              "upstream-signed with RSA 2048",
 			upstreamAuthority: true,
@@ -1138,6 +1197,14 @@ func TestAlternateKeyTypes(t *testing.T) {
 			checkX509CA:       expectEC384,
 			checkJWTKey:       expectEC384,
 		},
+		{
+			name:              "upstream-signed with Ed25519",
+			upstreamAuthority: true,
+			x509CAKeyType:     keymanager.Ed25519,
+			jwtKeyType:        keymanager.Ed25519,
+			checkX509CA:       expectEd25519,
+			checkJWTKey:       expectEd25519,
+		},
 	}
 
 	for _, testCase := range testCases {
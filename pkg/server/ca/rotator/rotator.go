@@ -14,9 +14,10 @@ import (
 )
 
 const (
-	rotateInterval          = 10 * time.Second
-	pruneBundleInterval     = 6 * time.Hour
-	pruneCAJournalsInterval = 8 * time.Hour
+	rotateInterval                = 10 * time.Second
+	pruneBundleInterval           = 6 * time.Hour
+	pruneCAJournalsInterval       = 8 * time.Hour
+	pruneRevokedX509SVIDsInterval = 6 * time.Hour
 )
 
 type CAManager interface {
@@ -39,6 +40,7 @@ type CAManager interface {
 
 	PruneBundle(ctx context.Context) error
 	PruneCAJournals(ctx context.Context) error
+	PruneRevokedX509SVIDs(ctx context.Context) error
 }
 
 type Config struct {
@@ -87,6 +89,9 @@ func (r *Rotator) Run(ctx context.Context) error {
 		func(ctx context.Context) error {
 			return r.pruneCAJournalsEvery(ctx, pruneCAJournalsInterval)
 		},
+		func(ctx context.Context) error {
+			return r.pruneRevokedX509SVIDsEvery(ctx, pruneRevokedX509SVIDsInterval)
+		},
 		func(ctx context.Context) error {
 			// notifyOnBundleUpdate does not fail but rather logs any errors
 			// encountered while notifying
@@ -226,6 +231,22 @@ func (r *Rotator) pruneCAJournalsEvery(ctx context.Context, interval time.Durati
 	}
 }
 
+func (r *Rotator) pruneRevokedX509SVIDsEvery(ctx context.Context, interval time.Duration) error {
+	ticker := r.c.Clock.Ticker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.c.Manager.PruneRevokedX509SVIDs(ctx); err != nil {
+				r.c.Log.WithError(err).Error("Could not prune revoked X509-SVIDs")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
 func (r *Rotator) failedRotationResult() uint64 {
 	return atomic.LoadUint64(&r.failedRotationNum)
 }
@@ -340,6 +340,26 @@ func TestPruneCAJournals(t *testing.T) {
 	require.True(t, test.fakeCAManager.pruneCAJournalsWasCalled)
 }
 
+func TestPruneRevokedX509SVIDs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	test := setupTest()
+
+	go func() {
+		err := test.rotator.Run(ctx)
+		assert.NoError(t, err)
+	}()
+
+	test.clock.Add(time.Minute + time.Second)
+	require.False(t, test.fakeCAManager.pruneRevokedX509SVIDsWasCalled)
+
+	// Prune revoked X509-SVIDs was called successfully
+	test.clock.Add(pruneRevokedX509SVIDsInterval)
+	test.fakeCAManager.waitPruneRevokedX509SVIDsCalled(ctx, t)
+
+	require.True(t, test.fakeCAManager.pruneRevokedX509SVIDsWasCalled)
+}
+
 type rotationTest struct {
 	rotator *Rotator
 
@@ -359,6 +379,8 @@ func setupTest() *rotationTest {
 		jwtKeyCh:          make(chan struct{}, 1),
 		pruneBundleCh:     make(chan struct{}, 1),
 		pruneCAJournalsCh: make(chan struct{}, 1),
+
+		pruneRevokedX509SVIDsCh: make(chan struct{}, 1),
 	}
 	fakeHealthChecker := fakehealthchecker.New()
 
@@ -404,6 +426,9 @@ type fakeCAManager struct {
 	pruneBundleCh            chan struct{}
 	pruneCAJournalsCh        chan struct{}
 	pruneCAJournalsWasCalled bool
+
+	pruneRevokedX509SVIDsCh        chan struct{}
+	pruneRevokedX509SVIDsWasCalled bool
 }
 
 func (f *fakeCAManager) NotifyBundleLoaded(context.Context) error {
@@ -524,6 +549,15 @@ func (f *fakeCAManager) PruneCAJournals(context.Context) error {
 	return nil
 }
 
+func (f *fakeCAManager) PruneRevokedX509SVIDs(context.Context) error {
+	defer func() {
+		f.pruneRevokedX509SVIDsCh <- struct{}{}
+	}()
+	f.pruneRevokedX509SVIDsWasCalled = true
+
+	return nil
+}
+
 func (f *fakeCAManager) cleanX509CACh() {
 	select {
 	case <-f.x509CACh:
@@ -570,6 +604,14 @@ func (f *fakeCAManager) waitPruneCAJournalsCalled(ctx context.Context, t *testin
 	}
 }
 
+func (f *fakeCAManager) waitPruneRevokedX509SVIDsCalled(ctx context.Context, t *testing.T) {
+	select {
+	case <-ctx.Done():
+		assert.Fail(t, "context finished")
+	case <-f.pruneRevokedX509SVIDsCh:
+	}
+}
+
 type fakeSlot struct {
 	manager.Slot
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -92,6 +93,10 @@ type Agent struct {
 type FullEntryCache struct {
 	aliases map[spiffeID][]aliasEntry
 	entries map[spiffeID][]*types.Entry
+
+	// agentSelectors holds the node selectors of each agent, which template
+	// entries are expanded with.
+	agentSelectors map[spiffeID][]*types.Selector
 }
 
 type selectorSet map[Selector]struct{}
@@ -146,9 +151,11 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	defer freeStringSet(aliasSeen)
 
 	aliases := make(map[spiffeID][]aliasEntry)
+	agentSelectorsByID := make(map[spiffeID][]*types.Selector)
 	for agentIter.Next(ctx) {
 		agent := agentIter.Agent()
 		agentID := spiffeIDFromID(agent.ID)
+		agentSelectorsByID[agentID] = agent.Selectors
 		agentSelectors := selectorSetFromProto(agent.Selectors)
 		// track which aliases we've evaluated so far to make sure we don't
 		// add one twice.
@@ -170,8 +177,9 @@ func Build(ctx context.Context, entryIter EntryIterator, agentIter AgentIterator
 	}
 
 	return &FullEntryCache{
-		aliases: aliases,
-		entries: entries,
+		aliases:        aliases,
+		entries:        entries,
+		agentSelectors: agentSelectorsByID,
 	}, nil
 }
 
@@ -179,8 +187,9 @@ func (c *FullEntryCache) LookupAuthorizedEntries(agentID spiffeid.ID, requestedE
 	seen := allocSeenSet()
 	defer freeSeenSet(seen)
 
+	id := spiffeIDFromID(agentID)
 	foundEntries := make(map[string]*types.Entry)
-	c.lookupAuthorizedEntries(spiffeIDFromID(agentID), foundEntries, requestedEntries, seen)
+	c.lookupAuthorizedEntries(id, c.agentSelectors[id], foundEntries, requestedEntries, seen)
 	return foundEntries
 }
 
@@ -189,19 +198,26 @@ func (c *FullEntryCache) GetAuthorizedEntries(agentID spiffeid.ID) []*types.Entr
 	seen := allocSeenSet()
 	defer freeSeenSet(seen)
 
-	return cloneEntries(c.getAuthorizedEntries(spiffeIDFromID(agentID), seen))
+	id := spiffeIDFromID(agentID)
+	return materializeTemplateEntries(cloneEntries(c.getAuthorizedEntries(id, seen)), c.agentSelectors[id])
 }
 
-func (c *FullEntryCache) lookupAuthorizedEntries(id spiffeID, foundEntries map[string]*types.Entry, requestedEntries map[string]struct{}, seen map[spiffeID]struct{}) {
+func (c *FullEntryCache) lookupAuthorizedEntries(id spiffeID, nodeSelectors []*types.Selector, foundEntries map[string]*types.Entry, requestedEntries map[string]struct{}, seen map[spiffeID]struct{}) {
 	for _, descendant := range c.crawl(id, seen) {
+		if entrytemplate.IsTemplate(descendant.SpiffeId.Path) {
+			if entry, ok := materializeTemplateEntry(descendant, nodeSelectors, requestedEntries); ok {
+				foundEntries[entry.Id] = entry
+			}
+			continue
+		}
 		if _, ok := requestedEntries[descendant.Id]; ok {
 			foundEntries[descendant.Id] = proto.Clone(descendant).(*types.Entry)
 		}
-		c.lookupAuthorizedEntries(spiffeIDFromProto(descendant.SpiffeId), foundEntries, requestedEntries, seen)
+		c.lookupAuthorizedEntries(spiffeIDFromProto(descendant.SpiffeId), nodeSelectors, foundEntries, requestedEntries, seen)
 	}
 
 	for _, alias := range c.aliases[id] {
-		c.lookupAuthorizedEntries(alias.id, foundEntries, requestedEntries, seen)
+		c.lookupAuthorizedEntries(alias.id, nodeSelectors, foundEntries, requestedEntries, seen)
 	}
 }
 
@@ -231,6 +247,43 @@ func (c *FullEntryCache) crawl(parentID spiffeID, seen map[spiffeID]struct{}) []
 	return entries
 }
 
+// materializeTemplateEntries replaces the template entries with the entries
+// materialized from them for the agent. Template entries that do not expand
+// for the agent are dropped.
+func materializeTemplateEntries(entries []*types.Entry, nodeSelectors []*types.Selector) []*types.Entry {
+	materialized := entries[:0]
+	for _, entry := range entries {
+		if entrytemplate.IsTemplate(entry.SpiffeId.Path) {
+			id, ok := entrytemplate.ExpandEntry(entry, nodeSelectors)
+			if !ok {
+				continue
+			}
+			entry.Id = entrytemplate.MaterializedEntryID(entry.Id, id)
+			entry.SpiffeId = &types.SPIFFEID{TrustDomain: id.TrustDomain().Name(), Path: id.Path()}
+		}
+		materialized = append(materialized, entry)
+	}
+	return materialized
+}
+
+// materializeTemplateEntry returns the entry materialized from the template
+// entry for the agent, if it is one of the requested entries.
+func materializeTemplateEntry(entry *types.Entry, nodeSelectors []*types.Selector, requestedEntries map[string]struct{}) (*types.Entry, bool) {
+	id, ok := entrytemplate.ExpandEntry(entry, nodeSelectors)
+	if !ok {
+		return nil, false
+	}
+	entryID := entrytemplate.MaterializedEntryID(entry.Id, id)
+	if _, ok := requestedEntries[entryID]; !ok {
+		return nil, false
+	}
+
+	materialized := proto.Clone(entry).(*types.Entry)
+	materialized.Id = entryID
+	materialized.SpiffeId = &types.SPIFFEID{TrustDomain: id.TrustDomain().Name(), Path: id.Path()}
+	return materialized, true
+}
+
 func spiffeIDFromID(id spiffeid.ID) spiffeID {
 	return spiffeID{
 		TrustDomain: id.TrustDomain().Name(),
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
@@ -83,7 +84,11 @@ func (it *entryIteratorDS) filterEntries(in []*common.RegistrationEntry) []*comm
 		// Filter out entries with invalid SPIFFE IDs. Operators are notified
 		// that they are ignored on server startup (see
 		// pkg/server/scanentries.go)
-		if _, err := spiffeid.FromString(entry.SpiffeId); err != nil {
+		if entrytemplate.IsTemplate(entry.SpiffeId) {
+			if _, err := entrytemplate.Parse(entry.SpiffeId); err != nil {
+				continue
+			}
+		} else if _, err := spiffeid.FromString(entry.SpiffeId); err != nil {
 			continue
 		}
 		if _, err := spiffeid.FromString(entry.ParentId); err != nil {
@@ -15,6 +15,7 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/entrytemplate"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	sqlds "github.com/spiffe/spire/pkg/server/datastore/sqlstore"
@@ -23,6 +24,7 @@ import (
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -237,6 +239,61 @@ func TestFullCacheNodeAliasing(t *testing.T) {
 	assertAuthorizedEntries(t, cache, agentIDs[2], workloadEntries, workloadEntries[2])
 }
 
+func TestFullCacheTemplateEntries(t *testing.T) {
+	agent1 := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/1")
+	agent2 := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/2")
+	agent3 := spiffeid.RequireFromString("spiffe://domain.test/spire/agent/3")
+	alias := spiffeid.RequireFromString("spiffe://domain.test/alias")
+
+	aliasEntry := &types.Entry{
+		Id:        "alias",
+		ParentId:  api.ProtoFromID(spiffeid.RequireFromString("spiffe://domain.test/spire/server")),
+		SpiffeId:  api.ProtoFromID(alias),
+		Selectors: []*types.Selector{{Type: "s", Value: "1"}},
+	}
+	templateEntry := &types.Entry{
+		Id:        "template",
+		ParentId:  api.ProtoFromID(alias),
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "domain.test", Path: "/cluster/{{node:cluster}}/ns/{{k8s:ns}}"},
+		Selectors: []*types.Selector{{Type: "k8s", Value: "ns:default"}},
+	}
+
+	cache, err := Build(context.Background(), makeEntryIterator([]*types.Entry{aliasEntry, templateEntry}), makeAgentIterator([]Agent{
+		{ID: agent1, Selectors: []*types.Selector{{Type: "s", Value: "1"}, {Type: "node", Value: "cluster:prod"}}},
+		{ID: agent2, Selectors: []*types.Selector{{Type: "s", Value: "1"}, {Type: "node", Value: "cluster:dev"}}},
+		{ID: agent3, Selectors: []*types.Selector{{Type: "s", Value: "1"}}},
+	}))
+	require.NoError(t, err)
+
+	materialize := func(id spiffeid.ID) *types.Entry {
+		entry := proto.Clone(templateEntry).(*types.Entry)
+		entry.Id = entrytemplate.MaterializedEntryID(templateEntry.Id, id)
+		entry.SpiffeId = api.ProtoFromID(id)
+		return entry
+	}
+	prodEntry := materialize(spiffeid.RequireFromString("spiffe://domain.test/cluster/prod/ns/default"))
+	devEntry := materialize(spiffeid.RequireFromString("spiffe://domain.test/cluster/dev/ns/default"))
+
+	// The template is expanded with the node selectors of each agent
+	spiretest.AssertProtoListEqual(t, []*types.Entry{prodEntry}, cache.GetAuthorizedEntries(agent1))
+	spiretest.AssertProtoListEqual(t, []*types.Entry{devEntry}, cache.GetAuthorizedEntries(agent2))
+	assert.Empty(t, cache.GetAuthorizedEntries(agent3))
+
+	// Only the expansion for the agent can be looked up
+	requested := map[string]struct{}{
+		templateEntry.Id: {},
+		prodEntry.Id:     {},
+		devEntry.Id:      {},
+	}
+	found := cache.LookupAuthorizedEntries(agent1, requested)
+	require.Len(t, found, 1)
+	spiretest.AssertProtoEqual(t, prodEntry, found[prodEntry.Id])
+	found = cache.LookupAuthorizedEntries(agent2, requested)
+	require.Len(t, found, 1)
+	spiretest.AssertProtoEqual(t, devEntry, found[devEntry.Id])
+	assert.Empty(t, cache.LookupAuthorizedEntries(agent3, requested))
+}
+
 func TestFullCacheExcludesNodeSelectorMappedEntriesForExpiredAgents(t *testing.T) {
 	// This test verifies that the cache contains no workloads parented to alias entries
 	// that are only associated with an expired agent.
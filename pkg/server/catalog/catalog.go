@@ -21,6 +21,7 @@ import (
 	km_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/keymanager"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/datastore/raftstore"
 	ds_sql "github.com/spiffe/spire/pkg/server/datastore/sqlstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/identityprovider"
@@ -146,11 +147,11 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	// Strip out the Datastore plugin configuration and load the SQL plugin
 	// directly. This allows us to bypass gRPC and get rid of response limits.
 	dataStoreConfigs, pluginConfigs := config.PluginConfigs.FilterByType(dataStoreType)
-	sqlDataStore, err := loadSQLDataStore(ctx, config, coreConfig, dataStoreConfigs)
+	builtInDataStore, err := loadSQLDataStore(ctx, config, coreConfig, dataStoreConfigs)
 	if err != nil {
 		return nil, err
 	}
-	repo.dsCloser = sqlDataStore
+	repo.dsCloser = builtInDataStore
 
 	repo.catalog, err = catalog.Load(ctx, catalog.Config{
 		Log:           config.Log,
@@ -166,7 +167,7 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 		return nil, err
 	}
 
-	var dataStore datastore.DataStore = sqlDataStore
+	var dataStore datastore.DataStore = builtInDataStore
 	_ = config.HealthChecker.AddCheck("catalog.datastore", &datastore.Health{
 		DataStore: dataStore,
 	})
@@ -180,7 +181,30 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	return repo, nil
 }
 
-func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.CoreConfig, datastoreConfigs catalog.PluginConfigs) (*ds_sql.Plugin, error) {
+// DataStore is a DataStore that must be closed when no longer in use.
+type DataStore interface {
+	datastore.DataStore
+	io.Closer
+}
+
+// LoadDataStore loads only the DataStore from the plugin configurations. It
+// is used by tooling that operates on the DataStore while the server is not
+// running.
+func LoadDataStore(ctx context.Context, config Config) (DataStore, error) {
+	dataStoreConfigs, _ := config.PluginConfigs.FilterByType(dataStoreType)
+	return loadSQLDataStore(ctx, config, catalog.CoreConfig{
+		TrustDomain: config.TrustDomain,
+	}, dataStoreConfigs)
+}
+
+// builtInDataStore is implemented by the built-in DataStore implementations.
+type builtInDataStore interface {
+	datastore.DataStore
+	io.Closer
+	Configure(ctx context.Context, hclConfiguration string) error
+}
+
+func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.CoreConfig, datastoreConfigs catalog.PluginConfigs) (builtInDataStore, error) {
 	switch {
 	case len(datastoreConfigs) == 0:
 		return nil, errors.New("expecting a DataStore plugin")
@@ -200,8 +224,20 @@ func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.Cor
 		sqlConfig.DataSource = catalog.FixedData("")
 	}
 
+	data, err := sqlConfig.DataSource.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DataStore configuration: %w", err)
+	}
+
+	// The raft datastore is configured through the SQL plugin by setting the
+	// database type to "raft".
 	dsLog := config.Log.WithField(telemetry.SubsystemName, sqlConfig.Name)
-	ds := ds_sql.New(dsLog)
+	var ds builtInDataStore
+	if raftstore.IsRaftConfiguration(data) {
+		ds = raftstore.New(dsLog)
+	} else {
+		ds = ds_sql.New(dsLog)
+	}
 	configurer := catalog.ConfigurerFunc(func(ctx context.Context, _ catalog.CoreConfig, configuration string) error {
 		return ds.Configure(ctx, configuration)
 	})
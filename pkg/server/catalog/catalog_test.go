@@ -2,6 +2,7 @@ package catalog_test
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
 	"path/filepath"
 	"testing"
@@ -9,7 +10,9 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	commoncatalog "github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +90,37 @@ func Test(t *testing.T) {
 	}
 }
 
+func TestBuiltInKeyManagerGeneratesEd25519Keys(t *testing.T) {
+	dir := t.TempDir()
+	log, _ := test.NewNullLogger()
+
+	repo, err := catalog.Load(context.Background(), catalog.Config{
+		Log:           log,
+		Metrics:       telemetry.Blackhole{},
+		HealthChecker: fakeHealthChecker{},
+		PluginConfigs: catalog.PluginConfigs{
+			{
+				Type: "DataStore",
+				Name: "sql",
+				DataSource: commoncatalog.FixedData(fmt.Sprintf(`
+				database_type = "sqlite3"
+				connection_string = %q
+			`, filepath.Join(dir, "test.sql"))),
+			},
+			{
+				Type: "KeyManager",
+				Name: "memory",
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	key, err := repo.GetKeyManager().GenerateKey(context.Background(), "foo", keymanager.Ed25519)
+	require.NoError(t, err)
+	require.IsType(t, ed25519.PublicKey{}, key.Public())
+}
+
 type fakeHealthChecker struct{}
 
 func (fakeHealthChecker) AddCheck(string, health.Checkable) error { return nil }
@@ -13,10 +13,24 @@ import (
 
 type keyManagerRepository struct {
 	keymanager.Repository
+
+	// ed25519KeyGenerators are the built-in KeyManagers that generate
+	// Ed25519 keys, by plugin name.
+	ed25519KeyGenerators map[string]keymanager.Ed25519KeyGenerator
 }
 
 func (repo *keyManagerRepository) Binder() any {
-	return repo.SetKeyManager
+	return repo.bindKeyManager
+}
+
+func (repo *keyManagerRepository) bindKeyManager(keyManager keymanager.KeyManager) {
+	// The KeyManager plugin interface does not define a key type for Ed25519
+	// keys, so the built-in KeyManagers generate them in process. The server
+	// refuses to start with the Ed25519 key type and an external KeyManager.
+	if generator, ok := repo.ed25519KeyGenerators[keyManager.Name()]; ok {
+		keyManager = keymanager.WithEd25519Keys(keyManager, generator)
+	}
+	repo.SetKeyManager(keyManager)
 }
 
 func (repo *keyManagerRepository) Constraints() catalog.Constraints {
@@ -28,12 +42,19 @@ func (repo *keyManagerRepository) Versions() []catalog.Version {
 }
 
 func (repo *keyManagerRepository) BuiltIns() []catalog.BuiltIn {
+	diskKeyManager := disk.New(nil)
+	memoryKeyManager := memory.New(nil)
+	repo.ed25519KeyGenerators = map[string]keymanager.Ed25519KeyGenerator{
+		"disk":   diskKeyManager,
+		"memory": memoryKeyManager,
+	}
+
 	return []catalog.BuiltIn{
 		awskms.BuiltIn(),
-		disk.BuiltIn(),
+		disk.AsBuiltIn(diskKeyManager),
 		gcpkms.BuiltIn(),
 		azurekeyvault.BuiltIn(),
-		memory.BuiltIn(),
+		memory.AsBuiltIn(memoryKeyManager),
 	}
 }
 
@@ -16,6 +16,7 @@ import (
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
 	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
+	"github.com/spiffe/spire/pkg/server/k8ssigner"
 	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 )
 
@@ -124,11 +125,34 @@ type Config struct {
 
 	// TLSPolicy determines the policy settings to apply to all TLS connections.
 	TLSPolicy tlspolicy.Policy
+
+	// KubernetesSigner, if set, enables signing Kubernetes
+	// CertificateSigningRequests and cert-manager CertificateRequests with
+	// X509-SVIDs.
+	KubernetesSigner *KubernetesSignerConfig
 }
 
 type ExperimentalConfig struct {
 }
 
+type KubernetesSignerConfig struct {
+	// KubeConfigFilePath is the path to the kubeconfig used to reach the
+	// cluster. The in-cluster configuration is used if empty.
+	KubeConfigFilePath string
+
+	// ResyncInterval is the interval to list all the requests again, in
+	// addition to watching them.
+	ResyncInterval time.Duration
+
+	// SignerName is the signer name of the CertificateSigningRequests signed
+	// by the server.
+	SignerName string
+
+	// CertManagerIssuer is the issuer of the cert-manager CertificateRequests
+	// signed by the server.
+	CertManagerIssuer *k8ssigner.IssuerRef
+}
+
 type FederationConfig struct {
 	// BundleEndpoint contains the federation bundle endpoint configuration.
 	BundleEndpoint *bundle.EndpointConfig
@@ -3,6 +3,7 @@ package credtemplate
 import (
 	"context"
 	"crypto"
+	"crypto/ed25519"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
@@ -90,6 +91,10 @@ type WorkloadX509SVIDParams struct {
 	DNSNames    []string
 	TTL         time.Duration
 	Subject     pkix.Name
+
+	// Workload describes what the SVID is minted for. It is passed to the
+	// credential composers.
+	Workload credentialcomposer.WorkloadContext
 }
 
 type WorkloadJWTSVIDParams struct {
@@ -97,6 +102,10 @@ type WorkloadJWTSVIDParams struct {
 	Audience      []string
 	TTL           time.Duration
 	ExpirationCap time.Time
+
+	// Workload describes what the SVID is minted for. It is passed to the
+	// credential composers.
+	Workload credentialcomposer.WorkloadContext
 }
 
 type Config struct {
@@ -113,6 +122,11 @@ type Config struct {
 	NewSerialNumber              func() (*big.Int, error)
 	UseLegacyDownstreamX509CATTL bool
 	TLSPolicy                    tlspolicy.Policy
+
+	// X509SVIDRevocationURL, if set, is the base URL of the endpoint that
+	// publishes the revocation status of X509-SVIDs. The CRL distribution
+	// point and OCSP server of each X509-SVID are derived from it.
+	X509SVIDRevocationURL string
 }
 
 type Builder struct {
@@ -207,10 +221,11 @@ func (b *Builder) BuildUpstreamSignedX509CACSR(ctx context.Context, params Upstr
 	// TODO: maybe revisit this if needed and embed the policy identifiers in
 	// the extra extensions.
 	return &x509.CertificateRequest{
-		Subject:         tmpl.Subject,
-		ExtraExtensions: tmpl.ExtraExtensions,
-		URIs:            tmpl.URIs,
-		PublicKey:       tmpl.PublicKey,
+		SignatureAlgorithm: tmpl.SignatureAlgorithm,
+		Subject:            tmpl.Subject,
+		ExtraExtensions:    tmpl.ExtraExtensions,
+		URIs:               tmpl.URIs,
+		PublicKey:          tmpl.PublicKey,
 	}, nil
 }
 
@@ -298,7 +313,7 @@ func (b *Builder) BuildWorkloadX509SVIDTemplate(ctx context.Context, params Work
 	}
 
 	for _, cc := range b.config.CredentialComposers {
-		attributes, err := cc.ComposeWorkloadX509SVID(ctx, params.SPIFFEID, params.PublicKey, x509SVIDAttributesFromTemplate(tmpl))
+		attributes, err := cc.ComposeWorkloadX509SVID(ctx, params.SPIFFEID, params.PublicKey, params.Workload, x509SVIDAttributesFromTemplate(tmpl))
 		if err != nil {
 			return nil, err
 		}
@@ -343,7 +358,7 @@ func (b *Builder) BuildWorkloadJWTSVIDClaims(ctx context.Context, params Workloa
 
 	for _, cc := range b.config.CredentialComposers {
 		var err error
-		attributes, err = cc.ComposeWorkloadJWTSVID(ctx, params.SPIFFEID, attributes)
+		attributes, err = cc.ComposeWorkloadJWTSVID(ctx, params.SPIFFEID, params.Workload, attributes)
 		if err != nil {
 			return nil, err
 		}
@@ -403,14 +418,20 @@ func (b *Builder) buildX509SVIDTemplate(spiffeID spiffeid.ID, publicKey crypto.P
 	}
 
 	tmpl.NotBefore, tmpl.NotAfter = b.computeX509SVIDLifetime(parentChain, ttl)
-	tmpl.KeyUsage = x509.KeyUsageKeyEncipherment |
-		x509.KeyUsageKeyAgreement |
-		x509.KeyUsageDigitalSignature
+	tmpl.KeyUsage = x509SVIDKeyUsage(publicKey)
 	tmpl.ExtKeyUsage = []x509.ExtKeyUsage{
 		x509.ExtKeyUsageServerAuth,
 		x509.ExtKeyUsageClientAuth,
 	}
 
+	if b.config.X509SVIDRevocationURL != "" {
+		// CRLs are published per X.509 authority, identified by the
+		// subject key ID of the certificate that signs the X509-SVID.
+		authorityID := x509util.SubjectKeyIDToString(parentChain[0].SubjectKeyId)
+		tmpl.CRLDistributionPoints = []string{b.config.X509SVIDRevocationURL + "/crl/" + authorityID}
+		tmpl.OCSPServer = []string{b.config.X509SVIDRevocationURL + "/ocsp"}
+	}
+
 	return tmpl, nil
 }
 
@@ -432,7 +453,15 @@ func (b *Builder) buildBaseTemplate(spiffeID spiffeid.ID, publicKey crypto.Publi
 		authorityKeyID = parentChain[0].SubjectKeyId
 	}
 
+	// The certificate is signed by the parent, or is self-signed if there is
+	// no parent.
+	signerKey := publicKey
+	if len(parentChain) > 0 {
+		signerKey = parentChain[0].PublicKey
+	}
+
 	return &x509.Certificate{
+		SignatureAlgorithm:    signatureAlgorithm(signerKey),
 		SerialNumber:          serialNumber,
 		URIs:                  []*url.URL{spiffeID.URL()},
 		SubjectKeyId:          subjectKeyID,
@@ -456,6 +485,30 @@ func (b *Builder) computeX509SVIDLifetime(parentChain []*x509.Certificate, ttl t
 	return computeCappedLifetime(b.config.Clock, ttl, parentChainExpiration(parentChain))
 }
 
+// x509SVIDKeyUsage returns the key usage for an X509-SVID with the given
+// public key. Ed25519 keys (RFC 8410) can only be used for signatures.
+func x509SVIDKeyUsage(publicKey crypto.PublicKey) x509.KeyUsage {
+	switch publicKey.(type) {
+	case ed25519.PublicKey:
+		return x509.KeyUsageDigitalSignature
+	default:
+		return x509.KeyUsageKeyEncipherment |
+			x509.KeyUsageKeyAgreement |
+			x509.KeyUsageDigitalSignature
+	}
+}
+
+// signatureAlgorithm returns the signature algorithm for certificates signed
+// by the given key. Ed25519 keys have a single signature algorithm. For other
+// keys, x509.UnknownSignatureAlgorithm is returned so that the x509 package
+// selects the default for the key.
+func signatureAlgorithm(signerKey crypto.PublicKey) x509.SignatureAlgorithm {
+	if _, ok := signerKey.(ed25519.PublicKey); ok {
+		return x509.PureEd25519
+	}
+	return x509.UnknownSignatureAlgorithm
+}
+
 func x509CAAttributesFromTemplate(tmpl *x509.Certificate) credentialcomposer.X509CAAttributes {
 	return credentialcomposer.X509CAAttributes{
 		Subject:           tmpl.Subject,
@@ -508,6 +508,16 @@ func TestBuildServerX509SVIDTemplate(t *testing.T) {
 		{
 			desc: "defaults",
 		},
+		{
+			desc: "with revocation URL",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.X509SVIDRevocationURL = "https://spire.domain.test:8443"
+			},
+			overrideExpected: func(expected *x509.Certificate) {
+				expected.CRLDistributionPoints = []string{"https://spire.domain.test:8443/crl/" + x509util.SubjectKeyIDToString(parentKeyID)}
+				expected.OCSPServer = []string{"https://spire.domain.test:8443/ocsp"}
+			},
+		},
 		{
 			desc: "fail to get serial number",
 			overrideConfig: func(config *credtemplate.Config) {
@@ -647,6 +657,16 @@ func TestBuildAgentX509SVIDTemplate(t *testing.T) {
 		{
 			desc: "defaults",
 		},
+		{
+			desc: "with revocation URL",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.X509SVIDRevocationURL = "https://spire.domain.test:8443"
+			},
+			overrideExpected: func(expected *x509.Certificate) {
+				expected.CRLDistributionPoints = []string{"https://spire.domain.test:8443/crl/" + x509util.SubjectKeyIDToString(parentKeyID)}
+				expected.OCSPServer = []string{"https://spire.domain.test:8443/ocsp"}
+			},
+		},
 		{
 			desc: "fail to get serial number",
 			overrideConfig: func(config *credtemplate.Config) {
@@ -811,6 +831,16 @@ func TestBuildWorkloadX509SVIDTemplate(t *testing.T) {
 		{
 			desc: "defaults",
 		},
+		{
+			desc: "with revocation URL",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.X509SVIDRevocationURL = "https://spire.domain.test:8443"
+			},
+			overrideExpected: func(expected *x509.Certificate) {
+				expected.CRLDistributionPoints = []string{"https://spire.domain.test:8443/crl/" + x509util.SubjectKeyIDToString(parentKeyID)}
+				expected.OCSPServer = []string{"https://spire.domain.test:8443/ocsp"}
+			},
+		},
 		{
 			desc: "fail to get serial number",
 			overrideConfig: func(config *credtemplate.Config) {
@@ -1025,6 +1055,60 @@ func TestBuildWorkloadX509SVIDTemplate(t *testing.T) {
 	}
 }
 
+func TestBuildX509TemplatesWithEd25519Keys(t *testing.T) {
+	ed25519Key := testkey.NewEd25519(t).Public()
+
+	testBuilder(t, nil, func(t *testing.T, credBuilder *credtemplate.Builder) {
+		caTemplate, err := credBuilder.BuildSelfSignedX509CATemplate(ctx, credtemplate.SelfSignedX509CAParams{
+			PublicKey: ed25519Key,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, x509.PureEd25519, caTemplate.SignatureAlgorithm)
+		assert.Equal(t, caKeyUsage, caTemplate.KeyUsage)
+
+		csr, err := credBuilder.BuildUpstreamSignedX509CACSR(ctx, credtemplate.UpstreamSignedX509CAParams{
+			PublicKey: ed25519Key,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, x509.PureEd25519, csr.SignatureAlgorithm)
+
+		ed25519ParentChain := []*x509.Certificate{{PublicKey: ed25519Key, NotAfter: parentNotAfter}}
+		for _, tc := range []struct {
+			desc                   string
+			publicKey              crypto.PublicKey
+			parentChain            []*x509.Certificate
+			expectSignatureAlg     x509.SignatureAlgorithm
+			expectX509SVIDKeyUsage x509.KeyUsage
+		}{
+			{
+				desc:                   "EC key signed by Ed25519 parent",
+				publicKey:              publicKey,
+				parentChain:            ed25519ParentChain,
+				expectSignatureAlg:     x509.PureEd25519,
+				expectX509SVIDKeyUsage: svidKeyUsage,
+			},
+			{
+				desc:                   "Ed25519 key signed by EC parent",
+				publicKey:              ed25519Key,
+				parentChain:            parentChain,
+				expectSignatureAlg:     x509.UnknownSignatureAlgorithm,
+				expectX509SVIDKeyUsage: x509.KeyUsageDigitalSignature,
+			},
+		} {
+			t.Run(tc.desc, func(t *testing.T) {
+				template, err := credBuilder.BuildWorkloadX509SVIDTemplate(ctx, credtemplate.WorkloadX509SVIDParams{
+					ParentChain: tc.parentChain,
+					PublicKey:   tc.publicKey,
+					SPIFFEID:    workloadID,
+				})
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectSignatureAlg, template.SignatureAlgorithm)
+				assert.Equal(t, tc.expectX509SVIDKeyUsage, template.KeyUsage)
+			})
+		}
+	})
+}
+
 func TestBuildWorkloadJWTSVIDClaims(t *testing.T) {
 	for _, tc := range []struct {
 		desc             string
@@ -1176,6 +1260,19 @@ func TestBuildWorkloadJWTSVIDClaims(t *testing.T) {
 				expected["i64"] = float64(math.MaxInt64)
 			},
 		},
+		{
+			desc: "workload context passed to composers",
+			overrideConfig: func(config *credtemplate.Config) {
+				config.CredentialComposers = []credentialcomposer.CredentialComposer{fakeCC{id: 1, onlyFoo: true, addEntryID: true}}
+			},
+			overrideParams: func(params *credtemplate.WorkloadJWTSVIDParams) {
+				params.Workload = credentialcomposer.WorkloadContext{EntryID: "ENTRYID"}
+			},
+			overrideExpected: func(expected map[string]any) {
+				expected["foo"] = "VALUE-1"
+				expected["entry_id"] = "ENTRYID"
+			},
+		},
 		{
 			desc: "real grpc composer with second composer",
 			overrideConfig: func(config *credtemplate.Config) {
@@ -1253,11 +1350,11 @@ func (badCC) ComposeAgentX509SVID(context.Context, spiffeid.ID, crypto.PublicKey
 	return credentialcomposer.X509SVIDAttributes{}, errors.New("oh no")
 }
 
-func (badCC) ComposeWorkloadX509SVID(context.Context, spiffeid.ID, crypto.PublicKey, credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
+func (badCC) ComposeWorkloadX509SVID(context.Context, spiffeid.ID, crypto.PublicKey, credentialcomposer.WorkloadContext, credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
 	return credentialcomposer.X509SVIDAttributes{}, errors.New("oh no")
 }
 
-func (badCC) ComposeWorkloadJWTSVID(context.Context, spiffeid.ID, credentialcomposer.JWTSVIDAttributes) (credentialcomposer.JWTSVIDAttributes, error) {
+func (badCC) ComposeWorkloadJWTSVID(context.Context, spiffeid.ID, credentialcomposer.WorkloadContext, credentialcomposer.JWTSVIDAttributes) (credentialcomposer.JWTSVIDAttributes, error) {
 	return credentialcomposer.JWTSVIDAttributes{}, errors.New("oh no")
 }
 
@@ -1268,6 +1365,7 @@ type fakeCC struct {
 	onlyCommonName bool
 	onlyFoo        bool
 	addInt64       bool
+	addEntryID     bool
 }
 
 func (cc fakeCC) ComposeServerX509CA(_ context.Context, attributes credentialcomposer.X509CAAttributes) (credentialcomposer.X509CAAttributes, error) {
@@ -1287,12 +1385,15 @@ func (cc fakeCC) ComposeAgentX509SVID(_ context.Context, _ spiffeid.ID, _ crypto
 	return cc.overrideX509SVIDAttributes(attributes), nil
 }
 
-func (cc fakeCC) ComposeWorkloadX509SVID(_ context.Context, _ spiffeid.ID, _ crypto.PublicKey, attributes credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
+func (cc fakeCC) ComposeWorkloadX509SVID(_ context.Context, _ spiffeid.ID, _ crypto.PublicKey, _ credentialcomposer.WorkloadContext, attributes credentialcomposer.X509SVIDAttributes) (credentialcomposer.X509SVIDAttributes, error) {
 	return cc.overrideX509SVIDAttributes(attributes), nil
 }
 
-func (cc fakeCC) ComposeWorkloadJWTSVID(_ context.Context, _ spiffeid.ID, attributes credentialcomposer.JWTSVIDAttributes) (credentialcomposer.JWTSVIDAttributes, error) {
+func (cc fakeCC) ComposeWorkloadJWTSVID(_ context.Context, _ spiffeid.ID, workload credentialcomposer.WorkloadContext, attributes credentialcomposer.JWTSVIDAttributes) (credentialcomposer.JWTSVIDAttributes, error) {
 	attributes.Claims["foo"] = cc.applySuffix("VALUE")
+	if cc.addEntryID {
+		attributes.Claims["entry_id"] = workload.EntryID
+	}
 	if !cc.onlyFoo {
 		attributes.Claims["bar"] = cc.applySuffix("VALUE")
 	}
@@ -0,0 +1,647 @@
+// Package archive exports the contents of a DataStore to a versioned,
+// dialect-neutral archive, and imports an archive into a DataStore. It is used
+// to back up a deployment and to migrate it between DataStore implementations
+// or SQL dialects.
+//
+// An archive is a stream of JSON objects, one per line. The first object is a
+// header holding the archive version and trust domain. It is followed by the
+// bundles, federation relationships, registration entries, attested nodes,
+// join tokens, CA journals and revoked X509-SVIDs, in that order, so that
+// records are imported after the records they reference.
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Version is the version of the archive format written by Export.
+const Version = 1
+
+const pageSize = 1000
+
+const (
+	kindHeader                 = "header"
+	kindBundle                 = "bundle"
+	kindFederationRelationship = "federation_relationship"
+	kindRegistrationEntry      = "registration_entry"
+	kindAttestedNode           = "attested_node"
+	kindJoinToken              = "join_token"
+	kindCAJournal              = "ca_journal"
+	kindRevokedX509SVID        = "revoked_x509_svid"
+)
+
+// Stats holds the number of records of each kind exported or imported.
+type Stats struct {
+	Bundles                 int
+	FederationRelationships int
+	RegistrationEntries     int
+	AttestedNodes           int
+	JoinTokens              int
+	CAJournals              int
+	RevokedX509SVIDs        int
+}
+
+type record struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type header struct {
+	Version     int       `json:"version"`
+	TrustDomain string    `json:"trust_domain"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type federationRelationship struct {
+	TrustDomain           string `json:"trust_domain"`
+	BundleEndpointURL     string `json:"bundle_endpoint_url"`
+	BundleEndpointProfile string `json:"bundle_endpoint_profile"`
+	EndpointSPIFFEID      string `json:"endpoint_spiffe_id,omitempty"`
+}
+
+type joinToken struct {
+	Token  string `json:"token"`
+	Expiry int64  `json:"expiry"`
+}
+
+type caJournal struct {
+	ActiveX509AuthorityID string `json:"active_x509_authority_id"`
+	Data                  []byte `json:"data"`
+}
+
+type revokedX509SVID struct {
+	AuthorityID  string `json:"authority_id"`
+	SerialNumber string `json:"serial_number"`
+	RevokedAt    int64  `json:"revoked_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// Export writes the contents of the DataStore to w.
+func Export(ctx context.Context, ds datastore.DataStore, td spiffeid.TrustDomain, w io.Writer) (*Stats, error) {
+	bw := bufio.NewWriter(w)
+	e := &exporter{ds: ds, enc: json.NewEncoder(bw)}
+
+	if err := e.write(kindHeader, &header{
+		Version:     Version,
+		TrustDomain: td.Name(),
+		CreatedAt:   time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, export := range []func(context.Context) error{
+		e.exportBundles,
+		e.exportFederationRelationships,
+		e.exportRegistrationEntries,
+		e.exportAttestedNodes,
+		e.exportJoinTokens,
+		e.exportCAJournals,
+		e.exportRevokedX509SVIDs,
+	} {
+		if err := export(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return &e.stats, nil
+}
+
+type exporter struct {
+	ds    datastore.DataStore
+	enc   *json.Encoder
+	stats Stats
+}
+
+func (e *exporter) exportBundles(ctx context.Context) error {
+	req := &datastore.ListBundlesRequest{
+		Pagination: &datastore.Pagination{PageSize: pageSize},
+	}
+	for {
+		resp, err := e.ds.ListBundles(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to list bundles: %w", err)
+		}
+		for _, bundle := range resp.Bundles {
+			if err := e.writeProto(kindBundle, bundle); err != nil {
+				return err
+			}
+			e.stats.Bundles++
+		}
+		if !nextPage(req.Pagination, resp.Pagination, len(resp.Bundles)) {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) exportFederationRelationships(ctx context.Context) error {
+	req := &datastore.ListFederationRelationshipsRequest{
+		Pagination: &datastore.Pagination{PageSize: pageSize},
+	}
+	for {
+		resp, err := e.ds.ListFederationRelationships(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to list federation relationships: %w", err)
+		}
+		for _, fr := range resp.FederationRelationships {
+			// The trust domain bundle is not included since it is exported
+			// along with the rest of the bundles.
+			out := &federationRelationship{
+				TrustDomain:           fr.TrustDomain.Name(),
+				BundleEndpointURL:     fr.BundleEndpointURL.String(),
+				BundleEndpointProfile: string(fr.BundleEndpointProfile),
+			}
+			if !fr.EndpointSPIFFEID.IsZero() {
+				out.EndpointSPIFFEID = fr.EndpointSPIFFEID.String()
+			}
+			if err := e.write(kindFederationRelationship, out); err != nil {
+				return err
+			}
+			e.stats.FederationRelationships++
+		}
+		if !nextPage(req.Pagination, resp.Pagination, len(resp.FederationRelationships)) {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) exportRegistrationEntries(ctx context.Context) error {
+	req := &datastore.ListRegistrationEntriesRequest{
+		Pagination: &datastore.Pagination{PageSize: pageSize},
+	}
+	for {
+		resp, err := e.ds.ListRegistrationEntries(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to list registration entries: %w", err)
+		}
+		for _, entry := range resp.Entries {
+			if err := e.writeProto(kindRegistrationEntry, entry); err != nil {
+				return err
+			}
+			e.stats.RegistrationEntries++
+		}
+		if !nextPage(req.Pagination, resp.Pagination, len(resp.Entries)) {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) exportAttestedNodes(ctx context.Context) error {
+	req := &datastore.ListAttestedNodesRequest{
+		FetchSelectors: true,
+		Pagination:     &datastore.Pagination{PageSize: pageSize},
+	}
+	for {
+		resp, err := e.ds.ListAttestedNodes(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to list attested nodes: %w", err)
+		}
+		for _, node := range resp.Nodes {
+			if err := e.writeProto(kindAttestedNode, node); err != nil {
+				return err
+			}
+			e.stats.AttestedNodes++
+		}
+		if !nextPage(req.Pagination, resp.Pagination, len(resp.Nodes)) {
+			return nil
+		}
+	}
+}
+
+func (e *exporter) exportJoinTokens(ctx context.Context) error {
+	tokens, err := e.ds.ListJoinTokens(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list join tokens: %w", err)
+	}
+	for _, token := range tokens {
+		if err := e.write(kindJoinToken, &joinToken{
+			Token:  token.Token,
+			Expiry: token.Expiry.Unix(),
+		}); err != nil {
+			return err
+		}
+		e.stats.JoinTokens++
+	}
+	return nil
+}
+
+func (e *exporter) exportCAJournals(ctx context.Context) error {
+	caJournals, err := e.ds.ListCAJournals(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list CA journals: %w", err)
+	}
+	for _, caj := range caJournals {
+		if err := e.write(kindCAJournal, &caJournal{
+			ActiveX509AuthorityID: caj.ActiveX509AuthorityID,
+			Data:                  caj.Data,
+		}); err != nil {
+			return err
+		}
+		e.stats.CAJournals++
+	}
+	return nil
+}
+
+func (e *exporter) exportRevokedX509SVIDs(ctx context.Context) error {
+	revoked, err := e.ds.ListRevokedX509SVIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list revoked X509-SVIDs: %w", err)
+	}
+	for _, r := range revoked {
+		if err := e.write(kindRevokedX509SVID, &revokedX509SVID{
+			AuthorityID:  r.AuthorityID,
+			SerialNumber: r.SerialNumber,
+			RevokedAt:    r.RevokedAt.Unix(),
+			ExpiresAt:    r.ExpiresAt.Unix(),
+		}); err != nil {
+			return err
+		}
+		e.stats.RevokedX509SVIDs++
+	}
+	return nil
+}
+
+func (e *exporter) write(kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return e.writeRecord(kind, data)
+}
+
+func (e *exporter) writeProto(kind string, m proto.Message) error {
+	data, err := protojson.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", kind, err)
+	}
+	return e.writeRecord(kind, data)
+}
+
+func (e *exporter) writeRecord(kind string, data []byte) error {
+	if err := e.enc.Encode(&record{Kind: kind, Data: data}); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// nextPage updates the request pagination with the token of the response. It
+// returns false when there are no more pages.
+func nextPage(req, resp *datastore.Pagination, count int) bool {
+	if count == 0 || resp == nil || resp.Token == "" {
+		return false
+	}
+	req.Token = resp.Token
+	return true
+}
+
+// Import reads an archive from r and imports its contents into the DataStore.
+// The archive must have been exported for the same trust domain. Bundles
+// replace the bundles already in the DataStore; any other record that already
+// exists fails the import, so the DataStore is expected to be empty.
+//
+// The whole archive is read, and its records are validated and checked for
+// conflicts with the records in the DataStore, before anything is written. A
+// malformed archive or a conflicting record therefore leaves the DataStore
+// untouched. The records are not written in a single transaction though, so a
+// failure while writing them (e.g. the database becoming unreachable) leaves
+// the records written so far in place.
+func Import(ctx context.Context, ds datastore.DataStore, td spiffeid.TrustDomain, r io.Reader) (*Stats, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var hdr header
+	if err := readRecord(dec, kindHeader, &hdr); err != nil {
+		return nil, err
+	}
+	switch {
+	case hdr.Version != Version:
+		return nil, fmt.Errorf("unsupported archive version %d; expected %d", hdr.Version, Version)
+	case hdr.TrustDomain != td.Name():
+		return nil, fmt.Errorf("archive trust domain %q does not match the server trust domain %q", hdr.TrustDomain, td.Name())
+	}
+
+	im := &importer{ds: ds}
+	for {
+		var rec record
+		err := dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if err := im.readRecord(&rec); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := im.check(ctx); err != nil {
+		return nil, err
+	}
+	if err := im.write(ctx); err != nil {
+		return nil, err
+	}
+	return &im.stats, nil
+}
+
+// importer holds the records read from an archive, by kind, in the order
+// they are written.
+type importer struct {
+	ds    datastore.DataStore
+	stats Stats
+
+	bundles                 []*common.Bundle
+	federationRelationships []*datastore.FederationRelationship
+	entries                 []*common.RegistrationEntry
+	nodes                   []*common.AttestedNode
+	joinTokens              []*datastore.JoinToken
+	caJournals              []*datastore.CAJournal
+	revokedX509SVIDs        []*datastore.RevokedX509SVID
+}
+
+func (im *importer) readRecord(rec *record) error {
+	switch rec.Kind {
+	case kindBundle:
+		bundle := new(common.Bundle)
+		if err := unmarshalProto(rec, bundle); err != nil {
+			return err
+		}
+		im.bundles = append(im.bundles, bundle)
+	case kindFederationRelationship:
+		fr, err := unmarshalFederationRelationship(rec)
+		if err != nil {
+			return err
+		}
+		im.federationRelationships = append(im.federationRelationships, fr)
+	case kindRegistrationEntry:
+		entry := new(common.RegistrationEntry)
+		if err := unmarshalProto(rec, entry); err != nil {
+			return err
+		}
+		im.entries = append(im.entries, entry)
+	case kindAttestedNode:
+		node := new(common.AttestedNode)
+		if err := unmarshalProto(rec, node); err != nil {
+			return err
+		}
+		im.nodes = append(im.nodes, node)
+	case kindJoinToken:
+		var token joinToken
+		if err := unmarshal(rec, &token); err != nil {
+			return err
+		}
+		im.joinTokens = append(im.joinTokens, &datastore.JoinToken{
+			Token:  token.Token,
+			Expiry: time.Unix(token.Expiry, 0),
+		})
+	case kindCAJournal:
+		var caj caJournal
+		if err := unmarshal(rec, &caj); err != nil {
+			return err
+		}
+		im.caJournals = append(im.caJournals, &datastore.CAJournal{
+			ActiveX509AuthorityID: caj.ActiveX509AuthorityID,
+			Data:                  caj.Data,
+		})
+	case kindRevokedX509SVID:
+		var revoked revokedX509SVID
+		if err := unmarshal(rec, &revoked); err != nil {
+			return err
+		}
+		im.revokedX509SVIDs = append(im.revokedX509SVIDs, &datastore.RevokedX509SVID{
+			AuthorityID:  revoked.AuthorityID,
+			SerialNumber: revoked.SerialNumber,
+			RevokedAt:    time.Unix(revoked.RevokedAt, 0),
+			ExpiresAt:    time.Unix(revoked.ExpiresAt, 0),
+		})
+	default:
+		return fmt.Errorf("unsupported archive record kind %q", rec.Kind)
+	}
+	return nil
+}
+
+// check validates the registration entries and fails if a record other than
+// a bundle appears more than once in the archive or already exists in the
+// DataStore.
+func (im *importer) check(ctx context.Context) error {
+	seen := make(map[string]bool)
+	for _, fr := range im.federationRelationships {
+		if err := checkNew(seen, fmt.Sprintf("federation relationship %q", fr.TrustDomain), func() (bool, error) {
+			existing, err := im.ds.FetchFederationRelationship(ctx, fr.TrustDomain)
+			return existing != nil, err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, entry := range im.entries {
+		if err := im.checkEntry(ctx, seen, entry); err != nil {
+			return err
+		}
+	}
+	for _, node := range im.nodes {
+		if err := checkNew(seen, fmt.Sprintf("attested node %q", node.SpiffeId), func() (bool, error) {
+			existing, err := im.ds.FetchAttestedNode(ctx, node.SpiffeId)
+			return existing != nil, err
+		}); err != nil {
+			return err
+		}
+	}
+	for i, token := range im.joinTokens {
+		// The token is a secret, so it is identified by its position
+		if err := checkNew(seen, fmt.Sprintf("join token #%d", i+1), func() (bool, error) {
+			existing, err := im.ds.FetchJoinToken(ctx, token.Token)
+			return existing != nil, err
+		}); err != nil {
+			return err
+		}
+	}
+	for _, caj := range im.caJournals {
+		if err := checkNew(seen, fmt.Sprintf("CA journal %q", caj.ActiveX509AuthorityID), func() (bool, error) {
+			existing, err := im.ds.FetchCAJournal(ctx, caj.ActiveX509AuthorityID)
+			return existing != nil, err
+		}); err != nil {
+			return err
+		}
+	}
+	if len(im.revokedX509SVIDs) > 0 {
+		existing, err := im.ds.ListRevokedX509SVIDs(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list revoked X509-SVIDs: %w", err)
+		}
+		revoked := make(map[string]bool, len(existing))
+		for _, r := range existing {
+			revoked[revokedX509SVIDName(r)] = true
+		}
+		for _, r := range im.revokedX509SVIDs {
+			name := revokedX509SVIDName(r)
+			if err := checkNew(seen, name, func() (bool, error) {
+				return revoked[name], nil
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (im *importer) checkEntry(ctx context.Context, seen map[string]bool, entry *common.RegistrationEntry) error {
+	name := fmt.Sprintf("registration entry %q", entry.EntryId)
+	if err := datastore.ValidateRegistrationEntry(entry); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return checkNew(seen, name, func() (bool, error) {
+		existing, err := im.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+		if err != nil || existing != nil {
+			return existing != nil, err
+		}
+		resp, err := im.ds.ListRegistrationEntries(ctx, datastore.SimilarEntriesRequest(entry))
+		if err != nil {
+			return false, err
+		}
+		for _, similar := range resp.Entries {
+			if datastore.IsSimilarEntry(similar, entry) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// checkNew fails if the record with the given name was already seen in the
+// archive or exists in the DataStore.
+func checkNew(seen map[string]bool, name string, exists func() (bool, error)) error {
+	if seen[name] {
+		return fmt.Errorf("archive holds %s more than once", name)
+	}
+	seen[name] = true
+
+	ok, err := exists()
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to check for existing %s: %w", name, err)
+	case ok:
+		return fmt.Errorf("%s already exists", name)
+	}
+	return nil
+}
+
+func revokedX509SVIDName(r *datastore.RevokedX509SVID) string {
+	return fmt.Sprintf("revoked X509-SVID %q issued by authority %q", r.SerialNumber, r.AuthorityID)
+}
+
+func (im *importer) write(ctx context.Context) error {
+	for _, bundle := range im.bundles {
+		if _, err := im.ds.SetBundle(ctx, bundle); err != nil {
+			return fmt.Errorf("failed to import bundle %q: %w", bundle.TrustDomainId, err)
+		}
+		im.stats.Bundles++
+	}
+	for _, fr := range im.federationRelationships {
+		if _, err := im.ds.CreateFederationRelationship(ctx, fr); err != nil {
+			return fmt.Errorf("failed to import federation relationship %q: %w", fr.TrustDomain, err)
+		}
+		im.stats.FederationRelationships++
+	}
+	for _, entry := range im.entries {
+		if _, err := im.ds.CreateRegistrationEntry(ctx, entry); err != nil {
+			return fmt.Errorf("failed to import registration entry %q: %w", entry.EntryId, err)
+		}
+		im.stats.RegistrationEntries++
+	}
+	for _, node := range im.nodes {
+		if _, err := im.ds.CreateAttestedNode(ctx, node); err != nil {
+			return fmt.Errorf("failed to import attested node %q: %w", node.SpiffeId, err)
+		}
+		if err := im.ds.SetNodeSelectors(ctx, node.SpiffeId, node.Selectors); err != nil {
+			return fmt.Errorf("failed to import selectors for attested node %q: %w", node.SpiffeId, err)
+		}
+		im.stats.AttestedNodes++
+	}
+	for _, token := range im.joinTokens {
+		if err := im.ds.CreateJoinToken(ctx, token); err != nil {
+			return fmt.Errorf("failed to import join token: %w", err)
+		}
+		im.stats.JoinTokens++
+	}
+	for _, caj := range im.caJournals {
+		if _, err := im.ds.SetCAJournal(ctx, caj); err != nil {
+			return fmt.Errorf("failed to import CA journal %q: %w", caj.ActiveX509AuthorityID, err)
+		}
+		im.stats.CAJournals++
+	}
+	for _, revoked := range im.revokedX509SVIDs {
+		if _, err := im.ds.RevokeX509SVID(ctx, revoked); err != nil {
+			return fmt.Errorf("failed to import %s: %w", revokedX509SVIDName(revoked), err)
+		}
+		im.stats.RevokedX509SVIDs++
+	}
+	return nil
+}
+
+func readRecord(dec *json.Decoder, kind string, v any) error {
+	var rec record
+	if err := dec.Decode(&rec); err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	if rec.Kind != kind {
+		return fmt.Errorf("expected archive %s record; got %q", kind, rec.Kind)
+	}
+	return unmarshal(&rec, v)
+}
+
+func unmarshal(rec *record, v any) error {
+	if err := json.Unmarshal(rec.Data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", rec.Kind, err)
+	}
+	return nil
+}
+
+func unmarshalProto(rec *record, m proto.Message) error {
+	if err := protojson.Unmarshal(rec.Data, m); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", rec.Kind, err)
+	}
+	return nil
+}
+
+func unmarshalFederationRelationship(rec *record) (*datastore.FederationRelationship, error) {
+	var in federationRelationship
+	if err := unmarshal(rec, &in); err != nil {
+		return nil, err
+	}
+
+	td, err := spiffeid.TrustDomainFromString(in.TrustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid federation relationship trust domain %q: %w", in.TrustDomain, err)
+	}
+	bundleEndpointURL, err := url.Parse(in.BundleEndpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle endpoint URL for federation relationship %q: %w", in.TrustDomain, err)
+	}
+
+	fr := &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     bundleEndpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointType(in.BundleEndpointProfile),
+	}
+	if in.EndpointSPIFFEID != "" {
+		fr.EndpointSPIFFEID, err = spiffeid.FromString(in.EndpointSPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint SPIFFE ID for federation relationship %q: %w", in.TrustDomain, err)
+		}
+	}
+	return fr, nil
+}
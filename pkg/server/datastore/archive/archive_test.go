@@ -0,0 +1,308 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/datastore/archive"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctx     = context.Background()
+	td      = spiffeid.RequireTrustDomainFromString("example.org")
+	otherTD = spiffeid.RequireTrustDomainFromString("other.org")
+
+	createdAtRE = regexp.MustCompile(`,"createdAt":"\d+"`)
+)
+
+func TestExportImport(t *testing.T) {
+	src := fakedatastore.New(t)
+	populate(t, src)
+
+	buf := new(bytes.Buffer)
+	stats, err := archive.Export(ctx, src, td, buf)
+	require.NoError(t, err)
+	expectedStats := &archive.Stats{
+		Bundles:                 2,
+		FederationRelationships: 1,
+		RegistrationEntries:     2,
+		AttestedNodes:           2,
+		JoinTokens:              1,
+		CAJournals:              1,
+		RevokedX509SVIDs:        1,
+	}
+	assert.Equal(t, expectedStats, stats)
+
+	dst := fakedatastore.New(t)
+	stats, err = archive.Import(ctx, dst, td, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, expectedStats, stats)
+
+	// Exporting the imported DataStore yields the same records
+	imported := new(bytes.Buffer)
+	_, err = archive.Export(ctx, dst, td, imported)
+	require.NoError(t, err)
+	assert.Equal(t, normalize(t, buf.String()), normalize(t, imported.String()))
+
+	node, err := dst.FetchAttestedNode(ctx, "spiffe://example.org/spire/agent/test/node1")
+	require.NoError(t, err)
+	require.NotNil(t, node)
+	selectors, err := dst.GetNodeSelectors(ctx, node.SpiffeId, datastore.RequireCurrent)
+	require.NoError(t, err)
+	spiretest.AssertProtoListEqual(t, []*common.Selector{{Type: "test", Value: "node1"}}, selectors)
+
+	// Importing into a DataStore that already holds the records fails
+	_, err = archive.Import(ctx, dst, td, bytes.NewReader(buf.Bytes()))
+	require.EqualError(t, err, `federation relationship "other.org" already exists`)
+}
+
+func TestImportConflicts(t *testing.T) {
+	src := fakedatastore.New(t)
+	populate(t, src)
+	buf := new(bytes.Buffer)
+	_, err := archive.Export(ctx, src, td, buf)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name      string
+		setup     func(t *testing.T, ds datastore.DataStore)
+		expectErr string
+	}{
+		{
+			name: "similar registration entry",
+			setup: func(t *testing.T, ds datastore.DataStore) {
+				_, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+					EntryId:   "other",
+					ParentId:  "spiffe://example.org/spire/agent/test/node2",
+					SpiffeId:  "spiffe://example.org/workload2",
+					Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+				})
+				require.NoError(t, err)
+			},
+			expectErr: `registration entry "entry2" already exists`,
+		},
+		{
+			name: "join token",
+			setup: func(t *testing.T, ds datastore.DataStore) {
+				require.NoError(t, ds.CreateJoinToken(ctx, &datastore.JoinToken{
+					Token:  "token",
+					Expiry: time.Now().Add(time.Hour),
+				}))
+			},
+			expectErr: "join token #1 already exists",
+		},
+		{
+			name: "CA journal",
+			setup: func(t *testing.T, ds datastore.DataStore) {
+				_, err := ds.SetCAJournal(ctx, &datastore.CAJournal{
+					ActiveX509AuthorityID: "authority",
+					Data:                  []byte("other"),
+				})
+				require.NoError(t, err)
+			},
+			expectErr: `CA journal "authority" already exists`,
+		},
+		{
+			name: "revoked X509-SVID",
+			setup: func(t *testing.T, ds datastore.DataStore) {
+				_, err := ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+					AuthorityID:  "authority",
+					SerialNumber: "0a1b2c",
+					RevokedAt:    time.Now(),
+					ExpiresAt:    time.Now().Add(time.Hour),
+				})
+				require.NoError(t, err)
+			},
+			expectErr: `revoked X509-SVID "0a1b2c" issued by authority "authority" already exists`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := fakedatastore.New(t)
+			tt.setup(t, dst)
+			before := new(bytes.Buffer)
+			_, err := archive.Export(ctx, dst, td, before)
+			require.NoError(t, err)
+
+			_, err = archive.Import(ctx, dst, td, bytes.NewReader(buf.Bytes()))
+			require.EqualError(t, err, tt.expectErr)
+
+			// Nothing was imported
+			after := new(bytes.Buffer)
+			_, err = archive.Export(ctx, dst, td, after)
+			require.NoError(t, err)
+			assert.Equal(t, normalize(t, before.String()), normalize(t, after.String()))
+		})
+	}
+}
+
+func TestImportFailures(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		archive   string
+		expectErr string
+	}{
+		{
+			name:      "empty",
+			archive:   "",
+			expectErr: "failed to read archive: EOF",
+		},
+		{
+			name:      "missing header",
+			archive:   `{"kind":"join_token","data":{"token":"token","expiry":1}}`,
+			expectErr: `expected archive header record; got "join_token"`,
+		},
+		{
+			name:      "unsupported version",
+			archive:   `{"kind":"header","data":{"version":2,"trust_domain":"example.org"}}`,
+			expectErr: "unsupported archive version 2; expected 1",
+		},
+		{
+			name:      "trust domain mismatch",
+			archive:   `{"kind":"header","data":{"version":1,"trust_domain":"other.org"}}`,
+			expectErr: `archive trust domain "other.org" does not match the server trust domain "example.org"`,
+		},
+		{
+			name: "unsupported kind",
+			archive: `{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}
+{"kind":"unknown","data":{}}`,
+			expectErr: `unsupported archive record kind "unknown"`,
+		},
+		{
+			name: "malformed record",
+			archive: `{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}
+{"kind":"bundle","data":{"trustDomainId":1}}`,
+			expectErr: "failed to unmarshal bundle",
+		},
+		{
+			name: "truncated",
+			archive: `{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}
+{"kind":"bundle","data":{"trustDomainId":"spiffe://example.org"}}
+{"kind":"bundle","data":{`,
+			expectErr: "failed to read archive: unexpected EOF",
+		},
+		{
+			name: "invalid registration entry",
+			archive: `{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}
+{"kind":"bundle","data":{"trustDomainId":"spiffe://example.org"}}
+{"kind":"registration_entry","data":{"entryId":"entry1","spiffeId":"spiffe://example.org/workload"}}`,
+			expectErr: `invalid registration entry "entry1"`,
+		},
+		{
+			name: "duplicate record",
+			archive: `{"kind":"header","data":{"version":1,"trust_domain":"example.org"}}
+{"kind":"bundle","data":{"trustDomainId":"spiffe://example.org"}}
+{"kind":"attested_node","data":{"spiffeId":"spiffe://example.org/spire/agent/test/node1"}}
+{"kind":"attested_node","data":{"spiffeId":"spiffe://example.org/spire/agent/test/node1"}}`,
+			expectErr: `archive holds attested node "spiffe://example.org/spire/agent/test/node1" more than once`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			_, err := archive.Import(ctx, ds, td, strings.NewReader(tt.archive))
+			require.ErrorContains(t, err, tt.expectErr)
+
+			// Nothing is written when the archive cannot be imported
+			count, err := ds.CountBundles(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, count)
+		})
+	}
+}
+
+func populate(t *testing.T, ds datastore.DataStore) {
+	_, err := ds.CreateBundle(ctx, &common.Bundle{
+		TrustDomainId: td.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: []byte("root")}},
+	})
+	require.NoError(t, err)
+
+	bundleEndpointURL, err := url.Parse("https://other.org/bundle")
+	require.NoError(t, err)
+	_, err = ds.CreateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain:           otherTD,
+		BundleEndpointURL:     bundleEndpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointSPIFFE,
+		EndpointSPIFFEID:      spiffeid.RequireFromPath(otherTD, "/bundle-server"),
+		TrustDomainBundle: &common.Bundle{
+			TrustDomainId: otherTD.IDString(),
+			RootCas:       []*common.Certificate{{DerBytes: []byte("other-root")}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:       "entry1",
+		ParentId:      "spiffe://example.org/spire/agent/test/node1",
+		SpiffeId:      "spiffe://example.org/workload1",
+		Selectors:     []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		FederatesWith: []string{otherTD.IDString()},
+		X509SvidTtl:   3600,
+	})
+	require.NoError(t, err)
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:   "entry2",
+		ParentId:  "spiffe://example.org/spire/agent/test/node2",
+		SpiffeId:  "spiffe://example.org/workload2",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+		DnsNames:  []string{"workload2.example.org"},
+		Hint:      "hint",
+	})
+	require.NoError(t, err)
+
+	_, err = ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/test/node1",
+		AttestationDataType: "test",
+		CertSerialNumber:    "1234",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		CanReattest:         true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ds.SetNodeSelectors(ctx, "spiffe://example.org/spire/agent/test/node1", []*common.Selector{{Type: "test", Value: "node1"}}))
+
+	// Banned node
+	_, err = ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/test/node2",
+		AttestationDataType: "test",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:  "token",
+		Expiry: time.Now().Add(time.Hour),
+	}))
+
+	_, err = ds.SetCAJournal(ctx, &datastore.CAJournal{
+		ActiveX509AuthorityID: "authority",
+		Data:                  []byte("journal"),
+	})
+	require.NoError(t, err)
+
+	_, err = ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+		AuthorityID:  "authority",
+		SerialNumber: "0a1b2c",
+		RevokedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+}
+
+// normalize strips the header, which holds the archive creation time, and the
+// registration entry creation times, which are set by the DataStore on import.
+func normalize(t *testing.T, archive string) string {
+	_, records, ok := strings.Cut(archive, "\n")
+	require.True(t, ok)
+	return createdAtRE.ReplaceAllString(records, "")
+}
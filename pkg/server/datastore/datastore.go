@@ -71,6 +71,7 @@ type DataStore interface {
 	CreateJoinToken(context.Context, *JoinToken) error
 	DeleteJoinToken(ctx context.Context, token string) error
 	FetchJoinToken(ctx context.Context, token string) (*JoinToken, error)
+	ListJoinTokens(context.Context) ([]*JoinToken, error)
 	PruneJoinTokens(context.Context, time.Time) error
 
 	// Federation Relationships
@@ -84,7 +85,12 @@ type DataStore interface {
 	SetCAJournal(ctx context.Context, caJournal *CAJournal) (*CAJournal, error)
 	FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (*CAJournal, error)
 	PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) error
-	ListCAJournalsForTesting(ctx context.Context) ([]*CAJournal, error)
+	ListCAJournals(ctx context.Context) ([]*CAJournal, error)
+
+	// Revoked X509-SVIDs
+	RevokeX509SVID(ctx context.Context, revoked *RevokedX509SVID) (*RevokedX509SVID, error)
+	ListRevokedX509SVIDs(ctx context.Context) ([]*RevokedX509SVID, error)
+	PruneRevokedX509SVIDs(ctx context.Context, expiresBefore time.Time) error
 }
 
 // DataConsistency indicates the required data consistency for a read operation.
@@ -220,6 +226,26 @@ type CAJournal struct {
 	ActiveX509AuthorityID string
 }
 
+// RevokedX509SVID is an X509-SVID that was revoked before its expiration.
+// It is identified by the X.509 authority that issued it and its serial
+// number, which is only unique for a given issuer.
+type RevokedX509SVID struct {
+	// AuthorityID is the authority ID (i.e. subject key ID) of the X.509
+	// authority that issued the SVID.
+	AuthorityID string
+
+	// SerialNumber is the serial number of the SVID as a lowercase
+	// hexadecimal string.
+	SerialNumber string
+
+	// RevokedAt is the time at which the SVID was revoked.
+	RevokedAt time.Time
+
+	// ExpiresAt is a time by which the SVID is known to have expired. Once
+	// it has passed, the revocation no longer needs to be published.
+	ExpiresAt time.Time
+}
+
 type ListRegistrationEntriesResponse struct {
 	Entries    []*common.RegistrationEntry
 	Pagination *Pagination
@@ -0,0 +1,510 @@
+package raftstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// op identifies the write operation carried by a command.
+type op string
+
+const (
+	opAppendBundle                 op = "append_bundle"
+	opCreateBundle                 op = "create_bundle"
+	opDeleteBundle                 op = "delete_bundle"
+	opPruneBundle                  op = "prune_bundle"
+	opSetBundle                    op = "set_bundle"
+	opUpdateBundle                 op = "update_bundle"
+	opTaintX509CA                  op = "taint_x509_ca"
+	opRevokeX509CA                 op = "revoke_x509_ca"
+	opTaintJWTKey                  op = "taint_jwt_key"
+	opRevokeJWTKey                 op = "revoke_jwt_key"
+	opCreateRegistrationEntry      op = "create_registration_entry"
+	opDeleteRegistrationEntry      op = "delete_registration_entry"
+	opPruneRegistrationEntries     op = "prune_registration_entries"
+	opUpdateRegistrationEntry      op = "update_registration_entry"
+	opCreateRegistrationEntryEvent op = "create_registration_entry_event"
+	opDeleteRegistrationEntryEvent op = "delete_registration_entry_event"
+	opPruneRegistrationEntryEvents op = "prune_registration_entry_events"
+	opCreateAttestedNode           op = "create_attested_node"
+	opDeleteAttestedNode           op = "delete_attested_node"
+	opUpdateAttestedNode           op = "update_attested_node"
+	opSetNodeSelectors             op = "set_node_selectors"
+	opCreateAttestedNodeEvent      op = "create_attested_node_event"
+	opDeleteAttestedNodeEvent      op = "delete_attested_node_event"
+	opPruneAttestedNodeEvents      op = "prune_attested_node_events"
+	opCreateJoinToken              op = "create_join_token"
+	opDeleteJoinToken              op = "delete_join_token"
+	opPruneJoinTokens              op = "prune_join_tokens"
+	opCreateFederationRelationship op = "create_federation_relationship"
+	opDeleteFederationRelationship op = "delete_federation_relationship"
+	opUpdateFederationRelationship op = "update_federation_relationship"
+	opSetCAJournal                 op = "set_ca_journal"
+	opPruneCAJournals              op = "prune_ca_journals"
+	opRevokeX509SVID               op = "revoke_x509_svid"
+	opPruneRevokedX509SVIDs        op = "prune_revoked_x509_svids"
+)
+
+// command is a write operation replicated through the raft log. The time at
+// which the command was proposed is part of the command so that time-based
+// operations (e.g. pruning) yield the same results on every member.
+// Protobuf messages are carried in their binary encoding.
+type command struct {
+	Op   op              `json:"op"`
+	Now  int64           `json:"now"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type bundleArgs struct {
+	Bundle  []byte `json:"bundle,omitempty"`
+	Mask    []byte `json:"mask,omitempty"`
+	HasMask bool   `json:"has_mask,omitempty"`
+}
+
+type trustDomainArgs struct {
+	TrustDomainID string               `json:"trust_domain_id"`
+	Mode          datastore.DeleteMode `json:"mode,omitempty"`
+	KeyID         string               `json:"key_id,omitempty"`
+	ExpiresBefore int64                `json:"expires_before,omitempty"`
+}
+
+type entryArgs struct {
+	Entry   []byte `json:"entry,omitempty"`
+	Mask    []byte `json:"mask,omitempty"`
+	HasMask bool   `json:"has_mask,omitempty"`
+	EntryID string `json:"entry_id,omitempty"`
+}
+
+type nodeArgs struct {
+	Node      []byte     `json:"node,omitempty"`
+	Mask      []byte     `json:"mask,omitempty"`
+	HasMask   bool       `json:"has_mask,omitempty"`
+	SpiffeID  string     `json:"spiffe_id,omitempty"`
+	Selectors []selector `json:"selectors,omitempty"`
+}
+
+type selector struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type eventArgs struct {
+	EventID   uint          `json:"event_id,omitempty"`
+	Key       string        `json:"key,omitempty"`
+	OlderThan time.Duration `json:"older_than,omitempty"`
+}
+
+type pruneArgs struct {
+	Before int64 `json:"before"`
+}
+
+type joinTokenArgs struct {
+	Token  string `json:"token"`
+	Expiry int64  `json:"expiry,omitempty"`
+}
+
+type federationRelationshipArgs struct {
+	Relationship federationRelationship `json:"relationship"`
+	Bundle       []byte                 `json:"bundle,omitempty"`
+	Mask         []byte                 `json:"mask,omitempty"`
+}
+
+type caJournalArgs struct {
+	ID                    uint   `json:"id,omitempty"`
+	Data                  []byte `json:"data,omitempty"`
+	ActiveX509AuthorityID string `json:"active_x509_authority_id,omitempty"`
+}
+
+type revokedX509SVIDArgs struct {
+	AuthorityID  string `json:"authority_id"`
+	SerialNumber string `json:"serial_number"`
+	RevokedAt    int64  `json:"revoked_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// result is the outcome of applying a command. Errors are carried as gRPC
+// status codes and messages so they can be reconstructed on the member that
+// proposed the command.
+type result struct {
+	Code    codes.Code `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+	Value   []byte     `json:"value,omitempty"`
+	Flag    bool       `json:"flag,omitempty"`
+}
+
+func (r *result) err() error {
+	if r.Code == codes.OK {
+		return nil
+	}
+	return status.Error(r.Code, r.Message)
+}
+
+func newCommand(op op, args any) (*command, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to encode command: %v", datastoreRaftErrorPrefix, err)
+	}
+	return &command{Op: op, Args: data}, nil
+}
+
+func errorResult(err error) *result {
+	st := status.Convert(err)
+	return &result{
+		Code:    st.Code(),
+		Message: st.Message(),
+	}
+}
+
+func protoResult(m proto.Message, err error) *result {
+	if err != nil {
+		return errorResult(err)
+	}
+	value, err := proto.Marshal(m)
+	if err != nil {
+		return errorResult(status.Errorf(codes.Internal, "failed to encode result: %v", err))
+	}
+	return &result{Value: value}
+}
+
+func jsonResult(v any, err error) *result {
+	if err != nil {
+		return errorResult(err)
+	}
+	value, err := json.Marshal(v)
+	if err != nil {
+		return errorResult(status.Errorf(codes.Internal, "failed to encode result: %v", err))
+	}
+	return &result{Value: value}
+}
+
+func marshalProto(m proto.Message) ([]byte, error) {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to encode message: %v", datastoreRaftErrorPrefix, err)
+	}
+	return data, nil
+}
+
+func unmarshalProto[T proto.Message](data []byte, m T) (T, error) {
+	if err := proto.Unmarshal(data, m); err != nil {
+		var zero T
+		return zero, status.Errorf(codes.Internal, "%s: failed to decode message: %v", datastoreRaftErrorPrefix, err)
+	}
+	return m, nil
+}
+
+func decodeArgs[T any](cmd *command) (*T, error) {
+	args := new(T)
+	if err := json.Unmarshal(cmd.Args, args); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to decode %s command: %v", datastoreRaftErrorPrefix, cmd.Op, err)
+	}
+	return args, nil
+}
+
+func toSelectors(selectors []*common.Selector) []selector {
+	out := make([]selector, 0, len(selectors))
+	for _, s := range selectors {
+		out = append(out, selector{Type: s.Type, Value: s.Value})
+	}
+	return out
+}
+
+func fromSelectors(selectors []selector) []*common.Selector {
+	out := make([]*common.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		out = append(out, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+	return out
+}
+
+// apply applies the command to the state and returns the result.
+func (s *state) apply(cmd *command) *result {
+	now := time.Unix(0, cmd.Now)
+
+	switch cmd.Op {
+	case opAppendBundle, opCreateBundle, opSetBundle, opUpdateBundle:
+		args, err := decodeArgs[bundleArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		bundle, err := unmarshalProto(args.Bundle, new(common.Bundle))
+		if err != nil {
+			return errorResult(err)
+		}
+		switch cmd.Op {
+		case opAppendBundle:
+			return protoResult(s.appendBundle(bundle))
+		case opCreateBundle:
+			return protoResult(s.createBundle(bundle))
+		case opSetBundle:
+			return protoResult(s.setBundle(bundle))
+		default:
+			var mask *common.BundleMask
+			if args.HasMask {
+				if mask, err = unmarshalProto(args.Mask, new(common.BundleMask)); err != nil {
+					return errorResult(err)
+				}
+			}
+			return protoResult(s.updateBundle(bundle, mask))
+		}
+
+	case opDeleteBundle, opPruneBundle, opTaintX509CA, opRevokeX509CA, opTaintJWTKey, opRevokeJWTKey:
+		args, err := decodeArgs[trustDomainArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		switch cmd.Op {
+		case opDeleteBundle:
+			return errorResult(s.deleteBundle(args.TrustDomainID, args.Mode, now))
+		case opPruneBundle:
+			changed, err := s.pruneBundle(args.TrustDomainID, time.Unix(0, args.ExpiresBefore))
+			if err != nil {
+				return errorResult(err)
+			}
+			return &result{Flag: changed}
+		case opTaintX509CA:
+			return errorResult(s.taintX509CA(args.TrustDomainID, args.KeyID))
+		case opRevokeX509CA:
+			return errorResult(s.revokeX509CA(args.TrustDomainID, args.KeyID))
+		case opTaintJWTKey:
+			return protoResult(s.taintJWTKey(args.TrustDomainID, args.KeyID))
+		default:
+			return protoResult(s.revokeJWTKey(args.TrustDomainID, args.KeyID))
+		}
+
+	case opCreateRegistrationEntry, opUpdateRegistrationEntry:
+		args, err := decodeArgs[entryArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		entry, err := unmarshalProto(args.Entry, new(common.RegistrationEntry))
+		if err != nil {
+			return errorResult(err)
+		}
+		if cmd.Op == opCreateRegistrationEntry {
+			entry, existing, err := s.createOrReturnRegistrationEntry(entry, now)
+			res := protoResult(entry, err)
+			res.Flag = existing
+			return res
+		}
+		var mask *common.RegistrationEntryMask
+		if args.HasMask {
+			if mask, err = unmarshalProto(args.Mask, new(common.RegistrationEntryMask)); err != nil {
+				return errorResult(err)
+			}
+		}
+		return protoResult(s.updateRegistrationEntry(entry, mask, now))
+
+	case opDeleteRegistrationEntry:
+		args, err := decodeArgs[entryArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return protoResult(s.deleteRegistrationEntry(args.EntryID, now))
+
+	case opPruneRegistrationEntries:
+		args, err := decodeArgs[pruneArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return errorResult(s.pruneRegistrationEntries(time.Unix(0, args.Before), now))
+
+	case opCreateRegistrationEntryEvent, opDeleteRegistrationEntryEvent, opPruneRegistrationEntryEvents,
+		opCreateAttestedNodeEvent, opDeleteAttestedNodeEvent, opPruneAttestedNodeEvents:
+		args, err := decodeArgs[eventArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		events := s.entryEvents
+		if cmd.Op == opCreateAttestedNodeEvent || cmd.Op == opDeleteAttestedNodeEvent || cmd.Op == opPruneAttestedNodeEvents {
+			events = s.nodeEvents
+		}
+		switch cmd.Op {
+		case opCreateRegistrationEntryEvent, opCreateAttestedNodeEvent:
+			return errorResult(events.create(args.EventID, args.Key, now))
+		case opDeleteRegistrationEntryEvent, opDeleteAttestedNodeEvent:
+			events.delete(args.EventID)
+		default:
+			events.prune(now.Add(-args.OlderThan))
+		}
+		return &result{}
+
+	case opCreateAttestedNode, opUpdateAttestedNode:
+		args, err := decodeArgs[nodeArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		node, err := unmarshalProto(args.Node, new(common.AttestedNode))
+		if err != nil {
+			return errorResult(err)
+		}
+		if cmd.Op == opCreateAttestedNode {
+			return protoResult(s.createAttestedNode(node, now))
+		}
+		var mask *common.AttestedNodeMask
+		if args.HasMask {
+			if mask, err = unmarshalProto(args.Mask, new(common.AttestedNodeMask)); err != nil {
+				return errorResult(err)
+			}
+		}
+		return protoResult(s.updateAttestedNode(node, mask, now))
+
+	case opDeleteAttestedNode:
+		args, err := decodeArgs[nodeArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return protoResult(s.deleteAttestedNode(args.SpiffeID, now))
+
+	case opSetNodeSelectors:
+		args, err := decodeArgs[nodeArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return errorResult(s.setNodeSelectors(args.SpiffeID, fromSelectors(args.Selectors), now))
+
+	case opCreateJoinToken, opDeleteJoinToken:
+		args, err := decodeArgs[joinTokenArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		if cmd.Op == opCreateJoinToken {
+			return errorResult(s.createJoinToken(args.Token, args.Expiry))
+		}
+		return errorResult(s.deleteJoinToken(args.Token))
+
+	case opPruneJoinTokens:
+		args, err := decodeArgs[pruneArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		s.pruneJoinTokens(time.Unix(0, args.Before))
+		return &result{}
+
+	case opCreateFederationRelationship, opUpdateFederationRelationship, opDeleteFederationRelationship:
+		args, err := decodeArgs[federationRelationshipArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		if cmd.Op == opDeleteFederationRelationship {
+			return errorResult(s.deleteFederationRelationship(args.Relationship.TrustDomain))
+		}
+		var bundle *common.Bundle
+		if len(args.Bundle) > 0 {
+			if bundle, err = unmarshalProto(args.Bundle, new(common.Bundle)); err != nil {
+				return errorResult(err)
+			}
+		}
+		if cmd.Op == opCreateFederationRelationship {
+			if err := s.createFederationRelationship(&args.Relationship, bundle); err != nil {
+				return errorResult(err)
+			}
+			fr, err := s.toFederationRelationship(&args.Relationship)
+			if err != nil {
+				return errorResult(err)
+			}
+			return jsonResult(encodeFederationRelationship(fr))
+		}
+		mask, err := unmarshalProto(args.Mask, new(types.FederationRelationshipMask))
+		if err != nil {
+			return errorResult(err)
+		}
+		fr, err := s.updateFederationRelationship(&args.Relationship, bundle, mask)
+		if err != nil {
+			return errorResult(err)
+		}
+		return jsonResult(encodeFederationRelationship(fr))
+
+	case opSetCAJournal:
+		args, err := decodeArgs[caJournalArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		caj, err := s.setCAJournal(&datastore.CAJournal{
+			ID:                    args.ID,
+			Data:                  args.Data,
+			ActiveX509AuthorityID: args.ActiveX509AuthorityID,
+		})
+		if err != nil {
+			return errorResult(err)
+		}
+		return jsonResult(&caJournalArgs{
+			ID:                    caj.ID,
+			Data:                  caj.Data,
+			ActiveX509AuthorityID: caj.ActiveX509AuthorityID,
+		}, nil)
+
+	case opPruneCAJournals:
+		args, err := decodeArgs[pruneArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return errorResult(s.pruneCAJournals(args.Before))
+
+	case opRevokeX509SVID:
+		args, err := decodeArgs[revokedX509SVIDArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		return errorResult(s.revokeX509SVID(&revokedX509SVID{
+			AuthorityID:  args.AuthorityID,
+			SerialNumber: args.SerialNumber,
+			RevokedAt:    args.RevokedAt,
+			ExpiresAt:    args.ExpiresAt,
+		}))
+
+	case opPruneRevokedX509SVIDs:
+		args, err := decodeArgs[pruneArgs](cmd)
+		if err != nil {
+			return errorResult(err)
+		}
+		s.pruneRevokedX509SVIDs(time.Unix(0, args.Before))
+		return &result{}
+
+	default:
+		return errorResult(status.Errorf(codes.Internal, "%s: unknown command %q", datastoreRaftErrorPrefix, cmd.Op))
+	}
+}
+
+func encodeFederationRelationship(fr *datastore.FederationRelationship) (*federationRelationshipArgs, error) {
+	args := &federationRelationshipArgs{
+		Relationship: federationRelationship{
+			TrustDomain:           fr.TrustDomain.Name(),
+			BundleEndpointProfile: string(fr.BundleEndpointProfile),
+		},
+	}
+	if fr.BundleEndpointURL != nil {
+		args.Relationship.BundleEndpointURL = fr.BundleEndpointURL.String()
+	}
+	if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
+		args.Relationship.EndpointSPIFFEID = fr.EndpointSPIFFEID.String()
+	}
+	if fr.TrustDomainBundle != nil {
+		bundle, err := marshalProto(fr.TrustDomainBundle)
+		if err != nil {
+			return nil, err
+		}
+		args.Bundle = bundle
+	}
+	return args, nil
+}
+
+func decodeFederationRelationship(args *federationRelationshipArgs) (*datastore.FederationRelationship, error) {
+	var bundle *common.Bundle
+	if len(args.Bundle) > 0 {
+		var err error
+		if bundle, err = unmarshalProto(args.Bundle, new(common.Bundle)); err != nil {
+			return nil, err
+		}
+	}
+	return toFederationRelationship(&args.Relationship, func(string) *common.Bundle {
+		return bundle
+	})
+}
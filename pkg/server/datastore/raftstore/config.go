@@ -0,0 +1,213 @@
+package raftstore
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/printer"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+)
+
+const (
+	// DatabaseType is the value of the "database_type" setting of the SQL
+	// datastore configuration that selects the raft datastore.
+	DatabaseType = "raft"
+
+	defaultApplyTimeout     = 10 * time.Second
+	defaultHeartbeatTimeout = time.Second
+	minHeartbeatTimeout     = 10 * time.Millisecond
+)
+
+// configuration is the DataStore plugin configuration. The raft settings
+// are nested under the database_type block so that the raft datastore is
+// configured through the same plugin as the SQL datastore:
+//
+//	DataStore "sql" {
+//	    plugin_data {
+//	        database_type "raft" {
+//	            node_id = "server-1"
+//	            ...
+//	        }
+//	    }
+//	}
+type configuration struct {
+	DatabaseTypeNode ast.Node `hcl:"database_type" json:"database_type"`
+}
+
+type dbTypeConfig struct {
+	Raft *raftConfig `hcl:"raft" json:"raft"`
+}
+
+type raftConfig struct {
+	NodeID           string            `hcl:"node_id" json:"node_id"`
+	BindAddress      string            `hcl:"bind_address" json:"bind_address"`
+	AdvertiseAddress string            `hcl:"advertise_address" json:"advertise_address"`
+	DataDir          string            `hcl:"data_dir" json:"data_dir"`
+	Peers            map[string]string `hcl:"peers" json:"peers"`
+	ApplyTimeout     string            `hcl:"apply_timeout" json:"apply_timeout"`
+	HeartbeatTimeout string            `hcl:"heartbeat_timeout" json:"heartbeat_timeout"`
+	TLS              *tlsConfig        `hcl:"tls" json:"tls"`
+
+	applyTimeout     time.Duration
+	heartbeatTimeout time.Duration
+	advertise        net.Addr
+}
+
+type tlsConfig struct {
+	CertFilePath string `hcl:"cert_file_path" json:"cert_file_path"`
+	KeyFilePath  string `hcl:"key_file_path" json:"key_file_path"`
+	CAFilePath   string `hcl:"ca_file_path" json:"ca_file_path"`
+}
+
+// IsRaftConfiguration returns true if the given DataStore plugin
+// configuration selects the raft database type.
+func IsRaftConfiguration(hclConfiguration string) bool {
+	config := new(configuration)
+	if err := hcl.Decode(config, hclConfiguration); err != nil {
+		return false
+	}
+	objectList, ok := config.DatabaseTypeNode.(*ast.ObjectList)
+	if !ok || len(objectList.Items) != 1 || len(objectList.Items[0].Keys) != 1 {
+		return false
+	}
+	return strings.Trim(objectList.Items[0].Keys[0].Token.Text, "\"") == DatabaseType
+}
+
+func parseConfiguration(hclConfiguration string) (*raftConfig, error) {
+	config := new(configuration)
+	if err := hcl.Decode(config, hclConfiguration); err != nil {
+		return nil, err
+	}
+
+	// We expect the node to be *ast.ObjectList.
+	objectList, ok := config.DatabaseTypeNode.(*ast.ObjectList)
+	if !ok {
+		return nil, errors.New("malformed database type configuration")
+	}
+	if len(objectList.Items) != 1 {
+		return nil, errors.New("exactly one database type is expected")
+	}
+	if len(objectList.Items[0].Keys) != 1 {
+		return nil, errors.New("exactly one key is expected")
+	}
+	if databaseType := strings.Trim(objectList.Items[0].Keys[0].Token.Text, "\""); databaseType != DatabaseType {
+		return nil, fmt.Errorf("unknown database type: %s", databaseType)
+	}
+
+	var data bytes.Buffer
+	if err := printer.DefaultConfig.Fprint(&data, objectList); err != nil {
+		return nil, err
+	}
+
+	dbTypeConfig := new(dbTypeConfig)
+	if err := hcl.Decode(dbTypeConfig, data.String()); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	if dbTypeConfig.Raft == nil {
+		return nil, errors.New("missing raft configuration")
+	}
+
+	if err := dbTypeConfig.Raft.validate(); err != nil {
+		return nil, err
+	}
+	return dbTypeConfig.Raft, nil
+}
+
+func (c *raftConfig) validate() error {
+	if c.NodeID == "" {
+		return errors.New("node_id must be set")
+	}
+	if c.BindAddress == "" {
+		return errors.New("bind_address must be set")
+	}
+	if c.DataDir == "" {
+		return errors.New("data_dir must be set")
+	}
+	for id, address := range c.Peers {
+		if id == c.NodeID {
+			return fmt.Errorf("peers must not include this node (%q)", id)
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return fmt.Errorf("invalid address for peer %q: %w", id, err)
+		}
+	}
+
+	if c.AdvertiseAddress != "" {
+		advertise, err := net.ResolveTCPAddr("tcp", c.AdvertiseAddress)
+		if err != nil {
+			return fmt.Errorf("invalid advertise_address: %w", err)
+		}
+		c.advertise = advertise
+	}
+
+	c.applyTimeout = defaultApplyTimeout
+	if c.ApplyTimeout != "" {
+		applyTimeout, err := time.ParseDuration(c.ApplyTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid apply_timeout: %w", err)
+		}
+		if applyTimeout <= 0 {
+			return errors.New("apply_timeout must be positive")
+		}
+		c.applyTimeout = applyTimeout
+	}
+
+	c.heartbeatTimeout = defaultHeartbeatTimeout
+	if c.HeartbeatTimeout != "" {
+		heartbeatTimeout, err := time.ParseDuration(c.HeartbeatTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid heartbeat_timeout: %w", err)
+		}
+		if heartbeatTimeout < minHeartbeatTimeout {
+			return fmt.Errorf("heartbeat_timeout must be at least %s", minHeartbeatTimeout)
+		}
+		c.heartbeatTimeout = heartbeatTimeout
+	}
+
+	// The raft traffic carries every write to the datastore, so members
+	// must always authenticate each other.
+	switch {
+	case c.TLS == nil:
+		return errors.New("tls must be set")
+	case c.TLS.CertFilePath == "":
+		return errors.New("tls cert_file_path must be set")
+	case c.TLS.KeyFilePath == "":
+		return errors.New("tls key_file_path must be set")
+	case c.TLS.CAFilePath == "":
+		return errors.New("tls ca_file_path must be set")
+	}
+	return nil
+}
+
+// loadTLSConfig returns the mutual TLS configuration used between members.
+func (c *raftConfig) loadTLSConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.TLS.CertFilePath, c.TLS.KeyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load raft TLS key pair: %w", err)
+	}
+
+	caCerts, err := pemutil.LoadCertificates(c.TLS.CAFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load raft TLS CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	for _, caCert := range caCerts {
+		pool.AddCert(caCert)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package raftstore
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	datastoreRaftErrorPrefix       = "datastore-raft"
+	datastoreValidationErrorPrefix = "datastore-validation"
+)
+
+func newRaftError(code codes.Code, fmtMsg string, args ...any) error {
+	return status.Errorf(code, "%s: %s", datastoreRaftErrorPrefix, fmt.Sprintf(fmtMsg, args...))
+}
+
+func newNotFoundError() error {
+	return newRaftError(codes.NotFound, "record not found")
+}
+
+func newAlreadyExistsError(fmtMsg string, args ...any) error {
+	return newRaftError(codes.AlreadyExists, fmtMsg, args...)
+}
+
+func newValidationError(fmtMsg string, args ...any) error {
+	return status.Errorf(codes.InvalidArgument, "%s: %s", datastoreValidationErrorPrefix, fmt.Sprintf(fmtMsg, args...))
+}
+
+func newWrappedValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return newValidationError("%v", err)
+}
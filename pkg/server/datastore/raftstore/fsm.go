@@ -0,0 +1,133 @@
+package raftstore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/hashicorp/raft"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fsm is the raft finite state machine backing the datastore. It applies
+// committed commands to the state and keeps track of the last applied index
+// so that writes proposed on this member can wait until they are visible to
+// local reads.
+type fsm struct {
+	log logrus.FieldLogger
+
+	mu      sync.RWMutex
+	state   *state
+	applied uint64
+	// appliedCh is closed and replaced every time the applied index moves.
+	appliedCh chan struct{}
+}
+
+var _ raft.FSM = (*fsm)(nil)
+
+func newFSM(log logrus.FieldLogger) *fsm {
+	return &fsm{
+		log:       log,
+		state:     newState(log),
+		appliedCh: make(chan struct{}),
+	}
+}
+
+// Apply applies a committed log entry. The returned value is a *result.
+func (f *fsm) Apply(l *raft.Log) any {
+	var res *result
+	cmd := new(command)
+	if err := json.Unmarshal(l.Data, cmd); err != nil {
+		f.log.WithError(err).WithField("index", l.Index).Error("Failed to decode raft log entry")
+		res = errorResult(status.Errorf(codes.Internal, "%s: failed to decode command: %v", datastoreRaftErrorPrefix, err))
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if res == nil {
+		res = f.state.apply(cmd)
+	}
+	f.setApplied(l.Index)
+	return res
+}
+
+// Snapshot returns a point-in-time snapshot of the state. Stored values are
+// never mutated in place, so a shallow clone of the state is enough.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return &fsmSnapshot{
+		state: f.state.clone(),
+		index: f.applied,
+	}, nil
+}
+
+// Restore replaces the state with the one in the snapshot.
+func (f *fsm) Restore(r io.ReadCloser) error {
+	defer r.Close()
+
+	data := new(snapshotData)
+	if err := json.NewDecoder(r).Decode(data); err != nil {
+		return status.Errorf(codes.Internal, "%s: failed to decode snapshot: %v", datastoreRaftErrorPrefix, err)
+	}
+	s, err := data.toState(f.log)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state = s
+	f.setApplied(data.Index)
+	return nil
+}
+
+// read calls fn with the current state under the read lock.
+func (f *fsm) read(fn func(s *state) error) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return fn(f.state)
+}
+
+// waitForIndex blocks until the log entry with the given index has been
+// applied to the local state or the context is done.
+func (f *fsm) waitForIndex(ctx context.Context, index uint64) error {
+	for {
+		f.mu.RLock()
+		applied, ch := f.applied, f.appliedCh
+		f.mu.RUnlock()
+		if applied >= index {
+			return nil
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return status.Errorf(codes.Unavailable, "%s: timed out waiting for index %d to be applied: %v", datastoreRaftErrorPrefix, index, ctx.Err())
+		}
+	}
+}
+
+// setApplied must be called with the write lock held.
+func (f *fsm) setApplied(index uint64) {
+	f.applied = index
+	close(f.appliedCh)
+	f.appliedCh = make(chan struct{})
+}
+
+type fsmSnapshot struct {
+	state *state
+	index uint64
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(newSnapshotData(s.state, s.index)); err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
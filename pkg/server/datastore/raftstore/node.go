@@ -0,0 +1,297 @@
+package raftstore
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	raftDBFile        = "raft.db"
+	snapshotsRetained = 2
+	logCacheSize      = 512
+	maxPoolSize       = 3
+	transportTimeout  = 10 * time.Second
+	retryInterval     = 50 * time.Millisecond
+)
+
+var errForwardDial = errors.New("failed to dial leader")
+
+// node is a member of the raft cluster.
+type node struct {
+	log          logrus.FieldLogger
+	fsm          *fsm
+	raft         *raft.Raft
+	transport    *transport
+	netTransport *raft.NetworkTransport
+	store        *raftboltdb.BoltStore
+	applyTimeout time.Duration
+}
+
+// forwardResponse is the response to a command forwarded to the leader.
+type forwardResponse struct {
+	Index     uint64  `json:"index,omitempty"`
+	Result    *result `json:"result,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	NotLeader bool    `json:"not_leader,omitempty"`
+}
+
+func newNode(logger logrus.FieldLogger, config *raftConfig) (_ *node, err error) {
+	if err := os.MkdirAll(config.DataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	tlsConfig, err := config.loadTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &node{
+		log:          logger,
+		fsm:          newFSM(logger),
+		applyTimeout: config.applyTimeout,
+	}
+	defer func() {
+		if err != nil {
+			n.close()
+		}
+	}()
+
+	n.transport, err = newTransport(logger, config.BindAddress, config.advertise, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	n.transport.authorize = n.authorizePeer
+	n.transport.forward = n.handleForward
+
+	n.store, err = raftboltdb.NewBoltStore(filepath.Join(config.DataDir, raftDBFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raft database: %w", err)
+	}
+	logStore, err := raft.NewLogCache(logCacheSize, n.store)
+	if err != nil {
+		return nil, err
+	}
+
+	hclogger := log.NewHCLogAdapter(logger, "raft")
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(config.DataDir, snapshotsRetained, hclogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot store: %w", err)
+	}
+
+	n.netTransport = raft.NewNetworkTransportWithConfig(&raft.NetworkTransportConfig{
+		Logger:  hclogger,
+		Stream:  n.transport.streamLayer(),
+		MaxPool: maxPoolSize,
+		Timeout: transportTimeout,
+	})
+
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(config.NodeID)
+	raftConfig.Logger = hclogger
+	raftConfig.HeartbeatTimeout = config.heartbeatTimeout
+	raftConfig.ElectionTimeout = config.heartbeatTimeout
+	raftConfig.LeaderLeaseTimeout = config.heartbeatTimeout / 2
+
+	hasState, err := raft.HasExistingState(logStore, n.store, snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing raft state: %w", err)
+	}
+	if !hasState {
+		servers := []raft.Server{
+			{
+				ID:      raftConfig.LocalID,
+				Address: n.netTransport.LocalAddr(),
+			},
+		}
+		for id, address := range config.Peers {
+			servers = append(servers, raft.Server{
+				ID:      raft.ServerID(id),
+				Address: raft.ServerAddress(address),
+			})
+		}
+		if err := raft.BootstrapCluster(raftConfig, logStore, n.store, snapshots, n.netTransport, raft.Configuration{Servers: servers}); err != nil {
+			return nil, fmt.Errorf("failed to bootstrap raft cluster: %w", err)
+		}
+	}
+
+	n.raft, err = raft.NewRaft(raftConfig, n.fsm, logStore, n.store, snapshots, n.netTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start raft: %w", err)
+	}
+
+	// Connections are only served once raft is running, since both the
+	// authorization of peers and forwarded commands depend on it.
+	n.transport.serve()
+	return n, nil
+}
+
+// apply proposes the command to the cluster and waits until it has been
+// applied to the local state, so that reads that follow observe the write.
+// Commands proposed on a follower are forwarded to the leader. Proposals are
+// retried while there is no reachable leader until the apply timeout
+// elapses.
+func (n *node) apply(ctx context.Context, cmd *command) (*result, error) {
+	ctx, cancel := context.WithTimeout(ctx, n.applyTimeout)
+	defer cancel()
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to encode command: %v", datastoreRaftErrorPrefix, err)
+	}
+
+	for {
+		resp, err := n.propose(ctx, data)
+		switch {
+		case err != nil:
+			return nil, status.Errorf(codes.Unavailable, "%s: failed to apply command: %v", datastoreRaftErrorPrefix, err)
+		case resp.Error != "":
+			return nil, status.Errorf(codes.Unavailable, "%s: failed to apply command: %s", datastoreRaftErrorPrefix, resp.Error)
+		case !resp.NotLeader:
+			if err := n.fsm.waitForIndex(ctx, resp.Index); err != nil {
+				return nil, err
+			}
+			return resp.Result, nil
+		}
+
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			return nil, status.Errorf(codes.Unavailable, "%s: no raft leader available: %v", datastoreRaftErrorPrefix, ctx.Err())
+		}
+	}
+}
+
+// propose applies the command on the leader. A response with NotLeader set
+// is returned when the command can be safely retried.
+func (n *node) propose(ctx context.Context, data []byte) (*forwardResponse, error) {
+	if n.raft.State() == raft.Leader {
+		return n.applyOnLeader(ctx, data), nil
+	}
+
+	leader, _ := n.raft.LeaderWithID()
+	if leader == "" {
+		return &forwardResponse{NotLeader: true}, nil
+	}
+
+	respData, err := n.transport.forwardTo(ctx, string(leader), data)
+	switch {
+	case errors.Is(err, errForwardDial):
+		n.log.WithError(err).WithField("leader", leader).Debug("Failed to forward command to leader")
+		return &forwardResponse{NotLeader: true}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	resp := new(forwardResponse)
+	if err := json.Unmarshal(respData, resp); err != nil {
+		return nil, fmt.Errorf("failed to decode forwarded command response: %w", err)
+	}
+	if !resp.NotLeader && resp.Error == "" && resp.Result == nil {
+		return nil, errors.New("forwarded command response is missing the result")
+	}
+	return resp, nil
+}
+
+func (n *node) applyOnLeader(ctx context.Context, data []byte) *forwardResponse {
+	timeout := n.applyTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+		if timeout <= 0 {
+			return &forwardResponse{Error: ctx.Err().Error()}
+		}
+	}
+
+	future := n.raft.Apply(data, timeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) {
+			return &forwardResponse{NotLeader: true}
+		}
+		return &forwardResponse{Error: err.Error()}
+	}
+
+	res, ok := future.Response().(*result)
+	if !ok {
+		return &forwardResponse{Error: fmt.Sprintf("unexpected apply response type %T", future.Response())}
+	}
+	return &forwardResponse{
+		Index:  future.Index(),
+		Result: res,
+	}
+}
+
+// authorizePeer verifies that the certificate of a connecting peer is valid
+// for the host of a member of the cluster configuration. Certificates issued
+// by the same CA to clients that are not members are rejected, so that only
+// members can take part in the raft protocol or forward commands.
+func (n *node) authorizePeer(cert *x509.Certificate) error {
+	future := n.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return err
+	}
+	for _, server := range future.Configuration().Servers {
+		host, _, err := net.SplitHostPort(string(server.Address))
+		if err != nil {
+			continue
+		}
+		if cert.VerifyHostname(host) == nil {
+			return nil
+		}
+	}
+	return errors.New("certificate is not valid for the address of any member of the cluster")
+}
+
+// handleForward handles a command forwarded by a follower.
+func (n *node) handleForward(ctx context.Context, data []byte) []byte {
+	var resp *forwardResponse
+	if n.raft.State() == raft.Leader {
+		resp = n.applyOnLeader(ctx, data)
+	} else {
+		resp = &forwardResponse{NotLeader: true}
+	}
+
+	respData, err := json.Marshal(resp)
+	if err != nil {
+		respData, _ = json.Marshal(&forwardResponse{Error: err.Error()})
+	}
+	return respData
+}
+
+func (n *node) close() error {
+	var errs []error
+	if n.raft != nil {
+		if err := n.raft.Shutdown().Error(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// Closing the raft transport also closes the underlying transport.
+	switch {
+	case n.netTransport != nil:
+		if err := n.netTransport.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	case n.transport != nil:
+		if err := n.transport.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.store != nil {
+		if err := n.store.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,874 @@
+// Package raftstore implements a DataStore replicated across the SPIRE
+// servers of a cluster using the raft consensus protocol. Every server holds
+// the full state in memory; writes are proposed to the raft leader and
+// applied by every member in the same order, while reads are served from the
+// local state. Reads are not coordinated with the leader, so a member may not
+// yet observe writes made through other members.
+package raftstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/gofrs/uuid/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ datastore.DataStore = (*Plugin)(nil)
+
+// Plugin is a DataStore implementation replicated with raft.
+type Plugin struct {
+	log   logrus.FieldLogger
+	clock clock.Clock
+
+	mu   sync.RWMutex
+	node *node
+}
+
+// New creates a new raft datastore plugin.
+func New(log logrus.FieldLogger) *Plugin {
+	return &Plugin{
+		log:   log,
+		clock: clock.New(),
+	}
+}
+
+// Configure parses the HCL configuration and joins the raft cluster. The
+// datastore cannot be reconfigured once it has joined the cluster.
+func (ds *Plugin) Configure(_ context.Context, hclConfiguration string) error {
+	config, err := parseConfiguration(hclConfiguration)
+	if err != nil {
+		return err
+	}
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.node != nil {
+		return errors.New("raft datastore cannot be reconfigured")
+	}
+
+	ds.log.WithFields(logrus.Fields{
+		telemetry.Type:    DatabaseType,
+		telemetry.Address: config.BindAddress,
+	}).Info("Joining raft cluster")
+
+	node, err := newNode(ds.log, config)
+	if err != nil {
+		return err
+	}
+	ds.node = node
+	return nil
+}
+
+// Close leaves the raft cluster and closes the local raft storage.
+func (ds *Plugin) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if ds.node == nil {
+		return nil
+	}
+	err := ds.node.close()
+	ds.node = nil
+	return err
+}
+
+// AppendBundle append bundle contents to the existing bundle (by trust domain). If no existing one is present, create it.
+func (ds *Plugin) AppendBundle(ctx context.Context, b *common.Bundle) (*common.Bundle, error) {
+	return ds.applyBundle(ctx, opAppendBundle, b, nil)
+}
+
+// CountBundles can be used to count all existing bundles.
+func (ds *Plugin) CountBundles(context.Context) (count int32, err error) {
+	err = ds.read(func(s *state) error {
+		count = int32(s.bundles.len()) //nolint: gosec // count is bounded by the number of bundles
+		return nil
+	})
+	return count, err
+}
+
+// CreateBundle stores the given bundle
+func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (*common.Bundle, error) {
+	return ds.applyBundle(ctx, opCreateBundle, b, nil)
+}
+
+// DeleteBundle deletes the bundle with the matching TrustDomain. Any CACert data passed is ignored.
+func (ds *Plugin) DeleteBundle(ctx context.Context, trustDomainID string, mode datastore.DeleteMode) error {
+	_, err := ds.apply(ctx, opDeleteBundle, &trustDomainArgs{
+		TrustDomainID: trustDomainID,
+		Mode:          mode,
+	})
+	return err
+}
+
+// FetchBundle returns the bundle matching the specified Trust Domain.
+func (ds *Plugin) FetchBundle(_ context.Context, trustDomainID string) (resp *common.Bundle, err error) {
+	err = ds.read(func(s *state) error {
+		resp = s.fetchBundle(trustDomainID)
+		return nil
+	})
+	return resp, err
+}
+
+// ListBundles can be used to fetch all existing bundles.
+func (ds *Plugin) ListBundles(_ context.Context, req *datastore.ListBundlesRequest) (resp *datastore.ListBundlesResponse, err error) {
+	err = ds.read(func(s *state) (err error) {
+		resp, err = s.listBundles(req)
+		return err
+	})
+	return resp, err
+}
+
+// PruneBundle removes expired certs and keys from a bundle
+func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (bool, error) {
+	res, err := ds.apply(ctx, opPruneBundle, &trustDomainArgs{
+		TrustDomainID: trustDomainID,
+		ExpiresBefore: expiresBefore.UnixNano(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Flag, nil
+}
+
+// SetBundle Sets bundle contents. If no bundle exists for the trust domain, it is created.
+func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (*common.Bundle, error) {
+	return ds.applyBundle(ctx, opSetBundle, b, nil)
+}
+
+// UpdateBundle updates an existing bundle with the given CAs. Overwrites any
+// existing certificates.
+func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *common.BundleMask) (*common.Bundle, error) {
+	if mask == nil {
+		mask = protoutil.AllTrueCommonBundleMask
+	}
+	return ds.applyBundle(ctx, opUpdateBundle, b, mask)
+}
+
+// TaintX509CA taints an X.509 CA signed using the provided public key
+func (ds *Plugin) TaintX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToTaint string) error {
+	_, err := ds.apply(ctx, opTaintX509CA, &trustDomainArgs{
+		TrustDomainID: trustDomainID,
+		KeyID:         subjectKeyIDToTaint,
+	})
+	return err
+}
+
+// RevokeX509CA removes a Root CA from the bundle
+func (ds *Plugin) RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) error {
+	_, err := ds.apply(ctx, opRevokeX509CA, &trustDomainArgs{
+		TrustDomainID: trustDomainID,
+		KeyID:         subjectKeyIDToRevoke,
+	})
+	return err
+}
+
+// TaintJWTKey taints a JWT Authority key
+func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	return ds.applyJWTKey(ctx, opTaintJWTKey, trustDomainID, authorityID)
+}
+
+// RevokeJWTKey removes JWT key from the bundle
+func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	return ds.applyJWTKey(ctx, opRevokeJWTKey, trustDomainID, authorityID)
+}
+
+// CountRegistrationEntries counts all registrations (pagination available)
+func (ds *Plugin) CountRegistrationEntries(_ context.Context, req *datastore.CountRegistrationEntriesRequest) (count int32, err error) {
+	err = ds.read(func(s *state) (err error) {
+		count, err = s.countRegistrationEntries(req)
+		return err
+	})
+	return count, err
+}
+
+// CreateRegistrationEntry stores the given registration entry
+func (ds *Plugin) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
+	out, _, err := ds.createOrReturnRegistrationEntry(ctx, entry)
+	return out, err
+}
+
+// CreateOrReturnRegistrationEntry stores the given registration entry. If an
+// entry already exists with the same (parentID, spiffeID, selector) tuple,
+// that entry is returned instead.
+func (ds *Plugin) CreateOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, bool, error) {
+	return ds.createOrReturnRegistrationEntry(ctx, entry)
+}
+
+func (ds *Plugin) createOrReturnRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, bool, error) {
+	if err := validateRegistrationEntry(entry); err != nil {
+		return nil, false, err
+	}
+
+	// The entry ID is assigned here, before proposing the command, so that
+	// every member of the cluster stores the same ID.
+	entry = cloneEntry(entry)
+	if entry.EntryId == "" {
+		u, err := uuid.NewV4()
+		if err != nil {
+			return nil, false, err
+		}
+		entry.EntryId = u.String()
+	}
+
+	args, err := encodeEntryArgs(entry, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	res, err := ds.apply(ctx, opCreateRegistrationEntry, args)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := unmarshalProto(res.Value, new(common.RegistrationEntry))
+	if err != nil {
+		return nil, false, err
+	}
+	return out, res.Flag, nil
+}
+
+// DeleteRegistrationEntry deletes the given registration
+func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	res, err := ds.apply(ctx, opDeleteRegistrationEntry, &entryArgs{EntryID: entryID})
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.RegistrationEntry))
+}
+
+// FetchRegistrationEntry fetches an existing registration by entry ID
+func (ds *Plugin) FetchRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
+	entries, err := ds.FetchRegistrationEntries(ctx, []string{entryID})
+	if err != nil {
+		return nil, err
+	}
+	return entries[entryID], nil
+}
+
+// FetchRegistrationEntries fetches existing registrations by entry IDs
+func (ds *Plugin) FetchRegistrationEntries(_ context.Context, entryIDs []string) (entries map[string]*common.RegistrationEntry, err error) {
+	err = ds.read(func(s *state) error {
+		entries = s.fetchRegistrationEntries(entryIDs)
+		return nil
+	})
+	return entries, err
+}
+
+// ListRegistrationEntries lists all registrations (pagination available)
+func (ds *Plugin) ListRegistrationEntries(_ context.Context, req *datastore.ListRegistrationEntriesRequest) (resp *datastore.ListRegistrationEntriesResponse, err error) {
+	err = ds.read(func(s *state) (err error) {
+		resp, err = s.listRegistrationEntries(req)
+		return err
+	})
+	return resp, err
+}
+
+// PruneRegistrationEntries takes a registration entry message, and deletes all entries which have expired
+// before the date in the message
+func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) error {
+	_, err := ds.apply(ctx, opPruneRegistrationEntries, &pruneArgs{Before: expiresBefore.UnixNano()})
+	return err
+}
+
+// UpdateRegistrationEntry updates an existing registration entry
+func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (*common.RegistrationEntry, error) {
+	if err := validateRegistrationEntryForUpdate(e, mask); err != nil {
+		return nil, err
+	}
+
+	args, err := encodeEntryArgs(e, mask)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ds.apply(ctx, opUpdateRegistrationEntry, args)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.RegistrationEntry))
+}
+
+// ListRegistrationEntryEvents lists all registration entry events
+func (ds *Plugin) ListRegistrationEntryEvents(_ context.Context, req *datastore.ListRegistrationEntryEventsRequest) (resp *datastore.ListRegistrationEntryEventsResponse, err error) {
+	err = ds.read(func(s *state) error {
+		events, err := s.entryEvents.list(req.GreaterThanEventID, req.LessThanEventID)
+		if err != nil {
+			return err
+		}
+		resp = &datastore.ListRegistrationEntryEventsResponse{
+			Events: make([]datastore.RegistrationEntryEvent, 0, len(events)),
+		}
+		for _, e := range events {
+			resp.Events = append(resp.Events, datastore.RegistrationEntryEvent{
+				EventID: e.ID,
+				EntryID: e.Key,
+			})
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// PruneRegistrationEntryEvents deletes all registration entry events older than a specified duration (i.e. more than 24 hours old)
+func (ds *Plugin) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) error {
+	_, err := ds.apply(ctx, opPruneRegistrationEntryEvents, &eventArgs{OlderThan: olderThan})
+	return err
+}
+
+// FetchRegistrationEntryEvent fetches an existing registration entry event by event ID
+func (ds *Plugin) FetchRegistrationEntryEvent(_ context.Context, eventID uint) (event *datastore.RegistrationEntryEvent, err error) {
+	err = ds.read(func(s *state) error {
+		e, err := s.entryEvents.fetch(eventID)
+		if err != nil {
+			return err
+		}
+		event = &datastore.RegistrationEntryEvent{
+			EventID: e.ID,
+			EntryID: e.Key,
+		}
+		return nil
+	})
+	return event, err
+}
+
+// CreateRegistrationEntryEventForTesting creates a registration entry event. Used for unit testing.
+func (ds *Plugin) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) error {
+	_, err := ds.apply(ctx, opCreateRegistrationEntryEvent, &eventArgs{
+		EventID: event.EventID,
+		Key:     event.EntryID,
+	})
+	return err
+}
+
+// DeleteRegistrationEntryEventForTesting deletes a registration entry event by event ID. Used for unit testing.
+func (ds *Plugin) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) error {
+	_, err := ds.apply(ctx, opDeleteRegistrationEntryEvent, &eventArgs{EventID: eventID})
+	return err
+}
+
+// CountAttestedNodes counts all attested nodes
+func (ds *Plugin) CountAttestedNodes(_ context.Context, req *datastore.CountAttestedNodesRequest) (count int32, err error) {
+	err = ds.read(func(s *state) (err error) {
+		count, err = s.countAttestedNodes(req)
+		return err
+	})
+	return count, err
+}
+
+// CreateAttestedNode stores the given attested node
+func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (*common.AttestedNode, error) {
+	if node == nil {
+		return nil, newRaftError(codes.Unknown, "invalid request: missing attested node")
+	}
+	return ds.applyAttestedNode(ctx, opCreateAttestedNode, node, nil)
+}
+
+// DeleteAttestedNode deletes the given attested node and the associated node selectors.
+func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (*common.AttestedNode, error) {
+	res, err := ds.apply(ctx, opDeleteAttestedNode, &nodeArgs{SpiffeID: spiffeID})
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.AttestedNode))
+}
+
+// FetchAttestedNode fetches an existing attested node by SPIFFE ID
+func (ds *Plugin) FetchAttestedNode(_ context.Context, spiffeID string) (node *common.AttestedNode, err error) {
+	err = ds.read(func(s *state) error {
+		node = s.fetchAttestedNode(spiffeID)
+		if node != nil {
+			node.Selectors = s.getNodeSelectors(spiffeID)
+		}
+		return nil
+	})
+	return node, err
+}
+
+// ListAttestedNodes lists all attested nodes (pagination available)
+func (ds *Plugin) ListAttestedNodes(_ context.Context, req *datastore.ListAttestedNodesRequest) (resp *datastore.ListAttestedNodesResponse, err error) {
+	err = ds.read(func(s *state) (err error) {
+		resp, err = s.listAttestedNodes(req)
+		return err
+	})
+	return resp, err
+}
+
+// UpdateAttestedNode updates the given node's cert serial and expiration.
+func (ds *Plugin) UpdateAttestedNode(ctx context.Context, n *common.AttestedNode, mask *common.AttestedNodeMask) (*common.AttestedNode, error) {
+	if n == nil {
+		return nil, newRaftError(codes.Unknown, "invalid request: missing attested node")
+	}
+	if mask == nil {
+		mask = protoutil.AllTrueCommonAgentMask
+	}
+	return ds.applyAttestedNode(ctx, opUpdateAttestedNode, n, mask)
+}
+
+// ListAttestedNodeEvents lists all attested node events
+func (ds *Plugin) ListAttestedNodeEvents(_ context.Context, req *datastore.ListAttestedNodeEventsRequest) (resp *datastore.ListAttestedNodeEventsResponse, err error) {
+	err = ds.read(func(s *state) error {
+		events, err := s.nodeEvents.list(req.GreaterThanEventID, req.LessThanEventID)
+		if err != nil {
+			return err
+		}
+		resp = &datastore.ListAttestedNodeEventsResponse{
+			Events: make([]datastore.AttestedNodeEvent, 0, len(events)),
+		}
+		for _, e := range events {
+			resp.Events = append(resp.Events, datastore.AttestedNodeEvent{
+				EventID:  e.ID,
+				SpiffeID: e.Key,
+			})
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// PruneAttestedNodeEvents deletes all attested node events older than a specified duration (i.e. more than 24 hours old)
+func (ds *Plugin) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) error {
+	_, err := ds.apply(ctx, opPruneAttestedNodeEvents, &eventArgs{OlderThan: olderThan})
+	return err
+}
+
+// FetchAttestedNodeEvent fetches an existing attested node event by event ID
+func (ds *Plugin) FetchAttestedNodeEvent(_ context.Context, eventID uint) (event *datastore.AttestedNodeEvent, err error) {
+	err = ds.read(func(s *state) error {
+		e, err := s.nodeEvents.fetch(eventID)
+		if err != nil {
+			return err
+		}
+		event = &datastore.AttestedNodeEvent{
+			EventID:  e.ID,
+			SpiffeID: e.Key,
+		}
+		return nil
+	})
+	return event, err
+}
+
+// CreateAttestedNodeEventForTesting creates an attested node event. Used for unit testing.
+func (ds *Plugin) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) error {
+	_, err := ds.apply(ctx, opCreateAttestedNodeEvent, &eventArgs{
+		EventID: event.EventID,
+		Key:     event.SpiffeID,
+	})
+	return err
+}
+
+// DeleteAttestedNodeEventForTesting deletes an attested node event by event ID. Used for unit testing.
+func (ds *Plugin) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) error {
+	_, err := ds.apply(ctx, opDeleteAttestedNodeEvent, &eventArgs{EventID: eventID})
+	return err
+}
+
+// GetNodeSelectors gets node (agent) selectors by SPIFFE ID. Reads are always
+// served from the local state, regardless of the requested data consistency.
+func (ds *Plugin) GetNodeSelectors(_ context.Context, spiffeID string, _ datastore.DataConsistency) (selectors []*common.Selector, err error) {
+	err = ds.read(func(s *state) error {
+		selectors = s.getNodeSelectors(spiffeID)
+		return nil
+	})
+	return selectors, err
+}
+
+// ListNodeSelectors gets node (agent) selectors by SPIFFE ID
+func (ds *Plugin) ListNodeSelectors(_ context.Context, req *datastore.ListNodeSelectorsRequest) (resp *datastore.ListNodeSelectorsResponse, err error) {
+	err = ds.read(func(s *state) error {
+		resp = s.listNodeSelectors(req)
+		return nil
+	})
+	return resp, err
+}
+
+// SetNodeSelectors sets node (agent) selectors by SPIFFE ID, deleting old selectors first
+func (ds *Plugin) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) error {
+	_, err := ds.apply(ctx, opSetNodeSelectors, &nodeArgs{
+		SpiffeID:  spiffeID,
+		Selectors: toSelectors(selectors),
+	})
+	return err
+}
+
+// CreateJoinToken takes a Token message and stores it
+func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) error {
+	if token == nil || token.Token == "" || token.Expiry.IsZero() {
+		return errors.New("token and expiry are required")
+	}
+	_, err := ds.apply(ctx, opCreateJoinToken, &joinTokenArgs{
+		Token:  token.Token,
+		Expiry: token.Expiry.Unix(),
+	})
+	return err
+}
+
+// DeleteJoinToken deletes the matching join token
+func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) error {
+	_, err := ds.apply(ctx, opDeleteJoinToken, &joinTokenArgs{Token: token})
+	return err
+}
+
+// FetchJoinToken takes a Token message and returns one, populating the fields
+// we have knowledge of
+func (ds *Plugin) FetchJoinToken(_ context.Context, token string) (resp *datastore.JoinToken, err error) {
+	err = ds.read(func(s *state) error {
+		resp = s.fetchJoinToken(token)
+		return nil
+	})
+	return resp, err
+}
+
+// ListJoinTokens returns all the join tokens
+func (ds *Plugin) ListJoinTokens(context.Context) (resp []*datastore.JoinToken, err error) {
+	err = ds.read(func(s *state) error {
+		resp = s.listJoinTokens()
+		return nil
+	})
+	return resp, err
+}
+
+// PruneJoinTokens takes a Token message, and deletes all tokens which have expired
+// before the date in the message
+func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) error {
+	_, err := ds.apply(ctx, opPruneJoinTokens, &pruneArgs{Before: expiry.UnixNano()})
+	return err
+}
+
+// CreateFederationRelationship creates a new federation relationship. If the bundle endpoint
+// profile is 'https_spiffe' and the given federation relationship contains a bundle, the current
+// stored bundle is overridden.
+func (ds *Plugin) CreateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
+	if err := validateFederationRelationship(fr, protoutil.AllTrueFederationRelationshipMask); err != nil {
+		return nil, err
+	}
+
+	args, err := encodeFederationRelationship(fr)
+	if err != nil {
+		return nil, err
+	}
+	res, err := ds.apply(ctx, opCreateFederationRelationship, args)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFederationRelationshipResult(res)
+}
+
+// DeleteFederationRelationship deletes the federation relationship to the
+// given trust domain. The associated trust bundle is not deleted.
+func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) error {
+	if trustDomain.IsZero() {
+		return status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	_, err := ds.apply(ctx, opDeleteFederationRelationship, &federationRelationshipArgs{
+		Relationship: federationRelationship{TrustDomain: trustDomain.Name()},
+	})
+	return err
+}
+
+// FetchFederationRelationship fetches the federation relationship that matches
+// the given trust domain. If the federation relationship is not found, nil is returned.
+func (ds *Plugin) FetchFederationRelationship(_ context.Context, trustDomain spiffeid.TrustDomain) (fr *datastore.FederationRelationship, err error) {
+	if trustDomain.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	err = ds.read(func(s *state) (err error) {
+		fr, err = s.fetchFederationRelationship(trustDomain)
+		return err
+	})
+	return fr, err
+}
+
+// ListFederationRelationships can be used to list all existing federation relationships
+func (ds *Plugin) ListFederationRelationships(_ context.Context, req *datastore.ListFederationRelationshipsRequest) (resp *datastore.ListFederationRelationshipsResponse, err error) {
+	err = ds.read(func(s *state) (err error) {
+		resp, err = s.listFederationRelationships(req)
+		return err
+	})
+	return resp, err
+}
+
+// UpdateFederationRelationship updates the given federation relationship.
+// Attributes are only updated if the correspondent mask value is set to true.
+func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (*datastore.FederationRelationship, error) {
+	if err := validateFederationRelationship(fr, mask); err != nil {
+		return nil, err
+	}
+
+	args, err := encodeFederationRelationship(fr)
+	if err != nil {
+		return nil, err
+	}
+	if args.Mask, err = marshalProto(mask); err != nil {
+		return nil, err
+	}
+	res, err := ds.apply(ctx, opUpdateFederationRelationship, args)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFederationRelationshipResult(res)
+}
+
+// SetCAJournal sets the content of a CA journal. If the CA journal does not
+// exist, a new one is created.
+func (ds *Plugin) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (*datastore.CAJournal, error) {
+	if caJournal == nil {
+		return nil, status.Error(codes.InvalidArgument, "ca journal is required")
+	}
+
+	res, err := ds.apply(ctx, opSetCAJournal, &caJournalArgs{
+		ID:                    caJournal.ID,
+		Data:                  caJournal.Data,
+		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	caj := new(caJournalArgs)
+	if err := json.Unmarshal(res.Value, caj); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to decode result: %v", datastoreRaftErrorPrefix, err)
+	}
+	return &datastore.CAJournal{
+		ID:                    caj.ID,
+		Data:                  caj.Data,
+		ActiveX509AuthorityID: caj.ActiveX509AuthorityID,
+	}, nil
+}
+
+// FetchCAJournal fetches the CA journal that has the given active X509
+// authority domain. If the CA journal is not found, nil is returned.
+func (ds *Plugin) FetchCAJournal(_ context.Context, activeX509AuthorityID string) (caJournal *datastore.CAJournal, err error) {
+	if activeX509AuthorityID == "" {
+		return nil, status.Error(codes.InvalidArgument, "active X509 authority ID is required")
+	}
+
+	err = ds.read(func(s *state) error {
+		caJournal = s.fetchCAJournal(activeX509AuthorityID)
+		return nil
+	})
+	return caJournal, err
+}
+
+// PruneCAJournals prunes the CA journals that have all of their authorities
+// expired.
+func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefore int64) error {
+	_, err := ds.apply(ctx, opPruneCAJournals, &pruneArgs{Before: allAuthoritiesExpireBefore})
+	return err
+}
+
+// ListCAJournals returns all the CA journal records.
+func (ds *Plugin) ListCAJournals(context.Context) (caJournals []*datastore.CAJournal, err error) {
+	err = ds.read(func(s *state) error {
+		caJournals = s.listCAJournals()
+		return nil
+	})
+	return caJournals, err
+}
+
+// RevokeX509SVID records the revocation of an X509-SVID. An AlreadyExists
+// error is returned if the SVID issued by the same authority was already
+// revoked.
+func (ds *Plugin) RevokeX509SVID(ctx context.Context, revoked *datastore.RevokedX509SVID) (*datastore.RevokedX509SVID, error) {
+	if err := validateRevokedX509SVID(revoked); err != nil {
+		return nil, err
+	}
+	if _, err := ds.apply(ctx, opRevokeX509SVID, &revokedX509SVIDArgs{
+		AuthorityID:  revoked.AuthorityID,
+		SerialNumber: revoked.SerialNumber,
+		RevokedAt:    revoked.RevokedAt.UnixNano(),
+		ExpiresAt:    revoked.ExpiresAt.UnixNano(),
+	}); err != nil {
+		return nil, err
+	}
+	return &datastore.RevokedX509SVID{
+		AuthorityID:  revoked.AuthorityID,
+		SerialNumber: revoked.SerialNumber,
+		RevokedAt:    revoked.RevokedAt,
+		ExpiresAt:    revoked.ExpiresAt,
+	}, nil
+}
+
+// ListRevokedX509SVIDs returns all the revoked X509-SVIDs, ordered by
+// authority ID and serial number.
+func (ds *Plugin) ListRevokedX509SVIDs(context.Context) (revoked []*datastore.RevokedX509SVID, err error) {
+	err = ds.read(func(s *state) error {
+		revoked = s.listRevokedX509SVIDs()
+		return nil
+	})
+	return revoked, err
+}
+
+// PruneRevokedX509SVIDs deletes the revocations of X509-SVIDs known to have
+// expired before the given time.
+func (ds *Plugin) PruneRevokedX509SVIDs(ctx context.Context, expiresBefore time.Time) error {
+	_, err := ds.apply(ctx, opPruneRevokedX509SVIDs, &pruneArgs{Before: expiresBefore.UnixNano()})
+	return err
+}
+
+// apply proposes a write command to the cluster and returns its result once
+// it has been applied to the local state.
+func (ds *Plugin) apply(ctx context.Context, op op, args any) (*result, error) {
+	cmd, err := newCommand(op, args)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Now = ds.clock.Now().UnixNano()
+
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if ds.node == nil {
+		return nil, newRaftError(codes.FailedPrecondition, "datastore is not configured")
+	}
+
+	res, err := ds.node.apply(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// read calls fn with the local state, which may lag behind the leader.
+func (ds *Plugin) read(fn func(s *state) error) error {
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	if ds.node == nil {
+		return newRaftError(codes.FailedPrecondition, "datastore is not configured")
+	}
+	return ds.node.fsm.read(fn)
+}
+
+func (ds *Plugin) applyBundle(ctx context.Context, op op, b *common.Bundle, mask *common.BundleMask) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newRaftError(codes.Unknown, "missing bundle in request")
+	}
+
+	args := new(bundleArgs)
+	var err error
+	if args.Bundle, err = marshalProto(b); err != nil {
+		return nil, err
+	}
+	if mask != nil {
+		args.HasMask = true
+		if args.Mask, err = marshalProto(mask); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := ds.apply(ctx, op, args)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.Bundle))
+}
+
+func (ds *Plugin) applyJWTKey(ctx context.Context, op op, trustDomainID, authorityID string) (*common.PublicKey, error) {
+	res, err := ds.apply(ctx, op, &trustDomainArgs{
+		TrustDomainID: trustDomainID,
+		KeyID:         authorityID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.PublicKey))
+}
+
+func (ds *Plugin) applyAttestedNode(ctx context.Context, op op, n *common.AttestedNode, mask *common.AttestedNodeMask) (*common.AttestedNode, error) {
+	args := new(nodeArgs)
+	var err error
+	if args.Node, err = marshalProto(n); err != nil {
+		return nil, err
+	}
+	if mask != nil {
+		args.HasMask = true
+		if args.Mask, err = marshalProto(mask); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := ds.apply(ctx, op, args)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalProto(res.Value, new(common.AttestedNode))
+}
+
+func encodeEntryArgs(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) (*entryArgs, error) {
+	args := new(entryArgs)
+	var err error
+	if args.Entry, err = marshalProto(entry); err != nil {
+		return nil, err
+	}
+	if mask != nil {
+		args.HasMask = true
+		if args.Mask, err = marshalProto(mask); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func decodeFederationRelationshipResult(res *result) (*datastore.FederationRelationship, error) {
+	args := new(federationRelationshipArgs)
+	if err := json.Unmarshal(res.Value, args); err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to decode result: %v", datastoreRaftErrorPrefix, err)
+	}
+	return decodeFederationRelationship(args)
+}
+
+func validateRevokedX509SVID(revoked *datastore.RevokedX509SVID) error {
+	switch {
+	case revoked == nil:
+		return status.Error(codes.InvalidArgument, "revoked X509-SVID is required")
+	case revoked.AuthorityID == "":
+		return status.Error(codes.InvalidArgument, "authority ID is required")
+	case revoked.SerialNumber == "":
+		return status.Error(codes.InvalidArgument, "serial number is required")
+	case revoked.ExpiresAt.IsZero():
+		return status.Error(codes.InvalidArgument, "expiration time is required")
+	}
+	return nil
+}
+
+func validateFederationRelationship(fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) error {
+	if fr == nil {
+		return status.Error(codes.InvalidArgument, "federation relationship is nil")
+	}
+
+	if fr.TrustDomain.IsZero() {
+		return status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	if mask.BundleEndpointUrl && fr.BundleEndpointURL == nil {
+		return status.Error(codes.InvalidArgument, "bundle endpoint URL is required")
+	}
+
+	if mask.BundleEndpointProfile {
+		switch fr.BundleEndpointProfile {
+		case datastore.BundleEndpointWeb:
+		case datastore.BundleEndpointSPIFFE:
+			if fr.EndpointSPIFFEID.IsZero() {
+				return status.Error(codes.InvalidArgument, "bundle endpoint SPIFFE ID is required")
+			}
+		default:
+			return status.Errorf(codes.InvalidArgument, "unknown bundle endpoint profile type: %q", fr.BundleEndpointProfile)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,599 @@
+package raftstore
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var ctx = context.Background()
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		config string
+		expErr string
+	}{
+		{
+			name:   "literal database type",
+			config: `database_type = "sqlite3"`,
+			expErr: "malformed database type configuration",
+		},
+		{
+			name:   "unknown database type",
+			config: `database_type "aws_mysql" {}`,
+			expErr: "unknown database type: aws_mysql",
+		},
+		{
+			name:   "missing node ID",
+			config: `database_type "raft" { bind_address = "127.0.0.1:0" data_dir = "data" }`,
+			expErr: "node_id must be set",
+		},
+		{
+			name:   "missing bind address",
+			config: `database_type "raft" { node_id = "a" data_dir = "data" }`,
+			expErr: "bind_address must be set",
+		},
+		{
+			name:   "missing data dir",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" }`,
+			expErr: "data_dir must be set",
+		},
+		{
+			name:   "peers include this node",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" peers = { a = "127.0.0.1:1" } }`,
+			expErr: `peers must not include this node ("a")`,
+		},
+		{
+			name:   "invalid peer address",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" peers = { b = "nope" } }`,
+			expErr: `invalid address for peer "b"`,
+		},
+		{
+			name:   "invalid apply timeout",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" apply_timeout = "soon" }`,
+			expErr: "invalid apply_timeout",
+		},
+		{
+			name:   "invalid heartbeat timeout",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" heartbeat_timeout = "1ms" }`,
+			expErr: "heartbeat_timeout must be at least 10ms",
+		},
+		{
+			name:   "missing TLS",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" }`,
+			expErr: "tls must be set",
+		},
+		{
+			name:   "incomplete TLS",
+			config: `database_type "raft" { node_id = "a" bind_address = "127.0.0.1:0" data_dir = "data" tls { cert_file_path = "cert.pem" } }`,
+			expErr: "tls key_file_path must be set",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			log, _ := test.NewNullLogger()
+			err := New(log).Configure(ctx, tt.config)
+			require.ErrorContains(t, err, tt.expErr)
+		})
+	}
+}
+
+func TestIsRaftConfiguration(t *testing.T) {
+	assert.True(t, IsRaftConfiguration(`database_type "raft" { node_id = "a" }`))
+	assert.False(t, IsRaftConfiguration(`database_type = "raft"`))
+	assert.False(t, IsRaftConfiguration(`database_type = "sqlite3"`))
+	assert.False(t, IsRaftConfiguration(`database_type "aws_postgres" { region = "us-east-1" }`))
+	assert.False(t, IsRaftConfiguration(`{`))
+}
+
+func TestBundles(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+
+	ca := testca.New(t, spiffeid.RequireTrustDomainFromString("example.org"))
+	bundle := &common.Bundle{
+		TrustDomainId: "spiffe://example.org",
+		RootCas:       []*common.Certificate{{DerBytes: ca.X509Authorities()[0].Raw}},
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: "kid1", PkixBytes: []byte("key1"), NotAfter: 1000},
+		},
+	}
+	created, err := ds.CreateBundle(ctx, bundle)
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, bundle, created)
+
+	_, err = ds.CreateBundle(ctx, bundle)
+	spiretest.RequireGRPCStatus(t, err, codes.AlreadyExists, `datastore-raft: bundle for trust domain "spiffe://example.org" already exists`)
+
+	appended, err := ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId: "spiffe://example.org",
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: "kid2", PkixBytes: []byte("key2"), NotAfter: 3000},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, appended.JwtSigningKeys, 2)
+	assert.Equal(t, uint64(1), appended.SequenceNumber)
+
+	fetched, err := ds.FetchBundle(ctx, "spiffe://example.org")
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, appended, fetched)
+
+	fetched, err = ds.FetchBundle(ctx, "spiffe://other.org")
+	require.NoError(t, err)
+	assert.Nil(t, fetched)
+
+	changed, err := ds.PruneBundle(ctx, "spiffe://example.org", time.Unix(2000, 0))
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	count, err := ds.CountBundles(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), count)
+
+	err = ds.DeleteBundle(ctx, "spiffe://example.org", datastore.Restrict)
+	require.NoError(t, err)
+
+	err = ds.DeleteBundle(ctx, "spiffe://example.org", datastore.Restrict)
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "datastore-raft: record not found")
+}
+
+func TestRegistrationEntries(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+
+	var entryIDs []string
+	for i := range 3 {
+		entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			SpiffeId:  fmt.Sprintf("spiffe://example.org/workload-%d", i),
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "unix", Value: fmt.Sprintf("uid:%d", i)}},
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, entry.EntryId)
+		entryIDs = append(entryIDs, entry.EntryId)
+	}
+
+	existing, isExisting, err := ds.CreateOrReturnRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/workload-0",
+		ParentId:  "spiffe://example.org/agent",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:0"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, isExisting)
+	assert.Equal(t, entryIDs[0], existing.EntryId)
+
+	resp, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		Pagination: &datastore.Pagination{PageSize: 2},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+	assert.Equal(t, entryIDs[0], resp.Entries[0].EntryId)
+	assert.Equal(t, entryIDs[1], resp.Entries[1].EntryId)
+
+	resp, err = ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		Pagination: resp.Pagination,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, entryIDs[2], resp.Entries[0].EntryId)
+
+	resp, err = ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1"}, {Type: "unix", Value: "uid:2"}},
+			Match:     datastore.MatchAny,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Entries, 2)
+
+	updated, err := ds.UpdateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:     entryIDs[1],
+		X509SvidTtl: 60,
+	}, &common.RegistrationEntryMask{X509SvidTtl: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(60), updated.X509SvidTtl)
+	assert.Equal(t, int64(1), updated.RevisionNumber)
+
+	deleted, err := ds.DeleteRegistrationEntry(ctx, entryIDs[2])
+	require.NoError(t, err)
+	assert.Equal(t, entryIDs[2], deleted.EntryId)
+
+	fetched, err := ds.FetchRegistrationEntry(ctx, entryIDs[2])
+	require.NoError(t, err)
+	assert.Nil(t, fetched)
+
+	events, err := ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []datastore.RegistrationEntryEvent{
+		{EventID: 1, EntryID: entryIDs[0]},
+		{EventID: 2, EntryID: entryIDs[1]},
+		{EventID: 3, EntryID: entryIDs[2]},
+		{EventID: 4, EntryID: entryIDs[1]},
+		{EventID: 5, EntryID: entryIDs[2]},
+	}, events.Events)
+}
+
+func TestAttestedNodes(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+
+	node := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/agent",
+		AttestationDataType: "join_token",
+		CertSerialNumber:    "1",
+		CertNotAfter:        1000,
+	}
+	_, err := ds.CreateAttestedNode(ctx, node)
+	require.NoError(t, err)
+
+	_, err = ds.CreateAttestedNode(ctx, node)
+	spiretest.RequireGRPCStatusContains(t, err, codes.AlreadyExists, "already exists")
+
+	selectors := []*common.Selector{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}}
+	require.NoError(t, ds.SetNodeSelectors(ctx, node.SpiffeId, selectors))
+
+	fetched, err := ds.FetchAttestedNode(ctx, node.SpiffeId)
+	require.NoError(t, err)
+	assert.Equal(t, "1", fetched.CertSerialNumber)
+	assert.Len(t, fetched.Selectors, 2)
+
+	_, err = ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:         node.SpiffeId,
+		CertSerialNumber: "2",
+	}, &common.AttestedNodeMask{CertSerialNumber: true})
+	require.NoError(t, err)
+
+	resp, err := ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
+		BySelectorMatch: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+			Match:     datastore.Superset,
+		},
+		FetchSelectors: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Nodes, 1)
+	assert.Equal(t, "2", resp.Nodes[0].CertSerialNumber)
+
+	_, err = ds.DeleteAttestedNode(ctx, node.SpiffeId)
+	require.NoError(t, err)
+
+	events, err := ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{GreaterThanEventID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []datastore.AttestedNodeEvent{
+		{EventID: 3, SpiffeID: node.SpiffeId},
+		{EventID: 4, SpiffeID: node.SpiffeId},
+	}, events.Events)
+}
+
+func TestEventPruning(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+	clk := clock.NewMock(t)
+	ds.clock = clk
+
+	require.NoError(t, ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{EntryID: "old"}))
+	clk.Add(time.Hour)
+	require.NoError(t, ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{EntryID: "new"}))
+
+	require.NoError(t, ds.PruneRegistrationEntryEvents(ctx, 30*time.Minute))
+
+	events, err := ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []datastore.RegistrationEntryEvent{{EventID: 2, EntryID: "new"}}, events.Events)
+
+	_, err = ds.FetchRegistrationEntryEvent(ctx, 1)
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, "datastore-raft: record not found")
+}
+
+func TestJoinTokensAndFederationRelationships(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+
+	require.NoError(t, ds.CreateJoinToken(ctx, &datastore.JoinToken{Token: "token", Expiry: time.Unix(1000, 0)}))
+	token, err := ds.FetchJoinToken(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, &datastore.JoinToken{Token: "token", Expiry: time.Unix(1000, 0)}, token)
+	tokens, err := ds.ListJoinTokens(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*datastore.JoinToken{{Token: "token", Expiry: time.Unix(1000, 0)}}, tokens)
+	require.NoError(t, ds.PruneJoinTokens(ctx, time.Unix(2000, 0)))
+	token, err = ds.FetchJoinToken(ctx, "token")
+	require.NoError(t, err)
+	assert.Nil(t, token)
+
+	td := spiffeid.RequireTrustDomainFromString("other.org")
+	endpointURL, err := url.Parse("https://other.org/bundle")
+	require.NoError(t, err)
+	bundle := &common.Bundle{TrustDomainId: td.IDString(), RootCas: []*common.Certificate{{DerBytes: []byte("root")}}}
+	fr, err := ds.CreateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     endpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+		TrustDomainBundle:     bundle,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, td, fr.TrustDomain)
+	spiretest.AssertProtoEqual(t, bundle, fr.TrustDomainBundle)
+
+	fr, err = ds.UpdateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointProfile: datastore.BundleEndpointSPIFFE,
+		EndpointSPIFFEID:      spiffeid.RequireFromString("spiffe://other.org/server"),
+	}, &types.FederationRelationshipMask{BundleEndpointProfile: true})
+	require.NoError(t, err)
+	assert.Equal(t, endpointURL.String(), fr.BundleEndpointURL.String())
+	assert.Equal(t, "spiffe://other.org/server", fr.EndpointSPIFFEID.String())
+
+	resp, err := ds.ListFederationRelationships(ctx, &datastore.ListFederationRelationshipsRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.FederationRelationships, 1)
+
+	require.NoError(t, ds.DeleteFederationRelationship(ctx, td))
+	fr, err = ds.FetchFederationRelationship(ctx, td)
+	require.NoError(t, err)
+	assert.Nil(t, fr)
+}
+
+func TestRevokedX509SVIDs(t *testing.T) {
+	ds := newSingleNode(t, t.TempDir())
+
+	revoked1 := &datastore.RevokedX509SVID{AuthorityID: "authority1", SerialNumber: "2a", RevokedAt: time.Unix(1000, 0), ExpiresAt: time.Unix(2000, 0)}
+	revoked2 := &datastore.RevokedX509SVID{AuthorityID: "authority1", SerialNumber: "1b", RevokedAt: time.Unix(1000, 0), ExpiresAt: time.Unix(3000, 0)}
+	revoked3 := &datastore.RevokedX509SVID{AuthorityID: "authority2", SerialNumber: "2a", RevokedAt: time.Unix(1000, 0), ExpiresAt: time.Unix(3000, 0)}
+	resp, err := ds.RevokeX509SVID(ctx, revoked1)
+	require.NoError(t, err)
+	assert.Equal(t, revoked1, resp)
+	_, err = ds.RevokeX509SVID(ctx, revoked2)
+	require.NoError(t, err)
+	_, err = ds.RevokeX509SVID(ctx, revoked3)
+	require.NoError(t, err)
+
+	_, err = ds.RevokeX509SVID(ctx, revoked1)
+	spiretest.RequireGRPCStatus(t, err, codes.AlreadyExists, `datastore-raft: X509-SVID with serial number "2a" issued by authority "authority1" is already revoked`)
+	_, err = ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{SerialNumber: "3c"})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "authority ID is required")
+	_, err = ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{AuthorityID: "authority1", SerialNumber: "3c"})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "expiration time is required")
+
+	revoked, err := ds.ListRevokedX509SVIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*datastore.RevokedX509SVID{revoked2, revoked1, revoked3}, revoked)
+
+	require.NoError(t, ds.PruneRevokedX509SVIDs(ctx, time.Unix(2500, 0)))
+	revoked, err = ds.ListRevokedX509SVIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*datastore.RevokedX509SVID{revoked2, revoked3}, revoked)
+}
+
+func TestRestart(t *testing.T) {
+	dir := t.TempDir()
+	address := freeAddresses(t, 1)[0]
+	ds := newSingleNodeAt(t, dir, address)
+
+	_, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	require.NoError(t, err)
+	entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  "spiffe://example.org/agent",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:0"}},
+	})
+	require.NoError(t, err)
+	revoked := &datastore.RevokedX509SVID{AuthorityID: "authority", SerialNumber: "2a", RevokedAt: time.Unix(1000, 0), ExpiresAt: time.Unix(2000, 0)}
+	_, err = ds.RevokeX509SVID(ctx, revoked)
+	require.NoError(t, err)
+
+	// Take a snapshot so that the state is restored from both the snapshot
+	// and the log.
+	require.NoError(t, ds.node.raft.Snapshot().Error())
+	_, err = ds.SetCAJournal(ctx, &datastore.CAJournal{ActiveX509AuthorityID: "authority", Data: []byte("data")})
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+
+	ds = newSingleNodeAt(t, dir, address)
+	bundle, err := ds.FetchBundle(ctx, "spiffe://example.org")
+	require.NoError(t, err)
+	require.NotNil(t, bundle)
+	fetched, err := ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, entry, fetched)
+	revokedSVIDs, err := ds.ListRevokedX509SVIDs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*datastore.RevokedX509SVID{revoked}, revokedSVIDs)
+
+	// Wait for the log to be replayed before reading the CA journal, which
+	// was written after the snapshot.
+	require.Eventually(t, func() bool {
+		caJournal, err := ds.FetchCAJournal(ctx, "authority")
+		return err == nil && caJournal != nil
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestCluster(t *testing.T) {
+	addrs := freeAddresses(t, 3)
+	ids := []string{"a", "b", "c"}
+	pki := newTestPKI(t)
+
+	var nodes []*Plugin
+	for i, id := range ids {
+		peers := ""
+		for j, peer := range ids {
+			if j != i {
+				peers += fmt.Sprintf("%s = %q\n", peer, addrs[j])
+			}
+		}
+		nodes = append(nodes, newPlugin(t, fmt.Sprintf(`
+			database_type "raft" {
+				node_id = %q
+				bind_address = %q
+				data_dir = %q
+				heartbeat_timeout = "100ms"
+				peers = {
+					%s
+				}
+				%s
+			}`, id, addrs[i], filepath.Join(t.TempDir(), id), peers, pki.tlsBlock(t))))
+	}
+
+	// Write through every member, including the followers, which forward the
+	// commands to the leader.
+	for i, ds := range nodes {
+		_, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: fmt.Sprintf("spiffe://domain%d.test", i)})
+		require.NoError(t, err)
+	}
+
+	// Writes are replicated to every member.
+	for _, ds := range nodes {
+		require.Eventually(t, func() bool {
+			count, err := ds.CountBundles(ctx)
+			return err == nil && count == 3
+		}, 10*time.Second, 50*time.Millisecond)
+	}
+
+	// Errors are propagated back to the member that proposed the command.
+	for _, ds := range nodes {
+		_, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://domain0.test"})
+		spiretest.RequireGRPCStatus(t, err, codes.AlreadyExists, `datastore-raft: bundle for trust domain "spiffe://domain0.test" already exists`)
+	}
+}
+
+func TestUnknownPeerIsRejected(t *testing.T) {
+	pki := newTestPKI(t)
+	address := freeAddresses(t, 1)[0]
+	ds := newPlugin(t, fmt.Sprintf(`
+		database_type "raft" {
+			node_id = "a"
+			bind_address = %q
+			data_dir = %q
+			heartbeat_timeout = "100ms"
+			%s
+		}`, address, t.TempDir(), pki.tlsBlock(t)))
+
+	// Wait for the node to become the leader, so that a forwarded command
+	// would be applied if the peer was authorized.
+	_, err := ds.CreateBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://example.org"})
+	require.NoError(t, err)
+
+	forward := func(cert *x509.Certificate, key crypto.Signer) error {
+		conn, err := tls.Dial("tcp", address, &tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{cert.Raw}, PrivateKey: key}},
+			RootCAs:      util.NewCertPool(pki.caCert),
+			MinVersion:   tls.VersionTLS12,
+		})
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		cmd, err := json.Marshal(&command{Op: opDeleteBundle})
+		require.NoError(t, err)
+		if _, err := conn.Write([]byte{rpcForward}); err != nil {
+			return err
+		}
+		if err := writeFrame(conn, cmd); err != nil {
+			return err
+		}
+		_, err = readFrame(conn)
+		return err
+	}
+
+	// A certificate issued by the cluster CA for a host that is not a
+	// member of the cluster is rejected.
+	cert, key := testca.CreateX509Certificate(t, pki.caCert, pki.caKey, testca.WithIPAddresses(net.ParseIP("127.0.0.2")))
+	require.Error(t, forward(cert, key))
+
+	// A certificate for a member is accepted.
+	cert, key = testca.CreateX509Certificate(t, pki.caCert, pki.caKey, testca.WithIPAddresses(net.ParseIP("127.0.0.1")))
+	require.NoError(t, forward(cert, key))
+}
+
+func newSingleNode(t *testing.T, dir string) *Plugin {
+	return newSingleNodeAt(t, dir, "127.0.0.1:0")
+}
+
+func newSingleNodeAt(t *testing.T, dir, address string) *Plugin {
+	return newPlugin(t, fmt.Sprintf(`
+		database_type "raft" {
+			node_id = "a"
+			bind_address = %q
+			data_dir = %q
+			heartbeat_timeout = "100ms"
+			%s
+		}`, address, dir, newTestPKI(t).tlsBlock(t)))
+}
+
+func newPlugin(t *testing.T, config string) *Plugin {
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	require.NoError(t, ds.Configure(ctx, config))
+	t.Cleanup(func() {
+		assert.NoError(t, ds.Close())
+	})
+	return ds
+}
+
+type testPKI struct {
+	caCert *x509.Certificate
+	caKey  crypto.Signer
+	caPath string
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	caCert, caKey := testca.CreateCACertificate(t, nil, nil)
+	caPath := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caPath, pemutil.EncodeCertificate(caCert), 0o600))
+	return &testPKI{
+		caCert: caCert,
+		caKey:  caKey,
+		caPath: caPath,
+	}
+}
+
+// tlsBlock issues a certificate for the loopback address and returns the
+// tls configuration block that uses it.
+func (p *testPKI) tlsBlock(t *testing.T) string {
+	cert, key := testca.CreateX509Certificate(t, p.caCert, p.caKey, testca.WithIPAddresses(net.ParseIP("127.0.0.1")))
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	require.NoError(t, os.WriteFile(certPath, pemutil.EncodeCertificate(cert), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0o600))
+	return fmt.Sprintf(`tls {
+		cert_file_path = %q
+		key_file_path = %q
+		ca_file_path = %q
+	}`, certPath, keyPath, p.caPath)
+}
+
+func freeAddresses(t *testing.T, n int) []string {
+	var addrs []string
+	for range n {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addrs = append(addrs, l.Addr().String())
+		require.NoError(t, l.Close())
+	}
+	return addrs
+}
@@ -0,0 +1,164 @@
+package raftstore
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// snapshotVersion is the version of the snapshot encoding. It must be bumped
+// whenever the encoding changes in a non backwards compatible way.
+const snapshotVersion = 1
+
+// snapshotData is the serialized form of the state. Protobuf messages are
+// stored in their binary encoding.
+type snapshotData struct {
+	Version                 int                   `json:"version"`
+	Index                   uint64                `json:"index"`
+	Bundles                 snapshotTable         `json:"bundles"`
+	Entries                 snapshotTable         `json:"entries"`
+	Nodes                   snapshotTable         `json:"nodes"`
+	NodeSelectors           map[string][]selector `json:"node_selectors"`
+	JoinTokens              map[string]int64      `json:"join_tokens"`
+	FederationRelationships snapshotTable         `json:"federation_relationships"`
+	CAJournals              snapshotTable         `json:"ca_journals"`
+	RevokedX509SVIDs        []*revokedX509SVID    `json:"revoked_x509_svids,omitempty"`
+	EntryEvents             snapshotEventLog      `json:"entry_events"`
+	NodeEvents              snapshotEventLog      `json:"node_events"`
+}
+
+type snapshotTable struct {
+	NextID uint64        `json:"next_id"`
+	Rows   []snapshotRow `json:"rows"`
+}
+
+type snapshotRow struct {
+	ID    uint64 `json:"id"`
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type snapshotEventLog struct {
+	NextID uint    `json:"next_id"`
+	Events []event `json:"events"`
+}
+
+func newSnapshotData(s *state, index uint64) *snapshotData {
+	data := &snapshotData{
+		Version:                 snapshotVersion,
+		Index:                   index,
+		Bundles:                 encodeTable(s.bundles, encodeProto[*common.Bundle]),
+		Entries:                 encodeTable(s.entries, encodeProto[*common.RegistrationEntry]),
+		Nodes:                   encodeTable(s.nodes, encodeProto[*common.AttestedNode]),
+		NodeSelectors:           make(map[string][]selector, len(s.nodeSelectors)),
+		JoinTokens:              s.joinTokens,
+		FederationRelationships: encodeTable(s.federationRelationships, encodeJSON[*federationRelationship]),
+		CAJournals:              encodeTable(s.caJournals, encodeJSON[*caJournal]),
+		RevokedX509SVIDs:        s.sortedRevokedX509SVIDs(),
+		EntryEvents:             snapshotEventLog{NextID: s.entryEvents.nextID, Events: s.entryEvents.events},
+		NodeEvents:              snapshotEventLog{NextID: s.nodeEvents.nextID, Events: s.nodeEvents.events},
+	}
+	for spiffeID, selectors := range s.nodeSelectors {
+		data.NodeSelectors[spiffeID] = toSelectors(selectors)
+	}
+	return data
+}
+
+func (d *snapshotData) toState(log logrus.FieldLogger) (*state, error) {
+	if d.Version != snapshotVersion {
+		return nil, status.Errorf(codes.Internal, "%s: unsupported snapshot version %d", datastoreRaftErrorPrefix, d.Version)
+	}
+
+	s := newState(log)
+	if err := decodeTable(s.bundles, d.Bundles, decodeProto[common.Bundle]); err != nil {
+		return nil, err
+	}
+	if err := decodeTable(s.entries, d.Entries, decodeProto[common.RegistrationEntry]); err != nil {
+		return nil, err
+	}
+	if err := decodeTable(s.nodes, d.Nodes, decodeProto[common.AttestedNode]); err != nil {
+		return nil, err
+	}
+	if err := decodeTable(s.federationRelationships, d.FederationRelationships, decodeJSON[federationRelationship]); err != nil {
+		return nil, err
+	}
+	if err := decodeTable(s.caJournals, d.CAJournals, decodeJSON[caJournal]); err != nil {
+		return nil, err
+	}
+	for spiffeID, selectors := range d.NodeSelectors {
+		s.nodeSelectors[spiffeID] = fromSelectors(selectors)
+	}
+	for token, expiry := range d.JoinTokens {
+		s.joinTokens[token] = expiry
+	}
+	for _, revoked := range d.RevokedX509SVIDs {
+		s.revokedX509SVIDs[revoked.key()] = revoked
+	}
+	s.entryEvents = &eventLog{nextID: d.EntryEvents.NextID, events: d.EntryEvents.Events}
+	s.nodeEvents = &eventLog{nextID: d.NodeEvents.NextID, events: d.NodeEvents.Events}
+	return s, nil
+}
+
+func encodeTable[V any](t *table[V], encode func(V) []byte) snapshotTable {
+	st := snapshotTable{
+		NextID: t.nextID,
+		Rows:   make([]snapshotRow, 0, len(t.order)),
+	}
+	for _, r := range t.order {
+		st.Rows = append(st.Rows, snapshotRow{
+			ID:    r.id,
+			Key:   r.key,
+			Value: encode(r.value),
+		})
+	}
+	return st
+}
+
+func decodeTable[V any](t *table[V], st snapshotTable, decode func([]byte) (V, error)) error {
+	for _, r := range st.Rows {
+		value, err := decode(r.Value)
+		if err != nil {
+			return status.Errorf(codes.Internal, "%s: failed to decode snapshot row %q: %v", datastoreRaftErrorPrefix, r.Key, err)
+		}
+		t.restore(r.ID, r.Key, value)
+	}
+	t.nextID = max(t.nextID, st.NextID)
+	return nil
+}
+
+// encodeProto encodes a stored message. Stored messages were decoded from a
+// command, so encoding them cannot fail.
+func encodeProto[M proto.Message](m M) []byte {
+	data, _ := proto.Marshal(m)
+	return data
+}
+
+func decodeProto[T any, M interface {
+	*T
+	proto.Message
+}](data []byte) (M, error) {
+	m := M(new(T))
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// encodeJSON encodes a stored model. Models only hold plain fields, so
+// encoding them cannot fail.
+func encodeJSON[V any](v V) []byte {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+func decodeJSON[T any](data []byte) (*T, error) {
+	v := new(T)
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
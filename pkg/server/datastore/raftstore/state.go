@@ -0,0 +1,1433 @@
+package raftstore
+
+import (
+	"cmp"
+	"crypto/x509"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/journal"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// federationRelationship is the stored form of a federation relationship.
+// The trust domain bundle is stored separately in the bundles table.
+type federationRelationship struct {
+	TrustDomain           string `json:"trust_domain"`
+	BundleEndpointURL     string `json:"bundle_endpoint_url"`
+	BundleEndpointProfile string `json:"bundle_endpoint_profile"`
+	EndpointSPIFFEID      string `json:"endpoint_spiffe_id,omitempty"`
+}
+
+// caJournal is the stored form of a CA journal. The journal ID is the ID of
+// the row in the CA journals table.
+type caJournal struct {
+	Data                  []byte `json:"data"`
+	ActiveX509AuthorityID string `json:"active_x509_authority_id"`
+}
+
+// revokedX509SVID is the stored form of a revoked X509-SVID. Times are stored
+// as nanoseconds since the Unix epoch.
+type revokedX509SVID struct {
+	AuthorityID  string `json:"authority_id"`
+	SerialNumber string `json:"serial_number"`
+	RevokedAt    int64  `json:"revoked_at"`
+	ExpiresAt    int64  `json:"expires_at"`
+}
+
+// revokedX509SVIDKey identifies a revoked X509-SVID, since serial numbers are
+// only unique for a given issuer.
+type revokedX509SVIDKey struct {
+	authorityID  string
+	serialNumber string
+}
+
+func (r *revokedX509SVID) key() revokedX509SVIDKey {
+	return revokedX509SVIDKey{authorityID: r.AuthorityID, serialNumber: r.SerialNumber}
+}
+
+// event is a registration entry or attested node event. The key holds the
+// entry ID or the agent SPIFFE ID respectively.
+type event struct {
+	ID        uint   `json:"id"`
+	Key       string `json:"key"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// eventLog is an ordered log of events.
+type eventLog struct {
+	nextID uint
+	events []event
+}
+
+func (l *eventLog) create(eventID uint, key string, now time.Time) error {
+	if eventID == 0 {
+		l.nextID++
+		eventID = l.nextID
+	} else if eventID > l.nextID {
+		l.nextID = eventID
+	}
+
+	i := sort.Search(len(l.events), func(i int) bool {
+		return l.events[i].ID >= eventID
+	})
+	if i < len(l.events) && l.events[i].ID == eventID {
+		return newAlreadyExistsError("event %d already exists", eventID)
+	}
+	l.events = slices.Insert(l.events, i, event{
+		ID:        eventID,
+		Key:       key,
+		CreatedAt: now.UnixNano(),
+	})
+	return nil
+}
+
+func (l *eventLog) fetch(eventID uint) (event, error) {
+	i := sort.Search(len(l.events), func(i int) bool {
+		return l.events[i].ID >= eventID
+	})
+	if i == len(l.events) || l.events[i].ID != eventID {
+		return event{}, newNotFoundError()
+	}
+	return l.events[i], nil
+}
+
+func (l *eventLog) delete(eventID uint) {
+	l.events = slices.DeleteFunc(l.events, func(e event) bool {
+		return e.ID == eventID
+	})
+}
+
+func (l *eventLog) list(greaterThanEventID, lessThanEventID uint) ([]event, error) {
+	if greaterThanEventID != 0 && lessThanEventID != 0 {
+		return nil, fmt.Errorf("%s: can't set both greater and less than event id", datastoreRaftErrorPrefix)
+	}
+
+	var events []event
+	for _, e := range l.events {
+		if greaterThanEventID != 0 && e.ID <= greaterThanEventID {
+			continue
+		}
+		if lessThanEventID != 0 && e.ID >= lessThanEventID {
+			break
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+func (l *eventLog) prune(olderThan time.Time) {
+	l.events = slices.DeleteFunc(l.events, func(e event) bool {
+		return e.CreatedAt < olderThan.UnixNano()
+	})
+}
+
+func (l *eventLog) clone() *eventLog {
+	return &eventLog{
+		nextID: l.nextID,
+		events: slices.Clone(l.events),
+	}
+}
+
+// state is the replicated state of the datastore. All the write operations
+// are deterministic given the command arguments, so every member of the
+// cluster converges to the same state when applying the same log. Stored
+// values are never mutated in place; writes always store a new value so that
+// snapshots can be taken from a shallow copy of the state.
+type state struct {
+	log logrus.FieldLogger
+
+	bundles                 *table[*common.Bundle]
+	entries                 *table[*common.RegistrationEntry]
+	nodes                   *table[*common.AttestedNode]
+	nodeSelectors           map[string][]*common.Selector
+	joinTokens              map[string]int64
+	federationRelationships *table[*federationRelationship]
+	caJournals              *table[*caJournal]
+	revokedX509SVIDs        map[revokedX509SVIDKey]*revokedX509SVID
+	entryEvents             *eventLog
+	nodeEvents              *eventLog
+}
+
+func newState(log logrus.FieldLogger) *state {
+	return &state{
+		log:                     log,
+		bundles:                 newTable[*common.Bundle](),
+		entries:                 newTable[*common.RegistrationEntry](),
+		nodes:                   newTable[*common.AttestedNode](),
+		nodeSelectors:           make(map[string][]*common.Selector),
+		joinTokens:              make(map[string]int64),
+		federationRelationships: newTable[*federationRelationship](),
+		caJournals:              newTable[*caJournal](),
+		revokedX509SVIDs:        make(map[revokedX509SVIDKey]*revokedX509SVID),
+		entryEvents:             new(eventLog),
+		nodeEvents:              new(eventLog),
+	}
+}
+
+func (s *state) clone() *state {
+	return &state{
+		log:                     s.log,
+		bundles:                 s.bundles.clone(),
+		entries:                 s.entries.clone(),
+		nodes:                   s.nodes.clone(),
+		nodeSelectors:           maps.Clone(s.nodeSelectors),
+		joinTokens:              maps.Clone(s.joinTokens),
+		federationRelationships: s.federationRelationships.clone(),
+		caJournals:              s.caJournals.clone(),
+		revokedX509SVIDs:        maps.Clone(s.revokedX509SVIDs),
+		entryEvents:             s.entryEvents.clone(),
+		nodeEvents:              s.nodeEvents.clone(),
+	}
+}
+
+// Bundles
+
+func (s *state) createBundle(b *common.Bundle) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newRaftError(codes.Unknown, "missing bundle in request")
+	}
+	if _, ok := s.bundles.get(b.TrustDomainId); ok {
+		return nil, newAlreadyExistsError("bundle for trust domain %q already exists", b.TrustDomainId)
+	}
+	s.bundles.insert(b.TrustDomainId, cloneBundle(b))
+	return b, nil
+}
+
+func (s *state) updateBundle(newBundle *common.Bundle, mask *common.BundleMask) (*common.Bundle, error) {
+	if newBundle == nil {
+		return nil, newRaftError(codes.Unknown, "missing bundle in request")
+	}
+	current, ok := s.bundles.get(newBundle.TrustDomainId)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+
+	if mask == nil {
+		mask = protoutil.AllTrueCommonBundleMask
+	}
+
+	bundle := cloneBundle(current)
+	if mask.RefreshHint {
+		bundle.RefreshHint = newBundle.RefreshHint
+	}
+	if mask.RootCas {
+		bundle.RootCas = newBundle.RootCas
+	}
+	if mask.JwtSigningKeys {
+		bundle.JwtSigningKeys = newBundle.JwtSigningKeys
+	}
+	if mask.SequenceNumber {
+		bundle.SequenceNumber = newBundle.SequenceNumber
+	}
+
+	s.bundles.set(bundle.TrustDomainId, cloneBundle(bundle))
+	return bundle, nil
+}
+
+func (s *state) setBundle(b *common.Bundle) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newRaftError(codes.Unknown, "missing bundle in request")
+	}
+	if _, ok := s.bundles.get(b.TrustDomainId); !ok {
+		return s.createBundle(b)
+	}
+	return s.updateBundle(b, nil)
+}
+
+func (s *state) appendBundle(b *common.Bundle) (*common.Bundle, error) {
+	if b == nil {
+		return nil, newRaftError(codes.Unknown, "missing bundle in request")
+	}
+	current, ok := s.bundles.get(b.TrustDomainId)
+	if !ok {
+		return s.createBundle(b)
+	}
+
+	bundle, changed := bundleutil.MergeBundles(current, b)
+	if changed {
+		bundle.SequenceNumber++
+		s.bundles.set(bundle.TrustDomainId, cloneBundle(bundle))
+	}
+	return bundle, nil
+}
+
+func (s *state) deleteBundle(trustDomainID string, mode datastore.DeleteMode, now time.Time) error {
+	if _, ok := s.bundles.get(trustDomainID); !ok {
+		return newNotFoundError()
+	}
+
+	var federated []*row[*common.RegistrationEntry]
+	for _, r := range s.entries.after(0) {
+		if slices.Contains(r.value.FederatesWith, trustDomainID) {
+			federated = append(federated, r)
+		}
+	}
+
+	if len(federated) > 0 {
+		switch mode {
+		case datastore.Delete:
+			for _, r := range federated {
+				s.entries.delete(r.key)
+				if err := s.entryEvents.create(0, r.key, now); err != nil {
+					return err
+				}
+			}
+		case datastore.Dissociate:
+			for _, r := range federated {
+				entry := cloneEntry(r.value)
+				entry.FederatesWith = slices.DeleteFunc(entry.FederatesWith, func(td string) bool {
+					return td == trustDomainID
+				})
+				s.entries.set(r.key, entry)
+				if err := s.entryEvents.create(0, r.key, now); err != nil {
+					return err
+				}
+			}
+		default:
+			return newRaftError(codes.FailedPrecondition, "cannot delete bundle; federated with %d registration entries", len(federated))
+		}
+	}
+
+	s.bundles.delete(trustDomainID)
+	return nil
+}
+
+func (s *state) fetchBundle(trustDomainID string) *common.Bundle {
+	bundle, ok := s.bundles.get(trustDomainID)
+	if !ok {
+		return nil
+	}
+	return cloneBundle(bundle)
+}
+
+func (s *state) listBundles(req *datastore.ListBundlesRequest) (*datastore.ListBundlesResponse, error) {
+	bundles, p, err := paginate(s.bundles, req.Pagination, func(*common.Bundle) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListBundlesResponse{
+		Pagination: p,
+	}
+	for _, bundle := range bundles {
+		resp.Bundles = append(resp.Bundles, cloneBundle(bundle))
+	}
+	return resp, nil
+}
+
+func (s *state) pruneBundle(trustDomainID string, expiresBefore time.Time) (bool, error) {
+	current, ok := s.bundles.get(trustDomainID)
+	if !ok {
+		// No bundle to prune
+		return false, nil
+	}
+
+	newBundle, changed, err := bundleutil.PruneBundle(current, expiresBefore, s.log)
+	if err != nil {
+		return false, fmt.Errorf("prune failed: %w", err)
+	}
+
+	// Update only if bundle was modified
+	if changed {
+		newBundle.SequenceNumber = current.SequenceNumber + 1
+		if _, err := s.updateBundle(newBundle, nil); err != nil {
+			return false, fmt.Errorf("unable to write new bundle: %w", err)
+		}
+	}
+
+	return changed, nil
+}
+
+func (s *state) getBundle(trustDomainID string) (*common.Bundle, error) {
+	bundle, ok := s.bundles.get(trustDomainID)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+	return cloneBundle(bundle), nil
+}
+
+func (s *state) taintX509CA(trustDomainID string, subjectKeyIDToTaint string) error {
+	bundle, err := s.getBundle(trustDomainID)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, eachRootCA := range bundle.RootCas {
+		x509CA, err := x509.ParseCertificate(eachRootCA.DerBytes)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to parse rootCA: %v", err)
+		}
+
+		caSubjectKeyID := x509util.SubjectKeyIDToString(x509CA.SubjectKeyId)
+		if subjectKeyIDToTaint != caSubjectKeyID {
+			continue
+		}
+
+		if eachRootCA.TaintedKey {
+			return status.Errorf(codes.InvalidArgument, "root CA is already tainted")
+		}
+
+		found = true
+		eachRootCA.TaintedKey = true
+	}
+
+	if !found {
+		return status.Error(codes.NotFound, "no ca found with provided subject key ID")
+	}
+
+	bundle.SequenceNumber++
+	_, err = s.updateBundle(bundle, nil)
+	return err
+}
+
+func (s *state) revokeX509CA(trustDomainID string, subjectKeyIDToRevoke string) error {
+	bundle, err := s.getBundle(trustDomainID)
+	if err != nil {
+		return err
+	}
+
+	keyFound := false
+	var rootCAs []*common.Certificate
+	for _, ca := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(ca.DerBytes)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to parse root CA: %v", err)
+		}
+
+		caSubjectKeyID := x509util.SubjectKeyIDToString(cert.SubjectKeyId)
+		if subjectKeyIDToRevoke == caSubjectKeyID {
+			if !ca.TaintedKey {
+				return status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted root CA")
+			}
+			keyFound = true
+			continue
+		}
+
+		rootCAs = append(rootCAs, ca)
+	}
+
+	if !keyFound {
+		return status.Error(codes.NotFound, "no root CA found with provided subject key ID")
+	}
+
+	bundle.RootCas = rootCAs
+	bundle.SequenceNumber++
+
+	if _, err := s.updateBundle(bundle, nil); err != nil {
+		return status.Errorf(codes.Internal, "failed to update bundle: %v", err)
+	}
+	return nil
+}
+
+func (s *state) taintJWTKey(trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	bundle, err := s.getBundle(trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taintedKey *common.PublicKey
+	for _, jwtKey := range bundle.JwtSigningKeys {
+		if jwtKey.Kid != authorityID {
+			continue
+		}
+
+		if jwtKey.TaintedKey {
+			return nil, status.Error(codes.InvalidArgument, "key is already tainted")
+		}
+
+		// Check if a JWT Key with the provided keyID was already
+		// tainted in this loop. This is purely defensive since we do not
+		// allow to have repeated key IDs.
+		if taintedKey != nil {
+			return nil, status.Error(codes.Internal, "another JWT Key found with the same KeyID")
+		}
+		taintedKey = jwtKey
+		jwtKey.TaintedKey = true
+	}
+
+	if taintedKey == nil {
+		return nil, status.Error(codes.NotFound, "no JWT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if _, err := s.updateBundle(bundle, nil); err != nil {
+		return nil, err
+	}
+	return taintedKey, nil
+}
+
+func (s *state) revokeJWTKey(trustDomainID string, authorityID string) (*common.PublicKey, error) {
+	bundle, err := s.getBundle(trustDomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []*common.PublicKey
+	var revokedKey *common.PublicKey
+	for _, key := range bundle.JwtSigningKeys {
+		if key.Kid == authorityID {
+			// Check if a JWT Key with the provided keyID was already
+			// found in this loop. This is purely defensive since we do not
+			// allow to have repeated key IDs.
+			if revokedKey != nil {
+				return nil, status.Error(codes.Internal, "another key found with the same KeyID")
+			}
+
+			if !key.TaintedKey {
+				return nil, status.Error(codes.InvalidArgument, "it is not possible to revoke an untainted key")
+			}
+
+			revokedKey = key
+			continue
+		}
+		publicKeys = append(publicKeys, key)
+	}
+	bundle.JwtSigningKeys = publicKeys
+
+	if revokedKey == nil {
+		return nil, status.Error(codes.NotFound, "no JWT Key found with provided key ID")
+	}
+
+	bundle.SequenceNumber++
+	if _, err := s.updateBundle(bundle, nil); err != nil {
+		return nil, err
+	}
+	return revokedKey, nil
+}
+
+// Nodes
+
+func (s *state) createAttestedNode(node *common.AttestedNode, now time.Time) (*common.AttestedNode, error) {
+	if node == nil {
+		return nil, newRaftError(codes.Unknown, "invalid request: missing attested node")
+	}
+	if _, ok := s.nodes.get(node.SpiffeId); ok {
+		return nil, newAlreadyExistsError("attested node %q already exists", node.SpiffeId)
+	}
+
+	stored := &common.AttestedNode{
+		SpiffeId:            node.SpiffeId,
+		AttestationDataType: node.AttestationDataType,
+		CertSerialNumber:    node.CertSerialNumber,
+		CertNotAfter:        node.CertNotAfter,
+		NewCertSerialNumber: node.NewCertSerialNumber,
+		NewCertNotAfter:     node.NewCertNotAfter,
+		CanReattest:         node.CanReattest,
+	}
+	s.nodes.insert(node.SpiffeId, stored)
+
+	if err := s.nodeEvents.create(0, node.SpiffeId, now); err != nil {
+		return nil, err
+	}
+	return cloneNode(stored), nil
+}
+
+func (s *state) fetchAttestedNode(spiffeID string) *common.AttestedNode {
+	node, ok := s.nodes.get(spiffeID)
+	if !ok {
+		return nil
+	}
+	return cloneNode(node)
+}
+
+func (s *state) countAttestedNodes(req *datastore.CountAttestedNodesRequest) (int32, error) {
+	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) == 0 {
+		return -1, status.Error(codes.InvalidArgument, "cannot list by empty selectors set")
+	}
+
+	match, err := s.attestedNodeFilter(&datastore.ListAttestedNodesRequest{
+		ByAttestationType: req.ByAttestationType,
+		ByBanned:          req.ByBanned,
+		ByExpiresBefore:   req.ByExpiresBefore,
+		BySelectorMatch:   req.BySelectorMatch,
+		ByCanReattest:     req.ByCanReattest,
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	var count int32
+	for _, r := range s.nodes.after(0) {
+		if match(r.value) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *state) listAttestedNodes(req *datastore.ListAttestedNodesRequest) (*datastore.ListAttestedNodesResponse, error) {
+	if req.Pagination != nil && req.Pagination.PageSize == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+	}
+	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot list by empty selectors set")
+	}
+
+	match, err := s.attestedNodeFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, p, err := paginate(s.nodes, req.Pagination, match)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListAttestedNodesResponse{
+		Nodes:      make([]*common.AttestedNode, 0, len(nodes)),
+		Pagination: p,
+	}
+	for _, node := range nodes {
+		node = cloneNode(node)
+		if req.FetchSelectors {
+			node.Selectors = cloneSelectors(s.nodeSelectors[node.SpiffeId])
+		}
+		resp.Nodes = append(resp.Nodes, node)
+	}
+	return resp, nil
+}
+
+func (s *state) attestedNodeFilter(req *datastore.ListAttestedNodesRequest) (func(*common.AttestedNode) bool, error) {
+	if req.BySelectorMatch != nil {
+		if err := validateMatchBehavior(req.BySelectorMatch.Match); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(node *common.AttestedNode) bool {
+		if !req.ByExpiresBefore.IsZero() && node.CertNotAfter >= req.ByExpiresBefore.Unix() {
+			return false
+		}
+		if req.ByAttestationType != "" && node.AttestationDataType != req.ByAttestationType {
+			return false
+		}
+		// An attested node is banned when its serial number is empty.
+		if req.ByBanned != nil && *req.ByBanned != (node.CertSerialNumber == "") {
+			return false
+		}
+		if req.ByCanReattest != nil && *req.ByCanReattest != node.CanReattest {
+			return false
+		}
+		if req.BySelectorMatch != nil {
+			have := selectorKeys(s.nodeSelectors[node.SpiffeId])
+			want := selectorKeys(req.BySelectorMatch.Selectors)
+			if !matchSet(have, want, req.BySelectorMatch.Match) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (s *state) updateAttestedNode(n *common.AttestedNode, mask *common.AttestedNodeMask, now time.Time) (*common.AttestedNode, error) {
+	if n == nil {
+		return nil, newRaftError(codes.Unknown, "invalid request: missing attested node")
+	}
+	current, ok := s.nodes.get(n.SpiffeId)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+
+	if mask == nil {
+		mask = protoutil.AllTrueCommonAgentMask
+	}
+
+	node := cloneNode(current)
+	if mask.CertNotAfter {
+		node.CertNotAfter = n.CertNotAfter
+	}
+	if mask.CertSerialNumber {
+		node.CertSerialNumber = n.CertSerialNumber
+	}
+	if mask.NewCertNotAfter {
+		node.NewCertNotAfter = n.NewCertNotAfter
+	}
+	if mask.NewCertSerialNumber {
+		node.NewCertSerialNumber = n.NewCertSerialNumber
+	}
+	if mask.CanReattest {
+		node.CanReattest = n.CanReattest
+	}
+	s.nodes.set(node.SpiffeId, node)
+
+	if err := s.nodeEvents.create(0, node.SpiffeId, now); err != nil {
+		return nil, err
+	}
+	return cloneNode(node), nil
+}
+
+func (s *state) deleteAttestedNode(spiffeID string, now time.Time) (*common.AttestedNode, error) {
+	node, ok := s.nodes.get(spiffeID)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+
+	delete(s.nodeSelectors, spiffeID)
+	s.nodes.delete(spiffeID)
+
+	if err := s.nodeEvents.create(0, spiffeID, now); err != nil {
+		return nil, err
+	}
+	return cloneNode(node), nil
+}
+
+func (s *state) setNodeSelectors(spiffeID string, selectors []*common.Selector, now time.Time) error {
+	seen := make(map[selectorKey]struct{}, len(selectors))
+	for _, selector := range selectors {
+		key := selectorKey{Type: selector.Type, Value: selector.Value}
+		if _, ok := seen[key]; ok {
+			return newAlreadyExistsError("duplicate node selector %s:%s", selector.Type, selector.Value)
+		}
+		seen[key] = struct{}{}
+	}
+
+	if len(selectors) == 0 {
+		delete(s.nodeSelectors, spiffeID)
+	} else {
+		s.nodeSelectors[spiffeID] = cloneSelectors(selectors)
+	}
+
+	return s.nodeEvents.create(0, spiffeID, now)
+}
+
+func (s *state) getNodeSelectors(spiffeID string) []*common.Selector {
+	return cloneSelectors(s.nodeSelectors[spiffeID])
+}
+
+func (s *state) listNodeSelectors(req *datastore.ListNodeSelectorsRequest) *datastore.ListNodeSelectorsResponse {
+	resp := &datastore.ListNodeSelectorsResponse{
+		Selectors: make(map[string][]*common.Selector),
+	}
+	for spiffeID, selectors := range s.nodeSelectors {
+		if !req.ValidAt.IsZero() {
+			node, ok := s.nodes.get(spiffeID)
+			if !ok || node.CertNotAfter <= req.ValidAt.Unix() {
+				continue
+			}
+		}
+		resp.Selectors[spiffeID] = cloneSelectors(selectors)
+	}
+	return resp
+}
+
+// Entries
+
+func (s *state) createOrReturnRegistrationEntry(entry *common.RegistrationEntry, now time.Time) (*common.RegistrationEntry, bool, error) {
+	if err := validateRegistrationEntry(entry); err != nil {
+		return nil, false, err
+	}
+
+	if existing := s.lookupSimilarEntry(entry); existing != nil {
+		return cloneEntry(existing), true, nil
+	}
+
+	if entry.EntryId == "" {
+		// Entry IDs are assigned before the command is proposed so that
+		// every member of the cluster stores the same ID.
+		return nil, false, newRaftError(codes.Internal, "missing entry ID")
+	}
+	if _, ok := s.entries.get(entry.EntryId); ok {
+		return nil, false, newAlreadyExistsError("registration entry %q already exists", entry.EntryId)
+	}
+	if err := s.checkFederatesWith(entry.FederatesWith); err != nil {
+		return nil, false, err
+	}
+
+	stored := cloneEntry(entry)
+	stored.RevisionNumber = 0
+	stored.CreatedAt = now.Round(time.Second).Unix()
+	if len(stored.DnsNames) == 0 {
+		stored.DnsNames = nil
+	}
+	if len(stored.FederatesWith) == 0 {
+		stored.FederatesWith = nil
+	}
+	s.entries.insert(stored.EntryId, stored)
+
+	if err := s.entryEvents.create(0, stored.EntryId, now); err != nil {
+		return nil, false, err
+	}
+	return cloneEntry(stored), false, nil
+}
+
+func (s *state) lookupSimilarEntry(entry *common.RegistrationEntry) *common.RegistrationEntry {
+	for _, r := range s.entries.after(0) {
+		if datastore.IsSimilarEntry(r.value, entry) {
+			return r.value
+		}
+	}
+	return nil
+}
+
+func (s *state) checkFederatesWith(trustDomainIDs []string) error {
+	for _, id := range trustDomainIDs {
+		if _, ok := s.bundles.get(id); !ok {
+			return status.Errorf(codes.Unknown, "unable to find federated bundle %q", id)
+		}
+	}
+	return nil
+}
+
+func (s *state) fetchRegistrationEntries(entryIDs []string) map[string]*common.RegistrationEntry {
+	entries := make(map[string]*common.RegistrationEntry, len(entryIDs))
+	for _, entryID := range entryIDs {
+		if entry, ok := s.entries.get(entryID); ok {
+			entries[entryID] = cloneEntry(entry)
+		}
+	}
+	return entries
+}
+
+func (s *state) countRegistrationEntries(req *datastore.CountRegistrationEntriesRequest) (int32, error) {
+	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
+		return 0, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	}
+
+	match, err := registrationEntryFilter(&datastore.ListRegistrationEntriesRequest{
+		ByParentID:      req.ByParentID,
+		BySelectors:     req.BySelectors,
+		BySpiffeID:      req.BySpiffeID,
+		ByFederatesWith: req.ByFederatesWith,
+		ByHint:          req.ByHint,
+		ByDownstream:    req.ByDownstream,
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	var count int32
+	for _, r := range s.entries.after(0) {
+		if match(r.value) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *state) listRegistrationEntries(req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+	if req.Pagination != nil && req.Pagination.PageSize == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+	}
+	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	}
+
+	match, err := registrationEntryFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, p, err := paginate(s.entries, req.Pagination, match)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListRegistrationEntriesResponse{
+		Entries:    make([]*common.RegistrationEntry, 0, len(entries)),
+		Pagination: p,
+	}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, cloneEntry(entry))
+	}
+	return resp, nil
+}
+
+func registrationEntryFilter(req *datastore.ListRegistrationEntriesRequest) (func(*common.RegistrationEntry) bool, error) {
+	if req.BySelectors != nil {
+		if err := validateMatchBehavior(req.BySelectors.Match); err != nil {
+			return nil, err
+		}
+	}
+	byFederatesWith := req.ByFederatesWith != nil && len(req.ByFederatesWith.TrustDomains) > 0
+	if byFederatesWith {
+		if err := validateMatchBehavior(req.ByFederatesWith.Match); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(entry *common.RegistrationEntry) bool {
+		if req.ByParentID != "" && entry.ParentId != req.ByParentID {
+			return false
+		}
+		if req.BySpiffeID != "" && entry.SpiffeId != req.BySpiffeID {
+			return false
+		}
+		if req.ByHint != "" && entry.Hint != req.ByHint {
+			return false
+		}
+		// Like the SQL datastore, only filtering for downstream entries is
+		// supported.
+		if req.ByDownstream != nil && *req.ByDownstream && !entry.Downstream {
+			return false
+		}
+		if req.BySelectors != nil {
+			have := selectorKeys(entry.Selectors)
+			want := selectorKeys(req.BySelectors.Selectors)
+			if !matchSet(have, want, req.BySelectors.Match) {
+				return false
+			}
+		}
+		if byFederatesWith && !matchSet(entry.FederatesWith, req.ByFederatesWith.TrustDomains, req.ByFederatesWith.Match) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+func (s *state) updateRegistrationEntry(e *common.RegistrationEntry, mask *common.RegistrationEntryMask, now time.Time) (*common.RegistrationEntry, error) {
+	if err := validateRegistrationEntryForUpdate(e, mask); err != nil {
+		return nil, err
+	}
+
+	current, ok := s.entries.get(e.EntryId)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+
+	entry := cloneEntry(current)
+	if mask == nil || mask.StoreSvid {
+		entry.StoreSvid = e.StoreSvid
+	}
+	if mask == nil || mask.Selectors {
+		entry.Selectors = cloneSelectors(e.Selectors)
+	}
+
+	// Verify that final selectors contains the same 'type' when entry is used for store SVIDs
+	if entry.StoreSvid && !equalSelectorTypes(entry.Selectors) {
+		return nil, newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
+	}
+
+	if mask == nil || mask.DnsNames {
+		entry.DnsNames = slices.Clone(e.DnsNames)
+		if len(entry.DnsNames) == 0 {
+			entry.DnsNames = nil
+		}
+	}
+	if mask == nil || mask.SpiffeId {
+		entry.SpiffeId = e.SpiffeId
+	}
+	if mask == nil || mask.ParentId {
+		entry.ParentId = e.ParentId
+	}
+	if mask == nil || mask.X509SvidTtl {
+		entry.X509SvidTtl = e.X509SvidTtl
+	}
+	if mask == nil || mask.Admin {
+		entry.Admin = e.Admin
+	}
+	if mask == nil || mask.Downstream {
+		entry.Downstream = e.Downstream
+	}
+	if mask == nil || mask.EntryExpiry {
+		entry.EntryExpiry = e.EntryExpiry
+	}
+	if mask == nil || mask.JwtSvidTtl {
+		entry.JwtSvidTtl = e.JwtSvidTtl
+	}
+	if mask == nil || mask.Hint {
+		entry.Hint = e.Hint
+	}
+	if mask == nil || mask.FederatesWith {
+		if err := s.checkFederatesWith(e.FederatesWith); err != nil {
+			return nil, err
+		}
+		entry.FederatesWith = slices.Clone(e.FederatesWith)
+		if len(entry.FederatesWith) == 0 {
+			entry.FederatesWith = nil
+		}
+	}
+
+	// Revision number is increased by 1 on every update call
+	entry.RevisionNumber++
+	s.entries.set(entry.EntryId, entry)
+
+	if err := s.entryEvents.create(0, entry.EntryId, now); err != nil {
+		return nil, err
+	}
+	return cloneEntry(entry), nil
+}
+
+func (s *state) deleteRegistrationEntry(entryID string, now time.Time) (*common.RegistrationEntry, error) {
+	entry, ok := s.entries.get(entryID)
+	if !ok {
+		return nil, newNotFoundError()
+	}
+	s.entries.delete(entryID)
+
+	if err := s.entryEvents.create(0, entryID, now); err != nil {
+		return nil, err
+	}
+	return cloneEntry(entry), nil
+}
+
+func (s *state) pruneRegistrationEntries(expiresBefore time.Time, now time.Time) error {
+	var expired []*common.RegistrationEntry
+	for _, r := range s.entries.after(0) {
+		if r.value.EntryExpiry != 0 && r.value.EntryExpiry < expiresBefore.Unix() {
+			expired = append(expired, r.value)
+		}
+	}
+
+	for _, entry := range expired {
+		s.entries.delete(entry.EntryId)
+		if err := s.entryEvents.create(0, entry.EntryId, now); err != nil {
+			return err
+		}
+		s.log.WithFields(logrus.Fields{
+			telemetry.SPIFFEID:       entry.SpiffeId,
+			telemetry.ParentID:       entry.ParentId,
+			telemetry.RegistrationID: entry.EntryId,
+		}).Info("Pruned an expired registration")
+	}
+	return nil
+}
+
+// Join tokens
+
+func (s *state) createJoinToken(token string, expiry int64) error {
+	if _, ok := s.joinTokens[token]; ok {
+		return newAlreadyExistsError("join token already exists")
+	}
+	s.joinTokens[token] = expiry
+	return nil
+}
+
+func (s *state) fetchJoinToken(token string) *datastore.JoinToken {
+	expiry, ok := s.joinTokens[token]
+	if !ok {
+		return nil
+	}
+	return &datastore.JoinToken{
+		Token:  token,
+		Expiry: time.Unix(expiry, 0),
+	}
+}
+
+func (s *state) listJoinTokens() []*datastore.JoinToken {
+	tokens := make([]*datastore.JoinToken, 0, len(s.joinTokens))
+	for _, token := range slices.Sorted(maps.Keys(s.joinTokens)) {
+		tokens = append(tokens, s.fetchJoinToken(token))
+	}
+	return tokens
+}
+
+func (s *state) deleteJoinToken(token string) error {
+	if _, ok := s.joinTokens[token]; !ok {
+		return newNotFoundError()
+	}
+	delete(s.joinTokens, token)
+	return nil
+}
+
+func (s *state) pruneJoinTokens(expiresBefore time.Time) {
+	for token, expiry := range s.joinTokens {
+		if expiry < expiresBefore.Unix() {
+			delete(s.joinTokens, token)
+		}
+	}
+}
+
+// Federation relationships
+
+func (s *state) createFederationRelationship(fr *federationRelationship, bundle *common.Bundle) error {
+	if _, ok := s.federationRelationships.get(fr.TrustDomain); ok {
+		return newAlreadyExistsError("federation relationship with %q already exists", fr.TrustDomain)
+	}
+
+	if bundle != nil {
+		// overwrite current bundle
+		if _, err := s.setBundle(bundle); err != nil {
+			return fmt.Errorf("unable to set bundle: %w", err)
+		}
+	}
+
+	s.federationRelationships.insert(fr.TrustDomain, fr)
+	return nil
+}
+
+func (s *state) deleteFederationRelationship(trustDomain string) error {
+	if !s.federationRelationships.delete(trustDomain) {
+		return newNotFoundError()
+	}
+	return nil
+}
+
+func (s *state) fetchFederationRelationship(trustDomain spiffeid.TrustDomain) (*datastore.FederationRelationship, error) {
+	fr, ok := s.federationRelationships.get(trustDomain.Name())
+	if !ok {
+		return nil, nil
+	}
+	return s.toFederationRelationship(fr)
+}
+
+func (s *state) listFederationRelationships(req *datastore.ListFederationRelationshipsRequest) (*datastore.ListFederationRelationshipsResponse, error) {
+	relationships, p, err := paginate(s.federationRelationships, req.Pagination, func(*federationRelationship) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &datastore.ListFederationRelationshipsResponse{
+		Pagination:              p,
+		FederationRelationships: []*datastore.FederationRelationship{},
+	}
+	for _, model := range relationships {
+		fr, err := s.toFederationRelationship(model)
+		if err != nil {
+			return nil, err
+		}
+		resp.FederationRelationships = append(resp.FederationRelationships, fr)
+	}
+	return resp, nil
+}
+
+func (s *state) updateFederationRelationship(update *federationRelationship, bundle *common.Bundle, mask *types.FederationRelationshipMask) (*datastore.FederationRelationship, error) {
+	current, ok := s.federationRelationships.get(update.TrustDomain)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unable to fetch federation relationship: record not found")
+	}
+
+	fr := *current
+	if mask.BundleEndpointUrl {
+		fr.BundleEndpointURL = update.BundleEndpointURL
+	}
+	if mask.BundleEndpointProfile {
+		fr.BundleEndpointProfile = update.BundleEndpointProfile
+		if fr.BundleEndpointProfile == string(datastore.BundleEndpointSPIFFE) {
+			fr.EndpointSPIFFEID = update.EndpointSPIFFEID
+		}
+	}
+
+	if mask.TrustDomainBundle && bundle != nil {
+		// overwrite current bundle
+		if _, err := s.setBundle(bundle); err != nil {
+			return nil, fmt.Errorf("unable to set bundle: %w", err)
+		}
+	}
+
+	s.federationRelationships.set(fr.TrustDomain, &fr)
+	return s.toFederationRelationship(&fr)
+}
+
+func (s *state) toFederationRelationship(model *federationRelationship) (*datastore.FederationRelationship, error) {
+	return toFederationRelationship(model, s.fetchBundle)
+}
+
+func toFederationRelationship(model *federationRelationship, fetchBundle func(string) *common.Bundle) (*datastore.FederationRelationship, error) {
+	bundleEndpointURL, err := url.Parse(model.BundleEndpointURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse URL: %w", err)
+	}
+
+	td, err := spiffeid.TrustDomainFromString(model.TrustDomain)
+	if err != nil {
+		return nil, newRaftError(codes.Unknown, "%v", err)
+	}
+
+	fr := &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     bundleEndpointURL,
+		BundleEndpointProfile: datastore.BundleEndpointType(model.BundleEndpointProfile),
+	}
+
+	switch fr.BundleEndpointProfile {
+	case datastore.BundleEndpointWeb:
+	case datastore.BundleEndpointSPIFFE:
+		endpointSPIFFEID, err := spiffeid.FromString(model.EndpointSPIFFEID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse bundle endpoint SPIFFE ID: %w", err)
+		}
+		fr.EndpointSPIFFEID = endpointSPIFFEID
+	default:
+		return nil, fmt.Errorf("unknown bundle endpoint profile type: %q", model.BundleEndpointProfile)
+	}
+
+	fr.TrustDomainBundle = fetchBundle(td.IDString())
+	return fr, nil
+}
+
+// CA journals
+
+func (s *state) setCAJournal(caj *datastore.CAJournal) (*datastore.CAJournal, error) {
+	model := &caJournal{
+		Data:                  slices.Clone(caj.Data),
+		ActiveX509AuthorityID: caj.ActiveX509AuthorityID,
+	}
+
+	if caj.ID == 0 {
+		id := s.caJournals.insert(caJournalKey(s.caJournals.nextID+1), model)
+		return toCAJournal(id, model), nil
+	}
+
+	// The CA journal already exists, update it.
+	if !s.caJournals.set(caJournalKey(uint64(caj.ID)), model) {
+		return nil, newNotFoundError()
+	}
+	return toCAJournal(uint64(caj.ID), model), nil
+}
+
+func (s *state) fetchCAJournal(activeX509AuthorityID string) *datastore.CAJournal {
+	for _, r := range s.caJournals.after(0) {
+		if r.value.ActiveX509AuthorityID == activeX509AuthorityID {
+			return toCAJournal(r.id, r.value)
+		}
+	}
+	return nil
+}
+
+func (s *state) listCAJournals() []*datastore.CAJournal {
+	var caJournals []*datastore.CAJournal
+	for _, r := range s.caJournals.after(0) {
+		caJournals = append(caJournals, toCAJournal(r.id, r.value))
+	}
+	return caJournals
+}
+
+func (s *state) pruneCAJournals(allAuthoritiesExpireBefore int64) error {
+	var stale []*row[*caJournal]
+
+checkAuthorities:
+	for _, r := range s.caJournals.after(0) {
+		entries := new(journal.Entries)
+		if err := proto.Unmarshal(r.value.Data, entries); err != nil {
+			return status.Errorf(codes.Internal, "unable to unmarshal entries from CA journal record: %v", err)
+		}
+
+		for _, x509CA := range entries.X509CAs {
+			if x509CA.NotAfter > allAuthoritiesExpireBefore {
+				continue checkAuthorities
+			}
+		}
+		for _, jwtKey := range entries.JwtKeys {
+			if jwtKey.NotAfter > allAuthoritiesExpireBefore {
+				continue checkAuthorities
+			}
+		}
+		stale = append(stale, r)
+	}
+
+	for _, r := range stale {
+		s.caJournals.delete(r.key)
+		s.log.WithFields(logrus.Fields{
+			telemetry.CAJournalID: r.id,
+		}).Info("Pruned stale CA journal record")
+	}
+	return nil
+}
+
+func caJournalKey(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func toCAJournal(id uint64, model *caJournal) *datastore.CAJournal {
+	return &datastore.CAJournal{
+		ID:                    uint(id),
+		Data:                  slices.Clone(model.Data),
+		ActiveX509AuthorityID: model.ActiveX509AuthorityID,
+	}
+}
+
+// Revoked X509-SVIDs
+
+func (s *state) revokeX509SVID(revoked *revokedX509SVID) error {
+	key := revoked.key()
+	if _, ok := s.revokedX509SVIDs[key]; ok {
+		return newAlreadyExistsError("X509-SVID with serial number %q issued by authority %q is already revoked", revoked.SerialNumber, revoked.AuthorityID)
+	}
+	s.revokedX509SVIDs[key] = revoked
+	return nil
+}
+
+// sortedRevokedX509SVIDs returns the revoked X509-SVIDs ordered by authority
+// ID and serial number.
+func (s *state) sortedRevokedX509SVIDs() []*revokedX509SVID {
+	return slices.SortedFunc(maps.Values(s.revokedX509SVIDs), func(a, b *revokedX509SVID) int {
+		return cmp.Or(strings.Compare(a.AuthorityID, b.AuthorityID), strings.Compare(a.SerialNumber, b.SerialNumber))
+	})
+}
+
+func (s *state) listRevokedX509SVIDs() []*datastore.RevokedX509SVID {
+	models := s.sortedRevokedX509SVIDs()
+	revoked := make([]*datastore.RevokedX509SVID, 0, len(models))
+	for _, model := range models {
+		revoked = append(revoked, &datastore.RevokedX509SVID{
+			AuthorityID:  model.AuthorityID,
+			SerialNumber: model.SerialNumber,
+			RevokedAt:    time.Unix(0, model.RevokedAt),
+			ExpiresAt:    time.Unix(0, model.ExpiresAt),
+		})
+	}
+	return revoked
+}
+
+func (s *state) pruneRevokedX509SVIDs(expiresBefore time.Time) {
+	maps.DeleteFunc(s.revokedX509SVIDs, func(_ revokedX509SVIDKey, model *revokedX509SVID) bool {
+		return model.ExpiresAt < expiresBefore.UnixNano()
+	})
+}
+
+// paginate returns the values of the table that satisfy the match function,
+// honoring the given pagination. The returned pagination holds the token for
+// the next page, which is empty when no values were returned.
+func paginate[V any](t *table[V], p *datastore.Pagination, match func(V) bool) ([]V, *datastore.Pagination, error) {
+	var afterID uint64
+	if p != nil {
+		if p.PageSize == 0 {
+			return nil, nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+		}
+		if len(p.Token) > 0 {
+			id, err := strconv.ParseUint(p.Token, 10, 64)
+			if err != nil {
+				return nil, nil, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", p.Token)
+			}
+			afterID = id
+		}
+	}
+
+	var values []V
+	var lastID uint64
+	for _, r := range t.after(afterID) {
+		if !match(r.value) {
+			continue
+		}
+		values = append(values, r.value)
+		lastID = r.id
+		if p != nil && len(values) == int(p.PageSize) {
+			break
+		}
+	}
+
+	if p == nil {
+		return values, nil, nil
+	}
+
+	next := &datastore.Pagination{
+		PageSize: p.PageSize,
+	}
+	if len(values) > 0 {
+		next.Token = strconv.FormatUint(lastID, 10)
+	}
+	return values, next, nil
+}
+
+type selectorKey struct {
+	Type  string
+	Value string
+}
+
+func selectorKeys(selectors []*common.Selector) []selectorKey {
+	keys := make([]selectorKey, 0, len(selectors))
+	for _, s := range selectors {
+		keys = append(keys, selectorKey{Type: s.Type, Value: s.Value})
+	}
+	return keys
+}
+
+func validateMatchBehavior(match datastore.MatchBehavior) error {
+	switch match {
+	case datastore.Exact, datastore.Subset, datastore.Superset, datastore.MatchAny:
+		return nil
+	default:
+		return newRaftError(codes.Unknown, "unhandled match behavior %d", match)
+	}
+}
+
+// matchSet reports whether the values a record has match the requested ones
+// using the given match behavior:
+//   - Exact: the record has exactly the requested values
+//   - Subset: the record has a non-empty subset of the requested values
+//   - Superset: the record has all the requested values, and maybe more
+//   - MatchAny: the record has at least one of the requested values
+func matchSet[K comparable](have, want []K, match datastore.MatchBehavior) bool {
+	wantSet := make(map[K]struct{}, len(want))
+	for _, k := range want {
+		wantSet[k] = struct{}{}
+	}
+	haveSet := make(map[K]struct{}, len(have))
+	for _, k := range have {
+		haveSet[k] = struct{}{}
+	}
+
+	shared := 0
+	for k := range haveSet {
+		if _, ok := wantSet[k]; ok {
+			shared++
+		}
+	}
+
+	switch match {
+	case datastore.Exact:
+		return shared == len(haveSet) && shared == len(wantSet)
+	case datastore.Subset:
+		return shared > 0 && shared == len(haveSet)
+	case datastore.Superset:
+		return shared == len(wantSet)
+	case datastore.MatchAny:
+		return shared > 0
+	default:
+		return false
+	}
+}
+
+func validateRegistrationEntry(entry *common.RegistrationEntry) error {
+	return newWrappedValidationError(datastore.ValidateRegistrationEntry(entry))
+}
+
+func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) error {
+	return newWrappedValidationError(datastore.ValidateRegistrationEntryForUpdate(entry, mask))
+}
+
+// equalSelectorTypes validates that all selectors has the same type,
+func equalSelectorTypes(selectors []*common.Selector) bool {
+	typ := ""
+	for _, t := range selectors {
+		switch {
+		case typ == "":
+			typ = t.Type
+		case typ != t.Type:
+			return false
+		}
+	}
+	return true
+}
+
+func cloneBundle(bundle *common.Bundle) *common.Bundle {
+	return proto.Clone(bundle).(*common.Bundle)
+}
+
+func cloneEntry(entry *common.RegistrationEntry) *common.RegistrationEntry {
+	return proto.Clone(entry).(*common.RegistrationEntry)
+}
+
+func cloneNode(node *common.AttestedNode) *common.AttestedNode {
+	return proto.Clone(node).(*common.AttestedNode)
+}
+
+func cloneSelectors(selectors []*common.Selector) []*common.Selector {
+	if len(selectors) == 0 {
+		return nil
+	}
+	cloned := make([]*common.Selector, 0, len(selectors))
+	for _, selector := range selectors {
+		cloned = append(cloned, &common.Selector{Type: selector.Type, Value: selector.Value})
+	}
+	return cloned
+}
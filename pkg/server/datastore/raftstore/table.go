@@ -0,0 +1,114 @@
+package raftstore
+
+import (
+	"maps"
+	"slices"
+	"sort"
+)
+
+// table is an ordered key/value collection. Every row is assigned a
+// monotonically increasing ID on insertion which is used to order listings
+// and as the pagination token, mirroring the auto-increment primary keys of
+// the SQL datastore.
+type table[V any] struct {
+	nextID uint64
+	rows   map[string]*row[V]
+	order  []*row[V]
+}
+
+type row[V any] struct {
+	id    uint64
+	key   string
+	value V
+}
+
+func newTable[V any]() *table[V] {
+	return &table[V]{
+		rows: make(map[string]*row[V]),
+	}
+}
+
+func (t *table[V]) len() int {
+	return len(t.rows)
+}
+
+func (t *table[V]) get(key string) (V, bool) {
+	r, ok := t.rows[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return r.value, true
+}
+
+// insert adds a new row for the given key. The caller is responsible for
+// making sure the key does not already exist.
+func (t *table[V]) insert(key string, value V) uint64 {
+	t.nextID++
+	r := &row[V]{id: t.nextID, key: key, value: value}
+	t.rows[key] = r
+	t.order = append(t.order, r)
+	return r.id
+}
+
+// restore adds a row with a known ID. Rows must be restored in ID order.
+func (t *table[V]) restore(id uint64, key string, value V) {
+	r := &row[V]{id: id, key: key, value: value}
+	t.rows[key] = r
+	t.order = append(t.order, r)
+	if id > t.nextID {
+		t.nextID = id
+	}
+}
+
+// set replaces the value of an existing row, keeping its ID. Rows are
+// replaced rather than mutated so that snapshots taken from a shallow clone
+// of the table are not affected by later writes.
+func (t *table[V]) set(key string, value V) bool {
+	r, ok := t.rows[key]
+	if !ok {
+		return false
+	}
+	updated := &row[V]{id: r.id, key: key, value: value}
+	t.rows[key] = updated
+	t.order[t.index(r.id)] = updated
+	return true
+}
+
+func (t *table[V]) delete(key string) bool {
+	r, ok := t.rows[key]
+	if !ok {
+		return false
+	}
+	delete(t.rows, key)
+	i := t.index(r.id)
+	t.order = slices.Delete(t.order, i, i+1)
+	return true
+}
+
+// after returns the rows with an ID greater than the given one, in ID order.
+func (t *table[V]) after(id uint64) []*row[V] {
+	i := sort.Search(len(t.order), func(i int) bool {
+		return t.order[i].id > id
+	})
+	return t.order[i:]
+}
+
+func (t *table[V]) index(id uint64) int {
+	return sort.Search(len(t.order), func(i int) bool {
+		return t.order[i].id >= id
+	})
+}
+
+// clone returns a shallow copy of the table. Since rows are never mutated in
+// place, the clone is a consistent point-in-time view of the table.
+func (t *table[V]) clone() *table[V] {
+	c := &table[V]{
+		nextID: t.nextID,
+		rows:   make(map[string]*row[V], len(t.rows)),
+		order:  make([]*row[V], len(t.order)),
+	}
+	copy(c.order, t.order)
+	maps.Copy(c.rows, t.rows)
+	return c
+}
@@ -0,0 +1,275 @@
+package raftstore
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// rpcRaft and rpcForward are the first byte written on every connection
+	// to select the protocol spoken over it.
+	rpcRaft    byte = 0x01
+	rpcForward byte = 0x02
+
+	// maxFrameSize is the maximum size of a forwarded command or result.
+	maxFrameSize = 64 << 20
+
+	handshakeTimeout = 10 * time.Second
+)
+
+// transport multiplexes the raft protocol and the forwarding of commands to
+// the leader over a single mutually authenticated TLS listener.
+type transport struct {
+	log       logrus.FieldLogger
+	listener  net.Listener
+	advertise net.Addr
+	tlsConfig *tls.Config
+
+	// authorize verifies that the certificate of a connecting peer belongs
+	// to a member of the cluster.
+	authorize func(cert *x509.Certificate) error
+
+	// forward handles a command forwarded by another member.
+	forward func(ctx context.Context, req []byte) []byte
+
+	raftConns chan net.Conn
+	closeOnce sync.Once
+	closed    chan struct{}
+	wg        sync.WaitGroup
+}
+
+func newTransport(log logrus.FieldLogger, bindAddress string, advertise net.Addr, tlsConfig *tls.Config) (*transport, error) {
+	listener, err := net.Listen("tcp", bindAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %q: %w", bindAddress, err)
+	}
+	if advertise == nil {
+		advertise = listener.Addr()
+	}
+	if tcpAddr, ok := advertise.(*net.TCPAddr); ok && tcpAddr.IP.IsUnspecified() {
+		listener.Close()
+		return nil, fmt.Errorf("advertise address %q is not routable; set advertise_address", advertise)
+	}
+
+	t := &transport{
+		log:       log,
+		listener:  listener,
+		advertise: advertise,
+		tlsConfig: tlsConfig,
+		raftConns: make(chan net.Conn),
+		closed:    make(chan struct{}),
+	}
+	return t, nil
+}
+
+// serve accepts connections until the transport is closed. The authorize
+// and forward handlers must be set before calling serve.
+func (t *transport) serve() {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		for {
+			conn, err := t.listener.Accept()
+			if err != nil {
+				select {
+				case <-t.closed:
+					return
+				default:
+				}
+				t.log.WithError(err).Warn("Failed to accept raft connection")
+				continue
+			}
+			t.wg.Add(1)
+			go func() {
+				defer t.wg.Done()
+				t.handleConn(conn)
+			}()
+		}
+	}()
+}
+
+func (t *transport) handleConn(rawConn net.Conn) {
+	log := t.log.WithField("remote_address", rawConn.RemoteAddr())
+	conn := tls.Server(rawConn, t.tlsConfig)
+
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := conn.Handshake(); err != nil {
+		log.WithError(err).Debug("Failed raft connection TLS handshake")
+		conn.Close()
+		return
+	}
+	// The client certificate has been verified against the CA during the
+	// handshake; it must also belong to a member of the cluster.
+	if err := t.authorize(conn.ConnectionState().PeerCertificates[0]); err != nil {
+		log.WithError(err).Warn("Rejected raft connection from unknown peer")
+		conn.Close()
+		return
+	}
+
+	var rpcType [1]byte
+	if _, err := io.ReadFull(conn, rpcType[:]); err != nil {
+		log.WithError(err).Debug("Failed to read connection type")
+		conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	switch rpcType[0] {
+	case rpcRaft:
+		select {
+		case t.raftConns <- conn:
+		case <-t.closed:
+			conn.Close()
+		}
+	case rpcForward:
+		defer conn.Close()
+		t.serveForward(conn)
+	default:
+		log.Warn("Unknown raft connection type")
+		conn.Close()
+	}
+}
+
+func (t *transport) serveForward(conn net.Conn) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-t.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	_ = conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	req, err := readFrame(conn)
+	if err != nil {
+		t.log.WithError(err).Debug("Failed to read forwarded command")
+		return
+	}
+	if err := writeFrame(conn, t.forward(ctx, req)); err != nil {
+		t.log.WithError(err).Debug("Failed to write forwarded command result")
+	}
+}
+
+// dial opens a connection of the given type to the given member.
+func (t *transport) dial(ctx context.Context, address string, rpcType byte) (net.Conn, error) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := t.tlsConfig.Clone()
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		tlsConfig.ServerName = host
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := tlsConn.Write([]byte{rpcType}); err != nil {
+		tlsConn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// forwardTo sends the request to the given member and returns its response.
+func (t *transport) forwardTo(ctx context.Context, address string, req []byte) ([]byte, error) {
+	conn, err := t.dial(ctx, address, rpcForward)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errForwardDial, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if err := writeFrame(conn, req); err != nil {
+		return nil, err
+	}
+	return readFrame(conn)
+}
+
+func (t *transport) close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		err = t.listener.Close()
+		t.wg.Wait()
+	})
+	return err
+}
+
+// streamLayer returns the raft stream layer over the transport.
+func (t *transport) streamLayer() raft.StreamLayer {
+	return (*streamLayer)(t)
+}
+
+// streamLayer implements raft.StreamLayer for the raft connections of the
+// transport.
+type streamLayer transport
+
+func (s *streamLayer) Accept() (net.Conn, error) {
+	select {
+	case conn := <-s.raftConns:
+		return conn, nil
+	case <-s.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (s *streamLayer) Close() error {
+	return (*transport)(s).close()
+}
+
+func (s *streamLayer) Addr() net.Addr {
+	return s.advertise
+}
+
+func (s *streamLayer) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return (*transport)(s).dial(ctx, string(address), rpcRaft)
+}
+
+func writeFrame(w io.Writer, data []byte) error {
+	if len(data) > maxFrameSize {
+		return fmt.Errorf("frame too large: %d bytes", len(data))
+	}
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(data))) //nolint: gosec // checked above
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return nil, errors.New("frame too large")
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
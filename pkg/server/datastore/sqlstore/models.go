@@ -204,6 +204,28 @@ type CAJournal struct {
 	ActiveJWTAuthorityID string `gorm:"index:idx_ca_journals_active_jwt_authority_id"`
 }
 
+// RevokedX509SVID holds an X509-SVID that was revoked before its
+// expiration.
+type RevokedX509SVID struct {
+	Model
+
+	// AuthorityID is the authority ID of the X.509 authority that issued
+	// the SVID.
+	AuthorityID string `gorm:"not null;unique_index:uix_revoked_x509_svids_authority_id_serial_number"`
+
+	// SerialNumber is the serial number of the SVID as a lowercase
+	// hexadecimal string.
+	SerialNumber string `gorm:"not null;unique_index:uix_revoked_x509_svids_authority_id_serial_number"`
+
+	RevokedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// TableName gets table name of RevokedX509SVID
+func (RevokedX509SVID) TableName() string {
+	return "revoked_x509_svids"
+}
+
 // Migration holds database schema version number, and
 // the SPIRE Code version number
 type Migration struct {
@@ -12,7 +12,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/hashicorp/hcl"
@@ -31,22 +30,13 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/private/server/journal"
 	"github.com/spiffe/spire/proto/spire/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 )
 
-var validEntryIDChars = &unicode.RangeTable{
-	R16: []unicode.Range16{
-		{0x002d, 0x002e, 1}, // - | .
-		{0x0030, 0x0039, 1}, // [0-9]
-		{0x0041, 0x005a, 1}, // [A-Z]
-		{0x005f, 0x005f, 1}, // _
-		{0x0061, 0x007a, 1}, // [a-z]
-	},
-	LatinOffset: 5,
-}
-
 const (
 	PluginName = "sql"
 
@@ -147,6 +137,9 @@ func New(log logrus.FieldLogger) *Plugin {
 
 // CreateBundle stores the given bundle
 func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	ctx, span := ds.startSpan(ctx, "CreateBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		bundle, err = createBundle(tx, b)
 		return err
@@ -159,6 +152,9 @@ func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (bundle *c
 // UpdateBundle updates an existing bundle with the given CAs. Overwrites any
 // existing certificates.
 func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *common.BundleMask) (bundle *common.Bundle, err error) {
+	ctx, span := ds.startSpan(ctx, "UpdateBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		bundle, err = updateBundle(tx, b, mask)
 		return err
@@ -170,6 +166,9 @@ func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *comm
 
 // SetBundle sets bundle contents. If no bundle exists for the trust domain, it is created.
 func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	ctx, span := ds.startSpan(ctx, "SetBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		bundle, err = setBundle(tx, b)
 		return err
@@ -181,6 +180,9 @@ func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (bundle *comm
 
 // AppendBundle append bundle contents to the existing bundle (by trust domain). If no existing one is present, create it.
 func (ds *Plugin) AppendBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	ctx, span := ds.startSpan(ctx, "AppendBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		bundle, err = appendBundle(tx, b)
 		return err
@@ -192,6 +194,9 @@ func (ds *Plugin) AppendBundle(ctx context.Context, b *common.Bundle) (bundle *c
 
 // DeleteBundle deletes the bundle with the matching TrustDomain. Any CACert data passed is ignored.
 func (ds *Plugin) DeleteBundle(ctx context.Context, trustDomainID string, mode datastore.DeleteMode) (err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = deleteBundle(tx, trustDomainID, mode)
 		return err
@@ -200,6 +205,9 @@ func (ds *Plugin) DeleteBundle(ctx context.Context, trustDomainID string, mode d
 
 // FetchBundle returns the bundle matching the specified Trust Domain.
 func (ds *Plugin) FetchBundle(ctx context.Context, trustDomainID string) (resp *common.Bundle, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = fetchBundle(tx, trustDomainID)
 		return err
@@ -211,6 +219,9 @@ func (ds *Plugin) FetchBundle(ctx context.Context, trustDomainID string) (resp *
 
 // CountBundles can be used to count all existing bundles.
 func (ds *Plugin) CountBundles(ctx context.Context) (count int32, err error) {
+	ctx, span := ds.startSpan(ctx, "CountBundles")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		count, err = countBundles(tx)
 		return err
@@ -222,6 +233,9 @@ func (ds *Plugin) CountBundles(ctx context.Context) (count int32, err error) {
 
 // ListBundles can be used to fetch all existing bundles.
 func (ds *Plugin) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (resp *datastore.ListBundlesResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListBundles")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = listBundles(tx, req)
 		return err
@@ -233,6 +247,9 @@ func (ds *Plugin) ListBundles(ctx context.Context, req *datastore.ListBundlesReq
 
 // PruneBundle removes expired certs and keys from a bundle
 func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (changed bool, err error) {
+	ctx, span := ds.startSpan(ctx, "PruneBundle")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		changed, err = pruneBundle(tx, trustDomainID, expiresBefore, ds.log)
 		return err
@@ -244,21 +261,30 @@ func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expires
 }
 
 // TaintX509CAByKey taints an X.509 CA signed using the provided public key
-func (ds *Plugin) TaintX509CA(ctx context.Context, trustDoaminID string, subjectKeyIDToTaint string) error {
+func (ds *Plugin) TaintX509CA(ctx context.Context, trustDoaminID string, subjectKeyIDToTaint string) (err error) {
+	ctx, span := ds.startSpan(ctx, "TaintX509CA")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return taintX509CA(tx, trustDoaminID, subjectKeyIDToTaint)
 	})
 }
 
 // RevokeX509CA removes a Root CA from the bundle
-func (ds *Plugin) RevokeX509CA(ctx context.Context, trustDoaminID string, subjectKeyIDToRevoke string) error {
+func (ds *Plugin) RevokeX509CA(ctx context.Context, trustDoaminID string, subjectKeyIDToRevoke string) (err error) {
+	ctx, span := ds.startSpan(ctx, "RevokeX509CA")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return revokeX509CA(tx, trustDoaminID, subjectKeyIDToRevoke)
 	})
 }
 
 // TaintJWTKey taints a JWT Authority key
-func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDoaminID string, authorityID string) (*common.PublicKey, error) {
+func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDoaminID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := ds.startSpan(ctx, "TaintJWTKey")
+	defer telemetry.EndSpan(span, &err)
+
 	var taintedKey *common.PublicKey
 	if err := ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		taintedKey, err = taintJWTKey(tx, trustDoaminID, authorityID)
@@ -270,7 +296,10 @@ func (ds *Plugin) TaintJWTKey(ctx context.Context, trustDoaminID string, authori
 }
 
 // RevokeJWTAuthority removes JWT key from the bundle
-func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDoaminID string, authorityID string) (*common.PublicKey, error) {
+func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDoaminID string, authorityID string) (_ *common.PublicKey, err error) {
+	ctx, span := ds.startSpan(ctx, "RevokeJWTKey")
+	defer telemetry.EndSpan(span, &err)
+
 	var revokedKey *common.PublicKey
 	if err := ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		revokedKey, err = revokeJWTKey(tx, trustDoaminID, authorityID)
@@ -283,6 +312,9 @@ func (ds *Plugin) RevokeJWTKey(ctx context.Context, trustDoaminID string, author
 
 // CreateAttestedNode stores the given attested node
 func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (attestedNode *common.AttestedNode, err error) {
+	ctx, span := ds.startSpan(ctx, "CreateAttestedNode")
+	defer telemetry.EndSpan(span, &err)
+
 	if node == nil {
 		return nil, newSQLError("invalid request: missing attested node")
 	}
@@ -303,6 +335,9 @@ func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedN
 
 // FetchAttestedNode fetches an existing attested node by SPIFFE ID
 func (ds *Plugin) FetchAttestedNode(ctx context.Context, spiffeID string) (attestedNode *common.AttestedNode, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchAttestedNode")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		attestedNode, err = fetchAttestedNode(tx, spiffeID)
 		return err
@@ -314,6 +349,9 @@ func (ds *Plugin) FetchAttestedNode(ctx context.Context, spiffeID string) (attes
 
 // CountAttestedNodes counts all attested nodes
 func (ds *Plugin) CountAttestedNodes(ctx context.Context, req *datastore.CountAttestedNodesRequest) (count int32, err error) {
+	ctx, span := ds.startSpan(ctx, "CountAttestedNodes")
+	defer telemetry.EndSpan(span, &err)
+
 	if countAttestedNodesHasFilters(req) {
 		resp, err := countAttestedNodesWithFilters(ctx, ds.db, ds.log, req)
 		return resp, err
@@ -332,6 +370,9 @@ func (ds *Plugin) CountAttestedNodes(ctx context.Context, req *datastore.CountAt
 func (ds *Plugin) ListAttestedNodes(ctx context.Context,
 	req *datastore.ListAttestedNodesRequest,
 ) (resp *datastore.ListAttestedNodesResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListAttestedNodes")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = listAttestedNodes(ctx, ds.db, ds.log, req)
 		return err
@@ -343,6 +384,9 @@ func (ds *Plugin) ListAttestedNodes(ctx context.Context,
 
 // UpdateAttestedNode updates the given node's cert serial and expiration.
 func (ds *Plugin) UpdateAttestedNode(ctx context.Context, n *common.AttestedNode, mask *common.AttestedNodeMask) (node *common.AttestedNode, err error) {
+	ctx, span := ds.startSpan(ctx, "UpdateAttestedNode")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		node, err = updateAttestedNode(tx, n, mask)
 		if err != nil {
@@ -359,6 +403,9 @@ func (ds *Plugin) UpdateAttestedNode(ctx context.Context, n *common.AttestedNode
 
 // DeleteAttestedNode deletes the given attested node and the associated node selectors.
 func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (attestedNode *common.AttestedNode, err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteAttestedNode")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		attestedNode, err = deleteAttestedNodeAndSelectors(tx, spiffeID)
 		if err != nil {
@@ -375,6 +422,9 @@ func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (atte
 
 // ListAttestedNodeEvents lists all attested node events
 func (ds *Plugin) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (resp *datastore.ListAttestedNodeEventsResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListAttestedNodeEvents")
+	defer telemetry.EndSpan(span, &err)
+
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		return listAttestedNodeEvents(ds.roDb, req)
 	}
@@ -383,6 +433,9 @@ func (ds *Plugin) ListAttestedNodeEvents(ctx context.Context, req *datastore.Lis
 
 // PruneAttestedNodeEvents deletes all attested node events older than a specified duration (i.e. more than 24 hours old)
 func (ds *Plugin) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneAttestedNodeEvents")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneAttestedNodeEvents(tx, olderThan)
 		return err
@@ -390,14 +443,20 @@ func (ds *Plugin) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Du
 }
 
 // CreateRegistrationEntryEventForTestingForTesting creates an attested node event. Used for unit testing.
-func (ds *Plugin) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) error {
+func (ds *Plugin) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) (err error) {
+	ctx, span := ds.startSpan(ctx, "CreateAttestedNodeEventForTesting")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
 		return createAttestedNodeEvent(tx, event)
 	})
 }
 
 // DeleteAttestedNodeEventForTesting deletes an attested node event by event ID. Used for unit testing.
-func (ds *Plugin) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) error {
+func (ds *Plugin) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) (err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteAttestedNodeEventForTesting")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return deleteAttestedNodeEvent(tx, eventID)
 	})
@@ -405,6 +464,9 @@ func (ds *Plugin) DeleteAttestedNodeEventForTesting(ctx context.Context, eventID
 
 // FetchAttestedNodeEvent fetches an existing attested node event by event ID
 func (ds *Plugin) FetchAttestedNodeEvent(ctx context.Context, eventID uint) (event *datastore.AttestedNodeEvent, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchAttestedNodeEvent")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		event, err = fetchAttestedNodeEvent(ds.db, eventID)
 		return err
@@ -417,6 +479,9 @@ func (ds *Plugin) FetchAttestedNodeEvent(ctx context.Context, eventID uint) (eve
 
 // SetNodeSelectors sets node (agent) selectors by SPIFFE ID, deleting old selectors first
 func (ds *Plugin) SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) (err error) {
+	ctx, span := ds.startSpan(ctx, "SetNodeSelectors")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		if err = setNodeSelectors(tx, spiffeID, selectors); err != nil {
 			return err
@@ -431,6 +496,9 @@ func (ds *Plugin) SetNodeSelectors(ctx context.Context, spiffeID string, selecto
 func (ds *Plugin) GetNodeSelectors(ctx context.Context, spiffeID string,
 	dataConsistency datastore.DataConsistency,
 ) (selectors []*common.Selector, err error) {
+	ctx, span := ds.startSpan(ctx, "GetNodeSelectors")
+	defer telemetry.EndSpan(span, &err)
+
 	if dataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		return getNodeSelectors(ctx, ds.roDb, spiffeID)
 	}
@@ -441,6 +509,9 @@ func (ds *Plugin) GetNodeSelectors(ctx context.Context, spiffeID string,
 func (ds *Plugin) ListNodeSelectors(ctx context.Context,
 	req *datastore.ListNodeSelectorsRequest,
 ) (resp *datastore.ListNodeSelectorsResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListNodeSelectors")
+	defer telemetry.EndSpan(span, &err)
+
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		return listNodeSelectors(ctx, ds.roDb, req)
 	}
@@ -451,6 +522,9 @@ func (ds *Plugin) ListNodeSelectors(ctx context.Context,
 func (ds *Plugin) CreateRegistrationEntry(ctx context.Context,
 	entry *common.RegistrationEntry,
 ) (registrationEntry *common.RegistrationEntry, err error) {
+	ctx, span := ds.startSpan(ctx, "CreateRegistrationEntry")
+	defer telemetry.EndSpan(span, &err)
+
 	out, _, err := ds.createOrReturnRegistrationEntry(ctx, entry)
 	return out, err
 }
@@ -461,6 +535,9 @@ func (ds *Plugin) CreateRegistrationEntry(ctx context.Context,
 func (ds *Plugin) CreateOrReturnRegistrationEntry(ctx context.Context,
 	entry *common.RegistrationEntry,
 ) (registrationEntry *common.RegistrationEntry, existing bool, err error) {
+	ctx, span := ds.startSpan(ctx, "CreateOrReturnRegistrationEntry")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.createOrReturnRegistrationEntry(ctx, entry)
 }
 
@@ -497,7 +574,10 @@ func (ds *Plugin) createOrReturnRegistrationEntry(ctx context.Context,
 // FetchRegistrationEntry fetches an existing registration by entry ID
 func (ds *Plugin) FetchRegistrationEntry(ctx context.Context,
 	entryID string,
-) (*common.RegistrationEntry, error) {
+) (_ *common.RegistrationEntry, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchRegistrationEntry")
+	defer telemetry.EndSpan(span, &err)
+
 	entries, err := fetchRegistrationEntries(ctx, ds.db, []string{entryID})
 	if err != nil {
 		return nil, err
@@ -510,12 +590,18 @@ func (ds *Plugin) FetchRegistrationEntry(ctx context.Context,
 // FetchRegistrationEntries fetches existing registrations by entry IDs
 func (ds *Plugin) FetchRegistrationEntries(ctx context.Context,
 	entryIDs []string,
-) (map[string]*common.RegistrationEntry, error) {
+) (_ map[string]*common.RegistrationEntry, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchRegistrationEntries")
+	defer telemetry.EndSpan(span, &err)
+
 	return fetchRegistrationEntries(ctx, ds.db, entryIDs)
 }
 
 // CountRegistrationEntries counts all registrations (pagination available)
 func (ds *Plugin) CountRegistrationEntries(ctx context.Context, req *datastore.CountRegistrationEntriesRequest) (count int32, err error) {
+	ctx, span := ds.startSpan(ctx, "CountRegistrationEntries")
+	defer telemetry.EndSpan(span, &err)
+
 	actDb := ds.db
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		actDb = ds.roDb
@@ -529,6 +615,9 @@ func (ds *Plugin) CountRegistrationEntries(ctx context.Context, req *datastore.C
 func (ds *Plugin) ListRegistrationEntries(ctx context.Context,
 	req *datastore.ListRegistrationEntriesRequest,
 ) (resp *datastore.ListRegistrationEntriesResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListRegistrationEntries")
+	defer telemetry.EndSpan(span, &err)
+
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		return listRegistrationEntries(ctx, ds.roDb, ds.log, req)
 	}
@@ -537,6 +626,9 @@ func (ds *Plugin) ListRegistrationEntries(ctx context.Context,
 
 // UpdateRegistrationEntry updates an existing registration entry
 func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (entry *common.RegistrationEntry, err error) {
+	ctx, span := ds.startSpan(ctx, "UpdateRegistrationEntry")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		entry, err = updateRegistrationEntry(tx, e, mask)
 		if err != nil {
@@ -556,6 +648,9 @@ func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.Registr
 func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 	entryID string,
 ) (registrationEntry *common.RegistrationEntry, err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteRegistrationEntry")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		registrationEntry, err = deleteRegistrationEntry(tx, entryID)
 		if err != nil {
@@ -574,6 +669,9 @@ func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 // PruneRegistrationEntries takes a registration entry message, and deletes all entries which have expired
 // before the date in the message
 func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneRegistrationEntries")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneRegistrationEntries(tx, expiresBefore, ds.log)
 		return err
@@ -582,6 +680,9 @@ func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore ti
 
 // ListRegistrationEntryEvents lists all registration entry events
 func (ds *Plugin) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (resp *datastore.ListRegistrationEntryEventsResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListRegistrationEntryEvents")
+	defer telemetry.EndSpan(span, &err)
+
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
 		return listRegistrationEntryEvents(ds.roDb, req)
 	}
@@ -590,6 +691,9 @@ func (ds *Plugin) ListRegistrationEntryEvents(ctx context.Context, req *datastor
 
 // PruneRegistrationEntryEvents deletes all registration entry events older than a specified duration (i.e. more than 24 hours old)
 func (ds *Plugin) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneRegistrationEntryEvents")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneRegistrationEntryEvents(tx, olderThan)
 		return err
@@ -597,14 +701,20 @@ func (ds *Plugin) PruneRegistrationEntryEvents(ctx context.Context, olderThan ti
 }
 
 // CreateRegistrationEntryEventForTesting creates a registration entry event. Used for unit testing.
-func (ds *Plugin) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) error {
+func (ds *Plugin) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) (err error) {
+	ctx, span := ds.startSpan(ctx, "CreateRegistrationEntryEventForTesting")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return createRegistrationEntryEvent(tx, event)
 	})
 }
 
 // DeleteRegistrationEntryEventForTesting deletes the given registration entry event. Used for unit testing.
-func (ds *Plugin) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) error {
+func (ds *Plugin) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) (err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteRegistrationEntryEventForTesting")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		return deleteRegistrationEntryEvent(tx, eventID)
 	})
@@ -612,6 +722,9 @@ func (ds *Plugin) DeleteRegistrationEntryEventForTesting(ctx context.Context, ev
 
 // FetchRegistrationEntryEvent fetches an existing registration entry event by event ID
 func (ds *Plugin) FetchRegistrationEntryEvent(ctx context.Context, eventID uint) (event *datastore.RegistrationEntryEvent, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchRegistrationEntryEvent")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		event, err = fetchRegistrationEntryEvent(ds.db, eventID)
 		return err
@@ -624,6 +737,9 @@ func (ds *Plugin) FetchRegistrationEntryEvent(ctx context.Context, eventID uint)
 
 // CreateJoinToken takes a Token message and stores it
 func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
+	ctx, span := ds.startSpan(ctx, "CreateJoinToken")
+	defer telemetry.EndSpan(span, &err)
+
 	if token == nil || token.Token == "" || token.Expiry.IsZero() {
 		return errors.New("token and expiry are required")
 	}
@@ -637,6 +753,9 @@ func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToke
 // FetchJoinToken takes a Token message and returns one, populating the fields
 // we have knowledge of
 func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datastore.JoinToken, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchJoinToken")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = fetchJoinToken(tx, token)
 		return err
@@ -647,8 +766,26 @@ func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datas
 	return resp, nil
 }
 
+// ListJoinTokens returns all the join tokens
+func (ds *Plugin) ListJoinTokens(ctx context.Context) (resp []*datastore.JoinToken, err error) {
+	ctx, span := ds.startSpan(ctx, "ListJoinTokens")
+	defer telemetry.EndSpan(span, &err)
+
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listJoinTokens(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // DeleteJoinToken deletes the given join token
 func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteJoinToken")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = deleteJoinToken(tx, token)
 		return err
@@ -658,6 +795,9 @@ func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error)
 // PruneJoinTokens takes a Token message, and deletes all tokens which have expired
 // before the date in the message
 func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneJoinTokens")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneJoinTokens(tx, expiry)
 		return err
@@ -670,6 +810,9 @@ func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) (err er
 // If no bundle is provided and there is not a previously stored bundle in the datastore, the
 // federation relationship is not created.
 func (ds *Plugin) CreateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship) (newFr *datastore.FederationRelationship, err error) {
+	ctx, span := ds.startSpan(ctx, "CreateFederationRelationship")
+	defer telemetry.EndSpan(span, &err)
+
 	if err := validateFederationRelationship(fr, protoutil.AllTrueFederationRelationshipMask); err != nil {
 		return nil, err
 	}
@@ -682,7 +825,10 @@ func (ds *Plugin) CreateFederationRelationship(ctx context.Context, fr *datastor
 
 // DeleteFederationRelationship deletes the federation relationship to the
 // given trust domain. The associated trust bundle is not deleted.
-func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) error {
+func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (err error) {
+	ctx, span := ds.startSpan(ctx, "DeleteFederationRelationship")
+	defer telemetry.EndSpan(span, &err)
+
 	if trustDomain.IsZero() {
 		return status.Error(codes.InvalidArgument, "trust domain is required")
 	}
@@ -696,6 +842,9 @@ func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain
 // FetchFederationRelationship fetches the federation relationship that matches
 // the given trust domain. If the federation relationship is not found, nil is returned.
 func (ds *Plugin) FetchFederationRelationship(ctx context.Context, trustDomain spiffeid.TrustDomain) (fr *datastore.FederationRelationship, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchFederationRelationship")
+	defer telemetry.EndSpan(span, &err)
+
 	if trustDomain.IsZero() {
 		return nil, status.Error(codes.InvalidArgument, "trust domain is required")
 	}
@@ -712,6 +861,9 @@ func (ds *Plugin) FetchFederationRelationship(ctx context.Context, trustDomain s
 
 // ListFederationRelationships can be used to list all existing federation relationships
 func (ds *Plugin) ListFederationRelationships(ctx context.Context, req *datastore.ListFederationRelationshipsRequest) (resp *datastore.ListFederationRelationshipsResponse, err error) {
+	ctx, span := ds.startSpan(ctx, "ListFederationRelationships")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = listFederationRelationships(tx, req)
 		return err
@@ -724,6 +876,9 @@ func (ds *Plugin) ListFederationRelationships(ctx context.Context, req *datastor
 // UpdateFederationRelationship updates the given federation relationship.
 // Attributes are only updated if the correspondent mask value is set to true.
 func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (newFr *datastore.FederationRelationship, err error) {
+	ctx, span := ds.startSpan(ctx, "UpdateFederationRelationship")
+	defer telemetry.EndSpan(span, &err)
+
 	if err := validateFederationRelationship(fr, mask); err != nil {
 		return nil, err
 	}
@@ -734,6 +889,53 @@ func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastor
 	})
 }
 
+// RevokeX509SVID records the revocation of an X509-SVID. An AlreadyExists
+// error is returned if the SVID issued by the same authority was already
+// revoked.
+func (ds *Plugin) RevokeX509SVID(ctx context.Context, revoked *datastore.RevokedX509SVID) (resp *datastore.RevokedX509SVID, err error) {
+	ctx, span := ds.startSpan(ctx, "RevokeX509SVID")
+	defer telemetry.EndSpan(span, &err)
+
+	if err := validateRevokedX509SVID(revoked); err != nil {
+		return nil, err
+	}
+
+	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = revokeX509SVID(tx, revoked)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListRevokedX509SVIDs returns all the revoked X509-SVIDs, ordered by
+// authority ID and serial number.
+func (ds *Plugin) ListRevokedX509SVIDs(ctx context.Context) (resp []*datastore.RevokedX509SVID, err error) {
+	ctx, span := ds.startSpan(ctx, "ListRevokedX509SVIDs")
+	defer telemetry.EndSpan(span, &err)
+
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listRevokedX509SVIDs(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PruneRevokedX509SVIDs deletes the revocations of X509-SVIDs known to have
+// expired before the given time.
+func (ds *Plugin) PruneRevokedX509SVIDs(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneRevokedX509SVIDs")
+	defer telemetry.EndSpan(span, &err)
+
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		err = pruneRevokedX509SVIDs(tx, expiresBefore)
+		return err
+	})
+}
+
 // SetUseServerTimestamps controls whether server-generated timestamps should be used in the database.
 // This is only intended to be used by tests in order to produce deterministic timestamp data,
 // since some databases round off timestamp data with lower precision.
@@ -744,6 +946,9 @@ func (ds *Plugin) SetUseServerTimestamps(useServerTimestamps bool) {
 // FetchCAJournal fetches the CA journal that has the given active X509
 // authority domain. If the CA journal is not found, nil is returned.
 func (ds *Plugin) FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (caJournal *datastore.CAJournal, err error) {
+	ctx, span := ds.startSpan(ctx, "FetchCAJournal")
+	defer telemetry.EndSpan(span, &err)
+
 	if activeX509AuthorityID == "" {
 		return nil, status.Error(codes.InvalidArgument, "active X509 authority ID is required")
 	}
@@ -758,11 +963,13 @@ func (ds *Plugin) FetchCAJournal(ctx context.Context, activeX509AuthorityID stri
 	return caJournal, nil
 }
 
-// ListCAJournalsForTesting returns all the CA journal records, and is meant to
-// be used in tests.
-func (ds *Plugin) ListCAJournalsForTesting(ctx context.Context) (caJournals []*datastore.CAJournal, err error) {
+// ListCAJournals returns all the CA journal records.
+func (ds *Plugin) ListCAJournals(ctx context.Context) (caJournals []*datastore.CAJournal, err error) {
+	ctx, span := ds.startSpan(ctx, "ListCAJournals")
+	defer telemetry.EndSpan(span, &err)
+
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
-		caJournals, err = listCAJournalsForTesting(tx)
+		caJournals, err = listCAJournals(tx)
 		return err
 	}); err != nil {
 		return nil, err
@@ -773,6 +980,9 @@ func (ds *Plugin) ListCAJournalsForTesting(ctx context.Context) (caJournals []*d
 // SetCAJournal sets the content for the specified CA journal. If the CA journal
 // does not exist, it is created.
 func (ds *Plugin) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (caj *datastore.CAJournal, err error) {
+	ctx, span := ds.startSpan(ctx, "SetCAJournal")
+	defer telemetry.EndSpan(span, &err)
+
 	if err := validateCAJournal(caJournal); err != nil {
 		return nil, err
 	}
@@ -794,7 +1004,10 @@ func (ds *Plugin) SetCAJournal(ctx context.Context, caJournal *datastore.CAJourn
 
 // PruneCAJournals prunes the CA journals that have all of their authorities
 // expired.
-func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefore int64) error {
+func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefore int64) (err error) {
+	ctx, span := ds.startSpan(ctx, "PruneCAJournals")
+	defer telemetry.EndSpan(span, &err)
+
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = ds.pruneCAJournals(tx, allAuthoritiesExpireBefore)
 		return err
@@ -1007,6 +1220,18 @@ func (ds *Plugin) withTx(ctx context.Context, op func(tx *gorm.DB) error, readOn
 	return newWrappedSQLError(tx.Commit().Error)
 }
 
+// startSpan starts a span for a datastore operation. The span is tagged with
+// the type of the configured database.
+func (ds *Plugin) startSpan(ctx context.Context, op string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("db.operation.name", op)}
+	ds.mu.Lock()
+	if ds.db != nil {
+		attrs = append(attrs, attribute.String("db.system", ds.db.databaseType))
+	}
+	ds.mu.Unlock()
+	return telemetry.StartSpan(ctx, "datastore."+op, attrs...)
+}
+
 // gormToGRPCStatus takes an error, and converts it to a GRPC error.  If the
 // error is already a gRPC status , it will be returned unmodified. Otherwise
 // if the error is a gorm error type with a known mapping to a GRPC status,
@@ -4156,6 +4381,19 @@ func fetchJoinToken(tx *gorm.DB, token string) (*datastore.JoinToken, error) {
 	return modelToJoinToken(model), nil
 }
 
+func listJoinTokens(tx *gorm.DB) ([]*datastore.JoinToken, error) {
+	var models []JoinToken
+	if err := tx.Order("token").Find(&models).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	tokens := make([]*datastore.JoinToken, 0, len(models))
+	for _, model := range models {
+		tokens = append(tokens, modelToJoinToken(model))
+	}
+	return tokens, nil
+}
+
 func deleteJoinToken(tx *gorm.DB, token string) error {
 	var model JoinToken
 	if err := tx.Find(&model, "token = ?", token).Error; err != nil {
@@ -4177,6 +4415,49 @@ func pruneJoinTokens(tx *gorm.DB, expiresBefore time.Time) error {
 	return nil
 }
 
+func revokeX509SVID(tx *gorm.DB, revoked *datastore.RevokedX509SVID) (*datastore.RevokedX509SVID, error) {
+	var count int
+	if err := tx.Model(&RevokedX509SVID{}).Where("authority_id = ? AND serial_number = ?", revoked.AuthorityID, revoked.SerialNumber).Count(&count).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	if count > 0 {
+		return nil, status.Errorf(codes.AlreadyExists, "X509-SVID with serial number %q issued by authority %q is already revoked", revoked.SerialNumber, revoked.AuthorityID)
+	}
+
+	model := RevokedX509SVID{
+		AuthorityID:  revoked.AuthorityID,
+		SerialNumber: revoked.SerialNumber,
+		RevokedAt:    revoked.RevokedAt,
+		ExpiresAt:    revoked.ExpiresAt,
+	}
+	if err := tx.Create(&model).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	return modelToRevokedX509SVID(model), nil
+}
+
+func listRevokedX509SVIDs(tx *gorm.DB) ([]*datastore.RevokedX509SVID, error) {
+	var models []RevokedX509SVID
+	if err := tx.Order("authority_id, serial_number").Find(&models).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	revoked := make([]*datastore.RevokedX509SVID, 0, len(models))
+	for _, model := range models {
+		revoked = append(revoked, modelToRevokedX509SVID(model))
+	}
+	return revoked, nil
+}
+
+func pruneRevokedX509SVIDs(tx *gorm.DB, expiresBefore time.Time) error {
+	if err := tx.Where("expires_at < ?", expiresBefore).Delete(&RevokedX509SVID{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	return nil
+}
+
 func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
 	model := FederatedTrustDomain{
 		TrustDomain:           fr.TrustDomain.Name(),
@@ -4384,50 +4665,7 @@ func modelToBundle(model *Bundle) (*common.Bundle, error) {
 }
 
 func validateRegistrationEntry(entry *common.RegistrationEntry) error {
-	if entry == nil {
-		return newValidationError("invalid request: missing registered entry")
-	}
-
-	if len(entry.Selectors) == 0 {
-		return newValidationError("invalid registration entry: missing selector list")
-	}
-
-	// In case of StoreSvid is set, all entries 'must' be the same type,
-	// it is done to avoid users to mix selectors from different platforms in
-	// entries with storable SVIDs
-	if entry.StoreSvid {
-		// Selectors must never be empty
-		tpe := entry.Selectors[0].Type
-		for _, t := range entry.Selectors {
-			if tpe != t.Type {
-				return newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
-			}
-		}
-	}
-
-	if len(entry.EntryId) > 255 {
-		return newValidationError("invalid registration entry: entry ID too long")
-	}
-
-	for _, e := range entry.EntryId {
-		if !unicode.In(e, validEntryIDChars) {
-			return newValidationError("invalid registration entry: entry ID contains invalid characters")
-		}
-	}
-
-	if len(entry.SpiffeId) == 0 {
-		return newValidationError("invalid registration entry: missing SPIFFE ID")
-	}
-
-	if entry.X509SvidTtl < 0 {
-		return newValidationError("invalid registration entry: X509SvidTtl is not set")
-	}
-
-	if entry.JwtSvidTtl < 0 {
-		return newValidationError("invalid registration entry: JwtSvidTtl is not set")
-	}
-
-	return nil
+	return newWrappedValidationError(datastore.ValidateRegistrationEntry(entry))
 }
 
 // equalSelectorTypes validates that all selectors has the same type,
@@ -4445,30 +4683,7 @@ func equalSelectorTypes(selectors []Selector) bool {
 }
 
 func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) error {
-	if entry == nil {
-		return newValidationError("invalid request: missing registered entry")
-	}
-
-	if (mask == nil || mask.Selectors) && len(entry.Selectors) == 0 {
-		return newValidationError("invalid registration entry: missing selector list")
-	}
-
-	if (mask == nil || mask.SpiffeId) &&
-		entry.SpiffeId == "" {
-		return newValidationError("invalid registration entry: missing SPIFFE ID")
-	}
-
-	if (mask == nil || mask.X509SvidTtl) &&
-		(entry.X509SvidTtl < 0) {
-		return newValidationError("invalid registration entry: X509SvidTtl is not set")
-	}
-
-	if (mask == nil || mask.JwtSvidTtl) &&
-		(entry.JwtSvidTtl < 0) {
-		return newValidationError("invalid registration entry: JwtSvidTtl is not set")
-	}
-
-	return nil
+	return newWrappedValidationError(datastore.ValidateRegistrationEntryForUpdate(entry, mask))
 }
 
 // bundleToModel converts the given Protobuf bundle message to a database model. It
@@ -4579,6 +4794,15 @@ func modelToJoinToken(model JoinToken) *datastore.JoinToken {
 	}
 }
 
+func modelToRevokedX509SVID(model RevokedX509SVID) *datastore.RevokedX509SVID {
+	return &datastore.RevokedX509SVID{
+		AuthorityID:  model.AuthorityID,
+		SerialNumber: model.SerialNumber,
+		RevokedAt:    model.RevokedAt,
+		ExpiresAt:    model.ExpiresAt,
+	}
+}
+
 func modelToCAJournal(model CAJournal) *datastore.CAJournal {
 	return &datastore.CAJournal{
 		ID:                    model.ID,
@@ -4704,23 +4928,17 @@ func nullableUnixTimeToDBTime(unixTime int64) *time.Time {
 }
 
 func lookupSimilarEntry(ctx context.Context, db *sqlDB, tx *gorm.DB, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
-	resp, err := listRegistrationEntriesOnce(ctx, tx.CommonDB().(queryContext), db.databaseType, db.supportsCTE, &datastore.ListRegistrationEntriesRequest{
-		BySpiffeID: entry.SpiffeId,
-		ByParentID: entry.ParentId,
-		BySelectors: &datastore.BySelectors{
-			Match:     datastore.Exact,
-			Selectors: entry.Selectors,
-		},
-	})
+	resp, err := listRegistrationEntriesOnce(ctx, tx.CommonDB().(queryContext), db.databaseType, db.supportsCTE, datastore.SimilarEntriesRequest(entry))
 	if err != nil {
 		return nil, err
 	}
 
 	// listRegistrationEntriesOnce returns both exact and superset matches.
 	// Filter out the superset matches to get an exact match
-	entries := filterEntriesBySelectorSet(resp.Entries, entry.Selectors)
-	if len(entries) > 0 {
-		return entries[0], nil
+	for _, e := range resp.Entries {
+		if datastore.IsSimilarEntry(e, entry) {
+			return e, nil
+		}
 	}
 
 	return nil, nil
@@ -4797,7 +5015,7 @@ func fetchCAJournal(tx *gorm.DB, activeX509AuthorityID string) (*datastore.CAJou
 	return modelToCAJournal(model), nil
 }
 
-func listCAJournalsForTesting(tx *gorm.DB) (caJournals []*datastore.CAJournal, err error) {
+func listCAJournals(tx *gorm.DB) (caJournals []*datastore.CAJournal, err error) {
 	var caJournalsModel []CAJournal
 	if err := tx.Find(&caJournalsModel).Error; err != nil {
 		return nil, newWrappedSQLError(err)
@@ -4833,6 +5051,20 @@ func validateCAJournal(caJournal *datastore.CAJournal) error {
 	return nil
 }
 
+func validateRevokedX509SVID(revoked *datastore.RevokedX509SVID) error {
+	switch {
+	case revoked == nil:
+		return status.Error(codes.InvalidArgument, "revoked X509-SVID is required")
+	case revoked.AuthorityID == "":
+		return status.Error(codes.InvalidArgument, "authority ID is required")
+	case revoked.SerialNumber == "":
+		return status.Error(codes.InvalidArgument, "serial number is required")
+	case revoked.ExpiresAt.IsZero():
+		return status.Error(codes.InvalidArgument, "expiration time is required")
+	}
+	return nil
+}
+
 func deleteCAJournal(tx *gorm.DB, caJournalID uint) error {
 	model := new(CAJournal)
 	if err := tx.Find(model, "id = ?", caJournalID).Error; err != nil {
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -24,15 +25,18 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/datastore/raftstore"
 	"github.com/spiffe/spire/proto/private/server/journal"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testca"
 	"github.com/spiffe/spire/test/testkey"
 	testutil "github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
@@ -60,23 +64,40 @@ const (
 	_expiredNotAfterString = "2018-01-10T01:34:00+00:00"
 	_validNotAfterString   = "2018-01-10T01:36:00+00:00"
 	_middleTimeString      = "2018-01-10T01:35:00+00:00"
-	_notFoundErrMsg        = "datastore-sql: record not found"
 )
 
 func TestPlugin(t *testing.T) {
 	spiretest.Run(t, new(PluginSuite))
 }
 
+// TestRaftPlugin runs the suite against the raft datastore, which is
+// configured through this plugin and must behave like the SQL datastore.
+func TestRaftPlugin(t *testing.T) {
+	if TestDialect != "" {
+		t.Skip("the raft datastore is not tested against external SQL backends")
+	}
+	spiretest.Run(t, &PluginSuite{raft: true})
+}
+
+// suiteDataStore is the datastore under test.
+type suiteDataStore interface {
+	datastore.DataStore
+	Configure(ctx context.Context, hclConfiguration string) error
+	Close() error
+}
+
 type PluginSuite struct {
 	spiretest.Suite
 
 	cert   *x509.Certificate
 	cacert *x509.Certificate
 
-	dir    string
-	nextID int
-	ds     *Plugin
-	hook   *test.Hook
+	raft       bool
+	raftTLSDir string
+	dir        string
+	nextID     int
+	ds         suiteDataStore
+	hook       *test.Hook
 
 	readOnlyDelay time.Duration
 }
@@ -115,6 +136,10 @@ func (s *PluginSuite) SetupSuite() {
 		s.Require().NoError(err, "failed to parse read-only delay")
 		s.readOnlyDelay = delay
 	}
+
+	if s.raft {
+		s.setupRaftTLS()
+	}
 }
 
 func (s *PluginSuite) SetupTest() {
@@ -128,7 +153,11 @@ func (s *PluginSuite) TearDownTest() {
 	}
 }
 
-func (s *PluginSuite) newPlugin() *Plugin {
+func (s *PluginSuite) newPlugin() suiteDataStore {
+	if s.raft {
+		return s.newRaftPlugin()
+	}
+
 	log, hook := test.NewNullLogger()
 	ds := New(log)
 	s.hook = hook
@@ -189,7 +218,67 @@ func (s *PluginSuite) newPlugin() *Plugin {
 	return ds
 }
 
+func (s *PluginSuite) newRaftPlugin() suiteDataStore {
+	log, hook := test.NewNullLogger()
+	ds := raftstore.New(log)
+	s.hook = hook
+
+	s.nextID++
+	dir := filepath.Join(s.dir, fmt.Sprintf("raft%d", s.nextID))
+	err := ds.Configure(ctx, fmt.Sprintf(`
+		database_type "raft" {
+			node_id = "a"
+			bind_address = "127.0.0.1:0"
+			data_dir = %q
+			heartbeat_timeout = "20ms"
+			tls {
+				cert_file_path = %q
+				key_file_path = %q
+				ca_file_path = %q
+			}
+		}
+	`, filepath.Join(dir, "data"), filepath.Join(s.raftTLSDir, "server.crt"), filepath.Join(s.raftTLSDir, "server.key"), filepath.Join(s.raftTLSDir, "ca.crt")))
+	s.Require().NoError(err)
+	return ds
+}
+
+// setupRaftTLS writes the TLS material shared by the raft datastores of the
+// suite. It is created once so that the suite does not use up the test keys.
+func (s *PluginSuite) setupRaftTLS() {
+	caCert, caKey := testca.CreateCACertificate(s.T(), nil, nil)
+	cert, key := testca.CreateX509Certificate(s.T(), caCert, caKey, testca.WithIPAddresses(net.ParseIP("127.0.0.1")))
+	keyPEM, err := pemutil.EncodePKCS8PrivateKey(key)
+	s.Require().NoError(err)
+
+	s.raftTLSDir = s.TempDir()
+	s.Require().NoError(os.WriteFile(filepath.Join(s.raftTLSDir, "ca.crt"), pemutil.EncodeCertificate(caCert), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.raftTLSDir, "server.crt"), pemutil.EncodeCertificate(cert), 0o600))
+	s.Require().NoError(os.WriteFile(filepath.Join(s.raftTLSDir, "server.key"), keyPEM, 0o600))
+}
+
+// sqlPlugin returns the SQL datastore under test. Tests that depend on the
+// SQL implementation are skipped when the suite runs against the raft
+// datastore.
+func (s *PluginSuite) sqlPlugin() *Plugin {
+	ds, ok := s.ds.(*Plugin)
+	if !ok {
+		s.T().Skip("test depends on the SQL implementation")
+	}
+	return ds
+}
+
+// errMsg returns the given error message with the prefix of the datastore
+// under test.
+func (s *PluginSuite) errMsg(msg string) string {
+	if s.raft {
+		return "datastore-raft: " + msg
+	}
+	return "datastore-sql: " + msg
+}
+
 func (s *PluginSuite) TestInvalidPluginConfiguration() {
+	s.sqlPlugin()
+
 	err := s.ds.Configure(ctx, `
 		database_type = "wrong"
 		connection_string = "bad"
@@ -198,6 +287,8 @@ func (s *PluginSuite) TestInvalidPluginConfiguration() {
 }
 
 func (s *PluginSuite) TestInvalidAWSConfiguration() {
+	s.sqlPlugin()
+
 	testCases := []struct {
 		name        string
 		config      string
@@ -227,6 +318,8 @@ func (s *PluginSuite) TestInvalidAWSConfiguration() {
 }
 
 func (s *PluginSuite) TestInvalidMySQLConfiguration() {
+	s.sqlPlugin()
+
 	err := s.ds.Configure(ctx, `
 		database_type = "mysql"
 		connection_string = "username:@tcp(127.0.0.1)/spire_test"
@@ -255,11 +348,11 @@ func (s *PluginSuite) TestBundleCRUD() {
 
 	// update non-existent
 	_, err = s.ds.UpdateBundle(ctx, bundle, nil)
-	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+	s.RequireGRPCStatus(err, codes.NotFound, s.errMsg("record not found"))
 
 	// delete non-existent
 	err = s.ds.DeleteBundle(ctx, "spiffe://foo", datastore.Restrict)
-	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+	s.RequireGRPCStatus(err, codes.NotFound, s.errMsg("record not found"))
 
 	// create
 	_, err = s.ds.CreateBundle(ctx, bundle)
@@ -647,7 +740,7 @@ func (s *PluginSuite) TestTaintX509CA() {
 
 	t.Run("bundle not found", func(t *testing.T) {
 		err := s.ds.TaintX509CA(ctx, "spiffe://foo", "foo")
-		spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, s.errMsg("record not found"))
 	})
 
 	// Create Malformed CA
@@ -712,7 +805,7 @@ func (s *PluginSuite) TestRevokeX509CA() {
 	// Bundle not found
 	t.Run("bundle not found", func(t *testing.T) {
 		err := s.ds.RevokeX509CA(ctx, "spiffe://foo", "foo")
-		spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, s.errMsg("record not found"))
 	})
 
 	// Create new bundle with two cert (one valid and one expired)
@@ -799,7 +892,7 @@ func (s *PluginSuite) TestTaintJWTKey() {
 
 	// Bundle not found
 	publicKey, err := s.ds.TaintJWTKey(ctx, "spiffe://foo", "key1")
-	spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, s.errMsg("record not found"))
 	require.Nil(t, publicKey)
 
 	_, err = s.ds.CreateBundle(ctx, bundle)
@@ -860,7 +953,7 @@ func (s *PluginSuite) TestRevokeJWTKey() {
 
 	// Bundle not found
 	publicKey, err := s.ds.RevokeJWTKey(ctx, "spiffe://foo", "key1")
-	spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
+	spiretest.RequireGRPCStatus(t, err, codes.NotFound, s.errMsg("record not found"))
 	require.Nil(t, publicKey)
 
 	_, err = s.ds.CreateBundle(ctx, bundle)
@@ -912,7 +1005,7 @@ func (s *PluginSuite) TestRevokeJWTKey() {
 	// Revoke successfully
 	publicKey, err = s.ds.RevokeJWTKey(ctx, "spiffe://foo", "key2")
 	require.NoError(t, err)
-	require.Equal(t, &common.PublicKey{Kid: "key2", TaintedKey: true}, publicKey)
+	spiretest.RequireProtoEqual(t, &common.PublicKey{Kid: "key2", TaintedKey: true}, publicKey)
 
 	expectedJWTKeys := []*common.PublicKey{{Kid: "key1"}}
 	validateBundle(expectedJWTKeys, 1)
@@ -1327,7 +1420,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 				CertNotAfter:     updatedExpires,
 			},
 			expCode: codes.NotFound,
-			expMsg:  _notFoundErrMsg,
+			expMsg:  s.errMsg("record not found"),
 		},
 		{
 			name: "update attested node with all false mask",
@@ -1439,7 +1532,7 @@ func (s *PluginSuite) TestDeleteAttestedNode() {
 
 	s.Run("delete non-existing attested node", func() {
 		_, err := s.ds.DeleteAttestedNode(ctx, entryFoo.SpiffeId)
-		s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+		s.RequireGRPCStatus(err, codes.NotFound, s.errMsg("record not found"))
 	})
 
 	s.Run("delete attested node that don't have selectors associated", func() {
@@ -1594,7 +1687,7 @@ func (s *PluginSuite) TestListAttestedNodeEvents() {
 			name:               "Setting both greater and less than",
 			greaterThanEventID: 1,
 			lessThanEventID:    1,
-			expectedErr:        "datastore-sql: can't set both greater and less than event id",
+			expectedErr:        s.errMsg("can't set both greater and less than event id"),
 		},
 	}
 	for _, test := range tests {
@@ -1790,8 +1883,8 @@ func (s *PluginSuite) TestListNodeSelectors() {
 
 func (s *PluginSuite) TestListNodeSelectorsGroupsBySpiffeID() {
 	insertSelector := func(id int, spiffeID, selectorType, selectorValue string) {
-		query := maybeRebind(s.ds.db.databaseType, "INSERT INTO node_resolver_map_entries(id, spiffe_id, type, value) VALUES (?, ?, ?, ?)")
-		_, err := s.ds.db.raw.Exec(query, id, spiffeID, selectorType, selectorValue)
+		query := maybeRebind(s.sqlPlugin().db.databaseType, "INSERT INTO node_resolver_map_entries(id, spiffe_id, type, value) VALUES (?, ?, ?, ?)")
+		_, err := s.sqlPlugin().db.raw.Exec(query, id, spiffeID, selectorType, selectorValue)
 		s.Require().NoError(err)
 	}
 
@@ -2270,9 +2363,16 @@ func (s *PluginSuite) TestPruneRegistrationEntries() {
 				require.Equal(t, createdRegistrationEntry.EntryId, resp.Events[0].EntryID)
 			}
 
-			if tt.expectedLastLog.Message == prunedLogMessage {
+			switch {
+			case tt.expectedLastLog.Message == prunedLogMessage:
 				spiretest.AssertLastLogs(t, s.hook.AllEntries(), []spiretest.LogEntry{tt.expectedLastLog})
-			} else {
+			case s.raft:
+				// The raft library logs from its own goroutines, so
+				// only check that nothing was pruned.
+				for _, entry := range s.hook.AllEntries() {
+					assert.NotEqual(t, prunedLogMessage, entry.Message)
+				}
+			default:
 				assert.Equal(t, s.hook.LastEntry().Message, tt.expectedLastLog.Message)
 			}
 		})
@@ -2292,6 +2392,9 @@ func (s *PluginSuite) TestListRegistrationEntries() {
 	s.testListRegistrationEntries(datastore.RequireCurrent)
 	s.testListRegistrationEntries(datastore.TolerateStale)
 
+	// The datastore of the last test case has been closed
+	s.ds = s.newPlugin()
+
 	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
 		Pagination: &datastore.Pagination{
 			PageSize: 0,
@@ -3043,7 +3146,7 @@ func (s *PluginSuite) TestListRegistrationEntriesWhenCruftRowsExist() {
 	// This is gross. Since the bug that left selectors around has been fixed
 	// (#1191), I'm not sure how else to test this other than just sneaking in
 	// there and removing the registered_entries row.
-	res, err := s.ds.db.raw.Exec("DELETE FROM registered_entries")
+	res, err := s.sqlPlugin().db.raw.Exec("DELETE FROM registered_entries")
 	s.Require().NoError(err)
 	rowsAffected, err := res.RowsAffected()
 	s.Require().NoError(err)
@@ -3091,7 +3194,7 @@ func (s *PluginSuite) TestUpdateRegistrationEntry() {
 
 	entry.EntryId = "badid"
 	_, err = s.ds.UpdateRegistrationEntry(ctx, entry, nil)
-	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+	s.RequireGRPCStatus(err, codes.NotFound, s.errMsg("record not found"))
 }
 
 func (s *PluginSuite) TestUpdateRegistrationEntryWithStoreSvid() {
@@ -3454,7 +3557,7 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 func (s *PluginSuite) TestDeleteRegistrationEntry() {
 	// delete non-existing
 	_, err := s.ds.DeleteRegistrationEntry(ctx, "badid")
-	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+	s.RequireGRPCStatus(err, codes.NotFound, s.errMsg("record not found"))
 
 	entry1 := s.createRegistrationEntry(&common.RegistrationEntry{
 		Selectors: []*common.Selector{
@@ -3495,7 +3598,7 @@ func (s *PluginSuite) TestDeleteRegistrationEntry() {
 
 	// Delete again must fails with Not Found
 	deletedEntry, err = s.ds.DeleteRegistrationEntry(ctx, entry1.EntryId)
-	s.Require().EqualError(err, "rpc error: code = NotFound desc = datastore-sql: record not found")
+	s.Require().EqualError(err, "rpc error: code = NotFound desc = "+s.errMsg("record not found"))
 	s.Require().Nil(deletedEntry)
 }
 
@@ -4085,7 +4188,7 @@ func (s *PluginSuite) TestDeleteBundleRestrictedByRegistrationEntries() {
 
 	// delete the bundle in RESTRICTED mode
 	err := s.ds.DeleteBundle(context.Background(), "spiffe://otherdomain.org", datastore.Restrict)
-	s.RequireErrorContains(err, "datastore-sql: cannot delete bundle; federated with 1 registration entries")
+	s.RequireErrorContains(err, s.errMsg("cannot delete bundle; federated with 1 registration entries"))
 }
 
 func (s *PluginSuite) TestDeleteBundleDeleteRegistrationEntries() {
@@ -4231,7 +4334,7 @@ func (s *PluginSuite) TestListRegistrationEntryEvents() {
 			name:               "Setting both greater and less than",
 			greaterThanEventID: 1,
 			lessThanEventID:    1,
-			expectedErr:        "datastore-sql: can't set both greater and less than event id",
+			expectedErr:        s.errMsg("can't set both greater and less than event id"),
 		},
 	}
 	for _, test := range tests {
@@ -4331,6 +4434,28 @@ func (s *PluginSuite) TestCreateAndFetchJoinToken() {
 	s.Equal(now, res.Expiry)
 }
 
+func (s *PluginSuite) TestListJoinTokens() {
+	resp, err := s.ds.ListJoinTokens(ctx)
+	s.Require().NoError(err)
+	s.Empty(resp)
+
+	now := time.Now().Truncate(time.Second)
+	joinToken1 := &datastore.JoinToken{
+		Token:  "foobar",
+		Expiry: now,
+	}
+	joinToken2 := &datastore.JoinToken{
+		Token:  "batbaz",
+		Expiry: now.Add(time.Hour),
+	}
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, joinToken1))
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, joinToken2))
+
+	resp, err = s.ds.ListJoinTokens(ctx)
+	s.Require().NoError(err)
+	s.Equal([]*datastore.JoinToken{joinToken2, joinToken1}, resp)
+}
+
 func (s *PluginSuite) TestDeleteJoinToken() {
 	now := time.Now().Truncate(time.Second)
 	joinToken1 := &datastore.JoinToken{
@@ -4399,6 +4524,72 @@ func (s *PluginSuite) TestPruneJoinTokens() {
 	s.Nil(resp)
 }
 
+func (s *PluginSuite) TestRevokedX509SVIDs() {
+	resp, err := s.ds.ListRevokedX509SVIDs(ctx)
+	s.Require().NoError(err)
+	s.Empty(resp)
+
+	now := time.Now().Truncate(time.Second)
+	revoked1 := &datastore.RevokedX509SVID{
+		AuthorityID:  "authority1",
+		SerialNumber: "2a",
+		RevokedAt:    now,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+	revoked2 := &datastore.RevokedX509SVID{
+		AuthorityID:  "authority1",
+		SerialNumber: "1b",
+		RevokedAt:    now,
+		ExpiresAt:    now.Add(2 * time.Hour),
+	}
+	// Same serial number as revoked1, issued by another authority
+	revoked3 := &datastore.RevokedX509SVID{
+		AuthorityID:  "authority2",
+		SerialNumber: "2a",
+		RevokedAt:    now,
+		ExpiresAt:    now.Add(2 * time.Hour),
+	}
+	created, err := s.ds.RevokeX509SVID(ctx, revoked1)
+	s.Require().NoError(err)
+	s.Equal(revoked1, created)
+	_, err = s.ds.RevokeX509SVID(ctx, revoked2)
+	s.Require().NoError(err)
+	_, err = s.ds.RevokeX509SVID(ctx, revoked3)
+	s.Require().NoError(err)
+
+	_, err = s.ds.RevokeX509SVID(ctx, revoked1)
+	s.RequireGRPCStatusContains(err, codes.AlreadyExists, `X509-SVID with serial number "2a" issued by authority "authority1" is already revoked`)
+	_, err = s.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{SerialNumber: "3c", ExpiresAt: now})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "authority ID is required")
+	_, err = s.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{AuthorityID: "authority1", ExpiresAt: now})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "serial number is required")
+
+	resp, err = s.ds.ListRevokedX509SVIDs(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(resp, 3)
+	s.Equal("authority1", resp[0].AuthorityID)
+	s.Equal("1b", resp[0].SerialNumber)
+	s.Equal(revoked2.ExpiresAt.Unix(), resp[0].ExpiresAt.Unix())
+	s.Equal("authority1", resp[1].AuthorityID)
+	s.Equal("2a", resp[1].SerialNumber)
+	s.Equal(revoked1.RevokedAt.Unix(), resp[1].RevokedAt.Unix())
+	s.Equal("authority2", resp[2].AuthorityID)
+	s.Equal("2a", resp[2].SerialNumber)
+
+	// Ensure we don't prune on the exact ExpiresBefore
+	s.Require().NoError(s.ds.PruneRevokedX509SVIDs(ctx, revoked1.ExpiresAt))
+	resp, err = s.ds.ListRevokedX509SVIDs(ctx)
+	s.Require().NoError(err)
+	s.Len(resp, 3)
+
+	s.Require().NoError(s.ds.PruneRevokedX509SVIDs(ctx, revoked1.ExpiresAt.Add(time.Second)))
+	resp, err = s.ds.ListRevokedX509SVIDs(ctx)
+	s.Require().NoError(err)
+	s.Require().Len(resp, 2)
+	s.Equal("1b", resp[0].SerialNumber)
+	s.Equal("authority2", resp[1].AuthorityID)
+}
+
 func (s *PluginSuite) TestDeleteFederationRelationship() {
 	testCases := []struct {
 		name        string
@@ -4421,7 +4612,7 @@ func (s *PluginSuite) TestDeleteFederationRelationship() {
 		{
 			name:        "deleting an unexistent federation relationship returns not found",
 			trustDomain: spiffeid.RequireTrustDomainFromString("non-existent-td.org"),
-			expErr:      "rpc error: code = NotFound desc = datastore-sql: record not found",
+			expErr:      "rpc error: code = NotFound desc = " + s.errMsg("record not found"),
 		},
 		{
 			name:   "deleting a federation relationship using an empty trust domain fails nicely",
@@ -4517,7 +4708,7 @@ func (s *PluginSuite) TestFetchFederationRelationship() {
 					BundleEndpointURL:     "not-valid-endpoint-url%",
 					BundleEndpointProfile: string(datastore.BundleEndpointWeb),
 				}
-				s.Require().NoError(s.ds.db.Create(&model).Error)
+				s.Require().NoError(s.sqlPlugin().db.Create(&model).Error)
 				return nil
 			}(),
 		},
@@ -4532,7 +4723,7 @@ func (s *PluginSuite) TestFetchFederationRelationship() {
 					BundleEndpointProfile: string(datastore.BundleEndpointSPIFFE),
 					EndpointSPIFFEID:      "invalid-id",
 				}
-				s.Require().NoError(s.ds.db.Create(&model).Error)
+				s.Require().NoError(s.sqlPlugin().db.Create(&model).Error)
 				return nil
 			}(),
 		},
@@ -4546,7 +4737,7 @@ func (s *PluginSuite) TestFetchFederationRelationship() {
 					BundleEndpointURL:     "corrupted-endpoint-profile.org/bundleendpoint",
 					BundleEndpointProfile: "other",
 				}
-				s.Require().NoError(s.ds.db.Create(&model).Error)
+				s.Require().NoError(s.sqlPlugin().db.Create(&model).Error)
 				return nil
 			}(),
 		},
@@ -5042,6 +5233,8 @@ func (s *PluginSuite) TestUpdateFederationRelationship() {
 }
 
 func (s *PluginSuite) TestMigration() {
+	s.sqlPlugin()
+
 	for schemaVersion := range latestSchemaVersion {
 		s.T().Run(fmt.Sprintf("migration_from_schema_version_%d", schemaVersion), func(t *testing.T) {
 			require := require.New(t)
@@ -5089,6 +5282,9 @@ func (s *PluginSuite) TestMigration() {
 			// of SPIRE server and no longer have migration code.
 			case 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22:
 				prepareDB(false)
+			case 23:
+				prepareDB(true)
+				require.True(s.sqlPlugin().db.HasTable(&RevokedX509SVID{}))
 			default:
 				t.Fatalf("no migration test added for schema version %d", schemaVersion)
 			}
@@ -5098,7 +5294,7 @@ func (s *PluginSuite) TestMigration() {
 
 func (s *PluginSuite) TestPristineDatabaseMigrationValues() {
 	var m Migration
-	s.Require().NoError(s.ds.db.First(&m).Error)
+	s.Require().NoError(s.sqlPlugin().db.First(&m).Error)
 	s.Equal(latestSchemaVersion, m.Version)
 	s.Equal(codeVersion.String(), m.CodeVersion)
 }
@@ -5152,7 +5348,7 @@ func (s *PluginSuite) TestSetCAJournal() {
 		{
 			name: "try to update a non existing CA journal",
 			code: codes.NotFound,
-			msg:  "datastore-sql: record not found",
+			msg:  s.errMsg("record not found"),
 			caJournal: &datastore.CAJournal{
 				ID:                    999,
 				Data:                  []byte("test data"),
@@ -5591,7 +5787,7 @@ func makeID(suffix string) string {
 	return "spiffe://example.org/" + suffix
 }
 
-func createBundles(t *testing.T, ds *Plugin, trustDomains []string) {
+func createBundles(t *testing.T, ds datastore.DataStore, trustDomains []string) {
 	for _, td := range trustDomains {
 		_, err := ds.CreateBundle(ctx, &common.Bundle{
 			TrustDomainId: td,
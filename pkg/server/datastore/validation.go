@@ -0,0 +1,137 @@
+package datastore
+
+import (
+	"errors"
+	"maps"
+	"unicode"
+
+	"github.com/spiffe/spire/proto/spire/common"
+)
+
+var validEntryIDChars = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x002d, 0x002e, 1}, // - | .
+		{0x0030, 0x0039, 1}, // [0-9]
+		{0x0041, 0x005a, 1}, // [A-Z]
+		{0x005f, 0x005f, 1}, // _
+		{0x0061, 0x007a, 1}, // [a-z]
+	},
+	LatinOffset: 5,
+}
+
+// ValidateRegistrationEntry validates a registration entry before it is
+// created. DataStore implementations wrap the returned error with their own
+// validation error.
+func ValidateRegistrationEntry(entry *common.RegistrationEntry) error {
+	if entry == nil {
+		return errors.New("invalid request: missing registered entry")
+	}
+
+	if len(entry.Selectors) == 0 {
+		return errors.New("invalid registration entry: missing selector list")
+	}
+
+	// In case of StoreSvid is set, all entries 'must' be the same type,
+	// it is done to avoid users to mix selectors from different platforms in
+	// entries with storable SVIDs
+	if entry.StoreSvid {
+		// Selectors must never be empty
+		tpe := entry.Selectors[0].Type
+		for _, t := range entry.Selectors {
+			if tpe != t.Type {
+				return errors.New("invalid registration entry: selector types must be the same when store SVID is enabled")
+			}
+		}
+	}
+
+	if len(entry.EntryId) > 255 {
+		return errors.New("invalid registration entry: entry ID too long")
+	}
+
+	for _, e := range entry.EntryId {
+		if !unicode.In(e, validEntryIDChars) {
+			return errors.New("invalid registration entry: entry ID contains invalid characters")
+		}
+	}
+
+	if len(entry.SpiffeId) == 0 {
+		return errors.New("invalid registration entry: missing SPIFFE ID")
+	}
+
+	if entry.X509SvidTtl < 0 {
+		return errors.New("invalid registration entry: X509SvidTtl is not set")
+	}
+
+	if entry.JwtSvidTtl < 0 {
+		return errors.New("invalid registration entry: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
+
+// ValidateRegistrationEntryForUpdate validates the fields of a registration
+// entry selected by the mask before the entry is updated. DataStore
+// implementations wrap the returned error with their own validation error.
+func ValidateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) error {
+	if entry == nil {
+		return errors.New("invalid request: missing registered entry")
+	}
+
+	if (mask == nil || mask.Selectors) && len(entry.Selectors) == 0 {
+		return errors.New("invalid registration entry: missing selector list")
+	}
+
+	if (mask == nil || mask.SpiffeId) &&
+		entry.SpiffeId == "" {
+		return errors.New("invalid registration entry: missing SPIFFE ID")
+	}
+
+	if (mask == nil || mask.X509SvidTtl) &&
+		(entry.X509SvidTtl < 0) {
+		return errors.New("invalid registration entry: X509SvidTtl is not set")
+	}
+
+	if (mask == nil || mask.JwtSvidTtl) &&
+		(entry.JwtSvidTtl < 0) {
+		return errors.New("invalid registration entry: JwtSvidTtl is not set")
+	}
+
+	return nil
+}
+
+// SimilarEntriesRequest returns the request listing the candidates for
+// entries similar to the given entry. The listed entries must still be
+// checked with IsSimilarEntry, since the exact selector match also returns
+// entries with additional selectors.
+func SimilarEntriesRequest(entry *common.RegistrationEntry) *ListRegistrationEntriesRequest {
+	return &ListRegistrationEntriesRequest{
+		BySpiffeID: entry.SpiffeId,
+		ByParentID: entry.ParentId,
+		BySelectors: &BySelectors{
+			Match:     Exact,
+			Selectors: entry.Selectors,
+		},
+	}
+}
+
+// IsSimilarEntry returns true if both entries have the same SPIFFE ID, parent
+// ID and set of selectors. Creating an entry similar to an existing one
+// returns the existing entry instead.
+func IsSimilarEntry(a, b *common.RegistrationEntry) bool {
+	return a.SpiffeId == b.SpiffeId &&
+		a.ParentId == b.ParentId &&
+		maps.Equal(selectorSet(a.Selectors), selectorSet(b.Selectors))
+}
+
+type selectorKey struct {
+	Type  string
+	Value string
+}
+
+func selectorSet(selectors []*common.Selector) map[selectorKey]struct{} {
+	set := make(map[selectorKey]struct{}, len(selectors))
+	for _, s := range selectors {
+		set[selectorKey{Type: s.Type, Value: s.Value}] = struct{}{}
+	}
+	return set
+}
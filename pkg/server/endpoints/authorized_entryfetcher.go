@@ -64,6 +64,11 @@ func (a *AuthorizedEntryFetcherWithEventsBasedCache) FetchAuthorizedEntries(_ co
 	return a.cache.GetAuthorizedEntries(agentID), nil
 }
 
+// SnapshotAuthorizedEntries returns a copy of the authorized entries cache.
+func (a *AuthorizedEntryFetcherWithEventsBasedCache) SnapshotAuthorizedEntries(context.Context) (*authorizedentries.Cache, error) {
+	return a.cache.Clone(), nil
+}
+
 // RunUpdateCacheTask starts a ticker which rebuilds the in-memory entry cache.
 func (a *AuthorizedEntryFetcherWithEventsBasedCache) RunUpdateCacheTask(ctx context.Context) error {
 	for {
@@ -18,4 +18,22 @@ type EndpointConfig struct {
 	DiskCertManager *diskcertmanager.DiskCertManager
 
 	RefreshHint time.Duration
+
+	// X509SVIDRevocation, if set, publishes the revocation status of
+	// X509-SVIDs over the bundle endpoint.
+	X509SVIDRevocation *X509SVIDRevocationConfig
+
+	// SignBundle, if true, serves a detached JWS signature over the bundle,
+	// made with the current JWT authority.
+	SignBundle bool
+}
+
+type X509SVIDRevocationConfig struct {
+	// URL is the base URL under which the bundle endpoint is reachable by
+	// the parties checking the revocation status of X509-SVIDs. It is
+	// embedded in the X509-SVIDs issued by the server.
+	URL string
+
+	// TTL is the validity period of the published CRLs and OCSP responses.
+	TTL time.Duration
 }
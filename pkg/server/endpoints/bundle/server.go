@@ -1,16 +1,34 @@
 package bundle
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/pkg/server/ca"
+)
+
+const (
+	// maxOCSPRequestSize is the maximum size of the body of an OCSP
+	// request. Requests for a single certificate are well under it.
+	maxOCSPRequestSize = 10 * 1024
+
+	// SignaturePath is the path under which the detached JWS signature over
+	// the bundle is served.
+	SignaturePath = "/bundle.jws"
 )
 
 type Getter interface {
@@ -27,6 +45,24 @@ type ServerAuth interface {
 	GetTLSConfig() *tls.Config
 }
 
+// RevocationProvider provides the revocation status of the X509-SVIDs issued
+// by the server, which is published alongside the bundle.
+type RevocationProvider interface {
+	// CRL returns the DER encoded CRL for the X.509 authority with the given
+	// authority ID. It returns false if no CRL is available for it.
+	CRL(authorityID string) ([]byte, bool)
+
+	// OCSP returns the DER encoded OCSP response for the given DER encoded
+	// OCSP request.
+	OCSP(request []byte) []byte
+}
+
+// JWTKeySource provides the JWT authority currently used by the server to
+// sign JWT-SVIDs.
+type JWTKeySource interface {
+	JWTKey() *ca.JWTKey
+}
+
 type ServerConfig struct {
 	Log         logrus.FieldLogger
 	Address     string
@@ -34,18 +70,41 @@ type ServerConfig struct {
 	ServerAuth  ServerAuth
 	RefreshHint time.Duration
 
+	// Revocation, if set, serves CRLs under /crl/<authority ID> and OCSP
+	// responses under /ocsp.
+	Revocation RevocationProvider
+
+	// JWTKeySource, if set, serves a detached JWS signature over the bundle,
+	// made with the current JWT authority, under SignaturePath.
+	JWTKeySource JWTKeySource
+
 	// test hooks
 	listen func(network, address string) (net.Listener, error)
+	now    func() time.Time
 }
 
 type Server struct {
 	c ServerConfig
+
+	mu sync.Mutex
+	// etag and lastModified are the entity tag of the bundle document last
+	// served and the time it was first served.
+	etag         string
+	lastModified time.Time
+	// signature is the detached JWS over the bundle document with entity tag
+	// signatureETag, made with the JWT authority with ID signatureKid.
+	signature     string
+	signatureETag string
+	signatureKid  string
 }
 
 func NewServer(config ServerConfig) *Server {
 	if config.listen == nil {
 		config.listen = net.Listen
 	}
+	if config.now == nil {
+		config.now = time.Now
+	}
 	return &Server{
 		c: config,
 	}
@@ -84,11 +143,25 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 }
 
 func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
-	if req.Method != "GET" {
+	if s.c.Revocation != nil {
+		switch {
+		case strings.HasPrefix(req.URL.Path, "/crl/"):
+			s.serveCRL(w, req)
+			return
+		case req.URL.Path == "/ocsp" || strings.HasPrefix(req.URL.Path, "/ocsp/"):
+			s.serveOCSP(w, req)
+			return
+		}
+	}
+
+	if req.Method != "GET" && req.Method != "HEAD" {
 		http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if req.URL.Path != "/" {
+	switch {
+	case req.URL.Path == "/":
+	case req.URL.Path == SignaturePath && s.c.JWTKeySource != nil:
+	default:
 		http.NotFound(w, req)
 		return
 	}
@@ -112,8 +185,114 @@ func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_, _ = w.Write(jsonBytes)
+	etag, lastModified := s.trackBundle(jsonBytes)
+	w.Header().Set("ETag", etag)
+
+	content := jsonBytes
+	if req.URL.Path == SignaturePath {
+		signature, err := s.signBundle(jsonBytes, etag)
+		if err != nil {
+			s.c.Log.WithError(err).Error("Unable to sign local bundle")
+			http.Error(w, "500 unable to sign local bundle", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/jose")
+		content = []byte(signature)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	// ServeContent handles the If-None-Match and If-Modified-Since
+	// conditional request headers.
+	http.ServeContent(w, req, "", lastModified, bytes.NewReader(content))
+}
+
+// trackBundle returns the entity tag of the bundle document and the time it
+// was first served. The signature served under SignaturePath shares the
+// entity tag of the bundle it was made for.
+func (s *Server) trackBundle(doc []byte) (string, time.Time) {
+	sum := sha256.Sum256(doc)
+	etag := `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if etag != s.etag {
+		s.etag = etag
+		s.lastModified = s.c.now()
+	}
+	return s.etag, s.lastModified
+}
+
+// signBundle returns a detached JWS over the bundle document. Signatures are
+// reused until the bundle or the JWT authority changes.
+func (s *Server) signBundle(doc []byte, etag string) (string, error) {
+	jwtKey := s.c.JWTKeySource.JWTKey()
+	if jwtKey == nil {
+		return "", errors.New("JWT key is not available for signing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.signatureETag == etag && s.signatureKid == jwtKey.Kid {
+		return s.signature, nil
+	}
+
+	signature, err := bundleutil.SignBundle(doc, jwtKey.Signer, jwtKey.Kid)
+	if err != nil {
+		return "", err
+	}
+	s.signature = signature
+	s.signatureETag = etag
+	s.signatureKid = jwtKey.Kid
+	return signature, nil
+}
+
+func (s *Server) serveCRL(w http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	crl, ok := s.c.Revocation.CRL(strings.TrimPrefix(req.URL.Path, "/crl/"))
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pkix-crl")
+	_, _ = w.Write(crl)
+}
+
+func (s *Server) serveOCSP(w http.ResponseWriter, req *http.Request) {
+	var ocspReq []byte
+	switch req.Method {
+	case "GET":
+		// RFC 6960, appendix A.1: GET requests carry the base64 encoded
+		// request in the path.
+		var err error
+		ocspReq, err = base64.StdEncoding.DecodeString(strings.TrimPrefix(req.URL.Path, "/ocsp/"))
+		if err != nil {
+			http.Error(w, "400 malformed OCSP request", http.StatusBadRequest)
+			return
+		}
+	case "POST":
+		if req.URL.Path != "/ocsp" {
+			http.NotFound(w, req)
+			return
+		}
+		var err error
+		ocspReq, err = io.ReadAll(http.MaxBytesReader(w, req.Body, maxOCSPRequestSize))
+		if err != nil {
+			http.Error(w, "400 malformed OCSP request", http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	_, _ = w.Write(s.c.Revocation.OCSP(ocspReq))
 }
 
 func chainDER(chain []*x509.Certificate) [][]byte {
@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,8 +23,10 @@ import (
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/diskcertmanager"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle/internal/acmetest"
 	"github.com/spiffe/spire/test/fakes/fakeserverkeymanager"
 	"github.com/spiffe/spire/test/spiretest"
@@ -180,6 +183,213 @@ func TestServer(t *testing.T) {
 	}
 }
 
+func TestServerRevocation(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCert)
+	client := http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    rootCAs,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+
+	addr, done := newTestServerWithConfig(t, ServerConfig{
+		Getter:     testGetter(nil),
+		ServerAuth: testSPIFFEAuth(serverCert, serverKey),
+		Revocation: fakeRevocationProvider{},
+	})
+	defer done()
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		status      int
+		contentType string
+		respBody    string
+	}{
+		{
+			name:        "CRL",
+			method:      "GET",
+			path:        "/crl/authority",
+			status:      http.StatusOK,
+			contentType: "application/pkix-crl",
+			respBody:    "crl-authority",
+		},
+		{
+			name:     "CRL for unknown authority",
+			method:   "GET",
+			path:     "/crl/unknown",
+			status:   http.StatusNotFound,
+			respBody: "404 page not found\n",
+		},
+		{
+			name:     "CRL with invalid method",
+			method:   "POST",
+			path:     "/crl/authority",
+			status:   http.StatusMethodNotAllowed,
+			respBody: "405 method not allowed\n",
+		},
+		{
+			name:        "OCSP POST",
+			method:      "POST",
+			path:        "/ocsp",
+			body:        "request",
+			status:      http.StatusOK,
+			contentType: "application/ocsp-response",
+			respBody:    "ocsp-request",
+		},
+		{
+			name:        "OCSP GET",
+			method:      "GET",
+			path:        "/ocsp/" + url.PathEscape(base64.StdEncoding.EncodeToString([]byte("request"))),
+			status:      http.StatusOK,
+			contentType: "application/ocsp-response",
+			respBody:    "ocsp-request",
+		},
+		{
+			name:     "OCSP GET with malformed request",
+			method:   "GET",
+			path:     "/ocsp/not-base64",
+			status:   http.StatusBadRequest,
+			respBody: "400 malformed OCSP request\n",
+		},
+		{
+			name:     "OCSP with invalid method",
+			method:   "PUT",
+			path:     "/ocsp",
+			status:   http.StatusMethodNotAllowed,
+			respBody: "405 method not allowed\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			req, err := http.NewRequest(testCase.method, fmt.Sprintf("https://%s%s", addr, testCase.path), strings.NewReader(testCase.body))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			actual, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.status, resp.StatusCode)
+			require.Equal(t, testCase.respBody, string(actual))
+			if testCase.contentType != "" {
+				require.Equal(t, testCase.contentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestServerConditionalRequests(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	client := newTestClient(serverCert)
+
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+	bundle := spiffebundle.New(trustDomain)
+	bundle.AddX509Authority(serverCert)
+
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	addr, done := newTestServerWithConfig(t, ServerConfig{
+		Getter:      testGetter(bundle),
+		ServerAuth:  testSPIFFEAuth(serverCert, serverKey),
+		RefreshHint: 5 * time.Minute,
+		now:         func() time.Time { return lastModified },
+	})
+	defer done()
+
+	get := func(header http.Header) *http.Response {
+		req, err := http.NewRequest("GET", fmt.Sprintf("https://%s/", addr), nil)
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get(http.Header{})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, lastModified.Format(http.TimeFormat), resp.Header.Get("Last-Modified"))
+
+	resp = get(http.Header{"If-None-Match": []string{etag}})
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+	require.Equal(t, etag, resp.Header.Get("ETag"))
+
+	resp = get(http.Header{"If-None-Match": []string{`"stale"`}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = get(http.Header{"If-Modified-Since": []string{lastModified.Format(http.TimeFormat)}})
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp = get(http.Header{"If-Modified-Since": []string{lastModified.Add(-time.Second).Format(http.TimeFormat)}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// A new bundle gets a new entity tag
+	require.NoError(t, bundle.AddJWTAuthority("KID", serverKey.Public()))
+	resp = get(http.Header{"If-None-Match": []string{etag}})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEqual(t, etag, resp.Header.Get("ETag"))
+}
+
+func TestServerSignature(t *testing.T) {
+	serverCert, serverKey := createServerCertificate(t)
+	client := newTestClient(serverCert)
+
+	trustDomain := spiffeid.RequireTrustDomainFromString("domain.test")
+	bundle := spiffebundle.New(trustDomain)
+	bundle.AddX509Authority(serverCert)
+	require.NoError(t, bundle.AddJWTAuthority("KID", serverKey.Public()))
+
+	jwtKeySource := &fakeJWTKeySource{}
+	addr, done := newTestServerWithConfig(t, ServerConfig{
+		Getter:       testGetter(bundle),
+		ServerAuth:   testSPIFFEAuth(serverCert, serverKey),
+		JWTKeySource: jwtKeySource,
+	})
+	defer done()
+
+	get := func(path string) (*http.Response, []byte) {
+		resp, err := client.Get(fmt.Sprintf("https://%s%s", addr, path))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, body
+	}
+
+	// No JWT key available yet
+	resp, body := get(SignaturePath)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Equal(t, "500 unable to sign local bundle\n", string(body))
+
+	jwtKeySource.jwtKey = &ca.JWTKey{Signer: serverKey, Kid: "KID"}
+
+	bundleResp, doc := get("/")
+	require.Equal(t, http.StatusOK, bundleResp.StatusCode)
+
+	resp, signature := get(SignaturePath)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/jose", resp.Header.Get("Content-Type"))
+	require.Equal(t, bundleResp.Header.Get("ETag"), resp.Header.Get("ETag"))
+	require.NoError(t, bundleutil.VerifyBundleSignature(doc, string(signature), bundle))
+
+	// The signature is reused while the bundle and the JWT key do not change
+	_, again := get(SignaturePath)
+	require.Equal(t, signature, again)
+}
+
 func TestDiskCertManagerAuth(t *testing.T) {
 	dir := spiretest.TempDir(t)
 	serverCert, serverKey := createServerCertificate(t)
@@ -358,7 +568,28 @@ func TestACMEAuth(t *testing.T) {
 	})
 }
 
+func newTestClient(serverCert *x509.Certificate) *http.Client {
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCert)
+	return &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    rootCAs,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+}
+
 func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth, refreshHint time.Duration) (net.Addr, func()) {
+	return newTestServerWithConfig(t, ServerConfig{
+		Getter:      getter,
+		ServerAuth:  serverAuth,
+		RefreshHint: refreshHint,
+	})
+}
+
+func newTestServerWithConfig(t *testing.T, config ServerConfig) (net.Addr, func()) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	addrCh := make(chan net.Addr, 1)
@@ -372,14 +603,10 @@ func newTestServer(t *testing.T, getter Getter, serverAuth ServerAuth, refreshHi
 	}
 
 	log, _ := test.NewNullLogger()
-	server := NewServer(ServerConfig{
-		Log:         log,
-		Address:     "localhost:0",
-		Getter:      getter,
-		ServerAuth:  serverAuth,
-		listen:      listen,
-		RefreshHint: refreshHint,
-	})
+	config.Log = log
+	config.Address = "localhost:0"
+	config.listen = listen
+	server := NewServer(config)
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -430,3 +657,24 @@ func createServerCertificate(t *testing.T) (*x509.Certificate, crypto.Signer) {
 		URIs:         []*url.URL{{Scheme: "https", Host: "domain.test", Path: "/spire/server"}},
 	})
 }
+
+type fakeRevocationProvider struct{}
+
+func (fakeRevocationProvider) CRL(authorityID string) ([]byte, bool) {
+	if authorityID != "authority" {
+		return nil, false
+	}
+	return []byte("crl-" + authorityID), true
+}
+
+func (fakeRevocationProvider) OCSP(request []byte) []byte {
+	return append([]byte("ocsp-"), request...)
+}
+
+type fakeJWTKeySource struct {
+	jwtKey *ca.JWTKey
+}
+
+func (s *fakeJWTKeySource) JWTKey() *ca.JWTKey {
+	return s.jwtKey
+}
@@ -23,7 +23,9 @@ import (
 	healthv1 "github.com/spiffe/spire/pkg/server/api/health/v1"
 	localauthorityv1 "github.com/spiffe/spire/pkg/server/api/localauthority/v1"
 	loggerv1 "github.com/spiffe/spire/pkg/server/api/logger/v1"
+	"github.com/spiffe/spire/pkg/server/api/middleware"
 	svidv1 "github.com/spiffe/spire/pkg/server/api/svid/v1"
+	svidrevocationv1 "github.com/spiffe/spire/pkg/server/api/svidrevocation/v1"
 	trustdomainv1 "github.com/spiffe/spire/pkg/server/api/trustdomain/v1"
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	bundle_client "github.com/spiffe/spire/pkg/server/bundle/client"
@@ -55,9 +57,17 @@ type Config struct {
 	// Server CA for signing SVIDs
 	ServerCA ca.ServerCA
 
+	// JWTKeySource provides the JWT authority used to sign the bundle served
+	// by the bundle endpoint, when bundle signing is enabled.
+	JWTKeySource bundle.JWTKeySource
+
 	// Bundle endpoint configuration
 	BundleEndpoint bundle.EndpointConfig
 
+	// RevocationPublisher, if set, publishes the revocation status of
+	// X509-SVIDs over the bundle endpoint.
+	RevocationPublisher bundle.RevocationProvider
+
 	// Authority manager
 	AuthorityManager manager.AuthorityManager
 
@@ -139,6 +149,11 @@ func (c *Config) maybeMakeBundleEndpointServer() (Server, func(context.Context)
 		})
 	}
 
+	var jwtKeySource bundle.JWTKeySource
+	if c.BundleEndpoint.SignBundle {
+		jwtKeySource = c.JWTKeySource
+	}
+
 	ds := c.Catalog.GetDataStore()
 	return bundle.NewServer(bundle.ServerConfig{
 		Log:     c.Log.WithField(telemetry.SubsystemName, "bundle_endpoint"),
@@ -153,8 +168,10 @@ func (c *Config) maybeMakeBundleEndpointServer() (Server, func(context.Context)
 			}
 			return bundleutil.SPIFFEBundleFromProto(commonBundle)
 		}),
-		RefreshHint: c.BundleEndpoint.RefreshHint,
-		ServerAuth:  serverAuth,
+		RefreshHint:  c.BundleEndpoint.RefreshHint,
+		ServerAuth:   serverAuth,
+		Revocation:   c.RevocationPublisher,
+		JWTKeySource: jwtKeySource,
 	}), certificateReloadTask
 }
 
@@ -162,14 +179,34 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 	ds := c.Catalog.GetDataStore()
 	upstreamPublisher := UpstreamPublisher(c.AuthorityManager)
 
+	// Entries are only authorized individually when the policy can deny them,
+	// saving a policy evaluation per entry otherwise.
+	var entryAuthorizer entryv1.EntryAuthorizer
+	if c.AuthPolicyEngine != nil && c.AuthPolicyEngine.DefinesAllowEntry() {
+		entryAuthorizer = middleware.NewPolicyEntryAuthorizer(c.AuthPolicyEngine)
+	}
+
+	authorizedEntries, _ := entryFetcher.(entryv1.AuthorizedEntriesSnapshotter)
+
+	agentServer := agentv1.New(agentv1.Config{
+		DataStore:   ds,
+		ServerCA:    c.ServerCA,
+		TrustDomain: c.TrustDomain,
+		Catalog:     c.Catalog,
+		Clock:       c.Clock,
+	})
+
+	entryServer := entryv1.New(entryv1.Config{
+		TrustDomain:       c.TrustDomain,
+		DataStore:         ds,
+		EntryFetcher:      entryFetcher,
+		EntryAuthorizer:   entryAuthorizer,
+		AuthorizedEntries: authorizedEntries,
+	})
+
 	return APIServers{
-		AgentServer: agentv1.New(agentv1.Config{
-			DataStore:   ds,
-			ServerCA:    c.ServerCA,
-			TrustDomain: c.TrustDomain,
-			Catalog:     c.Catalog,
-			Clock:       c.Clock,
-		}),
+		AgentServer:      agentServer,
+		AgentBatchServer: agentServer,
 		BundleServer: bundlev1.New(bundlev1.Config{
 			TrustDomain:       c.TrustDomain,
 			DataStore:         ds,
@@ -182,11 +219,8 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			SVIDObserver: c.SVIDObserver,
 			Uptime:       c.Uptime,
 		}),
-		EntryServer: entryv1.New(entryv1.Config{
-			TrustDomain:  c.TrustDomain,
-			DataStore:    ds,
-			EntryFetcher: entryFetcher,
-		}),
+		EntryServer:       entryServer,
+		EntryDryRunServer: entryServer,
 		HealthServer: healthv1.New(healthv1.Config{
 			TrustDomain: c.TrustDomain,
 			DataStore:   ds,
@@ -200,6 +234,7 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			ServerCA:                     c.ServerCA,
 			DataStore:                    ds,
 			UseLegacyDownstreamX509CATTL: c.UseLegacyDownstreamX509CATTL,
+			HasCredentialComposers:       len(c.Catalog.GetCredentialComposers()) > 0,
 		}),
 		TrustDomainServer: trustdomainv1.New(trustdomainv1.Config{
 			TrustDomain:     c.TrustDomain,
@@ -211,5 +246,10 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			CAManager:   c.AuthorityManager,
 			DataStore:   ds,
 		}),
+		SVIDRevocationServer: svidrevocationv1.New(svidrevocationv1.Config{
+			TrustDomain: c.TrustDomain,
+			DataStore:   ds,
+			Clock:       c.Clock,
+		}),
 	}
 }
@@ -39,6 +39,9 @@ import (
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
 )
 
 const (
@@ -90,14 +93,17 @@ type Endpoints struct {
 
 type APIServers struct {
 	AgentServer          agentv1.AgentServer
+	AgentBatchServer     agentbatchv1.AgentBatchServer
 	BundleServer         bundlev1.BundleServer
 	DebugServer          debugv1_pb.DebugServer
 	EntryServer          entryv1.EntryServer
+	EntryDryRunServer    entrydryrunv1.EntryDryRunServer
 	HealthServer         grpc_health_v1.HealthServer
 	LoggerServer         loggerv1.LoggerServer
 	SVIDServer           svidv1.SVIDServer
 	TrustDomainServer    trustdomainv1.TrustDomainServer
 	LocalAUthorityServer localauthorityv1.LocalAuthorityServer
+	SVIDRevocationServer svidrevocationv1.SVIDRevocationServer
 }
 
 // RateLimitConfig holds rate limiting configurations.
@@ -196,16 +202,22 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	// TCP and UDS
 	agentv1.RegisterAgentServer(tcpServer, e.APIServers.AgentServer)
 	agentv1.RegisterAgentServer(udsServer, e.APIServers.AgentServer)
+	agentbatchv1.RegisterAgentBatchServer(tcpServer, e.APIServers.AgentBatchServer)
+	agentbatchv1.RegisterAgentBatchServer(udsServer, e.APIServers.AgentBatchServer)
 	bundlev1.RegisterBundleServer(tcpServer, e.APIServers.BundleServer)
 	bundlev1.RegisterBundleServer(udsServer, e.APIServers.BundleServer)
 	entryv1.RegisterEntryServer(tcpServer, e.APIServers.EntryServer)
 	entryv1.RegisterEntryServer(udsServer, e.APIServers.EntryServer)
+	entrydryrunv1.RegisterEntryDryRunServer(tcpServer, e.APIServers.EntryDryRunServer)
+	entrydryrunv1.RegisterEntryDryRunServer(udsServer, e.APIServers.EntryDryRunServer)
 	svidv1.RegisterSVIDServer(tcpServer, e.APIServers.SVIDServer)
 	svidv1.RegisterSVIDServer(udsServer, e.APIServers.SVIDServer)
 	trustdomainv1.RegisterTrustDomainServer(tcpServer, e.APIServers.TrustDomainServer)
 	trustdomainv1.RegisterTrustDomainServer(udsServer, e.APIServers.TrustDomainServer)
 	localauthorityv1.RegisterLocalAuthorityServer(tcpServer, e.APIServers.LocalAUthorityServer)
 	localauthorityv1.RegisterLocalAuthorityServer(udsServer, e.APIServers.LocalAUthorityServer)
+	svidrevocationv1.RegisterSVIDRevocationServer(tcpServer, e.APIServers.SVIDRevocationServer)
+	svidrevocationv1.RegisterSVIDRevocationServer(udsServer, e.APIServers.SVIDRevocationServer)
 
 	// UDS only
 	loggerv1.RegisterLoggerServer(udsServer, e.APIServers.LoggerServer)
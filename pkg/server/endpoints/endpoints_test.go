@@ -32,6 +32,9 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
+	agentbatchv1 "github.com/spiffe/spire/proto/spire/api/server/agentbatch/v1"
+	entrydryrunv1 "github.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1"
+	svidrevocationv1 "github.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -113,14 +116,17 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, svidObserver, endpoints.SVIDObserver)
 	assert.Equal(t, testTD, endpoints.TrustDomain)
 	assert.NotNil(t, endpoints.APIServers.AgentServer)
+	assert.NotNil(t, endpoints.APIServers.AgentBatchServer)
 	assert.NotNil(t, endpoints.APIServers.BundleServer)
 	assert.NotNil(t, endpoints.APIServers.DebugServer)
 	assert.NotNil(t, endpoints.APIServers.EntryServer)
+	assert.NotNil(t, endpoints.APIServers.EntryDryRunServer)
 	assert.NotNil(t, endpoints.APIServers.HealthServer)
 	assert.NotNil(t, endpoints.APIServers.LoggerServer)
 	assert.NotNil(t, endpoints.APIServers.SVIDServer)
 	assert.NotNil(t, endpoints.BundleEndpointServer)
 	assert.NotNil(t, endpoints.APIServers.LocalAUthorityServer)
+	assert.NotNil(t, endpoints.APIServers.SVIDRevocationServer)
 	assert.NotNil(t, endpoints.EntryFetcherPruneEventsTask)
 	assert.True(t, endpoints.TLSPolicy.RequirePQKEM)
 	assert.Equal(t, cat.GetDataStore(), endpoints.DataStore)
@@ -213,14 +219,17 @@ func TestListenAndServe(t *testing.T) {
 		BundleCache:  bundle.NewCache(ds, clk),
 		APIServers: APIServers{
 			AgentServer:          agentServer{},
+			AgentBatchServer:     agentBatchServer{},
 			BundleServer:         bundleServer{},
 			DebugServer:          debugServer{},
 			EntryServer:          entryServer{},
+			EntryDryRunServer:    entryDryRunServer{},
 			HealthServer:         healthServer{},
 			LoggerServer:         loggerServer{},
 			SVIDServer:           svidServer{},
 			TrustDomainServer:    trustDomainServer{},
 			LocalAUthorityServer: localAuthorityServer{},
+			SVIDRevocationServer: svidRevocationServer{},
 		},
 		BundleEndpointServer:         bundleEndpointServer,
 		Log:                          log,
@@ -349,6 +358,18 @@ func TestListenAndServe(t *testing.T) {
 		testLocalAuthorityAPI(ctx, t, conns)
 	})
 
+	t.Run("SVIDRevocation", func(t *testing.T) {
+		testSVIDRevocationAPI(ctx, t, conns)
+	})
+
+	t.Run("AgentBatch", func(t *testing.T) {
+		testAgentBatchAPI(ctx, t, conns)
+	})
+
+	t.Run("EntryDryRun", func(t *testing.T) {
+		testEntryDryRunAPI(ctx, t, conns)
+	})
+
 	t.Run("Access denied to remote caller", func(t *testing.T) {
 		testRemoteCaller(t, target)
 	})
@@ -1019,6 +1040,144 @@ func testLocalAuthorityAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testSVIDRevocationAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.local), map[string]bool{
+			"RevokeX509SVID":       true,
+			"ListRevokedX509SVIDs": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.noAuth), map[string]bool{
+			"RevokeX509SVID":       false,
+			"ListRevokedX509SVIDs": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.agent), map[string]bool{
+			"RevokeX509SVID":       false,
+			"ListRevokedX509SVIDs": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.admin), map[string]bool{
+			"RevokeX509SVID":       true,
+			"ListRevokedX509SVIDs": true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.federatedAdmin), map[string]bool{
+			"RevokeX509SVID":       true,
+			"ListRevokedX509SVIDs": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, svidrevocationv1.NewSVIDRevocationClient(conns.downstream), map[string]bool{
+			"RevokeX509SVID":       false,
+			"ListRevokedX509SVIDs": false,
+		})
+	})
+}
+
+func testAgentBatchAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.local), map[string]bool{
+			"BatchBanAgents":   true,
+			"BatchEvictAgents": true,
+			"BatchPurgeAgents": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.noAuth), map[string]bool{
+			"BatchBanAgents":   false,
+			"BatchEvictAgents": false,
+			"BatchPurgeAgents": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.agent), map[string]bool{
+			"BatchBanAgents":   false,
+			"BatchEvictAgents": false,
+			"BatchPurgeAgents": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.admin), map[string]bool{
+			"BatchBanAgents":   true,
+			"BatchEvictAgents": true,
+			"BatchPurgeAgents": true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.federatedAdmin), map[string]bool{
+			"BatchBanAgents":   true,
+			"BatchEvictAgents": true,
+			"BatchPurgeAgents": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, agentbatchv1.NewAgentBatchClient(conns.downstream), map[string]bool{
+			"BatchBanAgents":   false,
+			"BatchEvictAgents": false,
+			"BatchPurgeAgents": false,
+		})
+	})
+}
+
+func testEntryDryRunAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.local), map[string]bool{
+			"DryRunBatchCreateEntry": true,
+			"DryRunBatchUpdateEntry": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.noAuth), map[string]bool{
+			"DryRunBatchCreateEntry": false,
+			"DryRunBatchUpdateEntry": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.agent), map[string]bool{
+			"DryRunBatchCreateEntry": false,
+			"DryRunBatchUpdateEntry": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.admin), map[string]bool{
+			"DryRunBatchCreateEntry": true,
+			"DryRunBatchUpdateEntry": true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.federatedAdmin), map[string]bool{
+			"DryRunBatchCreateEntry": true,
+			"DryRunBatchUpdateEntry": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, entrydryrunv1.NewEntryDryRunClient(conns.downstream), map[string]bool{
+			"DryRunBatchCreateEntry": false,
+			"DryRunBatchUpdateEntry": false,
+		})
+	})
+}
+
 // testAuthorization issues an RPC for each method on the client interface and
 // asserts whether the RPC was authorized or not. If a method is not
 // represented in the expectedAuthResults, or a method in expectedAuthResults
@@ -1307,6 +1466,18 @@ func (entryServer) SyncAuthorizedEntries(stream entryv1.Entry_SyncAuthorizedEntr
 	return stream.Send(&entryv1.SyncAuthorizedEntriesResponse{})
 }
 
+type entryDryRunServer struct {
+	entrydryrunv1.UnsafeEntryDryRunServer
+}
+
+func (entryDryRunServer) DryRunBatchCreateEntry(context.Context, *entrydryrunv1.DryRunBatchCreateEntryRequest) (*entrydryrunv1.DryRunBatchCreateEntryResponse, error) {
+	return &entrydryrunv1.DryRunBatchCreateEntryResponse{}, nil
+}
+
+func (entryDryRunServer) DryRunBatchUpdateEntry(context.Context, *entrydryrunv1.DryRunBatchUpdateEntryRequest) (*entrydryrunv1.DryRunBatchUpdateEntryResponse, error) {
+	return &entrydryrunv1.DryRunBatchUpdateEntryResponse{}, nil
+}
+
 type healthServer struct {
 	grpc_health_v1.UnsafeHealthServer
 }
@@ -1438,3 +1609,31 @@ func (localAuthorityServer) RevokeX509Authority(context.Context, *localauthority
 func (localAuthorityServer) RevokeX509UpstreamAuthority(context.Context, *localauthorityv1.RevokeX509UpstreamAuthorityRequest) (*localauthorityv1.RevokeX509UpstreamAuthorityResponse, error) {
 	return &localauthorityv1.RevokeX509UpstreamAuthorityResponse{}, nil
 }
+
+type svidRevocationServer struct {
+	svidrevocationv1.UnsafeSVIDRevocationServer
+}
+
+func (svidRevocationServer) RevokeX509SVID(context.Context, *svidrevocationv1.RevokeX509SVIDRequest) (*svidrevocationv1.RevokeX509SVIDResponse, error) {
+	return &svidrevocationv1.RevokeX509SVIDResponse{}, nil
+}
+
+func (svidRevocationServer) ListRevokedX509SVIDs(context.Context, *svidrevocationv1.ListRevokedX509SVIDsRequest) (*svidrevocationv1.ListRevokedX509SVIDsResponse, error) {
+	return &svidrevocationv1.ListRevokedX509SVIDsResponse{}, nil
+}
+
+type agentBatchServer struct {
+	agentbatchv1.UnsafeAgentBatchServer
+}
+
+func (agentBatchServer) BatchBanAgents(context.Context, *agentbatchv1.BatchBanAgentsRequest) (*agentbatchv1.BatchBanAgentsResponse, error) {
+	return &agentbatchv1.BatchBanAgentsResponse{}, nil
+}
+
+func (agentBatchServer) BatchEvictAgents(context.Context, *agentbatchv1.BatchEvictAgentsRequest) (*agentbatchv1.BatchEvictAgentsResponse, error) {
+	return &agentbatchv1.BatchEvictAgentsResponse{}, nil
+}
+
+func (agentBatchServer) BatchPurgeAgents(context.Context, *agentbatchv1.BatchPurgeAgentsRequest) (*agentbatchv1.BatchPurgeAgentsResponse, error) {
+	return &agentbatchv1.BatchPurgeAgentsResponse{}, nil
+}
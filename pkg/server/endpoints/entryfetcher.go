@@ -11,6 +11,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/authorizedentries"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/pkg/server/datastore"
 )
@@ -61,6 +62,25 @@ func (a *AuthorizedEntryFetcherWithFullCache) FetchAuthorizedEntries(_ context.C
 	return a.cache.GetAuthorizedEntries(agentID), nil
 }
 
+// SnapshotAuthorizedEntries builds an authorized entries cache from the
+// datastore. The full cache is not an authorized entries cache, so unlike
+// with the events-based cache, the snapshot is built on demand.
+func (a *AuthorizedEntryFetcherWithFullCache) SnapshotAuthorizedEntries(ctx context.Context) (*authorizedentries.Cache, error) {
+	cache := authorizedentries.NewCache(a.clk)
+
+	registrationEntries := &registrationEntries{cache: cache, ds: a.ds}
+	if err := registrationEntries.loadCache(ctx, buildCachePageSize); err != nil {
+		return nil, err
+	}
+
+	attestedNodes := &attestedNodes{cache: cache, clk: a.clk, ds: a.ds}
+	if err := attestedNodes.loadCache(ctx); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
 // RunRebuildCacheTask starts a ticker which rebuilds the in-memory entry cache.
 func (a *AuthorizedEntryFetcherWithFullCache) RunRebuildCacheTask(ctx context.Context) error {
 	rebuild := func() {
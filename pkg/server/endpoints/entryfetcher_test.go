@@ -105,6 +105,49 @@ func TestFetchRegistrationEntries(t *testing.T) {
 	assert.Equal(t, expected, entries)
 }
 
+func TestSnapshotAuthorizedEntriesWithFullCache(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+
+	agentID := spiffeid.RequireFromPath(trustDomain, "/spire/agent/1")
+	expiredAgentID := spiffeid.RequireFromPath(trustDomain, "/spire/agent/2")
+	for id, notAfter := range map[spiffeid.ID]time.Time{
+		agentID:        clk.Now().Add(time.Hour),
+		expiredAgentID: clk.Now().Add(-time.Hour),
+	} {
+		_, err := ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:     id.String(),
+			CertNotAfter: notAfter.Unix(),
+		})
+		require.NoError(t, err)
+	}
+
+	var entryIDs []string
+	for _, parentID := range []spiffeid.ID{agentID, expiredAgentID} {
+		entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:  parentID.String(),
+			SpiffeId:  spiffeid.RequireFromPath(trustDomain, "/workload").String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		})
+		require.NoError(t, err)
+		entryIDs = append(entryIDs, entry.EntryId)
+	}
+
+	buildCache := func(context.Context) (entrycache.Cache, error) {
+		return newStaticEntryCache(nil), nil
+	}
+	ef, err := NewAuthorizedEntryFetcherWithFullCache(ctx, buildCache, log, clk, ds, defaultCacheReloadInterval, defaultPruneEventsOlderThan)
+	require.NoError(t, err)
+
+	cache, err := ef.SnapshotAuthorizedEntries(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		entryIDs[0]: {agentID.String()},
+	}, cache.AuthorizedAgents())
+}
+
 func TestRunRebuildCacheTask(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	watchErr := make(chan error, 1)
@@ -25,6 +25,7 @@ import (
 
 func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, rlConf RateLimitConfig, policyEngine *authpolicy.Engine, auditLogEnabled bool, adminIDs []spiffeid.ID) middleware.Middleware {
 	chain := []middleware.Middleware{
+		middleware.WithTracing(),
 		middleware.WithLogger(log),
 		middleware.WithMetrics(metrics),
 		middleware.WithAuthorization(policyEngine, EntryFetcher(ds), AgentAuthorizer(ds, clk), adminIDs),
@@ -153,6 +154,8 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":                              noLimit,
 		"/spire.api.server.entry.v1.Entry/GetAuthorizedEntries":                          noLimit,
 		"/spire.api.server.entry.v1.Entry/SyncAuthorizedEntries":                         noLimit,
+		"/spire.api.server.entrydryrun.v1.EntryDryRun/DryRunBatchCreateEntry":            noLimit,
+		"/spire.api.server.entrydryrun.v1.EntryDryRun/DryRunBatchUpdateEntry":            noLimit,
 		"/spire.api.server.logger.v1.Logger/GetLogger":                                   noLimit,
 		"/spire.api.server.logger.v1.Logger/SetLogLevel":                                 noLimit,
 		"/spire.api.server.logger.v1.Logger/ResetLogLevel":                               noLimit,
@@ -164,6 +167,9 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.agent.v1.Agent/AttestAgent":                                   attestLimit,
 		"/spire.api.server.agent.v1.Agent/RenewAgent":                                    csrLimit,
 		"/spire.api.server.agent.v1.Agent/CreateJoinToken":                               noLimit,
+		"/spire.api.server.agentbatch.v1.AgentBatch/BatchBanAgents":                      noLimit,
+		"/spire.api.server.agentbatch.v1.AgentBatch/BatchEvictAgents":                    noLimit,
+		"/spire.api.server.agentbatch.v1.AgentBatch/BatchPurgeAgents":                    noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/ListFederationRelationships":       noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/GetFederationRelationship":         noLimit,
 		"/spire.api.server.trustdomain.v1.TrustDomain/BatchCreateFederationRelationship": noLimit,
@@ -182,6 +188,8 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 		"/spire.api.server.localauthority.v1.LocalAuthority/TaintX509UpstreamAuthority":  noLimit,
 		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509Authority":         noLimit,
 		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509UpstreamAuthority": noLimit,
+		"/spire.api.server.svidrevocation.v1.SVIDRevocation/RevokeX509SVID":              noLimit,
+		"/spire.api.server.svidrevocation.v1.SVIDRevocation/ListRevokedX509SVIDs":        noLimit,
 		"/grpc.health.v1.Health/Check":                                                   noLimit,
 		"/grpc.health.v1.Health/Watch":                                                   noLimit,
 	}
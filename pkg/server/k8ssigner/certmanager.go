@@ -0,0 +1,166 @@
+package k8ssigner
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	cmapi "github.com/spiffe/spire/pkg/common/certmanager/v1"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func (s *Signer) certificateRequestKind() requestKind {
+	return requestKind{
+		name: "CertificateRequest",
+		newList: func() client.ObjectList {
+			return &cmapi.CertificateRequestList{}
+		},
+		handle: s.handleCertificateRequest,
+	}
+}
+
+// handleCertificateRequest signs the cert-manager CertificateRequest if it is
+// approved, references the configured issuer and is still pending.
+func (s *Signer) handleCertificateRequest(ctx context.Context, obj client.Object) error {
+	cr, ok := obj.(*cmapi.CertificateRequest)
+	if !ok || !s.matchesIssuer(cr.Spec.IssuerRef) || !isCertificateRequestPending(cr) {
+		return nil
+	}
+
+	// The request may have been handled since it was listed or watched,
+	// e.g. by another server, so the latest version is checked again.
+	latest := &cmapi.CertificateRequest{}
+	if err := s.c.Client.Get(ctx, client.ObjectKeyFromObject(cr), latest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get CertificateRequest: %w", err)
+	}
+	if !isCertificateRequestPending(latest) {
+		return nil
+	}
+	return s.signCertificateRequest(ctx, latest)
+}
+
+func (s *Signer) matchesIssuer(ref cmapi.ObjectReference) bool {
+	issuer := s.c.CertManagerIssuer
+	return ref.Group == issuer.Group &&
+		(issuer.Kind == "" || ref.Kind == issuer.Kind) &&
+		(issuer.Name == "" || ref.Name == issuer.Name)
+}
+
+func (s *Signer) signCertificateRequest(ctx context.Context, cr *cmapi.CertificateRequest) error {
+	bundle, err := s.c.DataStore.FetchBundle(ctx, s.c.TrustDomain.IDString())
+	if err != nil {
+		return fmt.Errorf("failed to fetch bundle: %w", err)
+	}
+	if bundle == nil {
+		return fmt.Errorf("bundle for trust domain %q not found", s.c.TrustDomain)
+	}
+	var rootCAs []*x509.Certificate
+	for _, rootCA := range bundle.RootCas {
+		cert, err := x509.ParseCertificate(rootCA.DerBytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse bundle root CA: %w", err)
+		}
+		rootCAs = append(rootCAs, cert)
+	}
+
+	var requestedTTL time.Duration
+	if cr.Spec.Duration != nil {
+		requestedTTL = cr.Spec.Duration.Duration
+	}
+
+	var chain []*x509.Certificate
+	if cr.Spec.IsCA {
+		err = requestErrorf("CA certificates cannot be requested")
+	} else {
+		chain, err = s.sign(ctx, cr.Spec.Request, requester{
+			username:  cr.Spec.Username,
+			groups:    cr.Spec.Groups,
+			namespace: cr.Namespace,
+		}, requestedTTL)
+	}
+
+	now := metav1.NewTime(s.c.Clock.Now())
+	switch {
+	case isRequestError(err):
+		s.c.Log.WithError(err).WithFields(logrus.Fields{
+			"namespace": cr.Namespace,
+			"name":      cr.Name,
+		}).Warn("Rejecting CertificateRequest")
+		setCertificateRequestReadyCondition(cr, cmapi.ConditionFalse, cmapi.CertificateRequestReasonFailed, err.Error(), now)
+		cr.Status.FailureTime = &now
+	case err != nil:
+		return err
+	default:
+		cr.Status.Certificate = pemutil.EncodeCertificates(chain)
+		cr.Status.CA = pemutil.EncodeCertificates(rootCAs)
+		setCertificateRequestReadyCondition(cr, cmapi.ConditionTrue, cmapi.CertificateRequestReasonIssued, "Certificate issued", now)
+	}
+
+	if err := s.c.Client.Status().Update(ctx, cr); err != nil {
+		if apierrors.IsConflict(err) {
+			// Updated since it was fetched. The request is handled again
+			// when the update is watched, if it is still pending.
+			s.c.Log.WithFields(logrus.Fields{
+				"namespace": cr.Namespace,
+				"name":      cr.Name,
+			}).Debug("CertificateRequest changed while being signed")
+			return nil
+		}
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
+
+// setCertificateRequestReadyCondition sets the Ready condition of the
+// CertificateRequest, replacing the existing one if any.
+func setCertificateRequestReadyCondition(cr *cmapi.CertificateRequest, status cmapi.ConditionStatus, reason, message string, now metav1.Time) {
+	condition := cmapi.CertificateRequestCondition{
+		Type:               cmapi.CertificateRequestConditionReady,
+		Status:             status,
+		LastTransitionTime: &now,
+		Reason:             reason,
+		Message:            message,
+	}
+	for i, c := range cr.Status.Conditions {
+		if c.Type != cmapi.CertificateRequestConditionReady {
+			continue
+		}
+		if c.Status == status && c.LastTransitionTime != nil {
+			condition.LastTransitionTime = c.LastTransitionTime
+		}
+		cr.Status.Conditions[i] = condition
+		return
+	}
+	cr.Status.Conditions = append(cr.Status.Conditions, condition)
+}
+
+// isCertificateRequestPending returns true if the CertificateRequest is
+// approved and not ready yet.
+func isCertificateRequestPending(cr *cmapi.CertificateRequest) bool {
+	if len(cr.Status.Certificate) > 0 || cr.Status.FailureTime != nil {
+		return false
+	}
+
+	approved := false
+	for _, c := range cr.Status.Conditions {
+		switch {
+		case c.Type == cmapi.CertificateRequestConditionApproved && c.Status == cmapi.ConditionTrue:
+			approved = true
+		case c.Type == cmapi.CertificateRequestConditionDenied && c.Status == cmapi.ConditionTrue:
+			return false
+		case c.Type == cmapi.CertificateRequestConditionReady && c.Status == cmapi.ConditionTrue:
+			return false
+		case c.Type == cmapi.CertificateRequestConditionReady && c.Reason == cmapi.CertificateRequestReasonFailed:
+			return false
+		}
+	}
+	return approved
+}
@@ -0,0 +1,122 @@
+package k8ssigner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// csrFailedReason is the reason of the Failed condition set on the
+	// CertificateSigningRequests that cannot be signed.
+	csrFailedReason = "SignerValidationFailure"
+
+	// csrSignerNameField is the field selector used to only list and watch
+	// the CertificateSigningRequests addressed to the signer.
+	csrSignerNameField = "spec.signerName"
+)
+
+func (s *Signer) certificateSigningRequestKind() requestKind {
+	return requestKind{
+		name: "CertificateSigningRequest",
+		newList: func() client.ObjectList {
+			return &certificatesv1.CertificateSigningRequestList{}
+		},
+		listOptions: []client.ListOption{
+			client.MatchingFields{csrSignerNameField: s.c.SignerName},
+		},
+		handle: s.handleCertificateSigningRequest,
+	}
+}
+
+// handleCertificateSigningRequest signs the CertificateSigningRequest if it
+// is approved, addressed to the signer and still pending.
+func (s *Signer) handleCertificateSigningRequest(ctx context.Context, obj client.Object) error {
+	csr, ok := obj.(*certificatesv1.CertificateSigningRequest)
+	if !ok || csr.Spec.SignerName != s.c.SignerName || !isCSRPending(csr) {
+		return nil
+	}
+
+	// The request may have been handled since it was listed or watched,
+	// e.g. by another server, so the latest version is checked again.
+	latest := &certificatesv1.CertificateSigningRequest{}
+	if err := s.c.Client.Get(ctx, client.ObjectKeyFromObject(csr), latest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get CertificateSigningRequest: %w", err)
+	}
+	if !isCSRPending(latest) {
+		return nil
+	}
+	return s.signCertificateSigningRequest(ctx, latest)
+}
+
+func (s *Signer) signCertificateSigningRequest(ctx context.Context, csr *certificatesv1.CertificateSigningRequest) error {
+	var requestedTTL time.Duration
+	if csr.Spec.ExpirationSeconds != nil {
+		requestedTTL = time.Duration(*csr.Spec.ExpirationSeconds) * time.Second
+	}
+
+	chain, err := s.sign(ctx, csr.Spec.Request, requester{
+		username: csr.Spec.Username,
+		groups:   csr.Spec.Groups,
+	}, requestedTTL)
+	switch {
+	case isRequestError(err):
+		s.c.Log.WithError(err).WithField("name", csr.Name).Warn("Rejecting CertificateSigningRequest")
+		now := metav1.NewTime(s.c.Clock.Now())
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+			Type:               certificatesv1.CertificateFailed,
+			Status:             corev1.ConditionTrue,
+			Reason:             csrFailedReason,
+			Message:            err.Error(),
+			LastUpdateTime:     now,
+			LastTransitionTime: now,
+		})
+	case err != nil:
+		return err
+	default:
+		csr.Status.Certificate = pemutil.EncodeCertificates(chain)
+	}
+
+	if err := s.c.Client.Status().Update(ctx, csr); err != nil {
+		if apierrors.IsConflict(err) {
+			// Updated since it was fetched. The request is handled again
+			// when the update is watched, if it is still pending.
+			s.c.Log.WithField("name", csr.Name).Debug("CertificateSigningRequest changed while being signed")
+			return nil
+		}
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	return nil
+}
+
+// isCSRPending returns true if the CertificateSigningRequest is approved and
+// has been neither signed nor failed.
+func isCSRPending(csr *certificatesv1.CertificateSigningRequest) bool {
+	if len(csr.Status.Certificate) > 0 {
+		return false
+	}
+
+	approved := false
+	for _, c := range csr.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case certificatesv1.CertificateApproved:
+			approved = true
+		case certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			return false
+		}
+	}
+	return approved
+}
@@ -0,0 +1,423 @@
+// Package k8ssigner signs Kubernetes CertificateSigningRequests and
+// cert-manager CertificateRequests with X509-SVIDs issued by the server CA.
+// Requests are authorized against the registration entries of the SPIFFE ID
+// they ask for.
+package k8ssigner
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	cmapi "github.com/spiffe/spire/pkg/common/certmanager/v1"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/pkg/common/selector"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SelectorType is the type of the selectors used in registration entries
+	// to authorize requests signed by this signer.
+	SelectorType = "k8s_signer"
+
+	// DefaultResyncInterval is the default interval to list all the requests
+	// again, in addition to watching them.
+	DefaultResyncInterval = 10 * time.Minute
+
+	// retryInterval is the interval to retry the requests that failed to be
+	// signed, and to watch again after the watch fails.
+	retryInterval = 5 * time.Second
+
+	serviceAccountPrefix = "system:serviceaccount:"
+)
+
+// X509SVIDSigner signs workload X509-SVIDs.
+type X509SVIDSigner interface {
+	SignWorkloadX509SVID(ctx context.Context, params ca.WorkloadX509SVIDParams) ([]*x509.Certificate, error)
+}
+
+// IssuerRef identifies the cert-manager issuer handled by the signer. Empty
+// name or kind match any issuer in the group.
+type IssuerRef struct {
+	Name  string
+	Kind  string
+	Group string
+}
+
+// Config is the configuration for the Kubernetes signer.
+type Config struct {
+	TrustDomain spiffeid.TrustDomain
+	Client      client.WithWatch
+	CA          X509SVIDSigner
+	DataStore   datastore.DataStore
+	Log         logrus.FieldLogger
+	Clock       clock.Clock
+
+	// ResyncInterval is the interval to list all the requests again, in
+	// addition to watching them.
+	ResyncInterval time.Duration
+
+	// X509SVIDTTL is the default TTL of the issued X509-SVIDs, used when the
+	// registration entry does not set one.
+	X509SVIDTTL time.Duration
+
+	// SignerName is the signer name of the CertificateSigningRequests that
+	// are signed. CertificateSigningRequests are not handled if it is empty.
+	SignerName string
+
+	// CertManagerIssuer is the issuer of the cert-manager CertificateRequests
+	// that are signed. CertificateRequests are not handled if it is nil.
+	CertManagerIssuer *IssuerRef
+}
+
+// Signer watches for Kubernetes certificate requests and signs them.
+type Signer struct {
+	c Config
+}
+
+// New creates a new Kubernetes signer.
+func New(c Config) *Signer {
+	if c.Clock == nil {
+		c.Clock = clock.New()
+	}
+	if c.ResyncInterval == 0 {
+		c.ResyncInterval = DefaultResyncInterval
+	}
+	return &Signer{c: c}
+}
+
+// NewClient creates a Kubernetes client able to handle
+// CertificateSigningRequests and cert-manager CertificateRequests. The
+// in-cluster configuration is used if the kubeconfig path is empty.
+func NewClient(kubeConfigPath string) (client.WithWatch, error) {
+	var config *rest.Config
+	var err error
+	if kubeConfigPath != "" {
+		config, err = clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	} else {
+		config, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewWithWatch(config, client.Options{Scheme: scheme})
+}
+
+// NewScheme returns a scheme with the types handled by the signer.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := certificatesv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := cmapi.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// Run watches for certificate requests and signs them until the context is
+// canceled.
+func (s *Signer) Run(ctx context.Context) error {
+	var tasks []func(context.Context) error
+	for _, kind := range s.requestKinds() {
+		tasks = append(tasks, func(ctx context.Context) error {
+			s.watchRequests(ctx, kind)
+			return nil
+		})
+	}
+
+	err := util.RunTasks(ctx, tasks...)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// requestKind describes a kind of certificate request handled by the signer.
+type requestKind struct {
+	name        string
+	newList     func() client.ObjectList
+	listOptions []client.ListOption
+
+	// handle signs the request if it is still pending. Requests failing
+	// with an error are retried.
+	handle func(ctx context.Context, obj client.Object) error
+}
+
+func (s *Signer) requestKinds() []requestKind {
+	var kinds []requestKind
+	if s.c.SignerName != "" {
+		kinds = append(kinds, s.certificateSigningRequestKind())
+	}
+	if s.c.CertManagerIssuer != nil {
+		kinds = append(kinds, s.certificateRequestKind())
+	}
+	return kinds
+}
+
+// watchRequests handles the requests of the given kind until the context is
+// canceled. The requests are listed, and then watched from the listed
+// version until the resync interval elapses or the watch ends.
+func (s *Signer) watchRequests(ctx context.Context, kind requestKind) {
+	for {
+		if err := s.syncRequests(ctx, kind); err != nil {
+			s.c.Log.WithError(err).WithField("kind", kind.name).Error("Failed to watch requests")
+			select {
+			case <-s.c.Clock.After(retryInterval):
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (s *Signer) syncRequests(ctx context.Context, kind requestKind) error {
+	retry := make(map[client.ObjectKey]client.Object)
+	resourceVersion, err := s.handleRequests(ctx, kind, retry)
+	if err != nil {
+		return err
+	}
+
+	w, err := s.c.Client.Watch(ctx, kind.newList(), append(slices.Clone(kind.listOptions), &client.ListOptions{
+		Raw: &metav1.ListOptions{ResourceVersion: resourceVersion},
+	})...)
+	if err != nil {
+		return fmt.Errorf("failed to watch %ss: %w", kind.name, err)
+	}
+	defer w.Stop()
+
+	resyncTimer := s.c.Clock.Timer(s.c.ResyncInterval)
+	defer resyncTimer.Stop()
+	retryTicker := s.c.Clock.Ticker(retryInterval)
+	defer retryTicker.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if obj, ok := event.Object.(client.Object); ok {
+					s.handleRequest(ctx, kind, obj, retry)
+				}
+			case watch.Error:
+				return fmt.Errorf("failed to watch %ss: %w", kind.name, apierrors.FromObject(event.Object))
+			}
+		case <-retryTicker.C:
+			failed := retry
+			retry = make(map[client.ObjectKey]client.Object)
+			for _, obj := range failed {
+				s.handleRequest(ctx, kind, obj, retry)
+			}
+		case <-resyncTimer.C:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handleRequests lists the requests of the given kind and handles them. It
+// returns the resource version of the list.
+func (s *Signer) handleRequests(ctx context.Context, kind requestKind, retry map[client.ObjectKey]client.Object) (string, error) {
+	list := kind.newList()
+	if err := s.c.Client.List(ctx, list, kind.listOptions...); err != nil {
+		return "", fmt.Errorf("failed to list %ss: %w", kind.name, err)
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract %ss: %w", kind.name, err)
+	}
+
+	for _, item := range items {
+		if obj, ok := item.(client.Object); ok {
+			s.handleRequest(ctx, kind, obj, retry)
+		}
+	}
+	return list.GetResourceVersion(), nil
+}
+
+func (s *Signer) handleRequest(ctx context.Context, kind requestKind, obj client.Object, retry map[client.ObjectKey]client.Object) {
+	if err := kind.handle(ctx, obj); err != nil {
+		s.c.Log.WithError(err).WithFields(logrus.Fields{
+			"kind":      kind.name,
+			"namespace": obj.GetNamespace(),
+			"name":      obj.GetName(),
+		}).Error("Failed to sign request")
+		retry[client.ObjectKeyFromObject(obj)] = obj
+	}
+}
+
+// requester holds the information known about the originator of a request.
+type requester struct {
+	username  string
+	groups    []string
+	namespace string
+}
+
+// selectors returns the selectors that describe the requester. Registration
+// entries authorize a requester when all their selectors are included in
+// this set.
+func (r requester) selectors() []*common.Selector {
+	var selectors []*common.Selector
+	add := func(value string) {
+		selectors = append(selectors, &common.Selector{Type: SelectorType, Value: value})
+	}
+
+	if r.username != "" {
+		add("user:" + r.username)
+		if sa, ok := strings.CutPrefix(r.username, serviceAccountPrefix); ok {
+			add("sa:" + sa)
+		}
+	}
+	for _, group := range r.groups {
+		add("group:" + group)
+	}
+	if r.namespace != "" {
+		add("ns:" + r.namespace)
+	}
+	return selectors
+}
+
+// requestError is returned when a request can never be signed, e.g. because
+// it is malformed or not authorized. Requests failing with any other error
+// are retried.
+type requestError struct {
+	err error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+func requestErrorf(format string, args ...any) error {
+	return &requestError{err: fmt.Errorf(format, args...)}
+}
+
+func isRequestError(err error) bool {
+	var re *requestError
+	return errors.As(err, &re)
+}
+
+// sign authorizes the PEM encoded CSR for the requester and signs it. The
+// requested TTL, if set, can only shorten the TTL of the X509-SVID.
+func (s *Signer) sign(ctx context.Context, csrPEM []byte, r requester, requestedTTL time.Duration) ([]*x509.Certificate, error) {
+	csr, err := pemutil.ParseCertificateRequest(csrPEM)
+	if err != nil {
+		return nil, requestErrorf("malformed CSR: %w", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, requestErrorf("CSR signature check failed: %w", err)
+	}
+	if len(csr.URIs) != 1 {
+		return nil, requestErrorf("CSR must have exactly one URI SAN")
+	}
+	id, err := spiffeid.FromURI(csr.URIs[0])
+	if err != nil {
+		return nil, requestErrorf("CSR URI SAN %q is not a valid SPIFFE ID: %w", csr.URIs[0], err)
+	}
+	if !id.MemberOf(s.c.TrustDomain) {
+		return nil, requestErrorf("SPIFFE ID %q is not a member of trust domain %q", id, s.c.TrustDomain)
+	}
+
+	entry, err := s.findAuthorizingEntry(ctx, id, r, csr.DNSNames)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.c.X509SVIDTTL
+	if entry.X509SvidTtl > 0 {
+		ttl = time.Duration(entry.X509SvidTtl) * time.Second
+	}
+	if requestedTTL > 0 && (ttl == 0 || requestedTTL < ttl) {
+		ttl = requestedTTL
+	}
+
+	chain, err := s.c.CA.SignWorkloadX509SVID(ctx, ca.WorkloadX509SVIDParams{
+		PublicKey: csr.PublicKey,
+		SPIFFEID:  id,
+		DNSNames:  entry.DnsNames,
+		TTL:       ttl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign X509-SVID: %w", err)
+	}
+
+	s.c.Log.WithFields(logrus.Fields{
+		telemetry.SPIFFEID:       id.String(),
+		telemetry.RegistrationID: entry.EntryId,
+		telemetry.Expiration:     chain[0].NotAfter.Format(time.RFC3339),
+		telemetry.SerialNumber:   chain[0].SerialNumber.String(),
+	}).Info("Signed X509-SVID for Kubernetes request")
+	return chain, nil
+}
+
+// findAuthorizingEntry returns the first registration entry for the SPIFFE ID
+// whose selectors are all satisfied by the requester and that allows the DNS
+// names in the CSR.
+func (s *Signer) findAuthorizingEntry(ctx context.Context, id spiffeid.ID, r requester, dnsNames []string) (*common.RegistrationEntry, error) {
+	resp, err := s.c.DataStore.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySpiffeID: id.String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registration entries: %w", err)
+	}
+
+	requesterSelectors := selector.NewSetFromRaw(r.selectors())
+	now := s.c.Clock.Now().Unix()
+	for _, entry := range resp.Entries {
+		if entry.Downstream || (entry.EntryExpiry != 0 && entry.EntryExpiry <= now) {
+			continue
+		}
+		if !requesterSelectors.IncludesSet(selector.NewSetFromRaw(entry.Selectors)) {
+			continue
+		}
+		if !allIncluded(entry.DnsNames, dnsNames) {
+			continue
+		}
+		return entry, nil
+	}
+	return nil, requestErrorf("no registration entry for %q authorizes the requester", id)
+}
+
+func allIncluded(allowed, values []string) bool {
+	for _, value := range values {
+		if !slices.Contains(allowed, value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,519 @@
+package k8ssigner
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	cmapi "github.com/spiffe/spire/pkg/common/certmanager/v1"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakeserverca"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testSignerName = "spiffe.io/spire"
+	testUsername   = "system:serviceaccount:foo:bar"
+)
+
+var (
+	td       = spiffeid.RequireTrustDomainFromString("example.org")
+	workload = spiffeid.RequireFromPath(td, "/workload")
+	key      = testkey.MustEC256()
+)
+
+func TestCertificateSigningRequests(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		csr           *certificatesv1.CertificateSigningRequest
+		dsErr         error
+		expectFailed  string
+		expectDNS     []string
+		expectTTL     time.Duration
+		expectUpdated bool
+		expectRetry   bool
+	}{
+		{
+			name:          "signed",
+			csr:           newCSR(t, testSignerName, workload, nil, true),
+			expectDNS:     []string{"workload.example.org"},
+			expectTTL:     time.Hour,
+			expectUpdated: true,
+		},
+		{
+			name: "signed with requested expiration",
+			csr: func() *certificatesv1.CertificateSigningRequest {
+				csr := newCSR(t, testSignerName, workload, nil, true)
+				expirationSeconds := int32(600)
+				csr.Spec.ExpirationSeconds = &expirationSeconds
+				return csr
+			}(),
+			expectDNS:     []string{"workload.example.org"},
+			expectTTL:     10 * time.Minute,
+			expectUpdated: true,
+		},
+		{
+			name:          "signed with allowed DNS name",
+			csr:           newCSR(t, testSignerName, workload, []string{"workload.example.org"}, true),
+			expectDNS:     []string{"workload.example.org"},
+			expectTTL:     time.Hour,
+			expectUpdated: true,
+		},
+		{
+			name: "not approved",
+			csr:  newCSR(t, testSignerName, workload, nil, false),
+		},
+		{
+			name: "other signer",
+			csr:  newCSR(t, "example.com/other", workload, nil, true),
+		},
+		{
+			name: "already signed",
+			csr: func() *certificatesv1.CertificateSigningRequest {
+				csr := newCSR(t, testSignerName, workload, nil, true)
+				csr.Status.Certificate = []byte("CERTIFICATE")
+				return csr
+			}(),
+		},
+		{
+			name: "denied",
+			csr: func() *certificatesv1.CertificateSigningRequest {
+				csr := newCSR(t, testSignerName, workload, nil, true)
+				csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{
+					Type:   certificatesv1.CertificateDenied,
+					Status: corev1.ConditionTrue,
+				})
+				return csr
+			}(),
+		},
+		{
+			name:          "unauthorized requester",
+			csr:           withUsername(newCSR(t, testSignerName, workload, nil, true), "system:serviceaccount:foo:other"),
+			expectFailed:  `no registration entry for "spiffe://example.org/workload" authorizes the requester`,
+			expectUpdated: true,
+		},
+		{
+			name:          "unknown SPIFFE ID",
+			csr:           newCSR(t, testSignerName, spiffeid.RequireFromPath(td, "/unknown"), nil, true),
+			expectFailed:  `no registration entry for "spiffe://example.org/unknown" authorizes the requester`,
+			expectUpdated: true,
+		},
+		{
+			name:          "DNS name not allowed",
+			csr:           newCSR(t, testSignerName, workload, []string{"other.example.org"}, true),
+			expectFailed:  `no registration entry for "spiffe://example.org/workload" authorizes the requester`,
+			expectUpdated: true,
+		},
+		{
+			name:          "foreign trust domain",
+			csr:           newCSR(t, testSignerName, spiffeid.RequireFromString("spiffe://other.org/workload"), nil, true),
+			expectFailed:  `SPIFFE ID "spiffe://other.org/workload" is not a member of trust domain "example.org"`,
+			expectUpdated: true,
+		},
+		{
+			name: "malformed CSR",
+			csr: func() *certificatesv1.CertificateSigningRequest {
+				csr := newCSR(t, testSignerName, workload, nil, true)
+				csr.Spec.Request = []byte("not a CSR")
+				return csr
+			}(),
+			expectFailed:  "malformed CSR: no PEM blocks",
+			expectUpdated: true,
+		},
+		{
+			name:        "datastore failure is retried",
+			csr:         newCSR(t, testSignerName, workload, nil, true),
+			dsErr:       errors.New("oh no"),
+			expectRetry: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, Config{SignerName: testSignerName}, tt.csr)
+			test.ds.SetNextError(tt.dsErr)
+
+			retry := test.handleRequests(t)
+			if tt.expectRetry {
+				assert.Contains(t, retry, client.ObjectKeyFromObject(tt.csr))
+			} else {
+				assert.Empty(t, retry)
+			}
+
+			csr := &certificatesv1.CertificateSigningRequest{}
+			require.NoError(t, test.client.Get(context.Background(), client.ObjectKeyFromObject(tt.csr), csr))
+
+			if !tt.expectUpdated {
+				assert.Equal(t, tt.csr.Status, csr.Status)
+				return
+			}
+
+			if tt.expectFailed != "" {
+				require.Empty(t, csr.Status.Certificate)
+				failed := csr.Status.Conditions[len(csr.Status.Conditions)-1]
+				assert.Equal(t, certificatesv1.CertificateFailed, failed.Type)
+				assert.Equal(t, corev1.ConditionTrue, failed.Status)
+				assert.Equal(t, csrFailedReason, failed.Reason)
+				assert.Contains(t, failed.Message, tt.expectFailed)
+				return
+			}
+
+			certs, err := pemutil.ParseCertificates(csr.Status.Certificate)
+			require.NoError(t, err)
+			require.NotEmpty(t, certs)
+			svid := certs[0]
+			require.Len(t, svid.URIs, 1)
+			assert.Equal(t, workload.String(), svid.URIs[0].String())
+			assert.Equal(t, tt.expectDNS, svid.DNSNames)
+			assert.Equal(t, tt.expectTTL, svid.NotAfter.Sub(test.clk.Now()).Round(time.Minute))
+		})
+	}
+}
+
+func TestCertificateRequests(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		issuer        *IssuerRef
+		cr            *cmapi.CertificateRequest
+		expectFailed  string
+		expectTTL     time.Duration
+		expectUpdated bool
+	}{
+		{
+			name:          "issued",
+			issuer:        &IssuerRef{Group: "spire.spiffe.io"},
+			cr:            newCertificateRequest(t, "foo", "spire.spiffe.io", true),
+			expectTTL:     time.Hour,
+			expectUpdated: true,
+		},
+		{
+			name:   "issued with requested duration",
+			issuer: &IssuerRef{Group: "spire.spiffe.io", Kind: "ClusterIssuer", Name: "spire"},
+			cr: func() *cmapi.CertificateRequest {
+				cr := newCertificateRequest(t, "foo", "spire.spiffe.io", true)
+				cr.Spec.Duration = &metav1.Duration{Duration: 5 * time.Minute}
+				return cr
+			}(),
+			expectTTL:     5 * time.Minute,
+			expectUpdated: true,
+		},
+		{
+			name:   "other issuer group",
+			issuer: &IssuerRef{Group: "spire.spiffe.io"},
+			cr:     newCertificateRequest(t, "foo", "cert-manager.io", true),
+		},
+		{
+			name:   "other issuer name",
+			issuer: &IssuerRef{Group: "spire.spiffe.io", Name: "other"},
+			cr:     newCertificateRequest(t, "foo", "spire.spiffe.io", true),
+		},
+		{
+			name:   "not approved",
+			issuer: &IssuerRef{Group: "spire.spiffe.io"},
+			cr:     newCertificateRequest(t, "foo", "spire.spiffe.io", false),
+		},
+		{
+			name:          "unauthorized namespace",
+			issuer:        &IssuerRef{Group: "spire.spiffe.io"},
+			cr:            newCertificateRequest(t, "other", "spire.spiffe.io", true),
+			expectFailed:  `no registration entry for "spiffe://example.org/workload" authorizes the requester`,
+			expectUpdated: true,
+		},
+		{
+			name:   "CA requested",
+			issuer: &IssuerRef{Group: "spire.spiffe.io"},
+			cr: func() *cmapi.CertificateRequest {
+				cr := newCertificateRequest(t, "foo", "spire.spiffe.io", true)
+				cr.Spec.IsCA = true
+				return cr
+			}(),
+			expectFailed:  "CA certificates cannot be requested",
+			expectUpdated: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, Config{CertManagerIssuer: tt.issuer}, tt.cr)
+
+			test.handleRequests(t)
+
+			cr := &cmapi.CertificateRequest{}
+			require.NoError(t, test.client.Get(context.Background(), client.ObjectKeyFromObject(tt.cr), cr))
+
+			if !tt.expectUpdated {
+				assert.Equal(t, tt.cr.Status, cr.Status)
+				return
+			}
+
+			ready := cr.Status.Conditions[len(cr.Status.Conditions)-1]
+			assert.Equal(t, cmapi.CertificateRequestConditionReady, ready.Type)
+
+			if tt.expectFailed != "" {
+				require.Empty(t, cr.Status.Certificate)
+				require.NotNil(t, cr.Status.FailureTime)
+				assert.Equal(t, cmapi.ConditionFalse, ready.Status)
+				assert.Equal(t, cmapi.CertificateRequestReasonFailed, ready.Reason)
+				assert.Contains(t, ready.Message, tt.expectFailed)
+				return
+			}
+
+			assert.Equal(t, cmapi.ConditionTrue, ready.Status)
+			assert.Equal(t, cmapi.CertificateRequestReasonIssued, ready.Reason)
+
+			certs, err := pemutil.ParseCertificates(cr.Status.Certificate)
+			require.NoError(t, err)
+			require.NotEmpty(t, certs)
+			svid := certs[0]
+			require.Len(t, svid.URIs, 1)
+			assert.Equal(t, workload.String(), svid.URIs[0].String())
+			assert.Equal(t, tt.expectTTL, svid.NotAfter.Sub(test.clk.Now()).Round(time.Minute))
+
+			rootCAs, err := pemutil.ParseCertificates(cr.Status.CA)
+			require.NoError(t, err)
+			assert.Equal(t, test.ca.Bundle(), rootCAs)
+		})
+	}
+}
+
+func TestRequestAlreadyHandled(t *testing.T) {
+	t.Run("CertificateSigningRequest", func(t *testing.T) {
+		signed := newCSR(t, testSignerName, workload, nil, true)
+		signed.Status.Certificate = []byte("CERTIFICATE")
+		test := setupTest(t, Config{SignerName: testSignerName}, signed)
+
+		// The watched version is still pending but another server signed
+		// the request in the meantime.
+		stale := newCSR(t, testSignerName, workload, nil, true)
+		require.NoError(t, test.signer.handleCertificateSigningRequest(context.Background(), stale))
+		assert.Empty(t, test.logHook.AllEntries())
+
+		csr := &certificatesv1.CertificateSigningRequest{}
+		require.NoError(t, test.client.Get(context.Background(), client.ObjectKeyFromObject(signed), csr))
+		assert.Equal(t, []byte("CERTIFICATE"), csr.Status.Certificate)
+	})
+
+	t.Run("CertificateRequest", func(t *testing.T) {
+		issued := newCertificateRequest(t, "foo", "spire.spiffe.io", true)
+		issued.Status.Certificate = []byte("CERTIFICATE")
+		test := setupTest(t, Config{CertManagerIssuer: &IssuerRef{Group: "spire.spiffe.io"}}, issued)
+
+		stale := newCertificateRequest(t, "foo", "spire.spiffe.io", true)
+		require.NoError(t, test.signer.handleCertificateRequest(context.Background(), stale))
+		assert.Empty(t, test.logHook.AllEntries())
+
+		cr := &cmapi.CertificateRequest{}
+		require.NoError(t, test.client.Get(context.Background(), client.ObjectKeyFromObject(issued), cr))
+		assert.Equal(t, []byte("CERTIFICATE"), cr.Status.Certificate)
+	})
+}
+
+func TestRun(t *testing.T) {
+	listed := newCSR(t, testSignerName, workload, nil, true)
+	test := setupTest(t, Config{SignerName: testSignerName}, listed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- test.signer.Run(ctx)
+	}()
+
+	isSigned := func(csr *certificatesv1.CertificateSigningRequest) func() bool {
+		return func() bool {
+			updated := &certificatesv1.CertificateSigningRequest{}
+			require.NoError(t, test.client.Get(ctx, client.ObjectKeyFromObject(csr), updated))
+			return len(updated.Status.Certificate) > 0
+		}
+	}
+
+	// Requests that exist when the signer starts are listed
+	test.clk.WaitForTicker(time.Minute, "waiting for the retry ticker")
+	require.Eventually(t, isSigned(listed), time.Minute, 10*time.Millisecond)
+
+	// Requests created afterwards are watched
+	watched := newCSR(t, testSignerName, workload, nil, true)
+	watched.Name = "watched"
+	require.NoError(t, test.client.Create(ctx, watched))
+	require.Eventually(t, isSigned(watched), time.Minute, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestRequesterSelectors(t *testing.T) {
+	r := requester{
+		username:  testUsername,
+		groups:    []string{"system:authenticated"},
+		namespace: "ns",
+	}
+	assert.Equal(t, []*common.Selector{
+		{Type: SelectorType, Value: "user:system:serviceaccount:foo:bar"},
+		{Type: SelectorType, Value: "sa:foo:bar"},
+		{Type: SelectorType, Value: "group:system:authenticated"},
+		{Type: SelectorType, Value: "ns:ns"},
+	}, r.selectors())
+}
+
+type signerTest struct {
+	signer  *Signer
+	client  client.Client
+	ds      *fakedatastore.DataStore
+	ca      *fakeserverca.CA
+	clk     *clock.Mock
+	logHook *test.Hook
+}
+
+// handleRequests lists and handles the requests once, returning the requests
+// to retry.
+func (s *signerTest) handleRequests(t *testing.T) map[client.ObjectKey]client.Object {
+	retry := make(map[client.ObjectKey]client.Object)
+	for _, kind := range s.signer.requestKinds() {
+		_, err := s.signer.handleRequests(context.Background(), kind, retry)
+		require.NoError(t, err)
+	}
+	return retry
+}
+
+func setupTest(t *testing.T, config Config, objects ...client.Object) *signerTest {
+	scheme, err := NewScheme()
+	require.NoError(t, err)
+
+	k8sClient := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&certificatesv1.CertificateSigningRequest{}, &cmapi.CertificateRequest{}).
+		WithIndex(&certificatesv1.CertificateSigningRequest{}, csrSignerNameField, func(obj client.Object) []string {
+			return []string{obj.(*certificatesv1.CertificateSigningRequest).Spec.SignerName}
+		}).
+		WithObjects(objects...).
+		Build()
+
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+	serverCA := fakeserverca.New(t, td, &fakeserverca.Options{
+		Clock:       clk,
+		X509SVIDTTL: 2 * time.Hour,
+	})
+
+	var rootCAs []*common.Certificate
+	for _, cert := range serverCA.Bundle() {
+		rootCAs = append(rootCAs, &common.Certificate{DerBytes: cert.Raw})
+	}
+	_, err = ds.CreateBundle(context.Background(), &common.Bundle{
+		TrustDomainId: td.IDString(),
+		RootCas:       rootCAs,
+	})
+	require.NoError(t, err)
+
+	for _, entry := range []*common.RegistrationEntry{
+		{
+			SpiffeId:  workload.String(),
+			ParentId:  spiffeid.RequireFromPath(td, "/k8s").String(),
+			Selectors: []*common.Selector{{Type: SelectorType, Value: "sa:foo:bar"}},
+			DnsNames:  []string{"workload.example.org"},
+		},
+		{
+			SpiffeId:  workload.String(),
+			ParentId:  spiffeid.RequireFromPath(td, "/k8s").String(),
+			Selectors: []*common.Selector{{Type: SelectorType, Value: "ns:foo"}},
+		},
+		{
+			// Entries with selectors of other types never authorize requests
+			SpiffeId:  workload.String(),
+			ParentId:  spiffeid.RequireFromPath(td, "/agent").String(),
+			Selectors: []*common.Selector{{Type: "k8s", Value: "sa:other"}},
+		},
+	} {
+		_, err := ds.CreateRegistrationEntry(context.Background(), entry)
+		require.NoError(t, err)
+	}
+
+	log, logHook := test.NewNullLogger()
+	config.TrustDomain = td
+	config.Client = k8sClient
+	config.CA = serverCA
+	config.DataStore = ds
+	config.Log = log
+	config.Clock = clk
+	config.X509SVIDTTL = time.Hour
+
+	return &signerTest{
+		signer:  New(config),
+		client:  k8sClient,
+		ds:      ds,
+		ca:      serverCA,
+		clk:     clk,
+		logHook: logHook,
+	}
+}
+
+func newCSR(t *testing.T, signerName string, id spiffeid.ID, dnsNames []string, approved bool) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "csr",
+		},
+		Spec: certificatesv1.CertificateSigningRequestSpec{
+			Request:    createCSR(t, id, dnsNames),
+			SignerName: signerName,
+			Username:   testUsername,
+			Groups:     []string{"system:serviceaccounts", "system:authenticated"},
+			Usages:     []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth},
+		},
+	}
+	if approved {
+		csr.Status.Conditions = []certificatesv1.CertificateSigningRequestCondition{
+			{Type: certificatesv1.CertificateApproved, Status: corev1.ConditionTrue},
+		}
+	}
+	return csr
+}
+
+func withUsername(csr *certificatesv1.CertificateSigningRequest, username string) *certificatesv1.CertificateSigningRequest {
+	csr.Spec.Username = username
+	return csr
+}
+
+func newCertificateRequest(t *testing.T, namespace, issuerGroup string, approved bool) *cmapi.CertificateRequest {
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      "cr",
+		},
+		Spec: cmapi.CertificateRequestSpec{
+			IssuerRef: cmapi.ObjectReference{
+				Name:  "spire",
+				Kind:  "ClusterIssuer",
+				Group: issuerGroup,
+			},
+			Request:  createCSR(t, workload, nil),
+			Username: "system:serviceaccount:cert-manager:cert-manager",
+		},
+	}
+	if approved {
+		cr.Status.Conditions = []cmapi.CertificateRequestCondition{
+			{Type: cmapi.CertificateRequestConditionApproved, Status: cmapi.ConditionTrue},
+		}
+	}
+	return cr
+}
+
+func createCSR(t *testing.T, id spiffeid.ID, dnsNames []string) []byte {
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		URIs:     []*url.URL{id.URL()},
+		DNSNames: dnsNames,
+	}, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER})
+}
@@ -16,8 +16,8 @@ type CredentialComposer interface {
 	ComposeServerX509CA(ctx context.Context, attributes X509CAAttributes) (X509CAAttributes, error)
 	ComposeServerX509SVID(ctx context.Context, attributes X509SVIDAttributes) (X509SVIDAttributes, error)
 	ComposeAgentX509SVID(ctx context.Context, id spiffeid.ID, publicKey crypto.PublicKey, attributes X509SVIDAttributes) (X509SVIDAttributes, error)
-	ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, publicKey crypto.PublicKey, attributes X509SVIDAttributes) (X509SVIDAttributes, error)
-	ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, attributes JWTSVIDAttributes) (JWTSVIDAttributes, error)
+	ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, publicKey crypto.PublicKey, workload WorkloadContext, attributes X509SVIDAttributes) (X509SVIDAttributes, error)
+	ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, workload WorkloadContext, attributes JWTSVIDAttributes) (JWTSVIDAttributes, error)
 }
 
 type X509CAAttributes struct {
@@ -0,0 +1,376 @@
+package template
+
+import (
+	"encoding/asn1"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	gotemplate "text/template"
+
+	"github.com/hashicorp/hcl"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/agentpathtemplate"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+)
+
+const (
+	credentialServerX509CA     = "server_x509_ca"
+	credentialServerX509SVID   = "server_x509_svid"
+	credentialAgentX509SVID    = "agent_x509_svid"
+	credentialWorkloadX509SVID = "workload_x509_svid"
+	credentialWorkloadJWTSVID  = "workload_jwt_svid"
+
+	encodingUTF8      = "utf8"
+	encodingIA5       = "ia5"
+	encodingPrintable = "printable"
+	encodingDER       = "der"
+)
+
+var (
+	allCredentials = []string{
+		credentialServerX509CA,
+		credentialServerX509SVID,
+		credentialAgentX509SVID,
+		credentialWorkloadX509SVID,
+		credentialWorkloadJWTSVID,
+	}
+
+	// reservedClaims are set by SPIRE and cannot be set by rules. The
+	// audience can be extended with jwt_audiences.
+	reservedClaims = []string{"sub", "aud", "exp", "iat"}
+
+	// reservedExtensions are set by SPIRE and cannot be set by rules
+	reservedExtensions = []asn1.ObjectIdentifier{
+		{2, 5, 29, 17}, // Subject Alternative Name
+		{2, 5, 29, 19}, // Basic Constraints
+	}
+)
+
+// Configuration is the configuration of the plugin.
+type Configuration struct {
+	Rules []ruleConfig `hcl:"rules"`
+}
+
+type ruleConfig struct {
+	// Credentials are the kinds of credentials the rule applies to. The rule
+	// applies to all of them when empty.
+	Credentials []string `hcl:"credentials"`
+
+	// SPIFFEIDPattern is a glob pattern the SPIFFE ID must match
+	SPIFFEIDPattern string `hcl:"spiffe_id_pattern"`
+
+	// TrustDomains restricts the rule to SPIFFE IDs of the given trust domains
+	TrustDomains []string `hcl:"trust_domains"`
+
+	// PathRegex is a regular expression the SPIFFE ID path must match
+	PathRegex string `hcl:"path_regex"`
+
+	// Condition is a template that must render "true" for the rule to apply
+	Condition string `hcl:"condition"`
+
+	JWTAudiences   []string              `hcl:"jwt_audiences"`
+	JWTClaims      []jwtClaimConfig      `hcl:"jwt_claims"`
+	X509Subject    *x509SubjectConfig    `hcl:"x509_subject"`
+	X509Extensions []x509ExtensionConfig `hcl:"x509_extensions"`
+}
+
+type jwtClaimConfig struct {
+	Name string `hcl:"name"`
+
+	// Exactly one of the following is set. Value renders a string claim,
+	// Values a list of strings claim and JSON any JSON value.
+	Value  string   `hcl:"value"`
+	Values []string `hcl:"values"`
+	JSON   string   `hcl:"json"`
+}
+
+type x509SubjectConfig struct {
+	CommonName         string                `hcl:"common_name"`
+	SerialNumber       string                `hcl:"serial_number"`
+	Country            []string              `hcl:"country"`
+	Organization       []string              `hcl:"organization"`
+	OrganizationalUnit []string              `hcl:"organizational_unit"`
+	Locality           []string              `hcl:"locality"`
+	Province           []string              `hcl:"province"`
+	StreetAddress      []string              `hcl:"street_address"`
+	PostalCode         []string              `hcl:"postal_code"`
+	ExtraNames         []x509ExtraNameConfig `hcl:"extra_names"`
+}
+
+type x509ExtraNameConfig struct {
+	OID   string `hcl:"oid"`
+	Value string `hcl:"value"`
+}
+
+type x509ExtensionConfig struct {
+	OID      string `hcl:"oid"`
+	Critical bool   `hcl:"critical"`
+	Value    string `hcl:"value"`
+
+	// Encoding is how the rendered value is encoded in the extension. The
+	// "utf8", "ia5" and "printable" encodings encode the value as an ASN.1
+	// string of that type. The "der" encoding expects a base64 encoded DER
+	// value.
+	Encoding string `hcl:"encoding"`
+}
+
+// config is the parsed configuration of the plugin
+type config struct {
+	trustDomain spiffeid.TrustDomain
+	rules       []*rule
+}
+
+func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *config {
+	newConfig := new(Configuration)
+	if err := hcl.Decode(newConfig, hclText); err != nil {
+		status.ReportErrorf("unable to decode configuration: %v", err)
+		return nil
+	}
+
+	if len(newConfig.Rules) == 0 {
+		status.ReportInfo("no rules are configured; credentials are not modified")
+	}
+
+	c := &config{
+		trustDomain: coreConfig.TrustDomain,
+	}
+	for i, ruleConfig := range newConfig.Rules {
+		r, err := parseRule(ruleConfig)
+		if err != nil {
+			status.ReportErrorf("rule %d: %v", i, err)
+			continue
+		}
+		c.rules = append(c.rules, r)
+	}
+	return c
+}
+
+func parseRule(c ruleConfig) (*rule, error) {
+	r := &rule{
+		credentials: c.Credentials,
+	}
+	if len(r.credentials) == 0 {
+		r.credentials = allCredentials
+	}
+	for _, credential := range r.credentials {
+		if !slices.Contains(allCredentials, credential) {
+			return nil, fmt.Errorf("unknown credential %q: expected one of %q", credential, allCredentials)
+		}
+	}
+
+	if c.SPIFFEIDPattern != "" {
+		if _, err := path.Match(c.SPIFFEIDPattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid spiffe_id_pattern: %w", err)
+		}
+		r.spiffeIDPattern = c.SPIFFEIDPattern
+	}
+	for _, td := range c.TrustDomains {
+		trustDomain, err := spiffeid.TrustDomainFromString(td)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust domain %q: %w", td, err)
+		}
+		r.trustDomains = append(r.trustDomains, trustDomain)
+	}
+	if c.PathRegex != "" {
+		pathRegex, err := regexp.Compile(c.PathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path_regex: %w", err)
+		}
+		r.pathRegex = pathRegex
+	}
+
+	var err error
+	if r.condition, err = parseTemplate("condition", c.Condition); err != nil {
+		return nil, err
+	}
+	if r.jwtAudiences, err = parseTemplates("jwt_audiences", c.JWTAudiences); err != nil {
+		return nil, err
+	}
+	for _, claim := range c.JWTClaims {
+		jwtClaim, err := parseJWTClaim(claim)
+		if err != nil {
+			return nil, err
+		}
+		r.jwtClaims = append(r.jwtClaims, jwtClaim)
+	}
+	if c.X509Subject != nil {
+		if r.x509Subject, err = parseX509Subject(c.X509Subject); err != nil {
+			return nil, err
+		}
+	}
+	for _, extension := range c.X509Extensions {
+		x509Extension, err := parseX509Extension(extension)
+		if err != nil {
+			return nil, err
+		}
+		r.x509Extensions = append(r.x509Extensions, x509Extension)
+	}
+
+	return r, nil
+}
+
+func parseJWTClaim(c jwtClaimConfig) (*jwtClaim, error) {
+	switch {
+	case c.Name == "":
+		return nil, fmt.Errorf("jwt_claim is missing the name")
+	case slices.Contains(reservedClaims, c.Name):
+		return nil, fmt.Errorf("jwt_claim %q is reserved", c.Name)
+	}
+
+	set := 0
+	for _, isSet := range []bool{c.Value != "", len(c.Values) > 0, c.JSON != ""} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("jwt_claim %q requires exactly one of value, values or json", c.Name)
+	}
+
+	claim := &jwtClaim{
+		name: c.Name,
+	}
+	var err error
+	switch {
+	case c.Value != "":
+		claim.value, err = parseTemplate("jwt_claim "+c.Name, c.Value)
+	case len(c.Values) > 0:
+		claim.values, err = parseTemplates("jwt_claim "+c.Name, c.Values)
+	default:
+		claim.json, err = parseTemplate("jwt_claim "+c.Name, c.JSON)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func parseX509Subject(c *x509SubjectConfig) (*x509Subject, error) {
+	subject := new(x509Subject)
+
+	var err error
+	if subject.commonName, err = parseTemplate("common_name", c.CommonName); err != nil {
+		return nil, err
+	}
+	if subject.serialNumber, err = parseTemplate("serial_number", c.SerialNumber); err != nil {
+		return nil, err
+	}
+	for _, field := range []struct {
+		name  string
+		texts []string
+		out   *[]*gotemplate.Template
+	}{
+		{name: "country", texts: c.Country, out: &subject.country},
+		{name: "organization", texts: c.Organization, out: &subject.organization},
+		{name: "organizational_unit", texts: c.OrganizationalUnit, out: &subject.organizationalUnit},
+		{name: "locality", texts: c.Locality, out: &subject.locality},
+		{name: "province", texts: c.Province, out: &subject.province},
+		{name: "street_address", texts: c.StreetAddress, out: &subject.streetAddress},
+		{name: "postal_code", texts: c.PostalCode, out: &subject.postalCode},
+	} {
+		if *field.out, err = parseTemplates(field.name, field.texts); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, extraName := range c.ExtraNames {
+		if _, err := parseOID(extraName.OID); err != nil {
+			return nil, fmt.Errorf("invalid extra_name: %w", err)
+		}
+		value, err := parseTemplate("extra_name "+extraName.OID, extraName.Value)
+		if err != nil {
+			return nil, err
+		}
+		subject.extraNames = append(subject.extraNames, &x509ExtraName{
+			oid:   extraName.OID,
+			value: value,
+		})
+	}
+
+	return subject, nil
+}
+
+func parseX509Extension(c x509ExtensionConfig) (*x509Extension, error) {
+	oid, err := parseOID(c.OID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x509_extension: %w", err)
+	}
+	for _, reserved := range reservedExtensions {
+		if oid.Equal(reserved) {
+			return nil, fmt.Errorf("x509_extension %q is reserved", c.OID)
+		}
+	}
+
+	encoding := c.Encoding
+	switch encoding {
+	case "":
+		encoding = encodingUTF8
+	case encodingUTF8, encodingIA5, encodingPrintable, encodingDER:
+	default:
+		return nil, fmt.Errorf("x509_extension %q has unknown encoding %q: expected one of %q", c.OID, c.Encoding, []string{encodingUTF8, encodingIA5, encodingPrintable, encodingDER})
+	}
+
+	value, err := parseTemplate("x509_extension "+c.OID, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("x509_extension %q is missing the value", c.OID)
+	}
+
+	return &x509Extension{
+		oid:      c.OID,
+		critical: c.Critical,
+		encoding: encoding,
+		value:    value,
+	}, nil
+}
+
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	if s == "" {
+		return nil, fmt.Errorf("OID is required")
+	}
+	var oid asn1.ObjectIdentifier
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("malformed OID %q", s)
+		}
+		oid = append(oid, n)
+	}
+	if len(oid) < 2 {
+		return nil, fmt.Errorf("malformed OID %q", s)
+	}
+	return oid, nil
+}
+
+// parseTemplate parses the template text, returning nil when the text is
+// empty. Missing keys are an error instead of rendering "<no value>".
+func parseTemplate(name, text string) (*gotemplate.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := gotemplate.New(name).Option("missingkey=error").Funcs(agentpathtemplate.FuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func parseTemplates(name string, texts []string) ([]*gotemplate.Template, error) {
+	var tmpls []*gotemplate.Template
+	for _, text := range texts {
+		tmpl, err := parseTemplate(name, text)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl != nil {
+			tmpls = append(tmpls, tmpl)
+		}
+	}
+	return tmpls, nil
+}
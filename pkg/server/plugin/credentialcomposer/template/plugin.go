@@ -0,0 +1,262 @@
+package template
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	credentialcomposerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/credentialcomposer/v1"
+	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	pluginName = "template"
+)
+
+func BuiltIn() catalog.BuiltIn {
+	return builtIn(New())
+}
+
+func builtIn(p *Plugin) catalog.BuiltIn {
+	return catalog.MakeBuiltIn(pluginName,
+		credentialcomposerv1.CredentialComposerPluginServer(p),
+		configv1.ConfigServiceServer(p),
+	)
+}
+
+// Plugin composes credentials by applying the rules of its configuration.
+// Rules render Go templates to add JWT-SVID claims and audiences, and to set
+// X.509 subject fields and extensions.
+type Plugin struct {
+	credentialcomposerv1.UnsafeCredentialComposerServer
+	configv1.UnsafeConfigServer
+
+	config    *config
+	configMtx sync.RWMutex
+}
+
+func New() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Configure(_ context.Context, req *configv1.ConfigureRequest) (*configv1.ConfigureResponse, error) {
+	newConfig, _, err := pluginconf.Build(req, buildConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setConfig(newConfig)
+	return &configv1.ConfigureResponse{}, nil
+}
+
+func (p *Plugin) Validate(_ context.Context, req *configv1.ValidateRequest) (*configv1.ValidateResponse, error) {
+	_, notes, err := pluginconf.Build(req, buildConfig)
+
+	return &configv1.ValidateResponse{
+		Valid: err == nil,
+		Notes: notes,
+	}, err
+}
+
+func (p *Plugin) ComposeServerX509CA(_ context.Context, req *credentialcomposerv1.ComposeServerX509CARequest) (*credentialcomposerv1.ComposeServerX509CAResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	if req.Attributes == nil {
+		return nil, status.Error(codes.InvalidArgument, "request missing attributes")
+	}
+
+	// No need to clone
+	attributes := req.Attributes
+	attributes.Subject, attributes.ExtraExtensions, err = config.composeX509(credentialServerX509CA, config.trustDomain.ID(), credentialcomposer.WorkloadContext{}, attributes.Subject, attributes.ExtraExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialcomposerv1.ComposeServerX509CAResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) ComposeServerX509SVID(_ context.Context, req *credentialcomposerv1.ComposeServerX509SVIDRequest) (*credentialcomposerv1.ComposeServerX509SVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	if req.Attributes == nil {
+		return nil, status.Error(codes.InvalidArgument, "request missing attributes")
+	}
+
+	attributes := req.Attributes
+	attributes.Subject, attributes.ExtraExtensions, err = config.composeX509(credentialServerX509SVID, idutil.RequireServerID(config.trustDomain), credentialcomposer.WorkloadContext{}, attributes.Subject, attributes.ExtraExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialcomposerv1.ComposeServerX509SVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) ComposeAgentX509SVID(_ context.Context, req *credentialcomposerv1.ComposeAgentX509SVIDRequest) (*credentialcomposerv1.ComposeAgentX509SVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseRequest(req.Attributes != nil, req.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := req.Attributes
+	attributes.Subject, attributes.ExtraExtensions, err = config.composeX509(credentialAgentX509SVID, id, credentialcomposer.WorkloadContext{}, attributes.Subject, attributes.ExtraExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialcomposerv1.ComposeAgentX509SVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) ComposeWorkloadX509SVID(ctx context.Context, req *credentialcomposerv1.ComposeWorkloadX509SVIDRequest) (*credentialcomposerv1.ComposeWorkloadX509SVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseRequest(req.Attributes != nil, req.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := credentialcomposer.WorkloadContextFromIncomingContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	attributes := req.Attributes
+	attributes.Subject, attributes.ExtraExtensions, err = config.composeX509(credentialWorkloadX509SVID, id, workload, attributes.Subject, attributes.ExtraExtensions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialcomposerv1.ComposeWorkloadX509SVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) ComposeWorkloadJWTSVID(ctx context.Context, req *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest) (*credentialcomposerv1.ComposeWorkloadJWTSVIDResponse, error) {
+	config, err := p.getConfig()
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseRequest(req.Attributes != nil, req.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := credentialcomposer.WorkloadContextFromIncomingContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	attributes := req.Attributes
+	attributes.Claims, err = config.composeJWT(id, workload, attributes.Claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credentialcomposerv1.ComposeWorkloadJWTSVIDResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+func (p *Plugin) getConfig() (*config, error) {
+	p.configMtx.RLock()
+	defer p.configMtx.RUnlock()
+
+	if p.config == nil {
+		return nil, status.Error(codes.FailedPrecondition, "not configured")
+	}
+	return p.config, nil
+}
+
+func (p *Plugin) setConfig(config *config) {
+	p.configMtx.Lock()
+	defer p.configMtx.Unlock()
+
+	p.config = config
+}
+
+func (c *config) composeX509(credential string, id spiffeid.ID, workload credentialcomposer.WorkloadContext, subject *credentialcomposerv1.DistinguishedName, extensions []*credentialcomposerv1.X509Extension) (*credentialcomposerv1.DistinguishedName, []*credentialcomposerv1.X509Extension, error) {
+	data := newTemplateData(credential, id, workload)
+	for i, r := range c.rules {
+		ok, err := r.matches(credential, id, data)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "rule %d: failed to evaluate condition: %v", i, err)
+		}
+		if !ok {
+			continue
+		}
+		subject, extensions, err = r.applyX509(subject, extensions, data)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "rule %d: %v", i, err)
+		}
+	}
+	return subject, extensions, nil
+}
+
+func (c *config) composeJWT(id spiffeid.ID, workload credentialcomposer.WorkloadContext, claims *structpb.Struct) (*structpb.Struct, error) {
+	data := newTemplateData(credentialWorkloadJWTSVID, id, workload)
+
+	var newClaims map[string]any
+	for i, r := range c.rules {
+		ok, err := r.matches(credentialWorkloadJWTSVID, id, data)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "rule %d: failed to evaluate condition: %v", i, err)
+		}
+		if !ok {
+			continue
+		}
+		if newClaims == nil {
+			newClaims = claimsFromStruct(claims)
+		}
+		if err := r.applyJWT(newClaims, data); err != nil {
+			return nil, status.Errorf(codes.Internal, "rule %d: %v", i, err)
+		}
+	}
+
+	// Leave the claims untouched if no rule applied
+	if newClaims == nil {
+		return claims, nil
+	}
+
+	claims, err := structpb.NewStruct(newClaims)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode claims: %v", err)
+	}
+	return claims, nil
+}
+
+func parseRequest(hasAttributes bool, spiffeID string) (spiffeid.ID, error) {
+	switch {
+	case !hasAttributes:
+		return spiffeid.ID{}, status.Error(codes.InvalidArgument, "request missing attributes")
+	case spiffeID == "":
+		return spiffeid.ID{}, status.Error(codes.InvalidArgument, "request missing SPIFFE ID")
+	}
+
+	id, err := spiffeid.FromString(spiffeID)
+	if err != nil {
+		return spiffeid.ID{}, status.Errorf(codes.InvalidArgument, "malformed SPIFFE ID: %v", err)
+	}
+	return id, nil
+}
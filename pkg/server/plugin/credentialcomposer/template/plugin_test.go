@@ -0,0 +1,397 @@
+package template_test
+
+import (
+	"context"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer/template"
+	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/plugintest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	td          = spiffeid.RequireTrustDomainFromString("example.org")
+	workloadID  = spiffeid.RequireFromPath(td, "/ns/prod/sa/billing")
+	otherID     = spiffeid.RequireFromString("spiffe://other.org/ns/dev/sa/billing")
+	agentID     = spiffeid.RequireFromPath(td, "/spire/agent/join_token/abc")
+	key         = testkey.MustEC256()
+	ctx         = context.Background()
+	oidEmployee = asn1.ObjectIdentifier{1, 2, 3, 4}
+	oidCustom   = asn1.ObjectIdentifier{1, 2, 3, 5}
+
+	workload = credentialcomposer.WorkloadContext{
+		EntryID: "ENTRYID",
+		Selectors: []*common.Selector{
+			{Type: "k8s", Value: "ns:prod"},
+			{Type: "k8s", Value: "sa:billing"},
+		},
+		Hint:     "billing",
+		ParentID: agentID,
+		NodeSelectors: []*common.Selector{
+			{Type: "k8s_psat", Value: "cluster:prod"},
+		},
+	}
+)
+
+const config = `
+rules = [
+	{
+		credentials = ["workload_jwt_svid"]
+		spiffe_id_pattern = "spiffe://example.org/ns/*/sa/*"
+		jwt_audiences = ["aud-{{ index .PathSegments 1 }}"]
+		jwt_claims = [
+			{
+				name = "namespace"
+				value = "{{ index .PathSegments 1 }}"
+			},
+			{
+				name = "groups"
+				values = ["all", "{{ index .PathSegments 3 }}"]
+			},
+			{
+				name = "meta"
+				json = "{\"td\": \"{{ .TrustDomain }}\", \"n\": 1}"
+			},
+		]
+	},
+	{
+		credentials = ["workload_x509_svid", "agent_x509_svid"]
+		trust_domains = ["example.org"]
+		x509_subject = {
+			common_name = "{{ .Path | base }}"
+			organization = ["Acme"]
+			organizational_unit = ["{{ .Credential }}"]
+			extra_names = [
+				{
+					oid = "1.2.3.4"
+					value = "{{ .SPIFFEID }}"
+				},
+			]
+		}
+		x509_extensions = [
+			{
+				oid = "1.2.3.5"
+				value = "{{ .TrustDomain }}"
+			},
+		]
+	},
+	{
+		credentials = ["workload_x509_svid"]
+		path_regex = "^/ns/prod/"
+		condition = "{{ eq (index .PathSegments 3) \"billing\" }}"
+		x509_extensions = [
+			{
+				oid = "1.2.3.5"
+				critical = true
+				encoding = "ia5"
+				value = "prod"
+			},
+		]
+	},
+	{
+		credentials = ["server_x509_ca", "server_x509_svid"]
+		x509_subject = {
+			common_name = "{{ .Credential }} {{ .SPIFFEID }}"
+		}
+	},
+	{
+		credentials = ["workload_x509_svid", "workload_jwt_svid"]
+		condition = "{{ ne .EntryID \"\" }}"
+		jwt_claims = [
+			{
+				name = "entry"
+				value = "{{ .EntryID }} {{ .Hint }} {{ .ParentID }}"
+			},
+			{
+				name = "cluster"
+				value = "{{ index .NodeSelectors \"k8s_psat\" | first }}"
+			},
+		]
+		x509_subject = {
+			organizational_unit = ["{{ index .Selectors \"k8s\" | join \",\" }}"]
+		}
+	},
+]
+`
+
+func TestConfigure(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		config    string
+		expectMsg string
+	}{
+		{
+			name:   "no rules",
+			config: "",
+		},
+		{
+			name:   "valid rules",
+			config: config,
+		},
+		{
+			name:      "malformed HCL",
+			config:    "rules = [",
+			expectMsg: "unable to decode configuration",
+		},
+		{
+			name:      "unknown credential",
+			config:    `rules = [{ credentials = ["jwt"] }]`,
+			expectMsg: `rule 0: unknown credential "jwt"`,
+		},
+		{
+			name:      "invalid SPIFFE ID pattern",
+			config:    `rules = [{ spiffe_id_pattern = "[" }]`,
+			expectMsg: "rule 0: invalid spiffe_id_pattern",
+		},
+		{
+			name:      "invalid trust domain",
+			config:    `rules = [{ trust_domains = ["Example.org"] }]`,
+			expectMsg: `rule 0: invalid trust domain "Example.org"`,
+		},
+		{
+			name:      "invalid path regex",
+			config:    `rules = [{ path_regex = "(" }]`,
+			expectMsg: "rule 0: invalid path_regex",
+		},
+		{
+			name:      "invalid template",
+			config:    `rules = [{ condition = "{{ .Path" }]`,
+			expectMsg: "rule 0: invalid condition template",
+		},
+		{
+			name:      "reserved claim",
+			config:    `rules = [{ jwt_claims = [{ name = "sub", value = "x" }] }]`,
+			expectMsg: `rule 0: jwt_claim "sub" is reserved`,
+		},
+		{
+			name:      "claim without value",
+			config:    `rules = [{ jwt_claims = [{ name = "foo" }] }]`,
+			expectMsg: `rule 0: jwt_claim "foo" requires exactly one of value, values or json`,
+		},
+		{
+			name:      "claim with multiple values",
+			config:    `rules = [{ jwt_claims = [{ name = "foo", value = "x", json = "1" }] }]`,
+			expectMsg: `rule 0: jwt_claim "foo" requires exactly one of value, values or json`,
+		},
+		{
+			name:      "malformed extra name OID",
+			config:    `rules = [{ x509_subject = { extra_names = [{ oid = "1.a", value = "x" }] } }]`,
+			expectMsg: `rule 0: invalid extra_name: malformed OID "1.a"`,
+		},
+		{
+			name:      "reserved extension",
+			config:    `rules = [{ x509_extensions = [{ oid = "2.5.29.17", value = "x" }] }]`,
+			expectMsg: `rule 0: x509_extension "2.5.29.17" is reserved`,
+		},
+		{
+			name:      "unknown extension encoding",
+			config:    `rules = [{ x509_extensions = [{ oid = "1.2.3", value = "x", encoding = "bmp" }] }]`,
+			expectMsg: `rule 0: x509_extension "1.2.3" has unknown encoding "bmp"`,
+		},
+		{
+			name:      "extension without value",
+			config:    `rules = [{ x509_extensions = [{ oid = "1.2.3" }] }]`,
+			expectMsg: `rule 0: x509_extension "1.2.3" is missing the value`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			plugintest.Load(t, template.BuiltIn(), nil,
+				plugintest.CaptureConfigureError(&err),
+				plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+				plugintest.Configure(tt.config),
+			)
+			if tt.expectMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			spiretest.RequireGRPCStatusHasPrefix(t, err, codes.InvalidArgument, tt.expectMsg)
+		})
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	cc := new(credentialcomposer.V1)
+	plugintest.Load(t, template.BuiltIn(), cc)
+
+	_, err := cc.ComposeWorkloadJWTSVID(ctx, workloadID, credentialcomposer.WorkloadContext{}, credentialcomposer.JWTSVIDAttributes{
+		Claims: map[string]any{"sub": workloadID.String()},
+	})
+	spiretest.RequireGRPCStatusContains(t, err, codes.FailedPrecondition, "not configured")
+}
+
+func TestPlugin(t *testing.T) {
+	cc := new(credentialcomposer.V1)
+	plugintest.Load(t, template.BuiltIn(), cc,
+		plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+		plugintest.Configure(config),
+	)
+
+	t.Run("ComposeServerX509CA", func(t *testing.T) {
+		got, err := cc.ComposeServerX509CA(ctx, credentialcomposer.X509CAAttributes{
+			Subject: pkix.Name{Organization: []string{"SPIFFE"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, pkix.Name{
+			Organization: []string{"SPIFFE"},
+			CommonName:   "server_x509_ca spiffe://example.org",
+		}, got.Subject)
+	})
+
+	t.Run("ComposeServerX509SVID", func(t *testing.T) {
+		got, err := cc.ComposeServerX509SVID(ctx, credentialcomposer.X509SVIDAttributes{})
+		require.NoError(t, err)
+		assert.Equal(t, pkix.Name{
+			CommonName: "server_x509_svid spiffe://example.org/spire/server",
+		}, got.Subject)
+	})
+
+	t.Run("ComposeAgentX509SVID", func(t *testing.T) {
+		got, err := cc.ComposeAgentX509SVID(ctx, agentID, key.Public(), credentialcomposer.X509SVIDAttributes{})
+		require.NoError(t, err)
+		assert.Equal(t, pkix.Name{
+			CommonName:         "abc",
+			Organization:       []string{"Acme"},
+			OrganizationalUnit: []string{"agent_x509_svid"},
+			ExtraNames: []pkix.AttributeTypeAndValue{
+				{Type: oidEmployee, Value: agentID.String()},
+			},
+		}, got.Subject)
+		assert.Equal(t, []pkix.Extension{
+			{Id: oidCustom, Value: mustMarshal(t, "example.org", "utf8")},
+		}, got.ExtraExtensions)
+	})
+
+	t.Run("ComposeWorkloadX509SVID", func(t *testing.T) {
+		t.Run("rules applied in order", func(t *testing.T) {
+			got, err := cc.ComposeWorkloadX509SVID(ctx, workloadID, key.Public(), credentialcomposer.WorkloadContext{}, credentialcomposer.X509SVIDAttributes{
+				DNSNames: []string{"billing"},
+				Subject: pkix.Name{
+					Organization: []string{"SPIFFE"},
+					ExtraNames: []pkix.AttributeTypeAndValue{
+						{Type: oidEmployee, Value: "old"},
+					},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, credentialcomposer.X509SVIDAttributes{
+				DNSNames: []string{"billing"},
+				Subject: pkix.Name{
+					CommonName:         "billing",
+					Organization:       []string{"Acme"},
+					OrganizationalUnit: []string{"workload_x509_svid"},
+					ExtraNames: []pkix.AttributeTypeAndValue{
+						{Type: oidEmployee, Value: workloadID.String()},
+					},
+				},
+				// The extension of the second rule is replaced by the third
+				ExtraExtensions: []pkix.Extension{
+					{Id: oidCustom, Critical: true, Value: mustMarshal(t, "prod", "ia5")},
+				},
+			}, got)
+		})
+
+		t.Run("attributes unchanged", func(t *testing.T) {
+			want := credentialcomposer.X509SVIDAttributes{
+				Subject: pkix.Name{Organization: []string{"SPIFFE"}},
+			}
+			got, err := cc.ComposeWorkloadX509SVID(ctx, otherID, key.Public(), credentialcomposer.WorkloadContext{}, want)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+
+		t.Run("workload context", func(t *testing.T) {
+			got, err := cc.ComposeWorkloadX509SVID(ctx, otherID, key.Public(), workload, credentialcomposer.X509SVIDAttributes{})
+			require.NoError(t, err)
+			assert.Equal(t, pkix.Name{
+				OrganizationalUnit: []string{"ns:prod,sa:billing"},
+			}, got.Subject)
+		})
+	})
+
+	t.Run("ComposeWorkloadJWTSVID", func(t *testing.T) {
+		t.Run("claims added", func(t *testing.T) {
+			got, err := cc.ComposeWorkloadJWTSVID(ctx, workloadID, credentialcomposer.WorkloadContext{}, credentialcomposer.JWTSVIDAttributes{
+				Claims: map[string]any{
+					"sub": workloadID.String(),
+					"aud": []any{"aud1", "aud-prod"},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{
+				"sub":       workloadID.String(),
+				"aud":       []any{"aud1", "aud-prod"},
+				"namespace": "prod",
+				"groups":    []any{"all", "billing"},
+				"meta":      map[string]any{"td": "example.org", "n": float64(1)},
+			}, got.Claims)
+		})
+
+		t.Run("audience appended to a single audience", func(t *testing.T) {
+			got, err := cc.ComposeWorkloadJWTSVID(ctx, workloadID, credentialcomposer.WorkloadContext{}, credentialcomposer.JWTSVIDAttributes{
+				Claims: map[string]any{"aud": "aud1"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []any{"aud1", "aud-prod"}, got.Claims["aud"])
+		})
+
+		t.Run("attributes unchanged", func(t *testing.T) {
+			want := credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"sub": otherID.String()}}
+			got, err := cc.ComposeWorkloadJWTSVID(ctx, otherID, credentialcomposer.WorkloadContext{}, want)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+
+		t.Run("workload context", func(t *testing.T) {
+			got, err := cc.ComposeWorkloadJWTSVID(ctx, otherID, workload, credentialcomposer.JWTSVIDAttributes{
+				Claims: map[string]any{"sub": otherID.String()},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, map[string]any{
+				"sub":     otherID.String(),
+				"entry":   "ENTRYID billing " + agentID.String(),
+				"cluster": "cluster:prod",
+			}, got.Claims)
+		})
+	})
+}
+
+func TestRenderError(t *testing.T) {
+	cc := new(credentialcomposer.V1)
+	plugintest.Load(t, template.BuiltIn(), cc,
+		plugintest.CoreConfig(catalog.CoreConfig{TrustDomain: td}),
+		plugintest.Configure(`
+			rules = [
+				{
+					jwt_claims = [
+						{
+							name = "foo"
+							value = "{{ index .PathSegments 5 }}"
+						},
+					]
+				},
+			]
+		`),
+	)
+
+	_, err := cc.ComposeWorkloadJWTSVID(ctx, workloadID, credentialcomposer.WorkloadContext{}, credentialcomposer.JWTSVIDAttributes{
+		Claims: map[string]any{"sub": workloadID.String()},
+	})
+	spiretest.RequireGRPCStatusContains(t, err, codes.Internal, "rule 0:")
+}
+
+func mustMarshal(t *testing.T, value, params string) []byte {
+	b, err := asn1.MarshalWithParams(value, params)
+	require.NoError(t, err)
+	return b
+}
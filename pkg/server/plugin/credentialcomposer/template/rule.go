@@ -0,0 +1,371 @@
+package template
+
+import (
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	gotemplate "text/template"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	credentialcomposerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/credentialcomposer/v1"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// templateData is the data available to the templates of a rule
+type templateData struct {
+	// Credential is the kind of credential being composed (e.g.
+	// "workload_x509_svid")
+	Credential string
+
+	// SPIFFEID is the SPIFFE ID of the credential. It is the trust domain
+	// ID for the server X509 CA.
+	SPIFFEID string
+
+	// TrustDomain is the trust domain name of the SPIFFE ID
+	TrustDomain string
+
+	// Path is the path of the SPIFFE ID
+	Path string
+
+	// PathSegments are the segments of the path of the SPIFFE ID
+	PathSegments []string
+
+	// The following describe the registration entry of workload SVIDs and
+	// the agent that requested them. They are empty for other credentials.
+
+	// EntryID is the ID of the registration entry
+	EntryID string
+
+	// Selectors are the values of the registration entry selectors, by type
+	Selectors map[string][]string
+
+	// Hint is the hint of the registration entry
+	Hint string
+
+	// ParentID is the parent ID of the registration entry
+	ParentID string
+
+	// NodeSelectors are the values of the agent selectors, by type
+	NodeSelectors map[string][]string
+}
+
+func newTemplateData(credential string, id spiffeid.ID, workload credentialcomposer.WorkloadContext) templateData {
+	data := templateData{
+		Credential:    credential,
+		SPIFFEID:      id.String(),
+		TrustDomain:   id.TrustDomain().Name(),
+		Path:          id.Path(),
+		PathSegments:  pathSegments(id.Path()),
+		EntryID:       workload.EntryID,
+		Selectors:     selectorValues(workload.Selectors),
+		Hint:          workload.Hint,
+		NodeSelectors: selectorValues(workload.NodeSelectors),
+	}
+	if !workload.ParentID.IsZero() {
+		data.ParentID = workload.ParentID.String()
+	}
+	return data
+}
+
+func selectorValues(selectors []*common.Selector) map[string][]string {
+	values := make(map[string][]string)
+	for _, selector := range selectors {
+		values[selector.Type] = append(values[selector.Type], selector.Value)
+	}
+	return values
+}
+
+func pathSegments(p string) []string {
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+type rule struct {
+	credentials     []string
+	spiffeIDPattern string
+	trustDomains    []spiffeid.TrustDomain
+	pathRegex       *regexp.Regexp
+	condition       *gotemplate.Template
+
+	jwtAudiences   []*gotemplate.Template
+	jwtClaims      []*jwtClaim
+	x509Subject    *x509Subject
+	x509Extensions []*x509Extension
+}
+
+type jwtClaim struct {
+	name   string
+	value  *gotemplate.Template
+	values []*gotemplate.Template
+	json   *gotemplate.Template
+}
+
+type x509Subject struct {
+	commonName         *gotemplate.Template
+	serialNumber       *gotemplate.Template
+	country            []*gotemplate.Template
+	organization       []*gotemplate.Template
+	organizationalUnit []*gotemplate.Template
+	locality           []*gotemplate.Template
+	province           []*gotemplate.Template
+	streetAddress      []*gotemplate.Template
+	postalCode         []*gotemplate.Template
+	extraNames         []*x509ExtraName
+}
+
+type x509ExtraName struct {
+	oid   string
+	value *gotemplate.Template
+}
+
+type x509Extension struct {
+	oid      string
+	critical bool
+	encoding string
+	value    *gotemplate.Template
+}
+
+// matches returns true if the rule applies to the credential
+func (r *rule) matches(credential string, id spiffeid.ID, data templateData) (bool, error) {
+	if !slices.Contains(r.credentials, credential) {
+		return false, nil
+	}
+	if r.spiffeIDPattern != "" {
+		// The pattern was validated when the rule was parsed
+		if ok, _ := path.Match(r.spiffeIDPattern, id.String()); !ok {
+			return false, nil
+		}
+	}
+	if len(r.trustDomains) > 0 && !slices.Contains(r.trustDomains, id.TrustDomain()) {
+		return false, nil
+	}
+	if r.pathRegex != nil && !r.pathRegex.MatchString(id.Path()) {
+		return false, nil
+	}
+	if r.condition != nil {
+		result, err := render(r.condition, data)
+		if err != nil {
+			return false, err
+		}
+		return strings.TrimSpace(result) == "true", nil
+	}
+	return true, nil
+}
+
+func (r *rule) applyX509(subject *credentialcomposerv1.DistinguishedName, extensions []*credentialcomposerv1.X509Extension, data templateData) (*credentialcomposerv1.DistinguishedName, []*credentialcomposerv1.X509Extension, error) {
+	if r.x509Subject != nil {
+		if subject == nil {
+			subject = &credentialcomposerv1.DistinguishedName{}
+		}
+		if err := r.x509Subject.apply(subject, data); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, extension := range r.x509Extensions {
+		x509Extension, err := extension.render(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Replace the extension if it already exists. Otherwise, add it.
+		i := slices.IndexFunc(extensions, func(e *credentialcomposerv1.X509Extension) bool {
+			return e.Oid == x509Extension.Oid
+		})
+		if i >= 0 {
+			extensions[i] = x509Extension
+		} else {
+			extensions = append(extensions, x509Extension)
+		}
+	}
+
+	return subject, extensions, nil
+}
+
+func (r *rule) applyJWT(claims map[string]any, data templateData) error {
+	if len(r.jwtAudiences) > 0 {
+		audiences, err := renderAll(r.jwtAudiences, data)
+		if err != nil {
+			return err
+		}
+		claims["aud"] = appendAudiences(claims["aud"], audiences)
+	}
+
+	for _, claim := range r.jwtClaims {
+		value, err := claim.render(data)
+		if err != nil {
+			return err
+		}
+		claims[claim.name] = value
+	}
+	return nil
+}
+
+// appendAudiences appends the audiences to the "aud" claim, which is either
+// a string or a list of strings.
+func appendAudiences(aud any, audiences []string) any {
+	var out []any
+	switch aud := aud.(type) {
+	case string:
+		out = append(out, aud)
+	case []any:
+		out = append(out, aud...)
+	}
+	for _, audience := range audiences {
+		if !slices.Contains(out, any(audience)) {
+			out = append(out, audience)
+		}
+	}
+	return out
+}
+
+func (c *jwtClaim) render(data templateData) (any, error) {
+	switch {
+	case c.value != nil:
+		return render(c.value, data)
+	case len(c.values) > 0:
+		values, err := renderAll(c.values, data)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]any, 0, len(values))
+		for _, value := range values {
+			out = append(out, value)
+		}
+		return out, nil
+	default:
+		text, err := render(c.json, data)
+		if err != nil {
+			return nil, err
+		}
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("jwt_claim %q did not render valid JSON: %w", c.name, err)
+		}
+		return value, nil
+	}
+}
+
+func (s *x509Subject) apply(subject *credentialcomposerv1.DistinguishedName, data templateData) error {
+	if s.commonName != nil {
+		commonName, err := render(s.commonName, data)
+		if err != nil {
+			return err
+		}
+		subject.CommonName = commonName
+	}
+	if s.serialNumber != nil {
+		serialNumber, err := render(s.serialNumber, data)
+		if err != nil {
+			return err
+		}
+		subject.SerialNumber = serialNumber
+	}
+
+	for _, field := range []struct {
+		tmpls []*gotemplate.Template
+		out   *[]string
+	}{
+		{tmpls: s.country, out: &subject.Country},
+		{tmpls: s.organization, out: &subject.Organization},
+		{tmpls: s.organizationalUnit, out: &subject.OrganizationalUnit},
+		{tmpls: s.locality, out: &subject.Locality},
+		{tmpls: s.province, out: &subject.Province},
+		{tmpls: s.streetAddress, out: &subject.StreetAddress},
+		{tmpls: s.postalCode, out: &subject.PostalCode},
+	} {
+		if len(field.tmpls) == 0 {
+			continue
+		}
+		values, err := renderAll(field.tmpls, data)
+		if err != nil {
+			return err
+		}
+		*field.out = values
+	}
+
+	for _, extraName := range s.extraNames {
+		value, err := render(extraName.value, data)
+		if err != nil {
+			return err
+		}
+		attribute := &credentialcomposerv1.AttributeTypeAndValue{
+			Oid:         extraName.oid,
+			StringValue: value,
+		}
+		// Replace the attribute if it already exists. Otherwise, add it.
+		i := slices.IndexFunc(subject.ExtraNames, func(a *credentialcomposerv1.AttributeTypeAndValue) bool {
+			return a.Oid == extraName.oid
+		})
+		if i >= 0 {
+			subject.ExtraNames[i] = attribute
+		} else {
+			subject.ExtraNames = append(subject.ExtraNames, attribute)
+		}
+	}
+	return nil
+}
+
+func (e *x509Extension) render(data templateData) (*credentialcomposerv1.X509Extension, error) {
+	text, err := render(e.value, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	switch e.encoding {
+	case encodingDER:
+		value, err = base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("x509_extension %q did not render valid base64: %w", e.oid, err)
+		}
+	default:
+		value, err = asn1.MarshalWithParams(text, e.encoding)
+		if err != nil {
+			return nil, fmt.Errorf("x509_extension %q could not be encoded as %s: %w", e.oid, e.encoding, err)
+		}
+	}
+
+	return &credentialcomposerv1.X509Extension{
+		Oid:      e.oid,
+		Value:    value,
+		Critical: e.critical,
+	}, nil
+}
+
+func render(tmpl *gotemplate.Template, data templateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func renderAll(tmpls []*gotemplate.Template, data templateData) ([]string, error) {
+	values := make([]string, 0, len(tmpls))
+	for _, tmpl := range tmpls {
+		value, err := render(tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// claimsFromStruct converts the claims to a map that rules can modify
+func claimsFromStruct(claims *structpb.Struct) map[string]any {
+	if claims == nil {
+		return make(map[string]any)
+	}
+	return claims.AsMap()
+}
@@ -56,7 +56,7 @@ func TestPlugin(t *testing.T) {
 		t.Run("appended to subject without unique ID", func(t *testing.T) {
 			want := credentialcomposer.X509SVIDAttributes{}
 
-			got, err := cc.ComposeWorkloadX509SVID(ctx, id1, key.Public(), want)
+			got, err := cc.ComposeWorkloadX509SVID(ctx, id1, key.Public(), credentialcomposer.WorkloadContext{}, want)
 
 			// The plugin should add the unique ID attribute
 			want.Subject.ExtraNames = append(want.Subject.ExtraNames, x509svid.UniqueIDAttribute(id1))
@@ -74,7 +74,7 @@ func TestPlugin(t *testing.T) {
 				},
 			}
 
-			got, err := cc.ComposeWorkloadX509SVID(ctx, id2, key.Public(), want)
+			got, err := cc.ComposeWorkloadX509SVID(ctx, id2, key.Public(), credentialcomposer.WorkloadContext{}, want)
 
 			// The plugin should replace the unique ID attribute
 			want.Subject.ExtraNames[0] = x509svid.UniqueIDAttribute(id2)
@@ -87,7 +87,7 @@ func TestPlugin(t *testing.T) {
 	t.Run("ComposeWorkloadJWTSVID", func(t *testing.T) {
 		t.Run("attributes unchanged", func(t *testing.T) {
 			want := credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"sub": id1.String()}}
-			got, err := cc.ComposeWorkloadJWTSVID(ctx, id1, want)
+			got, err := cc.ComposeWorkloadJWTSVID(ctx, id1, credentialcomposer.WorkloadContext{}, want)
 			assert.NoError(t, err)
 			assert.Equal(t, want, got)
 		})
@@ -71,7 +71,7 @@ func (v1 V1) ComposeAgentX509SVID(ctx context.Context, id spiffeid.ID, publicKey
 	return v1.handleX509SVIDAttributesResponse(attributes, resp, err)
 }
 
-func (v1 V1) ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, publicKey crypto.PublicKey, attributes X509SVIDAttributes) (X509SVIDAttributes, error) {
+func (v1 V1) ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, publicKey crypto.PublicKey, workload WorkloadContext, attributes X509SVIDAttributes) (X509SVIDAttributes, error) {
 	if id.IsZero() {
 		return X509SVIDAttributes{}, v1.Error(codes.Internal, "invalid workload ID: empty")
 	}
@@ -85,6 +85,10 @@ func (v1 V1) ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, public
 	if err != nil {
 		return X509SVIDAttributes{}, v1.Errorf(codes.Internal, "invalid workload X509SVID attributes: %v", err)
 	}
+	ctx, err = withWorkloadContext(ctx, workload)
+	if err != nil {
+		return X509SVIDAttributes{}, v1.Errorf(codes.Internal, "invalid workload context: %v", err)
+	}
 	resp, err := v1.CredentialComposerPluginClient.ComposeWorkloadX509SVID(ctx, &credentialcomposerv1.ComposeWorkloadX509SVIDRequest{
 		Attributes: attributesIn,
 		SpiffeId:   id.String(),
@@ -93,7 +97,7 @@ func (v1 V1) ComposeWorkloadX509SVID(ctx context.Context, id spiffeid.ID, public
 	return v1.handleX509SVIDAttributesResponse(attributes, resp, err)
 }
 
-func (v1 V1) ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, attributes JWTSVIDAttributes) (JWTSVIDAttributes, error) {
+func (v1 V1) ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, workload WorkloadContext, attributes JWTSVIDAttributes) (JWTSVIDAttributes, error) {
 	if id.IsZero() {
 		return JWTSVIDAttributes{}, v1.Error(codes.Internal, "invalid workload ID: empty")
 	}
@@ -101,6 +105,10 @@ func (v1 V1) ComposeWorkloadJWTSVID(ctx context.Context, id spiffeid.ID, attribu
 	if err != nil {
 		return JWTSVIDAttributes{}, v1.Errorf(codes.Internal, "invalid workload JWTSVID attributes: %v", err)
 	}
+	ctx, err = withWorkloadContext(ctx, workload)
+	if err != nil {
+		return JWTSVIDAttributes{}, v1.Errorf(codes.Internal, "invalid workload context: %v", err)
+	}
 	resp, err := v1.CredentialComposerPluginClient.ComposeWorkloadJWTSVID(ctx, &credentialcomposerv1.ComposeWorkloadJWTSVIDRequest{
 		SpiffeId:   id.String(),
 		Attributes: attributesIn,
@@ -12,6 +12,7 @@ import (
 	credentialcomposerv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/credentialcomposer/v1"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
+	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
@@ -26,6 +27,14 @@ var (
 	publicKey         = testkey.MustEC256().Public()
 	publicKeyBytes, _ = x509.MarshalPKIXPublicKey(publicKey)
 
+	workload = credentialcomposer.WorkloadContext{
+		EntryID:       "ENTRYID",
+		Selectors:     []*common.Selector{{Type: "k8s", Value: "sa:billing"}},
+		Hint:          "HINT",
+		ParentID:      spiffeid.RequireFromString("spiffe://domain.test/agent"),
+		NodeSelectors: []*common.Selector{{Type: "aws_iid", Value: "account:123"}},
+	}
+
 	subject1 = pkix.Name{
 		Country:            []string{"C1"},
 		Organization:       []string{"O1"},
@@ -588,6 +597,7 @@ func TestV1ComposeWorkloadX509SVID(t *testing.T) {
 
 		idIn            spiffeid.ID
 		publicKeyIn     crypto.PublicKey
+		workloadIn      credentialcomposer.WorkloadContext
 		attributesIn    credentialcomposer.X509SVIDAttributes
 		expectRequestIn *credentialcomposerv1.ComposeWorkloadX509SVIDRequest
 
@@ -651,6 +661,26 @@ func TestV1ComposeWorkloadX509SVID(t *testing.T) {
 				Subject: subject1,
 			},
 		},
+		{
+			test:        "workload context sent to plugin",
+			idIn:        id,
+			publicKeyIn: publicKey,
+			workloadIn:  workload,
+			attributesIn: credentialcomposer.X509SVIDAttributes{
+				Subject: subject1,
+			},
+			expectRequestIn: &credentialcomposerv1.ComposeWorkloadX509SVIDRequest{
+				SpiffeId:  id.String(),
+				PublicKey: publicKeyBytes,
+				Attributes: &credentialcomposerv1.X509SVIDAttributes{
+					Subject: subject1v1,
+				},
+			},
+			responseOut: &credentialcomposerv1.ComposeWorkloadX509SVIDResponse{},
+			expectAttributesOut: credentialcomposer.X509SVIDAttributes{
+				Subject: subject1,
+			},
+		},
 		{
 			test:        "attributes unchanged if plugin does not respond with attributes",
 			idIn:        id,
@@ -750,13 +780,14 @@ func TestV1ComposeWorkloadX509SVID(t *testing.T) {
 		t.Run(tt.test, func(t *testing.T) {
 			plugin := &fakeV1Plugin{err: tt.pluginErr, composeWorkloadX509SVIDResponseOut: tt.responseOut}
 			cc := loadV1Plugin(t, plugin)
-			attributesOut, err := cc.ComposeWorkloadX509SVID(context.Background(), tt.idIn, tt.publicKeyIn, tt.attributesIn)
+			attributesOut, err := cc.ComposeWorkloadX509SVID(context.Background(), tt.idIn, tt.publicKeyIn, tt.workloadIn, tt.attributesIn)
 			if tt.expectCode != codes.OK {
 				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMessage)
 				return
 			}
 			require.NoError(t, err)
 			spiretest.AssertProtoEqual(t, plugin.composeWorkloadX509SVIDRequestIn, tt.expectRequestIn)
+			assert.Equal(t, tt.workloadIn, plugin.workloadIn)
 			assert.Equal(t, attributesOut, tt.expectAttributesOut)
 		})
 	}
@@ -769,6 +800,7 @@ func TestV1ComposeWorkloadJWTSVID(t *testing.T) {
 		pluginErr error
 
 		idIn            spiffeid.ID
+		workloadIn      credentialcomposer.WorkloadContext
 		attributesIn    credentialcomposer.JWTSVIDAttributes
 		expectRequestIn *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest
 
@@ -825,6 +857,20 @@ func TestV1ComposeWorkloadJWTSVID(t *testing.T) {
 			responseOut:         &credentialcomposerv1.ComposeWorkloadJWTSVIDResponse{},
 			expectAttributesOut: credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
 		},
+		{
+			test:         "workload context sent to plugin",
+			idIn:         id,
+			workloadIn:   workload,
+			attributesIn: credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+			expectRequestIn: &credentialcomposerv1.ComposeWorkloadJWTSVIDRequest{
+				SpiffeId: id.String(),
+				Attributes: &credentialcomposerv1.JWTSVIDAttributes{
+					Claims: &structpb.Struct{Fields: map[string]*structpb.Value{"ORIGINAL_KEY": structpb.NewStringValue("ORIGINAL_VALUE")}},
+				},
+			},
+			responseOut:         &credentialcomposerv1.ComposeWorkloadJWTSVIDResponse{},
+			expectAttributesOut: credentialcomposer.JWTSVIDAttributes{Claims: map[string]any{"ORIGINAL_KEY": "ORIGINAL_VALUE"}},
+		},
 		{
 			test:         "attributes overridden by plugin",
 			idIn:         id,
@@ -846,13 +892,14 @@ func TestV1ComposeWorkloadJWTSVID(t *testing.T) {
 		t.Run(tt.test, func(t *testing.T) {
 			plugin := &fakeV1Plugin{err: tt.pluginErr, composeWorkloadJWTSVIDResponseOut: tt.responseOut}
 			cc := loadV1Plugin(t, plugin)
-			attributesOut, err := cc.ComposeWorkloadJWTSVID(context.Background(), tt.idIn, tt.attributesIn)
+			attributesOut, err := cc.ComposeWorkloadJWTSVID(context.Background(), tt.idIn, tt.workloadIn, tt.attributesIn)
 			if tt.expectCode != codes.OK {
 				spiretest.RequireGRPCStatusContains(t, err, tt.expectCode, tt.expectMessage)
 				return
 			}
 			require.NoError(t, err)
 			spiretest.AssertProtoEqual(t, plugin.composeWorkloadJWTSVIDRequestIn, tt.expectRequestIn)
+			assert.Equal(t, tt.workloadIn, plugin.workloadIn)
 			assert.Equal(t, attributesOut, tt.expectAttributesOut)
 		})
 	}
@@ -879,6 +926,7 @@ type fakeV1Plugin struct {
 	composeWorkloadX509SVIDResponseOut *credentialcomposerv1.ComposeWorkloadX509SVIDResponse
 	composeWorkloadJWTSVIDRequestIn    *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest
 	composeWorkloadJWTSVIDResponseOut  *credentialcomposerv1.ComposeWorkloadJWTSVIDResponse
+	workloadIn                         credentialcomposer.WorkloadContext
 }
 
 func (p *fakeV1Plugin) ComposeServerX509CA(_ context.Context, req *credentialcomposerv1.ComposeServerX509CARequest) (*credentialcomposerv1.ComposeServerX509CAResponse, error) {
@@ -896,12 +944,22 @@ func (p *fakeV1Plugin) ComposeAgentX509SVID(_ context.Context, req *credentialco
 	return p.composeAgentX509SVIDResponseOut, p.err
 }
 
-func (p *fakeV1Plugin) ComposeWorkloadX509SVID(_ context.Context, req *credentialcomposerv1.ComposeWorkloadX509SVIDRequest) (*credentialcomposerv1.ComposeWorkloadX509SVIDResponse, error) {
+func (p *fakeV1Plugin) ComposeWorkloadX509SVID(ctx context.Context, req *credentialcomposerv1.ComposeWorkloadX509SVIDRequest) (*credentialcomposerv1.ComposeWorkloadX509SVIDResponse, error) {
+	workload, err := credentialcomposer.WorkloadContextFromIncomingContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	p.composeWorkloadX509SVIDRequestIn = req
+	p.workloadIn = workload
 	return p.composeWorkloadX509SVIDResponseOut, p.err
 }
 
-func (p *fakeV1Plugin) ComposeWorkloadJWTSVID(_ context.Context, req *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest) (*credentialcomposerv1.ComposeWorkloadJWTSVIDResponse, error) {
+func (p *fakeV1Plugin) ComposeWorkloadJWTSVID(ctx context.Context, req *credentialcomposerv1.ComposeWorkloadJWTSVIDRequest) (*credentialcomposerv1.ComposeWorkloadJWTSVIDResponse, error) {
+	workload, err := credentialcomposer.WorkloadContextFromIncomingContext(ctx)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	p.composeWorkloadJWTSVIDRequestIn = req
+	p.workloadIn = workload
 	return p.composeWorkloadJWTSVIDResponseOut, p.err
 }
@@ -0,0 +1,137 @@
+package credentialcomposer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/metadata"
+)
+
+// WorkloadContextMetadataKey is the gRPC request metadata key holding the
+// JSON encoded workload context of the ComposeWorkloadX509SVID and
+// ComposeWorkloadJWTSVID calls. Plugins can read it with
+// WorkloadContextFromIncomingContext. The metadata is not set when the
+// context is empty.
+//
+// This is a stopgap: the context belongs in the ComposeWorkloadX509SVIDRequest
+// and ComposeWorkloadJWTSVIDRequest messages of the plugin SDK, which do not
+// have the fields yet (entry_id, selectors, hint, parent_id and
+// node_selectors). Once an SDK release adds them, the server sets both and the
+// metadata is removed after a deprecation period. Unlike request fields, the
+// metadata is not part of the plugin interface contract, so plugins written
+// against other SDK languages or versions will not see it.
+const WorkloadContextMetadataKey = "spire-credentialcomposer-workload-bin"
+
+// WorkloadContext describes what a workload SVID is minted for. It is empty
+// when the SVID is not minted for a registration entry (e.g. SVIDs minted
+// with the MintX509SVID and MintJWTSVID RPCs).
+type WorkloadContext struct {
+	// EntryID is the ID of the registration entry
+	EntryID string
+
+	// Selectors are the selectors of the registration entry
+	Selectors []*common.Selector
+
+	// Hint is the hint of the registration entry
+	Hint string
+
+	// ParentID is the parent ID of the registration entry
+	ParentID spiffeid.ID
+
+	// NodeSelectors are the selectors of the agent that requested the SVID.
+	// They are empty if the SVID was not requested by an agent.
+	NodeSelectors []*common.Selector
+}
+
+// IsZero returns true if the context is empty.
+func (w WorkloadContext) IsZero() bool {
+	return w.EntryID == "" && len(w.Selectors) == 0 && w.Hint == "" && w.ParentID.IsZero() && len(w.NodeSelectors) == 0
+}
+
+// workloadContextJSON is the JSON encoding of the workload context sent to
+// plugins.
+type workloadContextJSON struct {
+	EntryID       string         `json:"entry_id,omitempty"`
+	Selectors     []selectorJSON `json:"selectors,omitempty"`
+	Hint          string         `json:"hint,omitempty"`
+	ParentID      string         `json:"parent_id,omitempty"`
+	NodeSelectors []selectorJSON `json:"node_selectors,omitempty"`
+}
+
+type selectorJSON struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// withWorkloadContext returns a context that sends the workload context on
+// outgoing calls.
+func withWorkloadContext(ctx context.Context, workload WorkloadContext) (context.Context, error) {
+	if workload.IsZero() {
+		return ctx, nil
+	}
+
+	out := workloadContextJSON{
+		EntryID:       workload.EntryID,
+		Selectors:     selectorsToJSON(workload.Selectors),
+		Hint:          workload.Hint,
+		NodeSelectors: selectorsToJSON(workload.NodeSelectors),
+	}
+	if !workload.ParentID.IsZero() {
+		out.ParentID = workload.ParentID.String()
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx, WorkloadContextMetadataKey, string(data)), nil
+}
+
+// WorkloadContextFromIncomingContext returns the workload context of an
+// incoming ComposeWorkloadX509SVID or ComposeWorkloadJWTSVID call. It returns
+// an empty context if the call does not have one.
+func WorkloadContextFromIncomingContext(ctx context.Context) (WorkloadContext, error) {
+	values := metadata.ValueFromIncomingContext(ctx, WorkloadContextMetadataKey)
+	if len(values) == 0 {
+		return WorkloadContext{}, nil
+	}
+
+	in := new(workloadContextJSON)
+	if err := json.Unmarshal([]byte(values[0]), in); err != nil {
+		return WorkloadContext{}, fmt.Errorf("malformed workload context: %w", err)
+	}
+
+	workload := WorkloadContext{
+		EntryID:       in.EntryID,
+		Selectors:     selectorsFromJSON(in.Selectors),
+		Hint:          in.Hint,
+		NodeSelectors: selectorsFromJSON(in.NodeSelectors),
+	}
+	if in.ParentID != "" {
+		parentID, err := spiffeid.FromString(in.ParentID)
+		if err != nil {
+			return WorkloadContext{}, fmt.Errorf("malformed workload context parent ID: %w", err)
+		}
+		workload.ParentID = parentID
+	}
+	return workload, nil
+}
+
+func selectorsToJSON(selectors []*common.Selector) []selectorJSON {
+	var out []selectorJSON
+	for _, selector := range selectors {
+		out = append(out, selectorJSON{Type: selector.Type, Value: selector.Value})
+	}
+	return out
+}
+
+func selectorsFromJSON(selectors []selectorJSON) []*common.Selector {
+	var out []*common.Selector
+	for _, selector := range selectors {
+		out = append(out, &common.Selector{Type: selector.Type, Value: selector.Value})
+	}
+	return out
+}
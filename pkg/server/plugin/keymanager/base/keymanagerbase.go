@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -43,6 +44,7 @@ type Generator interface {
 	GenerateRSA4096Key() (crypto.Signer, error)
 	GenerateEC256Key() (crypto.Signer, error)
 	GenerateEC384Key() (crypto.Signer, error)
+	GenerateEd25519Key() (crypto.Signer, error)
 }
 
 // Base is the base KeyManager implementation
@@ -121,6 +123,15 @@ func (m *Base) SignData(_ context.Context, req *keymanagerv1.SignDataRequest) (*
 	return resp, prefixStatus(err, "failed to sign data")
 }
 
+// GenerateEd25519Key generates an Ed25519 key with the given ID. If a key
+// with that ID already exists, it is overwritten. The KeyManager plugin
+// interface does not define a key type for Ed25519 keys, so the server calls
+// this method in process instead of the GenerateKey RPC. Once generated, the
+// key is available through the KeyManager RPCs.
+func (m *Base) GenerateEd25519Key(ctx context.Context, id string) error {
+	return prefixStatus(m.generateEd25519Key(ctx, id), "failed to generate key")
+}
+
 func (m *Base) generateKey(ctx context.Context, req *keymanagerv1.GenerateKeyRequest) (*keymanagerv1.GenerateKeyResponse, error) {
 	if req.KeyId == "" {
 		return nil, status.Error(codes.InvalidArgument, "key id is required")
@@ -134,27 +145,53 @@ func (m *Base) generateKey(ctx context.Context, req *keymanagerv1.GenerateKeyReq
 		return nil, err
 	}
 
+	if err := m.setEntry(ctx, newEntry); err != nil {
+		return nil, err
+	}
+
+	return &keymanagerv1.GenerateKeyResponse{
+		PublicKey: clonePublicKey(newEntry.PublicKey),
+	}, nil
+}
+
+func (m *Base) generateEd25519Key(ctx context.Context, id string) error {
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "key id is required")
+	}
+
+	privateKey, err := m.config.Generator.GenerateEd25519Key()
+	if err != nil {
+		return err
+	}
+
+	newEntry, err := makeKeyEntry(id, keymanagerv1.KeyType_UNSPECIFIED_KEY_TYPE, privateKey)
+	if err != nil {
+		return status.Errorf(codes.Internal, "unable to make key entry for new key %q: %v", id, err)
+	}
+
+	return m.setEntry(ctx, newEntry)
+}
+
+// setEntry adds or replaces the entry, and persists the entries.
+func (m *Base) setEntry(ctx context.Context, newEntry *KeyEntry) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	oldEntry, hasEntry := m.entries[req.KeyId]
+	oldEntry, hasEntry := m.entries[newEntry.Id]
 
-	m.entries[req.KeyId] = newEntry
+	m.entries[newEntry.Id] = newEntry
 
 	if m.config.WriteEntries != nil {
 		if err := m.config.WriteEntries(ctx, entriesSliceFromMap(m.entries)); err != nil {
 			if hasEntry {
-				m.entries[req.KeyId] = oldEntry
+				m.entries[newEntry.Id] = oldEntry
 			} else {
-				delete(m.entries, req.KeyId)
+				delete(m.entries, newEntry.Id)
 			}
-			return nil, err
+			return err
 		}
 	}
-
-	return &keymanagerv1.GenerateKeyResponse{
-		PublicKey: clonePublicKey(newEntry.PublicKey),
-	}, nil
+	return nil
 }
 
 func (m *Base) signData(req *keymanagerv1.SignDataRequest) (*keymanagerv1.SignDataResponse, error) {
@@ -165,10 +202,16 @@ func (m *Base) signData(req *keymanagerv1.SignDataRequest) (*keymanagerv1.SignDa
 		return nil, status.Error(codes.InvalidArgument, "signer opts is required")
 	}
 
+	privateKey, fingerprint, ok := m.getPrivateKeyAndFingerprint(req.KeyId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no such key %q", req.KeyId)
+	}
+
 	var signerOpts crypto.SignerOpts
 	switch opts := req.SignerOpts.(type) {
 	case *keymanagerv1.SignDataRequest_HashAlgorithm:
-		if opts.HashAlgorithm == keymanagerv1.HashAlgorithm_UNSPECIFIED_HASH_ALGORITHM {
+		// Ed25519 keys sign the data directly, without hashing.
+		if opts.HashAlgorithm == keymanagerv1.HashAlgorithm_UNSPECIFIED_HASH_ALGORITHM && !signsDataDirectly(privateKey) {
 			return nil, status.Error(codes.InvalidArgument, "hash algorithm is required")
 		}
 		signerOpts = util.MustCast[crypto.Hash](opts.HashAlgorithm)
@@ -187,11 +230,6 @@ func (m *Base) signData(req *keymanagerv1.SignDataRequest) (*keymanagerv1.SignDa
 		return nil, status.Errorf(codes.InvalidArgument, "unsupported signer opts type %T", opts)
 	}
 
-	privateKey, fingerprint, ok := m.getPrivateKeyAndFingerprint(req.KeyId)
-	if !ok {
-		return nil, status.Errorf(codes.NotFound, "no such key %q", req.KeyId)
-	}
-
 	signature, err := privateKey.Sign(rand.Reader, req.Data, signerOpts)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "keypair %q signing operation failed: %v", req.KeyId, err)
@@ -269,6 +307,10 @@ func MakeKeyEntryFromKey(id string, privateKey crypto.PrivateKey) (*KeyEntry, er
 			return nil, fmt.Errorf("unable to make key entry for key %q: %w", id, err)
 		}
 		return makeKeyEntry(id, keyType, privateKey)
+	case ed25519.PrivateKey:
+		// The KeyManager plugin interface does not define a key type for
+		// Ed25519 keys.
+		return makeKeyEntry(id, keymanagerv1.KeyType_UNSPECIFIED_KEY_TYPE, privateKey)
 	default:
 		return nil, fmt.Errorf("unexpected private key type %T for key %q", privateKey, id)
 	}
@@ -298,6 +340,13 @@ func ecdsaKeyType(privateKey *ecdsa.PrivateKey) (keymanagerv1.KeyType, error) {
 	}
 }
 
+// signsDataDirectly returns true if the key signs the data directly instead
+// of a digest of the data.
+func signsDataDirectly(privateKey crypto.Signer) bool {
+	_, ok := privateKey.(ed25519.PrivateKey)
+	return ok
+}
+
 type defaultGenerator struct{}
 
 func (defaultGenerator) GenerateRSA2048Key() (crypto.Signer, error) {
@@ -316,6 +365,11 @@ func (defaultGenerator) GenerateEC384Key() (crypto.Signer, error) {
 	return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
 }
 
+func (defaultGenerator) GenerateEd25519Key() (crypto.Signer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	return privateKey, err
+}
+
 func entriesSliceFromMap(entriesMap map[string]*KeyEntry) (entriesSlice []*KeyEntry) {
 	for _, entry := range entriesMap {
 		entriesSlice = append(entriesSlice, entry)
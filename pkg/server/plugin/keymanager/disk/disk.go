@@ -21,14 +21,14 @@ import (
 type Generator = keymanagerbase.Generator
 
 func BuiltIn() catalog.BuiltIn {
-	return asBuiltIn(newKeyManager(nil))
+	return AsBuiltIn(New(nil))
 }
 
 func TestBuiltIn(generator Generator) catalog.BuiltIn {
-	return asBuiltIn(newKeyManager(generator))
+	return AsBuiltIn(New(generator))
 }
 
-func asBuiltIn(p *KeyManager) catalog.BuiltIn {
+func AsBuiltIn(p *KeyManager) catalog.BuiltIn {
 	return catalog.MakeBuiltIn("disk",
 		keymanagerv1.KeyManagerPluginServer(p),
 		configv1.ConfigServiceServer(p))
@@ -60,7 +60,7 @@ type KeyManager struct {
 	config *configuration
 }
 
-func newKeyManager(generator Generator) *KeyManager {
+func New(generator Generator) *KeyManager {
 	m := &KeyManager{}
 	m.Base = keymanagerbase.New(keymanagerbase.Config{
 		WriteEntries: m.writeEntries,
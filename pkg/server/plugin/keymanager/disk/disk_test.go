@@ -26,6 +26,7 @@ func TestKeyManagerContract(t *testing.T) {
 			require.NoError(t, err)
 			return km
 		},
+		ExtendedKeyTypes: true,
 	})
 }
 
@@ -83,16 +84,17 @@ func TestGenerateKeyPersistence(t *testing.T) {
 }
 
 func loadPlugin(t *testing.T, configFmt string, configArgs ...any) (keymanager.KeyManager, error) {
+	p := disk.New(keymanagertest.NewGenerator())
 	km := new(keymanager.V1)
 	var configErr error
-	plugintest.Load(t, disk.TestBuiltIn(keymanagertest.NewGenerator()), km,
+	plugintest.Load(t, disk.AsBuiltIn(p), km,
 		plugintest.CoreConfig(catalog.CoreConfig{
 			TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
 		}),
 		plugintest.Configuref(configFmt, configArgs...),
 		plugintest.CaptureConfigureError(&configErr),
 	)
-	return km, configErr
+	return keymanager.WithEd25519Keys(km, p), configErr
 }
 
 func mkdir(t *testing.T, dir string) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -45,8 +46,46 @@ const (
 	ECP384
 	RSA2048
 	RSA4096
+
+	// Ed25519 keys are only supported by the built-in key managers (see
+	// Ed25519KeyGenerator).
+	Ed25519
 )
 
+// Ed25519KeyGenerator generates Ed25519 keys in a built-in KeyManager. The
+// KeyManager plugin interface does not define a key type for Ed25519 keys, so
+// they are generated in process rather than through the plugin interface.
+type Ed25519KeyGenerator interface {
+	GenerateEd25519Key(ctx context.Context, id string) error
+}
+
+// WithEd25519Keys returns a KeyManager that generates Ed25519 keys with the
+// generator, which must be the built-in KeyManager backing km. Once
+// generated, Ed25519 keys are retrieved and used through km like any other
+// key.
+func WithEd25519Keys(km KeyManager, generator Ed25519KeyGenerator) KeyManager {
+	return ed25519KeyManager{
+		KeyManager: km,
+		generator:  generator,
+	}
+}
+
+type ed25519KeyManager struct {
+	KeyManager
+
+	generator Ed25519KeyGenerator
+}
+
+func (m ed25519KeyManager) GenerateKey(ctx context.Context, id string, keyType KeyType) (Key, error) {
+	if keyType != Ed25519 {
+		return m.KeyManager.GenerateKey(ctx, id, keyType)
+	}
+	if err := m.generator.GenerateEd25519Key(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.KeyManager.GetKey(ctx, id)
+}
+
 // GenerateSigner generates a new key for the given key type
 func (keyType KeyType) GenerateSigner() (crypto.Signer, error) {
 	switch keyType {
@@ -58,6 +97,9 @@ func (keyType KeyType) GenerateSigner() (crypto.Signer, error) {
 		return rsa.GenerateKey(rand.Reader, 2048)
 	case RSA4096:
 		return rsa.GenerateKey(rand.Reader, 4096)
+	case Ed25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
 	}
 	return nil, fmt.Errorf("unknown key type %q", keyType)
 }
@@ -74,6 +116,8 @@ func (keyType KeyType) String() string {
 		return "rsa-2048"
 	case RSA4096:
 		return "rsa-4096"
+	case Ed25519:
+		return "ed25519"
 	default:
 		return fmt.Sprintf("UNKNOWN(%d)", int(keyType))
 	}
@@ -9,14 +9,14 @@ import (
 type Generator = keymanagerbase.Generator
 
 func BuiltIn() catalog.BuiltIn {
-	return asBuiltIn(newKeyManager(nil))
+	return AsBuiltIn(New(nil))
 }
 
 func TestBuiltIn(generator Generator) catalog.BuiltIn {
-	return asBuiltIn(newKeyManager(generator))
+	return AsBuiltIn(New(generator))
 }
 
-func asBuiltIn(p *KeyManager) catalog.BuiltIn {
+func AsBuiltIn(p *KeyManager) catalog.BuiltIn {
 	return catalog.MakeBuiltIn("memory", keymanagerv1.KeyManagerPluginServer(p))
 }
 
@@ -24,7 +24,7 @@ type KeyManager struct {
 	*keymanagerbase.Base
 }
 
-func newKeyManager(generator Generator) *KeyManager {
+func New(generator Generator) *KeyManager {
 	return &KeyManager{
 		Base: keymanagerbase.New(keymanagerbase.Config{
 			Generator: generator,
@@ -12,9 +12,11 @@ import (
 func TestKeyManagerContract(t *testing.T) {
 	keymanagertest.Test(t, keymanagertest.Config{
 		Create: func(t *testing.T) keymanager.KeyManager {
+			p := memory.New(keymanagertest.NewGenerator())
 			km := new(keymanager.V1)
-			plugintest.Load(t, memory.TestBuiltIn(keymanagertest.NewGenerator()), km)
-			return km
+			plugintest.Load(t, memory.AsBuiltIn(p), km)
+			return keymanager.WithEd25519Keys(km, p)
 		},
+		ExtendedKeyTypes: true,
 	})
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
 	"crypto/x509"
+	"maps"
 	"math/big"
 	"os"
 	"strconv"
@@ -29,6 +31,7 @@ type keyAlgorithm int
 const (
 	keyAlgorithmEC keyAlgorithm = iota
 	keyAlgorithmRSA
+	keyAlgorithmEd25519
 )
 
 var (
@@ -41,6 +44,10 @@ var (
 		keymanager.RSA4096: keyAlgorithmRSA,
 	}
 
+	extendedKeyTypes = map[keymanager.KeyType]keyAlgorithm{
+		keymanager.Ed25519: keyAlgorithmEd25519,
+	}
+
 	expectCurve = map[keymanager.KeyType]elliptic.Curve{
 		keymanager.ECP256: elliptic.P256(),
 		keymanager.ECP384: elliptic.P384(),
@@ -68,6 +75,11 @@ type Config struct {
 	// unsupported for the given key type.
 	UnsupportedSignatureAlgorithms map[keymanager.KeyType][]x509.SignatureAlgorithm
 
+	// ExtendedKeyTypes enables testing of the Ed25519 key type, which is
+	// only supported by the built-in key managers.
+	ExtendedKeyTypes bool
+
+	keyTypes            map[keymanager.KeyType]keyAlgorithm
 	signatureAlgorithms map[keymanager.KeyType][]x509.SignatureAlgorithm
 }
 
@@ -79,12 +91,14 @@ func (config *Config) testKeyWithID(t *testing.T, key keymanager.Key, keyType ke
 	t.Run("id matches", func(t *testing.T) {
 		require.Equal(t, expectID, key.ID())
 	})
-	keyAlgorithm := keyTypes[keyType]
+	keyAlgorithm := config.keyTypes[keyType]
 	switch keyAlgorithm {
 	case keyAlgorithmRSA:
 		assertRSAKey(t, key, expectBits[keyType])
 	case keyAlgorithmEC:
 		assertECKey(t, key, expectCurve[keyType])
+	case keyAlgorithmEd25519:
+		assertEd25519Key(t, key)
 	default:
 		require.Fail(t, "unexpected key algorithm", "key algorithm", keyAlgorithm)
 	}
@@ -124,6 +138,12 @@ func Test(t *testing.T, config Config) {
 		keymanager.RSA4096: rsaAlgorithms,
 	}
 
+	config.keyTypes = maps.Clone(keyTypes)
+	if config.ExtendedKeyTypes {
+		maps.Copy(config.keyTypes, extendedKeyTypes)
+		candidateSignatureAlgorithms[keymanager.Ed25519] = []x509.SignatureAlgorithm{x509.PureEd25519}
+	}
+
 	config.signatureAlgorithms = make(map[keymanager.KeyType][]x509.SignatureAlgorithm)
 	for keyType, signatureAlgorithms := range candidateSignatureAlgorithms {
 		for _, signatureAlgorithm := range signatureAlgorithms {
@@ -149,7 +169,7 @@ func Test(t *testing.T, config Config) {
 func testGenerateKey(t *testing.T, config Config) {
 	km := config.Create(t)
 
-	for keyType := range keyTypes {
+	for keyType := range config.keyTypes {
 		t.Run(keyType.String(), func(t *testing.T) {
 			key := requireGenerateKey(t, km, keyType)
 			config.testKey(t, key, keyType)
@@ -183,7 +203,7 @@ func testGenerateKey(t *testing.T, config Config) {
 func testGetKey(t *testing.T, config Config) {
 	km := config.Create(t)
 
-	for keyType := range keyTypes {
+	for keyType := range config.keyTypes {
 		t.Run(keyType.String(), func(t *testing.T) {
 			requireGenerateKey(t, km, keyType)
 			key := requireGetKey(t, km, keyType.String())
@@ -209,7 +229,7 @@ func testGetKeys(t *testing.T, config Config) {
 		require.Empty(t, requireGetKeys(t, km))
 	})
 
-	for keyType := range keyTypes {
+	for keyType := range config.keyTypes {
 		requireGenerateKey(t, km, keyType)
 	}
 
@@ -218,8 +238,8 @@ func testGetKeys(t *testing.T, config Config) {
 		for _, key := range requireGetKeys(t, km) {
 			keys[key.ID()] = key
 		}
-		require.Len(t, keys, len(keyTypes))
-		for keyType := range keyTypes {
+		require.Len(t, keys, len(config.keyTypes))
+		for keyType := range config.keyTypes {
 			config.testKey(t, keys[keyType.String()], keyType)
 		}
 	})
@@ -261,6 +281,11 @@ func assertRSAKey(t *testing.T, key keymanager.Key, bits int) {
 	require.Equal(t, bits, publicKey.N.BitLen(), "unexpected bits")
 }
 
+func assertEd25519Key(t *testing.T, key keymanager.Key) {
+	_, ok := key.Public().(ed25519.PublicKey)
+	require.True(t, ok, "type %T is not Ed25519 public key", key.Public())
+}
+
 func testSignCertificates(t *testing.T, key keymanager.Key, signatureAlgorithms []x509.SignatureAlgorithm) {
 	for _, signatureAlgorithm := range signatureAlgorithms {
 		t.Run("sign data "+signatureAlgorithm.String(), func(t *testing.T) {
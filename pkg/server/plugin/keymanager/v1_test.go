@@ -92,6 +92,13 @@ func TestV1GenerateKey(t *testing.T) {
 	}
 }
 
+func TestV1GenerateKeyWithEd25519KeyType(t *testing.T) {
+	// The plugin interface does not define a key type for Ed25519 keys
+	km := loadV1Plugin(t, fakeV1Plugin{})
+	_, err := km.GenerateKey(context.Background(), "foo", keymanager.Ed25519)
+	spiretest.RequireGRPCStatus(t, err, codes.Internal, `keymanager(test): facade does not support key type "ed25519"`)
+}
+
 func TestV1GetKey(t *testing.T) {
 	for _, tt := range []struct {
 		test          string
@@ -221,6 +228,9 @@ func TestV1SignData(t *testing.T) {
 	hashAlgorithm := &keymanagerv1.SignDataRequest_HashAlgorithm{
 		HashAlgorithm: keymanagerv1.HashAlgorithm_SHA256,
 	}
+	unspecifiedHashAlgorithm := &keymanagerv1.SignDataRequest_HashAlgorithm{
+		HashAlgorithm: keymanagerv1.HashAlgorithm_UNSPECIFIED_HASH_ALGORITHM,
+	}
 	pssOptions := &keymanagerv1.SignDataRequest_PssOptions{
 		PssOptions: &keymanagerv1.SignDataRequest_PSSOptions{HashAlgorithm: keymanagerv1.HashAlgorithm_SHA384, SaltLength: 123},
 	}
@@ -275,6 +285,14 @@ func TestV1SignData(t *testing.T) {
 			expectSignerOpts: hashAlgorithm,
 			expectCode:       codes.OK,
 		},
+		{
+			test:             "success with unspecified hash algorithm for Ed25519 keys",
+			signerOpts:       crypto.Hash(0),
+			fingerprint:      "foo1",
+			signature:        "SIGNATURE",
+			expectSignerOpts: unspecifiedHashAlgorithm,
+			expectCode:       codes.OK,
+		},
 		{
 			test: "success with PSS options",
 			signerOpts: &rsa.PSSOptions{
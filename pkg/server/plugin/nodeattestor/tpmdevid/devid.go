@@ -41,8 +41,9 @@ func builtin(p *Plugin) catalog.BuiltIn {
 }
 
 type Config struct {
-	DevIDBundlePath       string `hcl:"devid_ca_path"`
-	EndorsementBundlePath string `hcl:"endorsement_ca_path"`
+	DevIDBundlePath       string              `hcl:"devid_ca_path"`
+	EndorsementBundlePath string              `hcl:"endorsement_ca_path"`
+	MeasuredBoot          *MeasuredBootConfig `hcl:"measured_boot"`
 }
 
 type config struct {
@@ -50,6 +51,9 @@ type config struct {
 
 	devIDRoots *x509.CertPool
 	ekRoots    *x509.CertPool
+
+	// measuredBoot is nil if the measured boot state of nodes is not verified.
+	measuredBoot *measuredBootPolicy
 }
 
 func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *config {
@@ -84,6 +88,10 @@ func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginco
 		status.ReportErrorf("unable to load endorsement trust bundle: %v", err)
 	}
 
+	if hclConfig.MeasuredBoot != nil {
+		newConfig.measuredBoot = buildMeasuredBootPolicy(hclConfig.MeasuredBoot, status)
+	}
+
 	return newConfig
 }
 
@@ -162,10 +170,21 @@ func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
 		return err
 	}
 
+	// Issue a quote challenge (to verify the measured boot state of the node)
+	var quoteRequest *common_devid.QuoteRequest
+	if conf.measuredBoot != nil {
+		quoteNonce, err := newNonce(quoteNonceSize)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unable to generate quote nonce: %v", err)
+		}
+		quoteRequest = conf.measuredBoot.quoteRequest(quoteNonce)
+	}
+
 	// Marshal challenges
 	challenge, err := json.Marshal(common_devid.ChallengeRequest{
 		DevID:          devIDChallenge,
 		CredActivation: credActivationChallenge,
+		Quote:          quoteRequest,
 	})
 	if err != nil {
 		return status.Errorf(codes.Internal, "unable to marshal challenges data: %v", err)
@@ -205,12 +224,25 @@ func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
 		return status.Errorf(codes.InvalidArgument, "credential activation failed: %v", err)
 	}
 
+	// Verify the PCR quote. The attestation key is known to reside in the
+	// same TPM as the EK at this point, so the quoted measurements can be
+	// trusted.
+	var measurementSelectors []string
+	if conf.measuredBoot != nil {
+		measurementSelectors, err = conf.measuredBoot.verify(attData.AKPub, quoteRequest, challengeResponse.Quote)
+		if err != nil {
+			st := status.Convert(err)
+			return status.Errorf(st.Code(), "measured boot verification failed: %s", st.Message())
+		}
+	}
+
 	// Create SPIFFE ID and selectors
 	spiffeID, err := idutil.AgentID(conf.trustDomain, fmt.Sprintf("/%s/%s", common_devid.PluginName, Fingerprint(devIDCert)))
 	if err != nil {
 		return status.Errorf(codes.Internal, "failed to create agent ID: %v", err)
 	}
 	selectors := buildSelectorValues(devIDCert, chains)
+	selectors = append(selectors, measurementSelectors...)
 
 	return stream.Send(&nodeattestorv1.AttestResponse{
 		Response: &nodeattestorv1.AttestResponse_AgentAttributes{
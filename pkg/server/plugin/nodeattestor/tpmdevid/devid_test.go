@@ -3,13 +3,18 @@
 package tpmdevid_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/go-tpm/legacy/tpm2"
@@ -127,6 +132,68 @@ func TestConfigure(t *testing.T) {
 								endorsement_ca_path = "non-existent/endorsement/bundle/path"`,
 				devIDBundlePath),
 		},
+		{
+			name:     "Configure fails if measured boot PCR bank is not supported",
+			expErr:   `rpc error: code = InvalidArgument desc = invalid measured_boot.pcr_bank: unsupported PCR bank "md5"`,
+			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+			hclConf: fmt.Sprintf(`devid_ca_path = %q
+								endorsement_ca_path = %q
+								measured_boot {
+									pcr_bank = "md5"
+								}`,
+				devIDBundlePath,
+				endorsementBundlePath),
+		},
+		{
+			name:     "Configure fails if measured boot policy is empty",
+			expErr:   "rpc error: code = InvalidArgument desc = measured_boot requires at least one of pcr_values, bootloader_digests or kernel_digests",
+			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+			hclConf: fmt.Sprintf(`devid_ca_path = %q
+								endorsement_ca_path = %q
+								measured_boot {}`,
+				devIDBundlePath,
+				endorsementBundlePath),
+		},
+		{
+			name:     "Configure fails if measured boot PCR index is invalid",
+			expErr:   `rpc error: code = InvalidArgument desc = invalid measured_boot.pcr_values: PCR index "24" must be between 0 and 23`,
+			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+			hclConf: fmt.Sprintf(`devid_ca_path = %q
+								endorsement_ca_path = %q
+								measured_boot {
+									pcr_values = { "24" = [%q] }
+								}`,
+				devIDBundlePath,
+				endorsementBundlePath,
+				strings.Repeat("00", 32)),
+		},
+		{
+			name:     "Configure fails if measured boot digest does not match the PCR bank",
+			expErr:   `rpc error: code = InvalidArgument desc = invalid measured_boot.kernel_digests: "0011" is not a hex encoded sha256 digest`,
+			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+			hclConf: fmt.Sprintf(`devid_ca_path = %q
+								endorsement_ca_path = %q
+								measured_boot {
+									kernel_digests = ["0011"]
+								}`,
+				devIDBundlePath,
+				endorsementBundlePath),
+		},
+		{
+			name:     "Configure succeeds with measured boot",
+			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
+			hclConf: fmt.Sprintf(`devid_ca_path = %q
+								endorsement_ca_path = %q
+								measured_boot {
+									pcr_bank = "sha1"
+									pcr_values = { "7" = [%q] }
+									kernel_digests = [%q]
+								}`,
+				devIDBundlePath,
+				endorsementBundlePath,
+				strings.Repeat("00", 20),
+				strings.Repeat("AB", 20)),
+		},
 		{
 			name:     "Configure succeeds",
 			coreConf: &configv1.CoreConfiguration{TrustDomain: "example.org"},
@@ -676,6 +743,233 @@ func TestAttestSucceeds(t *testing.T) {
 	}
 }
 
+func TestAttestMeasuredBoot(t *testing.T) {
+	devicePath := "/dev/tpmrm0"
+	if isWindows {
+		devicePath = ""
+	}
+
+	provisioningCA, err := tpmsimulator.NewProvisioningCA(&tpmsimulator.ProvisioningConf{})
+	require.NoError(t, err)
+	sim := setupSimulator(t, provisioningCA)
+
+	devID, err := sim.GenerateDevID(provisioningCA, tpmsimulator.RSA, tpmPasswords.DevIDKey)
+	require.NoError(t, err)
+
+	// Measure a boot chain made of shim, GRUB and the kernel, along with the
+	// Secure Boot configuration
+	const (
+		evSeparator                  = 0x00000004
+		evEFIVariableDriverConfig    = 0x80000001
+		evEFIBootServicesApplication = 0x80000003
+	)
+	require.NoError(t, sim.MeasureEvent(7, evEFIVariableDriverConfig, []byte("SecureBoot=1")))
+	require.NoError(t, sim.MeasureEvent(4, evSeparator, []byte{0, 0, 0, 0}))
+	require.NoError(t, sim.MeasureEvent(4, evEFIBootServicesApplication, []byte("shim")))
+	require.NoError(t, sim.MeasureEvent(4, evEFIBootServicesApplication, []byte("grub")))
+	require.NoError(t, sim.MeasureEvent(4, evEFIBootServicesApplication, []byte("kernel")))
+	eventLog := sim.EventLog()
+
+	pcr7, err := tpm2.ReadPCR(sim, 7, tpm2.AlgSHA256)
+	require.NoError(t, err)
+	pcr7SHA1, err := tpm2.ReadPCR(sim, 7, tpm2.AlgSHA1)
+	require.NoError(t, err)
+	shimDigest := sha256Hex("shim")
+	grubDigest := sha256Hex("grub")
+	kernelDigest := sha256Hex("kernel")
+
+	session, err := tpmutil.NewSession(&tpmutil.SessionConfig{
+		DevicePath: devicePath,
+		DevIDPriv:  devID.PrivateBlob,
+		DevIDPub:   devID.PublicBlob,
+		Passwords:  tpmPasswords,
+		Log:        hclog.NewNullLogger(),
+	})
+	require.NoError(t, err)
+	defer session.Close()
+
+	ekCert, err := session.GetEKCert()
+	require.NoError(t, err)
+	ekPub, err := session.GetEKPublic()
+	require.NoError(t, err)
+	certifiedDevID, signature, err := session.CertifyDevIDKey()
+	require.NoError(t, err)
+
+	payload := marshalPayload(t, &common_devid.AttestationRequest{
+		DevIDCert:              devID.Chain(),
+		DevIDPub:               devID.PublicBlob,
+		EKCert:                 ekCert,
+		EKPub:                  ekPub,
+		AKPub:                  session.GetAKPublic(),
+		CertifiedDevID:         certifiedDevID,
+		CertificationSignature: signature,
+	})
+
+	// solveChallenges solves the challenges sent by the server, allowing to
+	// tamper with the quote response.
+	solveChallenges := func(modifyQuote func(*common_devid.QuoteResponse) *common_devid.QuoteResponse) func(ctx context.Context, challenge []byte) ([]byte, error) {
+		return func(ctx context.Context, challenge []byte) ([]byte, error) {
+			var req common_devid.ChallengeRequest
+			require.NoError(t, json.Unmarshal(challenge, &req))
+			require.NotNil(t, req.Quote)
+
+			devIDChallengeResponse, err := session.SolveDevIDChallenge(req.DevID)
+			require.NoError(t, err)
+			credActChallengeResponse, err := session.SolveCredActivationChallenge(
+				req.CredActivation.Credential,
+				req.CredActivation.Secret)
+			require.NoError(t, err)
+
+			bank, err := common_devid.PCRBankAlgorithm(req.Quote.PCRBank)
+			require.NoError(t, err)
+			pcrs, quote, quoteSignature, err := session.QuotePCRs(bank, req.Quote.PCRs, req.Quote.Nonce)
+			require.NoError(t, err)
+			quoteResponse := &common_devid.QuoteResponse{
+				Quote:     quote,
+				Signature: quoteSignature,
+				PCRs:      pcrs,
+			}
+			if req.Quote.EventLog {
+				quoteResponse.EventLog = eventLog
+			}
+
+			return json.Marshal(common_devid.ChallengeResponse{
+				DevID:          devIDChallengeResponse,
+				CredActivation: credActChallengeResponse,
+				Quote:          modifyQuote(quoteResponse),
+			})
+		}
+	}
+	noModification := func(resp *common_devid.QuoteResponse) *common_devid.QuoteResponse {
+		return resp
+	}
+
+	devIDSelectors := []*common.Selector{
+		{Type: "tpm_devid", Value: "subject:cn:devid-leaf"},
+		{Type: "tpm_devid", Value: "issuer:cn:intermediate"},
+		{Type: "tpm_devid", Value: "ca:fingerprint:" + tpmdevid.Fingerprint(devID.Intermediates[0])},
+		{Type: "tpm_devid", Value: "ca:fingerprint:" + tpmdevid.Fingerprint(provisioningCA.RootCert)},
+	}
+
+	tests := []struct {
+		name              string
+		measuredBoot      string
+		modifyQuote       func(*common_devid.QuoteResponse) *common_devid.QuoteResponse
+		expErr            string
+		expectedSelectors []*common.Selector
+	}{
+		{
+			name:         "Attest succeeds with allowed PCR values",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q, %q] }`, strings.Repeat("00", 32), hex.EncodeToString(pcr7)),
+			modifyQuote:  noModification,
+			expectedSelectors: append(slices.Clone(devIDSelectors),
+				&common.Selector{Type: "tpm_devid", Value: "pcr:sha256:7:" + hex.EncodeToString(pcr7)},
+			),
+		},
+		{
+			name:         "Attest succeeds with allowed PCR values of the SHA-1 bank",
+			measuredBoot: fmt.Sprintf(`pcr_bank = "sha1", pcr_values = { "7" = [%q] }`, hex.EncodeToString(pcr7SHA1)),
+			modifyQuote:  noModification,
+			expectedSelectors: append(slices.Clone(devIDSelectors),
+				&common.Selector{Type: "tpm_devid", Value: "pcr:sha1:7:" + hex.EncodeToString(pcr7SHA1)},
+			),
+		},
+		{
+			name: "Attest succeeds with allowed bootloader and kernel digests",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q] }, bootloader_digests = [%q, %q], kernel_digests = [%q]`,
+				hex.EncodeToString(pcr7), shimDigest, grubDigest, kernelDigest),
+			modifyQuote: noModification,
+			expectedSelectors: append(slices.Clone(devIDSelectors),
+				&common.Selector{Type: "tpm_devid", Value: "pcr:sha256:7:" + hex.EncodeToString(pcr7)},
+				&common.Selector{Type: "tpm_devid", Value: "bootloader:digest:" + shimDigest},
+				&common.Selector{Type: "tpm_devid", Value: "bootloader:digest:" + grubDigest},
+				&common.Selector{Type: "tpm_devid", Value: "kernel:digest:" + kernelDigest},
+			),
+		},
+		{
+			name:         "Attest fails if PCR value is not allowed",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q] }`, strings.Repeat("00", 32)),
+			modifyQuote:  noModification,
+			expErr:       fmt.Sprintf("rpc error: code = PermissionDenied desc = nodeattestor(tpm_devid): measured boot verification failed: value %s of PCR 7 is not allowed", hex.EncodeToString(pcr7)),
+		},
+		{
+			name:         "Attest fails if bootloader digest is not allowed",
+			measuredBoot: fmt.Sprintf(`bootloader_digests = [%q]`, shimDigest),
+			modifyQuote:  noModification,
+			expErr:       fmt.Sprintf("rpc error: code = PermissionDenied desc = nodeattestor(tpm_devid): measured boot verification failed: bootloader digest %s is not allowed", grubDigest),
+		},
+		{
+			name:         "Attest fails if kernel digest is not allowed",
+			measuredBoot: fmt.Sprintf(`kernel_digests = [%q]`, grubDigest),
+			modifyQuote:  noModification,
+			expErr:       fmt.Sprintf("rpc error: code = PermissionDenied desc = nodeattestor(tpm_devid): measured boot verification failed: kernel digest %s is not allowed", kernelDigest),
+		},
+		{
+			name:         "Attest fails if agent does not send a quote",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q] }`, hex.EncodeToString(pcr7)),
+			modifyQuote: func(*common_devid.QuoteResponse) *common_devid.QuoteResponse {
+				return nil
+			},
+			expErr: "rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): measured boot verification failed: missing PCR quote",
+		},
+		{
+			name:         "Attest fails if agent sends a PCR value that was not quoted",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q] }`, strings.Repeat("00", 32)),
+			modifyQuote: func(resp *common_devid.QuoteResponse) *common_devid.QuoteResponse {
+				resp.PCRs[7] = make([]byte, 32)
+				return resp
+			},
+			expErr: "rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): measured boot verification failed: invalid PCR quote: quote digest didn't match pcrs provided",
+		},
+		{
+			name:         "Attest fails if agent does not send all the PCR values",
+			measuredBoot: fmt.Sprintf(`pcr_values = { "7" = [%q] }`, hex.EncodeToString(pcr7)),
+			modifyQuote: func(resp *common_devid.QuoteResponse) *common_devid.QuoteResponse {
+				delete(resp.PCRs, 7)
+				return resp
+			},
+			expErr: "rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): measured boot verification failed: missing value for PCR 7",
+		},
+		{
+			name:         "Attest fails if agent does not send the event log",
+			measuredBoot: fmt.Sprintf(`kernel_digests = [%q]`, kernelDigest),
+			modifyQuote: func(resp *common_devid.QuoteResponse) *common_devid.QuoteResponse {
+				resp.EventLog = nil
+				return resp
+			},
+			expErr: "rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): measured boot verification failed: missing event log",
+		},
+		{
+			name:         "Attest fails if event log does not match the quoted PCRs",
+			measuredBoot: fmt.Sprintf(`kernel_digests = [%q]`, sha256Hex("allowed-kernel")),
+			modifyQuote: func(resp *common_devid.QuoteResponse) *common_devid.QuoteResponse {
+				// Claim that an allowed kernel was booted
+				booted, _ := hex.DecodeString(kernelDigest)
+				claimed, _ := hex.DecodeString(sha256Hex("allowed-kernel"))
+				resp.EventLog = bytes.Replace(resp.EventLog, booted, claimed, 1)
+				return resp
+			},
+			expErr: "rpc error: code = InvalidArgument desc = nodeattestor(tpm_devid): measured boot verification failed: event log does not match the quoted PCRs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := loadPlugin(t, fmt.Sprintf(`devid_ca_path = %q, endorsement_ca_path = %q, measured_boot { %s }`,
+				devIDBundlePath, endorsementBundlePath, tt.measuredBoot))
+
+			result, err := plugin.Attest(context.Background(), payload, solveChallenges(tt.modifyQuote))
+			if tt.expErr != "" {
+				require.ErrorContains(t, err, tt.expErr)
+				require.Nil(t, result)
+				return
+			}
+			require.NoError(t, err)
+			requireSelectorsMatch(t, tt.expectedSelectors, result.Selectors)
+		})
+	}
+}
+
 func loadPlugin(t *testing.T, config string) nodeattestor.NodeAttestor {
 	v1 := new(nodeattestor.V1)
 	plugintest.Load(t, tpmdevid.BuiltIn(), v1,
@@ -693,6 +987,11 @@ func marshalPayload(t *testing.T, attReq *common_devid.AttestationRequest) []byt
 	return attReqBytes
 }
 
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
 func requireSelectorsMatch(t *testing.T, expected []*common.Selector, actual []*common.Selector) {
 	require.Equal(t, len(expected), len(actual))
 	for idx, expSel := range expected {
@@ -0,0 +1,258 @@
+package tpmdevid
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/google/go-attestation/attest"
+	common_devid "github.com/spiffe/spire/pkg/common/plugin/tpmdevid"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// defaultPCRBank is the PCR bank quoted when none is configured.
+	defaultPCRBank = "sha256"
+
+	// bootApplicationsPCR is the PCR where the UEFI boot manager measures the
+	// applications it starts (TCG PC Client Platform Firmware Profile,
+	// section 3.3.4.5).
+	bootApplicationsPCR = 4
+
+	// evEFIBootServicesApplication is the type of the events that measure
+	// the UEFI applications loaded during boot, such as shim, GRUB or a kernel
+	// with an EFI stub.
+	evEFIBootServicesApplication = 0x80000003
+
+	// We use a 32 bytes nonce to be consistent with the DevID challenge.
+	quoteNonceSize = 32
+
+	maxPCRIndex = 23
+)
+
+type MeasuredBootConfig struct {
+	PCRBank           string              `hcl:"pcr_bank"`
+	PCRValues         map[string][]string `hcl:"pcr_values"`
+	BootloaderDigests []string            `hcl:"bootloader_digests"`
+	KernelDigests     []string            `hcl:"kernel_digests"`
+}
+
+// measuredBootPolicy holds the measurements a node is allowed to have booted
+// with. Digests are kept hex encoded.
+type measuredBootPolicy struct {
+	pcrBank           string
+	hash              crypto.Hash
+	pcrValues         map[int]map[string]bool
+	bootloaderDigests map[string]bool
+	kernelDigests     map[string]bool
+}
+
+func buildMeasuredBootPolicy(c *MeasuredBootConfig, status *pluginconf.Status) *measuredBootPolicy {
+	policy := &measuredBootPolicy{
+		pcrBank:   c.PCRBank,
+		pcrValues: make(map[int]map[string]bool),
+	}
+	if policy.pcrBank == "" {
+		policy.pcrBank = defaultPCRBank
+	}
+
+	alg, err := common_devid.PCRBankAlgorithm(policy.pcrBank)
+	if err != nil {
+		status.ReportErrorf("invalid measured_boot.pcr_bank: %v", err)
+		return nil
+	}
+	policy.hash, err = alg.Hash()
+	if err != nil {
+		status.ReportErrorf("invalid measured_boot.pcr_bank: %v", err)
+		return nil
+	}
+
+	if len(c.PCRValues) == 0 && len(c.BootloaderDigests) == 0 && len(c.KernelDigests) == 0 {
+		status.ReportError("measured_boot requires at least one of pcr_values, bootloader_digests or kernel_digests")
+		return nil
+	}
+
+	for key, values := range c.PCRValues {
+		index, err := strconv.Atoi(key)
+		if err != nil || index < 0 || index > maxPCRIndex {
+			status.ReportErrorf("invalid measured_boot.pcr_values: PCR index %q must be between 0 and %d", key, maxPCRIndex)
+			continue
+		}
+		if len(values) == 0 {
+			status.ReportErrorf("invalid measured_boot.pcr_values: no allowed values for PCR %d", index)
+			continue
+		}
+		policy.pcrValues[index] = policy.parseDigests(fmt.Sprintf("pcr_values[%d]", index), values, status)
+	}
+	if len(c.BootloaderDigests) > 0 {
+		policy.bootloaderDigests = policy.parseDigests("bootloader_digests", c.BootloaderDigests, status)
+	}
+	if len(c.KernelDigests) > 0 {
+		policy.kernelDigests = policy.parseDigests("kernel_digests", c.KernelDigests, status)
+	}
+
+	return policy
+}
+
+func (p *measuredBootPolicy) parseDigests(name string, values []string, status *pluginconf.Status) map[string]bool {
+	digests := make(map[string]bool, len(values))
+	for _, value := range values {
+		digest, err := hex.DecodeString(value)
+		if err != nil || len(digest) != p.hash.Size() {
+			status.ReportErrorf("invalid measured_boot.%s: %q is not a hex encoded %s digest", name, value, p.pcrBank)
+			continue
+		}
+		digests[hex.EncodeToString(digest)] = true
+	}
+	return digests
+}
+
+// checksBootApplications returns true if the policy checks the boot
+// applications recorded in the event log.
+func (p *measuredBootPolicy) checksBootApplications() bool {
+	return len(p.bootloaderDigests) > 0 || len(p.kernelDigests) > 0
+}
+
+// quoteRequest builds the request for a quote over the PCRs needed to verify
+// the policy.
+func (p *measuredBootPolicy) quoteRequest(nonce []byte) *common_devid.QuoteRequest {
+	pcrs := slices.Collect(maps.Keys(p.pcrValues))
+	if p.checksBootApplications() && !slices.Contains(pcrs, bootApplicationsPCR) {
+		pcrs = append(pcrs, bootApplicationsPCR)
+	}
+	slices.Sort(pcrs)
+
+	return &common_devid.QuoteRequest{
+		Nonce:    nonce,
+		PCRBank:  p.pcrBank,
+		PCRs:     pcrs,
+		EventLog: p.checksBootApplications(),
+	}
+}
+
+// verify checks that the quote was signed by the attestation key over the
+// requested PCRs and nonce, and that the measurements satisfy the policy.
+// It returns the selector values for the verified measurements.
+func (p *measuredBootPolicy) verify(akPubBlob []byte, req *common_devid.QuoteRequest, resp *common_devid.QuoteResponse) ([]string, error) {
+	if resp == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing PCR quote; the agent may not support measured boot attestation")
+	}
+
+	akPub, err := attest.ParseAKPublic(attest.TPMVersion20, akPubBlob)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot parse attestation key public blob: %v", err)
+	}
+
+	pcrs := make([]attest.PCR, 0, len(resp.PCRs))
+	for _, index := range req.PCRs {
+		digest, ok := resp.PCRs[index]
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "missing value for PCR %d", index)
+		}
+		pcrs = append(pcrs, attest.PCR{
+			Index:     index,
+			Digest:    digest,
+			DigestAlg: p.hash,
+		})
+	}
+
+	err = akPub.Verify(attest.Quote{
+		Version:   attest.TPMVersion20,
+		Quote:     resp.Quote,
+		Signature: resp.Signature,
+	}, pcrs, req.Nonce)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid PCR quote: %v", err)
+	}
+	// The quote may have been made over a different bank, in which case the
+	// provided values are not covered by it.
+	for _, pcr := range pcrs {
+		if !pcr.QuoteVerified() {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid PCR quote: PCR %d is not covered by the quote", pcr.Index)
+		}
+	}
+
+	var selectorValues []string
+	for _, pcr := range pcrs {
+		allowed, ok := p.pcrValues[pcr.Index]
+		if !ok {
+			continue
+		}
+		value := hex.EncodeToString(pcr.Digest)
+		if !allowed[value] {
+			return nil, status.Errorf(codes.PermissionDenied, "value %s of PCR %d is not allowed", value, pcr.Index)
+		}
+		selectorValues = append(selectorValues, fmt.Sprintf("pcr:%s:%d:%s", p.pcrBank, pcr.Index, value))
+	}
+
+	if !p.checksBootApplications() {
+		return selectorValues, nil
+	}
+
+	bootloaders, kernel, err := p.bootApplications(resp.EventLog, pcrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.bootloaderDigests) > 0 && len(bootloaders) == 0 {
+		return nil, status.Error(codes.PermissionDenied, "event log does not contain bootloader measurements")
+	}
+	for _, bootloader := range bootloaders {
+		if len(p.bootloaderDigests) > 0 && !p.bootloaderDigests[bootloader] {
+			return nil, status.Errorf(codes.PermissionDenied, "bootloader digest %s is not allowed", bootloader)
+		}
+		if selectorValue := "bootloader:digest:" + bootloader; !slices.Contains(selectorValues, selectorValue) {
+			selectorValues = append(selectorValues, selectorValue)
+		}
+	}
+
+	if len(p.kernelDigests) > 0 && !p.kernelDigests[kernel] {
+		return nil, status.Errorf(codes.PermissionDenied, "kernel digest %s is not allowed", kernel)
+	}
+	selectorValues = append(selectorValues, "kernel:digest:"+kernel)
+
+	return selectorValues, nil
+}
+
+// bootApplications replays the event log against the quoted value of PCR 4
+// and returns the digests of the UEFI applications started during boot. The
+// last application started is considered to be the kernel, and the ones
+// before it the bootloader chain.
+func (p *measuredBootPolicy) bootApplications(eventLog []byte, pcrs []attest.PCR) ([]string, string, error) {
+	if len(eventLog) == 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "missing event log")
+	}
+
+	el, err := attest.ParseEventLog(eventLog)
+	if err != nil {
+		return nil, "", status.Errorf(codes.InvalidArgument, "cannot parse event log: %v", err)
+	}
+
+	// Only the events of PCR 4 are replayed, so the policy does not depend
+	// on the other PCRs being fully described by the event log.
+	i := slices.IndexFunc(pcrs, func(pcr attest.PCR) bool {
+		return pcr.Index == bootApplicationsPCR
+	})
+	events, err := el.Verify(pcrs[i : i+1])
+	if err != nil {
+		return nil, "", status.Errorf(codes.InvalidArgument, "event log does not match the quoted PCRs: %v", err)
+	}
+
+	var applications []string
+	for _, event := range events {
+		if event.Index == bootApplicationsPCR && event.Type == evEFIBootServicesApplication {
+			applications = append(applications, hex.EncodeToString(event.Digest))
+		}
+	}
+	if len(applications) == 0 {
+		return nil, "", status.Error(codes.PermissionDenied, "event log does not contain boot application measurements")
+	}
+
+	last := len(applications) - 1
+	return applications[:last], applications[last], nil
+}
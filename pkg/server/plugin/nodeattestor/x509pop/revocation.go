@@ -0,0 +1,371 @@
+package x509pop
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/hashicorp/go-hclog"
+	metricsv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/common/metrics/v1"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	defaultCRLRefreshInterval = time.Hour
+	revocationRequestTimeout  = 10 * time.Second
+
+	// maxRevocationResponseSize limits the size of the CRLs and OCSP
+	// responses fetched over HTTP.
+	maxRevocationResponseSize = 32 << 20
+)
+
+// RevocationConfig configures revocation checking of agent certificates.
+type RevocationConfig struct {
+	// CRLPaths are paths to PEM or DER encoded CRLs.
+	CRLPaths []string `hcl:"crl_paths"`
+
+	// CRLDistributionPoints enables fetching CRLs from the HTTP distribution
+	// points listed in the agent certificate.
+	CRLDistributionPoints bool `hcl:"crl_distribution_points"`
+
+	// CRLRefreshInterval is how often CRLs are reloaded. CRLs are also
+	// reloaded once they reach their next update time.
+	CRLRefreshInterval string `hcl:"crl_refresh_interval"`
+
+	// OCSP enables querying the OCSP responders listed in the agent
+	// certificate when the agent does not staple an OCSP response.
+	OCSP bool `hcl:"ocsp"`
+
+	// FailOpen allows attestation when the revocation status of the agent
+	// certificate cannot be determined.
+	FailOpen bool `hcl:"fail_open"`
+}
+
+type revocationStatus string
+
+const (
+	revocationStatusGood    revocationStatus = "good"
+	revocationStatusRevoked revocationStatus = "revoked"
+	revocationStatusUnknown revocationStatus = "unknown"
+)
+
+// revocationSource is where the revocation status was obtained from.
+type revocationSource string
+
+const (
+	revocationSourceNone       revocationSource = "none"
+	revocationSourceOCSPStaple revocationSource = "ocsp_staple"
+	revocationSourceCRL        revocationSource = "crl"
+	revocationSourceOCSP       revocationSource = "ocsp"
+)
+
+// revocationChecker checks the revocation status of agent certificates using
+// stapled OCSP responses, CRLs and OCSP responders, in that order.
+type revocationChecker struct {
+	clk             clock.Clock
+	httpClient      *http.Client
+	crlPaths        []string
+	useCRLDPs       bool
+	useOCSP         bool
+	failOpen        bool
+	refreshInterval time.Duration
+
+	mu   sync.Mutex
+	crls map[string]*cachedCRL
+}
+
+type cachedCRL struct {
+	crl      *x509.RevocationList
+	loadedAt time.Time
+}
+
+func buildRevocationChecker(config *RevocationConfig, status *pluginconf.Status) *revocationChecker {
+	refreshInterval := defaultCRLRefreshInterval
+	if config.CRLRefreshInterval != "" {
+		var err error
+		refreshInterval, err = time.ParseDuration(config.CRLRefreshInterval)
+		if err != nil {
+			status.ReportErrorf("invalid crl_refresh_interval: %v", err)
+		} else if refreshInterval <= 0 {
+			status.ReportError("crl_refresh_interval must be positive")
+		}
+	}
+
+	if len(config.CRLPaths) == 0 && !config.CRLDistributionPoints && !config.OCSP {
+		status.ReportError("revocation checking requires at least one of crl_paths, crl_distribution_points or ocsp")
+	}
+	if config.FailOpen {
+		status.ReportInfo("revocation checking fails open; agents whose certificate revocation status cannot be determined will be allowed to attest")
+	}
+
+	checker := &revocationChecker{
+		clk:             clock.New(),
+		httpClient:      &http.Client{Timeout: revocationRequestTimeout},
+		crlPaths:        config.CRLPaths,
+		useCRLDPs:       config.CRLDistributionPoints,
+		useOCSP:         config.OCSP,
+		failOpen:        config.FailOpen,
+		refreshInterval: refreshInterval,
+		crls:            make(map[string]*cachedCRL),
+	}
+
+	// Load the CRL files up front so that configuration errors surface
+	// when the plugin is configured rather than on the first attestation.
+	for _, path := range config.CRLPaths {
+		crl, err := loadCRLFile(path)
+		if err != nil {
+			status.ReportErrorf("unable to load CRL %q: %v", path, err)
+			continue
+		}
+		checker.crls[path] = &cachedCRL{crl: crl, loadedAt: checker.clk.Now()}
+	}
+
+	return checker
+}
+
+// Check returns an error if the certificate, issued by issuer, is
+// revoked or, when failing closed, if its revocation status cannot be
+// determined.
+func (c *revocationChecker) Check(ctx context.Context, log hclog.Logger, metrics metricsv1.MetricsClient, cert, issuer *x509.Certificate, staple []byte) error {
+	status, source, err := c.status(ctx, log, cert, issuer, staple)
+	emitRevocationCheck(ctx, log, metrics, status, source)
+
+	switch status {
+	case revocationStatusGood:
+		return nil
+	case revocationStatusRevoked:
+		return fmt.Errorf("certificate %s is revoked (source: %s)", serialNumberString(cert), source)
+	}
+
+	if err == nil {
+		err = errors.New("no revocation information available")
+	}
+	if c.failOpen {
+		log.Warn("Unable to determine certificate revocation status; allowing attestation since revocation checking fails open", "serial_number", serialNumberString(cert), "error", err)
+		return nil
+	}
+	return fmt.Errorf("unable to determine revocation status of certificate %s: %w", serialNumberString(cert), err)
+}
+
+func (c *revocationChecker) status(ctx context.Context, log hclog.Logger, cert, issuer *x509.Certificate, staple []byte) (revocationStatus, revocationSource, error) {
+	var errs []error
+	if len(staple) > 0 {
+		status, err := c.ocspStatus(staple, cert, issuer)
+		if err == nil {
+			return status, revocationSourceOCSPStaple, nil
+		}
+		errs = append(errs, fmt.Errorf("invalid stapled OCSP response: %w", err))
+	}
+
+	crlsFound := false
+	for _, crl := range c.crlsFor(ctx, log, cert, issuer) {
+		crlsFound = true
+		for _, revoked := range crl.RevokedCertificateEntries {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return revocationStatusRevoked, revocationSourceCRL, nil
+			}
+		}
+	}
+	if crlsFound {
+		return revocationStatusGood, revocationSourceCRL, nil
+	}
+
+	if c.useOCSP {
+		for _, server := range cert.OCSPServer {
+			status, err := c.queryOCSP(ctx, server, cert, issuer)
+			if err == nil {
+				return status, revocationSourceOCSP, nil
+			}
+			errs = append(errs, fmt.Errorf("OCSP responder %q: %w", server, err))
+		}
+	}
+
+	return revocationStatusUnknown, revocationSourceNone, errors.Join(errs...)
+}
+
+// crlsFor returns the current CRLs issued by the issuer of the certificate.
+func (c *revocationChecker) crlsFor(ctx context.Context, log hclog.Logger, cert, issuer *x509.Certificate) []*x509.RevocationList {
+	sources := slices.Clone(c.crlPaths)
+	if c.useCRLDPs {
+		for _, dp := range cert.CRLDistributionPoints {
+			if isHTTPURL(dp) {
+				sources = append(sources, dp)
+			}
+		}
+	}
+
+	var crls []*x509.RevocationList
+	for _, source := range sources {
+		crl, err := c.getCRL(ctx, source)
+		if err != nil {
+			log.Warn("Unable to load CRL", "source", source, "error", err)
+		}
+		if crl == nil || !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			log.Warn("Ignoring CRL with invalid signature", "source", source, "error", err)
+			continue
+		}
+		if !crl.NextUpdate.IsZero() && c.clk.Now().After(crl.NextUpdate) {
+			log.Warn("Ignoring expired CRL", "source", source, "next_update", crl.NextUpdate)
+			continue
+		}
+		crls = append(crls, crl)
+	}
+	return crls
+}
+
+// getCRL returns the CRL for the source, reloading it when the refresh
+// interval has elapsed or the CRL has reached its next update time. If the
+// reload fails, the previously loaded CRL, if any, is returned along with the
+// error.
+func (c *revocationChecker) getCRL(ctx context.Context, source string) (*x509.RevocationList, error) {
+	c.mu.Lock()
+	cached := c.crls[source]
+	c.mu.Unlock()
+
+	now := c.clk.Now()
+	if cached != nil && now.Sub(cached.loadedAt) < c.refreshInterval &&
+		(cached.crl.NextUpdate.IsZero() || now.Before(cached.crl.NextUpdate)) {
+		return cached.crl, nil
+	}
+
+	var crl *x509.RevocationList
+	var err error
+	if isHTTPURL(source) {
+		crl, err = c.fetchCRL(ctx, source)
+	} else {
+		crl, err = loadCRLFile(source)
+	}
+	if err != nil {
+		if cached != nil {
+			return cached.crl, err
+		}
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.crls[source] = &cachedCRL{crl: crl, loadedAt: now}
+	c.mu.Unlock()
+	return crl, nil
+}
+
+func (c *revocationChecker) fetchCRL(ctx context.Context, url string) (*x509.RevocationList, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return parseCRL(body)
+}
+
+func (c *revocationChecker) queryOCSP(ctx context.Context, server string, cert, issuer *x509.Certificate) (revocationStatus, error) {
+	ocspReq, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return revocationStatusUnknown, fmt.Errorf("unable to create request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server, bytes.NewReader(ocspReq))
+	if err != nil {
+		return revocationStatusUnknown, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	body, err := c.do(req)
+	if err != nil {
+		return revocationStatusUnknown, err
+	}
+	return c.ocspStatus(body, cert, issuer)
+}
+
+func (c *revocationChecker) ocspStatus(der []byte, cert, issuer *x509.Certificate) (revocationStatus, error) {
+	resp, err := ocsp.ParseResponseForCert(der, cert, issuer)
+	if err != nil {
+		return revocationStatusUnknown, err
+	}
+	now := c.clk.Now()
+	if now.Before(resp.ThisUpdate) {
+		return revocationStatusUnknown, errors.New("response is not yet valid")
+	}
+	if !resp.NextUpdate.IsZero() && now.After(resp.NextUpdate) {
+		return revocationStatusUnknown, errors.New("response has expired")
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return revocationStatusGood, nil
+	case ocsp.Revoked:
+		return revocationStatusRevoked, nil
+	default:
+		return revocationStatusUnknown, errors.New("responder does not know the certificate")
+	}
+}
+
+func (c *revocationChecker) do(req *http.Request) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxRevocationResponseSize))
+}
+
+func loadCRLFile(path string) (*x509.RevocationList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseCRL(data)
+}
+
+// parseCRL parses a PEM or DER encoded CRL.
+func parseCRL(data []byte) (*x509.RevocationList, error) {
+	if block, _ := pem.Decode(data); block != nil && block.Type == "X509 CRL" {
+		data = block.Bytes
+	}
+	return x509.ParseRevocationList(data)
+}
+
+func emitRevocationCheck(ctx context.Context, log hclog.Logger, metrics metricsv1.MetricsClient, status revocationStatus, source revocationSource) {
+	if metrics == nil {
+		return
+	}
+	if _, err := metrics.IncrCounter(ctx, &metricsv1.IncrCounterRequest{
+		Key: []string{"x509pop", "revocation_check"},
+		Val: 1,
+		Labels: []*metricsv1.Label{
+			{Name: "status", Value: string(status)},
+			{Name: "source", Value: string(source)},
+		},
+	}); err != nil {
+		log.Debug("Failed to emit revocation check metric", "error", err)
+	}
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func serialNumberString(cert *x509.Certificate) string {
+	if cert.SerialNumber == nil {
+		return "<none>"
+	}
+	return cert.SerialNumber.Text(16)
+}
@@ -0,0 +1,279 @@
+package x509pop
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/spiffe/spire/pkg/common/pluginconf"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/testkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestRevocationChecker(t *testing.T) {
+	pki := newTestPKI(t)
+	revokedCRL := pki.crl(t, pki.leaf.SerialNumber)
+	emptyCRL := pki.crl(t)
+
+	for _, tt := range []struct {
+		name      string
+		config    *RevocationConfig
+		staple    []byte
+		crlDP     []byte
+		ocspResp  []byte
+		expectErr string
+	}{
+		{
+			name:   "good stapled OCSP response",
+			config: &RevocationConfig{OCSP: true},
+			staple: pki.ocspResponse(t, ocsp.Good),
+		},
+		{
+			name:      "revoked stapled OCSP response",
+			config:    &RevocationConfig{OCSP: true},
+			staple:    pki.ocspResponse(t, ocsp.Revoked),
+			expectErr: "certificate 2a is revoked (source: ocsp_staple)",
+		},
+		{
+			name:      "invalid stapled OCSP response falls back to CRLs",
+			config:    &RevocationConfig{CRLPaths: []string{pki.writeCRL(t, revokedCRL)}},
+			staple:    []byte("garbage"),
+			expectErr: "certificate 2a is revoked (source: crl)",
+		},
+		{
+			name:   "not revoked according to CRL file",
+			config: &RevocationConfig{CRLPaths: []string{pki.writeCRL(t, emptyCRL)}},
+		},
+		{
+			name:      "revoked according to CRL file",
+			config:    &RevocationConfig{CRLPaths: []string{pki.writeCRL(t, emptyCRL), pki.writeCRL(t, revokedCRL)}},
+			expectErr: "certificate 2a is revoked (source: crl)",
+		},
+		{
+			name:      "revoked according to CRL distribution point",
+			config:    &RevocationConfig{CRLDistributionPoints: true},
+			crlDP:     revokedCRL,
+			expectErr: "certificate 2a is revoked (source: crl)",
+		},
+		{
+			name:     "good according to OCSP responder",
+			config:   &RevocationConfig{OCSP: true},
+			ocspResp: pki.ocspResponse(t, ocsp.Good),
+		},
+		{
+			name:      "revoked according to OCSP responder",
+			config:    &RevocationConfig{OCSP: true},
+			ocspResp:  pki.ocspResponse(t, ocsp.Revoked),
+			expectErr: "certificate 2a is revoked (source: ocsp)",
+		},
+		{
+			name:      "CRL from another issuer is ignored",
+			config:    &RevocationConfig{CRLPaths: []string{pki.writeCRL(t, newTestPKI(t).crl(t, pki.leaf.SerialNumber))}},
+			expectErr: "unable to determine revocation status of certificate 2a: no revocation information available",
+		},
+		{
+			name:      "unknown fails closed",
+			config:    &RevocationConfig{OCSP: true},
+			expectErr: "unable to determine revocation status of certificate 2a: OCSP responder",
+		},
+		{
+			name:   "unknown fails open",
+			config: &RevocationConfig{OCSP: true, FailOpen: true},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/crl" && tt.crlDP != nil:
+					_, _ = w.Write(tt.crlDP)
+				case r.URL.Path == "/ocsp" && tt.ocspResp != nil:
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					_, err = ocsp.ParseRequest(body)
+					assert.NoError(t, err)
+					_, _ = w.Write(tt.ocspResp)
+				default:
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			t.Cleanup(server.Close)
+
+			leaf := pki.issueLeaf(t, server.URL)
+			checker := buildTestRevocationChecker(t, tt.config)
+
+			err := checker.Check(context.Background(), hclog.NewNullLogger(), nil, leaf, pki.ca, tt.staple)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRevocationCheckerRefreshesCRLs(t *testing.T) {
+	pki := newTestPKI(t)
+	crlPath := pki.writeCRL(t, pki.crl(t))
+
+	checker := buildTestRevocationChecker(t, &RevocationConfig{
+		CRLPaths:           []string{crlPath},
+		CRLRefreshInterval: "10m",
+	})
+	clk := checker.clk.(*clock.Mock)
+
+	check := func() error {
+		return checker.Check(context.Background(), hclog.NewNullLogger(), nil, pki.leaf, pki.ca, nil)
+	}
+	require.NoError(t, check())
+
+	// The updated CRL is not used until the refresh interval elapses
+	require.NoError(t, os.WriteFile(crlPath, pki.crl(t, pki.leaf.SerialNumber), 0600))
+	require.NoError(t, check())
+
+	clk.Add(10 * time.Minute)
+	require.EqualError(t, check(), "certificate 2a is revoked (source: crl)")
+
+	// A CRL that fails to reload keeps being used until it expires
+	require.NoError(t, os.WriteFile(crlPath, []byte("garbage"), 0600))
+	clk.Add(10 * time.Minute)
+	require.EqualError(t, check(), "certificate 2a is revoked (source: crl)")
+
+	clk.Add(2 * time.Hour)
+	require.EqualError(t, check(), "unable to determine revocation status of certificate 2a: no revocation information available")
+}
+
+func buildTestRevocationChecker(t *testing.T, config *RevocationConfig) *revocationChecker {
+	checker := buildRevocationChecker(config, new(pluginconf.Status))
+	checker.clk = clock.NewMock(t)
+	for _, cached := range checker.crls {
+		cached.loadedAt = checker.clk.Now()
+	}
+	return checker
+}
+
+type testPKI struct {
+	ca      *x509.Certificate
+	caKey   crypto.Signer
+	leaf    *x509.Certificate
+	leafKey crypto.Signer
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	caKey := testkey.NewEC256(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	pki := &testPKI{
+		caKey:   caKey,
+		leafKey: testkey.NewEC256(t),
+	}
+	pki.ca = createCertificate(t, caTmpl, caTmpl, caKey.Public(), caKey)
+	pki.leaf = pki.issueLeaf(t, "")
+	return pki
+}
+
+// issueIntermediate returns a PKI whose CA is an intermediate CA issued by
+// this PKI's CA.
+func (p *testPKI) issueIntermediate(t *testing.T) *testPKI {
+	caKey := testkey.NewEC256(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(7),
+		Subject:               pkix.Name{CommonName: "INTERMEDIATE"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	intermediate := &testPKI{
+		caKey:   caKey,
+		leafKey: testkey.NewEC256(t),
+	}
+	intermediate.ca = createCertificate(t, caTmpl, p.ca, caKey.Public(), p.caKey)
+	intermediate.leaf = intermediate.issueLeaf(t, "")
+	return intermediate
+}
+
+// issueLeaf issues the leaf certificate, pointing its CRL distribution point
+// and OCSP responder to the given base URL, if any.
+func (p *testPKI) issueLeaf(t *testing.T, baseURL string) *x509.Certificate {
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "COMMONNAME"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if baseURL != "" {
+		tmpl.CRLDistributionPoints = []string{baseURL + "/crl"}
+		tmpl.OCSPServer = []string{baseURL + "/ocsp"}
+	}
+	return createCertificate(t, tmpl, p.ca, p.leafKey.Public(), p.caKey)
+}
+
+func (p *testPKI) crl(t *testing.T, revoked ...*big.Int) []byte {
+	tmpl := &x509.RevocationList{
+		Number:     big.NewInt(time.Now().UnixNano()),
+		ThisUpdate: time.Now().Add(-time.Minute),
+		NextUpdate: time.Now().Add(time.Hour),
+	}
+	for _, serial := range revoked {
+		tmpl.RevokedCertificateEntries = append(tmpl.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, tmpl, p.ca, p.caKey)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func (p *testPKI) writeCRL(t *testing.T, crl []byte) string {
+	path := filepath.Join(t.TempDir(), "crl.pem")
+	require.NoError(t, os.WriteFile(path, crl, 0600))
+	return path
+}
+
+func (p *testPKI) ocspResponse(t *testing.T, status int) []byte {
+	template := ocsp.Response{
+		Status:       status,
+		SerialNumber: p.leaf.SerialNumber,
+		ThisUpdate:   time.Now().Add(-time.Minute),
+		NextUpdate:   time.Now().Add(time.Hour),
+	}
+	if status == ocsp.Revoked {
+		template.RevokedAt = time.Now().Add(-time.Minute)
+	}
+	resp, err := ocsp.CreateResponse(p.ca, p.ca, template, p.caKey)
+	require.NoError(t, err)
+	return resp
+}
+
+func createCertificate(t *testing.T, tmpl, parent *x509.Certificate, publicKey any, signer crypto.Signer) *x509.Certificate {
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, publicKey, signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
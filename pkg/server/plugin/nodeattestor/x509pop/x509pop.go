@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/hcl"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-plugin-sdk/pluginsdk"
+	metricsv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/common/metrics/v1"
 	identityproviderv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/identityprovider/v1"
 	nodeattestorv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/nodeattestor/v1"
 	configv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/service/common/config/v1"
@@ -46,6 +47,8 @@ type Config struct {
 	CABundlePath      string   `hcl:"ca_bundle_path"`
 	CABundlePaths     []string `hcl:"ca_bundle_paths"`
 	AgentPathTemplate string   `hcl:"agent_path_template"`
+
+	Revocation *RevocationConfig `hcl:"revocation"`
 }
 
 type configuration struct {
@@ -54,6 +57,7 @@ type configuration struct {
 	trustDomain  spiffeid.TrustDomain
 	trustBundle  *x509.CertPool
 	pathTemplate *agentpathtemplate.Template
+	revocation   *revocationChecker
 }
 
 func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginconf.Status) *configuration {
@@ -97,6 +101,14 @@ func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginco
 		status.ReportError("you can not use ca_bundle_path or ca_bundle_paths in spiffe mode")
 	}
 
+	var revocation *revocationChecker
+	if hclConfig.Revocation != nil {
+		if hclConfig.Mode != "external_pki" {
+			status.ReportError("revocation checking is only supported in external_pki mode")
+		}
+		revocation = buildRevocationChecker(hclConfig.Revocation, status)
+	}
+
 	pathTemplate := x509pop.DefaultAgentPathTemplateCN
 	if hclConfig.Mode == "spiffe" {
 		pathTemplate = x509pop.DefaultAgentPathTemplateSVID
@@ -123,6 +135,7 @@ func buildConfig(coreConfig catalog.CoreConfig, hclText string, status *pluginco
 		pathTemplate: pathTemplate,
 		mode:         hclConfig.Mode,
 		svidPrefix:   svidPrefix,
+		revocation:   revocation,
 	}
 
 	return newConfig
@@ -137,6 +150,7 @@ type Plugin struct {
 	m                sync.Mutex
 	config           *configuration
 	identityProvider identityproviderv1.IdentityProviderServiceClient
+	metrics          metricsv1.MetricsServiceClient
 }
 
 func New() *Plugin {
@@ -147,6 +161,9 @@ func (p *Plugin) BrokerHostServices(broker pluginsdk.ServiceBroker) error {
 	if !broker.BrokerClient(&p.identityProvider) {
 		return status.Errorf(codes.FailedPrecondition, "IdentityProvider host service is required")
 	}
+	// The Metrics host service is optional and only used to report
+	// revocation checks.
+	broker.BrokerClient(&p.metrics)
 	return nil
 }
 
@@ -206,6 +223,22 @@ func (p *Plugin) Attest(stream nodeattestorv1.NodeAttestor_AttestServer) error {
 		return status.Errorf(codes.PermissionDenied, "certificate verification failed: %v", err)
 	}
 
+	if config.revocation != nil {
+		// Check every certificate of the verified chain against its issuer,
+		// up to the trusted root. The agent only staples an OCSP response for
+		// the leaf.
+		chain := chains[0]
+		for i := 0; i < len(chain)-1; i++ {
+			var staple []byte
+			if i == 0 {
+				staple = attestationData.OCSPStaple
+			}
+			if err := config.revocation.Check(stream.Context(), p.log, p.getMetrics(), chain[i], chain[i+1], staple); err != nil {
+				return status.Errorf(codes.PermissionDenied, "certificate revocation check failed: %v", err)
+			}
+		}
+	}
+
 	// now that the leaf certificate is trusted, issue a challenge to the node
 	// to prove possession of the private key.
 	challenge, err := x509pop.GenerateChallenge(leaf)
@@ -324,6 +357,13 @@ func (p *Plugin) getTrustBundle(ctx context.Context) (*x509.CertPool, error) {
 	return nil, nil
 }
 
+func (p *Plugin) getMetrics() metricsv1.MetricsClient {
+	if !p.metrics.IsInitialized() {
+		return nil
+	}
+	return p.metrics.MetricsClient
+}
+
 func (p *Plugin) getConfig() (*configuration, error) {
 	p.m.Lock()
 	defer p.m.Unlock()
@@ -10,17 +10,24 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	metricsv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/common/metrics/v1"
 	identityproviderv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/hostservice/server/identityprovider/v1"
 	plugintypes "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/types"
 	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/hostservice/metricsservice"
 	"github.com/spiffe/spire/pkg/common/plugin/x509pop"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	spirecommonutil "github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakeidentityprovider"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/fixture"
 	"github.com/spiffe/spire/test/plugintest"
 	"github.com/spiffe/spire/test/spiretest"
@@ -365,6 +372,153 @@ func (s *Suite) TestConfigure() {
 
 		spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "you can not use ca_bundle_path or ca_bundle_paths in spiffe mode")
 	})
+
+	s.T().Run("revocation in spiffe mode", func(t *testing.T) {
+		err := doConfig(t, coreConfig, `
+		mode = "spiffe"
+		revocation {
+			ocsp = true
+		}
+		`)
+
+		spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "revocation checking is only supported in external_pki mode")
+	})
+
+	s.T().Run("revocation without sources", func(t *testing.T) {
+		err := doConfig(t, coreConfig, s.createConfiguration("ca_bundle_path", `
+		revocation {
+			fail_open = true
+		}
+		`))
+
+		spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "revocation checking requires at least one of crl_paths, crl_distribution_points or ocsp")
+	})
+
+	s.T().Run("bad crl_paths", func(t *testing.T) {
+		err := doConfig(t, coreConfig, s.createConfiguration("ca_bundle_path", `
+		revocation {
+			crl_paths = ["blah"]
+		}
+		`))
+
+		spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, `unable to load CRL "blah"`)
+	})
+
+	s.T().Run("bad crl_refresh_interval", func(t *testing.T) {
+		err := doConfig(t, coreConfig, s.createConfiguration("ca_bundle_path", `
+		revocation {
+			ocsp = true
+			crl_refresh_interval = "blah"
+		}
+		`))
+
+		spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "invalid crl_refresh_interval")
+	})
+}
+
+func (s *Suite) TestAttestRevocation() {
+	pki := newTestPKI(s.T())
+	caPath := filepath.Join(s.T().TempDir(), "ca.pem")
+	s.Require().NoError(os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: pki.ca.Raw}), 0600))
+
+	attest := func(t *testing.T, issuer *testPKI, crls [][]byte, metrics *fakemetrics.FakeMetrics) (*nodeattestor.AttestResult, error) {
+		crlPaths := make([]string, 0, len(crls))
+		for _, crl := range crls {
+			crlPaths = append(crlPaths, strconv.Quote(pki.writeCRL(t, crl)))
+		}
+
+		attestor := new(nodeattestor.V1)
+		plugintest.Load(t, BuiltIn(), attestor,
+			plugintest.HostServices(
+				identityproviderv1.IdentityProviderServiceServer(fakeidentityprovider.New()),
+				metricsv1.MetricsServiceServer(metricsservice.V1(metrics)),
+			),
+			plugintest.CoreConfig(catalog.CoreConfig{
+				TrustDomain: spiffeid.RequireTrustDomainFromString("example.org"),
+			}),
+			plugintest.Configure(fmt.Sprintf(`
+ca_bundle_path = %q
+revocation {
+	crl_paths = [%s]
+}
+`, caPath, strings.Join(crlPaths, ", "))),
+		)
+
+		certificates := [][]byte{issuer.leaf.Raw}
+		if issuer != pki {
+			certificates = append(certificates, issuer.ca.Raw)
+		}
+		payload := marshal(t, &x509pop.AttestationData{
+			Certificates: certificates,
+		})
+		return attestor.Attest(context.Background(), payload, func(ctx context.Context, challenge []byte) ([]byte, error) {
+			popChallenge := new(x509pop.Challenge)
+			unmarshal(t, challenge, popChallenge)
+			response, err := x509pop.CalculateResponse(issuer.leafKey, popChallenge)
+			require.NoError(t, err)
+			return marshal(t, response), nil
+		})
+	}
+
+	s.T().Run("not revoked", func(t *testing.T) {
+		metrics := fakemetrics.New()
+		result, err := attest(t, pki, [][]byte{pki.crl(t)}, metrics)
+		require.NoError(t, err)
+		require.Equal(t, "spiffe://example.org/spire/agent/x509pop/"+x509pop.Fingerprint(pki.leaf), result.AgentID)
+		require.Equal(t, []fakemetrics.MetricItem{
+			{
+				Type:   fakemetrics.IncrCounterWithLabelsType,
+				Key:    []string{"x509pop", "revocation_check"},
+				Val:    1,
+				Labels: []telemetry.Label{{Name: "status", Value: "good"}, {Name: "source", Value: "crl"}},
+			},
+		}, metrics.AllMetrics())
+	})
+
+	s.T().Run("revoked", func(t *testing.T) {
+		metrics := fakemetrics.New()
+		result, err := attest(t, pki, [][]byte{pki.crl(t, pki.leaf.SerialNumber)}, metrics)
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "nodeattestor(x509pop): certificate revocation check failed: certificate 2a is revoked (source: crl)")
+		require.Nil(t, result)
+		require.Equal(t, []fakemetrics.MetricItem{
+			{
+				Type:   fakemetrics.IncrCounterWithLabelsType,
+				Key:    []string{"x509pop", "revocation_check"},
+				Val:    1,
+				Labels: []telemetry.Label{{Name: "status", Value: "revoked"}, {Name: "source", Value: "crl"}},
+			},
+		}, metrics.AllMetrics())
+	})
+	s.T().Run("intermediate not revoked", func(t *testing.T) {
+		intermediate := pki.issueIntermediate(t)
+		metrics := fakemetrics.New()
+		result, err := attest(t, intermediate, [][]byte{pki.crl(t), intermediate.crl(t)}, metrics)
+		require.NoError(t, err)
+		require.Equal(t, "spiffe://example.org/spire/agent/x509pop/"+x509pop.Fingerprint(intermediate.leaf), result.AgentID)
+		require.Len(t, metrics.AllMetrics(), 2)
+	})
+
+	s.T().Run("revoked intermediate", func(t *testing.T) {
+		intermediate := pki.issueIntermediate(t)
+		metrics := fakemetrics.New()
+		result, err := attest(t, intermediate, [][]byte{pki.crl(t, intermediate.ca.SerialNumber), intermediate.crl(t)}, metrics)
+		spiretest.RequireGRPCStatus(t, err, codes.PermissionDenied, "nodeattestor(x509pop): certificate revocation check failed: certificate 7 is revoked (source: crl)")
+		require.Nil(t, result)
+		require.Equal(t, []fakemetrics.MetricItem{
+			{
+				Type:   fakemetrics.IncrCounterWithLabelsType,
+				Key:    []string{"x509pop", "revocation_check"},
+				Val:    1,
+				Labels: []telemetry.Label{{Name: "status", Value: "good"}, {Name: "source", Value: "crl"}},
+			},
+			{
+				Type:   fakemetrics.IncrCounterWithLabelsType,
+				Key:    []string{"x509pop", "revocation_check"},
+				Val:    1,
+				Labels: []telemetry.Label{{Name: "status", Value: "revoked"}, {Name: "source", Value: "crl"}},
+			},
+		}, metrics.AllMetrics())
+	})
 }
 
 func (s *Suite) loadPlugin(t *testing.T, config string) nodeattestor.NodeAttestor {
@@ -7,10 +7,9 @@ import (
 	"time"
 
 	upstreamauthorityv1 "github.com/spiffe/spire-plugin-sdk/proto/spire/plugin/server/upstreamauthority/v1"
-	cmapi "github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/certmanager/internal/v1"
+	cmapi "github.com/spiffe/spire/pkg/common/certmanager/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -19,12 +18,7 @@ var (
 )
 
 func init() {
-	schemeGroupVersion := schema.GroupVersion{Group: "cert-manager.io", Version: "v1"}
-	scheme.AddKnownTypes(schemeGroupVersion,
-		&cmapi.CertificateRequest{},
-		&cmapi.CertificateRequestList{},
-	)
-	metav1.AddToGroupVersion(scheme, schemeGroupVersion)
+	_ = cmapi.AddToScheme(scheme)
 }
 
 func (p *Plugin) buildCertificateRequest(request *upstreamauthorityv1.MintX509CARequest) (*cmapi.CertificateRequest, error) {
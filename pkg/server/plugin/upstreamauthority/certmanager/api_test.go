@@ -6,7 +6,7 @@ import (
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
-	cmapi "github.com/spiffe/spire/pkg/server/plugin/upstreamauthority/certmanager/internal/v1"
+	cmapi "github.com/spiffe/spire/pkg/common/certmanager/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
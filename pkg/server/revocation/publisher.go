@@ -0,0 +1,475 @@
+// Package revocation publishes the revocation status of the X509-SVIDs issued
+// by the server as CRLs and OCSP responses signed by the X.509 authority that
+// issued them.
+package revocation
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/andres-erbsen/clock"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"golang.org/x/crypto/ocsp"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultTTL is the default validity period of the published CRLs and
+	// OCSP responses.
+	DefaultTTL = time.Hour
+
+	// DefaultOCSPSignLimit is the default number of OCSP responses per
+	// second that are signed for requests that cannot be answered from the
+	// cache.
+	DefaultOCSPSignLimit = 50
+
+	// refreshInterval is the interval to check for new revocations and for
+	// new X.509 authorities.
+	refreshInterval = 10 * time.Second
+
+	// maxCachedOCSPResponses bounds the number of cached OCSP responses.
+	maxCachedOCSPResponses = 10000
+)
+
+// X509CASource provides the X.509 authorities whose keys are held by the
+// server.
+type X509CASource interface {
+	X509Authorities() []*ca.X509CA
+}
+
+// Config is the configuration for the revocation publisher.
+type Config struct {
+	X509CASource X509CASource
+	DataStore    datastore.DataStore
+	Log          logrus.FieldLogger
+	Clock        clock.Clock
+
+	// TTL is the validity period of the published CRLs and OCSP responses.
+	// CRLs and cached OCSP responses are re-signed when half of it has
+	// elapsed.
+	TTL time.Duration
+
+	// OCSPSignLimit is the number of OCSP responses per second that are
+	// signed for requests that cannot be answered from the cache. Requests
+	// over the limit get a "try later" response.
+	OCSPSignLimit int
+}
+
+// Publisher publishes the revocation status of the X509-SVIDs issued by the
+// X.509 authorities provided by the X509CASource, until they expire or their
+// keys are no longer held by the server.
+//
+// OCSP responses are cached until half of their TTL has elapsed. Responses
+// for the revoked X509-SVIDs are signed ahead of time, so that the signing
+// done on behalf of unauthenticated OCSP clients is limited to the first
+// request for each X509-SVID in good standing, and is rate limited.
+type Publisher struct {
+	c           Config
+	ocspLimiter *rate.Limiter
+
+	mu          sync.RWMutex
+	authorities map[string]*authority
+	// revoked holds the revoked X509-SVIDs by authority ID and serial
+	// number. Each authority only publishes the revocation of the
+	// X509-SVIDs it issued.
+	revoked       map[string]map[string]*datastore.RevokedX509SVID
+	ocspResponses map[ocspResponseKey]*ocspResponse
+	// revision is incremented every time the revocation status or the
+	// authorities change, so responses signed for a previous revision are
+	// not cached.
+	revision uint64
+}
+
+type authority struct {
+	x509CA       *ca.X509CA
+	crl          []byte
+	crlUpdatedAt time.Time
+}
+
+type ocspResponseKey struct {
+	authorityID  string
+	hash         crypto.Hash
+	serialNumber string
+}
+
+type ocspResponse struct {
+	der       []byte
+	updatedAt time.Time
+}
+
+// New creates a new revocation publisher.
+func New(c Config) *Publisher {
+	if c.Clock == nil {
+		c.Clock = clock.New()
+	}
+	if c.TTL == 0 {
+		c.TTL = DefaultTTL
+	}
+	if c.OCSPSignLimit == 0 {
+		c.OCSPSignLimit = DefaultOCSPSignLimit
+	}
+	return &Publisher{
+		c:             c,
+		ocspLimiter:   rate.NewLimiter(rate.Limit(c.OCSPSignLimit), c.OCSPSignLimit),
+		authorities:   make(map[string]*authority),
+		revoked:       make(map[string]map[string]*datastore.RevokedX509SVID),
+		ocspResponses: make(map[ocspResponseKey]*ocspResponse),
+	}
+}
+
+// Run periodically refreshes the revocation status and the published CRLs
+// until the context is canceled.
+func (p *Publisher) Run(ctx context.Context) error {
+	ticker := p.c.Clock.Ticker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.refresh(ctx); err != nil {
+			p.c.Log.WithError(err).Error("Failed to refresh X509-SVID revocation status")
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// CRL returns the DER encoded CRL published for the X.509 authority with the
+// given authority ID. It returns false if no CRL is available for it.
+func (p *Publisher) CRL(authorityID string) ([]byte, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	a, ok := p.authorities[authorityID]
+	if !ok || a.crl == nil {
+		return nil, false
+	}
+	return a.crl, true
+}
+
+// OCSP returns the DER encoded OCSP response for the given DER encoded OCSP
+// request. Failures are reported using the OCSP error responses.
+func (p *Publisher) OCSP(rawRequest []byte) []byte {
+	req, err := ocsp.ParseRequest(rawRequest)
+	if err != nil {
+		return ocsp.MalformedRequestErrorResponse
+	}
+
+	now := p.c.Clock.Now()
+	key := ocspResponseKey{
+		hash:         req.HashAlgorithm,
+		serialNumber: SerialNumberString(req.SerialNumber),
+	}
+
+	p.mu.RLock()
+	a := p.findIssuer(req)
+	if a == nil {
+		p.mu.RUnlock()
+		return ocsp.UnauthorizedErrorResponse
+	}
+	x509CA := a.x509CA
+	key.authorityID = authorityID(x509CA)
+	cached, ok := p.ocspResponses[key]
+	revoked := p.revoked[key.authorityID][key.serialNumber]
+	revision := p.revision
+	p.mu.RUnlock()
+
+	if ok && p.isFresh(cached.updatedAt, now) {
+		return cached.der
+	}
+
+	if !p.ocspLimiter.AllowN(now, 1) {
+		return ocsp.TryLaterErrorResponse
+	}
+
+	// The key of an X.509 authority can be replaced once the authority is
+	// no longer provided by the source, which the publisher only observes
+	// on the next refresh.
+	if !slices.ContainsFunc(p.c.X509CASource.X509Authorities(), func(x509CA *ca.X509CA) bool {
+		return authorityID(x509CA) == key.authorityID
+	}) {
+		return ocsp.UnauthorizedErrorResponse
+	}
+
+	der, err := p.signOCSPResponse(x509CA, req.HashAlgorithm, req.SerialNumber, revoked, now)
+	if err != nil {
+		p.c.Log.WithError(err).WithField(telemetry.SerialNumber, key.serialNumber).Error("Failed to create OCSP response")
+		return ocsp.InternalErrorErrorResponse
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.revision == revision {
+		p.cacheOCSPResponse(key, der, now)
+	}
+	return der
+}
+
+func (p *Publisher) refresh(ctx context.Context) error {
+	revokedList, err := p.c.DataStore.ListRevokedX509SVIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list revoked X509-SVIDs: %w", err)
+	}
+	revoked := make(map[string]map[string]*datastore.RevokedX509SVID)
+	for _, r := range revokedList {
+		if revoked[r.AuthorityID] == nil {
+			revoked[r.AuthorityID] = make(map[string]*datastore.RevokedX509SVID)
+		}
+		revoked[r.AuthorityID][r.SerialNumber] = r
+	}
+
+	now := p.c.Clock.Now()
+	x509CAs := p.c.X509CASource.X509Authorities()
+
+	p.mu.Lock()
+	changed := !maps.EqualFunc(p.revoked, revoked, func(a, b map[string]*datastore.RevokedX509SVID) bool {
+		return maps.EqualFunc(a, b, func(a, b *datastore.RevokedX509SVID) bool {
+			return a.RevokedAt.Equal(b.RevokedAt)
+		})
+	})
+	p.revoked = revoked
+	if changed {
+		clear(p.ocspResponses)
+	}
+
+	current := make(map[string]*ca.X509CA, len(x509CAs))
+	for _, x509CA := range x509CAs {
+		if now.Before(x509CA.Certificate.NotAfter) {
+			current[authorityID(x509CA)] = x509CA
+		}
+	}
+	for authorityID, a := range p.authorities {
+		if x509CA, ok := current[authorityID]; ok {
+			a.x509CA = x509CA
+			continue
+		}
+		p.c.Log.WithField(telemetry.LocalAuthorityID, authorityID).Info("X.509 authority is no longer available; no longer publishing X509-SVID revocation status")
+		delete(p.authorities, authorityID)
+		maps.DeleteFunc(p.ocspResponses, func(key ocspResponseKey, _ *ocspResponse) bool {
+			return key.authorityID == authorityID
+		})
+		changed = true
+	}
+	for authorityID, x509CA := range current {
+		if _, ok := p.authorities[authorityID]; !ok {
+			p.c.Log.WithField(telemetry.LocalAuthorityID, authorityID).Info("Publishing X509-SVID revocation status for X.509 authority")
+			p.authorities[authorityID] = &authority{x509CA: x509CA}
+			changed = true
+		}
+	}
+	if changed {
+		p.revision++
+	}
+
+	for authorityID, a := range p.authorities {
+		if !changed && a.crl != nil && p.isFresh(a.crlUpdatedAt, now) {
+			continue
+		}
+		crl, err := p.signCRL(a.x509CA, p.revoked[authorityID], now)
+		if err != nil {
+			p.c.Log.WithError(err).WithField(telemetry.LocalAuthorityID, authorityID).Error("Failed to sign CRL")
+			continue
+		}
+		a.crl = crl
+		a.crlUpdatedAt = now
+	}
+
+	p.pruneOCSPResponses(now)
+	presign := p.revokedOCSPResponsesToSign(now)
+	revision := p.revision
+	p.mu.Unlock()
+
+	p.presignOCSPResponses(presign, revision, now)
+	return nil
+}
+
+type ocspResponseToSign struct {
+	key     ocspResponseKey
+	x509CA  *ca.X509CA
+	serial  *big.Int
+	revoked *datastore.RevokedX509SVID
+}
+
+// revokedOCSPResponsesToSign returns the OCSP responses for the revoked
+// X509-SVIDs that are not cached yet, or that are due to be re-signed, by the
+// authority that issued them. The responses are signed for requests using
+// SHA-1 to identify the issuer, which is what OCSP clients use in practice.
+func (p *Publisher) revokedOCSPResponsesToSign(now time.Time) []ocspResponseToSign {
+	var toSign []ocspResponseToSign
+	for authorityID, a := range p.authorities {
+		for serialNumber, revoked := range p.revoked[authorityID] {
+			key := ocspResponseKey{
+				authorityID:  authorityID,
+				hash:         crypto.SHA1,
+				serialNumber: serialNumber,
+			}
+			if cached, ok := p.ocspResponses[key]; ok && p.isFresh(cached.updatedAt, now) {
+				continue
+			}
+			serial, ok := new(big.Int).SetString(serialNumber, 16)
+			if !ok {
+				continue
+			}
+			toSign = append(toSign, ocspResponseToSign{
+				key:     key,
+				x509CA:  a.x509CA,
+				serial:  serial,
+				revoked: revoked,
+			})
+		}
+	}
+	return toSign
+}
+
+// presignOCSPResponses signs the given OCSP responses and caches them, unless
+// the revocation status or the authorities changed while signing.
+func (p *Publisher) presignOCSPResponses(toSign []ocspResponseToSign, revision uint64, now time.Time) {
+	signed := make(map[ocspResponseKey][]byte, len(toSign))
+	for _, r := range toSign {
+		der, err := p.signOCSPResponse(r.x509CA, r.key.hash, r.serial, r.revoked, now)
+		if err != nil {
+			p.c.Log.WithError(err).WithField(telemetry.SerialNumber, r.key.serialNumber).Error("Failed to create OCSP response")
+			continue
+		}
+		signed[r.key] = der
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.revision != revision {
+		return
+	}
+	for key, der := range signed {
+		p.cacheOCSPResponse(key, der, now)
+	}
+}
+
+func (p *Publisher) signOCSPResponse(x509CA *ca.X509CA, hash crypto.Hash, serial *big.Int, revoked *datastore.RevokedX509SVID, now time.Time) ([]byte, error) {
+	template := ocsp.Response{
+		Status:       ocsp.Good,
+		SerialNumber: serial,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(p.c.TTL),
+		IssuerHash:   hash,
+	}
+	if revoked != nil {
+		template.Status = ocsp.Revoked
+		template.RevokedAt = revoked.RevokedAt
+		template.RevocationReason = ocsp.Unspecified
+	}
+
+	// The X.509 authority signs the response itself, so there is no need
+	// to include a delegated responder certificate.
+	return ocsp.CreateResponse(x509CA.Certificate, x509CA.Certificate, template, x509CA.Signer)
+}
+
+// cacheOCSPResponse caches an OCSP response. If the cache is full, the
+// response is not cached.
+func (p *Publisher) cacheOCSPResponse(key ocspResponseKey, der []byte, now time.Time) {
+	if _, ok := p.ocspResponses[key]; !ok && len(p.ocspResponses) >= maxCachedOCSPResponses {
+		p.pruneOCSPResponses(now)
+		if len(p.ocspResponses) >= maxCachedOCSPResponses {
+			return
+		}
+	}
+	p.ocspResponses[key] = &ocspResponse{der: der, updatedAt: now}
+}
+
+func (p *Publisher) pruneOCSPResponses(now time.Time) {
+	maps.DeleteFunc(p.ocspResponses, func(_ ocspResponseKey, resp *ocspResponse) bool {
+		return !p.isFresh(resp.updatedAt, now)
+	})
+}
+
+// isFresh returns true if something signed at the given time does not need to
+// be re-signed yet.
+func (p *Publisher) isFresh(signedAt, now time.Time) bool {
+	return now.Sub(signedAt) < p.c.TTL/2
+}
+
+// signCRL signs a CRL listing the given X509-SVIDs revoked by the authority,
+// keyed by serial number.
+func (p *Publisher) signCRL(x509CA *ca.X509CA, revoked map[string]*datastore.RevokedX509SVID, now time.Time) ([]byte, error) {
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, serialNumber := range slices.Sorted(maps.Keys(revoked)) {
+		serial, ok := new(big.Int).SetString(serialNumber, 16)
+		if !ok {
+			p.c.Log.WithField(telemetry.SerialNumber, serialNumber).Warn("Ignoring revoked X509-SVID with malformed serial number")
+			continue
+		}
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: revoked[serialNumber].RevokedAt,
+		})
+	}
+
+	return x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		// The CRL number must increase monotonically across the CRLs
+		// published for an authority, even across server restarts.
+		Number:                    big.NewInt(now.UnixNano()),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(p.c.TTL),
+		RevokedCertificateEntries: entries,
+	}, x509CA.Certificate, x509CA.Signer)
+}
+
+// findIssuer returns the tracked authority that matches the issuer
+// identified by the OCSP request, or nil if there is none.
+func (p *Publisher) findIssuer(req *ocsp.Request) *authority {
+	for _, a := range p.authorities {
+		nameHash, keyHash, err := issuerHashes(a.x509CA.Certificate, req.HashAlgorithm)
+		if err != nil {
+			continue
+		}
+		if bytes.Equal(nameHash, req.IssuerNameHash) && bytes.Equal(keyHash, req.IssuerKeyHash) {
+			return a
+		}
+	}
+	return nil
+}
+
+// issuerHashes computes the issuer name and key hashes used to identify an
+// issuer in an OCSP request (RFC 6960, section 4.1.1).
+func issuerHashes(issuer *x509.Certificate, hash crypto.Hash) ([]byte, []byte, error) {
+	if !hash.Available() {
+		return nil, nil, fmt.Errorf("unsupported hash algorithm %v", hash)
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, nil, err
+	}
+
+	h := hash.New()
+	h.Write(issuer.RawSubject)
+	nameHash := h.Sum(nil)
+
+	h.Reset()
+	h.Write(spki.PublicKey.RightAlign())
+	keyHash := h.Sum(nil)
+
+	return nameHash, keyHash, nil
+}
+
+func authorityID(x509CA *ca.X509CA) string {
+	return x509util.SubjectKeyIDToString(x509CA.Certificate.SubjectKeyId)
+}
@@ -0,0 +1,338 @@
+package revocation
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/server/ca"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+var ctx = context.Background()
+
+func TestCRL(t *testing.T) {
+	test := setupTest(t)
+	test.revoke(t, test.x509CA, "0a", test.clk.Now().Add(-time.Minute))
+
+	require.NoError(t, test.p.refresh(ctx))
+
+	_, ok := test.p.CRL("unknown")
+	assert.False(t, ok)
+
+	crl := test.requireCRL(t, test.x509CA)
+	require.Len(t, crl.RevokedCertificateEntries, 1)
+	assert.Equal(t, big.NewInt(10), crl.RevokedCertificateEntries[0].SerialNumber)
+	assert.Equal(t, test.clk.Now().Add(-time.Minute).Unix(), crl.RevokedCertificateEntries[0].RevocationTime.Unix())
+	assert.Equal(t, test.clk.Now().Add(DefaultTTL).Unix(), crl.NextUpdate.Unix())
+
+	// The CRL is re-signed when a new revocation is observed
+	test.clk.Add(time.Minute)
+	test.revoke(t, test.x509CA, "0b", test.clk.Now())
+	require.NoError(t, test.p.refresh(ctx))
+	updated := test.requireCRL(t, test.x509CA)
+	assert.Len(t, updated.RevokedCertificateEntries, 2)
+	assert.Equal(t, 1, updated.Number.Cmp(crl.Number))
+
+	// The CRL is not re-signed when nothing changed
+	test.clk.Add(time.Minute)
+	require.NoError(t, test.p.refresh(ctx))
+	assert.Equal(t, updated.Number, test.requireCRL(t, test.x509CA).Number)
+
+	// The CRL is re-signed once half of its TTL has elapsed
+	test.clk.Add(DefaultTTL / 2)
+	require.NoError(t, test.p.refresh(ctx))
+	assert.Equal(t, 1, test.requireCRL(t, test.x509CA).Number.Cmp(updated.Number))
+}
+
+func TestCRLAuthorityRotation(t *testing.T) {
+	test := setupTest(t)
+	require.NoError(t, test.p.refresh(ctx))
+	old := test.x509CA
+
+	// The previous authority keeps being published after the rotation
+	test.x509CA = newX509CA(t, test.clk.Now().Add(3*time.Hour))
+	test.x509CAs = append(test.x509CAs, test.x509CA)
+	require.NoError(t, test.p.refresh(ctx))
+	test.requireCRL(t, old)
+	test.requireCRL(t, test.x509CA)
+
+	// ... until it expires
+	test.clk.Add(2 * time.Hour)
+	require.NoError(t, test.p.refresh(ctx))
+	_, ok := test.p.CRL(authorityID(old))
+	assert.False(t, ok)
+	test.requireCRL(t, test.x509CA)
+
+	// ... or is no longer provided by the source
+	next := newX509CA(t, test.clk.Now().Add(3*time.Hour))
+	test.x509CAs = []*ca.X509CA{next}
+	require.NoError(t, test.p.refresh(ctx))
+	_, ok = test.p.CRL(authorityID(test.x509CA))
+	assert.False(t, ok)
+	test.requireCRL(t, next)
+}
+
+func TestRevocationScopedToIssuer(t *testing.T) {
+	test := setupTest(t)
+	other := newX509CA(t, test.clk.Now().Add(time.Hour))
+	test.x509CAs = append(test.x509CAs, other)
+	test.revoke(t, test.x509CA, "0a", test.clk.Now())
+	require.NoError(t, test.p.refresh(ctx))
+
+	// Only the CRL of the issuer lists the revoked X509-SVID
+	assert.Len(t, test.requireCRL(t, test.x509CA).RevokedCertificateEntries, 1)
+	assert.Empty(t, test.requireCRL(t, other).RevokedCertificateEntries)
+
+	// Only the issuer has a response signed ahead of time
+	for key := range test.p.ocspResponses {
+		assert.Equal(t, authorityID(test.x509CA), key.authorityID)
+	}
+
+	// An X509-SVID with the same serial number issued by another authority
+	// is not revoked
+	revoked, _ := testca.CreateX509Certificate(t, test.x509CA.Certificate, test.x509CA.Signer, testca.WithSerial(big.NewInt(10)))
+	good, _ := testca.CreateX509Certificate(t, other.Certificate, other.Signer, testca.WithSerial(big.NewInt(10)))
+	assert.Equal(t, ocsp.Revoked, test.requireOCSP(t, revoked, test.x509CA.Certificate).Status)
+	assert.Equal(t, ocsp.Good, test.requireOCSP(t, good, other.Certificate).Status)
+}
+
+func TestRefreshFailure(t *testing.T) {
+	test := setupTest(t)
+	test.ds.SetNextError(assert.AnError)
+
+	require.EqualError(t, test.p.refresh(ctx), "failed to list revoked X509-SVIDs: "+assert.AnError.Error())
+	_, ok := test.p.CRL(authorityID(test.x509CA))
+	assert.False(t, ok)
+}
+
+func TestOCSP(t *testing.T) {
+	test := setupTest(t)
+	test.revoke(t, test.x509CA, "0a", test.clk.Now().Add(-time.Minute))
+	require.NoError(t, test.p.refresh(ctx))
+
+	issuer := test.x509CA.Certificate
+	revoked, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(10)))
+	good, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(11)))
+
+	resp := test.requireOCSP(t, revoked, issuer)
+	assert.Equal(t, ocsp.Revoked, resp.Status)
+	assert.Equal(t, ocsp.Unspecified, resp.RevocationReason)
+	assert.Equal(t, test.clk.Now().Add(-time.Minute).Unix(), resp.RevokedAt.Unix())
+	assert.Equal(t, test.clk.Now().Add(DefaultTTL).Unix(), resp.NextUpdate.Unix())
+
+	resp = test.requireOCSP(t, good, issuer)
+	assert.Equal(t, ocsp.Good, resp.Status)
+
+	// Unknown issuer
+	other := newX509CA(t, test.clk.Now().Add(time.Hour))
+	unknown, _ := testca.CreateX509Certificate(t, other.Certificate, other.Signer)
+	req, err := ocsp.CreateRequest(unknown, other.Certificate, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.UnauthorizedErrorResponse, test.p.OCSP(req))
+
+	// Malformed request
+	assert.Equal(t, ocsp.MalformedRequestErrorResponse, test.p.OCSP([]byte("malformed")))
+
+	// Authority no longer provided by the source
+	test.x509CAs = nil
+	req, err = ocsp.CreateRequest(good, issuer, &ocsp.RequestOptions{Hash: crypto.SHA512})
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.UnauthorizedErrorResponse, test.p.OCSP(req))
+}
+
+func TestOCSPCache(t *testing.T) {
+	test := setupTest(t)
+	require.NoError(t, test.p.refresh(ctx))
+
+	issuer := test.x509CA.Certificate
+	cert, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(10)))
+	req, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA256})
+	require.NoError(t, err)
+
+	// The response is served from the cache
+	resp := test.p.OCSP(req)
+	assert.Equal(t, resp, test.p.OCSP(req))
+
+	// ... until half of its TTL has elapsed
+	test.clk.Add(DefaultTTL / 2)
+	updated := test.p.OCSP(req)
+	assert.NotEqual(t, resp, updated)
+	assert.Equal(t, updated, test.p.OCSP(req))
+
+	// ... or the revocation status changes
+	test.revoke(t, test.x509CA, "0a", test.clk.Now())
+	require.NoError(t, test.p.refresh(ctx))
+	parsed := test.requireOCSP(t, cert, issuer)
+	assert.Equal(t, ocsp.Revoked, parsed.Status)
+}
+
+func TestOCSPSignLimit(t *testing.T) {
+	test := setupTest(t, func(c *Config) {
+		c.OCSPSignLimit = 1
+	})
+	test.revoke(t, test.x509CA, "0a", test.clk.Now())
+	require.NoError(t, test.p.refresh(ctx))
+
+	issuer := test.x509CA.Certificate
+	revoked, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(10)))
+	good1, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(11)))
+	good2, _ := testca.CreateX509Certificate(t, issuer, test.x509CA.Signer, testca.WithSerial(big.NewInt(12)))
+
+	// The first response is signed on demand and cached
+	resp := test.requireOCSP(t, good1, issuer)
+	assert.Equal(t, ocsp.Good, resp.Status)
+
+	// Responses that need to be signed are rate limited
+	req, err := ocsp.CreateRequest(good2, issuer, &ocsp.RequestOptions{Hash: crypto.SHA256})
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.TryLaterErrorResponse, test.p.OCSP(req))
+
+	// Cached responses are still served
+	resp = test.requireOCSP(t, good1, issuer)
+	assert.Equal(t, ocsp.Good, resp.Status)
+
+	// Responses for revoked X509-SVIDs are signed ahead of time for SHA-1
+	// requests
+	req, err = ocsp.CreateRequest(revoked, issuer, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	require.NoError(t, err)
+	resp, err = ocsp.ParseResponseForCert(test.p.OCSP(req), revoked, issuer)
+	require.NoError(t, err)
+	assert.Equal(t, ocsp.Revoked, resp.Status)
+
+	// The limit is replenished over time
+	test.clk.Add(time.Second)
+	resp = test.requireOCSP(t, good2, issuer)
+	assert.Equal(t, ocsp.Good, resp.Status)
+}
+
+func TestParseSerialNumber(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		out    string
+		expErr string
+	}{
+		{in: "0a", out: "0a"},
+		{in: "A", out: "0a"},
+		{in: "4A:3B:01", out: "4a3b01"},
+		{in: " 00ff ", out: "ff"},
+		{in: "", expErr: "serial number is required"},
+		{in: "xyz", expErr: "serial number must be hexadecimal"},
+		{in: "0", expErr: "serial number must be positive"},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			out, err := ParseSerialNumber(tt.in)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestParseAuthorityID(t *testing.T) {
+	for _, tt := range []struct {
+		in     string
+		out    string
+		expErr string
+	}{
+		{in: "0a1b", out: "0a1b"},
+		{in: "0A:1B:2C", out: "0a1b2c"},
+		{in: " 0a1b ", out: "0a1b"},
+		{in: "", expErr: "authority ID is required"},
+		{in: "xyz", expErr: "authority ID must be hexadecimal"},
+		{in: "a1b", expErr: "authority ID must be hexadecimal"},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			out, err := ParseAuthorityID(tt.in)
+			if tt.expErr != "" {
+				require.EqualError(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.out, out)
+		})
+	}
+}
+
+type publisherTest struct {
+	p       *Publisher
+	ds      *fakedatastore.DataStore
+	clk     *clock.Mock
+	x509CA  *ca.X509CA
+	x509CAs []*ca.X509CA
+}
+
+func setupTest(t *testing.T, opts ...func(*Config)) *publisherTest {
+	log, _ := test.NewNullLogger()
+	test := &publisherTest{
+		ds:  fakedatastore.New(t),
+		clk: clock.NewMock(t),
+	}
+	test.x509CA = newX509CA(t, test.clk.Now().Add(time.Hour))
+	test.x509CAs = []*ca.X509CA{test.x509CA}
+	c := Config{
+		X509CASource: test,
+		DataStore:    test.ds,
+		Log:          log,
+		Clock:        test.clk,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	test.p = New(c)
+	return test
+}
+
+func (test *publisherTest) X509Authorities() []*ca.X509CA {
+	return test.x509CAs
+}
+
+func (test *publisherTest) revoke(t *testing.T, issuer *ca.X509CA, serialNumber string, revokedAt time.Time) {
+	_, err := test.ds.RevokeX509SVID(ctx, &datastore.RevokedX509SVID{
+		AuthorityID:  authorityID(issuer),
+		SerialNumber: serialNumber,
+		RevokedAt:    revokedAt,
+		ExpiresAt:    revokedAt.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+}
+
+func (test *publisherTest) requireCRL(t *testing.T, x509CA *ca.X509CA) *x509.RevocationList {
+	der, ok := test.p.CRL(authorityID(x509CA))
+	require.True(t, ok)
+	crl, err := x509.ParseRevocationList(der)
+	require.NoError(t, err)
+	require.NoError(t, crl.CheckSignatureFrom(x509CA.Certificate))
+	return crl
+}
+
+func (test *publisherTest) requireOCSP(t *testing.T, cert, issuer *x509.Certificate) *ocsp.Response {
+	req, err := ocsp.CreateRequest(cert, issuer, &ocsp.RequestOptions{Hash: crypto.SHA256})
+	require.NoError(t, err)
+	resp, err := ocsp.ParseResponseForCert(test.p.OCSP(req), cert, issuer)
+	require.NoError(t, err)
+	return resp
+}
+
+func newX509CA(t *testing.T, notAfter time.Time) *ca.X509CA {
+	cert, signer := testca.CreateCACertificate(t, nil, nil,
+		testca.WithKeyUsage(x509.KeyUsageCertSign|x509.KeyUsageCRLSign),
+		testca.WithLifetime(notAfter.Add(-2*time.Hour), notAfter))
+	return &ca.X509CA{
+		Signer:      signer,
+		Certificate: cert,
+	}
+}
@@ -0,0 +1,55 @@
+package revocation
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/spiffe/spire/pkg/common/x509util"
+)
+
+// ParseSerialNumber parses a hexadecimal certificate serial number, optionally
+// with colon separators (e.g. as displayed by OpenSSL), and returns it in the
+// form used to record revocations.
+func ParseSerialNumber(s string) (string, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ":", "")
+	if s == "" {
+		return "", errors.New("serial number is required")
+	}
+	serial, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		return "", errors.New("serial number must be hexadecimal")
+	}
+	if serial.Sign() <= 0 {
+		return "", errors.New("serial number must be positive")
+	}
+	return SerialNumberString(serial), nil
+}
+
+// SerialNumberString returns the form used to record the revocation of the
+// certificate with the given serial number: lowercase hexadecimal with an
+// even number of digits.
+func SerialNumberString(serial *big.Int) string {
+	s := serial.Text(16)
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	return s
+}
+
+// ParseAuthorityID parses the hexadecimal authority ID of an X.509 authority,
+// i.e. the subject key ID of its certificate, optionally with colon separators
+// (e.g. as the authority key identifier is displayed by OpenSSL), and returns
+// it in the form used to identify the authority.
+func ParseAuthorityID(s string) (string, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ":", "")
+	if s == "" {
+		return "", errors.New("authority ID is required")
+	}
+	keyID, err := hex.DecodeString(s)
+	if err != nil {
+		return "", errors.New("authority ID must be hexadecimal")
+	}
+	return x509util.SubjectKeyIDToString(keyID), nil
+}
@@ -36,8 +36,10 @@ import (
 	"github.com/spiffe/spire/pkg/server/endpoints"
 	"github.com/spiffe/spire/pkg/server/hostservice/agentstore"
 	"github.com/spiffe/spire/pkg/server/hostservice/identityprovider"
+	"github.com/spiffe/spire/pkg/server/k8ssigner"
 	"github.com/spiffe/spire/pkg/server/plugin/bundlepublisher"
 	"github.com/spiffe/spire/pkg/server/registration"
+	"github.com/spiffe/spire/pkg/server/revocation"
 	"github.com/spiffe/spire/pkg/server/svid"
 	"google.golang.org/grpc"
 )
@@ -88,15 +90,27 @@ func (s *Server) run(ctx context.Context) (err error) {
 		defer stopProfiling()
 	}
 
-	metrics, err := telemetry.NewMetrics(&telemetry.MetricsConfig{
+	telemetryConfig := &telemetry.MetricsConfig{
 		FileConfig:  s.config.Telemetry,
 		Logger:      s.config.Log.WithField(telemetry.SubsystemName, telemetry.Telemetry),
 		ServiceName: telemetry.SpireServer,
 		TrustDomain: s.config.TrustDomain.Name(),
-	})
+	}
+
+	metrics, err := telemetry.NewMetrics(telemetryConfig)
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := telemetry.InitTracing(ctx, telemetryConfig)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			s.config.Log.WithError(err).Warn("Failed to shut down tracing")
+		}
+	}()
 
 	telemetry.EmitStarted(metrics, s.config.TrustDomain)
 	uptime.ReportMetrics(ctx, metrics)
@@ -153,6 +167,8 @@ func (s *Server) run(ctx context.Context) (err error) {
 	}
 	defer caManager.Close()
 
+	revocationPublisher := s.newRevocationPublisher(cat, caManager)
+
 	caSync, err := s.newCASync(ctx, healthChecker, caManager)
 	if err != nil {
 		return err
@@ -170,7 +186,7 @@ func (s *Server) run(ctx context.Context) (err error) {
 
 	bundleManager := s.newBundleManager(cat, metrics)
 
-	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager)
+	endpointsServer, err := s.newEndpointsServer(ctx, cat, svidRotator, serverCA, metrics, caManager, authPolicyEngine, bundleManager, revocationPublisher)
 	if err != nil {
 		return err
 	}
@@ -199,6 +215,11 @@ func (s *Server) run(ctx context.Context) (err error) {
 
 	registrationManager := s.newRegistrationManager(cat, metrics)
 
+	kubernetesSigner, err := s.newKubernetesSigner(cat, serverCA)
+	if err != nil {
+		return err
+	}
+
 	if err := healthChecker.AddCheck("server", s); err != nil {
 		return fmt.Errorf("failed adding healthcheck: %w", err)
 	}
@@ -219,6 +240,14 @@ func (s *Server) run(ctx context.Context) (err error) {
 		tasks = append(tasks, s.config.LogReopener)
 	}
 
+	if revocationPublisher != nil {
+		tasks = append(tasks, revocationPublisher.Run)
+	}
+
+	if kubernetesSigner != nil {
+		tasks = append(tasks, kubernetesSigner.Run)
+	}
+
 	err = util.RunTasks(ctx, tasks...)
 	if errors.Is(err, context.Canceled) {
 		err = nil
@@ -309,9 +338,17 @@ func (s *Server) newCredBuilder(cat catalog.Catalog) (*credtemplate.Builder, err
 		CredentialComposers:          cat.GetCredentialComposers(),
 		UseLegacyDownstreamX509CATTL: s.config.UseLegacyDownstreamX509CATTL,
 		TLSPolicy:                    s.config.TLSPolicy,
+		X509SVIDRevocationURL:        s.x509SVIDRevocationURL(),
 	})
 }
 
+func (s *Server) x509SVIDRevocationURL() string {
+	if s.config.Federation.BundleEndpoint == nil || s.config.Federation.BundleEndpoint.X509SVIDRevocation == nil {
+		return ""
+	}
+	return s.config.Federation.BundleEndpoint.X509SVIDRevocation.URL
+}
+
 func (s *Server) newCredValidator() (*credvalidator.Validator, error) {
 	return credvalidator.New(credvalidator.Config{
 		TrustDomain: s.config.TrustDomain,
@@ -329,6 +366,39 @@ func (s *Server) newCA(metrics telemetry.Metrics, credBuilder *credtemplate.Buil
 	})
 }
 
+func (s *Server) newRevocationPublisher(cat catalog.Catalog, caManager *manager.Manager) *revocation.Publisher {
+	if s.config.Federation.BundleEndpoint == nil || s.config.Federation.BundleEndpoint.X509SVIDRevocation == nil {
+		return nil
+	}
+	return revocation.New(revocation.Config{
+		X509CASource: caManager,
+		DataStore:    cat.GetDataStore(),
+		Log:          s.config.Log.WithField(telemetry.SubsystemName, "revocation_publisher"),
+		TTL:          s.config.Federation.BundleEndpoint.X509SVIDRevocation.TTL,
+	})
+}
+
+func (s *Server) newKubernetesSigner(cat catalog.Catalog, serverCA *ca.CA) (*k8ssigner.Signer, error) {
+	if s.config.KubernetesSigner == nil {
+		return nil, nil
+	}
+	client, err := k8ssigner.NewClient(s.config.KubernetesSigner.KubeConfigFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes signer client: %w", err)
+	}
+	return k8ssigner.New(k8ssigner.Config{
+		TrustDomain:       s.config.TrustDomain,
+		Client:            client,
+		CA:                serverCA,
+		DataStore:         cat.GetDataStore(),
+		Log:               s.config.Log.WithField(telemetry.SubsystemName, "kubernetes_signer"),
+		ResyncInterval:    s.config.KubernetesSigner.ResyncInterval,
+		X509SVIDTTL:       s.config.X509SVIDTTL,
+		SignerName:        s.config.KubernetesSigner.SignerName,
+		CertManagerIssuer: s.config.KubernetesSigner.CertManagerIssuer,
+	}), nil
+}
+
 func (s *Server) newCAManager(ctx context.Context, cat catalog.Catalog, metrics telemetry.Metrics, serverCA *ca.CA, credBuilder *credtemplate.Builder, credValidator *credvalidator.Validator) (*manager.Manager, error) {
 	caManager, err := manager.NewManager(ctx, manager.Config{
 		CA:            serverCA,
@@ -384,7 +454,7 @@ func (s *Server) newSVIDRotator(ctx context.Context, serverCA ca.ServerCA, metri
 	return svidRotator, nil
 }
 
-func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, authorityManager manager.AuthorityManager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager) (endpoints.Server, error) {
+func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA *ca.CA, metrics telemetry.Metrics, authorityManager manager.AuthorityManager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager, revocationPublisher *revocation.Publisher) (endpoints.Server, error) {
 	config := endpoints.Config{
 		TCPAddr:                      s.config.BindAddress,
 		LocalAddr:                    s.config.BindLocalAddress,
@@ -392,6 +462,7 @@ func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog
 		TrustDomain:                  s.config.TrustDomain,
 		Catalog:                      catalog,
 		ServerCA:                     serverCA,
+		JWTKeySource:                 serverCA,
 		Log:                          s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
 		RootLog:                      s.config.Log,
 		Metrics:                      metrics,
@@ -414,6 +485,10 @@ func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog
 		config.BundleEndpoint.RefreshHint = s.config.Federation.BundleEndpoint.RefreshHint
 		config.BundleEndpoint.ACME = s.config.Federation.BundleEndpoint.ACME
 		config.BundleEndpoint.DiskCertManager = s.config.Federation.BundleEndpoint.DiskCertManager
+		config.BundleEndpoint.SignBundle = s.config.Federation.BundleEndpoint.SignBundle
+	}
+	if revocationPublisher != nil {
+		config.RevocationPublisher = revocationPublisher
 	}
 	return endpoints.New(ctx, config)
 }
@@ -0,0 +1,882 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.4
+// source: spire/api/agent/cache/v1/cache.proto
+
+package cachev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Selector struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The type of the selector (e.g. "unix").
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The value of the selector (e.g. "uid:1000").
+	Value         string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Selector) Reset() {
+	*x = Selector{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Selector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Selector) ProtoMessage() {}
+
+func (x *Selector) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Selector.ProtoReflect.Descriptor instead.
+func (*Selector) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Selector) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Selector) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type Entry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the registration entry. For entries materialized from a
+	// template entry, the materialized entry ID.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The SPIFFE ID of the entry.
+	SpiffeId string `protobuf:"bytes,2,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The SPIFFE ID of the parent of the entry.
+	ParentId string `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	// The selectors of the entry.
+	Selectors []*Selector `protobuf:"bytes,4,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	// The hint of the entry.
+	Hint string `protobuf:"bytes,5,opt,name=hint,proto3" json:"hint,omitempty"`
+	// The trust domains the entry federates with.
+	FederatesWith []string `protobuf:"bytes,6,rep,name=federates_with,json=federatesWith,proto3" json:"federates_with,omitempty"`
+	// The revision number of the entry.
+	RevisionNumber int64 `protobuf:"varint,7,opt,name=revision_number,json=revisionNumber,proto3" json:"revision_number,omitempty"`
+	// When the cached X509-SVID expires (seconds since Unix epoch). Zero
+	// if no X509-SVID is cached for the entry.
+	X509SvidExpiresAt int64 `protobuf:"varint,8,opt,name=x509_svid_expires_at,json=x509SvidExpiresAt,proto3" json:"x509_svid_expires_at,omitempty"`
+	// Whether the entry is waiting for a new X509-SVID.
+	Stale bool `protobuf:"varint,9,opt,name=stale,proto3" json:"stale,omitempty"`
+	// The number of subscribers the entry is served to.
+	Subscribers   int32 `protobuf:"varint,10,opt,name=subscribers,proto3" json:"subscribers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entry) Reset() {
+	*x = Entry{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entry) ProtoMessage() {}
+
+func (x *Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entry.ProtoReflect.Descriptor instead.
+func (*Entry) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Entry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Entry) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *Entry) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Entry) GetSelectors() []*Selector {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+func (x *Entry) GetHint() string {
+	if x != nil {
+		return x.Hint
+	}
+	return ""
+}
+
+func (x *Entry) GetFederatesWith() []string {
+	if x != nil {
+		return x.FederatesWith
+	}
+	return nil
+}
+
+func (x *Entry) GetRevisionNumber() int64 {
+	if x != nil {
+		return x.RevisionNumber
+	}
+	return 0
+}
+
+func (x *Entry) GetX509SvidExpiresAt() int64 {
+	if x != nil {
+		return x.X509SvidExpiresAt
+	}
+	return 0
+}
+
+func (x *Entry) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *Entry) GetSubscribers() int32 {
+	if x != nil {
+		return x.Subscribers
+	}
+	return 0
+}
+
+type SubscriberSet struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The workload selectors of the subscribers.
+	Selectors []*Selector `protobuf:"bytes,1,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	// The number of subscribers with these selectors.
+	Count         int32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriberSet) Reset() {
+	*x = SubscriberSet{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriberSet) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriberSet) ProtoMessage() {}
+
+func (x *SubscriberSet) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriberSet.ProtoReflect.Descriptor instead.
+func (*SubscriberSet) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubscriberSet) GetSelectors() []*Selector {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+func (x *SubscriberSet) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type ListEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesRequest) Reset() {
+	*x = ListEntriesRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesRequest) ProtoMessage() {}
+
+func (x *ListEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{3}
+}
+
+type ListEntriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The cached entries, ordered by ID.
+	Entries       []*Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesResponse) Reset() {
+	*x = ListEntriesResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesResponse) ProtoMessage() {}
+
+func (x *ListEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListEntriesResponse) GetEntries() []*Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type GetEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The ID of the entry.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntryRequest) Reset() {
+	*x = GetEntryRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntryRequest) ProtoMessage() {}
+
+func (x *GetEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntryRequest.ProtoReflect.Descriptor instead.
+func (*GetEntryRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetEntryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetEntryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The cached entry.
+	Entry         *Entry `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEntryResponse) Reset() {
+	*x = GetEntryResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEntryResponse) ProtoMessage() {}
+
+func (x *GetEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEntryResponse.ProtoReflect.Descriptor instead.
+func (*GetEntryResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetEntryResponse) GetEntry() *Entry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type ListSubscribersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSubscribersRequest) Reset() {
+	*x = ListSubscribersRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscribersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersRequest) ProtoMessage() {}
+
+func (x *ListSubscribersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscribersRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{7}
+}
+
+type ListSubscribersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The selector sets with active subscribers.
+	SubscriberSets []*SubscriberSet `protobuf:"bytes,1,rep,name=subscriber_sets,json=subscriberSets,proto3" json:"subscriber_sets,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListSubscribersResponse) Reset() {
+	*x = ListSubscribersResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSubscribersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersResponse) ProtoMessage() {}
+
+func (x *ListSubscribersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersResponse.ProtoReflect.Descriptor instead.
+func (*ListSubscribersResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListSubscribersResponse) GetSubscriberSets() []*SubscriberSet {
+	if x != nil {
+		return x.SubscriberSets
+	}
+	return nil
+}
+
+type ResyncRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResyncRequest) Reset() {
+	*x = ResyncRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResyncRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResyncRequest) ProtoMessage() {}
+
+func (x *ResyncRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResyncRequest.ProtoReflect.Descriptor instead.
+func (*ResyncRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{9}
+}
+
+type ResyncResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// When the synchronization finished (seconds since Unix epoch).
+	LastSync      int64 `protobuf:"varint,1,opt,name=last_sync,json=lastSync,proto3" json:"last_sync,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResyncResponse) Reset() {
+	*x = ResyncResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResyncResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResyncResponse) ProtoMessage() {}
+
+func (x *ResyncResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResyncResponse.ProtoReflect.Descriptor instead.
+func (*ResyncResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResyncResponse) GetLastSync() int64 {
+	if x != nil {
+		return x.LastSync
+	}
+	return 0
+}
+
+type RotateX509SVIDRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The ID of the entry.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateX509SVIDRequest) Reset() {
+	*x = RotateX509SVIDRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateX509SVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateX509SVIDRequest) ProtoMessage() {}
+
+func (x *RotateX509SVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateX509SVIDRequest.ProtoReflect.Descriptor instead.
+func (*RotateX509SVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RotateX509SVIDRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RotateX509SVIDResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RotateX509SVIDResponse) Reset() {
+	*x = RotateX509SVIDResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RotateX509SVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RotateX509SVIDResponse) ProtoMessage() {}
+
+func (x *RotateX509SVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RotateX509SVIDResponse.ProtoReflect.Descriptor instead.
+func (*RotateX509SVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{12}
+}
+
+type EvictStaleJWTSVIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvictStaleJWTSVIDsRequest) Reset() {
+	*x = EvictStaleJWTSVIDsRequest{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvictStaleJWTSVIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvictStaleJWTSVIDsRequest) ProtoMessage() {}
+
+func (x *EvictStaleJWTSVIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvictStaleJWTSVIDsRequest.ProtoReflect.Descriptor instead.
+func (*EvictStaleJWTSVIDsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{13}
+}
+
+type EvictStaleJWTSVIDsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The number of JWT-SVIDs removed from the cache.
+	Evicted       int32 `protobuf:"varint,1,opt,name=evicted,proto3" json:"evicted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EvictStaleJWTSVIDsResponse) Reset() {
+	*x = EvictStaleJWTSVIDsResponse{}
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EvictStaleJWTSVIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvictStaleJWTSVIDsResponse) ProtoMessage() {}
+
+func (x *EvictStaleJWTSVIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_agent_cache_v1_cache_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvictStaleJWTSVIDsResponse.ProtoReflect.Descriptor instead.
+func (*EvictStaleJWTSVIDsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EvictStaleJWTSVIDsResponse) GetEvicted() int32 {
+	if x != nil {
+		return x.Evicted
+	}
+	return 0
+}
+
+var File_spire_api_agent_cache_v1_cache_proto protoreflect.FileDescriptor
+
+const file_spire_api_agent_cache_v1_cache_proto_rawDesc = "" +
+	"\n" +
+	"$spire/api/agent/cache/v1/cache.proto\x12\x18spire.api.agent.cache.v1\"4\n" +
+	"\bSelector\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xe0\x02\n" +
+	"\x05Entry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tspiffe_id\x18\x02 \x01(\tR\bspiffeId\x12\x1b\n" +
+	"\tparent_id\x18\x03 \x01(\tR\bparentId\x12@\n" +
+	"\tselectors\x18\x04 \x03(\v2\".spire.api.agent.cache.v1.SelectorR\tselectors\x12\x12\n" +
+	"\x04hint\x18\x05 \x01(\tR\x04hint\x12%\n" +
+	"\x0efederates_with\x18\x06 \x03(\tR\rfederatesWith\x12'\n" +
+	"\x0frevision_number\x18\a \x01(\x03R\x0erevisionNumber\x12/\n" +
+	"\x14x509_svid_expires_at\x18\b \x01(\x03R\x11x509SvidExpiresAt\x12\x14\n" +
+	"\x05stale\x18\t \x01(\bR\x05stale\x12 \n" +
+	"\vsubscribers\x18\n" +
+	" \x01(\x05R\vsubscribers\"g\n" +
+	"\rSubscriberSet\x12@\n" +
+	"\tselectors\x18\x01 \x03(\v2\".spire.api.agent.cache.v1.SelectorR\tselectors\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"\x14\n" +
+	"\x12ListEntriesRequest\"P\n" +
+	"\x13ListEntriesResponse\x129\n" +
+	"\aentries\x18\x01 \x03(\v2\x1f.spire.api.agent.cache.v1.EntryR\aentries\"!\n" +
+	"\x0fGetEntryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"I\n" +
+	"\x10GetEntryResponse\x125\n" +
+	"\x05entry\x18\x01 \x01(\v2\x1f.spire.api.agent.cache.v1.EntryR\x05entry\"\x18\n" +
+	"\x16ListSubscribersRequest\"k\n" +
+	"\x17ListSubscribersResponse\x12P\n" +
+	"\x0fsubscriber_sets\x18\x01 \x03(\v2'.spire.api.agent.cache.v1.SubscriberSetR\x0esubscriberSets\"\x0f\n" +
+	"\rResyncRequest\"-\n" +
+	"\x0eResyncResponse\x12\x1b\n" +
+	"\tlast_sync\x18\x01 \x01(\x03R\blastSync\"'\n" +
+	"\x15RotateX509SVIDRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x18\n" +
+	"\x16RotateX509SVIDResponse\"\x1b\n" +
+	"\x19EvictStaleJWTSVIDsRequest\"6\n" +
+	"\x1aEvictStaleJWTSVIDsResponse\x12\x18\n" +
+	"\aevicted\x18\x01 \x01(\x05R\aevicted2\xa1\x05\n" +
+	"\x05Cache\x12j\n" +
+	"\vListEntries\x12,.spire.api.agent.cache.v1.ListEntriesRequest\x1a-.spire.api.agent.cache.v1.ListEntriesResponse\x12a\n" +
+	"\bGetEntry\x12).spire.api.agent.cache.v1.GetEntryRequest\x1a*.spire.api.agent.cache.v1.GetEntryResponse\x12v\n" +
+	"\x0fListSubscribers\x120.spire.api.agent.cache.v1.ListSubscribersRequest\x1a1.spire.api.agent.cache.v1.ListSubscribersResponse\x12[\n" +
+	"\x06Resync\x12'.spire.api.agent.cache.v1.ResyncRequest\x1a(.spire.api.agent.cache.v1.ResyncResponse\x12s\n" +
+	"\x0eRotateX509SVID\x12/.spire.api.agent.cache.v1.RotateX509SVIDRequest\x1a0.spire.api.agent.cache.v1.RotateX509SVIDResponse\x12\x7f\n" +
+	"\x12EvictStaleJWTSVIDs\x123.spire.api.agent.cache.v1.EvictStaleJWTSVIDsRequest\x1a4.spire.api.agent.cache.v1.EvictStaleJWTSVIDsResponseB@Z>github.com/spiffe/spire/proto/spire/api/agent/cache/v1;cachev1b\x06proto3"
+
+var (
+	file_spire_api_agent_cache_v1_cache_proto_rawDescOnce sync.Once
+	file_spire_api_agent_cache_v1_cache_proto_rawDescData []byte
+)
+
+func file_spire_api_agent_cache_v1_cache_proto_rawDescGZIP() []byte {
+	file_spire_api_agent_cache_v1_cache_proto_rawDescOnce.Do(func() {
+		file_spire_api_agent_cache_v1_cache_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_spire_api_agent_cache_v1_cache_proto_rawDesc), len(file_spire_api_agent_cache_v1_cache_proto_rawDesc)))
+	})
+	return file_spire_api_agent_cache_v1_cache_proto_rawDescData
+}
+
+var file_spire_api_agent_cache_v1_cache_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_spire_api_agent_cache_v1_cache_proto_goTypes = []any{
+	(*Selector)(nil),                   // 0: spire.api.agent.cache.v1.Selector
+	(*Entry)(nil),                      // 1: spire.api.agent.cache.v1.Entry
+	(*SubscriberSet)(nil),              // 2: spire.api.agent.cache.v1.SubscriberSet
+	(*ListEntriesRequest)(nil),         // 3: spire.api.agent.cache.v1.ListEntriesRequest
+	(*ListEntriesResponse)(nil),        // 4: spire.api.agent.cache.v1.ListEntriesResponse
+	(*GetEntryRequest)(nil),            // 5: spire.api.agent.cache.v1.GetEntryRequest
+	(*GetEntryResponse)(nil),           // 6: spire.api.agent.cache.v1.GetEntryResponse
+	(*ListSubscribersRequest)(nil),     // 7: spire.api.agent.cache.v1.ListSubscribersRequest
+	(*ListSubscribersResponse)(nil),    // 8: spire.api.agent.cache.v1.ListSubscribersResponse
+	(*ResyncRequest)(nil),              // 9: spire.api.agent.cache.v1.ResyncRequest
+	(*ResyncResponse)(nil),             // 10: spire.api.agent.cache.v1.ResyncResponse
+	(*RotateX509SVIDRequest)(nil),      // 11: spire.api.agent.cache.v1.RotateX509SVIDRequest
+	(*RotateX509SVIDResponse)(nil),     // 12: spire.api.agent.cache.v1.RotateX509SVIDResponse
+	(*EvictStaleJWTSVIDsRequest)(nil),  // 13: spire.api.agent.cache.v1.EvictStaleJWTSVIDsRequest
+	(*EvictStaleJWTSVIDsResponse)(nil), // 14: spire.api.agent.cache.v1.EvictStaleJWTSVIDsResponse
+}
+var file_spire_api_agent_cache_v1_cache_proto_depIdxs = []int32{
+	0,  // 0: spire.api.agent.cache.v1.Entry.selectors:type_name -> spire.api.agent.cache.v1.Selector
+	0,  // 1: spire.api.agent.cache.v1.SubscriberSet.selectors:type_name -> spire.api.agent.cache.v1.Selector
+	1,  // 2: spire.api.agent.cache.v1.ListEntriesResponse.entries:type_name -> spire.api.agent.cache.v1.Entry
+	1,  // 3: spire.api.agent.cache.v1.GetEntryResponse.entry:type_name -> spire.api.agent.cache.v1.Entry
+	2,  // 4: spire.api.agent.cache.v1.ListSubscribersResponse.subscriber_sets:type_name -> spire.api.agent.cache.v1.SubscriberSet
+	3,  // 5: spire.api.agent.cache.v1.Cache.ListEntries:input_type -> spire.api.agent.cache.v1.ListEntriesRequest
+	5,  // 6: spire.api.agent.cache.v1.Cache.GetEntry:input_type -> spire.api.agent.cache.v1.GetEntryRequest
+	7,  // 7: spire.api.agent.cache.v1.Cache.ListSubscribers:input_type -> spire.api.agent.cache.v1.ListSubscribersRequest
+	9,  // 8: spire.api.agent.cache.v1.Cache.Resync:input_type -> spire.api.agent.cache.v1.ResyncRequest
+	11, // 9: spire.api.agent.cache.v1.Cache.RotateX509SVID:input_type -> spire.api.agent.cache.v1.RotateX509SVIDRequest
+	13, // 10: spire.api.agent.cache.v1.Cache.EvictStaleJWTSVIDs:input_type -> spire.api.agent.cache.v1.EvictStaleJWTSVIDsRequest
+	4,  // 11: spire.api.agent.cache.v1.Cache.ListEntries:output_type -> spire.api.agent.cache.v1.ListEntriesResponse
+	6,  // 12: spire.api.agent.cache.v1.Cache.GetEntry:output_type -> spire.api.agent.cache.v1.GetEntryResponse
+	8,  // 13: spire.api.agent.cache.v1.Cache.ListSubscribers:output_type -> spire.api.agent.cache.v1.ListSubscribersResponse
+	10, // 14: spire.api.agent.cache.v1.Cache.Resync:output_type -> spire.api.agent.cache.v1.ResyncResponse
+	12, // 15: spire.api.agent.cache.v1.Cache.RotateX509SVID:output_type -> spire.api.agent.cache.v1.RotateX509SVIDResponse
+	14, // 16: spire.api.agent.cache.v1.Cache.EvictStaleJWTSVIDs:output_type -> spire.api.agent.cache.v1.EvictStaleJWTSVIDsResponse
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_agent_cache_v1_cache_proto_init() }
+func file_spire_api_agent_cache_v1_cache_proto_init() {
+	if File_spire_api_agent_cache_v1_cache_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spire_api_agent_cache_v1_cache_proto_rawDesc), len(file_spire_api_agent_cache_v1_cache_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_agent_cache_v1_cache_proto_goTypes,
+		DependencyIndexes: file_spire_api_agent_cache_v1_cache_proto_depIdxs,
+		MessageInfos:      file_spire_api_agent_cache_v1_cache_proto_msgTypes,
+	}.Build()
+	File_spire_api_agent_cache_v1_cache_proto = out.File
+	file_spire_api_agent_cache_v1_cache_proto_goTypes = nil
+	file_spire_api_agent_cache_v1_cache_proto_depIdxs = nil
+}
@@ -0,0 +1,316 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.29.4
+// source: spire/api/agent/cache/v1/cache.proto
+
+package cachev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Cache_ListEntries_FullMethodName        = "/spire.api.agent.cache.v1.Cache/ListEntries"
+	Cache_GetEntry_FullMethodName           = "/spire.api.agent.cache.v1.Cache/GetEntry"
+	Cache_ListSubscribers_FullMethodName    = "/spire.api.agent.cache.v1.Cache/ListSubscribers"
+	Cache_Resync_FullMethodName             = "/spire.api.agent.cache.v1.Cache/Resync"
+	Cache_RotateX509SVID_FullMethodName     = "/spire.api.agent.cache.v1.Cache/RotateX509SVID"
+	Cache_EvictStaleJWTSVIDs_FullMethodName = "/spire.api.agent.cache.v1.Cache/EvictStaleJWTSVIDs"
+)
+
+// CacheClient is the client API for Cache service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CacheClient interface {
+	// ListEntries lists the registration entries cached by the agent, along
+	// with the expiration of their cached X509-SVID.
+	ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error)
+	// GetEntry gets a registration entry cached by the agent.
+	GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error)
+	// ListSubscribers lists the workload selector sets with active
+	// subscribers, along with the number of subscribers for each set.
+	ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersResponse, error)
+	// Resync synchronizes the authorized entries and bundles with the
+	// server immediately, instead of waiting for the next sync interval.
+	Resync(ctx context.Context, in *ResyncRequest, opts ...grpc.CallOption) (*ResyncResponse, error)
+	// RotateX509SVID forces the rotation of the X509-SVID cached for an
+	// entry. The cached X509-SVID is served until the new one is minted.
+	RotateX509SVID(ctx context.Context, in *RotateX509SVIDRequest, opts ...grpc.CallOption) (*RotateX509SVIDResponse, error)
+	// EvictStaleJWTSVIDs removes the JWT-SVIDs that are expired or about to
+	// expire from the JWT-SVID cache.
+	EvictStaleJWTSVIDs(ctx context.Context, in *EvictStaleJWTSVIDsRequest, opts ...grpc.CallOption) (*EvictStaleJWTSVIDsResponse, error)
+}
+
+type cacheClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCacheClient(cc grpc.ClientConnInterface) CacheClient {
+	return &cacheClient{cc}
+}
+
+func (c *cacheClient) ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error) {
+	out := new(ListEntriesResponse)
+	err := c.cc.Invoke(ctx, Cache_ListEntries_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) GetEntry(ctx context.Context, in *GetEntryRequest, opts ...grpc.CallOption) (*GetEntryResponse, error) {
+	out := new(GetEntryResponse)
+	err := c.cc.Invoke(ctx, Cache_GetEntry_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersResponse, error) {
+	out := new(ListSubscribersResponse)
+	err := c.cc.Invoke(ctx, Cache_ListSubscribers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) Resync(ctx context.Context, in *ResyncRequest, opts ...grpc.CallOption) (*ResyncResponse, error) {
+	out := new(ResyncResponse)
+	err := c.cc.Invoke(ctx, Cache_Resync_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) RotateX509SVID(ctx context.Context, in *RotateX509SVIDRequest, opts ...grpc.CallOption) (*RotateX509SVIDResponse, error) {
+	out := new(RotateX509SVIDResponse)
+	err := c.cc.Invoke(ctx, Cache_RotateX509SVID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cacheClient) EvictStaleJWTSVIDs(ctx context.Context, in *EvictStaleJWTSVIDsRequest, opts ...grpc.CallOption) (*EvictStaleJWTSVIDsResponse, error) {
+	out := new(EvictStaleJWTSVIDsResponse)
+	err := c.cc.Invoke(ctx, Cache_EvictStaleJWTSVIDs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CacheServer is the server API for Cache service.
+// All implementations must embed UnimplementedCacheServer
+// for forward compatibility
+type CacheServer interface {
+	// ListEntries lists the registration entries cached by the agent, along
+	// with the expiration of their cached X509-SVID.
+	ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error)
+	// GetEntry gets a registration entry cached by the agent.
+	GetEntry(context.Context, *GetEntryRequest) (*GetEntryResponse, error)
+	// ListSubscribers lists the workload selector sets with active
+	// subscribers, along with the number of subscribers for each set.
+	ListSubscribers(context.Context, *ListSubscribersRequest) (*ListSubscribersResponse, error)
+	// Resync synchronizes the authorized entries and bundles with the
+	// server immediately, instead of waiting for the next sync interval.
+	Resync(context.Context, *ResyncRequest) (*ResyncResponse, error)
+	// RotateX509SVID forces the rotation of the X509-SVID cached for an
+	// entry. The cached X509-SVID is served until the new one is minted.
+	RotateX509SVID(context.Context, *RotateX509SVIDRequest) (*RotateX509SVIDResponse, error)
+	// EvictStaleJWTSVIDs removes the JWT-SVIDs that are expired or about to
+	// expire from the JWT-SVID cache.
+	EvictStaleJWTSVIDs(context.Context, *EvictStaleJWTSVIDsRequest) (*EvictStaleJWTSVIDsResponse, error)
+	mustEmbedUnimplementedCacheServer()
+}
+
+// UnimplementedCacheServer must be embedded to have forward compatible implementations.
+type UnimplementedCacheServer struct {
+}
+
+func (UnimplementedCacheServer) ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEntries not implemented")
+}
+func (UnimplementedCacheServer) GetEntry(context.Context, *GetEntryRequest) (*GetEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEntry not implemented")
+}
+func (UnimplementedCacheServer) ListSubscribers(context.Context, *ListSubscribersRequest) (*ListSubscribersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscribers not implemented")
+}
+func (UnimplementedCacheServer) Resync(context.Context, *ResyncRequest) (*ResyncResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resync not implemented")
+}
+func (UnimplementedCacheServer) RotateX509SVID(context.Context, *RotateX509SVIDRequest) (*RotateX509SVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RotateX509SVID not implemented")
+}
+func (UnimplementedCacheServer) EvictStaleJWTSVIDs(context.Context, *EvictStaleJWTSVIDsRequest) (*EvictStaleJWTSVIDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvictStaleJWTSVIDs not implemented")
+}
+func (UnimplementedCacheServer) mustEmbedUnimplementedCacheServer() {}
+
+// UnsafeCacheServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CacheServer will
+// result in compilation errors.
+type UnsafeCacheServer interface {
+	mustEmbedUnimplementedCacheServer()
+}
+
+func RegisterCacheServer(s grpc.ServiceRegistrar, srv CacheServer) {
+	s.RegisterService(&Cache_ServiceDesc, srv)
+}
+
+func _Cache_ListEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).ListEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_ListEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).ListEntries(ctx, req.(*ListEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_GetEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).GetEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_GetEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).GetEntry(ctx, req.(*GetEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_ListSubscribers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscribersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).ListSubscribers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_ListSubscribers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).ListSubscribers(ctx, req.(*ListSubscribersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_Resync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResyncRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).Resync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_Resync_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).Resync(ctx, req.(*ResyncRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_RotateX509SVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RotateX509SVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).RotateX509SVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_RotateX509SVID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).RotateX509SVID(ctx, req.(*RotateX509SVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cache_EvictStaleJWTSVIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvictStaleJWTSVIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CacheServer).EvictStaleJWTSVIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Cache_EvictStaleJWTSVIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CacheServer).EvictStaleJWTSVIDs(ctx, req.(*EvictStaleJWTSVIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Cache_ServiceDesc is the grpc.ServiceDesc for Cache service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Cache_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.agent.cache.v1.Cache",
+	HandlerType: (*CacheServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListEntries",
+			Handler:    _Cache_ListEntries_Handler,
+		},
+		{
+			MethodName: "GetEntry",
+			Handler:    _Cache_GetEntry_Handler,
+		},
+		{
+			MethodName: "ListSubscribers",
+			Handler:    _Cache_ListSubscribers_Handler,
+		},
+		{
+			MethodName: "Resync",
+			Handler:    _Cache_Resync_Handler,
+		},
+		{
+			MethodName: "RotateX509SVID",
+			Handler:    _Cache_RotateX509SVID_Handler,
+		},
+		{
+			MethodName: "EvictStaleJWTSVIDs",
+			Handler:    _Cache_EvictStaleJWTSVIDs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spire/api/agent/cache/v1/cache.proto",
+}
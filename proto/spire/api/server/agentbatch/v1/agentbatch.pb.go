@@ -0,0 +1,860 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.4
+// source: spire/api/server/agentbatch/v1/agentbatch.proto
+
+package agentbatchv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SelectorMatch_MatchBehavior int32
+
+const (
+	// The agent selectors are exactly the given selectors.
+	SelectorMatch_MATCH_EXACT SelectorMatch_MatchBehavior = 0
+	// The agent selectors are a subset of the given selectors.
+	SelectorMatch_MATCH_SUBSET SelectorMatch_MatchBehavior = 1
+	// The agent selectors are a superset of the given selectors.
+	SelectorMatch_MATCH_SUPERSET SelectorMatch_MatchBehavior = 2
+	// The agent selectors contain any of the given selectors.
+	SelectorMatch_MATCH_ANY SelectorMatch_MatchBehavior = 3
+)
+
+// Enum value maps for SelectorMatch_MatchBehavior.
+var (
+	SelectorMatch_MatchBehavior_name = map[int32]string{
+		0: "MATCH_EXACT",
+		1: "MATCH_SUBSET",
+		2: "MATCH_SUPERSET",
+		3: "MATCH_ANY",
+	}
+	SelectorMatch_MatchBehavior_value = map[string]int32{
+		"MATCH_EXACT":    0,
+		"MATCH_SUBSET":   1,
+		"MATCH_SUPERSET": 2,
+		"MATCH_ANY":      3,
+	}
+)
+
+func (x SelectorMatch_MatchBehavior) Enum() *SelectorMatch_MatchBehavior {
+	p := new(SelectorMatch_MatchBehavior)
+	*p = x
+	return p
+}
+
+func (x SelectorMatch_MatchBehavior) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SelectorMatch_MatchBehavior) Descriptor() protoreflect.EnumDescriptor {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_enumTypes[0].Descriptor()
+}
+
+func (SelectorMatch_MatchBehavior) Type() protoreflect.EnumType {
+	return &file_spire_api_server_agentbatch_v1_agentbatch_proto_enumTypes[0]
+}
+
+func (x SelectorMatch_MatchBehavior) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SelectorMatch_MatchBehavior.Descriptor instead.
+func (SelectorMatch_MatchBehavior) EnumDescriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type Selector struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The type of the selector (e.g. "aws_iid").
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// The value of the selector (e.g. "tag:pool:blue").
+	Value         string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Selector) Reset() {
+	*x = Selector{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Selector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Selector) ProtoMessage() {}
+
+func (x *Selector) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Selector.ProtoReflect.Descriptor instead.
+func (*Selector) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Selector) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Selector) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type SelectorMatch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The selectors to match.
+	Selectors []*Selector `protobuf:"bytes,1,rep,name=selectors,proto3" json:"selectors,omitempty"`
+	// How the agent selectors are matched.
+	Match         SelectorMatch_MatchBehavior `protobuf:"varint,2,opt,name=match,proto3,enum=spire.api.server.agentbatch.v1.SelectorMatch_MatchBehavior" json:"match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelectorMatch) Reset() {
+	*x = SelectorMatch{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelectorMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectorMatch) ProtoMessage() {}
+
+func (x *SelectorMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectorMatch.ProtoReflect.Descriptor instead.
+func (*SelectorMatch) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SelectorMatch) GetSelectors() []*Selector {
+	if x != nil {
+		return x.Selectors
+	}
+	return nil
+}
+
+func (x *SelectorMatch) GetMatch() SelectorMatch_MatchBehavior {
+	if x != nil {
+		return x.Match
+	}
+	return SelectorMatch_MATCH_EXACT
+}
+
+// AgentFilter selects agents the same way as the filter of the
+// Agent service ListAgents RPC. At least one of the criteria must be set.
+type AgentFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Filters agents by their selectors.
+	BySelectorMatch *SelectorMatch `protobuf:"bytes,1,opt,name=by_selector_match,json=bySelectorMatch,proto3" json:"by_selector_match,omitempty"`
+	// Filters agents by attestation type.
+	ByAttestationType string `protobuf:"bytes,2,opt,name=by_attestation_type,json=byAttestationType,proto3" json:"by_attestation_type,omitempty"`
+	// Filters agents by whether they can re-attest.
+	ByCanReattest *bool `protobuf:"varint,3,opt,name=by_can_reattest,json=byCanReattest,proto3,oneof" json:"by_can_reattest,omitempty"`
+	// Filters agents by whether they are banned.
+	ByBanned *bool `protobuf:"varint,4,opt,name=by_banned,json=byBanned,proto3,oneof" json:"by_banned,omitempty"`
+	// Filters agents whose X509-SVID expires before this time (seconds
+	// since Unix epoch).
+	ByExpiresBefore int64 `protobuf:"varint,5,opt,name=by_expires_before,json=byExpiresBefore,proto3" json:"by_expires_before,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AgentFilter) Reset() {
+	*x = AgentFilter{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentFilter) ProtoMessage() {}
+
+func (x *AgentFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentFilter.ProtoReflect.Descriptor instead.
+func (*AgentFilter) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AgentFilter) GetBySelectorMatch() *SelectorMatch {
+	if x != nil {
+		return x.BySelectorMatch
+	}
+	return nil
+}
+
+func (x *AgentFilter) GetByAttestationType() string {
+	if x != nil {
+		return x.ByAttestationType
+	}
+	return ""
+}
+
+func (x *AgentFilter) GetByCanReattest() bool {
+	if x != nil && x.ByCanReattest != nil {
+		return *x.ByCanReattest
+	}
+	return false
+}
+
+func (x *AgentFilter) GetByBanned() bool {
+	if x != nil && x.ByBanned != nil {
+		return *x.ByBanned
+	}
+	return false
+}
+
+func (x *AgentFilter) GetByExpiresBefore() int64 {
+	if x != nil {
+		return x.ByExpiresBefore
+	}
+	return 0
+}
+
+type AgentResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The SPIFFE ID of the agent.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The attestation type of the agent.
+	AttestationType string `protobuf:"bytes,2,opt,name=attestation_type,json=attestationType,proto3" json:"attestation_type,omitempty"`
+	// When the agent X509-SVID expires (seconds since Unix epoch).
+	X509SvidExpiresAt int64 `protobuf:"varint,3,opt,name=x509_svid_expires_at,json=x509SvidExpiresAt,proto3" json:"x509_svid_expires_at,omitempty"`
+	// Whether the agent was banned before the operation.
+	Banned bool `protobuf:"varint,4,opt,name=banned,proto3" json:"banned,omitempty"`
+	// Whether the agent can re-attest.
+	CanReattest bool `protobuf:"varint,5,opt,name=can_reattest,json=canReattest,proto3" json:"can_reattest,omitempty"`
+	// The gRPC status code of the operation on the agent. Agents matching
+	// the filter of a dry run have an OK status code and no message.
+	StatusCode int32 `protobuf:"varint,6,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	// The status message of the operation on the agent.
+	StatusMessage string `protobuf:"bytes,7,opt,name=status_message,json=statusMessage,proto3" json:"status_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentResult) Reset() {
+	*x = AgentResult{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentResult) ProtoMessage() {}
+
+func (x *AgentResult) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentResult.ProtoReflect.Descriptor instead.
+func (*AgentResult) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AgentResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AgentResult) GetAttestationType() string {
+	if x != nil {
+		return x.AttestationType
+	}
+	return ""
+}
+
+func (x *AgentResult) GetX509SvidExpiresAt() int64 {
+	if x != nil {
+		return x.X509SvidExpiresAt
+	}
+	return 0
+}
+
+func (x *AgentResult) GetBanned() bool {
+	if x != nil {
+		return x.Banned
+	}
+	return false
+}
+
+func (x *AgentResult) GetCanReattest() bool {
+	if x != nil {
+		return x.CanReattest
+	}
+	return false
+}
+
+func (x *AgentResult) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *AgentResult) GetStatusMessage() string {
+	if x != nil {
+		return x.StatusMessage
+	}
+	return ""
+}
+
+type BatchBanAgentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The filter selecting the agents to ban.
+	Filter *AgentFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Optional. Restricts the operation to the agents with these SPIFFE
+	// IDs. It allows acting on exactly the agents returned by a dry run:
+	// listed agents that no longer match the filter are reported with a
+	// FAILED_PRECONDITION status and left untouched.
+	Ids []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
+	// If true, the matching agents are returned but not banned.
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchBanAgentsRequest) Reset() {
+	*x = BatchBanAgentsRequest{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchBanAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchBanAgentsRequest) ProtoMessage() {}
+
+func (x *BatchBanAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchBanAgentsRequest.ProtoReflect.Descriptor instead.
+func (*BatchBanAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchBanAgentsRequest) GetFilter() *AgentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *BatchBanAgentsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *BatchBanAgentsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type BatchBanAgentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The result for each agent, ordered by SPIFFE ID.
+	Results       []*AgentResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchBanAgentsResponse) Reset() {
+	*x = BatchBanAgentsResponse{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchBanAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchBanAgentsResponse) ProtoMessage() {}
+
+func (x *BatchBanAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchBanAgentsResponse.ProtoReflect.Descriptor instead.
+func (*BatchBanAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BatchBanAgentsResponse) GetResults() []*AgentResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchEvictAgentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The filter selecting the agents to evict.
+	Filter *AgentFilter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Optional. Restricts the operation to the agents with these SPIFFE
+	// IDs. It allows acting on exactly the agents returned by a dry run:
+	// listed agents that no longer match the filter are reported with a
+	// FAILED_PRECONDITION status and left untouched.
+	Ids []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
+	// If true, the matching agents are returned but not evicted.
+	DryRun        bool `protobuf:"varint,3,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEvictAgentsRequest) Reset() {
+	*x = BatchEvictAgentsRequest{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEvictAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEvictAgentsRequest) ProtoMessage() {}
+
+func (x *BatchEvictAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEvictAgentsRequest.ProtoReflect.Descriptor instead.
+func (*BatchEvictAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchEvictAgentsRequest) GetFilter() *AgentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *BatchEvictAgentsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *BatchEvictAgentsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type BatchEvictAgentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The result for each agent, ordered by SPIFFE ID.
+	Results       []*AgentResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchEvictAgentsResponse) Reset() {
+	*x = BatchEvictAgentsResponse{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchEvictAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchEvictAgentsResponse) ProtoMessage() {}
+
+func (x *BatchEvictAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchEvictAgentsResponse.ProtoReflect.Descriptor instead.
+func (*BatchEvictAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchEvictAgentsResponse) GetResults() []*AgentResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchPurgeAgentsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. Purges the agents whose X509-SVID expired at least this
+	// many seconds ago. It cannot be negative.
+	ExpiredFor int64 `protobuf:"varint,1,opt,name=expired_for,json=expiredFor,proto3" json:"expired_for,omitempty"`
+	// Optional. Restricts the purge to the agents matching these selectors.
+	BySelectorMatch *SelectorMatch `protobuf:"bytes,2,opt,name=by_selector_match,json=bySelectorMatch,proto3" json:"by_selector_match,omitempty"`
+	// Optional. Restricts the purge to the agents with this attestation
+	// type.
+	ByAttestationType string `protobuf:"bytes,3,opt,name=by_attestation_type,json=byAttestationType,proto3" json:"by_attestation_type,omitempty"`
+	// Optional. Restricts the operation to the agents with these SPIFFE
+	// IDs. It allows acting on exactly the agents returned by a dry run:
+	// listed agents that can no longer be purged are reported with a
+	// FAILED_PRECONDITION status and left untouched.
+	Ids []string `protobuf:"bytes,4,rep,name=ids,proto3" json:"ids,omitempty"`
+	// If true, the agents that can be purged are returned but not evicted.
+	DryRun        bool `protobuf:"varint,5,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchPurgeAgentsRequest) Reset() {
+	*x = BatchPurgeAgentsRequest{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchPurgeAgentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPurgeAgentsRequest) ProtoMessage() {}
+
+func (x *BatchPurgeAgentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPurgeAgentsRequest.ProtoReflect.Descriptor instead.
+func (*BatchPurgeAgentsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BatchPurgeAgentsRequest) GetExpiredFor() int64 {
+	if x != nil {
+		return x.ExpiredFor
+	}
+	return 0
+}
+
+func (x *BatchPurgeAgentsRequest) GetBySelectorMatch() *SelectorMatch {
+	if x != nil {
+		return x.BySelectorMatch
+	}
+	return nil
+}
+
+func (x *BatchPurgeAgentsRequest) GetByAttestationType() string {
+	if x != nil {
+		return x.ByAttestationType
+	}
+	return ""
+}
+
+func (x *BatchPurgeAgentsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *BatchPurgeAgentsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type BatchPurgeAgentsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The result for each agent, ordered by SPIFFE ID.
+	Results       []*AgentResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchPurgeAgentsResponse) Reset() {
+	*x = BatchPurgeAgentsResponse{}
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchPurgeAgentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchPurgeAgentsResponse) ProtoMessage() {}
+
+func (x *BatchPurgeAgentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchPurgeAgentsResponse.ProtoReflect.Descriptor instead.
+func (*BatchPurgeAgentsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchPurgeAgentsResponse) GetResults() []*AgentResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+var File_spire_api_server_agentbatch_v1_agentbatch_proto protoreflect.FileDescriptor
+
+const file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDesc = "" +
+	"\n" +
+	"/spire/api/server/agentbatch/v1/agentbatch.proto\x12\x1espire.api.server.agentbatch.v1\"4\n" +
+	"\bSelector\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\x81\x02\n" +
+	"\rSelectorMatch\x12F\n" +
+	"\tselectors\x18\x01 \x03(\v2(.spire.api.server.agentbatch.v1.SelectorR\tselectors\x12Q\n" +
+	"\x05match\x18\x02 \x01(\x0e2;.spire.api.server.agentbatch.v1.SelectorMatch.MatchBehaviorR\x05match\"U\n" +
+	"\rMatchBehavior\x12\x0f\n" +
+	"\vMATCH_EXACT\x10\x00\x12\x10\n" +
+	"\fMATCH_SUBSET\x10\x01\x12\x12\n" +
+	"\x0eMATCH_SUPERSET\x10\x02\x12\r\n" +
+	"\tMATCH_ANY\x10\x03\"\xb5\x02\n" +
+	"\vAgentFilter\x12Y\n" +
+	"\x11by_selector_match\x18\x01 \x01(\v2-.spire.api.server.agentbatch.v1.SelectorMatchR\x0fbySelectorMatch\x12.\n" +
+	"\x13by_attestation_type\x18\x02 \x01(\tR\x11byAttestationType\x12+\n" +
+	"\x0fby_can_reattest\x18\x03 \x01(\bH\x00R\rbyCanReattest\x88\x01\x01\x12 \n" +
+	"\tby_banned\x18\x04 \x01(\bH\x01R\bbyBanned\x88\x01\x01\x12*\n" +
+	"\x11by_expires_before\x18\x05 \x01(\x03R\x0fbyExpiresBeforeB\x12\n" +
+	"\x10_by_can_reattestB\f\n" +
+	"\n" +
+	"_by_banned\"\xfc\x01\n" +
+	"\vAgentResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x10attestation_type\x18\x02 \x01(\tR\x0fattestationType\x12/\n" +
+	"\x14x509_svid_expires_at\x18\x03 \x01(\x03R\x11x509SvidExpiresAt\x12\x16\n" +
+	"\x06banned\x18\x04 \x01(\bR\x06banned\x12!\n" +
+	"\fcan_reattest\x18\x05 \x01(\bR\vcanReattest\x12\x1f\n" +
+	"\vstatus_code\x18\x06 \x01(\x05R\n" +
+	"statusCode\x12%\n" +
+	"\x0estatus_message\x18\a \x01(\tR\rstatusMessage\"\x87\x01\n" +
+	"\x15BatchBanAgentsRequest\x12C\n" +
+	"\x06filter\x18\x01 \x01(\v2+.spire.api.server.agentbatch.v1.AgentFilterR\x06filter\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"_\n" +
+	"\x16BatchBanAgentsResponse\x12E\n" +
+	"\aresults\x18\x01 \x03(\v2+.spire.api.server.agentbatch.v1.AgentResultR\aresults\"\x89\x01\n" +
+	"\x17BatchEvictAgentsRequest\x12C\n" +
+	"\x06filter\x18\x01 \x01(\v2+.spire.api.server.agentbatch.v1.AgentFilterR\x06filter\x12\x10\n" +
+	"\x03ids\x18\x02 \x03(\tR\x03ids\x12\x17\n" +
+	"\adry_run\x18\x03 \x01(\bR\x06dryRun\"a\n" +
+	"\x18BatchEvictAgentsResponse\x12E\n" +
+	"\aresults\x18\x01 \x03(\v2+.spire.api.server.agentbatch.v1.AgentResultR\aresults\"\xf0\x01\n" +
+	"\x17BatchPurgeAgentsRequest\x12\x1f\n" +
+	"\vexpired_for\x18\x01 \x01(\x03R\n" +
+	"expiredFor\x12Y\n" +
+	"\x11by_selector_match\x18\x02 \x01(\v2-.spire.api.server.agentbatch.v1.SelectorMatchR\x0fbySelectorMatch\x12.\n" +
+	"\x13by_attestation_type\x18\x03 \x01(\tR\x11byAttestationType\x12\x10\n" +
+	"\x03ids\x18\x04 \x03(\tR\x03ids\x12\x17\n" +
+	"\adry_run\x18\x05 \x01(\bR\x06dryRun\"a\n" +
+	"\x18BatchPurgeAgentsResponse\x12E\n" +
+	"\aresults\x18\x01 \x03(\v2+.spire.api.server.agentbatch.v1.AgentResultR\aresults2\x9d\x03\n" +
+	"\n" +
+	"AgentBatch\x12\x7f\n" +
+	"\x0eBatchBanAgents\x125.spire.api.server.agentbatch.v1.BatchBanAgentsRequest\x1a6.spire.api.server.agentbatch.v1.BatchBanAgentsResponse\x12\x85\x01\n" +
+	"\x10BatchEvictAgents\x127.spire.api.server.agentbatch.v1.BatchEvictAgentsRequest\x1a8.spire.api.server.agentbatch.v1.BatchEvictAgentsResponse\x12\x85\x01\n" +
+	"\x10BatchPurgeAgents\x127.spire.api.server.agentbatch.v1.BatchPurgeAgentsRequest\x1a8.spire.api.server.agentbatch.v1.BatchPurgeAgentsResponseBKZIgithub.com/spiffe/spire/proto/spire/api/server/agentbatch/v1;agentbatchv1b\x06proto3"
+
+var (
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescOnce sync.Once
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescData []byte
+)
+
+func file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescGZIP() []byte {
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescOnce.Do(func() {
+		file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDesc), len(file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDesc)))
+	})
+	return file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDescData
+}
+
+var file_spire_api_server_agentbatch_v1_agentbatch_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_spire_api_server_agentbatch_v1_agentbatch_proto_goTypes = []any{
+	(SelectorMatch_MatchBehavior)(0), // 0: spire.api.server.agentbatch.v1.SelectorMatch.MatchBehavior
+	(*Selector)(nil),                 // 1: spire.api.server.agentbatch.v1.Selector
+	(*SelectorMatch)(nil),            // 2: spire.api.server.agentbatch.v1.SelectorMatch
+	(*AgentFilter)(nil),              // 3: spire.api.server.agentbatch.v1.AgentFilter
+	(*AgentResult)(nil),              // 4: spire.api.server.agentbatch.v1.AgentResult
+	(*BatchBanAgentsRequest)(nil),    // 5: spire.api.server.agentbatch.v1.BatchBanAgentsRequest
+	(*BatchBanAgentsResponse)(nil),   // 6: spire.api.server.agentbatch.v1.BatchBanAgentsResponse
+	(*BatchEvictAgentsRequest)(nil),  // 7: spire.api.server.agentbatch.v1.BatchEvictAgentsRequest
+	(*BatchEvictAgentsResponse)(nil), // 8: spire.api.server.agentbatch.v1.BatchEvictAgentsResponse
+	(*BatchPurgeAgentsRequest)(nil),  // 9: spire.api.server.agentbatch.v1.BatchPurgeAgentsRequest
+	(*BatchPurgeAgentsResponse)(nil), // 10: spire.api.server.agentbatch.v1.BatchPurgeAgentsResponse
+}
+var file_spire_api_server_agentbatch_v1_agentbatch_proto_depIdxs = []int32{
+	1,  // 0: spire.api.server.agentbatch.v1.SelectorMatch.selectors:type_name -> spire.api.server.agentbatch.v1.Selector
+	0,  // 1: spire.api.server.agentbatch.v1.SelectorMatch.match:type_name -> spire.api.server.agentbatch.v1.SelectorMatch.MatchBehavior
+	2,  // 2: spire.api.server.agentbatch.v1.AgentFilter.by_selector_match:type_name -> spire.api.server.agentbatch.v1.SelectorMatch
+	3,  // 3: spire.api.server.agentbatch.v1.BatchBanAgentsRequest.filter:type_name -> spire.api.server.agentbatch.v1.AgentFilter
+	4,  // 4: spire.api.server.agentbatch.v1.BatchBanAgentsResponse.results:type_name -> spire.api.server.agentbatch.v1.AgentResult
+	3,  // 5: spire.api.server.agentbatch.v1.BatchEvictAgentsRequest.filter:type_name -> spire.api.server.agentbatch.v1.AgentFilter
+	4,  // 6: spire.api.server.agentbatch.v1.BatchEvictAgentsResponse.results:type_name -> spire.api.server.agentbatch.v1.AgentResult
+	2,  // 7: spire.api.server.agentbatch.v1.BatchPurgeAgentsRequest.by_selector_match:type_name -> spire.api.server.agentbatch.v1.SelectorMatch
+	4,  // 8: spire.api.server.agentbatch.v1.BatchPurgeAgentsResponse.results:type_name -> spire.api.server.agentbatch.v1.AgentResult
+	5,  // 9: spire.api.server.agentbatch.v1.AgentBatch.BatchBanAgents:input_type -> spire.api.server.agentbatch.v1.BatchBanAgentsRequest
+	7,  // 10: spire.api.server.agentbatch.v1.AgentBatch.BatchEvictAgents:input_type -> spire.api.server.agentbatch.v1.BatchEvictAgentsRequest
+	9,  // 11: spire.api.server.agentbatch.v1.AgentBatch.BatchPurgeAgents:input_type -> spire.api.server.agentbatch.v1.BatchPurgeAgentsRequest
+	6,  // 12: spire.api.server.agentbatch.v1.AgentBatch.BatchBanAgents:output_type -> spire.api.server.agentbatch.v1.BatchBanAgentsResponse
+	8,  // 13: spire.api.server.agentbatch.v1.AgentBatch.BatchEvictAgents:output_type -> spire.api.server.agentbatch.v1.BatchEvictAgentsResponse
+	10, // 14: spire.api.server.agentbatch.v1.AgentBatch.BatchPurgeAgents:output_type -> spire.api.server.agentbatch.v1.BatchPurgeAgentsResponse
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_server_agentbatch_v1_agentbatch_proto_init() }
+func file_spire_api_server_agentbatch_v1_agentbatch_proto_init() {
+	if File_spire_api_server_agentbatch_v1_agentbatch_proto != nil {
+		return
+	}
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDesc), len(file_spire_api_server_agentbatch_v1_agentbatch_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_server_agentbatch_v1_agentbatch_proto_goTypes,
+		DependencyIndexes: file_spire_api_server_agentbatch_v1_agentbatch_proto_depIdxs,
+		EnumInfos:         file_spire_api_server_agentbatch_v1_agentbatch_proto_enumTypes,
+		MessageInfos:      file_spire_api_server_agentbatch_v1_agentbatch_proto_msgTypes,
+	}.Build()
+	File_spire_api_server_agentbatch_v1_agentbatch_proto = out.File
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_goTypes = nil
+	file_spire_api_server_agentbatch_v1_agentbatch_proto_depIdxs = nil
+}
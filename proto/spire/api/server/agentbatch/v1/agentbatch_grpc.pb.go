@@ -0,0 +1,195 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.29.4
+// source: spire/api/server/agentbatch/v1/agentbatch.proto
+
+package agentbatchv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AgentBatch_BatchBanAgents_FullMethodName   = "/spire.api.server.agentbatch.v1.AgentBatch/BatchBanAgents"
+	AgentBatch_BatchEvictAgents_FullMethodName = "/spire.api.server.agentbatch.v1.AgentBatch/BatchEvictAgents"
+	AgentBatch_BatchPurgeAgents_FullMethodName = "/spire.api.server.agentbatch.v1.AgentBatch/BatchPurgeAgents"
+)
+
+// AgentBatchClient is the client API for AgentBatch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AgentBatchClient interface {
+	// BatchBanAgents bans the agents matching the filter. Banned agents
+	// cannot renew their SVID nor attest again until they are evicted.
+	BatchBanAgents(ctx context.Context, in *BatchBanAgentsRequest, opts ...grpc.CallOption) (*BatchBanAgentsResponse, error)
+	// BatchEvictAgents evicts the agents matching the filter, removing them
+	// from the server. Evicted agents can attest again.
+	BatchEvictAgents(ctx context.Context, in *BatchEvictAgentsRequest, opts ...grpc.CallOption) (*BatchEvictAgentsResponse, error)
+	// BatchPurgeAgents evicts the agents that can re-attest and whose X509-SVID
+	// expired a given time ago. Agents that cannot re-attest are never purged.
+	BatchPurgeAgents(ctx context.Context, in *BatchPurgeAgentsRequest, opts ...grpc.CallOption) (*BatchPurgeAgentsResponse, error)
+}
+
+type agentBatchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentBatchClient(cc grpc.ClientConnInterface) AgentBatchClient {
+	return &agentBatchClient{cc}
+}
+
+func (c *agentBatchClient) BatchBanAgents(ctx context.Context, in *BatchBanAgentsRequest, opts ...grpc.CallOption) (*BatchBanAgentsResponse, error) {
+	out := new(BatchBanAgentsResponse)
+	err := c.cc.Invoke(ctx, AgentBatch_BatchBanAgents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentBatchClient) BatchEvictAgents(ctx context.Context, in *BatchEvictAgentsRequest, opts ...grpc.CallOption) (*BatchEvictAgentsResponse, error) {
+	out := new(BatchEvictAgentsResponse)
+	err := c.cc.Invoke(ctx, AgentBatch_BatchEvictAgents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentBatchClient) BatchPurgeAgents(ctx context.Context, in *BatchPurgeAgentsRequest, opts ...grpc.CallOption) (*BatchPurgeAgentsResponse, error) {
+	out := new(BatchPurgeAgentsResponse)
+	err := c.cc.Invoke(ctx, AgentBatch_BatchPurgeAgents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentBatchServer is the server API for AgentBatch service.
+// All implementations must embed UnimplementedAgentBatchServer
+// for forward compatibility
+type AgentBatchServer interface {
+	// BatchBanAgents bans the agents matching the filter. Banned agents
+	// cannot renew their SVID nor attest again until they are evicted.
+	BatchBanAgents(context.Context, *BatchBanAgentsRequest) (*BatchBanAgentsResponse, error)
+	// BatchEvictAgents evicts the agents matching the filter, removing them
+	// from the server. Evicted agents can attest again.
+	BatchEvictAgents(context.Context, *BatchEvictAgentsRequest) (*BatchEvictAgentsResponse, error)
+	// BatchPurgeAgents evicts the agents that can re-attest and whose X509-SVID
+	// expired a given time ago. Agents that cannot re-attest are never purged.
+	BatchPurgeAgents(context.Context, *BatchPurgeAgentsRequest) (*BatchPurgeAgentsResponse, error)
+	mustEmbedUnimplementedAgentBatchServer()
+}
+
+// UnimplementedAgentBatchServer must be embedded to have forward compatible implementations.
+type UnimplementedAgentBatchServer struct {
+}
+
+func (UnimplementedAgentBatchServer) BatchBanAgents(context.Context, *BatchBanAgentsRequest) (*BatchBanAgentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchBanAgents not implemented")
+}
+func (UnimplementedAgentBatchServer) BatchEvictAgents(context.Context, *BatchEvictAgentsRequest) (*BatchEvictAgentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchEvictAgents not implemented")
+}
+func (UnimplementedAgentBatchServer) BatchPurgeAgents(context.Context, *BatchPurgeAgentsRequest) (*BatchPurgeAgentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchPurgeAgents not implemented")
+}
+func (UnimplementedAgentBatchServer) mustEmbedUnimplementedAgentBatchServer() {}
+
+// UnsafeAgentBatchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentBatchServer will
+// result in compilation errors.
+type UnsafeAgentBatchServer interface {
+	mustEmbedUnimplementedAgentBatchServer()
+}
+
+func RegisterAgentBatchServer(s grpc.ServiceRegistrar, srv AgentBatchServer) {
+	s.RegisterService(&AgentBatch_ServiceDesc, srv)
+}
+
+func _AgentBatch_BatchBanAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchBanAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentBatchServer).BatchBanAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentBatch_BatchBanAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentBatchServer).BatchBanAgents(ctx, req.(*BatchBanAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentBatch_BatchEvictAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchEvictAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentBatchServer).BatchEvictAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentBatch_BatchEvictAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentBatchServer).BatchEvictAgents(ctx, req.(*BatchEvictAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentBatch_BatchPurgeAgents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchPurgeAgentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentBatchServer).BatchPurgeAgents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentBatch_BatchPurgeAgents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentBatchServer).BatchPurgeAgents(ctx, req.(*BatchPurgeAgentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AgentBatch_ServiceDesc is the grpc.ServiceDesc for AgentBatch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AgentBatch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.server.agentbatch.v1.AgentBatch",
+	HandlerType: (*AgentBatchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BatchBanAgents",
+			Handler:    _AgentBatch_BatchBanAgents_Handler,
+		},
+		{
+			MethodName: "BatchEvictAgents",
+			Handler:    _AgentBatch_BatchEvictAgents_Handler,
+		},
+		{
+			MethodName: "BatchPurgeAgents",
+			Handler:    _AgentBatch_BatchPurgeAgents_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spire/api/server/agentbatch/v1/agentbatch.proto",
+}
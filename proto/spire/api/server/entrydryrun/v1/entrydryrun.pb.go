@@ -0,0 +1,629 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.4
+// source: spire/api/server/entrydryrun/v1/entrydryrun.proto
+
+package entrydryrunv1
+
+import (
+	types "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Action int32
+
+const (
+	// The request for the entry would fail.
+	Action_ACTION_FAILED Action = 0
+	// The entry would be created.
+	Action_ACTION_CREATE Action = 1
+	// The entry would be updated.
+	Action_ACTION_UPDATE Action = 2
+	// The entry would be left unchanged, either because a similar entry
+	// already exists or because the update does not change any field.
+	Action_ACTION_UNCHANGED Action = 3
+)
+
+// Enum value maps for Action.
+var (
+	Action_name = map[int32]string{
+		0: "ACTION_FAILED",
+		1: "ACTION_CREATE",
+		2: "ACTION_UPDATE",
+		3: "ACTION_UNCHANGED",
+	}
+	Action_value = map[string]int32{
+		"ACTION_FAILED":    0,
+		"ACTION_CREATE":    1,
+		"ACTION_UPDATE":    2,
+		"ACTION_UNCHANGED": 3,
+	}
+)
+
+func (x Action) Enum() *Action {
+	p := new(Action)
+	*p = x
+	return p
+}
+
+func (x Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_enumTypes[0].Descriptor()
+}
+
+func (Action) Type() protoreflect.EnumType {
+	return &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_enumTypes[0]
+}
+
+func (x Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Action.Descriptor instead.
+func (Action) EnumDescriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{0}
+}
+
+type FieldDiff struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the entry field (e.g. "spiffe_id").
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// The value of the field before the change.
+	Old string `protobuf:"bytes,2,opt,name=old,proto3" json:"old,omitempty"`
+	// The value of the field after the change.
+	New           string `protobuf:"bytes,3,opt,name=new,proto3" json:"new,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldDiff) Reset() {
+	*x = FieldDiff{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldDiff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldDiff) ProtoMessage() {}
+
+func (x *FieldDiff) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldDiff.ProtoReflect.Descriptor instead.
+func (*FieldDiff) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FieldDiff) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldDiff) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *FieldDiff) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
+}
+
+type EntryResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The status of the request for the entry, as the Entry service would
+	// return it.
+	Status *types.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// The action that the request would take on the entry.
+	Action Action `protobuf:"varint,2,opt,name=action,proto3,enum=spire.api.server.entrydryrun.v1.Action" json:"action,omitempty"`
+	// The ID of the entry. It is empty for entries that would be created
+	// with a server generated ID.
+	EntryId string `protobuf:"bytes,3,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	// The SPIFFE ID of the entry.
+	SpiffeId string `protobuf:"bytes,4,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The fields that would change. Created entries are compared against an
+	// empty entry.
+	Diffs         []*FieldDiff `protobuf:"bytes,5,rep,name=diffs,proto3" json:"diffs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryResult) Reset() {
+	*x = EntryResult{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryResult) ProtoMessage() {}
+
+func (x *EntryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryResult.ProtoReflect.Descriptor instead.
+func (*EntryResult) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EntryResult) GetStatus() *types.Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *EntryResult) GetAction() Action {
+	if x != nil {
+		return x.Action
+	}
+	return Action_ACTION_FAILED
+}
+
+func (x *EntryResult) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *EntryResult) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *EntryResult) GetDiffs() []*FieldDiff {
+	if x != nil {
+		return x.Diffs
+	}
+	return nil
+}
+
+type AuthorizationChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The ID of the entry. It is empty for entries that would be created
+	// with a server generated ID.
+	EntryId string `protobuf:"bytes,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	// The SPIFFE ID of the entry.
+	SpiffeId string `protobuf:"bytes,2,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	// The SPIFFE IDs of the agents that would gain authorization for the
+	// entry.
+	AgentsGained []string `protobuf:"bytes,3,rep,name=agents_gained,json=agentsGained,proto3" json:"agents_gained,omitempty"`
+	// The SPIFFE IDs of the agents that would lose authorization for the
+	// entry.
+	AgentsLost    []string `protobuf:"bytes,4,rep,name=agents_lost,json=agentsLost,proto3" json:"agents_lost,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AuthorizationChange) Reset() {
+	*x = AuthorizationChange{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AuthorizationChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthorizationChange) ProtoMessage() {}
+
+func (x *AuthorizationChange) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthorizationChange.ProtoReflect.Descriptor instead.
+func (*AuthorizationChange) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AuthorizationChange) GetEntryId() string {
+	if x != nil {
+		return x.EntryId
+	}
+	return ""
+}
+
+func (x *AuthorizationChange) GetSpiffeId() string {
+	if x != nil {
+		return x.SpiffeId
+	}
+	return ""
+}
+
+func (x *AuthorizationChange) GetAgentsGained() []string {
+	if x != nil {
+		return x.AgentsGained
+	}
+	return nil
+}
+
+func (x *AuthorizationChange) GetAgentsLost() []string {
+	if x != nil {
+		return x.AgentsLost
+	}
+	return nil
+}
+
+type DryRunBatchCreateEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The entries to be created, as for BatchCreateEntry.
+	Entries       []*types.Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DryRunBatchCreateEntryRequest) Reset() {
+	*x = DryRunBatchCreateEntryRequest{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunBatchCreateEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunBatchCreateEntryRequest) ProtoMessage() {}
+
+func (x *DryRunBatchCreateEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunBatchCreateEntryRequest.ProtoReflect.Descriptor instead.
+func (*DryRunBatchCreateEntryRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *DryRunBatchCreateEntryRequest) GetEntries() []*types.Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type DryRunBatchCreateEntryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The result for each entry, in request order.
+	Results []*EntryResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// The entries that currently attested agents would gain or lose
+	// authorization for. This includes entries that are not part of the
+	// request but descend from one that is.
+	AuthorizationChanges []*AuthorizationChange `protobuf:"bytes,2,rep,name=authorization_changes,json=authorizationChanges,proto3" json:"authorization_changes,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DryRunBatchCreateEntryResponse) Reset() {
+	*x = DryRunBatchCreateEntryResponse{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunBatchCreateEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunBatchCreateEntryResponse) ProtoMessage() {}
+
+func (x *DryRunBatchCreateEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunBatchCreateEntryResponse.ProtoReflect.Descriptor instead.
+func (*DryRunBatchCreateEntryResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DryRunBatchCreateEntryResponse) GetResults() []*EntryResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *DryRunBatchCreateEntryResponse) GetAuthorizationChanges() []*AuthorizationChange {
+	if x != nil {
+		return x.AuthorizationChanges
+	}
+	return nil
+}
+
+type DryRunBatchUpdateEntryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The entries to be updated, as for BatchUpdateEntry.
+	Entries []*types.Entry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	// An input mask, as for BatchUpdateEntry.
+	InputMask     *types.EntryMask `protobuf:"bytes,2,opt,name=input_mask,json=inputMask,proto3" json:"input_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DryRunBatchUpdateEntryRequest) Reset() {
+	*x = DryRunBatchUpdateEntryRequest{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunBatchUpdateEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunBatchUpdateEntryRequest) ProtoMessage() {}
+
+func (x *DryRunBatchUpdateEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunBatchUpdateEntryRequest.ProtoReflect.Descriptor instead.
+func (*DryRunBatchUpdateEntryRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DryRunBatchUpdateEntryRequest) GetEntries() []*types.Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *DryRunBatchUpdateEntryRequest) GetInputMask() *types.EntryMask {
+	if x != nil {
+		return x.InputMask
+	}
+	return nil
+}
+
+type DryRunBatchUpdateEntryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The result for each entry, in request order.
+	Results []*EntryResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// The entries that currently attested agents would gain or lose
+	// authorization for. This includes entries that are not part of the
+	// request but descend from one that is.
+	AuthorizationChanges []*AuthorizationChange `protobuf:"bytes,2,rep,name=authorization_changes,json=authorizationChanges,proto3" json:"authorization_changes,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *DryRunBatchUpdateEntryResponse) Reset() {
+	*x = DryRunBatchUpdateEntryResponse{}
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DryRunBatchUpdateEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DryRunBatchUpdateEntryResponse) ProtoMessage() {}
+
+func (x *DryRunBatchUpdateEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DryRunBatchUpdateEntryResponse.ProtoReflect.Descriptor instead.
+func (*DryRunBatchUpdateEntryResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DryRunBatchUpdateEntryResponse) GetResults() []*EntryResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *DryRunBatchUpdateEntryResponse) GetAuthorizationChanges() []*AuthorizationChange {
+	if x != nil {
+		return x.AuthorizationChanges
+	}
+	return nil
+}
+
+var File_spire_api_server_entrydryrun_v1_entrydryrun_proto protoreflect.FileDescriptor
+
+const file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDesc = "" +
+	"\n" +
+	"1spire/api/server/entrydryrun/v1/entrydryrun.proto\x12\x1fspire.api.server.entrydryrun.v1\x1a\x1bspire/api/types/entry.proto\x1a\x1cspire/api/types/status.proto\"E\n" +
+	"\tFieldDiff\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x10\n" +
+	"\x03old\x18\x02 \x01(\tR\x03old\x12\x10\n" +
+	"\x03new\x18\x03 \x01(\tR\x03new\"\xf9\x01\n" +
+	"\vEntryResult\x12/\n" +
+	"\x06status\x18\x01 \x01(\v2\x17.spire.api.types.StatusR\x06status\x12?\n" +
+	"\x06action\x18\x02 \x01(\x0e2'.spire.api.server.entrydryrun.v1.ActionR\x06action\x12\x19\n" +
+	"\bentry_id\x18\x03 \x01(\tR\aentryId\x12\x1b\n" +
+	"\tspiffe_id\x18\x04 \x01(\tR\bspiffeId\x12@\n" +
+	"\x05diffs\x18\x05 \x03(\v2*.spire.api.server.entrydryrun.v1.FieldDiffR\x05diffs\"\x93\x01\n" +
+	"\x13AuthorizationChange\x12\x19\n" +
+	"\bentry_id\x18\x01 \x01(\tR\aentryId\x12\x1b\n" +
+	"\tspiffe_id\x18\x02 \x01(\tR\bspiffeId\x12#\n" +
+	"\ragents_gained\x18\x03 \x03(\tR\fagentsGained\x12\x1f\n" +
+	"\vagents_lost\x18\x04 \x03(\tR\n" +
+	"agentsLost\"Q\n" +
+	"\x1dDryRunBatchCreateEntryRequest\x120\n" +
+	"\aentries\x18\x01 \x03(\v2\x16.spire.api.types.EntryR\aentries\"\xd3\x01\n" +
+	"\x1eDryRunBatchCreateEntryResponse\x12F\n" +
+	"\aresults\x18\x01 \x03(\v2,.spire.api.server.entrydryrun.v1.EntryResultR\aresults\x12i\n" +
+	"\x15authorization_changes\x18\x02 \x03(\v24.spire.api.server.entrydryrun.v1.AuthorizationChangeR\x14authorizationChanges\"\x8c\x01\n" +
+	"\x1dDryRunBatchUpdateEntryRequest\x120\n" +
+	"\aentries\x18\x01 \x03(\v2\x16.spire.api.types.EntryR\aentries\x129\n" +
+	"\n" +
+	"input_mask\x18\x02 \x01(\v2\x1a.spire.api.types.EntryMaskR\tinputMask\"\xd3\x01\n" +
+	"\x1eDryRunBatchUpdateEntryResponse\x12F\n" +
+	"\aresults\x18\x01 \x03(\v2,.spire.api.server.entrydryrun.v1.EntryResultR\aresults\x12i\n" +
+	"\x15authorization_changes\x18\x02 \x03(\v24.spire.api.server.entrydryrun.v1.AuthorizationChangeR\x14authorizationChanges*W\n" +
+	"\x06Action\x12\x11\n" +
+	"\rACTION_FAILED\x10\x00\x12\x11\n" +
+	"\rACTION_CREATE\x10\x01\x12\x11\n" +
+	"\rACTION_UPDATE\x10\x02\x12\x14\n" +
+	"\x10ACTION_UNCHANGED\x10\x032\xc5\x02\n" +
+	"\vEntryDryRun\x12\x99\x01\n" +
+	"\x16DryRunBatchCreateEntry\x12>.spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryRequest\x1a?.spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryResponse\x12\x99\x01\n" +
+	"\x16DryRunBatchUpdateEntry\x12>.spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryRequest\x1a?.spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryResponseBMZKgithub.com/spiffe/spire/proto/spire/api/server/entrydryrun/v1;entrydryrunv1b\x06proto3"
+
+var (
+	file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescOnce sync.Once
+	file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescData []byte
+)
+
+func file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescGZIP() []byte {
+	file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescOnce.Do(func() {
+		file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDesc), len(file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDesc)))
+	})
+	return file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDescData
+}
+
+var file_spire_api_server_entrydryrun_v1_entrydryrun_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_spire_api_server_entrydryrun_v1_entrydryrun_proto_goTypes = []any{
+	(Action)(0),                            // 0: spire.api.server.entrydryrun.v1.Action
+	(*FieldDiff)(nil),                      // 1: spire.api.server.entrydryrun.v1.FieldDiff
+	(*EntryResult)(nil),                    // 2: spire.api.server.entrydryrun.v1.EntryResult
+	(*AuthorizationChange)(nil),            // 3: spire.api.server.entrydryrun.v1.AuthorizationChange
+	(*DryRunBatchCreateEntryRequest)(nil),  // 4: spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryRequest
+	(*DryRunBatchCreateEntryResponse)(nil), // 5: spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryResponse
+	(*DryRunBatchUpdateEntryRequest)(nil),  // 6: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryRequest
+	(*DryRunBatchUpdateEntryResponse)(nil), // 7: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryResponse
+	(*types.Status)(nil),                   // 8: spire.api.types.Status
+	(*types.Entry)(nil),                    // 9: spire.api.types.Entry
+	(*types.EntryMask)(nil),                // 10: spire.api.types.EntryMask
+}
+var file_spire_api_server_entrydryrun_v1_entrydryrun_proto_depIdxs = []int32{
+	8,  // 0: spire.api.server.entrydryrun.v1.EntryResult.status:type_name -> spire.api.types.Status
+	0,  // 1: spire.api.server.entrydryrun.v1.EntryResult.action:type_name -> spire.api.server.entrydryrun.v1.Action
+	1,  // 2: spire.api.server.entrydryrun.v1.EntryResult.diffs:type_name -> spire.api.server.entrydryrun.v1.FieldDiff
+	9,  // 3: spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryRequest.entries:type_name -> spire.api.types.Entry
+	2,  // 4: spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryResponse.results:type_name -> spire.api.server.entrydryrun.v1.EntryResult
+	3,  // 5: spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryResponse.authorization_changes:type_name -> spire.api.server.entrydryrun.v1.AuthorizationChange
+	9,  // 6: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryRequest.entries:type_name -> spire.api.types.Entry
+	10, // 7: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryRequest.input_mask:type_name -> spire.api.types.EntryMask
+	2,  // 8: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryResponse.results:type_name -> spire.api.server.entrydryrun.v1.EntryResult
+	3,  // 9: spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryResponse.authorization_changes:type_name -> spire.api.server.entrydryrun.v1.AuthorizationChange
+	4,  // 10: spire.api.server.entrydryrun.v1.EntryDryRun.DryRunBatchCreateEntry:input_type -> spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryRequest
+	6,  // 11: spire.api.server.entrydryrun.v1.EntryDryRun.DryRunBatchUpdateEntry:input_type -> spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryRequest
+	5,  // 12: spire.api.server.entrydryrun.v1.EntryDryRun.DryRunBatchCreateEntry:output_type -> spire.api.server.entrydryrun.v1.DryRunBatchCreateEntryResponse
+	7,  // 13: spire.api.server.entrydryrun.v1.EntryDryRun.DryRunBatchUpdateEntry:output_type -> spire.api.server.entrydryrun.v1.DryRunBatchUpdateEntryResponse
+	12, // [12:14] is the sub-list for method output_type
+	10, // [10:12] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_server_entrydryrun_v1_entrydryrun_proto_init() }
+func file_spire_api_server_entrydryrun_v1_entrydryrun_proto_init() {
+	if File_spire_api_server_entrydryrun_v1_entrydryrun_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDesc), len(file_spire_api_server_entrydryrun_v1_entrydryrun_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_server_entrydryrun_v1_entrydryrun_proto_goTypes,
+		DependencyIndexes: file_spire_api_server_entrydryrun_v1_entrydryrun_proto_depIdxs,
+		EnumInfos:         file_spire_api_server_entrydryrun_v1_entrydryrun_proto_enumTypes,
+		MessageInfos:      file_spire_api_server_entrydryrun_v1_entrydryrun_proto_msgTypes,
+	}.Build()
+	File_spire_api_server_entrydryrun_v1_entrydryrun_proto = out.File
+	file_spire_api_server_entrydryrun_v1_entrydryrun_proto_goTypes = nil
+	file_spire_api_server_entrydryrun_v1_entrydryrun_proto_depIdxs = nil
+}
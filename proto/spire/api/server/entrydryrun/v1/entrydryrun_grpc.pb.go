@@ -0,0 +1,154 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.29.4
+// source: spire/api/server/entrydryrun/v1/entrydryrun.proto
+
+package entrydryrunv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	EntryDryRun_DryRunBatchCreateEntry_FullMethodName = "/spire.api.server.entrydryrun.v1.EntryDryRun/DryRunBatchCreateEntry"
+	EntryDryRun_DryRunBatchUpdateEntry_FullMethodName = "/spire.api.server.entrydryrun.v1.EntryDryRun/DryRunBatchUpdateEntry"
+)
+
+// EntryDryRunClient is the client API for EntryDryRun service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EntryDryRunClient interface {
+	// DryRunBatchCreateEntry reports the changes that BatchCreateEntry would
+	// make for the given entries.
+	DryRunBatchCreateEntry(ctx context.Context, in *DryRunBatchCreateEntryRequest, opts ...grpc.CallOption) (*DryRunBatchCreateEntryResponse, error)
+	// DryRunBatchUpdateEntry reports the changes that BatchUpdateEntry would
+	// make for the given entries.
+	DryRunBatchUpdateEntry(ctx context.Context, in *DryRunBatchUpdateEntryRequest, opts ...grpc.CallOption) (*DryRunBatchUpdateEntryResponse, error)
+}
+
+type entryDryRunClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEntryDryRunClient(cc grpc.ClientConnInterface) EntryDryRunClient {
+	return &entryDryRunClient{cc}
+}
+
+func (c *entryDryRunClient) DryRunBatchCreateEntry(ctx context.Context, in *DryRunBatchCreateEntryRequest, opts ...grpc.CallOption) (*DryRunBatchCreateEntryResponse, error) {
+	out := new(DryRunBatchCreateEntryResponse)
+	err := c.cc.Invoke(ctx, EntryDryRun_DryRunBatchCreateEntry_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *entryDryRunClient) DryRunBatchUpdateEntry(ctx context.Context, in *DryRunBatchUpdateEntryRequest, opts ...grpc.CallOption) (*DryRunBatchUpdateEntryResponse, error) {
+	out := new(DryRunBatchUpdateEntryResponse)
+	err := c.cc.Invoke(ctx, EntryDryRun_DryRunBatchUpdateEntry_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EntryDryRunServer is the server API for EntryDryRun service.
+// All implementations must embed UnimplementedEntryDryRunServer
+// for forward compatibility
+type EntryDryRunServer interface {
+	// DryRunBatchCreateEntry reports the changes that BatchCreateEntry would
+	// make for the given entries.
+	DryRunBatchCreateEntry(context.Context, *DryRunBatchCreateEntryRequest) (*DryRunBatchCreateEntryResponse, error)
+	// DryRunBatchUpdateEntry reports the changes that BatchUpdateEntry would
+	// make for the given entries.
+	DryRunBatchUpdateEntry(context.Context, *DryRunBatchUpdateEntryRequest) (*DryRunBatchUpdateEntryResponse, error)
+	mustEmbedUnimplementedEntryDryRunServer()
+}
+
+// UnimplementedEntryDryRunServer must be embedded to have forward compatible implementations.
+type UnimplementedEntryDryRunServer struct {
+}
+
+func (UnimplementedEntryDryRunServer) DryRunBatchCreateEntry(context.Context, *DryRunBatchCreateEntryRequest) (*DryRunBatchCreateEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DryRunBatchCreateEntry not implemented")
+}
+func (UnimplementedEntryDryRunServer) DryRunBatchUpdateEntry(context.Context, *DryRunBatchUpdateEntryRequest) (*DryRunBatchUpdateEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DryRunBatchUpdateEntry not implemented")
+}
+func (UnimplementedEntryDryRunServer) mustEmbedUnimplementedEntryDryRunServer() {}
+
+// UnsafeEntryDryRunServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EntryDryRunServer will
+// result in compilation errors.
+type UnsafeEntryDryRunServer interface {
+	mustEmbedUnimplementedEntryDryRunServer()
+}
+
+func RegisterEntryDryRunServer(s grpc.ServiceRegistrar, srv EntryDryRunServer) {
+	s.RegisterService(&EntryDryRun_ServiceDesc, srv)
+}
+
+func _EntryDryRun_DryRunBatchCreateEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DryRunBatchCreateEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryDryRunServer).DryRunBatchCreateEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntryDryRun_DryRunBatchCreateEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryDryRunServer).DryRunBatchCreateEntry(ctx, req.(*DryRunBatchCreateEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EntryDryRun_DryRunBatchUpdateEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DryRunBatchUpdateEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EntryDryRunServer).DryRunBatchUpdateEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EntryDryRun_DryRunBatchUpdateEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EntryDryRunServer).DryRunBatchUpdateEntry(ctx, req.(*DryRunBatchUpdateEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EntryDryRun_ServiceDesc is the grpc.ServiceDesc for EntryDryRun service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EntryDryRun_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.server.entrydryrun.v1.EntryDryRun",
+	HandlerType: (*EntryDryRunServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DryRunBatchCreateEntry",
+			Handler:    _EntryDryRun_DryRunBatchCreateEntry_Handler,
+		},
+		{
+			MethodName: "DryRunBatchUpdateEntry",
+			Handler:    _EntryDryRun_DryRunBatchUpdateEntry_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spire/api/server/entrydryrun/v1/entrydryrun.proto",
+}
@@ -0,0 +1,361 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.4
+// source: spire/api/server/svidrevocation/v1/svidrevocation.proto
+
+package svidrevocationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RevokedX509SVID struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The serial number of the X509-SVID, in lowercase hexadecimal.
+	SerialNumber string `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	// When the X509-SVID was revoked (seconds since Unix epoch).
+	RevokedAt int64 `protobuf:"varint,2,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+	// When the revocation can be forgotten because no X.509 authority that
+	// could have issued the X509-SVID is valid anymore (seconds since Unix
+	// epoch).
+	ExpiresAt int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// The authority ID of the X.509 authority that issued the X509-SVID.
+	AuthorityId   string `protobuf:"bytes,4,opt,name=authority_id,json=authorityId,proto3" json:"authority_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokedX509SVID) Reset() {
+	*x = RevokedX509SVID{}
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokedX509SVID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokedX509SVID) ProtoMessage() {}
+
+func (x *RevokedX509SVID) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokedX509SVID.ProtoReflect.Descriptor instead.
+func (*RevokedX509SVID) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RevokedX509SVID) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *RevokedX509SVID) GetRevokedAt() int64 {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return 0
+}
+
+func (x *RevokedX509SVID) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *RevokedX509SVID) GetAuthorityId() string {
+	if x != nil {
+		return x.AuthorityId
+	}
+	return ""
+}
+
+type RevokeX509SVIDRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required. The serial number of the X509-SVID to revoke, in
+	// hexadecimal. Colon separators (e.g. "4a:3b:01") are accepted.
+	SerialNumber string `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	// Required. The authority ID of the X.509 authority that issued the
+	// X509-SVID, i.e. the authority key identifier of the X509-SVID, in
+	// hexadecimal. Colon separators (e.g. "4a:3b:01") are accepted.
+	AuthorityId   string `protobuf:"bytes,2,opt,name=authority_id,json=authorityId,proto3" json:"authority_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeX509SVIDRequest) Reset() {
+	*x = RevokeX509SVIDRequest{}
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeX509SVIDRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeX509SVIDRequest) ProtoMessage() {}
+
+func (x *RevokeX509SVIDRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeX509SVIDRequest.ProtoReflect.Descriptor instead.
+func (*RevokeX509SVIDRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RevokeX509SVIDRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *RevokeX509SVIDRequest) GetAuthorityId() string {
+	if x != nil {
+		return x.AuthorityId
+	}
+	return ""
+}
+
+type RevokeX509SVIDResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The revoked X509-SVID.
+	RevokedX509Svid *RevokedX509SVID `protobuf:"bytes,1,opt,name=revoked_x509_svid,json=revokedX509Svid,proto3" json:"revoked_x509_svid,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RevokeX509SVIDResponse) Reset() {
+	*x = RevokeX509SVIDResponse{}
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeX509SVIDResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeX509SVIDResponse) ProtoMessage() {}
+
+func (x *RevokeX509SVIDResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeX509SVIDResponse.ProtoReflect.Descriptor instead.
+func (*RevokeX509SVIDResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RevokeX509SVIDResponse) GetRevokedX509Svid() *RevokedX509SVID {
+	if x != nil {
+		return x.RevokedX509Svid
+	}
+	return nil
+}
+
+type ListRevokedX509SVIDsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRevokedX509SVIDsRequest) Reset() {
+	*x = ListRevokedX509SVIDsRequest{}
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevokedX509SVIDsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevokedX509SVIDsRequest) ProtoMessage() {}
+
+func (x *ListRevokedX509SVIDsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevokedX509SVIDsRequest.ProtoReflect.Descriptor instead.
+func (*ListRevokedX509SVIDsRequest) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP(), []int{3}
+}
+
+type ListRevokedX509SVIDsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The revoked X509-SVIDs, ordered by authority ID and serial number.
+	RevokedX509Svids []*RevokedX509SVID `protobuf:"bytes,1,rep,name=revoked_x509_svids,json=revokedX509Svids,proto3" json:"revoked_x509_svids,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ListRevokedX509SVIDsResponse) Reset() {
+	*x = ListRevokedX509SVIDsResponse{}
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRevokedX509SVIDsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRevokedX509SVIDsResponse) ProtoMessage() {}
+
+func (x *ListRevokedX509SVIDsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRevokedX509SVIDsResponse.ProtoReflect.Descriptor instead.
+func (*ListRevokedX509SVIDsResponse) Descriptor() ([]byte, []int) {
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListRevokedX509SVIDsResponse) GetRevokedX509Svids() []*RevokedX509SVID {
+	if x != nil {
+		return x.RevokedX509Svids
+	}
+	return nil
+}
+
+var File_spire_api_server_svidrevocation_v1_svidrevocation_proto protoreflect.FileDescriptor
+
+const file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDesc = "" +
+	"\n" +
+	"7spire/api/server/svidrevocation/v1/svidrevocation.proto\x12\"spire.api.server.svidrevocation.v1\"\x97\x01\n" +
+	"\x0fRevokedX509SVID\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x1d\n" +
+	"\n" +
+	"revoked_at\x18\x02 \x01(\x03R\trevokedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12!\n" +
+	"\fauthority_id\x18\x04 \x01(\tR\vauthorityId\"_\n" +
+	"\x15RevokeX509SVIDRequest\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12!\n" +
+	"\fauthority_id\x18\x02 \x01(\tR\vauthorityId\"y\n" +
+	"\x16RevokeX509SVIDResponse\x12_\n" +
+	"\x11revoked_x509_svid\x18\x01 \x01(\v23.spire.api.server.svidrevocation.v1.RevokedX509SVIDR\x0frevokedX509Svid\"\x1d\n" +
+	"\x1bListRevokedX509SVIDsRequest\"\x81\x01\n" +
+	"\x1cListRevokedX509SVIDsResponse\x12a\n" +
+	"\x12revoked_x509_svids\x18\x01 \x03(\v23.spire.api.server.svidrevocation.v1.RevokedX509SVIDR\x10revokedX509Svids2\xb6\x02\n" +
+	"\x0eSVIDRevocation\x12\x87\x01\n" +
+	"\x0eRevokeX509SVID\x129.spire.api.server.svidrevocation.v1.RevokeX509SVIDRequest\x1a:.spire.api.server.svidrevocation.v1.RevokeX509SVIDResponse\x12\x99\x01\n" +
+	"\x14ListRevokedX509SVIDs\x12?.spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsRequest\x1a@.spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsResponseBSZQgithub.com/spiffe/spire/proto/spire/api/server/svidrevocation/v1;svidrevocationv1b\x06proto3"
+
+var (
+	file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescOnce sync.Once
+	file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescData []byte
+)
+
+func file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescGZIP() []byte {
+	file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescOnce.Do(func() {
+		file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDesc), len(file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDesc)))
+	})
+	return file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDescData
+}
+
+var file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_spire_api_server_svidrevocation_v1_svidrevocation_proto_goTypes = []any{
+	(*RevokedX509SVID)(nil),              // 0: spire.api.server.svidrevocation.v1.RevokedX509SVID
+	(*RevokeX509SVIDRequest)(nil),        // 1: spire.api.server.svidrevocation.v1.RevokeX509SVIDRequest
+	(*RevokeX509SVIDResponse)(nil),       // 2: spire.api.server.svidrevocation.v1.RevokeX509SVIDResponse
+	(*ListRevokedX509SVIDsRequest)(nil),  // 3: spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsRequest
+	(*ListRevokedX509SVIDsResponse)(nil), // 4: spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsResponse
+}
+var file_spire_api_server_svidrevocation_v1_svidrevocation_proto_depIdxs = []int32{
+	0, // 0: spire.api.server.svidrevocation.v1.RevokeX509SVIDResponse.revoked_x509_svid:type_name -> spire.api.server.svidrevocation.v1.RevokedX509SVID
+	0, // 1: spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsResponse.revoked_x509_svids:type_name -> spire.api.server.svidrevocation.v1.RevokedX509SVID
+	1, // 2: spire.api.server.svidrevocation.v1.SVIDRevocation.RevokeX509SVID:input_type -> spire.api.server.svidrevocation.v1.RevokeX509SVIDRequest
+	3, // 3: spire.api.server.svidrevocation.v1.SVIDRevocation.ListRevokedX509SVIDs:input_type -> spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsRequest
+	2, // 4: spire.api.server.svidrevocation.v1.SVIDRevocation.RevokeX509SVID:output_type -> spire.api.server.svidrevocation.v1.RevokeX509SVIDResponse
+	4, // 5: spire.api.server.svidrevocation.v1.SVIDRevocation.ListRevokedX509SVIDs:output_type -> spire.api.server.svidrevocation.v1.ListRevokedX509SVIDsResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_spire_api_server_svidrevocation_v1_svidrevocation_proto_init() }
+func file_spire_api_server_svidrevocation_v1_svidrevocation_proto_init() {
+	if File_spire_api_server_svidrevocation_v1_svidrevocation_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDesc), len(file_spire_api_server_svidrevocation_v1_svidrevocation_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spire_api_server_svidrevocation_v1_svidrevocation_proto_goTypes,
+		DependencyIndexes: file_spire_api_server_svidrevocation_v1_svidrevocation_proto_depIdxs,
+		MessageInfos:      file_spire_api_server_svidrevocation_v1_svidrevocation_proto_msgTypes,
+	}.Build()
+	File_spire_api_server_svidrevocation_v1_svidrevocation_proto = out.File
+	file_spire_api_server_svidrevocation_v1_svidrevocation_proto_goTypes = nil
+	file_spire_api_server_svidrevocation_v1_svidrevocation_proto_depIdxs = nil
+}
@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v5.29.4
+// source: spire/api/server/svidrevocation/v1/svidrevocation.proto
+
+package svidrevocationv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	SVIDRevocation_RevokeX509SVID_FullMethodName       = "/spire.api.server.svidrevocation.v1.SVIDRevocation/RevokeX509SVID"
+	SVIDRevocation_ListRevokedX509SVIDs_FullMethodName = "/spire.api.server.svidrevocation.v1.SVIDRevocation/ListRevokedX509SVIDs"
+)
+
+// SVIDRevocationClient is the client API for SVIDRevocation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SVIDRevocationClient interface {
+	// RevokeX509SVID revokes the X509-SVID issued by the given X.509
+	// authority with the given serial number. The revocation is only
+	// published by that authority, and is recorded until every X.509
+	// authority in the bundle that could have issued the X509-SVID has
+	// expired.
+	//
+	// If the X509-SVID is already revoked, an AlreadyExists error will be
+	// returned.
+	RevokeX509SVID(ctx context.Context, in *RevokeX509SVIDRequest, opts ...grpc.CallOption) (*RevokeX509SVIDResponse, error)
+	// ListRevokedX509SVIDs lists the revoked X509-SVIDs.
+	ListRevokedX509SVIDs(ctx context.Context, in *ListRevokedX509SVIDsRequest, opts ...grpc.CallOption) (*ListRevokedX509SVIDsResponse, error)
+}
+
+type sVIDRevocationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSVIDRevocationClient(cc grpc.ClientConnInterface) SVIDRevocationClient {
+	return &sVIDRevocationClient{cc}
+}
+
+func (c *sVIDRevocationClient) RevokeX509SVID(ctx context.Context, in *RevokeX509SVIDRequest, opts ...grpc.CallOption) (*RevokeX509SVIDResponse, error) {
+	out := new(RevokeX509SVIDResponse)
+	err := c.cc.Invoke(ctx, SVIDRevocation_RevokeX509SVID_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sVIDRevocationClient) ListRevokedX509SVIDs(ctx context.Context, in *ListRevokedX509SVIDsRequest, opts ...grpc.CallOption) (*ListRevokedX509SVIDsResponse, error) {
+	out := new(ListRevokedX509SVIDsResponse)
+	err := c.cc.Invoke(ctx, SVIDRevocation_ListRevokedX509SVIDs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SVIDRevocationServer is the server API for SVIDRevocation service.
+// All implementations must embed UnimplementedSVIDRevocationServer
+// for forward compatibility
+type SVIDRevocationServer interface {
+	// RevokeX509SVID revokes the X509-SVID issued by the given X.509
+	// authority with the given serial number. The revocation is only
+	// published by that authority, and is recorded until every X.509
+	// authority in the bundle that could have issued the X509-SVID has
+	// expired.
+	//
+	// If the X509-SVID is already revoked, an AlreadyExists error will be
+	// returned.
+	RevokeX509SVID(context.Context, *RevokeX509SVIDRequest) (*RevokeX509SVIDResponse, error)
+	// ListRevokedX509SVIDs lists the revoked X509-SVIDs.
+	ListRevokedX509SVIDs(context.Context, *ListRevokedX509SVIDsRequest) (*ListRevokedX509SVIDsResponse, error)
+	mustEmbedUnimplementedSVIDRevocationServer()
+}
+
+// UnimplementedSVIDRevocationServer must be embedded to have forward compatible implementations.
+type UnimplementedSVIDRevocationServer struct {
+}
+
+func (UnimplementedSVIDRevocationServer) RevokeX509SVID(context.Context, *RevokeX509SVIDRequest) (*RevokeX509SVIDResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeX509SVID not implemented")
+}
+func (UnimplementedSVIDRevocationServer) ListRevokedX509SVIDs(context.Context, *ListRevokedX509SVIDsRequest) (*ListRevokedX509SVIDsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRevokedX509SVIDs not implemented")
+}
+func (UnimplementedSVIDRevocationServer) mustEmbedUnimplementedSVIDRevocationServer() {}
+
+// UnsafeSVIDRevocationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SVIDRevocationServer will
+// result in compilation errors.
+type UnsafeSVIDRevocationServer interface {
+	mustEmbedUnimplementedSVIDRevocationServer()
+}
+
+func RegisterSVIDRevocationServer(s grpc.ServiceRegistrar, srv SVIDRevocationServer) {
+	s.RegisterService(&SVIDRevocation_ServiceDesc, srv)
+}
+
+func _SVIDRevocation_RevokeX509SVID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeX509SVIDRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SVIDRevocationServer).RevokeX509SVID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SVIDRevocation_RevokeX509SVID_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SVIDRevocationServer).RevokeX509SVID(ctx, req.(*RevokeX509SVIDRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SVIDRevocation_ListRevokedX509SVIDs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRevokedX509SVIDsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SVIDRevocationServer).ListRevokedX509SVIDs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SVIDRevocation_ListRevokedX509SVIDs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SVIDRevocationServer).ListRevokedX509SVIDs(ctx, req.(*ListRevokedX509SVIDsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SVIDRevocation_ServiceDesc is the grpc.ServiceDesc for SVIDRevocation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SVIDRevocation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spire.api.server.svidrevocation.v1.SVIDRevocation",
+	HandlerType: (*SVIDRevocationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RevokeX509SVID",
+			Handler:    _SVIDRevocation_RevokeX509SVID_Handler,
+		},
+		{
+			MethodName: "ListRevokedX509SVIDs",
+			Handler:    _SVIDRevocation_ListRevokedX509SVIDs_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spire/api/server/svidrevocation/v1/svidrevocation.proto",
+}
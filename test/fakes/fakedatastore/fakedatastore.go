@@ -375,6 +375,13 @@ func (s *DataStore) FetchJoinToken(ctx context.Context, token string) (*datastor
 	return s.ds.FetchJoinToken(ctx, token)
 }
 
+func (s *DataStore) ListJoinTokens(ctx context.Context) ([]*datastore.JoinToken, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListJoinTokens(ctx)
+}
+
 func (s *DataStore) DeleteJoinToken(ctx context.Context, token string) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -431,11 +438,11 @@ func (s *DataStore) FetchCAJournal(ctx context.Context, activeX509AuthorityID st
 	return s.ds.FetchCAJournal(ctx, activeX509AuthorityID)
 }
 
-func (s *DataStore) ListCAJournalsForTesting(ctx context.Context) ([]*datastore.CAJournal, error) {
+func (s *DataStore) ListCAJournals(ctx context.Context) ([]*datastore.CAJournal, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
 	}
-	return s.ds.ListCAJournalsForTesting(ctx)
+	return s.ds.ListCAJournals(ctx)
 }
 
 func (s *DataStore) SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (*datastore.CAJournal, error) {
@@ -452,6 +459,27 @@ func (s *DataStore) PruneCAJournals(ctx context.Context, allCAsExpireBefore int6
 	return s.ds.PruneCAJournals(ctx, allCAsExpireBefore)
 }
 
+func (s *DataStore) RevokeX509SVID(ctx context.Context, revoked *datastore.RevokedX509SVID) (*datastore.RevokedX509SVID, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.RevokeX509SVID(ctx, revoked)
+}
+
+func (s *DataStore) ListRevokedX509SVIDs(ctx context.Context) ([]*datastore.RevokedX509SVID, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListRevokedX509SVIDs(ctx)
+}
+
+func (s *DataStore) PruneRevokedX509SVIDs(ctx context.Context, expiresBefore time.Time) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.PruneRevokedX509SVIDs(ctx, expiresBefore)
+}
+
 func (s *DataStore) SetNextError(err error) {
 	s.errs = []error{err}
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/credvalidator"
+	"github.com/spiffe/spire/pkg/server/plugin/credentialcomposer"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
 	"github.com/spiffe/spire/test/testkey"
@@ -28,6 +29,8 @@ type Options struct {
 	AgentSVIDTTL time.Duration
 	X509SVIDTTL  time.Duration
 	JWTSVIDTTL   time.Duration
+
+	CredentialComposers []credentialcomposer.CredentialComposer
 }
 
 type CA struct {
@@ -67,6 +70,8 @@ func New(t *testing.T, trustDomain spiffeid.TrustDomain, options *Options) *CA {
 		AgentSVIDTTL: options.AgentSVIDTTL,
 		X509SVIDTTL:  options.X509SVIDTTL,
 		JWTSVIDTTL:   options.JWTSVIDTTL,
+
+		CredentialComposers: options.CredentialComposers,
 	})
 	require.NoError(t, err)
 
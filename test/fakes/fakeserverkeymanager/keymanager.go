@@ -20,7 +20,7 @@ func New(t *testing.T) keymanager.KeyManager {
 
 	v1 := new(keymanager.V1)
 	plugintest.Load(t, catalog.MakeBuiltIn("fake", keymanagerv1.KeyManagerPluginServer(plugin)), v1)
-	return v1
+	return keymanager.WithEd25519Keys(v1, plugin.Base)
 }
 
 type keyManager struct {
@@ -2,6 +2,7 @@ package testkey
 
 import (
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
@@ -209,6 +210,20 @@ func MustEC384PKCS8PEM() []byte {
 	})
 }
 
+// NewEd25519 returns a new Ed25519 key. Ed25519 keys are cheap to generate so
+// they are not pre-generated.
+func NewEd25519(tb testing.TB) ed25519.PrivateKey {
+	key, err := generateEd25519()
+	require.NoError(tb, err)
+	return key
+}
+
+func MustEd25519() ed25519.PrivateKey {
+	key, err := generateEd25519()
+	check(err)
+	return key
+}
+
 type Keys struct {
 	mtx        sync.Mutex
 	rsa2048Idx int
@@ -341,6 +356,11 @@ func (ec384) GenerateKey() (*ecdsa.PrivateKey, error) {
 	return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
 }
 
+func generateEd25519() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -3,10 +3,14 @@
 package tpmsimulator
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha1" //nolint: gosec // SHA1 use is according to specification
+	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +21,7 @@ import (
 	"github.com/google/go-tpm-tools/client"
 	"github.com/google/go-tpm-tools/simulator"
 	"github.com/google/go-tpm/legacy/tpm2"
+	tpm2util "github.com/google/go-tpm/tpmutil"
 	"github.com/spiffe/spire/pkg/agent/plugin/nodeattestor/tpmdevid/tpmutil"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 )
@@ -28,6 +33,13 @@ type TPMSimulator struct {
 	ekRoot                       *x509.Certificate
 	ownerHierarchyPassword       string
 	endorsementHierarchyPassword string
+	events                       []measuredEvent
+}
+
+type measuredEvent struct {
+	pcr       int
+	eventType uint32
+	data      []byte
 }
 type Credential struct {
 	Certificate   *x509.Certificate
@@ -254,6 +266,78 @@ func (s *TPMSimulator) GenerateDevID(p *ProvisioningAuthority, keyType KeyType,
 	return devIDCred, nil
 }
 
+// MeasureEvent extends the SHA-1 and SHA-256 banks of the given PCR with the
+// digests of the event data, and records the event in the event log.
+func (s *TPMSimulator) MeasureEvent(pcr int, eventType uint32, data []byte) error {
+	sha1Digest := sha1.Sum(data) //nolint: gosec // SHA1 use is according to specification
+	if err := tpm2.PCRExtend(s, tpm2util.Handle(pcr), tpm2.AlgSHA1, sha1Digest[:], ""); err != nil {
+		return fmt.Errorf("unable to extend SHA-1 PCR %d: %w", pcr, err)
+	}
+	sha256Digest := sha256.Sum256(data)
+	if err := tpm2.PCRExtend(s, tpm2util.Handle(pcr), tpm2.AlgSHA256, sha256Digest[:], ""); err != nil {
+		return fmt.Errorf("unable to extend SHA-256 PCR %d: %w", pcr, err)
+	}
+
+	s.events = append(s.events, measuredEvent{
+		pcr:       pcr,
+		eventType: eventType,
+		data:      data,
+	})
+	return nil
+}
+
+// EventLog returns the events measured with MeasureEvent as a TCG crypto
+// agile event log, as exposed by the firmware of TPM 2.0 platforms.
+func (s *TPMSimulator) EventLog() []byte {
+	// The log starts with a Specification ID Version event in the SHA-1
+	// format, which describes the digests of the following events (TCG PC
+	// Client Platform Firmware Profile, section 9.4.5.1).
+	specID := new(bytes.Buffer)
+	specID.WriteString("Spec ID Event03\x00")
+	writeLE(specID,
+		uint32(0), // platformClass
+		uint8(0),  // specVersionMinor
+		uint8(2),  // specVersionMajor
+		uint8(0),  // specErrata
+		uint8(2),  // uintnSize
+		uint32(2), // numberOfAlgorithms
+		uint16(tpm2.AlgSHA1), uint16(sha1.Size),
+		uint16(tpm2.AlgSHA256), uint16(sha256.Size),
+		uint8(0), // vendorInfoSize
+	)
+
+	eventLog := new(bytes.Buffer)
+	writeLE(eventLog,
+		uint32(0), // pcrIndex
+		uint32(3), // EV_NO_ACTION
+		[sha1.Size]byte{},
+		uint32(specID.Len()),
+	)
+	eventLog.Write(specID.Bytes())
+
+	for _, event := range s.events {
+		// Each event carries one digest per bank
+		writeLE(eventLog,
+			uint32(event.pcr),
+			event.eventType,
+			uint32(2),
+			uint16(tpm2.AlgSHA1), sha1.Sum(event.data), //nolint: gosec // SHA1 use is according to specification
+			uint16(tpm2.AlgSHA256), sha256.Sum256(event.data),
+			uint32(len(event.data)),
+		)
+		eventLog.Write(event.data)
+	}
+
+	return eventLog.Bytes()
+}
+
+func writeLE(w *bytes.Buffer, values ...any) {
+	for _, value := range values {
+		// Writing fixed size values to a bytes.Buffer cannot fail
+		_ = binary.Write(w, binary.LittleEndian, value)
+	}
+}
+
 // GetEKRoot returns the manufacturer CA used to sign the endorsement certificate
 func (s *TPMSimulator) GetEKRoot() *x509.Certificate {
 	return s.ekRoot